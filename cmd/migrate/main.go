@@ -2,24 +2,73 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/unsavory/silocore-go/internal/database"
 )
 
+const usage = `Usage: migrate [command] [flags]
+
+Commands:
+  up       Apply pending migrations (default)
+  down     Roll back applied migrations
+  status   List applied and pending migrations
+  version  Print the current schema version and dirty flag
+  create   Create up and down migration files: migrate create <name>
+  force    Set the schema version and clear the dirty flag after repairing a
+           failed migration by hand: migrate force <version>
+
+Flags:
+`
+
 func main() {
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: Error loading .env file: %v", err)
 	}
 
+	// The command comes before the flags and defaults to up
+	command, args := "up", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	// The version to force comes before the flags, as it may be -1
+	var forceVersion string
+	if command == "force" && len(args) > 0 {
+		forceVersion, args = args[0], args[1:]
+	}
+
 	// Define command-line flags
-	migrationsPath := flag.String("path", "sql/migrations", "Path to migration files")
-	down := flag.Bool("down", false, "Migrate down instead of up")
-	steps := flag.Int("steps", 0, "Number of migrations to apply (0 means all)")
-	flag.Parse()
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	migrationsPath := flags.String("path", "", "Path to migration files (default: the migrations embedded in the binary)")
+	down := flags.Bool("down", false, "Migrate down instead of up")
+	steps := flags.Int("steps", 0, "Number of migrations to apply (0 means all)")
+	repair := flags.Bool("repair", false, "Retry the failed migration of a dirty database that it left unchanged")
+	dryRun := flags.Bool("dry-run", false, "Print the migrations that would be applied and their SQL without applying them")
+	flags.Parse(args)
+
+	// Creating a migration does not need the database, and writes to the
+	// source tree rather than the embedded migrations
+	if command == "create" {
+		path := *migrationsPath
+		if path == "" {
+			path = "sql/migrations"
+		}
+		createMigration(path, strings.Join(flags.Args(), "_"))
+		return
+	}
 
 	// Get database URL from environment variables
 	dbURL := os.Getenv("DATABASE_ADMIN_URL")
@@ -27,12 +76,32 @@ func main() {
 		log.Fatal("DATABASE_ADMIN_URL environment variable is required")
 	}
 
+	switch command {
+	case "up", "down":
+		migrate(dbURL, *migrationsPath, command == "up" && !*down, *steps, *repair, *dryRun)
+	case "force":
+		force(dbURL, *migrationsPath, forceVersion)
+	case "status":
+		printStatus(dbURL, *migrationsPath)
+	case "version":
+		printVersion(dbURL, *migrationsPath)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", command)
+		flags.Usage()
+		os.Exit(2)
+	}
+}
+
+// migrate applies or rolls back migrations
+func migrate(dbURL, migrationsPath string, up bool, steps int, repair, dryRun bool) {
 	// Set up migration options
 	opts := database.MigrateOptions{
 		DatabaseURL:    dbURL,
-		MigrationsPath: *migrationsPath,
-		MigrateUp:      !*down,
-		Steps:          *steps,
+		MigrationsPath: migrationsPath,
+		MigrateUp:      up,
+		Steps:          steps,
+		Repair:         repair,
+		DryRun:         dryRun,
 	}
 
 	// Run migrations
@@ -40,5 +109,81 @@ func main() {
 		log.Fatalf("Migration failed: %v", err)
 	}
 
-	log.Println("Migration completed successfully")
+	if !dryRun {
+		log.Println("Migration completed successfully")
+	}
+}
+
+// force sets the schema version and clears the dirty flag
+func force(dbURL, migrationsPath, version string) {
+	v, err := strconv.Atoi(version)
+	if err != nil || v < -1 {
+		log.Fatalf("A version of -1 or more is required: migrate force <version>")
+	}
+
+	if err := database.ForceMigrationVersion(dbURL, migrationsPath, v); err != nil {
+		log.Fatalf("Force failed: %v", err)
+	}
+}
+
+// createMigration creates empty up and down migration files
+func createMigration(migrationsPath, name string) {
+	paths, err := database.CreateMigration(migrationsPath, name, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to create migration: %v", err)
+	}
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+}
+
+// printStatus prints each migration as applied or pending, then the version
+func printStatus(dbURL, migrationsPath string) {
+	status, err := database.GetMigrationStatus(dbURL, migrationsPath)
+	if err != nil {
+		log.Fatalf("Failed to get migration status: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	pending := 0
+	for _, migration := range status.Migrations {
+		state := "pending"
+		switch {
+		case migration.Dirty:
+			state = "dirty"
+		case migration.Applied:
+			state = "applied"
+		default:
+			pending++
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", migration.Version, migration.Name, state)
+	}
+	w.Flush()
+
+	fmt.Println()
+	printSchemaVersion(status.Version, status.Dirty)
+	fmt.Printf("Pending: %d\n", pending)
+	if status.Dirty {
+		fmt.Println("\nThe dirty migration failed partway. Retry it with `migrate -repair` if it made no changes, or repair it by hand and run `migrate force <version>`.")
+	}
+}
+
+// printVersion prints the current schema version and dirty flag
+func printVersion(dbURL, migrationsPath string) {
+	version, dirty, err := database.GetMigrationVersion(dbURL, migrationsPath)
+	if err != nil {
+		log.Fatalf("Failed to get migration version: %v", err)
+	}
+	printSchemaVersion(version, dirty)
+}
+
+// printSchemaVersion prints a schema version, none if no migration has been applied
+func printSchemaVersion(version uint, dirty bool) {
+	if version == 0 {
+		fmt.Println("Version: none")
+	} else {
+		fmt.Printf("Version: %d\n", version)
+	}
+	fmt.Printf("Dirty: %t\n", dirty)
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/seed"
+)
+
+func main() {
+	// Load environment variables from .env file
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Error loading .env file: %v", err)
+	}
+
+	// Define command-line flags
+	seedPath := flag.String("file", "sql/seed/dev.yaml", "Path to the seed file")
+	flag.Parse()
+
+	// Get database URL from environment variables
+	dbURL := os.Getenv("DATABASE_ADMIN_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_ADMIN_URL environment variable is required")
+	}
+
+	// Read the seed file before connecting, so mistakes in it fail fast
+	file, err := seed.Load(*seedPath)
+	if err != nil {
+		log.Fatalf("Failed to load seed file: %v", err)
+	}
+
+	db, err := database.Open(context.Background(), dbURL, database.PoolConfig{MaxOpenConns: 1, MaxIdleConns: 1}, nil)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Apply the seed file
+	if err := seed.NewSeeder(db).Apply(context.Background(), file); err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+
+	log.Println("Seeding completed successfully")
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -11,12 +11,25 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/unsavory/silocore-go/internal/antivirus"
+	"github.com/unsavory/silocore-go/internal/auth/authz"
 	"github.com/unsavory/silocore-go/internal/auth/jwt"
+	billingservice "github.com/unsavory/silocore-go/internal/billing/service"
+	"github.com/unsavory/silocore-go/internal/cache"
 	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/errortracking"
+	"github.com/unsavory/silocore-go/internal/events"
+	custommw "github.com/unsavory/silocore-go/internal/http/middleware"
+	"github.com/unsavory/silocore-go/internal/http/middleware/ratelimit"
 	"github.com/unsavory/silocore-go/internal/http/router"
+	"github.com/unsavory/silocore-go/internal/mail"
+	"github.com/unsavory/silocore-go/internal/metrics"
 	orderservice "github.com/unsavory/silocore-go/internal/order/service"
 	appservice "github.com/unsavory/silocore-go/internal/service"
+	"github.com/unsavory/silocore-go/internal/storage"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/tracing"
 )
 
 func main() {
@@ -25,16 +38,31 @@ func main() {
 		log.Printf("Warning: Error loading .env file: %v", err)
 	}
 
+	// Export request traces to the OTLP collector, if one is configured
+	tracingConfig, err := tracing.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load tracing config: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	if tracingConfig.Enabled() {
+		log.Printf("[INFO] Exporting traces to %s", tracingConfig.Endpoint)
+	}
+
 	// Run database migrations at startup using the admin connection string
 	adminDbUrl := os.Getenv("DATABASE_ADMIN_URL")
 	if adminDbUrl == "" {
 		log.Fatal("DATABASE_ADMIN_URL environment variable is required for migrations")
 	}
 
-	// Set up migration options
+	// Set up migration options. The migrations embedded in the binary are used
+	// unless MIGRATIONS_PATH names a directory of them.
 	opts := database.MigrateOptions{
 		DatabaseURL:    adminDbUrl,
-		MigrationsPath: "sql/migrations",
+		MigrationsPath: os.Getenv("MIGRATIONS_PATH"),
 		MigrateUp:      true,
 		Steps:          0, // Run all pending migrations
 	}
@@ -45,17 +73,73 @@ func main() {
 	}
 	log.Println("Migrations completed successfully")
 
+	// In schema-per-tenant mode, give every tenant its schema and migrate it
+	tenancyConfig, err := database.LoadTenancyConfig()
+	if err != nil {
+		log.Fatalf("Failed to load tenancy config: %v", err)
+	}
+
+	var schemaProvisioner *database.SchemaProvisioner
+	if tenancyConfig.Mode == database.TenancySchema {
+		schemaProvisioner = database.NewSchemaProvisioner(adminDbUrl, tenancyConfig.TenantMigrationsPath)
+		if err := schemaProvisioner.ProvisionAll(context.Background()); err != nil {
+			log.Fatalf("Failed to provision tenant schemas: %v", err)
+		}
+	}
+	log.Printf("[INFO] Tenancy mode: %s", tenancyConfig.Mode)
+
 	// Initialize database connection
 	dbUrl := os.Getenv("DATABASE_URL")
 	if dbUrl == "" {
 		log.Fatal("DATABASE_URL environment variable is required")
 	}
 
-	db, err := sql.Open("postgres", dbUrl)
+	poolConfig, err := database.LoadPoolConfig()
+	if err != nil {
+		log.Fatalf("Failed to load database pool config: %v", err)
+	}
+
+	// Record query durations and log slow queries
+	queryLogConfig, err := database.LoadQueryLogConfig()
+	if err != nil {
+		log.Fatalf("Failed to load query log config: %v", err)
+	}
+	queryMetrics := database.NewQueryMetrics(queryLogConfig)
+
+	db, err := database.Open(context.Background(), dbUrl, poolConfig, queryMetrics)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
+	log.Printf("[INFO] Database pool: %d max open, %d max idle connections, %s max lifetime",
+		poolConfig.MaxOpenConns, poolConfig.MaxIdleConns, poolConfig.ConnMaxLifetime)
+
+	// Open the read replicas, if any, to take reads off the primary
+	replicas, err := database.OpenReplicas(context.Background(), poolConfig, queryMetrics)
+	if err != nil {
+		log.Fatalf("Failed to connect to read replica: %v", err)
+	}
+	queryRouter := database.NewQueryRouter(db, replicas...)
+	defer queryRouter.Close()
+	if queryRouter.HasReplicas() {
+		log.Printf("[INFO] Routing reads to %d read replicas", len(replicas))
+	}
+
+	// Record request, database and cache metrics for Prometheus
+	metricsConfig, err := metrics.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load metrics config: %v", err)
+	}
+
+	var appMetrics *metrics.Metrics
+	if metricsConfig.Enabled {
+		appMetrics = metrics.New()
+		appMetrics.RegisterQueries(queryMetrics)
+		appMetrics.RegisterDatabasePool("primary", db)
+		for i, replica := range replicas {
+			appMetrics.RegisterDatabasePool(fmt.Sprintf("replica_%d", i+1), replica)
+		}
+	}
 
 	// Initialize JWT service
 	jwtSecret := os.Getenv("JWT_SECRET")
@@ -70,8 +154,130 @@ func main() {
 		Issuer:            "silocore-go",
 	}
 
-	// Create service factory
-	serviceFactory := appservice.NewFactory(db, jwtConfig)
+	// Initialize authorization engine
+	authzConfig, err := authz.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load authorization config: %v", err)
+	}
+
+	authorizer, err := authz.New(authzConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize authorizer: %v", err)
+	}
+
+	// Initialize cache for role and membership lookups
+	cacheConfig, err := cache.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load cache config: %v", err)
+	}
+
+	roleCache, err := cache.New(cacheConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	// Count the cache's hits and misses
+	factoryCache := roleCache
+	if appMetrics != nil {
+		factoryCache = appMetrics.InstrumentCache("roles", roleCache)
+	}
+
+	// Cache responses of expensive read endpoints in the same cache, counting
+	// their hits and misses apart from the roles'
+	var responseCache *cache.ResponseCache
+	if roleCache != nil && cacheConfig.ResponseTTL > 0 {
+		responseStore := roleCache
+		if appMetrics != nil {
+			responseStore = appMetrics.InstrumentCache("responses", roleCache)
+		}
+		responseCache = cache.NewResponseCache(responseStore, cacheConfig.ResponseTTL)
+		log.Printf("[INFO] Caching order summaries and tenant statistics for %s", cacheConfig.ResponseTTL)
+	}
+
+	// Keep responses to requests with an Idempotency-Key in the same cache, so
+	// retries are answered without repeating the request
+	var idempotencyStore *cache.IdempotencyStore
+	if roleCache != nil && cacheConfig.IdempotencyTTL > 0 {
+		idempotencyStore = cache.NewIdempotencyStore(roleCache, cacheConfig.IdempotencyTTL)
+		log.Printf("[INFO] Replaying responses to retried requests with an Idempotency-Key for %s", cacheConfig.IdempotencyTTL)
+	}
+
+	// Load retention settings for soft deleted tenants
+	tenantLifecycle, err := tenantservice.LoadLifecycleConfig()
+	if err != nil {
+		log.Fatalf("Failed to load tenant lifecycle config: %v", err)
+	}
+
+	// Load retention settings for deleted and archived orders
+	orderLifecycle, err := orderservice.LoadLifecycleConfig()
+	if err != nil {
+		log.Fatalf("Failed to load order lifecycle config: %v", err)
+	}
+
+	// Initialize email delivery, logging messages if SMTP is not configured
+	mailConfig, err := mail.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load mail config: %v", err)
+	}
+	mailer := mail.New(mailConfig)
+
+	// Initialize object storage for tenant archives, using local files if no bucket is configured
+	storageConfig, err := storage.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load storage config: %v", err)
+	}
+	store := storage.New(storageConfig)
+
+	// Initialize virus scanning of uploaded order attachments, skipped if clamd is not configured
+	scanner := antivirus.New(antivirus.LoadConfig())
+
+	// Initialize error reporting, logging errors if no error tracker is configured
+	errorReporter, err := errortracking.New(errortracking.LoadConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize error reporting: %v", err)
+	}
+
+	// Initialize order event publishing, logging events if no broker is configured
+	eventsConfig, err := events.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load events config: %v", err)
+	}
+	publisher, err := events.New(eventsConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize event publisher: %v", err)
+	}
+	defer publisher.Close()
+
+	// Load Stripe billing settings
+	billingConfig, err := billingservice.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load billing config: %v", err)
+	}
+
+	// Initialize billing service if Stripe is configured
+	var billingService billingservice.BillingService
+	if billingConfig.Enabled() {
+		stripeClient := billingservice.NewHTTPStripeClient(billingConfig.APIURL, billingConfig.SecretKey, billingConfig.Timeout)
+		billingService = billingservice.NewDBBillingService(db, stripeClient, billingConfig.WebhookSecret)
+	} else {
+		log.Println("Billing disabled: STRIPE_SECRET_KEY is not set")
+	}
+
+	// Create service factory, applying plan API request budgets when billing is enabled
+	serviceFactory := appservice.NewFactory(db, jwtConfig, authorizer, factoryCache, tenantLifecycle.Retention, mailer, store, billingService, publisher, scanner, queryRouter, schemaProvisioner, responseCache)
+
+	// Cancel slow statements before the request times out
+	statementTimeouts, err := database.LoadStatementTimeoutConfig()
+	if err != nil {
+		log.Fatalf("Failed to load statement timeout config: %v", err)
+	}
+	serviceFactory.TransactionManager().WithStatementTimeout(statementTimeouts.Default)
+
+	// Skip transactions for routes that don't query the database
+	transactionConfig, err := transaction.LoadMiddlewareConfig()
+	if err != nil {
+		log.Fatalf("Failed to load transaction middleware config: %v", err)
+	}
 
 	// Initialize user service from factory
 	userService := serviceFactory.UserService()
@@ -91,20 +297,109 @@ func main() {
 	// Initialize tenant member service
 	tenantMemberService := serviceFactory.TenantMemberService()
 
+	// Initialize role service
+	roleService := serviceFactory.RoleService()
+
+	// Check the databases and the cache server, if any, for readiness
+	healthChecks := []router.HealthCheck{{Name: "database", Check: db.PingContext}}
+	for i, replica := range replicas {
+		healthChecks = append(healthChecks, router.HealthCheck{Name: fmt.Sprintf("replica_%d", i+1), Check: replica.PingContext})
+	}
+	if pinger, ok := roleCache.(cache.Pinger); ok {
+		healthChecks = append(healthChecks, router.HealthCheck{Name: "cache", Check: pinger.Ping})
+	}
+
+	// Limit how often clients can make requests
+	rateLimitConfig, err := ratelimit.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load rate limit config: %v", err)
+	}
+
+	rateLimiter, err := ratelimit.New(rateLimitConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize rate limiter: %v", err)
+	}
+
+	// Limit the size of request bodies
+	bodyLimitConfig, err := custommw.LoadBodyLimitConfig()
+	if err != nil {
+		log.Fatalf("Failed to load request body limit config: %v", err)
+	}
+
+	// Bound how long requests take, per route group
+	timeoutConfig, err := custommw.LoadTimeoutConfig()
+	if err != nil {
+		log.Fatalf("Failed to load request timeout config: %v", err)
+	}
+
+	// Compress responses with Brotli or gzip
+	compressionConfig, err := custommw.LoadCompressionConfig()
+	if err != nil {
+		log.Fatalf("Failed to load compression config: %v", err)
+	}
+
+	// Believe the client addresses forwarded by the proxies in front of the server
+	proxyConfig, err := custommw.LoadProxyConfig()
+	if err != nil {
+		log.Fatalf("Failed to load trusted proxy config: %v", err)
+	}
+
+	// Block IP addresses platform-wide, in addition to the tenants' IP rules
+	ipFilterConfig, err := custommw.LoadIPFilterConfig()
+	if err != nil {
+		log.Fatalf("Failed to load IP access list config: %v", err)
+	}
+
 	// Create router dependencies
 	routerDeps := router.RouterDependencies{
 		Factory:             serviceFactory,
 		JWTService:          jwtService,
 		UserService:         userService,
+		RoleService:         roleService,
 		AuthService:         authService,
 		OrderService:        orderService,
 		RegistrationService: registrationService,
 		JWTAuthService:      jwtService,
 		TenantMemberService: tenantMemberService,
+		TenantService:       serviceFactory.TenantService(),
+		InvitationService:   serviceFactory.InvitationService(),
+		QuotaService:        serviceFactory.QuotaService(),
+		UsageService:        serviceFactory.UsageService(),
+		BrandingService:     serviceFactory.BrandingService(),
+		ExportService:       serviceFactory.ExportService(),
+		AuditService:        serviceFactory.AuditService(),
+		OwnershipService:    serviceFactory.OwnershipService(),
+		APIKeyService:       serviceFactory.APIKeyService(),
+		WebhookService:      serviceFactory.WebhookService(),
+		DomainService:       serviceFactory.DomainService(),
+		IPRuleService:       serviceFactory.IPRuleService(),
+		CloneService:        serviceFactory.CloneService(),
+		ArchiveService:      serviceFactory.ArchiveService(),
+		OnboardingService:   serviceFactory.OnboardingService(),
+		StatsService:        serviceFactory.StatsService(),
+		ReportService:       serviceFactory.ReportService(),
+		BillingService:      billingService,
+		Authorizer:          serviceFactory.Authorizer(),
+		HealthChecks:        healthChecks,
+		QueryMetrics:        queryMetrics,
+		TenantBaseDomain:    os.Getenv("TENANT_BASE_DOMAIN"),
+
+		ReportStatementTimeout: statementTimeouts.Reports,
+		TransactionConfig:      transactionConfig,
+		RateLimiter:            rateLimiter,
+		Metrics:                appMetrics,
+		MetricsConfig:          metricsConfig,
+		MaxBodyBytes:           bodyLimitConfig.MaxBytes,
+		IPFilterConfig:         &ipFilterConfig,
+		ErrorReporter:          errorReporter,
+		IdempotencyStore:       idempotencyStore,
 	}
 
 	// Initialize Chi router with default options and dependencies
 	routerOpts := router.DefaultOptions()
+	routerOpts.Timeouts = timeoutConfig
+	routerOpts.Compression = compressionConfig
+	routerOpts.Proxies = proxyConfig
 	routerOpts.Dependencies = routerDeps
 	r := router.New(routerOpts)
 
@@ -131,11 +426,45 @@ func main() {
 		}
 	}()
 
+	// Purge soft deleted tenants once their retention window expires
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	tenantservice.StartPurgeJob(jobCtx, serviceFactory.TenantService(), tenantLifecycle.PurgeInterval)
+
+	// Purge deleted orders once their retention window expires
+	orderservice.StartPurgeJob(jobCtx, serviceFactory.OrderService(), orderLifecycle)
+
+	// Move orders that haven't changed for a long time to the archive
+	orderservice.StartArchiveJob(jobCtx, serviceFactory.OrderService(), orderLifecycle)
+
+	// Write metered usage to the database periodically
+	tenantservice.StartUsageFlushJob(jobCtx, serviceFactory.UsageService(), tenantservice.DefaultUsageFlushInterval)
+
+	// Publish the events written to the outbox
+	if dispatcher := serviceFactory.OutboxDispatcher(); dispatcher != nil {
+		events.StartOutboxDispatcher(jobCtx, dispatcher, events.DefaultOutboxInterval, events.DefaultOutboxRetention)
+	}
+
+	// Reload the role hierarchy, and drop roles and memberships from the in-memory
+	// cache, when any instance changes them. A Redis cache is shared, so the
+	// instances invalidate it themselves.
+	invalidationListener := cache.NewInvalidationListener(dbUrl)
+	serviceFactory.RegisterHierarchyInvalidation(invalidationListener)
+	if cacheConfig.Backend == cache.BackendMemory {
+		serviceFactory.RegisterCacheInvalidation(invalidationListener)
+	}
+	go func() {
+		if err := invalidationListener.Run(jobCtx); err != nil {
+			log.Printf("[ERROR] Cache invalidation listener stopped: %v", err)
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shut down the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Create a deadline for server shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -146,5 +475,15 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Write usage recorded since the last flush
+	if err := serviceFactory.UsageService().Flush(ctx); err != nil {
+		log.Printf("Failed to flush usage: %v", err)
+	}
+
+	// Export the spans of the last requests
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
+
 	log.Println("Server exited gracefully")
 }
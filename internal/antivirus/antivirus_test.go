@@ -0,0 +1,95 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd accepts one INSTREAM scan, reporting the streamed file and replying
+// with reply
+func fakeClamd(t *testing.T, reply string) (string, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		command, err := reader.ReadString(0)
+		if err != nil || command != "zINSTREAM\x00" {
+			return
+		}
+
+		var data []byte
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(reader, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			if _, err := io.ReadFull(reader, chunk); err != nil {
+				return
+			}
+			data = append(data, chunk...)
+		}
+
+		received <- data
+		conn.Write([]byte(reply + "\x00"))
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestClamdScanner(t *testing.T) {
+	t.Run("Clean file", func(t *testing.T) {
+		addr, received := fakeClamd(t, "stream: OK")
+		data := []byte(strings.Repeat("a", clamdChunkSize+10))
+
+		// Execute
+		err := NewClamdScanner(addr).Scan(context.Background(), data)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, data, <-received)
+	})
+
+	t.Run("Infected file", func(t *testing.T) {
+		addr, _ := fakeClamd(t, "stream: Eicar-Test-Signature FOUND")
+
+		// Execute
+		err := NewClamdScanner(addr).Scan(context.Background(), []byte("X5O!P%@AP"))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInfected)
+		assert.Contains(t, err.Error(), "Eicar-Test-Signature")
+	})
+
+	t.Run("Scan error", func(t *testing.T) {
+		addr, _ := fakeClamd(t, "INSTREAM size limit exceeded. ERROR")
+
+		// Execute
+		err := NewClamdScanner(addr).Scan(context.Background(), []byte("data"))
+
+		// Assert
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrInfected)
+	})
+}
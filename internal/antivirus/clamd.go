@@ -0,0 +1,91 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the size of the chunks files are streamed to clamd in. It
+// must stay below clamd's StreamMaxLength.
+const clamdChunkSize = 64 * 1024
+
+// ClamdScanner scans files with a ClamAV daemon, streaming them over TCP with
+// the INSTREAM command
+type ClamdScanner struct {
+	addr    string
+	timeout time.Duration
+}
+
+// Ensure ClamdScanner implements Scanner
+var _ Scanner = (*ClamdScanner)(nil)
+
+// NewClamdScanner creates a new ClamdScanner for the daemon at addr, e.g. localhost:3310
+func NewClamdScanner(addr string) *ClamdScanner {
+	return &ClamdScanner{
+		addr:    addr,
+		timeout: 30 * time.Second,
+	}
+}
+
+// Scan streams data to clamd and reports the signature it found, if any
+func (s *ClamdScanner) Scan(ctx context.Context, data []byte) error {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to clamd at %s: %w", s.addr, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return fmt.Errorf("failed to send file to clamd: %w", err)
+	}
+
+	// Each chunk is prefixed with its length, and a zero length ends the stream
+	size := make([]byte, 4)
+	for offset := 0; offset < len(data); offset += clamdChunkSize {
+		chunk := data[offset:min(offset+clamdChunkSize, len(data))]
+		binary.BigEndian.PutUint32(size, uint32(len(chunk)))
+		if _, err := conn.Write(size); err != nil {
+			return fmt.Errorf("failed to send file to clamd: %w", err)
+		}
+		if _, err := conn.Write(chunk); err != nil {
+			return fmt.Errorf("failed to send file to clamd: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return fmt.Errorf("failed to send file to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+
+	return parseClamdReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// parseClamdReply interprets a reply to INSTREAM, e.g. "stream: OK" or
+// "stream: Eicar-Test-Signature FOUND"
+func parseClamdReply(reply string) error {
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return nil
+	case strings.HasSuffix(result, " FOUND"):
+		return fmt.Errorf("%w: %s", ErrInfected, strings.TrimSuffix(result, " FOUND"))
+	default:
+		return fmt.Errorf("clamd failed to scan file: %s", reply)
+	}
+}
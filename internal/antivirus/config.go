@@ -0,0 +1,36 @@
+package antivirus
+
+import (
+	"log"
+	"os"
+)
+
+const (
+	// Environment variable names
+	envClamdAddr = "CLAMD_ADDR"
+)
+
+// Config holds configuration for scanning uploaded files
+type Config struct {
+	// ClamdAddr is the TCP address of a ClamAV daemon, or empty to skip scanning
+	ClamdAddr string
+}
+
+// LoadConfig loads virus scanning configuration from environment variables
+func LoadConfig() Config {
+	return Config{
+		ClamdAddr: os.Getenv(envClamdAddr),
+	}
+}
+
+// New creates the Scanner selected by the configuration.
+// Files are not scanned when CLAMD_ADDR is not set.
+func New(config Config) Scanner {
+	if config.ClamdAddr == "" {
+		log.Printf("[INFO] CLAMD_ADDR is not set, uploaded files will not be scanned for viruses")
+		return NoopScanner{}
+	}
+
+	log.Printf("[INFO] Scanning uploaded files with clamd at %s", config.ClamdAddr)
+	return NewClamdScanner(config.ClamdAddr)
+}
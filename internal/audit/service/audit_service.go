@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// Common errors
+var (
+	ErrDBOperation  = errors.New("database operation failed")
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// Audited actions
+const (
+	ActionMemberAdded        = "member.added"
+	ActionMemberRemoved      = "member.removed"
+	ActionRoleAssigned       = "role.assigned"
+	ActionRoleRevoked        = "role.revoked"
+	ActionOrderCreated       = "order.created"
+	ActionOrderUpdated       = "order.updated"
+	ActionOrderStatusChanged = "order.status_changed"
+	ActionOrderDeleted       = "order.deleted"
+	ActionOrderRestored      = "order.restored"
+	ActionOrderAssigned      = "order.assigned"
+	ActionOrderUnassigned    = "order.unassigned"
+
+	ActionOwnershipTransferRequested = "ownership.transfer_requested"
+	ActionOwnershipTransferred       = "ownership.transferred"
+
+	// ActionAccessBlocked records a request rejected by an IP access list
+	ActionAccessBlocked = "access.blocked"
+
+	// ActionRequest records a request that may have changed the tenant's data,
+	// whatever its outcome
+	ActionRequest = "request"
+)
+
+// Audited resource types
+const (
+	ResourceMember = "member"
+	ResourceRole   = "role"
+	ResourceOrder  = "order"
+	ResourceTenant = "tenant"
+
+	// ResourceIPAddress is identified by the address, such as 203.0.113.9
+	ResourceIPAddress = "ip_address"
+
+	// ResourceRoute is identified by the method and pattern of a route, such as
+	// PATCH /api/v1/orders/{id}
+	ResourceRoute = "route"
+)
+
+const (
+	// defaultAuditLimit is the number of entries returned when no limit is given
+	defaultAuditLimit = 100
+
+	// maxAuditLimit is the maximum number of entries returned at once
+	maxAuditLimit = 1000
+)
+
+// AuditEntry is a record of a change made within a tenant
+type AuditEntry struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
+	// ActorUserID is the user who made the change, or nil for changes made outside a request
+	ActorUserID  *int64                 `json:"actor_user_id"`
+	Action       string                 `json:"action"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// AuditFilter defines filters for querying a tenant's audit log
+type AuditFilter struct {
+	TenantID     int64
+	ActorUserID  *int64
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
+
+// AuditRecorder records changes to the audit log
+type AuditRecorder interface {
+	// Record writes an audit entry. The acting user is taken from the context.
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+// AuditService defines the interface for tenant audit log operations
+type AuditService interface {
+	AuditRecorder
+
+	// ListEntries retrieves a tenant's audit entries, newest first
+	ListEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
+
+// DBAuditService implements AuditService using a database
+type DBAuditService struct {
+	db *sql.DB
+}
+
+// Ensure DBAuditService implements AuditService
+var _ AuditService = (*DBAuditService)(nil)
+
+// NewDBAuditService creates a new DBAuditService
+func NewDBAuditService(db *sql.DB) *DBAuditService {
+	return &DBAuditService{db: db}
+}
+
+// Record writes an audit entry. The acting user is taken from the context.
+func (s *DBAuditService) Record(ctx context.Context, entry AuditEntry) error {
+	if entry.TenantID == 0 || entry.Action == "" || entry.ResourceType == "" {
+		return fmt.Errorf("%w: tenant, action and resource type are required", ErrInvalidInput)
+	}
+
+	if entry.ActorUserID == nil {
+		if id, err := authctx.GetUserID(ctx); err == nil {
+			entry.ActorUserID = &id
+		}
+	}
+
+	details := []byte("{}")
+	if len(entry.Details) > 0 {
+		var err error
+		details, err = json.Marshal(entry.Details)
+		if err != nil {
+			return fmt.Errorf("%w: failed to encode details: %v", ErrInvalidInput, err)
+		}
+	}
+
+	query := `
+		INSERT INTO audit_log (tenant_id, actor_user_id, action, resource_type, resource_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := s.db.ExecContext(ctx, query, entry.TenantID, entry.ActorUserID, entry.Action, entry.ResourceType, entry.ResourceID, details)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return nil
+}
+
+// ListEntries retrieves a tenant's audit entries, newest first
+func (s *DBAuditService) ListEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	if filter.TenantID == 0 {
+		return nil, fmt.Errorf("%w: tenant ID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT id, tenant_id, actor_user_id, action, resource_type, resource_id, details, created_at
+		FROM audit_log
+	`
+
+	args := []interface{}{filter.TenantID}
+	conditions := []string{"tenant_id = $1"}
+
+	if filter.ActorUserID != nil {
+		args = append(args, *filter.ActorUserID)
+		conditions = append(conditions, fmt.Sprintf("actor_user_id = $%d", len(args)))
+	}
+
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+
+	if filter.ResourceType != "" {
+		args = append(args, filter.ResourceType)
+		conditions = append(conditions, fmt.Sprintf("resource_type = $%d", len(args)))
+	}
+
+	if filter.ResourceID != "" {
+		args = append(args, filter.ResourceID)
+		conditions = append(conditions, fmt.Sprintf("resource_id = $%d", len(args)))
+	}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	query += " WHERE " + strings.Join(conditions, " AND ")
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := transaction.QuerierFor(ctx, s.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		var actorID sql.NullInt64
+		var details []byte
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.TenantID,
+			&actorID,
+			&entry.Action,
+			&entry.ResourceType,
+			&entry.ResourceID,
+			&details,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		if actorID.Valid {
+			entry.ActorUserID = &actorID.Int64
+		}
+
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &entry.Details); err != nil {
+				return nil, fmt.Errorf("%w: failed to decode details: %v", ErrDBOperation, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+func TestRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBAuditService(db)
+
+	t.Run("Actor taken from context", func(t *testing.T) {
+		// Setup
+		ctx := authctx.WithUserID(context.Background(), 7)
+
+		// Setup mock expectations
+		mock.ExpectExec("INSERT INTO audit_log \\(tenant_id, actor_user_id, action, resource_type, resource_id, details\\)").
+			WithArgs(int64(1), int64(7), ActionMemberAdded, ResourceMember, "9", []byte("{}")).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Execute
+		err := service.Record(ctx, AuditEntry{
+			TenantID:     1,
+			Action:       ActionMemberAdded,
+			ResourceType: ResourceMember,
+			ResourceID:   "9",
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No actor outside a request", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("INSERT INTO audit_log").
+			WithArgs(int64(1), nil, ActionOrderCreated, ResourceOrder, "3", []byte(`{"status":"pending"}`)).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+
+		// Execute
+		err := service.Record(context.Background(), AuditEntry{
+			TenantID:     1,
+			Action:       ActionOrderCreated,
+			ResourceType: ResourceOrder,
+			ResourceID:   "3",
+			Details:      map[string]interface{}{"status": "pending"},
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing tenant", func(t *testing.T) {
+		// Execute
+		err := service.Record(context.Background(), AuditEntry{Action: ActionOrderCreated, ResourceType: ResourceOrder})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBAuditService(db)
+	ctx := context.Background()
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "tenant_id", "actor_user_id", "action", "resource_type", "resource_id", "details", "created_at"}
+
+	t.Run("Default limit", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("FROM audit_log WHERE tenant_id = \\$1 ORDER BY created_at DESC, id DESC LIMIT \\$2 OFFSET \\$3").
+			WithArgs(int64(1), defaultAuditLimit, 0).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, 1, 7, ActionRoleAssigned, ResourceRole, "4", []byte(`{"user_id":9}`), createdAt).
+				AddRow(1, 1, nil, ActionMemberAdded, ResourceMember, "9", []byte("{}"), createdAt))
+
+		// Execute
+		entries, err := service.ListEntries(ctx, AuditFilter{TenantID: 1})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, int64(7), *entries[0].ActorUserID)
+		assert.Equal(t, float64(9), entries[0].Details["user_id"])
+		assert.Nil(t, entries[1].ActorUserID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		// Setup
+		actorID := int64(7)
+		from := createdAt.Add(-time.Hour)
+
+		// Setup mock expectations
+		mock.ExpectQuery("WHERE tenant_id = \\$1 AND actor_user_id = \\$2 AND action = \\$3 AND resource_type = \\$4 AND created_at >= \\$5 ORDER BY created_at DESC, id DESC LIMIT \\$6 OFFSET \\$7").
+			WithArgs(int64(1), actorID, ActionOrderDeleted, ResourceOrder, from, maxAuditLimit, 20).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		// Execute
+		entries, err := service.ListEntries(ctx, AuditFilter{
+			TenantID:     1,
+			ActorUserID:  &actorID,
+			Action:       ActionOrderDeleted,
+			ResourceType: ResourceOrder,
+			From:         &from,
+			Limit:        5000,
+			Offset:       20,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing tenant", func(t *testing.T) {
+		// Execute
+		_, err := service.ListEntries(ctx, AuditFilter{})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
@@ -0,0 +1,90 @@
+package authz
+
+import (
+	"context"
+	"errors"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// Common errors
+var (
+	ErrForbidden = errors.New("access denied by policy")
+)
+
+// Request describes an authorization decision to be made.
+// It carries the facts about the subject gathered by the caller (roles and
+// tenant membership) together with the resource and action being accessed.
+type Request struct {
+	// UserID is the user requesting access
+	UserID int64 `json:"user_id"`
+	// TenantID is the tenant context of the request, nil for global access
+	TenantID *int64 `json:"tenant_id,omitempty"`
+	// Roles are the user's effective roles in the current scope (system-wide
+	// roles plus tenant roles when a tenant context is present)
+	Roles []authctx.Role `json:"roles"`
+	// IsTenantMember indicates whether the user is a member of TenantID
+	IsTenantMember bool `json:"is_tenant_member"`
+	// RequiredRoles lists roles of which the user must hold at least one
+	RequiredRoles []authctx.Role `json:"required_roles,omitempty"`
+	// Resource is the resource being accessed (e.g. "orders")
+	Resource string `json:"resource,omitempty"`
+	// Action is the action being performed on the resource (e.g. "read")
+	Action string `json:"action,omitempty"`
+	// Scopes limits a request authenticated by an API key to these permissions,
+	// on top of the roles of the user who created the key
+	Scopes []string `json:"scopes,omitempty"`
+	// Attributes holds additional attributes for attribute-based policies
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// HasRole reports whether the request subject holds the given role
+func (r Request) HasRole(role authctx.Role) bool {
+	for _, held := range r.Roles {
+		if held == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer makes authorization decisions
+type Authorizer interface {
+	// Authorize returns nil if the request is allowed, ErrForbidden if it is
+	// denied, or another error if a decision could not be made
+	Authorize(ctx context.Context, req Request) error
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface
+type AuthorizerFunc func(ctx context.Context, req Request) error
+
+// Authorize calls f(ctx, req)
+func (f AuthorizerFunc) Authorize(ctx context.Context, req Request) error {
+	return f(ctx, req)
+}
+
+// RequestFromContext builds a Request from the authentication data that the
+// auth and role middleware placed in the context
+func RequestFromContext(ctx context.Context) Request {
+	req := Request{}
+
+	if userID, err := authctx.GetUserID(ctx); err == nil {
+		req.UserID = userID
+	}
+
+	if tenantID, err := authctx.GetTenantID(ctx); err == nil {
+		req.TenantID = tenantID
+	}
+
+	if roles, err := authctx.GetRoles(ctx); err == nil {
+		req.Roles = roles
+	}
+
+	if scopes, err := authctx.GetScopes(ctx); err == nil {
+		req.Scopes = scopes
+	}
+
+	req.IsTenantMember = req.TenantID != nil && req.HasRole(authctx.RoleTenantMember)
+
+	return req
+}
@@ -0,0 +1,229 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+func TestRoleAuthorizer(t *testing.T) {
+	tenantID := int64(1)
+	authorizer := NewRoleAuthorizer()
+
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr error
+	}{
+		{
+			name: "Admin has access to everything",
+			req: Request{
+				TenantID:      &tenantID,
+				Roles:         []authctx.Role{authctx.RoleAdmin},
+				RequiredRoles: []authctx.Role{authctx.RoleTenantSuper},
+			},
+		},
+		{
+			name: "Tenant member has access",
+			req: Request{
+				TenantID:       &tenantID,
+				Roles:          []authctx.Role{authctx.RoleInternal},
+				IsTenantMember: true,
+			},
+		},
+		{
+			name: "Non-member is denied",
+			req: Request{
+				TenantID: &tenantID,
+				Roles:    []authctx.Role{authctx.RoleInternal},
+			},
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "Member with required role has access",
+			req: Request{
+				TenantID:       &tenantID,
+				Roles:          []authctx.Role{authctx.RoleTenantSuper},
+				IsTenantMember: true,
+				RequiredRoles:  []authctx.Role{authctx.RoleTenantSuper},
+			},
+		},
+		{
+			name: "Member without required role is denied",
+			req: Request{
+				TenantID:       &tenantID,
+				Roles:          []authctx.Role{authctx.RoleTenantMember},
+				IsTenantMember: true,
+				RequiredRoles:  []authctx.Role{authctx.RoleTenantSuper},
+			},
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "Global request without required role is denied",
+			req: Request{
+				Roles:         []authctx.Role{authctx.RoleInternal},
+				RequiredRoles: []authctx.Role{authctx.RoleAdmin},
+			},
+			wantErr: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorizer.Authorize(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRequestFromContext(t *testing.T) {
+	tenantID := int64(7)
+	ctx := authctx.WithUserID(context.Background(), 42)
+	ctx = authctx.WithTenantID(ctx, &tenantID)
+	ctx = authctx.WithRoles(ctx, []authctx.Role{authctx.RoleTenantMember})
+
+	req := RequestFromContext(ctx)
+
+	assert.Equal(t, int64(42), req.UserID)
+	require.NotNil(t, req.TenantID)
+	assert.Equal(t, tenantID, *req.TenantID)
+	assert.True(t, req.IsTenantMember)
+}
+
+func TestOPAAuthorizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		wantErr  error
+		anyErr   bool
+	}{
+		{name: "Allowed", status: http.StatusOK, response: `{"result": true}`},
+		{name: "Denied", status: http.StatusOK, response: `{"result": false}`, wantErr: ErrForbidden},
+		{name: "Undefined result is denied", status: http.StatusOK, response: `{}`, wantErr: ErrForbidden},
+		{name: "Server error", status: http.StatusInternalServerError, response: `{}`, anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/data/silocore/authz/allow", r.URL.Path)
+				var body struct {
+					Input map[string]interface{} `json:"input"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				input = body.Input
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			authorizer := NewOPAAuthorizer(server.URL+"/", "/silocore/authz/allow", time.Second)
+			err := authorizer.Authorize(context.Background(), Request{UserID: 1, Resource: "orders", Action: "read"})
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.anyErr:
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, ErrForbidden)
+			default:
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "orders", input["resource"])
+			assert.Equal(t, "read", input["action"])
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	authorizer, err := New(Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &RoleAuthorizer{}, authorizer)
+
+	_, err = New(Config{Engine: EngineOPA})
+	assert.Error(t, err)
+
+	authorizer, err = New(Config{Engine: EngineOPA, OPAURL: "http://localhost:8181", OPAPolicyPath: "silocore/authz/allow"})
+	require.NoError(t, err)
+	assert.IsType(t, &OPAAuthorizer{}, authorizer)
+
+	_, err = New(Config{Engine: "casbin"})
+	assert.Error(t, err)
+}
+
+func TestRoleAuthorizerPermissions(t *testing.T) {
+	tenantID := int64(1)
+	authorizer := NewRoleAuthorizer()
+
+	tests := []struct {
+		name     string
+		roles    []authctx.Role
+		resource string
+		action   string
+		allowed  bool
+	}{
+		{"Member can read orders", []authctx.Role{authctx.RoleTenantMember}, ResourceOrders, ActionRead, true},
+		{"Member can read members", []authctx.Role{authctx.RoleTenantMember}, ResourceMembers, ActionRead, true},
+		{"Member cannot update tenant", []authctx.Role{authctx.RoleTenantMember}, ResourceTenant, ActionUpdate, false},
+		{"Member cannot manage tenant", []authctx.Role{authctx.RoleTenantMember}, ResourceTenant, ActionManage, false},
+		{"Super can manage tenant", []authctx.Role{authctx.RoleTenantMember, authctx.RoleTenantSuper}, ResourceTenant, ActionManage, true},
+		{"Super can delete members", []authctx.Role{authctx.RoleTenantSuper}, ResourceMembers, ActionDelete, true},
+		{"Admin can do anything", []authctx.Role{authctx.RoleAdmin}, "reports", ActionRead, true},
+		{"Unknown resource is denied", []authctx.Role{authctx.RoleTenantSuper}, "reports", ActionRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorizer.Authorize(context.Background(), Request{
+				TenantID:       &tenantID,
+				Roles:          tt.roles,
+				IsTenantMember: true,
+				Resource:       tt.resource,
+				Action:         tt.action,
+			})
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestPermissionMatches(t *testing.T) {
+	assert.True(t, NewPermission(ResourceOrders, ActionRead).Matches(ResourceOrders, ActionRead))
+	assert.False(t, NewPermission(ResourceOrders, ActionRead).Matches(ResourceOrders, ActionUpdate))
+	assert.True(t, NewPermission(ResourceOrders, Wildcard).Matches(ResourceOrders, ActionDelete))
+	assert.True(t, NewPermission(Wildcard, Wildcard).Matches(ResourceTenant, ActionManage))
+	assert.False(t, Permission("orders").Matches(ResourceOrders, ActionRead))
+}
+
+func TestPermissionValid(t *testing.T) {
+	assert.True(t, NewPermission(ResourceOrders, ActionRead).Valid())
+	assert.True(t, NewPermission(ResourceMembers, Wildcard).Valid())
+	assert.False(t, NewPermission("admin", ActionRead).Valid())
+	assert.False(t, NewPermission(ResourceOrders, "write").Valid())
+	assert.False(t, Permission("orders").Valid())
+}
+
+func TestScopesAllow(t *testing.T) {
+	scopes := []string{"orders:read", "members:*"}
+
+	assert.True(t, ScopesAllow(scopes, ResourceOrders, ActionRead))
+	assert.True(t, ScopesAllow(scopes, ResourceMembers, ActionDelete))
+	assert.False(t, ScopesAllow(scopes, ResourceOrders, ActionCreate))
+	assert.False(t, ScopesAllow(nil, ResourceOrders, ActionRead))
+}
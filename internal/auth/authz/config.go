@@ -0,0 +1,77 @@
+package authz
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Supported authorization engines
+const (
+	EngineRole = "role"
+	EngineOPA  = "opa"
+)
+
+const (
+	// Default values
+	defaultOPAPolicyPath = "silocore/authz/allow"
+	defaultOPATimeout    = 2 * time.Second
+
+	// Environment variable names
+	envAuthzEngine      = "AUTHZ_ENGINE"
+	envOPAURL           = "OPA_URL"
+	envOPAPolicyPath    = "OPA_POLICY_PATH"
+	envOPATimeoutMillis = "OPA_TIMEOUT_MS"
+)
+
+// Config holds configuration for the authorization engine
+type Config struct {
+	Engine        string
+	OPAURL        string
+	OPAPolicyPath string
+	OPATimeout    time.Duration
+}
+
+// LoadConfig loads authorization configuration from environment variables
+func LoadConfig() (Config, error) {
+	config := Config{
+		Engine:        os.Getenv(envAuthzEngine),
+		OPAURL:        os.Getenv(envOPAURL),
+		OPAPolicyPath: os.Getenv(envOPAPolicyPath),
+		OPATimeout:    defaultOPATimeout,
+	}
+
+	if config.Engine == "" {
+		config.Engine = EngineRole
+	}
+
+	if config.OPAPolicyPath == "" {
+		config.OPAPolicyPath = defaultOPAPolicyPath
+	}
+
+	if timeoutStr := os.Getenv(envOPATimeoutMillis); timeoutStr != "" {
+		timeoutMs, err := strconv.Atoi(timeoutStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid OPA_TIMEOUT_MS value: %w", err)
+		}
+		config.OPATimeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	return config, nil
+}
+
+// New creates the Authorizer selected by the configuration
+func New(config Config) (Authorizer, error) {
+	switch config.Engine {
+	case "", EngineRole:
+		return NewRoleAuthorizer(), nil
+	case EngineOPA:
+		if config.OPAURL == "" {
+			return nil, fmt.Errorf("OPA_URL environment variable is required for the opa authorization engine")
+		}
+		return NewOPAAuthorizer(config.OPAURL, config.OPAPolicyPath, config.OPATimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown authorization engine: %s", config.Engine)
+	}
+}
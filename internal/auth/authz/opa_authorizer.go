@@ -0,0 +1,84 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OPAAuthorizer delegates authorization decisions to an Open Policy Agent
+// server, allowing deployments to express attribute-based rules in Rego.
+// The request is sent as the policy input and the policy must evaluate to a
+// boolean, e.g. POST /v1/data/silocore/authz/allow.
+type OPAAuthorizer struct {
+	url    string
+	client *http.Client
+}
+
+// Ensure OPAAuthorizer implements Authorizer
+var _ Authorizer = (*OPAAuthorizer)(nil)
+
+// NewOPAAuthorizer creates a new OPAAuthorizer for the given OPA base URL and
+// policy path (e.g. "silocore/authz/allow")
+func NewOPAAuthorizer(baseURL, policyPath string, timeout time.Duration) *OPAAuthorizer {
+	url := strings.TrimRight(baseURL, "/") + "/v1/data/" + strings.Trim(policyPath, "/")
+	log.Printf("[INFO] Initializing OPA authorizer with policy endpoint: %s", url)
+	return &OPAAuthorizer{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// opaRequest is the request body sent to OPA
+type opaRequest struct {
+	Input Request `json:"input"`
+}
+
+// opaResponse is the response body returned by OPA
+type opaResponse struct {
+	Result *bool `json:"result"`
+}
+
+// Authorize evaluates the OPA policy for the request
+func (a *OPAAuthorizer) Authorize(ctx context.Context, req Request) error {
+	body, err := json.Marshal(opaRequest{Input: req})
+	if err != nil {
+		return fmt.Errorf("failed to encode OPA input: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create OPA request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		log.Printf("[ERROR] OPA request failed: %v", err)
+		return fmt.Errorf("OPA request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[ERROR] OPA returned unexpected status %d", resp.StatusCode)
+		return fmt.Errorf("OPA returned unexpected status %d", resp.StatusCode)
+	}
+
+	var result opaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode OPA response: %w", err)
+	}
+
+	// An undefined result means the policy did not match, which is a denial
+	if result.Result == nil || !*result.Result {
+		log.Printf("[DEBUG] OPA denied access for user ID %d to %s:%s", req.UserID, req.Resource, req.Action)
+		return ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package authz
+
+import (
+	"strings"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// Wildcard matches any resource or action in a permission
+const Wildcard = "*"
+
+// Resources protected by permissions
+const (
+	ResourceOrders  = "orders"
+	ResourceTenant  = "tenant"
+	ResourceMembers = "members"
+)
+
+// Actions that can be performed on resources
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionManage = "manage"
+)
+
+// Permission grants an action on a resource, written as "resource:action"
+type Permission string
+
+// NewPermission creates a permission for an action on a resource
+func NewPermission(resource, action string) Permission {
+	return Permission(resource + ":" + action)
+}
+
+// Matches reports whether the permission grants the action on the resource
+func (p Permission) Matches(resource, action string) bool {
+	permResource, permAction, ok := strings.Cut(string(p), ":")
+	if !ok {
+		return false
+	}
+	return (permResource == Wildcard || permResource == resource) &&
+		(permAction == Wildcard || permAction == action)
+}
+
+// Valid reports whether the permission names a known resource and action, or a wildcard
+func (p Permission) Valid() bool {
+	resource, action, ok := strings.Cut(string(p), ":")
+	if !ok {
+		return false
+	}
+
+	switch resource {
+	case Wildcard, ResourceOrders, ResourceTenant, ResourceMembers:
+	default:
+		return false
+	}
+
+	switch action {
+	case Wildcard, ActionRead, ActionCreate, ActionUpdate, ActionDelete, ActionManage:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScopesAllow reports whether any of the scopes, written as permissions, grants
+// the action on the resource
+func ScopesAllow(scopes []string, resource, action string) bool {
+	for _, scope := range scopes {
+		if Permission(scope).Matches(resource, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions maps roles to the permissions they grant
+type Permissions map[authctx.Role][]Permission
+
+// Allows reports whether any of the roles grants the action on the resource
+func (p Permissions) Allows(roles []authctx.Role, resource, action string) bool {
+	for _, role := range roles {
+		for _, permission := range p[role] {
+			if permission.Matches(resource, action) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DefaultPermissions returns the built-in role permissions.
+// ADMIN is not listed since admins are allowed everything.
+func DefaultPermissions() Permissions {
+	return Permissions{
+		authctx.RoleTenantSuper: {
+			NewPermission(ResourceOrders, Wildcard),
+			NewPermission(ResourceTenant, Wildcard),
+			NewPermission(ResourceMembers, Wildcard),
+		},
+		authctx.RoleTenantMember: {
+			NewPermission(ResourceOrders, ActionRead),
+			NewPermission(ResourceOrders, ActionCreate),
+			NewPermission(ResourceOrders, ActionUpdate),
+			NewPermission(ResourceOrders, ActionDelete),
+			NewPermission(ResourceTenant, ActionRead),
+			NewPermission(ResourceMembers, ActionRead),
+		},
+	}
+}
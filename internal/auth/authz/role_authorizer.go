@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"context"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// RoleAuthorizer is the default role-based Authorizer.
+//   - ADMIN users are allowed everything
+//   - Requests with a tenant context require tenant membership
+//   - When required roles are given, the user must hold at least one of them
+//   - Otherwise, when a resource is given, one of the user's roles must grant
+//     the action on it
+type RoleAuthorizer struct {
+	permissions Permissions
+}
+
+// Ensure RoleAuthorizer implements Authorizer
+var _ Authorizer = (*RoleAuthorizer)(nil)
+
+// NewRoleAuthorizer creates a new RoleAuthorizer with the default permissions
+func NewRoleAuthorizer() *RoleAuthorizer {
+	return NewRoleAuthorizerWithPermissions(DefaultPermissions())
+}
+
+// NewRoleAuthorizerWithPermissions creates a new RoleAuthorizer with custom permissions
+func NewRoleAuthorizerWithPermissions(permissions Permissions) *RoleAuthorizer {
+	return &RoleAuthorizer{
+		permissions: permissions,
+	}
+}
+
+// Authorize applies the role-based access rules to the request
+func (a *RoleAuthorizer) Authorize(ctx context.Context, req Request) error {
+	// Admin role has access to everything
+	if req.HasRole(authctx.RoleAdmin) {
+		return nil
+	}
+
+	// Tenant-specific access requires membership
+	if req.TenantID != nil && !req.IsTenantMember {
+		return ErrForbidden
+	}
+
+	// Check if user has any of the required roles
+	if len(req.RequiredRoles) > 0 {
+		for _, required := range req.RequiredRoles {
+			if req.HasRole(required) {
+				return nil
+			}
+		}
+		return ErrForbidden
+	}
+
+	// Check if any of the user's roles grants the requested permission
+	if req.Resource != "" && !a.permissions.Allows(req.Roles, req.Resource, req.Action) {
+		return ErrForbidden
+	}
+
+	return nil
+}
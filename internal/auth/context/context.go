@@ -14,6 +14,10 @@ const (
 	tenantIDKey contextKey = "tenant_id"
 	usernameKey contextKey = "username"
 	rolesKey    contextKey = "roles"
+
+	resolvedTenantIDKey contextKey = "resolved_tenant_id"
+	apiKeyIDKey         contextKey = "api_key_id"
+	scopesKey           contextKey = "scopes"
 )
 
 // Common errors
@@ -22,6 +26,10 @@ var (
 	ErrNoTenantID = errors.New("tenant ID not found in context")
 	ErrNoUsername = errors.New("username not found in context")
 	ErrNoRoles    = errors.New("roles not found in context")
+
+	ErrNoResolvedTenantID = errors.New("resolved tenant ID not found in context")
+	ErrNoAPIKeyID         = errors.New("API key ID not found in context")
+	ErrNoScopes           = errors.New("scopes not found in context")
 )
 
 // Role represents a system role
@@ -32,8 +40,18 @@ const (
 	RoleAdmin       Role = "ADMIN"
 	RoleInternal    Role = "INTERNAL"
 	RoleTenantSuper Role = "TENANT_SUPER"
+
+	// RoleTenantMember is granted to every member of the current tenant and
+	// inherited by TENANT_SUPER through the role hierarchy
+	RoleTenantMember Role = "TENANT_MEMBER"
 )
 
+// IsPlatformRole reports whether a role grants platform-wide access, so it must
+// never be assigned within a tenant
+func IsPlatformRole(role Role) bool {
+	return role == RoleAdmin || role == RoleInternal
+}
+
 // WithUserID adds a user ID to the context
 func WithUserID(ctx context.Context, userID int64) context.Context {
 	return context.WithValue(ctx, userIDKey, userID)
@@ -62,6 +80,49 @@ func GetTenantID(ctx context.Context) (*int64, error) {
 	return tenantID, nil
 }
 
+// WithResolvedTenantID adds the tenant resolved from the request host or path to the context
+func WithResolvedTenantID(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, resolvedTenantIDKey, tenantID)
+}
+
+// GetResolvedTenantID retrieves the tenant resolved from the request host or path
+func GetResolvedTenantID(ctx context.Context) (int64, error) {
+	tenantID, ok := ctx.Value(resolvedTenantIDKey).(int64)
+	if !ok {
+		return 0, ErrNoResolvedTenantID
+	}
+	return tenantID, nil
+}
+
+// WithAPIKeyID marks the request as authenticated by the API key with the given ID
+func WithAPIKeyID(ctx context.Context, keyID int64) context.Context {
+	return context.WithValue(ctx, apiKeyIDKey, keyID)
+}
+
+// GetAPIKeyID retrieves the ID of the API key that authenticated the request
+func GetAPIKeyID(ctx context.Context) (int64, error) {
+	keyID, ok := ctx.Value(apiKeyIDKey).(int64)
+	if !ok {
+		return 0, ErrNoAPIKeyID
+	}
+	return keyID, nil
+}
+
+// WithScopes limits the request to the given "resource:action" scopes
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey, scopes)
+}
+
+// GetScopes retrieves the scopes the request is limited to. Requests without
+// scopes are limited only by the user's roles.
+func GetScopes(ctx context.Context) ([]string, error) {
+	scopes, ok := ctx.Value(scopesKey).([]string)
+	if !ok {
+		return nil, ErrNoScopes
+	}
+	return scopes, nil
+}
+
 // WithUsername adds a username to the context
 func WithUsername(ctx context.Context, username string) context.Context {
 	return context.WithValue(ctx, usernameKey, username)
@@ -115,6 +176,11 @@ func IsTenantSuper(ctx context.Context) bool {
 	return HasRole(ctx, RoleTenantSuper)
 }
 
+// IsTenantMember checks if the context has the TENANT_MEMBER role
+func IsTenantMember(ctx context.Context) bool {
+	return HasRole(ctx, RoleTenantMember)
+}
+
 // IsInternal checks if the context has the INTERNAL role
 func IsInternal(ctx context.Context) bool {
 	return HasRole(ctx, RoleInternal)
@@ -79,6 +79,29 @@ func TestAuthContext(t *testing.T) {
 		}
 	})
 
+	t.Run("APIKey", func(t *testing.T) {
+		// Test with an API key and scopes
+		ctx := WithAPIKeyID(context.Background(), 9)
+		ctx = WithScopes(ctx, []string{"orders:read"})
+
+		keyID, err := GetAPIKeyID(ctx)
+		if err != nil || keyID != 9 {
+			t.Errorf("Expected API key ID 9, got %d (%v)", keyID, err)
+		}
+		scopes, err := GetScopes(ctx)
+		if err != nil || len(scopes) != 1 || scopes[0] != "orders:read" {
+			t.Errorf("Expected scopes [orders:read], got %v (%v)", scopes, err)
+		}
+
+		// Test without an API key
+		if _, err := GetAPIKeyID(context.Background()); err != ErrNoAPIKeyID {
+			t.Errorf("Expected error %v, got %v", ErrNoAPIKeyID, err)
+		}
+		if _, err := GetScopes(context.Background()); err != ErrNoScopes {
+			t.Errorf("Expected error %v, got %v", ErrNoScopes, err)
+		}
+	})
+
 	t.Run("Roles", func(t *testing.T) {
 		// Test with valid roles
 		roles := []Role{RoleAdmin, RoleTenantSuper}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+)
+
+// AuditingRoleService decorates a RoleService, recording tenant role changes in
+// the tenant's audit log. Failing to record an entry does not fail the change.
+type AuditingRoleService struct {
+	RoleService
+	recorder auditservice.AuditRecorder
+}
+
+// Ensure AuditingRoleService implements RoleService
+var _ RoleService = (*AuditingRoleService)(nil)
+
+// NewAuditingRoleService creates a new AuditingRoleService
+func NewAuditingRoleService(roleService RoleService, recorder auditservice.AuditRecorder) *AuditingRoleService {
+	return &AuditingRoleService{
+		RoleService: roleService,
+		recorder:    recorder,
+	}
+}
+
+// AssignTenantRole assigns a tenant-specific role and records it in the audit log
+func (s *AuditingRoleService) AssignTenantRole(ctx context.Context, userID int64, tenantID int64, roleID int64) error {
+	if err := s.RoleService.AssignTenantRole(ctx, userID, tenantID, roleID); err != nil {
+		return err
+	}
+	s.record(ctx, auditservice.ActionRoleAssigned, userID, tenantID, roleID)
+	return nil
+}
+
+// RevokeTenantRole revokes a tenant-specific role and records it in the audit log
+func (s *AuditingRoleService) RevokeTenantRole(ctx context.Context, userID int64, tenantID int64, roleID int64) error {
+	if err := s.RoleService.RevokeTenantRole(ctx, userID, tenantID, roleID); err != nil {
+		return err
+	}
+	s.record(ctx, auditservice.ActionRoleRevoked, userID, tenantID, roleID)
+	return nil
+}
+
+// ReplaceTenantRoles replaces a user's tenant-specific roles and records each
+// assignment and revocation in the audit log
+func (s *AuditingRoleService) ReplaceTenantRoles(ctx context.Context, userID int64, tenantID int64, roleIDs []int64) (*TenantRoleChanges, error) {
+	changes, err := s.RoleService.ReplaceTenantRoles(ctx, userID, tenantID, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, roleID := range changes.Revoked {
+		s.record(ctx, auditservice.ActionRoleRevoked, userID, tenantID, roleID)
+	}
+	for _, roleID := range changes.Assigned {
+		s.record(ctx, auditservice.ActionRoleAssigned, userID, tenantID, roleID)
+	}
+	return changes, nil
+}
+
+// record writes an audit entry for a tenant role change, logging rather than returning failures
+func (s *AuditingRoleService) record(ctx context.Context, action string, userID int64, tenantID int64, roleID int64) {
+	entry := auditservice.AuditEntry{
+		TenantID:     tenantID,
+		Action:       action,
+		ResourceType: auditservice.ResourceRole,
+		ResourceID:   strconv.FormatInt(roleID, 10),
+		Details: map[string]interface{}{
+			"user_id": userID,
+		},
+	}
+	if err := s.recorder.Record(ctx, entry); err != nil {
+		log.Printf("[WARN] Failed to record %s audit entry for role %d in tenant %d: %v", action, roleID, tenantID, err)
+	}
+}
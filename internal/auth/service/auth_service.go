@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
 	"errors"
@@ -9,6 +10,7 @@ import (
 	"log"
 	"strings"
 
+	"github.com/unsavory/silocore-go/internal/auth/authz"
 	authctx "github.com/unsavory/silocore-go/internal/auth/context"
 	"github.com/unsavory/silocore-go/internal/auth/jwt"
 	"golang.org/x/crypto/scrypt"
@@ -61,6 +63,7 @@ type DefaultAuthService struct {
 	userService         UserService
 	tenantMemberService TenantMemberService
 	jwtService          jwt.JWTService
+	authorizer          authz.Authorizer
 }
 
 // NewDefaultAuthService creates a new DefaultAuthService
@@ -69,9 +72,18 @@ func NewDefaultAuthService(userService UserService, tenantMemberService TenantMe
 		userService:         userService,
 		tenantMemberService: tenantMemberService,
 		jwtService:          jwtService,
+		authorizer:          authz.NewRoleAuthorizer(),
 	}
 }
 
+// WithAuthorizer replaces the default role-based authorizer used by ValidateAccess
+func (s *DefaultAuthService) WithAuthorizer(authorizer authz.Authorizer) *DefaultAuthService {
+	if authorizer != nil {
+		s.authorizer = authorizer
+	}
+	return s
+}
+
 // Login authenticates a user with email and password
 func (s *DefaultAuthService) Login(ctx context.Context, email, password string) (*jwt.TokenPair, int64, error) {
 	return s.loginWithVerifier(ctx, email, password, VerifyPassword)
@@ -172,50 +184,36 @@ func (s *DefaultAuthService) ValidateAccess(ctx context.Context, userID int64, t
 		return fmt.Errorf("failed to get user roles: %w", err)
 	}
 
-	// Admin role has access to everything
-	for _, role := range systemRoles {
-		if role == authctx.RoleAdmin {
-			return nil
-		}
+	req := authz.Request{
+		UserID:        userID,
+		TenantID:      tenantID,
+		Roles:         systemRoles,
+		RequiredRoles: requiredRoles,
 	}
 
-	// If tenant-specific access is required
-	if tenantID != nil {
+	// Gather tenant facts unless the user is an admin, who has access to everything
+	if tenantID != nil && !req.HasRole(authctx.RoleAdmin) {
 		// Check if user is a member of the tenant
-		isMember, err := s.tenantMemberService.IsTenantMember(ctx, userID, *tenantID)
+		req.IsTenantMember, err = s.tenantMemberService.IsTenantMember(ctx, userID, *tenantID)
 		if err != nil {
 			return fmt.Errorf("failed to check tenant membership: %w", err)
 		}
 
-		if !isMember {
-			return ErrUnauthorized
-		}
-
-		// If specific roles are required, check tenant-specific roles
-		if len(requiredRoles) > 0 {
+		// If specific roles are required, include tenant-specific roles
+		if req.IsTenantMember && len(requiredRoles) > 0 {
 			tenantRoles, err := s.userService.GetUserTenantRoles(ctx, userID, *tenantID)
 			if err != nil {
 				return fmt.Errorf("failed to get tenant roles: %w", err)
 			}
+			req.Roles = append(append([]authctx.Role{}, systemRoles...), tenantRoles...)
+		}
+	}
 
-			// Check if user has any of the required roles
-			hasRequiredRole := false
-			for _, required := range requiredRoles {
-				for _, role := range tenantRoles {
-					if role == required {
-						hasRequiredRole = true
-						break
-					}
-				}
-				if hasRequiredRole {
-					break
-				}
-			}
-
-			if !hasRequiredRole {
-				return ErrUnauthorized
-			}
+	if err := s.authorizer.Authorize(ctx, req); err != nil {
+		if errors.Is(err, authz.ErrForbidden) {
+			return ErrUnauthorized
 		}
+		return fmt.Errorf("failed to authorize access: %w", err)
 	}
 
 	return nil
@@ -269,6 +267,22 @@ func ValidatePassword(password string) error {
 	return nil
 }
 
+// HashPassword hashes a password with scrypt and a random salt, in the format
+// VerifyPassword checks: base64(salt):base64(hash)
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %w", err)
+	}
+
+	hashedPassword, err := scrypt.Key([]byte(password), salt, ScryptN, ScryptR, ScryptP, ScryptKeyLen)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(salt) + ":" + base64.StdEncoding.EncodeToString(hashedPassword), nil
+}
+
 // VerifyPassword verifies a password against a stored hash
 func VerifyPassword(storedHash, password string) (bool, error) {
 	// Split the stored hash into salt and hash components
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+// Cache key prefix for user roles
+const userRolesKeyPrefix = "roles:user:"
+
+// RoleCacheInvalidator invalidates cached role lookups
+type RoleCacheInvalidator interface {
+	// InvalidateUserRoles removes all cached roles for a user
+	InvalidateUserRoles(ctx context.Context, userID int64) error
+
+	// InvalidateAllRoles removes all cached roles, e.g. after the role hierarchy changes
+	InvalidateAllRoles(ctx context.Context) error
+}
+
+// CachingUserService decorates a UserService, caching role lookups that run
+// on every request
+type CachingUserService struct {
+	UserService
+	cache cache.Cache
+}
+
+// Ensure CachingUserService implements UserService and RoleCacheInvalidator
+var (
+	_ UserService          = (*CachingUserService)(nil)
+	_ RoleCacheInvalidator = (*CachingUserService)(nil)
+)
+
+// NewCachingUserService creates a new CachingUserService
+func NewCachingUserService(userService UserService, c cache.Cache) *CachingUserService {
+	return &CachingUserService{
+		UserService: userService,
+		cache:       c,
+	}
+}
+
+// GetUserRoles retrieves system-wide roles for a user, using the cache when possible
+func (s *CachingUserService) GetUserRoles(ctx context.Context, userID int64) ([]authctx.Role, error) {
+	key := fmt.Sprintf("%s%d:system", userRolesKeyPrefix, userID)
+	return s.cachedRoles(ctx, key, func() ([]authctx.Role, error) {
+		return s.UserService.GetUserRoles(ctx, userID)
+	})
+}
+
+// GetUserTenantRoles retrieves tenant-specific roles for a user, using the cache when possible
+func (s *CachingUserService) GetUserTenantRoles(ctx context.Context, userID int64, tenantID int64) ([]authctx.Role, error) {
+	key := fmt.Sprintf("%s%d:tenant:%d", userRolesKeyPrefix, userID, tenantID)
+	return s.cachedRoles(ctx, key, func() ([]authctx.Role, error) {
+		return s.UserService.GetUserTenantRoles(ctx, userID, tenantID)
+	})
+}
+
+// InvalidateUserRoles removes all cached roles for a user
+func (s *CachingUserService) InvalidateUserRoles(ctx context.Context, userID int64) error {
+	return s.cache.DeletePrefix(ctx, fmt.Sprintf("%s%d:", userRolesKeyPrefix, userID))
+}
+
+// InvalidateAllRoles removes all cached roles
+func (s *CachingUserService) InvalidateAllRoles(ctx context.Context) error {
+	return s.cache.DeletePrefix(ctx, userRolesKeyPrefix)
+}
+
+// cachedRoles returns the roles cached under key, loading and caching them on a miss.
+// Cache failures are logged and fall through to the underlying service.
+func (s *CachingUserService) cachedRoles(ctx context.Context, key string, load func() ([]authctx.Role, error)) ([]authctx.Role, error) {
+	data, found, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] Failed to read %s from cache: %v", key, err)
+	} else if found {
+		var roles []authctx.Role
+		if err := json.Unmarshal(data, &roles); err == nil {
+			return roles, nil
+		}
+		log.Printf("[WARN] Discarding malformed cache entry %s", key)
+	}
+
+	roles, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(roles); err == nil {
+		if err := s.cache.Set(ctx, key, data, 0); err != nil {
+			log.Printf("[WARN] Failed to write %s to cache: %v", key, err)
+		}
+	}
+
+	return roles, nil
+}
+
+// InvalidatingRoleService decorates a RoleService, invalidating cached roles
+// whenever role assignments or the role hierarchy change
+type InvalidatingRoleService struct {
+	RoleService
+	invalidator RoleCacheInvalidator
+}
+
+// Ensure InvalidatingRoleService implements RoleService
+var _ RoleService = (*InvalidatingRoleService)(nil)
+
+// NewInvalidatingRoleService creates a new InvalidatingRoleService
+func NewInvalidatingRoleService(roleService RoleService, invalidator RoleCacheInvalidator) *InvalidatingRoleService {
+	return &InvalidatingRoleService{
+		RoleService: roleService,
+		invalidator: invalidator,
+	}
+}
+
+// UpdateRole updates a role and invalidates all cached roles
+func (s *InvalidatingRoleService) UpdateRole(ctx context.Context, role *Role) error {
+	if err := s.RoleService.UpdateRole(ctx, role); err != nil {
+		return err
+	}
+	s.invalidateAll(ctx)
+	return nil
+}
+
+// DeleteRole deletes a role and invalidates all cached roles
+func (s *InvalidatingRoleService) DeleteRole(ctx context.Context, roleID int64) error {
+	if err := s.RoleService.DeleteRole(ctx, roleID); err != nil {
+		return err
+	}
+	s.invalidateAll(ctx)
+	return nil
+}
+
+// AssignUserRole assigns a system-wide role and invalidates the user's cached roles
+func (s *InvalidatingRoleService) AssignUserRole(ctx context.Context, userID int64, roleID int64) error {
+	if err := s.RoleService.AssignUserRole(ctx, userID, roleID); err != nil {
+		return err
+	}
+	s.invalidateUser(ctx, userID)
+	return nil
+}
+
+// RevokeUserRole revokes a system-wide role and invalidates the user's cached roles
+func (s *InvalidatingRoleService) RevokeUserRole(ctx context.Context, userID int64, roleID int64) error {
+	if err := s.RoleService.RevokeUserRole(ctx, userID, roleID); err != nil {
+		return err
+	}
+	s.invalidateUser(ctx, userID)
+	return nil
+}
+
+// AssignTenantRole assigns a tenant-specific role and invalidates the user's cached roles
+func (s *InvalidatingRoleService) AssignTenantRole(ctx context.Context, userID int64, tenantID int64, roleID int64) error {
+	if err := s.RoleService.AssignTenantRole(ctx, userID, tenantID, roleID); err != nil {
+		return err
+	}
+	s.invalidateUser(ctx, userID)
+	return nil
+}
+
+// RevokeTenantRole revokes a tenant-specific role and invalidates the user's cached roles
+func (s *InvalidatingRoleService) RevokeTenantRole(ctx context.Context, userID int64, tenantID int64, roleID int64) error {
+	if err := s.RoleService.RevokeTenantRole(ctx, userID, tenantID, roleID); err != nil {
+		return err
+	}
+	s.invalidateUser(ctx, userID)
+	return nil
+}
+
+// ReplaceTenantRoles replaces tenant-specific roles and invalidates the user's cached roles
+func (s *InvalidatingRoleService) ReplaceTenantRoles(ctx context.Context, userID int64, tenantID int64, roleIDs []int64) (*TenantRoleChanges, error) {
+	changes, err := s.RoleService.ReplaceTenantRoles(ctx, userID, tenantID, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateUser(ctx, userID)
+	return changes, nil
+}
+
+// AddRoleInheritance adds an inheritance relationship and invalidates all cached roles
+func (s *InvalidatingRoleService) AddRoleInheritance(ctx context.Context, roleID int64, inheritedRoleID int64) error {
+	if err := s.RoleService.AddRoleInheritance(ctx, roleID, inheritedRoleID); err != nil {
+		return err
+	}
+	s.invalidateAll(ctx)
+	return nil
+}
+
+// RemoveRoleInheritance removes an inheritance relationship and invalidates all cached roles
+func (s *InvalidatingRoleService) RemoveRoleInheritance(ctx context.Context, roleID int64, inheritedRoleID int64) error {
+	if err := s.RoleService.RemoveRoleInheritance(ctx, roleID, inheritedRoleID); err != nil {
+		return err
+	}
+	s.invalidateAll(ctx)
+	return nil
+}
+
+// invalidateUser invalidates a user's cached roles, logging failures
+func (s *InvalidatingRoleService) invalidateUser(ctx context.Context, userID int64) {
+	if err := s.invalidator.InvalidateUserRoles(ctx, userID); err != nil {
+		log.Printf("[ERROR] Failed to invalidate cached roles for user ID %d: %v", userID, err)
+	}
+}
+
+// invalidateAll invalidates all cached roles, logging failures
+func (s *InvalidatingRoleService) invalidateAll(ctx context.Context) {
+	if err := s.invalidator.InvalidateAllRoles(ctx); err != nil {
+		log.Printf("[ERROR] Failed to invalidate cached roles: %v", err)
+	}
+}
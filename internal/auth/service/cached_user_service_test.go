@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+func TestCachingUserService(t *testing.T) {
+	ctx := context.Background()
+	userID := int64(1)
+	tenantID := int64(2)
+
+	t.Run("Caches system and tenant roles", func(t *testing.T) {
+		mockUserService := new(MockUserService)
+		mockUserService.On("GetUserRoles", ctx, userID).Return([]authctx.Role{authctx.RoleInternal}, nil).Once()
+		mockUserService.On("GetUserTenantRoles", ctx, userID, tenantID).Return([]authctx.Role{authctx.RoleTenantSuper}, nil).Once()
+
+		service := NewCachingUserService(mockUserService, cache.NewLRUCache(100, time.Minute))
+
+		for i := 0; i < 2; i++ {
+			roles, err := service.GetUserRoles(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, []authctx.Role{authctx.RoleInternal}, roles)
+
+			tenantRoles, err := service.GetUserTenantRoles(ctx, userID, tenantID)
+			require.NoError(t, err)
+			assert.Equal(t, []authctx.Role{authctx.RoleTenantSuper}, tenantRoles)
+		}
+
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Reloads roles after invalidation", func(t *testing.T) {
+		mockUserService := new(MockUserService)
+		mockUserService.On("GetUserRoles", ctx, userID).Return([]authctx.Role{}, nil).Once()
+		mockUserService.On("GetUserRoles", ctx, userID).Return([]authctx.Role{authctx.RoleAdmin}, nil).Once()
+
+		service := NewCachingUserService(mockUserService, cache.NewLRUCache(100, time.Minute))
+
+		roles, err := service.GetUserRoles(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+
+		require.NoError(t, service.InvalidateUserRoles(ctx, userID))
+
+		roles, err = service.GetUserRoles(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, []authctx.Role{authctx.RoleAdmin}, roles)
+
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Does not cache errors", func(t *testing.T) {
+		mockUserService := new(MockUserService)
+		mockUserService.On("GetUserRoles", ctx, userID).Return([]authctx.Role(nil), ErrDBOperation).Once()
+		mockUserService.On("GetUserRoles", ctx, userID).Return([]authctx.Role{authctx.RoleInternal}, nil).Once()
+
+		service := NewCachingUserService(mockUserService, cache.NewLRUCache(100, time.Minute))
+
+		_, err := service.GetUserRoles(ctx, userID)
+		assert.ErrorIs(t, err, ErrDBOperation)
+
+		roles, err := service.GetUserRoles(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, []authctx.Role{authctx.RoleInternal}, roles)
+	})
+}
+
+// recordingInvalidator records cache invalidations
+type recordingInvalidator struct {
+	users []int64
+	all   int
+}
+
+func (r *recordingInvalidator) InvalidateUserRoles(ctx context.Context, userID int64) error {
+	r.users = append(r.users, userID)
+	return nil
+}
+
+func (r *recordingInvalidator) InvalidateAllRoles(ctx context.Context) error {
+	r.all++
+	return nil
+}
+
+func TestInvalidatingRoleService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Assignment invalidates the user", func(t *testing.T) {
+		mock, roleService, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		invalidator := &recordingInvalidator{}
+		service := NewInvalidatingRoleService(roleService, invalidator)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO user_role").
+			WithArgs(int64(5), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, service.AssignUserRole(ctx, 5, 1))
+		assert.Equal(t, []int64{5}, invalidator.users)
+		assert.Equal(t, 0, invalidator.all)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed assignment does not invalidate", func(t *testing.T) {
+		mock, roleService, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		invalidator := &recordingInvalidator{}
+		service := NewInvalidatingRoleService(roleService, invalidator)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO user_role").
+			WithArgs(int64(5), int64(1)).
+			WillReturnError(errors.New("connection refused"))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, service.AssignUserRole(ctx, 5, 1), ErrDBOperation)
+		assert.Empty(t, invalidator.users)
+	})
+}
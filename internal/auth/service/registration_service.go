@@ -2,41 +2,113 @@ package service
 
 import (
 	"context"
-	"crypto/rand"
 	"database/sql"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
-	"golang.org/x/crypto/scrypt"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
 )
 
 // Registration errors
 var (
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrRegistrationFailed = errors.New("registration failed")
+	ErrInvalidInvitation  = errors.New("invitation is invalid or has expired")
 )
 
+// registrationAttempts is how many times creating a user is attempted when it
+// deadlocks or fails to serialize with concurrent registrations, such as ones
+// accepting invitations to the same tenant
+const registrationAttempts = 3
+
+// envRegistrationDefaultRoles is the environment variable listing default system roles
+const envRegistrationDefaultRoles = "REGISTRATION_DEFAULT_ROLES"
+
+// DefaultRolesFromEnv returns the default system roles for new users, read from
+// REGISTRATION_DEFAULT_ROLES as a comma-separated list of role names
+func DefaultRolesFromEnv() []authctx.Role {
+	var roles []authctx.Role
+	for _, name := range strings.Split(os.Getenv(envRegistrationDefaultRoles), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			roles = append(roles, authctx.Role(strings.ToUpper(name)))
+		}
+	}
+	return roles
+}
+
 // RegistrationService defines the interface for user registration
 type RegistrationService interface {
 	// RegisterUser registers a new user
 	RegisterUser(ctx context.Context, firstName, lastName, email, password string) (int64, error)
+
+	// RegisterUserWithInvitation registers a new user and adds them to the
+	// inviting tenant with the invitation's role
+	RegisterUserWithInvitation(ctx context.Context, firstName, lastName, email, password, invitationToken string) (int64, error)
+}
+
+// MemberQuotaChecker checks whether a user can join a tenant within its member limit
+type MemberQuotaChecker interface {
+	CheckMemberQuota(ctx context.Context, tenantID int64, userID int64) error
 }
 
 // DBRegistrationService implements RegistrationService using a database
 type DBRegistrationService struct {
-	db *sql.DB
+	db           *sql.DB
+	txManager    transaction.TxManager
+	defaultRoles []authctx.Role
+	memberQuota  MemberQuotaChecker
 }
 
 // NewDBRegistrationService creates a new DBRegistrationService
 func NewDBRegistrationService(db *sql.DB) *DBRegistrationService {
-	return &DBRegistrationService{db: db}
+	return &DBRegistrationService{
+		db:        db,
+		txManager: transaction.NewManager(db),
+	}
+}
+
+// WithTxManager creates users in the transactions of txManager, such as the
+// factory's shared manager or a FakeManager in tests
+func (s *DBRegistrationService) WithTxManager(txManager transaction.TxManager) *DBRegistrationService {
+	s.txManager = txManager
+	return s
+}
+
+// WithDefaultRoles sets the system roles assigned to every new user
+func (s *DBRegistrationService) WithDefaultRoles(roles ...authctx.Role) *DBRegistrationService {
+	s.defaultRoles = roles
+	return s
+}
+
+// WithMemberQuota rejects registrations by invitation to tenants that have
+// reached their member limit. The limit is checked in the registration's
+// transaction, so concurrent registrations can't both pass it.
+func (s *DBRegistrationService) WithMemberQuota(quota MemberQuotaChecker) *DBRegistrationService {
+	s.memberQuota = quota
+	return s
 }
 
 // RegisterUser registers a new user
 func (s *DBRegistrationService) RegisterUser(ctx context.Context, firstName, lastName, email, password string) (int64, error) {
+	return s.register(ctx, firstName, lastName, email, password, "")
+}
+
+// RegisterUserWithInvitation registers a new user and accepts a tenant invitation
+func (s *DBRegistrationService) RegisterUserWithInvitation(ctx context.Context, firstName, lastName, email, password, invitationToken string) (int64, error) {
+	if invitationToken == "" {
+		return 0, ErrInvalidInvitation
+	}
+	return s.register(ctx, firstName, lastName, email, password, invitationToken)
+}
+
+// register creates the user, assigns default roles and accepts the invitation if given
+func (s *DBRegistrationService) register(ctx context.Context, firstName, lastName, email, password, invitationToken string) (int64, error) {
 	// Check if email already exists
 	var exists bool
 	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM usr WHERE email = $1)", email).Scan(&exists)
@@ -54,34 +126,35 @@ func (s *DBRegistrationService) RegisterUser(ctx context.Context, firstName, las
 		return 0, err
 	}
 
-	// Generate a random salt
-	salt := make([]byte, SaltSize)
-	_, err = rand.Read(salt)
+	// Hash the password
+	passwordHash, err := HashPassword(password)
 	if err != nil {
-		log.Printf("Error generating salt: %v", err)
+		log.Printf("Error hashing password: %v", err)
 		return 0, fmt.Errorf("%w: %v", ErrRegistrationFailed, err)
 	}
 
-	// Hash the password using scrypt
-	hashedPassword, err := scrypt.Key([]byte(password), salt, ScryptN, ScryptR, ScryptP, ScryptKeyLen)
+	// Create the user, retrying if the transaction deadlocks or loses its connection
+	var userID int64
+	err = s.txManager.WithTransactionRetry(ctx, registrationAttempts, func(ctx context.Context) (err error) {
+		userID, err = s.createUser(ctx, firstName, lastName, email, passwordHash, invitationToken)
+		return err
+	})
 	if err != nil {
-		log.Printf("Error hashing password: %v", err)
-		return 0, fmt.Errorf("%w: %v", ErrRegistrationFailed, err)
+		return 0, err
 	}
 
-	// Encode the salt and hashed password for storage
-	// Format: base64(salt):base64(hash)
-	saltBase64 := base64.StdEncoding.EncodeToString(salt)
-	hashBase64 := base64.StdEncoding.EncodeToString(hashedPassword)
-	passwordHash := fmt.Sprintf("%s:%s", saltBase64, hashBase64)
+	return userID, nil
+}
 
-	// Begin transaction
-	tx, err := s.db.BeginTx(ctx, nil)
+// createUser inserts a user with its default roles in the transaction in the
+// context, accepting the invitation if given
+func (s *DBRegistrationService) createUser(ctx context.Context, firstName, lastName, email, passwordHash, invitationToken string) (int64, error) {
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
 	if err != nil {
-		log.Printf("Error beginning transaction: %v", err)
-		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		log.Printf("Error getting transaction: %v", err)
+		return 0, fmt.Errorf("%w: %w", ErrDBOperation, err)
 	}
-	defer tx.Rollback()
 
 	// Insert user - using the correct column names from the database schema
 	var userID int64
@@ -96,14 +169,106 @@ func (s *DBRegistrationService) RegisterUser(ctx context.Context, firstName, las
 
 	if err != nil {
 		log.Printf("Error inserting user: %v", err)
-		return 0, fmt.Errorf("%w: %v", ErrRegistrationFailed, err)
+		return 0, fmt.Errorf("%w: %w", ErrRegistrationFailed, err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err)
-		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	// Assign default system roles
+	for _, role := range s.defaultRoles {
+		if err := assignRoleByName(ctx, tx, userID, role); err != nil {
+			return 0, err
+		}
+	}
+
+	// Join the inviting tenant
+	if invitationToken != "" {
+		if err := s.acceptInvitation(ctx, tx, userID, email, invitationToken); err != nil {
+			return 0, err
+		}
 	}
 
 	return userID, nil
 }
+
+// assignRoleByName assigns a system-wide role to a new user
+func assignRoleByName(ctx context.Context, tx *sql.Tx, userID int64, role authctx.Role) error {
+	var roleID int64
+	err := tx.QueryRowContext(ctx, "SELECT id FROM role WHERE name = $1", string(role)).Scan(&roleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[WARN] Default role %s does not exist, skipping", role)
+			return nil
+		}
+		return fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO user_role (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", userID, roleID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+
+	return recordRoleAudit(ctx, tx, RoleAuditAssign, userID, nil, roleID)
+}
+
+// acceptInvitation adds a new user to the tenant that invited them, with the
+// invitation's tenant role, and marks the invitation as accepted
+func (s *DBRegistrationService) acceptInvitation(ctx context.Context, tx *sql.Tx, userID int64, email, token string) error {
+	var invitationID, tenantID int64
+	var roleID sql.NullInt64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, tenant_id, role_id
+		FROM tenant_invitation
+		WHERE token_hash = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, tenantservice.HashToken(token), email).Scan(&invitationID, &tenantID, &roleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[WARN] Registration for %s with invalid or expired invitation", email)
+			return ErrInvalidInvitation
+		}
+		return fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+
+	// Invitations created before their role was checked may carry a platform
+	// role, which would be granted within the tenant
+	if roleID.Valid {
+		var roleName string
+		err = tx.QueryRowContext(ctx, "SELECT name FROM role WHERE id = $1", roleID.Int64).Scan(&roleName)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %w", ErrDBOperation, err)
+		}
+		if err != nil || !IsTenantAssignableRole(roleName) {
+			log.Printf("[WARN] Registration for %s with invitation %d for role %d, which cannot be assigned within a tenant", email, invitationID, roleID.Int64)
+			return ErrInvalidInvitation
+		}
+	}
+
+	if s.memberQuota != nil {
+		if err := s.memberQuota.CheckMemberQuota(ctx, tenantID, userID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO tenant_member (user_id, tenant_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", userID, tenantID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+
+	if roleID.Valid {
+		_, err = tx.ExecContext(ctx, "INSERT INTO tenant_role (user_id, tenant_id, role_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING", userID, tenantID, roleID.Int64)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDBOperation, err)
+		}
+
+		if err := recordRoleAudit(ctx, tx, RoleAuditAssign, userID, &tenantID, roleID.Int64); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE tenant_invitation SET accepted_at = NOW(), accepted_user_id = $1 WHERE id = $2", userID, invitationID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] User %d accepted invitation %d to tenant %d", userID, invitationID, tenantID)
+	return nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+const testPassword = "Str0ng!Passw0rd"
+
+// expectUserInsert sets up the expectations for creating a user row
+func expectUserInsert(mock sqlmock.Sqlmock, email string, userID int64) {
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM usr WHERE email = \\$1\\)").
+		WithArgs(email).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO usr").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+}
+
+func TestRegisterUserDefaultRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	email := "new@example.com"
+	userID := int64(42)
+
+	service := NewDBRegistrationService(db).WithDefaultRoles(authctx.RoleInternal, "MISSING")
+
+	expectUserInsert(mock, email, userID)
+	mock.ExpectQuery("SELECT id FROM role WHERE name = \\$1").
+		WithArgs("INTERNAL").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO user_role").
+		WithArgs(userID, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO role_audit").
+		WithArgs(nil, userID, nil, "assign", int64(2)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Unknown default roles are skipped
+	mock.ExpectQuery("SELECT id FROM role WHERE name = \\$1").
+		WithArgs("MISSING").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	id, err := service.RegisterUser(ctx, "New", "User", email, testPassword)
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterUserRetriesSerializationFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	email := "new@example.com"
+	service := NewDBRegistrationService(db).WithDefaultRoles()
+
+	// Expect the first transaction to fail and be rolled back
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM usr WHERE email = \\$1\\)").
+		WithArgs(email).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO usr").
+		WillReturnError(&pgconn.PgError{Code: database.CodeSerializationFailure})
+	mock.ExpectRollback()
+
+	// Expect the retry to create the user
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO usr").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(42))
+	mock.ExpectCommit()
+
+	id, err := service.RegisterUser(context.Background(), "New", "User", email, testPassword)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterUserWithInvitation(t *testing.T) {
+	ctx := context.Background()
+	email := "invited@example.com"
+	userID := int64(42)
+	tenantID := int64(7)
+	token := "invitation-token"
+
+	t.Run("Joins the inviting tenant with the invitation role", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectUserInsert(mock, email, userID)
+		mock.ExpectQuery("SELECT id, tenant_id, role_id FROM tenant_invitation").
+			WithArgs(tenantservice.HashToken(token), email).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "role_id"}).AddRow(3, tenantID, 4))
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(string(authctx.RoleTenantSuper)))
+		mock.ExpectExec("INSERT INTO tenant_member").
+			WithArgs(userID, tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO tenant_role").
+			WithArgs(userID, tenantID, int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(nil, userID, &tenantID, "assign", int64(4)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE tenant_invitation SET accepted_at = NOW\\(\\)").
+			WithArgs(userID, int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		id, err := NewDBRegistrationService(db).RegisterUserWithInvitation(ctx, "Invited", "User", email, testPassword, token)
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invitation with a platform role rejected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectUserInsert(mock, email, userID)
+		mock.ExpectQuery("SELECT id, tenant_id, role_id FROM tenant_invitation").
+			WithArgs(tenantservice.HashToken(token), email).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "role_id"}).AddRow(3, tenantID, 1))
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(string(authctx.RoleAdmin)))
+		mock.ExpectRollback()
+
+		_, err = NewDBRegistrationService(db).RegisterUserWithInvitation(ctx, "Invited", "User", email, testPassword, token)
+
+		assert.True(t, errors.Is(err, ErrInvalidInvitation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tenant at its member limit rolls back the registration", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectUserInsert(mock, email, userID)
+		mock.ExpectQuery("SELECT id, tenant_id, role_id FROM tenant_invitation").
+			WithArgs(tenantservice.HashToken(token), email).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "role_id"}).AddRow(3, tenantID, nil))
+		mock.ExpectQuery("SELECT max_members, max_orders, max_api_requests, max_storage_bytes, updated_at FROM tenant_quota WHERE tenant_id = \\$1 FOR UPDATE").
+			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"max_members", "max_orders", "max_api_requests", "max_storage_bytes", "updated_at"}).
+				AddRow(1, nil, nil, nil, time.Now()))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant_member").
+			WithArgs(tenantID, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		service := NewDBRegistrationService(db).WithMemberQuota(tenantservice.NewDBQuotaService(db))
+		_, err = service.RegisterUserWithInvitation(ctx, "Invited", "User", email, testPassword, token)
+
+		assert.True(t, errors.Is(err, tenantservice.ErrQuotaExceeded))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid invitation rolls back the registration", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectUserInsert(mock, email, userID)
+		mock.ExpectQuery("SELECT id, tenant_id, role_id FROM tenant_invitation").
+			WithArgs(tenantservice.HashToken(token), email).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "role_id"}))
+		mock.ExpectRollback()
+
+		_, err = NewDBRegistrationService(db).RegisterUserWithInvitation(ctx, "Invited", "User", email, testPassword, token)
+
+		assert.True(t, errors.Is(err, ErrInvalidInvitation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDefaultRolesFromEnv(t *testing.T) {
+	t.Setenv("REGISTRATION_DEFAULT_ROLES", " internal, ,TENANT_MEMBER")
+	assert.Equal(t, []authctx.Role{authctx.RoleInternal, authctx.RoleTenantMember}, DefaultRolesFromEnv())
+
+	t.Setenv("REGISTRATION_DEFAULT_ROLES", "")
+	assert.Empty(t, DefaultRolesFromEnv())
+}
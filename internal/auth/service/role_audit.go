@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// RoleAuditAction is the kind of role change recorded in the audit trail
+type RoleAuditAction string
+
+// Role audit actions
+const (
+	RoleAuditAssign RoleAuditAction = "assign"
+	RoleAuditRevoke RoleAuditAction = "revoke"
+)
+
+// defaultRoleAuditLimit is the number of audit entries returned when no limit is given
+const defaultRoleAuditLimit = 100
+
+// RoleAuditEntry is a record of a role being assigned to or revoked from a user
+type RoleAuditEntry struct {
+	ID           int64           `json:"id"`
+	ActorUserID  *int64          `json:"actor_user_id"`
+	TargetUserID *int64          `json:"target_user_id"`
+	RoleID       *int64          `json:"role_id"`
+	RoleName     string          `json:"role_name"`
+	TenantID     *int64          `json:"tenant_id,omitempty"`
+	Action       RoleAuditAction `json:"action"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// RoleAuditFilter defines filters for querying the role audit trail
+type RoleAuditFilter struct {
+	ActorUserID  *int64
+	TargetUserID *int64
+	TenantID     *int64
+	Limit        int
+	Offset       int
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordRoleAudit writes an audit record for a role change. The acting user is
+// taken from the context and is nil for changes made outside a request.
+func recordRoleAudit(ctx context.Context, exec execer, action RoleAuditAction, userID int64, tenantID *int64, roleID int64) error {
+	var actorID *int64
+	if id, err := authctx.GetUserID(ctx); err == nil {
+		actorID = &id
+	}
+
+	query := `
+		INSERT INTO role_audit (actor_user_id, target_user_id, role_id, role_name, tenant_id, action)
+		SELECT $1, $2, id, name, $3, $4
+		FROM role
+		WHERE id = $5
+	`
+
+	if _, err := exec.ExecContext(ctx, query, actorID, userID, tenantID, string(action), roleID); err != nil {
+		return fmt.Errorf("%w: failed to record role audit: %v", ErrDBOperation, err)
+	}
+
+	return nil
+}
+
+// GetRoleAuditLog retrieves role audit entries, newest first
+func (s *DBRoleService) GetRoleAuditLog(ctx context.Context, filter RoleAuditFilter) ([]RoleAuditEntry, error) {
+	query := `
+		SELECT id, actor_user_id, target_user_id, role_id, role_name, tenant_id, action, created_at
+		FROM role_audit
+	`
+
+	var conditions []string
+	var args []interface{}
+
+	if filter.ActorUserID != nil {
+		args = append(args, *filter.ActorUserID)
+		conditions = append(conditions, fmt.Sprintf("actor_user_id = $%d", len(args)))
+	}
+
+	if filter.TargetUserID != nil {
+		args = append(args, *filter.TargetUserID)
+		conditions = append(conditions, fmt.Sprintf("target_user_id = $%d", len(args)))
+	}
+
+	if filter.TenantID != nil {
+		args = append(args, *filter.TenantID)
+		conditions = append(conditions, fmt.Sprintf("tenant_id = $%d", len(args)))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultRoleAuditLimit
+	}
+
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	entries := []RoleAuditEntry{}
+	for rows.Next() {
+		var entry RoleAuditEntry
+		var actorID, targetID, roleID, tenantID sql.NullInt64
+		var action string
+		if err := rows.Scan(
+			&entry.ID,
+			&actorID,
+			&targetID,
+			&roleID,
+			&entry.RoleName,
+			&tenantID,
+			&action,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		entry.ActorUserID = nullInt64Ptr(actorID)
+		entry.TargetUserID = nullInt64Ptr(targetID)
+		entry.RoleID = nullInt64Ptr(roleID)
+		entry.TenantID = nullInt64Ptr(tenantID)
+		entry.Action = RoleAuditAction(action)
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return entries, nil
+}
+
+// nullInt64Ptr converts a nullable integer to a pointer
+func nullInt64Ptr(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
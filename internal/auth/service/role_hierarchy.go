@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// ErrRoleCycle is returned when a role inheritance configuration contains a cycle
+var ErrRoleCycle = errors.New("role inheritance cycle detected")
+
+// RoleHierarchy describes which roles inherit the permissions of other roles.
+// A user holding a role implicitly holds every role it inherits, transitively.
+type RoleHierarchy struct {
+	inherits map[authctx.Role][]authctx.Role
+}
+
+// NewRoleHierarchy creates a RoleHierarchy from a map of role to inherited roles,
+// rejecting configurations that contain cycles
+func NewRoleHierarchy(inherits map[authctx.Role][]authctx.Role) (*RoleHierarchy, error) {
+	h := &RoleHierarchy{inherits: make(map[authctx.Role][]authctx.Role, len(inherits))}
+	for role, inherited := range inherits {
+		h.inherits[role] = append([]authctx.Role(nil), inherited...)
+	}
+
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Inherits returns the roles directly inherited by the given role
+func (h *RoleHierarchy) Inherits(role authctx.Role) []authctx.Role {
+	if h == nil {
+		return nil
+	}
+	return h.inherits[role]
+}
+
+// WithInheritance returns a copy of the hierarchy with an additional edge,
+// failing with ErrRoleCycle if the edge would introduce a cycle
+func (h *RoleHierarchy) WithInheritance(role, inherited authctx.Role) (*RoleHierarchy, error) {
+	inherits := make(map[authctx.Role][]authctx.Role)
+	if h != nil {
+		for r, in := range h.inherits {
+			inherits[r] = in
+		}
+	}
+	inherits[role] = append(append([]authctx.Role(nil), inherits[role]...), inherited)
+
+	return NewRoleHierarchy(inherits)
+}
+
+// Validate checks the hierarchy for cycles using a depth-first search
+func (h *RoleHierarchy) Validate() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[authctx.Role]int)
+
+	var visit func(role authctx.Role, path []authctx.Role) error
+	visit = func(role authctx.Role, path []authctx.Role) error {
+		switch state[role] {
+		case visiting:
+			return fmt.Errorf("%w: %v", ErrRoleCycle, append(path, role))
+		case visited:
+			return nil
+		}
+
+		state[role] = visiting
+		for _, inherited := range h.inherits[role] {
+			if err := visit(inherited, append(path, role)); err != nil {
+				return err
+			}
+		}
+		state[role] = visited
+		return nil
+	}
+
+	// Visit roles in a stable order so error messages are deterministic
+	roles := make([]authctx.Role, 0, len(h.inherits))
+	for role := range h.inherits {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+
+	for _, role := range roles {
+		if err := visit(role, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Expand returns the given roles together with every role they inherit,
+// preserving the order of the input and removing duplicates
+func (h *RoleHierarchy) Expand(roles []authctx.Role) []authctx.Role {
+	if len(roles) == 0 {
+		return roles
+	}
+
+	seen := make(map[authctx.Role]bool)
+	var expanded []authctx.Role
+
+	var add func(role authctx.Role)
+	add = func(role authctx.Role) {
+		if seen[role] {
+			return
+		}
+		seen[role] = true
+		expanded = append(expanded, role)
+		for _, inherited := range h.Inherits(role) {
+			add(inherited)
+		}
+	}
+
+	for _, role := range roles {
+		add(role)
+	}
+
+	return expanded
+}
+
+// RoleResolvingUserService decorates a UserService so that every role lookup
+// includes the roles inherited through the role hierarchy
+type RoleResolvingUserService struct {
+	UserService
+	roleService RoleService
+}
+
+// NewRoleResolvingUserService creates a new RoleResolvingUserService
+func NewRoleResolvingUserService(userService UserService, roleService RoleService) *RoleResolvingUserService {
+	return &RoleResolvingUserService{
+		UserService: userService,
+		roleService: roleService,
+	}
+}
+
+// GetUserRoles retrieves a user's system-wide roles, including inherited roles
+func (s *RoleResolvingUserService) GetUserRoles(ctx context.Context, userID int64) ([]authctx.Role, error) {
+	roles, err := s.UserService.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(ctx, roles), nil
+}
+
+// GetUserTenantRoles retrieves a user's tenant-specific roles, including inherited roles
+func (s *RoleResolvingUserService) GetUserTenantRoles(ctx context.Context, userID int64, tenantID int64) ([]authctx.Role, error) {
+	roles, err := s.UserService.GetUserTenantRoles(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(ctx, roles), nil
+}
+
+// resolve expands roles through the hierarchy, falling back to the directly
+// assigned roles if the hierarchy cannot be loaded
+func (s *RoleResolvingUserService) resolve(ctx context.Context, roles []authctx.Role) []authctx.Role {
+	resolved, err := s.roleService.ResolveRoles(ctx, roles)
+	if err != nil {
+		log.Printf("[WARN] Failed to resolve inherited roles, using directly assigned roles: %v", err)
+		return roles
+	}
+	return resolved
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+func TestRoleHierarchyExpand(t *testing.T) {
+	hierarchy, err := NewRoleHierarchy(map[authctx.Role][]authctx.Role{
+		authctx.RoleTenantSuper: {authctx.RoleTenantMember},
+		authctx.RoleAdmin:       {authctx.RoleInternal},
+	})
+	require.NoError(t, err)
+
+	t.Run("Inherited roles are included", func(t *testing.T) {
+		roles := hierarchy.Expand([]authctx.Role{authctx.RoleTenantSuper})
+		assert.Equal(t, []authctx.Role{authctx.RoleTenantSuper, authctx.RoleTenantMember}, roles)
+	})
+
+	t.Run("Duplicates are removed", func(t *testing.T) {
+		roles := hierarchy.Expand([]authctx.Role{authctx.RoleTenantMember, authctx.RoleTenantSuper})
+		assert.Equal(t, []authctx.Role{authctx.RoleTenantMember, authctx.RoleTenantSuper}, roles)
+	})
+
+	t.Run("Roles without inheritance are unchanged", func(t *testing.T) {
+		roles := hierarchy.Expand([]authctx.Role{authctx.RoleInternal})
+		assert.Equal(t, []authctx.Role{authctx.RoleInternal}, roles)
+	})
+
+	t.Run("Transitive inheritance", func(t *testing.T) {
+		h, err := hierarchy.WithInheritance(authctx.RoleAdmin, authctx.RoleTenantSuper)
+		require.NoError(t, err)
+
+		roles := h.Expand([]authctx.Role{authctx.RoleAdmin})
+		assert.ElementsMatch(t, []authctx.Role{
+			authctx.RoleAdmin, authctx.RoleInternal, authctx.RoleTenantSuper, authctx.RoleTenantMember,
+		}, roles)
+	})
+}
+
+func TestRoleHierarchyCycleDetection(t *testing.T) {
+	t.Run("Direct cycle", func(t *testing.T) {
+		_, err := NewRoleHierarchy(map[authctx.Role][]authctx.Role{
+			"A": {"B"},
+			"B": {"A"},
+		})
+		assert.True(t, errors.Is(err, ErrRoleCycle))
+	})
+
+	t.Run("Indirect cycle introduced by new edge", func(t *testing.T) {
+		hierarchy, err := NewRoleHierarchy(map[authctx.Role][]authctx.Role{
+			"A": {"B"},
+			"B": {"C"},
+		})
+		require.NoError(t, err)
+
+		_, err = hierarchy.WithInheritance("C", "A")
+		assert.True(t, errors.Is(err, ErrRoleCycle))
+	})
+
+	t.Run("Diamond is not a cycle", func(t *testing.T) {
+		_, err := NewRoleHierarchy(map[authctx.Role][]authctx.Role{
+			"A": {"B", "C"},
+			"B": {"D"},
+			"C": {"D"},
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestRoleResolvingUserService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	roleService := NewDBRoleService(db)
+	userService := NewRoleResolvingUserService(NewDBUserService(db), roleService)
+
+	userID := int64(1)
+	tenantID := int64(2)
+
+	mock.ExpectQuery("SELECT r.name FROM tenant_role").
+		WithArgs(userID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(string(authctx.RoleTenantSuper)))
+
+	mock.ExpectQuery("SELECT r.name, ir.name FROM role_inheritance").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "name"}).
+			AddRow(string(authctx.RoleTenantSuper), string(authctx.RoleTenantMember)))
+
+	roles, err := userService.GetUserTenantRoles(context.Background(), userID, tenantID)
+
+	require.NoError(t, err)
+	assert.Equal(t, []authctx.Role{authctx.RoleTenantSuper, authctx.RoleTenantMember}, roles)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddRoleInheritance(t *testing.T) {
+	ctx := context.Background()
+	roleColumns := []string{"id", "name", "description", "created_at", "updated_at"}
+
+	expectRoles := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM role WHERE id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(roleColumns).AddRow(4, "A", "", time.Now(), time.Now()))
+		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM role WHERE id = \\$1").
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows(roleColumns).AddRow(5, "B", "", time.Now(), time.Now()))
+	}
+
+	t.Run("Checked and inserted under a lock", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		expectRoles(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE role_inheritance IN SHARE ROW EXCLUSIVE MODE").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT r.name, ir.name FROM role_inheritance").
+			WillReturnRows(sqlmock.NewRows([]string{"name", "name"}))
+		mock.ExpectExec("INSERT INTO role_inheritance").
+			WithArgs(int64(4), int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.AddRoleInheritance(ctx, 4, 5))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cycle rolled back", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		expectRoles(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE role_inheritance").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT r.name, ir.name FROM role_inheritance").
+			WillReturnRows(sqlmock.NewRows([]string{"name", "name"}).AddRow("B", "A"))
+		mock.ExpectRollback()
+
+		err := service.AddRoleInheritance(ctx, 4, 5)
+
+		assert.True(t, errors.Is(err, ErrRoleCycle))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -5,9 +5,46 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"sync"
 	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// Role errors
+var (
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrRoleAssignmentNotFound = errors.New("role assignment not found")
+	ErrRoleAlreadyExists      = errors.New("role already exists")
+	ErrSystemRole             = errors.New("system roles cannot be modified")
+	ErrLastAdmin              = errors.New("cannot remove the last ADMIN")
+	ErrInvalidRole            = errors.New("invalid role")
+	ErrInheritanceNotFound    = errors.New("role inheritance not found")
 )
 
+// systemRoles are the built-in roles seeded by the initial schema. They are
+// referenced by name throughout the codebase and cannot be renamed or deleted.
+var systemRoles = map[string]bool{
+	string(authctx.RoleAdmin):        true,
+	string(authctx.RoleInternal):     true,
+	string(authctx.RoleTenantSuper):  true,
+	string(authctx.RoleTenantMember): true,
+}
+
+// IsSystemRole reports whether the given role name is a built-in system role
+func IsSystemRole(name string) bool {
+	return systemRoles[name]
+}
+
+// IsTenantAssignableRole reports whether a role may be assigned within a tenant.
+// Platform roles grant platform-wide access and never are.
+func IsTenantAssignableRole(name string) bool {
+	return !authctx.IsPlatformRole(authctx.Role(name))
+}
+
 // Role represents a role in the system
 type Role struct {
 	ID          int64     `json:"id"`
@@ -32,6 +69,13 @@ type TenantRole struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// TenantRoleChanges are the roles assigned and revoked by replacing a user's
+// tenant roles
+type TenantRoleChanges struct {
+	Assigned []int64
+	Revoked  []int64
+}
+
 // RoleService defines the interface for role-related operations
 type RoleService interface {
 	// GetRoles retrieves all roles in the system
@@ -43,6 +87,15 @@ type RoleService interface {
 	// GetRoleByName retrieves a role by name
 	GetRoleByName(ctx context.Context, name string) (*Role, error)
 
+	// CreateRole creates a new role
+	CreateRole(ctx context.Context, role *Role) (*Role, error)
+
+	// UpdateRole updates an existing role
+	UpdateRole(ctx context.Context, role *Role) error
+
+	// DeleteRole deletes a role
+	DeleteRole(ctx context.Context, roleID int64) error
+
 	// AssignUserRole assigns a system-wide role to a user
 	AssignUserRole(ctx context.Context, userID int64, roleID int64) error
 
@@ -58,18 +111,52 @@ type RoleService interface {
 	// RevokeTenantRole revokes a tenant-specific role from a user
 	RevokeTenantRole(ctx context.Context, userID int64, tenantID int64, roleID int64) error
 
+	// ReplaceTenantRoles replaces a user's tenant-specific roles with the given
+	// roles in one transaction, returning the roles assigned and revoked
+	ReplaceTenantRoles(ctx context.Context, userID int64, tenantID int64, roleIDs []int64) (*TenantRoleChanges, error)
+
 	// GetUserTenantRoles retrieves all tenant-specific roles for a user
 	GetUserTenantRoles(ctx context.Context, userID int64, tenantID int64) ([]Role, error)
+
+	// GetInheritedRoles retrieves the roles directly inherited by a role
+	GetInheritedRoles(ctx context.Context, roleID int64) ([]Role, error)
+
+	// AddRoleInheritance makes a role inherit another role, rejecting cycles
+	AddRoleInheritance(ctx context.Context, roleID int64, inheritedRoleID int64) error
+
+	// RemoveRoleInheritance removes an inheritance relationship between two roles
+	RemoveRoleInheritance(ctx context.Context, roleID int64, inheritedRoleID int64) error
+
+	// GetRoleHierarchy retrieves the full role inheritance hierarchy
+	GetRoleHierarchy(ctx context.Context) (*RoleHierarchy, error)
+
+	// ResolveRoles expands a set of roles with every role they inherit
+	ResolveRoles(ctx context.Context, roles []authctx.Role) ([]authctx.Role, error)
+
+	// GetRoleAuditLog retrieves the audit trail of role assignments and revocations
+	GetRoleAuditLog(ctx context.Context, filter RoleAuditFilter) ([]RoleAuditEntry, error)
 }
 
+// defaultHierarchyTTL is how long a loaded role hierarchy is reused before it is reloaded
+const defaultHierarchyTTL = 5 * time.Minute
+
 // DBRoleService implements RoleService using a database
 type DBRoleService struct {
 	db *sql.DB
+
+	// Cached role hierarchy, reloaded after hierarchyTTL or when it changes
+	mu                sync.RWMutex
+	hierarchy         *RoleHierarchy
+	hierarchyLoadedAt time.Time
+	hierarchyTTL      time.Duration
 }
 
 // NewDBRoleService creates a new DBRoleService
 func NewDBRoleService(db *sql.DB) *DBRoleService {
-	return &DBRoleService{db: db}
+	return &DBRoleService{
+		db:           db,
+		hierarchyTTL: defaultHierarchyTTL,
+	}
 }
 
 // GetRoles retrieves all roles in the system
@@ -127,7 +214,7 @@ func (s *DBRoleService) GetRole(ctx context.Context, roleID int64) (*Role, error
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("role not found: %d", roleID)
+			return nil, fmt.Errorf("%w: %d", ErrRoleNotFound, roleID)
 		}
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
@@ -154,7 +241,7 @@ func (s *DBRoleService) GetRoleByName(ctx context.Context, name string) (*Role,
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("role not found: %s", name)
+			return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, name)
 		}
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
@@ -162,30 +249,199 @@ func (s *DBRoleService) GetRoleByName(ctx context.Context, name string) (*Role,
 	return &role, nil
 }
 
+// CreateRole creates a new role
+func (s *DBRoleService) CreateRole(ctx context.Context, role *Role) (*Role, error) {
+	if role.Name == "" {
+		return nil, fmt.Errorf("%w: role name is required", ErrInvalidRole)
+	}
+
+	// Ensure the role name is unique
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM role WHERE name = $1)", role.Name).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if exists {
+		return nil, fmt.Errorf("%w: %s", ErrRoleAlreadyExists, role.Name)
+	}
+
+	query := `
+		INSERT INTO role (name, description)
+		VALUES ($1, $2)
+		RETURNING id, name, description, created_at, updated_at
+	`
+
+	err = s.db.QueryRowContext(ctx, query, role.Name, role.Description).Scan(
+		&role.ID,
+		&role.Name,
+		&role.Description,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return role, nil
+}
+
+// UpdateRole updates an existing role. System roles may have their description
+// changed but cannot be renamed.
+func (s *DBRoleService) UpdateRole(ctx context.Context, role *Role) error {
+	if role.ID <= 0 {
+		return fmt.Errorf("%w: role ID is required", ErrInvalidRole)
+	}
+	if role.Name == "" {
+		return fmt.Errorf("%w: role name is required", ErrInvalidRole)
+	}
+
+	existing, err := s.GetRole(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+
+	if IsSystemRole(existing.Name) && existing.Name != role.Name {
+		return fmt.Errorf("%w: %s cannot be renamed", ErrSystemRole, existing.Name)
+	}
+
+	query := `
+		UPDATE role
+		SET name = $1, description = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	result, err := s.db.ExecContext(ctx, query, role.Name, role.Description, role.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrRoleNotFound, role.ID)
+	}
+
+	return nil
+}
+
+// DeleteRole deletes a role. System roles cannot be deleted.
+func (s *DBRoleService) DeleteRole(ctx context.Context, roleID int64) error {
+	existing, err := s.GetRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	if IsSystemRole(existing.Name) {
+		return fmt.Errorf("%w: %s cannot be deleted", ErrSystemRole, existing.Name)
+	}
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM role WHERE id = $1", roleID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrRoleNotFound, roleID)
+	}
+
+	return nil
+}
+
 // AssignUserRole assigns a system-wide role to a user
 func (s *DBRoleService) AssignUserRole(ctx context.Context, userID int64, roleID int64) error {
+	// Start a transaction so the assignment and its audit record are atomic
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO user_role (user_id, role_id)
 		VALUES ($1, $2)
 		ON CONFLICT (user_id, role_id) DO NOTHING
 	`
 
-	_, err := s.db.ExecContext(ctx, query, userID, roleID)
+	result, err := tx.ExecContext(ctx, query, userID, roleID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
+	// Only audit assignments that changed something
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	} else if rowsAffected > 0 {
+		if err := recordRoleAudit(ctx, tx, RoleAuditAssign, userID, nil, roleID); err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
 	return nil
 }
 
-// RevokeUserRole revokes a system-wide role from a user
+// RevokeUserRole revokes a system-wide role from a user.
+// Revoking ADMIN from the only remaining administrator is rejected with ErrLastAdmin.
 func (s *DBRoleService) RevokeUserRole(ctx context.Context, userID int64, roleID int64) error {
+	// Start a transaction so the admin count check and the delete are atomic
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	// Look up the role being revoked
+	var roleName string
+	err = tx.QueryRowContext(ctx, "SELECT name FROM role WHERE id = $1", roleID).Scan(&roleName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %d", ErrRoleNotFound, roleID)
+		}
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if roleName == string(authctx.RoleAdmin) {
+		// Lock the ADMIN assignments so concurrent revocations cannot both
+		// observe a second administrator
+		var adminCount, held int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2) FROM (
+				SELECT user_id FROM user_role WHERE role_id = $1 FOR UPDATE
+			) admins
+		`, roleID, userID).Scan(&adminCount, &held)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		// Only the user's own ADMIN assignment can be the last one
+		if held == 0 {
+			return fmt.Errorf("%w: user %d does not have role %d", ErrRoleAssignmentNotFound, userID, roleID)
+		}
+		if adminCount <= 1 {
+			return ErrLastAdmin
+		}
+	}
+
 	query := `
 		DELETE FROM user_role
 		WHERE user_id = $1 AND role_id = $2
 	`
 
-	result, err := s.db.ExecContext(ctx, query, userID, roleID)
+	result, err := tx.ExecContext(ctx, query, userID, roleID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
@@ -196,7 +452,16 @@ func (s *DBRoleService) RevokeUserRole(ctx context.Context, userID int64, roleID
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user %d does not have role %d", userID, roleID)
+		return fmt.Errorf("%w: user %d does not have role %d", ErrRoleAssignmentNotFound, userID, roleID)
+	}
+
+	if err := recordRoleAudit(ctx, tx, RoleAuditRevoke, userID, nil, roleID); err != nil {
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
 	return nil
@@ -265,11 +530,20 @@ func (s *DBRoleService) AssignTenantRole(ctx context.Context, userID int64, tena
 	}
 
 	// Assign the tenant role
-	_, err = tx.ExecContext(ctx, "INSERT INTO tenant_role (user_id, tenant_id, role_id) VALUES ($1, $2, $3) ON CONFLICT (user_id, tenant_id, role_id) DO NOTHING", userID, tenantID, roleID)
+	result, err := tx.ExecContext(ctx, "INSERT INTO tenant_role (user_id, tenant_id, role_id) VALUES ($1, $2, $3) ON CONFLICT (user_id, tenant_id, role_id) DO NOTHING", userID, tenantID, roleID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
+	// Only audit assignments that changed something
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	} else if rowsAffected > 0 {
+		if err := recordRoleAudit(ctx, tx, RoleAuditAssign, userID, &tenantID, roleID); err != nil {
+			return err
+		}
+	}
+
 	// Commit the transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
@@ -280,12 +554,19 @@ func (s *DBRoleService) AssignTenantRole(ctx context.Context, userID int64, tena
 
 // RevokeTenantRole revokes a tenant-specific role from a user
 func (s *DBRoleService) RevokeTenantRole(ctx context.Context, userID int64, tenantID int64, roleID int64) error {
+	// Start a transaction so the revocation and its audit record are atomic
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		DELETE FROM tenant_role
 		WHERE user_id = $1 AND tenant_id = $2 AND role_id = $3
 	`
 
-	result, err := s.db.ExecContext(ctx, query, userID, tenantID, roleID)
+	result, err := tx.ExecContext(ctx, query, userID, tenantID, roleID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
@@ -296,12 +577,91 @@ func (s *DBRoleService) RevokeTenantRole(ctx context.Context, userID int64, tena
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user %d does not have role %d for tenant %d", userID, roleID, tenantID)
+		return fmt.Errorf("%w: user %d does not have role %d for tenant %d", ErrRoleAssignmentNotFound, userID, roleID, tenantID)
+	}
+
+	if err := recordRoleAudit(ctx, tx, RoleAuditRevoke, userID, &tenantID, roleID); err != nil {
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
 	return nil
 }
 
+// ReplaceTenantRoles replaces a user's tenant-specific roles with the given
+// roles. Every change and its audit record is made in one transaction, so a
+// failure leaves the user's roles as they were.
+func (s *DBRoleService) ReplaceTenantRoles(ctx context.Context, userID int64, tenantID int64, roleIDs []int64) (*TenantRoleChanges, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	// Lock the user's current roles so concurrent replacements apply in turn
+	rows, err := tx.QueryContext(ctx, "SELECT role_id FROM tenant_role WHERE user_id = $1 AND tenant_id = $2 FOR UPDATE", userID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	current := make(map[int64]bool)
+	for rows.Next() {
+		var roleID int64
+		if err := rows.Scan(&roleID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		current[roleID] = true
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	rows.Close()
+
+	changes := &TenantRoleChanges{}
+	desired := make(map[int64]bool, len(roleIDs))
+	for _, roleID := range roleIDs {
+		if !desired[roleID] && !current[roleID] {
+			changes.Assigned = append(changes.Assigned, roleID)
+		}
+		desired[roleID] = true
+	}
+	for roleID := range current {
+		if !desired[roleID] {
+			changes.Revoked = append(changes.Revoked, roleID)
+		}
+	}
+	sort.Slice(changes.Revoked, func(i, j int) bool { return changes.Revoked[i] < changes.Revoked[j] })
+
+	for _, roleID := range changes.Revoked {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM tenant_role WHERE user_id = $1 AND tenant_id = $2 AND role_id = $3", userID, tenantID, roleID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if err := recordRoleAudit(ctx, tx, RoleAuditRevoke, userID, &tenantID, roleID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, roleID := range changes.Assigned {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO tenant_role (user_id, tenant_id, role_id) VALUES ($1, $2, $3)", userID, tenantID, roleID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if err := recordRoleAudit(ctx, tx, RoleAuditAssign, userID, &tenantID, roleID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return changes, nil
+}
+
 // GetUserTenantRoles retrieves all tenant-specific roles for a user
 func (s *DBRoleService) GetUserTenantRoles(ctx context.Context, userID int64, tenantID int64) ([]Role, error) {
 	query := `
@@ -339,3 +699,208 @@ func (s *DBRoleService) GetUserTenantRoles(ctx context.Context, userID int64, te
 
 	return roles, nil
 }
+
+// GetInheritedRoles retrieves the roles directly inherited by a role
+func (s *DBRoleService) GetInheritedRoles(ctx context.Context, roleID int64) ([]Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.created_at, r.updated_at
+		FROM role r
+		JOIN role_inheritance ri ON r.id = ri.inherited_role_id
+		WHERE ri.role_id = $1
+		ORDER BY r.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	var roles []Role
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.Description,
+			&role.CreatedAt,
+			&role.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return roles, nil
+}
+
+// AddRoleInheritance makes a role inherit another role, rejecting cycles
+func (s *DBRoleService) AddRoleInheritance(ctx context.Context, roleID int64, inheritedRoleID int64) error {
+	if roleID == inheritedRoleID {
+		return fmt.Errorf("%w: role %d cannot inherit itself", ErrRoleCycle, roleID)
+	}
+
+	role, err := s.GetRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	inherited, err := s.GetRole(ctx, inheritedRoleID)
+	if err != nil {
+		return err
+	}
+
+	// Check the new edge against the current hierarchy and persist it in one
+	// transaction. The lock serializes changes to the hierarchy, so concurrent
+	// additions such as A->B and B->A can't both pass the check; reads go on.
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE role_inheritance IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	hierarchy, err := loadRoleHierarchy(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := hierarchy.WithInheritance(authctx.Role(role.Name), authctx.Role(inherited.Name)); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO role_inheritance (role_id, inherited_role_id)
+		VALUES ($1, $2)
+		ON CONFLICT (role_id, inherited_role_id) DO NOTHING
+	`
+
+	if _, err := tx.ExecContext(ctx, query, roleID, inheritedRoleID); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	s.InvalidateHierarchy()
+	log.Printf("[INFO] Role %s now inherits role %s", role.Name, inherited.Name)
+	return nil
+}
+
+// RemoveRoleInheritance removes an inheritance relationship between two roles
+func (s *DBRoleService) RemoveRoleInheritance(ctx context.Context, roleID int64, inheritedRoleID int64) error {
+	query := `
+		DELETE FROM role_inheritance
+		WHERE role_id = $1 AND inherited_role_id = $2
+	`
+
+	result, err := s.db.ExecContext(ctx, query, roleID, inheritedRoleID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: role %d does not inherit role %d", ErrInheritanceNotFound, roleID, inheritedRoleID)
+	}
+
+	s.InvalidateHierarchy()
+	return nil
+}
+
+// GetRoleHierarchy retrieves the full role inheritance hierarchy, using a cached
+// copy when it is still fresh
+func (s *DBRoleService) GetRoleHierarchy(ctx context.Context) (*RoleHierarchy, error) {
+	s.mu.RLock()
+	hierarchy := s.hierarchy
+	fresh := hierarchy != nil && time.Since(s.hierarchyLoadedAt) < s.hierarchyTTL
+	s.mu.RUnlock()
+
+	if fresh {
+		return hierarchy, nil
+	}
+
+	hierarchy, err := loadRoleHierarchy(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.hierarchy = hierarchy
+	s.hierarchyLoadedAt = time.Now()
+	s.mu.Unlock()
+
+	return hierarchy, nil
+}
+
+// ResolveRoles expands a set of roles with every role they inherit
+func (s *DBRoleService) ResolveRoles(ctx context.Context, roles []authctx.Role) ([]authctx.Role, error) {
+	if len(roles) == 0 {
+		return roles, nil
+	}
+
+	hierarchy, err := s.GetRoleHierarchy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return hierarchy.Expand(roles), nil
+}
+
+// loadRoleHierarchy reads the role inheritance table from the database
+func loadRoleHierarchy(ctx context.Context, q transaction.Querier) (*RoleHierarchy, error) {
+	query := `
+		SELECT r.name, ir.name
+		FROM role_inheritance ri
+		JOIN role r ON r.id = ri.role_id
+		JOIN role ir ON ir.id = ri.inherited_role_id
+	`
+
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	inherits := make(map[authctx.Role][]authctx.Role)
+	for rows.Next() {
+		var roleName, inheritedName string
+		if err := rows.Scan(&roleName, &inheritedName); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		inherits[authctx.Role(roleName)] = append(inherits[authctx.Role(roleName)], authctx.Role(inheritedName))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	hierarchy, err := NewRoleHierarchy(inherits)
+	if err != nil {
+		log.Printf("[ERROR] Stored role hierarchy is invalid: %v", err)
+		return nil, err
+	}
+
+	return hierarchy, nil
+}
+
+// InvalidateHierarchy drops the cached role hierarchy so the next lookup
+// reloads it. Changes made by this instance invalidate it themselves; changes
+// made by others are received with the cache invalidation listener.
+func (s *DBRoleService) InvalidateHierarchy() {
+	s.mu.Lock()
+	s.hierarchy = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+func setupRoleServiceMock(t *testing.T) (sqlmock.Sqlmock, *DBRoleService, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return mock, NewDBRoleService(db), func() { db.Close() }
+}
+
+func TestCreateRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Successful creation", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM role WHERE name = \\$1\\)").
+			WithArgs("SUPPORT").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		mock.ExpectQuery("INSERT INTO role").
+			WithArgs("SUPPORT", "Support staff").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
+				AddRow(4, "SUPPORT", "Support staff", time.Now(), time.Now()))
+
+		role, err := service.CreateRole(ctx, &Role{Name: "SUPPORT", Description: "Support staff"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), role.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Duplicate name", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM role WHERE name = \\$1\\)").
+			WithArgs("ADMIN").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		role, err := service.CreateRole(ctx, &Role{Name: "ADMIN"})
+
+		assert.Nil(t, role)
+		assert.True(t, errors.Is(err, ErrRoleAlreadyExists))
+	})
+
+	t.Run("Missing name", func(t *testing.T) {
+		_, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		role, err := service.CreateRole(ctx, &Role{})
+
+		assert.Nil(t, role)
+		assert.True(t, errors.Is(err, ErrInvalidRole))
+	})
+}
+
+func TestDeleteRole(t *testing.T) {
+	ctx := context.Background()
+	roleColumns := []string{"id", "name", "description", "created_at", "updated_at"}
+
+	t.Run("Custom role deleted", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM role WHERE id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(roleColumns).AddRow(4, "SUPPORT", "Support staff", time.Now(), time.Now()))
+
+		mock.ExpectExec("DELETE FROM role WHERE id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := service.DeleteRole(ctx, 4)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("System role rejected", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM role WHERE id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(roleColumns).AddRow(1, "ADMIN", "Platform administrators", time.Now(), time.Now()))
+
+		err := service.DeleteRole(ctx, 1)
+
+		assert.True(t, errors.Is(err, ErrSystemRole))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRevokeUserRole(t *testing.T) {
+	ctx := context.Background()
+	userID := int64(10)
+	adminRoleID := int64(1)
+
+	t.Run("Last admin cannot be revoked", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(adminRoleID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("ADMIN"))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\), COUNT\\(\\*\\) FILTER").
+			WithArgs(adminRoleID, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "held"}).AddRow(1, 1))
+		mock.ExpectRollback()
+
+		err := service.RevokeUserRole(ctx, userID, adminRoleID)
+
+		assert.True(t, errors.Is(err, ErrLastAdmin))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Admin revoked when others remain", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(adminRoleID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("ADMIN"))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\), COUNT\\(\\*\\) FILTER").
+			WithArgs(adminRoleID, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "held"}).AddRow(2, 1))
+		mock.ExpectExec("DELETE FROM user_role").
+			WithArgs(userID, adminRoleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(nil, userID, nil, "revoke", adminRoleID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := service.RevokeUserRole(ctx, userID, adminRoleID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Admin not held by the user is not found", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(adminRoleID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("ADMIN"))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\), COUNT\\(\\*\\) FILTER").
+			WithArgs(adminRoleID, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "held"}).AddRow(1, 0))
+		mock.ExpectRollback()
+
+		err := service.RevokeUserRole(ctx, userID, adminRoleID)
+
+		assert.True(t, errors.Is(err, ErrRoleAssignmentNotFound))
+		assert.False(t, errors.Is(err, ErrLastAdmin))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Assignment not found", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("INTERNAL"))
+		mock.ExpectExec("DELETE FROM user_role").
+			WithArgs(userID, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := service.RevokeUserRole(ctx, userID, 2)
+
+		assert.True(t, errors.Is(err, ErrRoleAssignmentNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRoleAssignmentAudit(t *testing.T) {
+	actorID := int64(3)
+	ctx := authctx.WithUserID(context.Background(), actorID)
+	userID := int64(10)
+	tenantID := int64(5)
+	roleID := int64(2)
+
+	t.Run("Assignment is audited with actor", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO user_role").
+			WithArgs(userID, roleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(&actorID, userID, nil, "assign", roleID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.AssignUserRole(ctx, userID, roleID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Existing assignment is not audited", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO user_role").
+			WithArgs(userID, roleID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.AssignUserRole(ctx, userID, roleID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tenant revocation is audited with tenant", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM tenant_role").
+			WithArgs(userID, tenantID, roleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(&actorID, userID, &tenantID, "revoke", roleID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.RevokeTenantRole(ctx, userID, tenantID, roleID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Audit failure rolls back the change", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO user_role").
+			WithArgs(userID, roleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		err := service.AssignUserRole(ctx, userID, roleID)
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReplaceTenantRoles(t *testing.T) {
+	actorID := int64(3)
+	ctx := authctx.WithUserID(context.Background(), actorID)
+	userID := int64(10)
+	tenantID := int64(5)
+
+	expectCurrentRoles := func(mock sqlmock.Sqlmock, roleIDs ...int64) {
+		rows := sqlmock.NewRows([]string{"role_id"})
+		for _, roleID := range roleIDs {
+			rows.AddRow(roleID)
+		}
+		mock.ExpectQuery("SELECT role_id FROM tenant_role WHERE user_id = \\$1 AND tenant_id = \\$2 FOR UPDATE").
+			WithArgs(userID, tenantID).
+			WillReturnRows(rows)
+	}
+
+	t.Run("Revokes and assigns in one transaction", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectCurrentRoles(mock, 2, 3)
+		mock.ExpectExec("DELETE FROM tenant_role").
+			WithArgs(userID, tenantID, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(&actorID, userID, &tenantID, "revoke", int64(2)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO tenant_role").
+			WithArgs(userID, tenantID, int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(&actorID, userID, &tenantID, "assign", int64(4)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		changes, err := service.ReplaceTenantRoles(ctx, userID, tenantID, []int64{3, 4})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{4}, changes.Assigned)
+		assert.Equal(t, []int64{2}, changes.Revoked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed assignment rolls back the revocations", func(t *testing.T) {
+		mock, service, cleanup := setupRoleServiceMock(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectCurrentRoles(mock, 2)
+		mock.ExpectExec("DELETE FROM tenant_role").
+			WithArgs(userID, tenantID, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO tenant_role").
+			WithArgs(userID, tenantID, int64(4)).
+			WillReturnError(errors.New("foreign key violation"))
+		mock.ExpectRollback()
+
+		changes, err := service.ReplaceTenantRoles(ctx, userID, tenantID, []int64{4})
+
+		assert.Nil(t, changes)
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetRoleAuditLog(t *testing.T) {
+	mock, service, cleanup := setupRoleServiceMock(t)
+	defer cleanup()
+
+	tenantID := int64(5)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, actor_user_id, target_user_id, role_id, role_name, tenant_id, action, created_at FROM role_audit WHERE tenant_id = \\$1 ORDER BY created_at DESC, id DESC LIMIT \\$2 OFFSET \\$3").
+		WithArgs(tenantID, 100, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "target_user_id", "role_id", "role_name", "tenant_id", "action", "created_at"}).
+			AddRow(1, 3, 10, nil, "TENANT_SUPER", 5, "assign", now))
+
+	entries, err := service.GetRoleAuditLog(context.Background(), RoleAuditFilter{TenantID: &tenantID})
+
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(3), *entries[0].ActorUserID)
+	assert.Nil(t, entries[0].RoleID)
+	assert.Equal(t, "TENANT_SUPER", entries[0].RoleName)
+	assert.Equal(t, RoleAuditAssign, entries[0].Action)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsTenantAssignableRole(t *testing.T) {
+	assert.False(t, IsTenantAssignableRole("ADMIN"))
+	assert.False(t, IsTenantAssignableRole("INTERNAL"))
+	assert.True(t, IsTenantAssignableRole("TENANT_SUPER"))
+	assert.True(t, IsTenantAssignableRole("TENANT_MEMBER"))
+	assert.True(t, IsTenantAssignableRole("SUPPORT"))
+}
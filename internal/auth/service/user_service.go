@@ -7,6 +7,7 @@ import (
 	"log"
 
 	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database"
 )
 
 // Common errors
@@ -38,7 +39,8 @@ type UserService interface {
 
 // DBUserService implements UserService using a database
 type DBUserService struct {
-	db *sql.DB
+	db     *sql.DB
+	router *database.QueryRouter
 }
 
 // NewDBUserService creates a new DBUserService
@@ -46,6 +48,21 @@ func NewDBUserService(db *sql.DB) *DBUserService {
 	return &DBUserService{db: db}
 }
 
+// WithQueryRouter looks up roles on the router's read replicas. Role changes
+// take effect once they reach the replicas.
+func (s *DBUserService) WithQueryRouter(router *database.QueryRouter) *DBUserService {
+	s.router = router
+	return s
+}
+
+// reader returns the database for role lookups
+func (s *DBUserService) reader(ctx context.Context) *sql.DB {
+	if s.router == nil {
+		return s.db
+	}
+	return s.router.Reader(ctx)
+}
+
 // GetUserByEmail retrieves a user by their email address
 func (s *DBUserService) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
@@ -84,7 +101,7 @@ func (s *DBUserService) GetUserRoles(ctx context.Context, userID int64) ([]authc
 		WHERE ur.user_id = $1
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, userID)
+	rows, err := s.reader(ctx).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, ErrDBOperation
 	}
@@ -120,7 +137,7 @@ func (s *DBUserService) GetUserTenantRoles(ctx context.Context, userID int64, te
 		WHERE tr.user_id = $1 AND tr.tenant_id = $2
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, userID, tenantID)
+	rows, err := s.reader(ctx).QueryContext(ctx, query, userID, tenantID)
 	if err != nil {
 		return nil, ErrDBOperation
 	}
@@ -0,0 +1,581 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+// Common errors
+var (
+	ErrPlanNotFound         = errors.New("plan not found")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrTenantNotFound       = errors.New("tenant not found")
+	ErrDBOperation          = errors.New("database operation failed")
+	ErrInvalidInput         = errors.New("invalid input")
+
+	// ErrAlreadySubscribed is returned when checkout is started for a tenant with a subscription in good standing
+	ErrAlreadySubscribed = errors.New("tenant already has an active subscription")
+
+	// ErrStripe is returned when a Stripe API request fails
+	ErrStripe = errors.New("stripe request failed")
+)
+
+// FreePlanCode is the plan whose features apply to tenants without a subscription in good standing
+const FreePlanCode = "free"
+
+// Features that can be gated by plan
+const (
+	// FeatureInvitations allows inviting users to join the tenant
+	FeatureInvitations = "invitations"
+)
+
+// SubscriptionStatus mirrors the status of a Stripe subscription
+type SubscriptionStatus string
+
+// Subscription statuses
+const (
+	SubscriptionIncomplete        SubscriptionStatus = "incomplete"
+	SubscriptionIncompleteExpired SubscriptionStatus = "incomplete_expired"
+	SubscriptionTrialing          SubscriptionStatus = "trialing"
+	SubscriptionActive            SubscriptionStatus = "active"
+	SubscriptionPastDue           SubscriptionStatus = "past_due"
+	SubscriptionCanceled          SubscriptionStatus = "canceled"
+	SubscriptionUnpaid            SubscriptionStatus = "unpaid"
+	SubscriptionPaused            SubscriptionStatus = "paused"
+)
+
+// goodStandingStatuses are the statuses that grant the subscribed plan's features.
+// Past due subscriptions keep their features while Stripe retries the payment.
+var goodStandingStatuses = []string{
+	string(SubscriptionTrialing),
+	string(SubscriptionActive),
+	string(SubscriptionPastDue),
+}
+
+// InGoodStanding reports whether the status grants the subscribed plan's features
+func (s SubscriptionStatus) InGoodStanding() bool {
+	for _, status := range goodStandingStatuses {
+		if string(s) == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan is a billing plan and the features it grants
+type Plan struct {
+	ID            int64    `json:"id"`
+	Code          string   `json:"code"`
+	Name          string   `json:"name"`
+	StripePriceID string   `json:"stripe_price_id,omitempty"`
+	Features      []string `json:"features"`
+	Active        bool     `json:"active"`
+	// MaxAPIRequests is the plan's hourly API request budget. Nil is unlimited.
+	MaxAPIRequests *int `json:"max_api_requests_per_hour"`
+}
+
+// Subscription links a tenant to its Stripe customer and subscription
+type Subscription struct {
+	TenantID             int64              `json:"tenant_id"`
+	Plan                 *Plan              `json:"plan,omitempty"`
+	StripeCustomerID     string             `json:"stripe_customer_id"`
+	StripeSubscriptionID string             `json:"stripe_subscription_id,omitempty"`
+	Status               SubscriptionStatus `json:"status"`
+	CurrentPeriodEnd     *time.Time         `json:"current_period_end,omitempty"`
+	UpdatedAt            time.Time          `json:"updated_at"`
+}
+
+// BillingService defines the interface for tenant billing operations
+type BillingService interface {
+	// ListPlans retrieves the active plans
+	ListPlans(ctx context.Context) ([]Plan, error)
+
+	// GetSubscription retrieves a tenant's subscription
+	GetSubscription(ctx context.Context, tenantID int64) (*Subscription, error)
+
+	// CreateCheckoutSession starts a Stripe checkout that subscribes the tenant to a plan
+	CreateCheckoutSession(ctx context.Context, tenantID int64, planCode, successURL, cancelURL string) (*CheckoutSession, error)
+
+	// HandleWebhook verifies and applies a Stripe webhook event
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+
+	// GetTenantFeatures retrieves the features available to a tenant. Tenants whose
+	// subscription has lapsed are downgraded to the free plan's features.
+	GetTenantFeatures(ctx context.Context, tenantID int64) ([]string, error)
+
+	// HasFeature checks whether a feature is available to a tenant
+	HasFeature(ctx context.Context, tenantID int64, feature string) (bool, error)
+
+	// GetTenantAPIRequestLimit retrieves the hourly API request budget of a tenant's
+	// plan, or nil if it is unlimited. Tenants whose subscription has lapsed get
+	// the free plan's budget.
+	GetTenantAPIRequestLimit(ctx context.Context, tenantID int64) (*int, error)
+}
+
+// DBBillingService implements BillingService using a database and the Stripe API
+type DBBillingService struct {
+	db            *sql.DB
+	stripe        StripeClient
+	webhookSecret string
+	now           func() time.Time
+}
+
+// Ensure DBBillingService implements BillingService
+var _ BillingService = (*DBBillingService)(nil)
+
+// NewDBBillingService creates a new DBBillingService. Webhook events must be signed with webhookSecret.
+func NewDBBillingService(db *sql.DB, stripe StripeClient, webhookSecret string) *DBBillingService {
+	return &DBBillingService{
+		db:            db,
+		stripe:        stripe,
+		webhookSecret: webhookSecret,
+		now:           time.Now,
+	}
+}
+
+// ListPlans retrieves the active plans
+func (s *DBBillingService) ListPlans(ctx context.Context) ([]Plan, error) {
+	query := `
+		SELECT id, code, name, stripe_price_id, features, active, max_api_requests
+		FROM plan
+		WHERE active
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	plans := []Plan{}
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return plans, nil
+}
+
+// getPlanByCode retrieves an active plan by its code
+func (s *DBBillingService) getPlanByCode(ctx context.Context, code string) (*Plan, error) {
+	query := `
+		SELECT id, code, name, stripe_price_id, features, active, max_api_requests
+		FROM plan
+		WHERE code = $1 AND active
+	`
+
+	plan, err := scanPlan(s.db.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPlan scans a plan row. sql.ErrNoRows is returned unwrapped.
+func scanPlan(row rowScanner) (*Plan, error) {
+	var plan Plan
+	var priceID sql.NullString
+	var features database.StringArray
+	var maxAPIRequests sql.NullInt64
+	if err := row.Scan(&plan.ID, &plan.Code, &plan.Name, &priceID, &features, &plan.Active, &maxAPIRequests); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	plan.StripePriceID = priceID.String
+	plan.MaxAPIRequests = nullIntPtr(maxAPIRequests)
+	plan.Features = []string(features)
+	if plan.Features == nil {
+		plan.Features = []string{}
+	}
+
+	return &plan, nil
+}
+
+// GetSubscription retrieves a tenant's subscription
+func (s *DBBillingService) GetSubscription(ctx context.Context, tenantID int64) (*Subscription, error) {
+	query := `
+		SELECT s.tenant_id, s.stripe_customer_id, s.stripe_subscription_id, s.status, s.current_period_end, s.updated_at,
+			p.id, p.code, p.name, p.stripe_price_id, p.features, p.active, p.max_api_requests
+		FROM tenant_subscription s
+		LEFT JOIN plan p ON p.id = s.plan_id
+		WHERE s.tenant_id = $1
+	`
+
+	var sub Subscription
+	var subscriptionID, priceID, planCode, planName sql.NullString
+	var periodEnd sql.NullTime
+	var planID, planMaxAPIRequests sql.NullInt64
+	var planActive sql.NullBool
+	var features database.StringArray
+	err := s.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&sub.TenantID, &sub.StripeCustomerID, &subscriptionID, &sub.Status, &periodEnd, &sub.UpdatedAt,
+		&planID, &planCode, &planName, &priceID, &features, &planActive, &planMaxAPIRequests,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	sub.StripeSubscriptionID = subscriptionID.String
+	if periodEnd.Valid {
+		sub.CurrentPeriodEnd = &periodEnd.Time
+	}
+	if planID.Valid {
+		sub.Plan = &Plan{
+			ID:             planID.Int64,
+			Code:           planCode.String,
+			Name:           planName.String,
+			StripePriceID:  priceID.String,
+			Features:       []string(features),
+			Active:         planActive.Bool,
+			MaxAPIRequests: nullIntPtr(planMaxAPIRequests),
+		}
+	}
+
+	return &sub, nil
+}
+
+// CreateCheckoutSession starts a Stripe checkout that subscribes the tenant to a plan.
+// A Stripe customer is created for the tenant on its first checkout.
+func (s *DBBillingService) CreateCheckoutSession(ctx context.Context, tenantID int64, planCode, successURL, cancelURL string) (*CheckoutSession, error) {
+	if planCode == "" {
+		return nil, fmt.Errorf("%w: plan is required", ErrInvalidInput)
+	}
+
+	plan, err := s.getPlanByCode(ctx, planCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.StripePriceID == "" {
+		return nil, fmt.Errorf("%w: plan '%s' is not available for purchase", ErrInvalidInput, planCode)
+	}
+
+	customerID, err := s.ensureCustomer(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.stripe.CreateCheckoutSession(ctx, CheckoutSessionParams{
+		CustomerID: customerID,
+		PriceID:    plan.StripePriceID,
+		TenantID:   tenantID,
+		SuccessURL: successURL,
+		CancelURL:  cancelURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Created checkout session %s for tenant ID %d on plan '%s'", session.ID, tenantID, planCode)
+	return session, nil
+}
+
+// ensureCustomer returns the tenant's Stripe customer ID, creating the customer if needed
+func (s *DBBillingService) ensureCustomer(ctx context.Context, tenantID int64) (string, error) {
+	var customerID string
+	var status SubscriptionStatus
+	err := s.db.QueryRowContext(ctx, "SELECT stripe_customer_id, status FROM tenant_subscription WHERE tenant_id = $1", tenantID).Scan(&customerID, &status)
+	if err == nil {
+		if status.InGoodStanding() {
+			return "", ErrAlreadySubscribed
+		}
+		return customerID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	var name string
+	err = s.db.QueryRowContext(ctx, "SELECT name FROM tenant WHERE id = $1 AND deleted_at IS NULL", tenantID).Scan(&name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrTenantNotFound
+		}
+		return "", fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	customerID, err = s.stripe.CreateCustomer(ctx, tenantID, name)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO tenant_subscription (tenant_id, stripe_customer_id, status)
+		VALUES ($1, $2, $3)
+	`, tenantID, customerID, SubscriptionIncomplete)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return customerID, nil
+}
+
+// stripeEvent is a Stripe webhook event
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// stripeCheckoutSession is the object of a checkout.session.completed event
+type stripeCheckoutSession struct {
+	Customer     string `json:"customer"`
+	Subscription string `json:"subscription"`
+}
+
+// stripeSubscription is the object of a customer.subscription.* event
+type stripeSubscription struct {
+	ID               string `json:"id"`
+	Customer         string `json:"customer"`
+	Status           string `json:"status"`
+	CurrentPeriodEnd int64  `json:"current_period_end"`
+	Items            struct {
+		Data []struct {
+			CurrentPeriodEnd int64 `json:"current_period_end"`
+			Price            struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// HandleWebhook verifies and applies a Stripe webhook event. Each event is applied
+// at most once; redelivered events and event types that don't affect billing are ignored.
+func (s *DBBillingService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	if err := VerifyWebhookSignature(payload, signature, s.webhookSecret, DefaultWebhookTolerance, s.now()); err != nil {
+		log.Printf("[WARN] Rejected Stripe webhook: %v", err)
+		return err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" {
+		return fmt.Errorf("%w: malformed event", ErrInvalidInput)
+	}
+
+	// Start a transaction so the event is only recorded if it is applied
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "INSERT INTO billing_event (id, type) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING", event.ID, event.Type)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if rowsAffected == 0 {
+		log.Printf("[DEBUG] Ignoring redelivered Stripe event %s", event.ID)
+		return nil
+	}
+
+	switch event.Type {
+	case "checkout.session.completed":
+		err = s.applyCheckoutCompleted(ctx, tx, event.Data.Object)
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		err = s.applySubscription(ctx, tx, event.Data.Object)
+	default:
+		log.Printf("[DEBUG] Ignoring Stripe event %s of type %s", event.ID, event.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return nil
+}
+
+// applyCheckoutCompleted links the subscription created by a checkout to the tenant
+func (s *DBBillingService) applyCheckoutCompleted(ctx context.Context, tx *sql.Tx, object json.RawMessage) error {
+	var session stripeCheckoutSession
+	if err := json.Unmarshal(object, &session); err != nil {
+		return fmt.Errorf("%w: malformed checkout session", ErrInvalidInput)
+	}
+
+	if session.Subscription == "" {
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tenant_subscription
+		SET stripe_subscription_id = $1, updated_at = NOW()
+		WHERE stripe_customer_id = $2
+	`, session.Subscription, session.Customer)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	logUnknownCustomer(result, session.Customer)
+	return nil
+}
+
+// applySubscription updates the tenant's subscription status, plan and billing period
+func (s *DBBillingService) applySubscription(ctx context.Context, tx *sql.Tx, object json.RawMessage) error {
+	var sub stripeSubscription
+	if err := json.Unmarshal(object, &sub); err != nil || sub.ID == "" {
+		return fmt.Errorf("%w: malformed subscription", ErrInvalidInput)
+	}
+
+	var priceID string
+	periodEnd := sub.CurrentPeriodEnd
+	if len(sub.Items.Data) > 0 {
+		priceID = sub.Items.Data[0].Price.ID
+		// Newer API versions report the billing period on the subscription items
+		if periodEnd == 0 {
+			periodEnd = sub.Items.Data[0].CurrentPeriodEnd
+		}
+	}
+
+	var currentPeriodEnd *time.Time
+	if periodEnd > 0 {
+		end := time.Unix(periodEnd, 0).UTC()
+		currentPeriodEnd = &end
+	}
+
+	// Keep the current plan if the price doesn't belong to a known plan
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tenant_subscription
+		SET stripe_subscription_id = $1,
+			status = $2,
+			current_period_end = $3,
+			plan_id = COALESCE((SELECT id FROM plan WHERE stripe_price_id = $4), plan_id),
+			updated_at = NOW()
+		WHERE stripe_customer_id = $5
+	`, sub.ID, sub.Status, currentPeriodEnd, priceID, sub.Customer)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if logUnknownCustomer(result, sub.Customer) {
+		log.Printf("[INFO] Subscription %s for customer %s is now %s", sub.ID, sub.Customer, sub.Status)
+	}
+	return nil
+}
+
+// logUnknownCustomer logs events for customers that don't belong to a tenant and
+// reports whether the update matched a tenant
+func logUnknownCustomer(result sql.Result, customerID string) bool {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		log.Printf("[WARN] Ignoring Stripe event for customer %s without a tenant", customerID)
+		return false
+	}
+	return true
+}
+
+// GetTenantFeatures retrieves the features available to a tenant. Tenants whose
+// subscription has lapsed are downgraded to the free plan's features.
+func (s *DBBillingService) GetTenantFeatures(ctx context.Context, tenantID int64) ([]string, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT p.features
+			 FROM tenant_subscription s
+			 JOIN plan p ON p.id = s.plan_id
+			 WHERE s.tenant_id = $1 AND s.status = ANY($2)),
+			(SELECT features FROM plan WHERE code = $3),
+			'{}'
+		)
+	`
+
+	var features database.StringArray
+	err := s.db.QueryRowContext(ctx, query, tenantID, database.Array(goodStandingStatuses), FreePlanCode).Scan(&features)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if features == nil {
+		return []string{}, nil
+	}
+	return []string(features), nil
+}
+
+// HasFeature checks whether a feature is available to a tenant
+func (s *DBBillingService) HasFeature(ctx context.Context, tenantID int64, feature string) (bool, error) {
+	features, err := s.GetTenantFeatures(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, f := range features {
+		if f == feature {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetTenantAPIRequestLimit retrieves the hourly API request budget of a tenant's
+// plan, or nil if it is unlimited. Tenants whose subscription has lapsed get the
+// free plan's budget.
+func (s *DBBillingService) GetTenantAPIRequestLimit(ctx context.Context, tenantID int64) (*int, error) {
+	query := `
+		SELECT max_api_requests
+		FROM plan
+		WHERE id = COALESCE(
+			(SELECT plan_id
+			 FROM tenant_subscription
+			 WHERE tenant_id = $1 AND status = ANY($2)),
+			(SELECT id FROM plan WHERE code = $3)
+		)
+	`
+
+	var limit sql.NullInt64
+	err := s.db.QueryRowContext(ctx, query, tenantID, database.Array(goodStandingStatuses), FreePlanCode).Scan(&limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return nullIntPtr(limit), nil
+}
+
+// nullIntPtr converts a nullable integer column to a pointer
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStripeClient records Stripe calls made by the billing service
+type fakeStripeClient struct {
+	customers []int64
+	sessions  []CheckoutSessionParams
+}
+
+func (c *fakeStripeClient) CreateCustomer(ctx context.Context, tenantID int64, name string) (string, error) {
+	c.customers = append(c.customers, tenantID)
+	return "cus_new", nil
+}
+
+func (c *fakeStripeClient) CreateCheckoutSession(ctx context.Context, params CheckoutSessionParams) (*CheckoutSession, error) {
+	c.sessions = append(c.sessions, params)
+	return &CheckoutSession{ID: "cs_1", URL: "https://checkout.stripe.com/c/cs_1"}, nil
+}
+
+func TestCreateCheckoutSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	stripe := &fakeStripeClient{}
+	service := NewDBBillingService(db, stripe, "whsec_test")
+	ctx := context.Background()
+
+	planColumns := []string{"id", "code", "name", "stripe_price_id", "features", "active", "max_api_requests"}
+
+	t.Run("First checkout creates a customer", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT id, code, name, stripe_price_id, features, active, max_api_requests FROM plan WHERE code = \\$1 AND active").
+			WithArgs("pro").
+			WillReturnRows(sqlmock.NewRows(planColumns).AddRow(2, "pro", "Pro", "price_pro", "{invitations}", true, 5000))
+		mock.ExpectQuery("SELECT stripe_customer_id, status FROM tenant_subscription WHERE tenant_id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT name FROM tenant WHERE id = \\$1 AND deleted_at IS NULL").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Acme"))
+		mock.ExpectExec("INSERT INTO tenant_subscription").
+			WithArgs(int64(1), "cus_new", SubscriptionIncomplete).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		session, err := service.CreateCheckoutSession(ctx, 1, "pro", "https://ok", "https://cancel")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "cs_1", session.ID)
+		assert.Equal(t, []int64{1}, stripe.customers)
+		require.Len(t, stripe.sessions, 1)
+		assert.Equal(t, "cus_new", stripe.sessions[0].CustomerID)
+		assert.Equal(t, "price_pro", stripe.sessions[0].PriceID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tenant with an active subscription", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT id, code, name, stripe_price_id, features, active, max_api_requests FROM plan").
+			WithArgs("pro").
+			WillReturnRows(sqlmock.NewRows(planColumns).AddRow(2, "pro", "Pro", "price_pro", "{invitations}", true, 5000))
+		mock.ExpectQuery("SELECT stripe_customer_id, status FROM tenant_subscription").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"stripe_customer_id", "status"}).AddRow("cus_new", "active"))
+
+		// Execute
+		_, err := service.CreateCheckoutSession(ctx, 1, "pro", "https://ok", "https://cancel")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrAlreadySubscribed))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Plan without a Stripe price", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT id, code, name, stripe_price_id, features, active, max_api_requests FROM plan").
+			WithArgs("free").
+			WillReturnRows(sqlmock.NewRows(planColumns).AddRow(1, "free", "Free", nil, "{}", true, nil))
+
+		// Execute
+		_, err := service.CreateCheckoutSession(ctx, 1, "free", "https://ok", "https://cancel")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown plan", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT id, code, name, stripe_price_id, features, active, max_api_requests FROM plan").
+			WithArgs("enterprise").
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		_, err := service.CreateCheckoutSession(ctx, 1, "enterprise", "https://ok", "https://cancel")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrPlanNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHandleWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Unix(1700000000, 0)
+	service := NewDBBillingService(db, &fakeStripeClient{}, "whsec_test")
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("Subscription updated", func(t *testing.T) {
+		// Setup
+		payload := []byte(`{"id":"evt_1","type":"customer.subscription.updated","data":{"object":{
+			"id":"sub_1","customer":"cus_1","status":"past_due","current_period_end":1700600000,
+			"items":{"data":[{"price":{"id":"price_pro"}}]}}}}`)
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO billing_event \\(id, type\\) VALUES \\(\\$1, \\$2\\) ON CONFLICT \\(id\\) DO NOTHING").
+			WithArgs("evt_1", "customer.subscription.updated").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE tenant_subscription SET stripe_subscription_id = \\$1, status = \\$2").
+			WithArgs("sub_1", "past_due", sqlmock.AnyArg(), "price_pro", "cus_1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		err := service.HandleWebhook(ctx, payload, signPayload(payload, "whsec_test", now))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Redelivered event is ignored", func(t *testing.T) {
+		// Setup
+		payload := []byte(`{"id":"evt_1","type":"customer.subscription.updated","data":{"object":{"id":"sub_1"}}}`)
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO billing_event").
+			WithArgs("evt_1", "customer.subscription.updated").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		// Execute
+		err := service.HandleWebhook(ctx, payload, signPayload(payload, "whsec_test", now))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Checkout completed", func(t *testing.T) {
+		// Setup
+		payload := []byte(`{"id":"evt_2","type":"checkout.session.completed","data":{"object":{"customer":"cus_1","subscription":"sub_2"}}}`)
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO billing_event").
+			WithArgs("evt_2", "checkout.session.completed").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE tenant_subscription SET stripe_subscription_id = \\$1, updated_at = NOW\\(\\) WHERE stripe_customer_id = \\$2").
+			WithArgs("sub_2", "cus_1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		err := service.HandleWebhook(ctx, payload, signPayload(payload, "whsec_test", now))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		// Setup
+		payload := []byte(`{"id":"evt_3","type":"customer.subscription.deleted"}`)
+
+		// Execute
+		err := service.HandleWebhook(ctx, payload, signPayload(payload, "whsec_other", now))
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHasFeature(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBBillingService(db, &fakeStripeClient{}, "whsec_test")
+	ctx := context.Background()
+
+	t.Run("Feature on the tenant's plan", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT COALESCE").
+			WithArgs(int64(1), sqlmock.AnyArg(), FreePlanCode).
+			WillReturnRows(sqlmock.NewRows([]string{"features"}).AddRow("{invitations}"))
+
+		// Execute
+		allowed, err := service.HasFeature(ctx, 1, FeatureInvitations)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lapsed tenant falls back to the free plan", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT COALESCE").
+			WithArgs(int64(2), sqlmock.AnyArg(), FreePlanCode).
+			WillReturnRows(sqlmock.NewRows([]string{"features"}).AddRow("{}"))
+
+		// Execute
+		allowed, err := service.HasFeature(ctx, 2, FeatureInvitations)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTenantAPIRequestLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBBillingService(db, &fakeStripeClient{}, "whsec_test")
+	ctx := context.Background()
+
+	t.Run("Budget of the tenant's plan", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT max_api_requests FROM plan WHERE id = COALESCE").
+			WithArgs(int64(1), sqlmock.AnyArg(), FreePlanCode).
+			WillReturnRows(sqlmock.NewRows([]string{"max_api_requests"}).AddRow(5000))
+
+		// Execute
+		limit, err := service.GetTenantAPIRequestLimit(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, limit)
+		assert.Equal(t, 5000, *limit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unlimited plan", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT max_api_requests FROM plan").
+			WithArgs(int64(2), sqlmock.AnyArg(), FreePlanCode).
+			WillReturnRows(sqlmock.NewRows([]string{"max_api_requests"}).AddRow(nil))
+
+		// Execute
+		limit, err := service.GetTenantAPIRequestLimit(ctx, 2)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, limit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
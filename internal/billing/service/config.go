@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// Default values
+	defaultStripeTimeout = 10 * time.Second
+
+	// Environment variable names
+	envStripeSecretKey      = "STRIPE_SECRET_KEY"
+	envStripeWebhookSecret  = "STRIPE_WEBHOOK_SECRET"
+	envStripeAPIURL         = "STRIPE_API_URL"
+	envStripeTimeoutSeconds = "STRIPE_TIMEOUT_SECONDS"
+)
+
+// Config holds configuration for Stripe billing
+type Config struct {
+	SecretKey     string
+	WebhookSecret string
+	APIURL        string
+	Timeout       time.Duration
+}
+
+// Enabled reports whether billing is configured
+func (c Config) Enabled() bool {
+	return c.SecretKey != ""
+}
+
+// LoadConfig loads billing configuration from environment variables.
+// Billing is disabled when STRIPE_SECRET_KEY is not set.
+func LoadConfig() (Config, error) {
+	config := Config{
+		SecretKey:     os.Getenv(envStripeSecretKey),
+		WebhookSecret: os.Getenv(envStripeWebhookSecret),
+		APIURL:        os.Getenv(envStripeAPIURL),
+		Timeout:       defaultStripeTimeout,
+	}
+
+	if config.APIURL == "" {
+		config.APIURL = DefaultStripeAPIURL
+	}
+
+	if timeoutStr := os.Getenv(envStripeTimeoutSeconds); timeoutStr != "" {
+		timeoutSeconds, err := strconv.Atoi(timeoutStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid STRIPE_TIMEOUT_SECONDS value: %w", err)
+		}
+		config.Timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	if config.Enabled() && config.WebhookSecret == "" {
+		return Config{}, fmt.Errorf("STRIPE_WEBHOOK_SECRET environment variable is required when STRIPE_SECRET_KEY is set")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStripeAPIURL is the base URL of the Stripe API
+const DefaultStripeAPIURL = "https://api.stripe.com/v1"
+
+// DefaultWebhookTolerance is the maximum age of a webhook signature timestamp
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned when a webhook payload isn't signed with the webhook secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// CheckoutSessionParams holds the parameters for a subscription checkout session
+type CheckoutSessionParams struct {
+	CustomerID string
+	PriceID    string
+	TenantID   int64
+	SuccessURL string
+	CancelURL  string
+}
+
+// CheckoutSession is a Stripe hosted checkout page
+type CheckoutSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// StripeClient defines the Stripe API operations used for billing
+type StripeClient interface {
+	// CreateCustomer creates a Stripe customer for a tenant and returns its ID
+	CreateCustomer(ctx context.Context, tenantID int64, name string) (string, error)
+
+	// CreateCheckoutSession creates a checkout session that subscribes the customer to a price
+	CreateCheckoutSession(ctx context.Context, params CheckoutSessionParams) (*CheckoutSession, error)
+}
+
+// HTTPStripeClient implements StripeClient using the Stripe REST API
+type HTTPStripeClient struct {
+	baseURL   string
+	secretKey string
+	client    *http.Client
+}
+
+// Ensure HTTPStripeClient implements StripeClient
+var _ StripeClient = (*HTTPStripeClient)(nil)
+
+// NewHTTPStripeClient creates a new HTTPStripeClient authenticating with the secret key
+func NewHTTPStripeClient(baseURL, secretKey string, timeout time.Duration) *HTTPStripeClient {
+	return &HTTPStripeClient{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// stripeObject is the part of a Stripe API response used by the client
+type stripeObject struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// stripeErrorResponse is the body of a failed Stripe API request
+type stripeErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CreateCustomer creates a Stripe customer for a tenant and returns its ID
+func (c *HTTPStripeClient) CreateCustomer(ctx context.Context, tenantID int64, name string) (string, error) {
+	form := url.Values{}
+	form.Set("name", name)
+	form.Set("metadata[tenant_id]", strconv.FormatInt(tenantID, 10))
+
+	var customer stripeObject
+	if err := c.post(ctx, "/customers", form, &customer); err != nil {
+		return "", err
+	}
+
+	log.Printf("[INFO] Created Stripe customer %s for tenant ID %d", customer.ID, tenantID)
+	return customer.ID, nil
+}
+
+// CreateCheckoutSession creates a checkout session that subscribes the customer to a price
+func (c *HTTPStripeClient) CreateCheckoutSession(ctx context.Context, params CheckoutSessionParams) (*CheckoutSession, error) {
+	tenantID := strconv.FormatInt(params.TenantID, 10)
+
+	form := url.Values{}
+	form.Set("mode", "subscription")
+	form.Set("customer", params.CustomerID)
+	form.Set("client_reference_id", tenantID)
+	form.Set("line_items[0][price]", params.PriceID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("success_url", params.SuccessURL)
+	form.Set("cancel_url", params.CancelURL)
+	// Tag the subscription so its webhook events can be matched to the tenant
+	form.Set("subscription_data[metadata][tenant_id]", tenantID)
+
+	var session stripeObject
+	if err := c.post(ctx, "/checkout/sessions", form, &session); err != nil {
+		return nil, err
+	}
+
+	return &CheckoutSession{ID: session.ID, URL: session.URL}, nil
+}
+
+// post sends a form encoded request to the Stripe API and decodes the response into out
+func (c *HTTPStripeClient) post(ctx context.Context, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create Stripe request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		log.Printf("[ERROR] Stripe request to %s failed: %v", path, err)
+		return fmt.Errorf("%w: %v", ErrStripe, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var stripeErr stripeErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&stripeErr); err == nil && stripeErr.Error.Message != "" {
+			log.Printf("[ERROR] Stripe request to %s returned status %d: %s", path, resp.StatusCode, stripeErr.Error.Message)
+			return fmt.Errorf("%w: %s", ErrStripe, stripeErr.Error.Message)
+		}
+		log.Printf("[ERROR] Stripe request to %s returned status %d", path, resp.StatusCode)
+		return fmt.Errorf("%w: unexpected status %d", ErrStripe, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: failed to decode response: %v", ErrStripe, err)
+	}
+
+	return nil
+}
+
+// VerifyWebhookSignature checks the Stripe-Signature header of a webhook payload.
+// The header has the form "t=<timestamp>,v1=<signature>[,v1=...]", where each
+// signature is the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed with the
+// webhook secret. Signatures older than tolerance are rejected to prevent replays.
+func VerifyWebhookSignature(payload []byte, header, secret string, tolerance time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: missing timestamp or signature", ErrInvalidSignature)
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	if tolerance > 0 && now.Sub(time.Unix(seconds, 0)) > tolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: no matching signature", ErrInvalidSignature)
+}
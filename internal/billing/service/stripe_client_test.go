@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signPayload builds a Stripe-Signature header for a payload
+func signPayload(payload []byte, secret string, timestamp time.Time) string {
+	ts := fmt.Sprintf("%d", timestamp.Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + string(payload)))
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	secret := "whsec_test"
+	now := time.Unix(1700000000, 0)
+
+	t.Run("Valid signature", func(t *testing.T) {
+		header := signPayload(payload, secret, now)
+		assert.NoError(t, VerifyWebhookSignature(payload, header, secret, DefaultWebhookTolerance, now))
+	})
+
+	t.Run("Any matching signature is accepted", func(t *testing.T) {
+		header := signPayload(payload, secret, now) + ",v1=deadbeef"
+		assert.NoError(t, VerifyWebhookSignature(payload, header, secret, DefaultWebhookTolerance, now))
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		header := signPayload(payload, "whsec_other", now)
+		err := VerifyWebhookSignature(payload, header, secret, DefaultWebhookTolerance, now)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("Tampered payload", func(t *testing.T) {
+		header := signPayload(payload, secret, now)
+		err := VerifyWebhookSignature([]byte(`{"id":"evt_2"}`), header, secret, DefaultWebhookTolerance, now)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("Expired timestamp", func(t *testing.T) {
+		header := signPayload(payload, secret, now.Add(-10*time.Minute))
+		err := VerifyWebhookSignature(payload, header, secret, DefaultWebhookTolerance, now)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("Missing header", func(t *testing.T) {
+		err := VerifyWebhookSignature(payload, "", secret, DefaultWebhookTolerance, now)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+}
+
+func TestHTTPStripeClient(t *testing.T) {
+	var gotPath string
+	var gotAuth string
+	var gotForm map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		require.NoError(t, r.ParseForm())
+		gotForm = map[string]string{}
+		for key := range r.PostForm {
+			gotForm[key] = r.PostForm.Get(key)
+		}
+
+		switch r.URL.Path {
+		case "/v1/customers":
+			w.Write([]byte(`{"id":"cus_123"}`))
+		case "/v1/checkout/sessions":
+			w.Write([]byte(`{"id":"cs_123","url":"https://checkout.stripe.com/c/cs_123"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"type":"invalid_request_error","message":"No such price"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPStripeClient(server.URL+"/v1", "sk_test", time.Second)
+	ctx := context.Background()
+
+	t.Run("Create customer", func(t *testing.T) {
+		customerID, err := client.CreateCustomer(ctx, 7, "Acme")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "cus_123", customerID)
+		assert.Equal(t, "/v1/customers", gotPath)
+		assert.Equal(t, "Bearer sk_test", gotAuth)
+		assert.Equal(t, "Acme", gotForm["name"])
+		assert.Equal(t, "7", gotForm["metadata[tenant_id]"])
+	})
+
+	t.Run("Create checkout session", func(t *testing.T) {
+		session, err := client.CreateCheckoutSession(ctx, CheckoutSessionParams{
+			CustomerID: "cus_123",
+			PriceID:    "price_pro",
+			TenantID:   7,
+			SuccessURL: "https://app.example.com/tenant/billing?checkout=success",
+			CancelURL:  "https://app.example.com/tenant/billing?checkout=canceled",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://checkout.stripe.com/c/cs_123", session.URL)
+		assert.Equal(t, "subscription", gotForm["mode"])
+		assert.Equal(t, "price_pro", gotForm["line_items[0][price]"])
+		assert.Equal(t, "7", gotForm["client_reference_id"])
+		assert.Equal(t, "7", gotForm["subscription_data[metadata][tenant_id]"])
+	})
+
+	t.Run("Stripe error", func(t *testing.T) {
+		var out stripeObject
+		err := client.post(ctx, "/prices", nil, &out)
+
+		assert.True(t, errors.Is(err, ErrStripe))
+		assert.Contains(t, err.Error(), "No such price")
+	})
+}
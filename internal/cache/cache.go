@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Common errors
+var (
+	ErrCacheOperation = errors.New("cache operation failed")
+)
+
+// Cache is a key-value store for cached values with expiration
+type Cache interface {
+	// Get retrieves a value, reporting whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores a value that expires after ttl (or the cache default if ttl is 0)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the given keys
+	Delete(ctx context.Context, keys ...string) error
+
+	// DeletePrefix removes all keys starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// Pinger is implemented by caches backed by a server, to check that the server
+// is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
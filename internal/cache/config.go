@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Supported cache backends
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+const (
+	// Default values
+	defaultCapacity  = 10000
+	defaultTTL       = 60 * time.Second
+	defaultKeyPrefix = "silocore:"
+
+	// defaultResponseTTL is how long responses of expensive read endpoints are cached
+	defaultResponseTTL = 30 * time.Second
+
+	// defaultIdempotencyTTL is how long responses to requests with an
+	// idempotency key are kept for their retries
+	defaultIdempotencyTTL = 24 * time.Hour
+
+	// Environment variable names
+	envCacheBackend    = "CACHE_BACKEND"
+	envCacheCapacity   = "CACHE_CAPACITY"
+	envCacheTTLSeconds = "CACHE_TTL_SECONDS"
+	envRedisURL        = "REDIS_URL"
+	envResponseTTL     = "RESPONSE_CACHE_TTL_SECONDS"
+	envIdempotencyTTL  = "IDEMPOTENCY_KEY_TTL_SECONDS"
+)
+
+// Config holds configuration for the cache
+type Config struct {
+	Backend  string
+	Capacity int
+	TTL      time.Duration
+	RedisURL string
+
+	// ResponseTTL is how long responses of expensive read endpoints are cached,
+	// 0 to not cache them
+	ResponseTTL time.Duration
+
+	// IdempotencyTTL is how long responses to requests with an idempotency key
+	// are kept, 0 to ignore idempotency keys
+	IdempotencyTTL time.Duration
+}
+
+// LoadConfig loads cache configuration from environment variables
+func LoadConfig() (Config, error) {
+	config := Config{
+		Backend:        os.Getenv(envCacheBackend),
+		Capacity:       defaultCapacity,
+		TTL:            defaultTTL,
+		RedisURL:       os.Getenv(envRedisURL),
+		ResponseTTL:    defaultResponseTTL,
+		IdempotencyTTL: defaultIdempotencyTTL,
+	}
+
+	if config.Backend == "" {
+		config.Backend = BackendMemory
+	}
+
+	if capacityStr := os.Getenv(envCacheCapacity); capacityStr != "" {
+		capacity, err := strconv.Atoi(capacityStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid CACHE_CAPACITY value: %w", err)
+		}
+		config.Capacity = capacity
+	}
+
+	if ttlStr := os.Getenv(envCacheTTLSeconds); ttlStr != "" {
+		ttlSeconds, err := strconv.Atoi(ttlStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid CACHE_TTL_SECONDS value: %w", err)
+		}
+		config.TTL = time.Duration(ttlSeconds) * time.Second
+	}
+
+	if ttlStr := os.Getenv(envResponseTTL); ttlStr != "" {
+		ttlSeconds, err := strconv.Atoi(ttlStr)
+		if err != nil || ttlSeconds < 0 {
+			return Config{}, fmt.Errorf("invalid RESPONSE_CACHE_TTL_SECONDS value: %q", ttlStr)
+		}
+		config.ResponseTTL = time.Duration(ttlSeconds) * time.Second
+	}
+
+	if ttlStr := os.Getenv(envIdempotencyTTL); ttlStr != "" {
+		ttlSeconds, err := strconv.Atoi(ttlStr)
+		if err != nil || ttlSeconds < 0 {
+			return Config{}, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL_SECONDS value: %q", ttlStr)
+		}
+		config.IdempotencyTTL = time.Duration(ttlSeconds) * time.Second
+	}
+
+	return config, nil
+}
+
+// New creates the Cache selected by the configuration.
+// It returns nil if caching is disabled.
+func New(config Config) (Cache, error) {
+	switch config.Backend {
+	case BackendNone:
+		log.Printf("[INFO] Caching disabled")
+		return nil, nil
+	case "", BackendMemory:
+		log.Printf("[INFO] Using in-memory cache with capacity %d and TTL %s", config.Capacity, config.TTL)
+		return NewLRUCache(config.Capacity, config.TTL), nil
+	case BackendRedis:
+		if config.RedisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL environment variable is required for the redis cache backend")
+		}
+		options, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL value: %w", err)
+		}
+		log.Printf("[INFO] Using Redis cache at %s with TTL %s", options.Addr, config.TTL)
+		return NewRedisCache(redis.NewClient(options), defaultKeyPrefix, config.TTL), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s", config.Backend)
+	}
+}
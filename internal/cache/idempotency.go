@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// Cache key prefix for idempotency records
+	idempotencyKeyPrefix = "idempotency:"
+
+	// idempotencyLockTTL bounds how long a request in progress holds its key,
+	// so a key isn't blocked for the full TTL if the server stops mid-request
+	idempotencyLockTTL = 2 * time.Minute
+)
+
+// IdempotencyKey identifies a request made with an Idempotency-Key header. Keys
+// are scoped to the tenant, the user and the route, so clients can't replay the
+// responses of others.
+type IdempotencyKey struct {
+	// TenantID is nil for requests made outside a tenant context
+	TenantID *int64
+	UserID   int64
+
+	// Key is the value of the Idempotency-Key header
+	Key string
+
+	// Route is the method and path of the request, e.g. POST /api/v1/orders
+	Route string
+}
+
+// IdempotentResponse is the record of a request made with an idempotency key
+type IdempotentResponse struct {
+	// Fingerprint is a hash of the request's body, so a key reused for another
+	// request is told apart from a retry
+	Fingerprint string `json:"fingerprint"`
+
+	// InProgress is set while the first request with the key is served
+	InProgress bool `json:"in_progress,omitempty"`
+
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// IdempotencyStore keeps the responses of requests made with an idempotency
+// key, so retries of the request are answered with the same response instead
+// of repeating its effects. Responses expire after the store's TTL. A request in
+// progress holds its key for at most two minutes.
+type IdempotencyStore struct {
+	cache Cache
+	ttl   time.Duration
+}
+
+// NewIdempotencyStore creates a new IdempotencyStore, keeping responses in c for ttl
+func NewIdempotencyStore(c Cache, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		cache: c,
+		ttl:   ttl,
+	}
+}
+
+// Get retrieves the record of a request, reporting whether it was found
+func (s *IdempotencyStore) Get(ctx context.Context, key IdempotencyKey) (*IdempotentResponse, bool, error) {
+	value, found, err := s.cache.Get(ctx, idempotencyCacheKey(key))
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	var response IdempotentResponse
+	if err := json.Unmarshal(value, &response); err != nil {
+		return nil, false, fmt.Errorf("%w: invalid idempotency record: %v", ErrCacheOperation, err)
+	}
+	return &response, true, nil
+}
+
+// Begin records that the first request with a key is in progress. The cache has
+// no atomic insert, so two requests racing with the same key may both begin.
+func (s *IdempotencyStore) Begin(ctx context.Context, key IdempotencyKey, fingerprint string) error {
+	return s.set(ctx, key, IdempotentResponse{Fingerprint: fingerprint, InProgress: true}, idempotencyLockTTL)
+}
+
+// Save stores the response to a request, to be replayed for its retries
+func (s *IdempotencyStore) Save(ctx context.Context, key IdempotencyKey, response IdempotentResponse) error {
+	response.InProgress = false
+	return s.set(ctx, key, response, s.ttl)
+}
+
+// Release forgets a request whose response wasn't saved, such as a server
+// error, so it can be retried with the same key
+func (s *IdempotencyStore) Release(ctx context.Context, key IdempotencyKey) error {
+	return s.cache.Delete(ctx, idempotencyCacheKey(key))
+}
+
+// set stores the record of a request
+func (s *IdempotencyStore) set(ctx context.Context, key IdempotencyKey, response IdempotentResponse, ttl time.Duration) error {
+	value, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheOperation, err)
+	}
+	return s.cache.Set(ctx, idempotencyCacheKey(key), value, ttl)
+}
+
+// idempotencyCacheKey returns the cache key of a request's record. The client's
+// key and the route are hashed, so their length doesn't matter.
+func idempotencyCacheKey(key IdempotencyKey) string {
+	tenant := "none"
+	if key.TenantID != nil {
+		tenant = fmt.Sprint(*key.TenantID)
+	}
+	hash := sha256.Sum256([]byte(key.Route + "\n" + key.Key))
+	return fmt.Sprintf("%stenant:%s:user:%d:%s", idempotencyKeyPrefix, tenant, key.UserID, hex.EncodeToString(hash[:]))
+}
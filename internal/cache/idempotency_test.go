@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	tenantID := int64(1)
+	key := IdempotencyKey{TenantID: &tenantID, UserID: 7, Key: "abc", Route: "POST /api/v1/orders"}
+
+	t.Run("Saves responses for retries", func(t *testing.T) {
+		s := NewIdempotencyStore(NewLRUCache(10, time.Minute), time.Hour)
+		require.NoError(t, s.Begin(ctx, key, "fp"))
+
+		record, found, err := s.Get(ctx, key)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.True(t, record.InProgress)
+
+		require.NoError(t, s.Save(ctx, key, IdempotentResponse{
+			Fingerprint: "fp",
+			Status:      http.StatusCreated,
+			Header:      http.Header{"Content-Type": {"application/json"}},
+			Body:        []byte(`{"id":1}`),
+		}))
+
+		record, found, err = s.Get(ctx, key)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.False(t, record.InProgress)
+		assert.Equal(t, "fp", record.Fingerprint)
+		assert.Equal(t, http.StatusCreated, record.Status)
+		assert.Equal(t, "application/json", record.Header.Get("Content-Type"))
+		assert.Equal(t, []byte(`{"id":1}`), record.Body)
+	})
+
+	t.Run("Scopes keys by tenant, user and route", func(t *testing.T) {
+		s := NewIdempotencyStore(NewLRUCache(10, time.Minute), time.Hour)
+		require.NoError(t, s.Save(ctx, key, IdempotentResponse{Fingerprint: "fp", Status: http.StatusOK}))
+
+		otherTenant := int64(2)
+		for _, other := range []IdempotencyKey{
+			{TenantID: &otherTenant, UserID: 7, Key: "abc", Route: "POST /api/v1/orders"},
+			{TenantID: nil, UserID: 7, Key: "abc", Route: "POST /api/v1/orders"},
+			{TenantID: &tenantID, UserID: 8, Key: "abc", Route: "POST /api/v1/orders"},
+			{TenantID: &tenantID, UserID: 7, Key: "abc", Route: "POST /api/v1/orders/bulk"},
+		} {
+			_, found, err := s.Get(ctx, other)
+			require.NoError(t, err)
+			assert.False(t, found, "%+v", other)
+		}
+	})
+
+	t.Run("Release forgets the request", func(t *testing.T) {
+		s := NewIdempotencyStore(NewLRUCache(10, time.Minute), time.Hour)
+		require.NoError(t, s.Begin(ctx, key, "fp"))
+		require.NoError(t, s.Release(ctx, key))
+
+		_, found, err := s.Get(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
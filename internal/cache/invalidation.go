@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// InvalidationChannel is the Postgres notification channel that database
+// triggers broadcast changes to cached data on
+const InvalidationChannel = "cache_invalidation"
+
+const (
+	// Reconnect intervals of the listener's connection
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+
+	// listenerPingInterval is how often an idle connection is checked
+	listenerPingInterval = 90 * time.Second
+)
+
+// Change is a committed change to a row of cached data. UserID and TenantID are
+// nil if the table has no such column, or for statement-level changes.
+type Change struct {
+	Table    string `json:"table"`
+	UserID   *int64 `json:"user_id"`
+	TenantID *int64 `json:"tenant_id"`
+}
+
+// ChangeHandler invalidates the cache entries affected by a change
+type ChangeHandler func(ctx context.Context, change Change)
+
+// InvalidationListener listens for changes to cached data with Postgres
+// LISTEN/NOTIFY and passes them to the handlers of their table, so each server
+// instance can invalidate its in-memory cache without a shared cache server
+type InvalidationListener struct {
+	databaseURL string
+	handlers    map[string][]ChangeHandler
+	onReconnect []func(ctx context.Context)
+}
+
+// NewInvalidationListener creates a new InvalidationListener connecting to the
+// database at databaseURL
+func NewInvalidationListener(databaseURL string) *InvalidationListener {
+	return &InvalidationListener{
+		databaseURL: databaseURL,
+		handlers:    make(map[string][]ChangeHandler),
+	}
+}
+
+// OnChange calls handler for every change to table
+func (l *InvalidationListener) OnChange(table string, handler ChangeHandler) *InvalidationListener {
+	l.handlers[table] = append(l.handlers[table], handler)
+	return l
+}
+
+// OnReconnect calls fn when the connection is restored after it was lost.
+// Changes made in the meantime are not received, so fn should drop everything
+// the handlers would have.
+func (l *InvalidationListener) OnReconnect(fn func(ctx context.Context)) *InvalidationListener {
+	l.onReconnect = append(l.onReconnect, fn)
+	return l
+}
+
+// Run listens for changes until ctx is done. Handlers must be registered before.
+// A lost connection is re-established, waiting longer after every failed try.
+func (l *InvalidationListener) Run(ctx context.Context) error {
+	conn, err := l.listen(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: failed to listen on %s: %v", ErrCacheOperation, InvalidationChannel, err)
+	}
+	defer func() {
+		conn.Close(context.Background())
+	}()
+	log.Printf("[INFO] Listening for cache invalidations on %s", InvalidationChannel)
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, listenerPingInterval)
+		notification, err := conn.WaitForNotification(waitCtx)
+		idle := waitCtx.Err() != nil
+		cancel()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			l.dispatch(ctx, notification.Payload)
+			continue
+		}
+
+		// Check that an idle connection is still alive
+		if idle {
+			if err = conn.Ping(ctx); err == nil {
+				continue
+			}
+		}
+
+		log.Printf("[WARN] Cache invalidation listener: %v", err)
+		conn.Close(context.Background())
+		reconnected, err := l.reconnect(ctx)
+		if err != nil {
+			return nil
+		}
+		conn = reconnected
+		log.Printf("[WARN] Cache invalidation listener reconnected; dropping cached entries")
+		for _, fn := range l.onReconnect {
+			fn(ctx)
+		}
+	}
+}
+
+// listen opens a connection listening on the invalidation channel
+func (l *InvalidationListener) listen(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, l.databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{InvalidationChannel}.Sanitize()); err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+	return conn, nil
+}
+
+// reconnect opens a new listening connection, retrying until it succeeds or ctx
+// is done
+func (l *InvalidationListener) reconnect(ctx context.Context) (*pgx.Conn, error) {
+	delay := listenerMinReconnect
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		conn, err := l.listen(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		log.Printf("[WARN] Cache invalidation listener: %v", err)
+		delay = min(2*delay, listenerMaxReconnect)
+	}
+}
+
+// dispatch passes a change notification to the handlers of its table
+func (l *InvalidationListener) dispatch(ctx context.Context, payload string) {
+	var change Change
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		log.Printf("[WARN] Ignoring invalid cache invalidation %q: %v", payload, err)
+		return
+	}
+
+	for _, handler := range l.handlers[change.Table] {
+		handler(ctx, change)
+	}
+}
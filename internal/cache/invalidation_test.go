@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidationListenerDispatch(t *testing.T) {
+	ctx := context.Background()
+
+	var changes []Change
+	record := func(ctx context.Context, change Change) {
+		changes = append(changes, change)
+	}
+	listener := NewInvalidationListener("").
+		OnChange("user_role", record).
+		OnChange("role", record)
+
+	t.Run("Row change", func(t *testing.T) {
+		changes = nil
+		listener.dispatch(ctx, `{"table": "user_role", "user_id": 7, "tenant_id": 42}`)
+
+		require.Len(t, changes, 1)
+		assert.Equal(t, "user_role", changes[0].Table)
+		require.NotNil(t, changes[0].UserID)
+		assert.Equal(t, int64(7), *changes[0].UserID)
+		require.NotNil(t, changes[0].TenantID)
+		assert.Equal(t, int64(42), *changes[0].TenantID)
+	})
+
+	t.Run("Statement change", func(t *testing.T) {
+		changes = nil
+		listener.dispatch(ctx, `{"table": "role", "user_id": null, "tenant_id": null}`)
+
+		require.Len(t, changes, 1)
+		assert.Nil(t, changes[0].UserID)
+		assert.Nil(t, changes[0].TenantID)
+	})
+
+	t.Run("Unhandled table", func(t *testing.T) {
+		changes = nil
+		listener.dispatch(ctx, `{"table": "tenant_quota", "tenant_id": 42}`)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		changes = nil
+		listener.dispatch(ctx, `not json`)
+		assert.Empty(t, changes)
+	})
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lruEntry is an entry in the LRU cache
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entries
+// once it reaches capacity. Entries also expire after their TTL.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+// Ensure LRUCache implements Cache
+var _ Cache = (*LRUCache)(nil)
+
+// NewLRUCache creates a new LRUCache holding at most capacity entries with the
+// given default TTL
+func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get retrieves a value, reporting whether it was found
+func (c *LRUCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && c.now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores a value that expires after ttl (or the cache default if ttl is 0)
+func (c *LRUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	// Evict the least recently used entries beyond capacity
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the given keys
+func (c *LRUCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.removeElement(elem)
+		}
+	}
+	return nil
+}
+
+// DeletePrefix removes all keys starting with prefix
+func (c *LRUCache) DeletePrefix(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(elem)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// that have not been evicted yet
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeElement removes an element from the cache; the caller must hold the lock
+func (c *LRUCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Get returns stored value", func(t *testing.T) {
+		c := NewLRUCache(10, time.Minute)
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+
+		value, found, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []byte("1"), value)
+
+		_, found, err = c.Get(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Evicts least recently used entry", func(t *testing.T) {
+		c := NewLRUCache(2, time.Minute)
+		c.Set(ctx, "a", []byte("1"), 0)
+		c.Set(ctx, "b", []byte("2"), 0)
+
+		// Touch "a" so "b" becomes the least recently used
+		c.Get(ctx, "a")
+		c.Set(ctx, "c", []byte("3"), 0)
+
+		_, found, _ := c.Get(ctx, "b")
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, "a")
+		assert.True(t, found)
+		_, found, _ = c.Get(ctx, "c")
+		assert.True(t, found)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("Expires entries after TTL", func(t *testing.T) {
+		now := time.Now()
+		c := NewLRUCache(10, time.Minute)
+		c.now = func() time.Time { return now }
+
+		c.Set(ctx, "a", []byte("1"), 0)
+		c.Set(ctx, "b", []byte("2"), 5*time.Minute)
+
+		now = now.Add(2 * time.Minute)
+
+		_, found, _ := c.Get(ctx, "a")
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, "b")
+		assert.True(t, found)
+	})
+
+	t.Run("Deletes keys and prefixes", func(t *testing.T) {
+		c := NewLRUCache(10, time.Minute)
+		c.Set(ctx, "roles:user:1:system", []byte("1"), 0)
+		c.Set(ctx, "roles:user:1:tenant:2", []byte("1"), 0)
+		c.Set(ctx, "roles:user:10:system", []byte("1"), 0)
+		c.Set(ctx, "other", []byte("1"), 0)
+
+		require.NoError(t, c.DeletePrefix(ctx, "roles:user:1:"))
+		assert.Equal(t, 2, c.Len())
+
+		require.NoError(t, c.Delete(ctx, "other"))
+		_, found, _ := c.Get(ctx, "other")
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, "roles:user:10:system")
+		assert.True(t, found)
+	})
+}
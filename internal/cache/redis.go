@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisScanCount is the number of keys requested per SCAN iteration
+const redisScanCount = 100
+
+// RedisCache is a Cache backed by Redis, allowing cached values to be shared
+// and invalidated across application instances
+type RedisCache struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// Ensure RedisCache implements Cache
+var _ Cache = (*RedisCache)(nil)
+
+// Ensure RedisCache implements Pinger
+var _ Pinger = (*RedisCache)(nil)
+
+// NewRedisCache creates a new RedisCache. All keys are namespaced with keyPrefix.
+func NewRedisCache(client *redis.Client, keyPrefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+	}
+}
+
+// Ping checks that Redis is reachable
+func (c *RedisCache) Ping(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheOperation, err)
+	}
+	return nil
+}
+
+// Get retrieves a value, reporting whether it was found
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("%w: %v", ErrCacheOperation, err)
+	}
+	return value, true, nil
+}
+
+// Set stores a value that expires after ttl (or the cache default if ttl is 0)
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+	if err := c.client.Set(ctx, c.keyPrefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheOperation, err)
+	}
+	return nil
+}
+
+// Delete removes the given keys
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.keyPrefix + key
+	}
+
+	if err := c.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheOperation, err)
+	}
+	return nil
+}
+
+// DeletePrefix removes all keys starting with prefix
+func (c *RedisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := c.client.Scan(ctx, 0, c.keyPrefix+prefix+"*", redisScanCount).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheOperation, err)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheOperation, err)
+	}
+	return nil
+}
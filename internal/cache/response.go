@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Cache key prefix for cached responses
+const responseKeyPrefix = "response:tenant:"
+
+// ResponseCache keeps the responses of expensive read endpoints, such as order
+// summaries and tenant statistics, for each tenant. Responses are keyed by the
+// tenant, the request path and its query, and expire after the cache's TTL or
+// when the services changing the data they were computed from invalidate the
+// tenant's responses.
+type ResponseCache struct {
+	cache Cache
+	ttl   time.Duration
+}
+
+// NewResponseCache creates a new ResponseCache, keeping responses in c for ttl
+func NewResponseCache(c Cache, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		cache: c,
+		ttl:   ttl,
+	}
+}
+
+// Get retrieves the cached response to a tenant's request, reporting whether it
+// was found
+func (c *ResponseCache) Get(ctx context.Context, tenantID int64, u *url.URL) ([]byte, bool, error) {
+	return c.cache.Get(ctx, responseKey(tenantID, u))
+}
+
+// Set caches the response to a tenant's request
+func (c *ResponseCache) Set(ctx context.Context, tenantID int64, u *url.URL, body []byte) error {
+	return c.cache.Set(ctx, responseKey(tenantID, u), body, c.ttl)
+}
+
+// InvalidateTenant removes all cached responses of a tenant
+func (c *ResponseCache) InvalidateTenant(ctx context.Context, tenantID int64) error {
+	return c.cache.DeletePrefix(ctx, fmt.Sprintf("%s%d:", responseKeyPrefix, tenantID))
+}
+
+// InvalidateAll removes the cached responses of all tenants
+func (c *ResponseCache) InvalidateAll(ctx context.Context) error {
+	return c.cache.DeletePrefix(ctx, responseKeyPrefix)
+}
+
+// responseKey returns the cache key for the response to a tenant's request. The
+// query parameters are sorted, so their order doesn't matter.
+func responseKey(tenantID int64, u *url.URL) string {
+	key := fmt.Sprintf("%s%d:%s", responseKeyPrefix, tenantID, u.Path)
+	if query := u.Query().Encode(); query != "" {
+		key += "?" + query
+	}
+	return key
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	ctx := context.Background()
+	summary, _ := url.Parse("/orders/api/summary?interval=week&from=2024-01-01")
+	stats, _ := url.Parse("/tenant/stats")
+
+	t.Run("Keys responses by tenant, path and query", func(t *testing.T) {
+		c := NewResponseCache(NewLRUCache(10, time.Minute), time.Minute)
+		require.NoError(t, c.Set(ctx, 1, summary, []byte(`{"total":1}`)))
+
+		// The order of query parameters doesn't matter
+		reordered, _ := url.Parse("/orders/api/summary?from=2024-01-01&interval=week")
+		body, found, err := c.Get(ctx, 1, reordered)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []byte(`{"total":1}`), body)
+
+		_, found, _ = c.Get(ctx, 2, summary)
+		assert.False(t, found, "other tenants don't share responses")
+
+		other, _ := url.Parse("/orders/api/summary?interval=month&from=2024-01-01")
+		_, found, _ = c.Get(ctx, 1, other)
+		assert.False(t, found, "other queries don't share responses")
+	})
+
+	t.Run("InvalidateTenant removes only the tenant's responses", func(t *testing.T) {
+		c := NewResponseCache(NewLRUCache(10, time.Minute), time.Minute)
+		c.Set(ctx, 1, summary, []byte("1"))
+		c.Set(ctx, 1, stats, []byte("1"))
+		c.Set(ctx, 11, summary, []byte("11"))
+
+		require.NoError(t, c.InvalidateTenant(ctx, 1))
+
+		_, found, _ := c.Get(ctx, 1, summary)
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, 1, stats)
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, 11, summary)
+		assert.True(t, found)
+	})
+
+	t.Run("InvalidateAll removes every tenant's responses", func(t *testing.T) {
+		c := NewResponseCache(NewLRUCache(10, time.Minute), time.Minute)
+		c.Set(ctx, 1, summary, []byte("1"))
+		c.Set(ctx, 2, summary, []byte("2"))
+
+		require.NoError(t, c.InvalidateAll(ctx))
+
+		_, found, _ := c.Get(ctx, 1, summary)
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, 2, summary)
+		assert.False(t, found)
+	})
+}
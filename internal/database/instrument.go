@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// instrumentedConnector opens connections that trace their queries and record
+// their durations
+type instrumentedConnector struct {
+	driver.Connector
+	metrics *QueryMetrics
+}
+
+// Connect opens an instrumented connection
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, metrics: c.metrics}, nil
+}
+
+// instrumentedConn traces the queries run on a driver connection and records
+// their durations, unless metrics is nil.
+// It passes the driver's optional interfaces through, so database/sql uses the
+// connection as it would the driver's. Queries through prepared statements are
+// not recorded; database/sql only prepares statements for drivers without
+// QueryerContext and ExecerContext, or when asked to.
+type instrumentedConn struct {
+	driver.Conn
+	metrics *QueryMetrics
+}
+
+// Ensure instrumentedConn passes through the interfaces database/sql relies on
+var (
+	_ driver.ExecerContext      = (*instrumentedConn)(nil)
+	_ driver.QueryerContext     = (*instrumentedConn)(nil)
+	_ driver.ConnBeginTx        = (*instrumentedConn)(nil)
+	_ driver.ConnPrepareContext = (*instrumentedConn)(nil)
+	_ driver.Pinger             = (*instrumentedConn)(nil)
+	_ driver.SessionResetter    = (*instrumentedConn)(nil)
+	_ driver.Validator          = (*instrumentedConn)(nil)
+	_ driver.NamedValueChecker  = (*instrumentedConn)(nil)
+)
+
+// ExecContext runs, traces and records a statement
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startQuerySpan(ctx, query)
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	c.observe(query, len(args), time.Since(start), err)
+	endQuerySpan(span, err)
+	return result, err
+}
+
+// QueryContext runs, traces and records a query, up to its first rows being
+// available
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startQuerySpan(ctx, query)
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.observe(query, len(args), time.Since(start), err)
+	endQuerySpan(span, err)
+	return rows, err
+}
+
+// observe records the duration of a query the driver ran
+func (c *instrumentedConn) observe(query string, args int, d time.Duration, err error) {
+	if c.metrics != nil && err != driver.ErrSkip {
+		c.metrics.Observe(query, args, d, err)
+	}
+}
+
+// BeginTx starts a transaction with the given options
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.ReadOnly || opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("driver does not support transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+// PrepareContext prepares a statement
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// Ping checks that the connection is alive
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession prepares the connection for reuse
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the connection can be reused
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue lets the driver check and convert query arguments itself, as
+// pgx does for the types it encodes, or leaves them to database/sql
+func (c *instrumentedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// defaultSlowQueryThreshold is the duration above which queries are logged
+	defaultSlowQueryThreshold = 500 * time.Millisecond
+
+	// envSlowQueryMS is the slow query threshold in milliseconds, 0 to disable
+	envSlowQueryMS = "DB_SLOW_QUERY_MS"
+
+	// maxLoggedQueryLength truncates long queries in the slow query log
+	maxLoggedQueryLength = 1000
+)
+
+// queryBuckets are the upper bounds of the query duration histogram buckets
+var queryBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// queryOperations are the statements queries are tagged with. Other statements
+// are tagged "other".
+var queryOperations = map[string]bool{
+	"select": true,
+	"insert": true,
+	"update": true,
+	"delete": true,
+	"with":   true,
+}
+
+var (
+	// stringLiteral matches SQL string literals, which may hold personal data
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+	// whitespace matches runs of whitespace, collapsed in logged queries
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+// QueryLogConfig holds the settings of query logging
+type QueryLogConfig struct {
+	// SlowQueryThreshold is the duration above which queries are logged, 0 to
+	// log none
+	SlowQueryThreshold time.Duration
+}
+
+// LoadQueryLogConfig loads query logging settings from environment variables
+func LoadQueryLogConfig() (QueryLogConfig, error) {
+	config := QueryLogConfig{
+		SlowQueryThreshold: defaultSlowQueryThreshold,
+	}
+
+	if value := os.Getenv(envSlowQueryMS); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return QueryLogConfig{}, fmt.Errorf("invalid DB_SLOW_QUERY_MS value: %q", value)
+		}
+		config.SlowQueryThreshold = time.Duration(ms) * time.Millisecond
+	}
+
+	return config, nil
+}
+
+// QueryMetrics records the durations of queries in histograms by operation and
+// logs slow queries, with their parameters and string literals redacted
+type QueryMetrics struct {
+	slowThreshold time.Duration
+
+	mu          sync.Mutex
+	operations  map[string]*queryHistogram
+	slowQueries int64
+}
+
+// queryHistogram counts the queries of an operation by duration
+type queryHistogram struct {
+	count   int64
+	errors  int64
+	total   time.Duration
+	max     time.Duration
+	buckets []int64
+}
+
+// NewQueryMetrics creates a new QueryMetrics with the given logging settings
+func NewQueryMetrics(config QueryLogConfig) *QueryMetrics {
+	return &QueryMetrics{
+		slowThreshold: config.SlowQueryThreshold,
+		operations:    make(map[string]*queryHistogram),
+	}
+}
+
+// Observe records a query that took d, logging it if it was slow
+func (m *QueryMetrics) Observe(query string, args int, d time.Duration, err error) {
+	operation := queryOperation(query)
+	slow := m.slowThreshold > 0 && d >= m.slowThreshold
+
+	m.mu.Lock()
+	h, ok := m.operations[operation]
+	if !ok {
+		h = &queryHistogram{buckets: make([]int64, len(queryBuckets))}
+		m.operations[operation] = h
+	}
+	h.count++
+	if err != nil {
+		h.errors++
+	}
+	h.total += d
+	h.max = max(h.max, d)
+	for i, bound := range queryBuckets {
+		if d <= bound {
+			h.buckets[i]++
+		}
+	}
+	if slow {
+		m.slowQueries++
+	}
+	m.mu.Unlock()
+
+	if slow {
+		log.Printf("[WARN] Slow %s query took %s (%d parameters redacted): %s", operation, d.Round(time.Millisecond), args, RedactQuery(query))
+	}
+}
+
+// QueryStats are the recorded query durations, for monitoring
+type QueryStats struct {
+	SlowQueryThresholdMS int64                     `json:"slow_query_threshold_ms"`
+	SlowQueries          int64                     `json:"slow_queries"`
+	Operations           map[string]OperationStats `json:"operations"`
+}
+
+// OperationStats are the recorded durations of an operation's queries. Buckets
+// count the queries that took at most each duration, keyed like "50ms", so they
+// are cumulative; Count includes the queries slower than the last bucket.
+type OperationStats struct {
+	Count   int64            `json:"count"`
+	Errors  int64            `json:"errors"`
+	TotalMS float64          `json:"total_ms"`
+	MaxMS   float64          `json:"max_ms"`
+	Buckets map[string]int64 `json:"buckets"`
+}
+
+// Stats returns the durations recorded so far
+func (m *QueryMetrics) Stats() QueryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := QueryStats{
+		SlowQueryThresholdMS: m.slowThreshold.Milliseconds(),
+		SlowQueries:          m.slowQueries,
+		Operations:           make(map[string]OperationStats, len(m.operations)),
+	}
+	for operation, h := range m.operations {
+		buckets := make(map[string]int64, len(queryBuckets))
+		for i, bound := range queryBuckets {
+			buckets[bound.String()] = h.buckets[i]
+		}
+		stats.Operations[operation] = OperationStats{
+			Count:   h.count,
+			Errors:  h.errors,
+			TotalMS: milliseconds(h.total),
+			MaxMS:   milliseconds(h.max),
+			Buckets: buckets,
+		}
+	}
+	return stats
+}
+
+// queryOperation returns the statement a query starts with, in lower case
+func queryOperation(query string) string {
+	keyword := strings.TrimLeftFunc(query, unicode.IsSpace)
+	if end := strings.IndexFunc(keyword, func(r rune) bool { return unicode.IsSpace(r) || r == '(' }); end >= 0 {
+		keyword = keyword[:end]
+	}
+	keyword = strings.ToLower(keyword)
+	if queryOperations[keyword] {
+		return keyword
+	}
+	return "other"
+}
+
+// RedactQuery prepares a query for logging, replacing string literals with '?'
+// and collapsing whitespace. Parameters are never part of the query text.
+func RedactQuery(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "'?'")
+	query = strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+	if len(query) > maxLoggedQueryLength {
+		query = query[:maxLoggedQueryLength] + "..."
+	}
+	return query
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
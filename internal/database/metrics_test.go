@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadQueryLogConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		config, err := LoadQueryLogConfig()
+
+		require.NoError(t, err)
+		assert.Equal(t, defaultSlowQueryThreshold, config.SlowQueryThreshold)
+	})
+
+	t.Run("From environment", func(t *testing.T) {
+		t.Setenv(envSlowQueryMS, "0")
+
+		config, err := LoadQueryLogConfig()
+
+		require.NoError(t, err)
+		assert.Zero(t, config.SlowQueryThreshold)
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		t.Setenv(envSlowQueryMS, "-1")
+
+		_, err := LoadQueryLogConfig()
+
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryMetrics(t *testing.T) {
+	metrics := NewQueryMetrics(QueryLogConfig{SlowQueryThreshold: time.Second})
+
+	metrics.Observe("SELECT 1", 0, 3*time.Millisecond, nil)
+	metrics.Observe("\n\t\tselect * FROM usr WHERE email = $1", 1, 2*time.Second, nil)
+	metrics.Observe("UPDATE usr SET first_name = $1", 1, 20*time.Millisecond, errors.New("failed"))
+	metrics.Observe("SELECT set_config('core.tenant_context', $1::TEXT, TRUE)", 1, time.Millisecond, nil)
+	metrics.Observe("VACUUM", 0, time.Millisecond, nil)
+
+	stats := metrics.Stats()
+
+	assert.Equal(t, int64(1000), stats.SlowQueryThresholdMS)
+	assert.Equal(t, int64(1), stats.SlowQueries)
+	require.Contains(t, stats.Operations, "select")
+	selects := stats.Operations["select"]
+	assert.Equal(t, int64(3), selects.Count)
+	assert.Equal(t, 2000.0, selects.MaxMS)
+	assert.Equal(t, int64(1), selects.Buckets["1ms"])
+	assert.Equal(t, int64(2), selects.Buckets["5ms"])
+	assert.Equal(t, int64(2), selects.Buckets["1s"])
+	assert.Equal(t, int64(3), selects.Buckets["5s"])
+	assert.Equal(t, int64(1), stats.Operations["update"].Errors)
+	assert.Equal(t, int64(1), stats.Operations["other"].Count)
+}
+
+func TestRedactQuery(t *testing.T) {
+	query := `
+		SELECT id FROM usr
+		WHERE email = 'jane@example.com' AND note = 'it''s' AND id = $1
+	`
+
+	assert.Equal(t, "SELECT id FROM usr WHERE email = '?' AND note = '?' AND id = $1", RedactQuery(query))
+}
+
+// mockConnector opens connections to a sqlmock database
+type mockConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c mockConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c mockConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func TestInstrumentedConn(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("instrumented")
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	metrics := NewQueryMetrics(QueryLogConfig{})
+	db := sql.OpenDB(&instrumentedConnector{
+		Connector: mockConnector{dsn: "instrumented", driver: mockDB.Driver()},
+		metrics:   metrics,
+	})
+	defer db.Close()
+
+	// Expect queries in and out of a read-only transaction
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT name FROM tenant").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("acme"))
+	mock.ExpectCommit()
+	mock.ExpectExec("DELETE FROM tenant").
+		WillReturnError(errors.New("permission denied"))
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	var name string
+	require.NoError(t, tx.QueryRow("SELECT name FROM tenant WHERE id = $1", 1).Scan(&name))
+	require.NoError(t, tx.Commit())
+	_, err = db.Exec("DELETE FROM tenant")
+	assert.Error(t, err)
+
+	// Verify the queries were recorded
+	stats := metrics.Stats()
+	assert.Equal(t, int64(1), stats.Operations["select"].Count)
+	assert.Equal(t, int64(1), stats.Operations["delete"].Errors)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,27 +4,45 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/lib/pq"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	migrationfiles "github.com/unsavory/silocore-go/sql/migrations"
 )
 
+// ErrDirtyDatabase is returned when a migration failed partway, marking the
+// database dirty at its version. Migrations do not run until it is repaired.
+var ErrDirtyDatabase = errors.New("database is dirty")
+
 // MigrateOptions contains options for running migrations
 type MigrateOptions struct {
 	// DatabaseURL is the connection string for the database
 	DatabaseURL string
-	// MigrationsPath is the path to the migrations directory
+	// MigrationsPath is the path to the migrations directory. If empty, the
+	// migrations embedded in the binary are used.
 	MigrationsPath string
 	// MigrateUp indicates whether to migrate up or down
 	MigrateUp bool
 	// Steps is the number of migrations to apply (0 means all)
 	Steps int
+	// Repair retries the failed migration of a dirty database when migrating up,
+	// for migrations that failed without changing the schema. Otherwise a dirty
+	// database is an error explaining how to repair it by hand.
+	Repair bool
+	// DryRun lists the migrations that would be applied and prints their SQL
+	// to stdout instead of applying them
+	DryRun bool
 }
 
 // RunMigrationsUp is a convenience function to run all migrations up
@@ -53,49 +71,15 @@ func RunMigrationsDown(dbURL, migrationsPath string) error {
 
 // RunMigrations runs database migrations based on the provided options
 func RunMigrations(opts MigrateOptions) error {
-	log.Printf("Running migrations with options: path=%s, up=%t, steps=%d",
-		opts.MigrationsPath, opts.MigrateUp, opts.Steps)
-
-	// Connect to the database
-	db, err := sql.Open("postgres", opts.DatabaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
-	}
-	defer db.Close()
-
-	// Ping the database to ensure the connection is valid
-	if err := db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	// Get the absolute path to the migrations directory
-	absPath, err := filepath.Abs(opts.MigrationsPath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute path for migrations: %w", err)
-	}
-
-	// Check if the migrations directory exists
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("migrations directory does not exist: %s", absPath)
-	}
-
-	// Create a new postgres driver instance
-	driver, err := postgres.WithInstance(db, &postgres.Config{
-		MigrationsTable: "_migration",
-		// Set the search path to public schema
-		SchemaName: "public",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create postgres driver instance: %w", err)
-	}
+	log.Printf("Running migrations with options: path=%s, up=%t, steps=%d, repair=%t, dry-run=%t",
+		opts.MigrationsPath, opts.MigrateUp, opts.Steps, opts.Repair, opts.DryRun)
 
-	// Create a new migrate instance
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file:///%s", absPath),
-		"postgres", driver)
+	// Connect to the database and read the migrations
+	m, migrations, err := newMigrate(opts.DatabaseURL, opts.MigrationsPath)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
+	defer m.Close()
 
 	// Set up a function to log migration version
 	logVersion := func() {
@@ -115,7 +99,7 @@ func RunMigrations(opts MigrateOptions) error {
 	logVersion()
 
 	// Count and log the number of migrations to be applied
-	files, err := os.ReadDir(absPath)
+	files, err := fs.ReadDir(migrations, ".")
 	if err != nil {
 		log.Printf("Warning: Failed to read migrations directory: %v", err)
 	} else {
@@ -129,6 +113,15 @@ func RunMigrations(opts MigrateOptions) error {
 		log.Printf("Found %d migration files", len(migrationFiles))
 	}
 
+	if opts.DryRun {
+		return dryRun(m, migrations, opts, os.Stdout)
+	}
+
+	// A dirty database must be repaired before migrating
+	if err := repairDirty(m, migrations, opts); err != nil {
+		return err
+	}
+
 	// Start time for measuring migration duration
 	startTime := time.Now()
 
@@ -157,6 +150,10 @@ func RunMigrations(opts MigrateOptions) error {
 		if errors.Is(migrationErr, migrate.ErrNoChange) {
 			log.Println("No migration needed, database is up to date")
 		} else {
+			// Explain how to repair the database if the failure left it dirty
+			if version, dirty, err := migrationVersion(m); err == nil && dirty {
+				log.Printf("[WARN] %v", dirtyError(version, previousVersion(migrations, version)))
+			}
 			return fmt.Errorf("migration failed: %w", migrationErr)
 		}
 	}
@@ -168,3 +165,387 @@ func RunMigrations(opts MigrateOptions) error {
 
 	return nil
 }
+
+// ForceMigrationVersion sets the database's schema version and clears the dirty
+// flag without running any migration, after a failed migration was repaired by
+// hand. A version of -1 marks no migration as applied.
+func ForceMigrationVersion(databaseURL, migrationsPath string, version int) error {
+	m, _, err := newMigrate(databaseURL, migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("failed to force migration version %d: %w", version, err)
+	}
+	log.Printf("Forced migration version to %d", version)
+	return nil
+}
+
+// repairDirty returns an error explaining the dirty state if the database is
+// dirty, unless opts.Repair is set when migrating up. Then it forces the version
+// back to before the failed migration, so that it is retried.
+func repairDirty(m *migrate.Migrate, migrations fs.FS, opts MigrateOptions) error {
+	version, dirty, err := migrationVersion(m)
+	if err != nil || !dirty {
+		return err
+	}
+
+	previous := previousVersion(migrations, version)
+	if !opts.Repair || !opts.MigrateUp {
+		return dirtyError(version, previous)
+	}
+
+	log.Printf("[WARN] Repairing dirty migration %d: forcing version %d to retry it", version, previous)
+	if err := m.Force(previous); err != nil {
+		return fmt.Errorf("failed to force migration version %d: %w", previous, err)
+	}
+	return nil
+}
+
+// previousVersion returns the version of the migration before version, or -1 if
+// there is none
+func previousVersion(migrations fs.FS, version uint) int {
+	files, err := listMigrations(migrations)
+	if err != nil {
+		return -1
+	}
+
+	previous := -1
+	for _, file := range files {
+		if file.Version < version {
+			previous = int(file.Version)
+		}
+	}
+	return previous
+}
+
+// dirtyError explains how to repair a database that is dirty at version
+func dirtyError(version uint, previous int) error {
+	return fmt.Errorf("%w: the migration to version %d failed partway. "+
+		"Run with -repair to retry it if it made no changes, as when its transaction was rolled back. "+
+		"Otherwise undo its changes by hand and run `migrate force %d` to retry it, "+
+		"or complete them and run `migrate force %d`",
+		ErrDirtyDatabase, version, previous, version)
+}
+
+// dryRun writes the migrations that opts would apply, with their SQL, to w
+func dryRun(m *migrate.Migrate, migrations fs.FS, opts MigrateOptions, w io.Writer) error {
+	version, dirty, err := migrationVersion(m)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		log.Printf("[WARN] Database is dirty at version %d; migrations will not run until it is fixed", version)
+	}
+
+	files, err := listMigrations(migrations)
+	if err != nil {
+		return err
+	}
+
+	plan := planMigrations(files, version, opts.MigrateUp, opts.Steps)
+	log.Printf("Dry run: %d migrations would be applied %s from version %d", len(plan), direction(opts.MigrateUp), version)
+	return writePlan(w, migrations, plan, opts.MigrateUp)
+}
+
+// planMigrations returns the migrations that migrating up or down from version
+// would apply, in the order they would be applied. Steps limits the number of
+// migrations; 0 means all.
+func planMigrations(migrations []MigrationState, version uint, up bool, steps int) []MigrationState {
+	var plan []MigrationState
+	if up {
+		for _, migration := range migrations {
+			if migration.Version > version {
+				plan = append(plan, migration)
+			}
+		}
+	} else {
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if migrations[i].Version <= version {
+				plan = append(plan, migrations[i])
+			}
+		}
+	}
+
+	if steps > 0 && len(plan) > steps {
+		plan = plan[:steps]
+	}
+	return plan
+}
+
+// writePlan writes each planned migration's file name and SQL to w
+func writePlan(w io.Writer, migrations fs.FS, plan []MigrationState, up bool) error {
+	for _, migration := range plan {
+		name := fmt.Sprintf("%d_%s.%s.sql", migration.Version, migration.Name, direction(up))
+		body, err := fs.ReadFile(migrations, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(w, "-- %s: no %s migration\n\n", name, direction(up))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		fmt.Fprintf(w, "-- %s\n%s\n\n", name, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// direction names the direction of a migration as in its file name
+func direction(up bool) string {
+	if up {
+		return "up"
+	}
+	return "down"
+}
+
+// MigrationState is a migration file and whether it has been applied
+type MigrationState struct {
+	Version uint
+	Name    string
+	Applied bool
+	// Dirty is set on the current migration if it failed partway
+	Dirty bool
+}
+
+// MigrationStatus is the schema version of a database and the state of each
+// migration. Version is 0 if no migration has been applied.
+type MigrationStatus struct {
+	Version    uint
+	Dirty      bool
+	Migrations []MigrationState
+}
+
+// migrationFile matches the names of up migrations: {version}_{name}.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+// GetMigrationVersion returns the database's schema version and whether the
+// migration to it failed partway. The version is 0 if no migration has been
+// applied.
+func GetMigrationVersion(databaseURL, migrationsPath string) (uint, bool, error) {
+	m, _, err := newMigrate(databaseURL, migrationsPath)
+	if err != nil {
+		return 0, false, err
+	}
+	defer m.Close()
+
+	return migrationVersion(m)
+}
+
+// GetMigrationStatus returns the database's schema version and lists the
+// migrations in migrationsPath, or the embedded ones if it is empty, as applied
+// or pending
+func GetMigrationStatus(databaseURL, migrationsPath string) (*MigrationStatus, error) {
+	m, migrations, err := newMigrate(databaseURL, migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+
+	version, dirty, err := migrationVersion(m)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := listMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MigrationStatus{
+		Version:    version,
+		Dirty:      dirty,
+		Migrations: migrationStates(files, version, dirty),
+	}, nil
+}
+
+// newMigrate connects to the database and returns a migrate instance for the
+// migrations in migrationsPath, or the embedded ones if it is empty, along with
+// the migrations. Closing the instance closes the connection.
+func newMigrate(databaseURL, migrationsPath string) (*migrate.Migrate, fs.FS, error) {
+	migrations, err := migrationsSource(migrationsPath, migrationfiles.FS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := openMigrate(databaseURL, migrations, "public")
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, migrations, nil
+}
+
+// openMigrate connects to the database and returns a migrate instance for
+// migrations, recording applied versions in the schema's _migration table.
+// Closing the instance closes the connection.
+func openMigrate(databaseURL string, migrations fs.FS, schema string) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	db, err := sql.Open(DriverName, databaseURL)
+	if err != nil {
+		source.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Ping the database to ensure the connection is valid
+	if err := db.Ping(); err != nil {
+		source.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// Create a new postgres driver instance
+	driver, err := postgres.WithInstance(db, &postgres.Config{
+		MigrationsTable: "_migration",
+		SchemaName:      schema,
+	})
+	if err != nil {
+		source.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to create postgres driver instance: %w", err)
+	}
+
+	// Create a new migrate instance
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		source.Close()
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+
+	return m, nil
+}
+
+// migrationsSource returns the migrations directory at migrationsPath, or the
+// embedded migrations if it is empty
+func migrationsSource(migrationsPath string, embedded fs.FS) (fs.FS, error) {
+	if migrationsPath == "" {
+		log.Println("Using embedded migrations")
+		return embedded, nil
+	}
+
+	// Get the absolute path to the migrations directory
+	absPath, err := filepath.Abs(migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for migrations: %w", err)
+	}
+
+	// Check if the migrations directory exists
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("migrations directory does not exist: %s", absPath)
+	}
+
+	log.Printf("Using migrations in %s", absPath)
+	return os.DirFS(absPath), nil
+}
+
+// migrationVersion returns the schema version, 0 if no migration has been applied
+func migrationVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if err != nil {
+		if errors.Is(err, migrate.ErrNilVersion) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// listMigrations returns the up migrations in a file system, ordered by version
+func listMigrations(fsys fs.FS) ([]MigrationState, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var migrations []MigrationState
+	for _, file := range files {
+		match := migrationFile.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", file.Name(), err)
+		}
+		migrations = append(migrations, MigrationState{Version: uint(version), Name: match[2]})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// migrationStates marks the migrations up to the schema version as applied,
+// and the one at the version as dirty if it failed partway
+func migrationStates(migrations []MigrationState, version uint, dirty bool) []MigrationState {
+	states := make([]MigrationState, len(migrations))
+	for i, migration := range migrations {
+		migration.Applied = migration.Version <= version
+		migration.Dirty = dirty && migration.Version == version
+		states[i] = migration
+	}
+	return states
+}
+
+// migrationVersionLayout formats the time a migration is created as its version
+const migrationVersionLayout = "20060102150405"
+
+// migrationHeader starts every new migration, so its objects are owned by the
+// admin role
+const migrationHeader = "SET ROLE silocore_admin;\n\n"
+
+// migrationNameSeparators matches the runs of characters replaced in migration names
+var migrationNameSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CreateMigration creates empty up and down migration files in migrationsPath,
+// named {version}_{name}.up.sql and {version}_{name}.down.sql with the creation
+// time as the version, and returns their paths. The name is lowercased with
+// other characters than letters and digits replaced by underscores.
+func CreateMigration(migrationsPath, name string, now time.Time) ([]string, error) {
+	name = migrationName(name)
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	if err := os.MkdirAll(migrationsPath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	base := fmt.Sprintf("%s_%s", now.UTC().Format(migrationVersionLayout), name)
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(migrationsPath, fmt.Sprintf("%s.%s.sql", base, direction))
+		// O_EXCL so an existing migration is never overwritten
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			for _, created := range paths {
+				os.Remove(created)
+			}
+			return nil, fmt.Errorf("failed to create migration file: %w", err)
+		}
+		_, err = file.WriteString(migrationHeader)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		paths = append(paths, path)
+		if err != nil {
+			for _, created := range paths {
+				os.Remove(created)
+			}
+			return nil, fmt.Errorf("failed to write migration file: %w", err)
+		}
+	}
+	return paths, nil
+}
+
+// migrationName normalizes a migration name to lowercase words joined by underscores
+func migrationName(name string) string {
+	name = migrationNameSeparators.ReplaceAllString(strings.ToLower(name), "_")
+	return strings.Trim(name, "_")
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	migrationfiles "github.com/unsavory/silocore-go/sql/migrations"
+)
+
+func TestListMigrations(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"10_add_order.up.sql", "2_create_tenant.up.sql", "1_init.up.sql", "2_create_tenant.down.sql", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "3_dir.up.sql"), 0o755))
+
+	migrations, err := listMigrations(os.DirFS(dir))
+	require.NoError(t, err)
+
+	// Only up migrations, ordered by version rather than name
+	assert.Equal(t, []MigrationState{
+		{Version: 1, Name: "init"},
+		{Version: 2, Name: "create_tenant"},
+		{Version: 10, Name: "add_order"},
+	}, migrations)
+}
+
+func TestMigrationStates(t *testing.T) {
+	migrations := []MigrationState{{Version: 1, Name: "init"}, {Version: 2, Name: "tenant"}, {Version: 3, Name: "order"}}
+
+	t.Run("None applied", func(t *testing.T) {
+		states := migrationStates(migrations, 0, false)
+		for _, state := range states {
+			assert.False(t, state.Applied)
+			assert.False(t, state.Dirty)
+		}
+	})
+
+	t.Run("Partly applied", func(t *testing.T) {
+		states := migrationStates(migrations, 2, false)
+		assert.True(t, states[0].Applied)
+		assert.True(t, states[1].Applied)
+		assert.False(t, states[2].Applied)
+	})
+
+	t.Run("Dirty", func(t *testing.T) {
+		states := migrationStates(migrations, 2, true)
+		assert.False(t, states[0].Dirty)
+		assert.True(t, states[1].Dirty)
+		assert.False(t, states[2].Dirty)
+
+		// The input is not modified
+		assert.False(t, migrations[1].Applied)
+	})
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	now := time.Date(2024, 3, 7, 9, 30, 15, 0, time.UTC)
+
+	paths, err := CreateMigration(dir, "Add Order Tags!", now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "20240307093015_add_order_tags.up.sql"),
+		filepath.Join(dir, "20240307093015_add_order_tags.down.sql"),
+	}, paths)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, migrationHeader, string(content))
+	}
+
+	// The new migration is listed with the existing ones
+	migrations, err := listMigrations(os.DirFS(dir))
+	require.NoError(t, err)
+	assert.Equal(t, []MigrationState{{Version: 20240307093015, Name: "add_order_tags"}}, migrations)
+
+	t.Run("Existing files are not overwritten", func(t *testing.T) {
+		_, err := CreateMigration(dir, "add_order_tags", now)
+		assert.Error(t, err)
+	})
+
+	t.Run("Name required", func(t *testing.T) {
+		_, err := CreateMigration(dir, " - ", now)
+		assert.Error(t, err)
+	})
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := migrationsSource("", migrationfiles.FS)
+	require.NoError(t, err)
+
+	files, err := listMigrations(migrations)
+	require.NoError(t, err)
+
+	// The embedded migrations start at version 1 and include only SQL files
+	require.NotEmpty(t, files)
+	assert.Equal(t, uint(1), files[0].Version)
+	entries, err := fs.ReadDir(migrations, ".")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.Equal(t, ".sql", filepath.Ext(entry.Name()))
+	}
+}
+
+func TestPlanMigrations(t *testing.T) {
+	migrations := []MigrationState{{Version: 1, Name: "init"}, {Version: 2, Name: "tenant"}, {Version: 3, Name: "order"}}
+	versions := func(plan []MigrationState) []uint {
+		var versions []uint
+		for _, migration := range plan {
+			versions = append(versions, migration.Version)
+		}
+		return versions
+	}
+
+	assert.Equal(t, []uint{2, 3}, versions(planMigrations(migrations, 1, true, 0)))
+	assert.Equal(t, []uint{1}, versions(planMigrations(migrations, 0, true, 1)))
+	assert.Empty(t, planMigrations(migrations, 3, true, 0))
+	assert.Equal(t, []uint{2, 1}, versions(planMigrations(migrations, 2, false, 0)))
+	assert.Equal(t, []uint{3}, versions(planMigrations(migrations, 3, false, 1)))
+	assert.Empty(t, planMigrations(migrations, 0, false, 0))
+}
+
+func TestWritePlan(t *testing.T) {
+	migrations := fstest.MapFS{
+		"1_init.up.sql":   {Data: []byte("CREATE TABLE a ();\n")},
+		"1_init.down.sql": {Data: []byte("DROP TABLE a;\n")},
+		"2_tenant.up.sql": {Data: []byte("CREATE TABLE b ();\n")},
+	}
+	plan := []MigrationState{{Version: 2, Name: "tenant"}, {Version: 1, Name: "init"}}
+
+	var out strings.Builder
+	require.NoError(t, writePlan(&out, migrations, plan, false))
+
+	// Migrations without a down file are noted rather than failing the plan
+	assert.Equal(t, "-- 2_tenant.down.sql: no down migration\n\n-- 1_init.down.sql\nDROP TABLE a;\n\n", out.String())
+}
+
+func TestPreviousVersion(t *testing.T) {
+	migrations := fstest.MapFS{
+		"1_init.up.sql":     {},
+		"5_tenant.up.sql":   {},
+		"12_order.up.sql":   {},
+		"12_order.down.sql": {},
+	}
+
+	assert.Equal(t, 5, previousVersion(migrations, 12))
+	assert.Equal(t, 1, previousVersion(migrations, 5))
+	assert.Equal(t, -1, previousVersion(migrations, 1))
+
+	err := dirtyError(12, 5)
+	assert.ErrorIs(t, err, ErrDirtyDatabase)
+	assert.Contains(t, err.Error(), "migrate force 5")
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// Default connection pool settings
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+	defaultConnectTimeout  = 10 * time.Second
+
+	// Environment variable names
+	envMaxOpenConns       = "DB_MAX_OPEN_CONNS"
+	envMaxIdleConns       = "DB_MAX_IDLE_CONNS"
+	envConnMaxLifetimeMin = "DB_CONN_MAX_LIFETIME_MINUTES"
+	envConnectTimeoutSec  = "DB_CONNECT_TIMEOUT_SECONDS"
+)
+
+// PoolConfig holds the settings of the application's connection pool. A zero
+// MaxOpenConns or ConnMaxLifetime means no limit.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// ConnectTimeout bounds how long startup waits for the database
+	ConnectTimeout time.Duration
+}
+
+// LoadPoolConfig loads connection pool settings from environment variables
+func LoadPoolConfig() (PoolConfig, error) {
+	config := PoolConfig{
+		MaxOpenConns:    defaultMaxOpenConns,
+		MaxIdleConns:    defaultMaxIdleConns,
+		ConnMaxLifetime: defaultConnMaxLifetime,
+		ConnectTimeout:  defaultConnectTimeout,
+	}
+
+	if value := os.Getenv(envMaxOpenConns); value != "" {
+		conns, err := strconv.Atoi(value)
+		if err != nil || conns < 0 {
+			return PoolConfig{}, fmt.Errorf("invalid DB_MAX_OPEN_CONNS value: %q", value)
+		}
+		config.MaxOpenConns = conns
+	}
+
+	if value := os.Getenv(envMaxIdleConns); value != "" {
+		conns, err := strconv.Atoi(value)
+		if err != nil || conns < 0 {
+			return PoolConfig{}, fmt.Errorf("invalid DB_MAX_IDLE_CONNS value: %q", value)
+		}
+		config.MaxIdleConns = conns
+	}
+
+	if value := os.Getenv(envConnMaxLifetimeMin); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 0 {
+			return PoolConfig{}, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME_MINUTES value: %q", value)
+		}
+		config.ConnMaxLifetime = time.Duration(minutes) * time.Minute
+	}
+
+	if value := os.Getenv(envConnectTimeoutSec); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return PoolConfig{}, fmt.Errorf("invalid DB_CONNECT_TIMEOUT_SECONDS value: %q", value)
+		}
+		config.ConnectTimeout = time.Duration(seconds) * time.Second
+	}
+
+	// Idle connections beyond the open limit would be closed right away
+	if config.MaxOpenConns > 0 && config.MaxIdleConns > config.MaxOpenConns {
+		return PoolConfig{}, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) cannot exceed DB_MAX_OPEN_CONNS (%d)", config.MaxIdleConns, config.MaxOpenConns)
+	}
+
+	return config, nil
+}
+
+// apply sets the pool's limits on db
+func (c PoolConfig) apply(db *sql.DB) {
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
+}
+
+// PoolStats reports the state of a connection pool
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+// Stats returns the state of db's connection pool. Waits mean the pool is too
+// small for the load.
+func Stats(db *sql.DB) PoolStats {
+	stats := db.Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPoolConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		config, err := LoadPoolConfig()
+
+		require.NoError(t, err)
+		assert.Equal(t, defaultMaxOpenConns, config.MaxOpenConns)
+		assert.Equal(t, defaultMaxIdleConns, config.MaxIdleConns)
+		assert.Equal(t, defaultConnMaxLifetime, config.ConnMaxLifetime)
+		assert.Equal(t, defaultConnectTimeout, config.ConnectTimeout)
+	})
+
+	t.Run("From environment", func(t *testing.T) {
+		t.Setenv(envMaxOpenConns, "50")
+		t.Setenv(envMaxIdleConns, "20")
+		t.Setenv(envConnMaxLifetimeMin, "0")
+		t.Setenv(envConnectTimeoutSec, "3")
+
+		config, err := LoadPoolConfig()
+
+		require.NoError(t, err)
+		assert.Equal(t, PoolConfig{MaxOpenConns: 50, MaxIdleConns: 20, ConnMaxLifetime: 0, ConnectTimeout: 3 * time.Second}, config)
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		t.Setenv(envMaxOpenConns, "many")
+
+		_, err := LoadPoolConfig()
+
+		assert.Error(t, err)
+	})
+
+	t.Run("More idle than open connections", func(t *testing.T) {
+		t.Setenv(envMaxOpenConns, "5")
+
+		_, err := LoadPoolConfig()
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// DriverName is the database/sql driver migrations are run with: pgx, through
+// its database/sql adapter. Open connects with the same driver. Services only
+// depend on database/sql, the error codes and the array types of this package,
+// so the driver can be swapped here.
+const DriverName = "pgx"
+
+// PostgreSQL error codes the services handle
+const (
+	CodeUniqueViolation      = "23505"
+	CodeForeignKeyViolation  = "23503"
+	CodeCheckViolation       = "23514"
+	CodeSerializationFailure = "40001"
+	CodeDeadlockDetected     = "40P01"
+	CodeQueryCanceled        = "57014"
+)
+
+// sqlStateError is implemented by the errors PostgreSQL drivers return for errors
+// reported by the server, such as pgx's *pgconn.PgError and lib/pq's *pq.Error
+type sqlStateError interface {
+	SQLState() string
+}
+
+// Open opens a connection pool to the database with the given settings and
+// checks that the database is reachable within the pool's connect timeout. Its
+// queries are traced, and their durations recorded in metrics unless it is nil.
+func Open(ctx context.Context, databaseURL string, pool PoolConfig, metrics *QueryMetrics) (*sql.DB, error) {
+	config, err := pgx.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	connector := stdlib.GetConnector(*config, stdlib.OptionAfterConnect(func(ctx context.Context, conn *pgx.Conn) error {
+		registerTextParams(conn.TypeMap())
+		return nil
+	}))
+	db := sql.OpenDB(&instrumentedConnector{Connector: connector, metrics: metrics})
+	pool.apply(db)
+
+	if pool.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, pool.ConnectTimeout)
+		defer cancel()
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to reach database: %w", err)
+	}
+
+	return db, nil
+}
+
+// ErrorCode returns the SQLSTATE code of an error reported by the database
+// server, or "" for other errors
+func ErrorCode(err error) string {
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation
+func IsUniqueViolation(err error) bool {
+	return ErrorCode(err) == CodeUniqueViolation
+}
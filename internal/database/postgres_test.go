@@ -0,0 +1,19 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	wrapped := fmt.Errorf("insert failed: %w", &pgconn.PgError{Code: CodeUniqueViolation})
+
+	assert.Equal(t, CodeUniqueViolation, ErrorCode(wrapped))
+	assert.True(t, IsUniqueViolation(wrapped))
+	assert.Equal(t, "", ErrorCode(errors.New("connection refused")))
+	assert.False(t, IsUniqueViolation(&pgconn.PgError{Code: CodeForeignKeyViolation}))
+}
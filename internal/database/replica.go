@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// envReplicaURLs lists the connection strings of read replicas, separated by commas
+const envReplicaURLs = "DATABASE_REPLICA_URLS"
+
+// primaryReadsKey marks contexts whose reads must see the primary's latest writes
+type primaryReadsKey struct{}
+
+// WithPrimaryReads returns a context whose reads go to the primary, for reading
+// data right after writing it
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether reads in the context must go to the primary
+func PrimaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+// QueryRouter sends reads to read replicas in turn and everything else to the
+// primary. Replicas lag behind the primary, so reads that must see a write just
+// made are marked with WithPrimaryReads.
+type QueryRouter struct {
+	primary  *sql.DB
+	replicas []*sql.DB
+	next     atomic.Uint64
+}
+
+// NewQueryRouter creates a new QueryRouter. Without replicas every query goes to
+// the primary.
+func NewQueryRouter(primary *sql.DB, replicas ...*sql.DB) *QueryRouter {
+	return &QueryRouter{
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+// Primary returns the primary database, for writes
+func (r *QueryRouter) Primary() *sql.DB {
+	return r.primary
+}
+
+// HasReplicas reports whether reads can go to replicas
+func (r *QueryRouter) HasReplicas() bool {
+	return len(r.replicas) > 0
+}
+
+// Reader returns the database for a read: the next replica, or the primary if
+// there are none or the context requires it
+func (r *QueryRouter) Reader(ctx context.Context) *sql.DB {
+	if len(r.replicas) == 0 || PrimaryReads(ctx) {
+		return r.primary
+	}
+	i := r.next.Add(1) - 1
+	return r.replicas[i%uint64(len(r.replicas))]
+}
+
+// Close closes the replicas. The primary is closed by its owner.
+func (r *QueryRouter) Close() error {
+	var errs []error
+	for _, replica := range r.replicas {
+		errs = append(errs, replica.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// OpenReplicas opens a connection pool with the given settings to each read
+// replica in DATABASE_REPLICA_URLS, recording query durations in metrics unless
+// it is nil. It returns no replicas if it is unset.
+func OpenReplicas(ctx context.Context, pool PoolConfig, metrics *QueryMetrics) ([]*sql.DB, error) {
+	var replicas []*sql.DB
+	for i, url := range strings.Split(os.Getenv(envReplicaURLs), ",") {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+
+		replica, err := Open(ctx, url, pool, metrics)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryRouter(t *testing.T) {
+	primary, first, second := &sql.DB{}, &sql.DB{}, &sql.DB{}
+
+	t.Run("Reads rotate over the replicas", func(t *testing.T) {
+		router := NewQueryRouter(primary, first, second)
+
+		assert.Same(t, first, router.Reader(context.Background()))
+		assert.Same(t, second, router.Reader(context.Background()))
+		assert.Same(t, first, router.Reader(context.Background()))
+		assert.Same(t, primary, router.Primary())
+	})
+
+	t.Run("Primary reads", func(t *testing.T) {
+		router := NewQueryRouter(primary, first, second)
+
+		assert.Same(t, primary, router.Reader(WithPrimaryReads(context.Background())))
+	})
+
+	t.Run("No replicas", func(t *testing.T) {
+		router := NewQueryRouter(primary)
+
+		assert.False(t, router.HasReplicas())
+		assert.Same(t, primary, router.Reader(context.Background()))
+	})
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// codeClassConnectionException is the SQLSTATE class of errors reported when
+// the connection to the server fails
+const codeClassConnectionException = "08"
+
+// RetryPolicy controls how often and how fast failed operations are retried
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first
+	MaxAttempts int
+
+	// BaseDelay is the maximum delay before the first retry. It doubles with
+	// every retry, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy retries twice within about half a second
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// IsConflict reports whether err is a serialization failure or a deadlock, which
+// transactions writing the same rows concurrently fail with. Unlike a lost
+// connection, it leaves the connection usable, so the work can be retried from a
+// savepoint.
+func IsConflict(err error) bool {
+	code := ErrorCode(err)
+	return code == CodeSerializationFailure || code == CodeDeadlockDetected
+}
+
+// IsTransient reports whether err is a failure that may not recur if the
+// operation is retried: a serialization failure, a deadlock or a lost connection
+func IsTransient(err error) bool {
+	if IsConflict(err) || strings.HasPrefix(ErrorCode(err), codeClassConnectionException) {
+		return true
+	}
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Retry runs fn until it succeeds, fails with an error that is not transient or
+// has been attempted policy.MaxAttempts times, and returns its last error. Retries
+// wait a random delay of up to BaseDelay, doubling with every retry, so clients
+// that failed together don't retry together. fn must be safe to run again, e.g.
+// by running in a transaction that is rolled back when it fails.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	return RetryWhen(ctx, policy, IsTransient, fn)
+}
+
+// RetryWhen runs fn like Retry, retrying the errors retryable reports true for
+func RetryWhen(ctx context.Context, policy RetryPolicy, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	delay := policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= policy.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		log.Printf("[WARN] Retrying after transient database error (attempt %d of %d): %v", attempt, policy.MaxAttempts, err)
+		if err := sleep(ctx, jitter(delay)); err != nil {
+			return err
+		}
+		delay = min(delay*2, policy.MaxDelay)
+	}
+}
+
+// jitter returns a random delay between 0 and d
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
+
+// sleep waits for d, returning early with the context's error if it's done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRetryPolicy retries without waiting
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, IsTransient(fmt.Errorf("insert failed: %w", &pgconn.PgError{Code: CodeSerializationFailure})))
+	assert.True(t, IsTransient(&pgconn.PgError{Code: CodeDeadlockDetected}))
+	assert.True(t, IsTransient(&pgconn.PgError{Code: "08006"}))
+	assert.True(t, IsTransient(driver.ErrBadConn))
+	assert.False(t, IsTransient(&pgconn.PgError{Code: CodeUniqueViolation}))
+	assert.False(t, IsTransient(errors.New("invalid input")))
+}
+
+func TestIsConflict(t *testing.T) {
+	assert.True(t, IsConflict(fmt.Errorf("update failed: %w", &pgconn.PgError{Code: CodeSerializationFailure})))
+	assert.True(t, IsConflict(&pgconn.PgError{Code: CodeDeadlockDetected}))
+	assert.False(t, IsConflict(&pgconn.PgError{Code: "08006"}))
+	assert.False(t, IsConflict(driver.ErrBadConn))
+}
+
+func TestRetry(t *testing.T) {
+	deadlock := &pgconn.PgError{Code: CodeDeadlockDetected}
+
+	t.Run("Retries transient errors", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return deadlock
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("Gives up after the last attempt", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+			attempts++
+			return deadlock
+		})
+
+		assert.ErrorIs(t, err, deadlock)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("Does not retry other errors", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+			attempts++
+			return &pgconn.PgError{Code: CodeUniqueViolation}
+		})
+
+		assert.True(t, IsUniqueViolation(err))
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Stops waiting when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+		err := Retry(ctx, policy, func(ctx context.Context) error {
+			return deadlock
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5"
+	tenantmigrations "github.com/unsavory/silocore-go/sql/tenant_migrations"
+)
+
+// TenancyMode is how tenants' data is isolated from each other
+type TenancyMode string
+
+const (
+	// TenancyShared keeps every tenant's rows in shared tables, isolated by row
+	// level security
+	TenancyShared TenancyMode = "shared"
+
+	// TenancySchema additionally gives each tenant its own schema holding its
+	// orders, which its transactions resolve tables in before the shared ones
+	TenancySchema TenancyMode = "schema"
+
+	// Environment variable names
+	envTenancyMode          = "TENANCY_MODE"
+	envTenantMigrationsPath = "TENANT_MIGRATIONS_PATH"
+)
+
+// TenancyConfig holds how tenants are isolated
+type TenancyConfig struct {
+	Mode TenancyMode
+	// TenantMigrationsPath is the directory of the migrations run in each tenant's
+	// schema. If empty, the migrations embedded in the binary are used.
+	TenantMigrationsPath string
+}
+
+// LoadTenancyConfig loads the tenancy mode from environment variables
+func LoadTenancyConfig() (TenancyConfig, error) {
+	config := TenancyConfig{
+		Mode:                 TenancyShared,
+		TenantMigrationsPath: os.Getenv(envTenantMigrationsPath),
+	}
+
+	if value := os.Getenv(envTenancyMode); value != "" {
+		mode := TenancyMode(value)
+		if mode != TenancyShared && mode != TenancySchema {
+			return TenancyConfig{}, fmt.Errorf("invalid TENANCY_MODE value: %q", value)
+		}
+		config.Mode = mode
+	}
+
+	return config, nil
+}
+
+// TenantSchema returns the name of a tenant's schema in schema-per-tenant mode
+func TenantSchema(tenantID int64) string {
+	return fmt.Sprintf("tenant_%d", tenantID)
+}
+
+// TenantSearchPath returns the search path of a tenant's transactions in
+// schema-per-tenant mode: the tenant's schema, then the shared tables
+func TenantSearchPath(tenantID int64) string {
+	return TenantSchema(tenantID) + ", public"
+}
+
+// SchemaProvisioner gives tenants their own schemas in schema-per-tenant mode,
+// creating them and running the tenant migrations in them
+type SchemaProvisioner struct {
+	databaseURL    string
+	migrationsPath string
+}
+
+// NewSchemaProvisioner creates a new SchemaProvisioner, connecting with the admin
+// connection string as for migrations. The tenant migrations in migrationsPath
+// are run, or the embedded ones if it is empty.
+func NewSchemaProvisioner(databaseURL, migrationsPath string) *SchemaProvisioner {
+	return &SchemaProvisioner{
+		databaseURL:    databaseURL,
+		migrationsPath: migrationsPath,
+	}
+}
+
+// ProvisionTenant creates the tenant's schema if it doesn't exist and applies
+// pending tenant migrations to it
+func (p *SchemaProvisioner) ProvisionTenant(ctx context.Context, tenantID int64) error {
+	return RunTenantMigrations(ctx, p.databaseURL, p.migrationsPath, tenantID)
+}
+
+// ProvisionAll provisions the schema of every tenant, including soft deleted
+// ones whose data is still kept, for startup after the shared migrations
+func (p *SchemaProvisioner) ProvisionAll(ctx context.Context) error {
+	db, err := sql.Open(DriverName, p.databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM tenant ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+	var tenantIDs []int64
+	for rows.Next() {
+		var tenantID int64
+		if err := rows.Scan(&tenantID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to list tenants: %w", err)
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	for _, tenantID := range tenantIDs {
+		if err := p.ProvisionTenant(ctx, tenantID); err != nil {
+			return fmt.Errorf("tenant %d: %w", tenantID, err)
+		}
+	}
+	log.Printf("[INFO] Provisioned schemas of %d tenants", len(tenantIDs))
+	return nil
+}
+
+// RunTenantMigrations creates a tenant's schema if it doesn't exist and applies
+// the pending tenant migrations in migrationsPath, or the embedded ones if it is
+// empty, to it. Each schema records its version in its own _migration table.
+func RunTenantMigrations(ctx context.Context, databaseURL, migrationsPath string, tenantID int64) error {
+	schema := TenantSchema(tenantID)
+	if err := createTenantSchema(ctx, databaseURL, schema); err != nil {
+		return err
+	}
+
+	migrations, err := migrationsSource(migrationsPath, tenantmigrations.FS)
+	if err != nil {
+		return err
+	}
+
+	// Tables created by the migrations go in the tenant's schema
+	schemaURL, err := withSearchPath(databaseURL, schema+",public")
+	if err != nil {
+		return err
+	}
+
+	m, err := openMigrate(schemaURL, migrations, schema)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		if version, dirty, verr := migrationVersion(m); verr == nil && dirty {
+			log.Printf("[WARN] Schema %s: %v", schema, dirtyError(version, previousVersion(migrations, version)))
+		}
+		return fmt.Errorf("failed to migrate schema %s: %w", schema, err)
+	}
+	return nil
+}
+
+// createTenantSchema creates a tenant's schema owned by the admin role, letting
+// the application role use the tables later created in it
+func createTenantSchema(ctx context.Context, databaseURL, schema string) error {
+	db, err := sql.Open(DriverName, databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	quoted := pgx.Identifier{schema}.Sanitize()
+	for _, statement := range []string{
+		"SET LOCAL ROLE silocore_admin",
+		"CREATE SCHEMA IF NOT EXISTS " + quoted,
+		"GRANT USAGE ON SCHEMA " + quoted + " TO silocore_app",
+		"ALTER DEFAULT PRIVILEGES FOR ROLE silocore_admin IN SCHEMA " + quoted +
+			" GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO silocore_app",
+		"ALTER DEFAULT PRIVILEGES FOR ROLE silocore_admin IN SCHEMA " + quoted +
+			" GRANT USAGE, SELECT, UPDATE ON SEQUENCES TO silocore_app",
+	} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+	return nil
+}
+
+// withSearchPath sets the search path of the connections of a connection string,
+// in URL or key/value form
+func withSearchPath(databaseURL, searchPath string) (string, error) {
+	if !strings.Contains(databaseURL, "://") {
+		return databaseURL + " search_path=" + searchPath, nil
+	}
+
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid database URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("search_path", searchPath)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
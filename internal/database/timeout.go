@@ -0,0 +1,54 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// Default statement timeouts. Both are below the default 60 second request
+	// timeout, so a slow query is cancelled before the request is abandoned.
+	defaultStatementTimeout       = 30 * time.Second
+	defaultReportStatementTimeout = 55 * time.Second
+
+	// Environment variable names
+	envStatementTimeoutMS       = "DB_STATEMENT_TIMEOUT_MS"
+	envReportStatementTimeoutMS = "DB_REPORT_STATEMENT_TIMEOUT_MS"
+)
+
+// StatementTimeoutConfig bounds how long the statements of the application's
+// transactions run. Zero means no limit beyond the database's.
+type StatementTimeoutConfig struct {
+	Default time.Duration
+	// Reports bounds the statements of platform reports, which aggregate
+	// across tenants
+	Reports time.Duration
+}
+
+// LoadStatementTimeoutConfig loads statement timeouts from environment variables
+func LoadStatementTimeoutConfig() (StatementTimeoutConfig, error) {
+	config := StatementTimeoutConfig{
+		Default: defaultStatementTimeout,
+		Reports: defaultReportStatementTimeout,
+	}
+
+	if value := os.Getenv(envStatementTimeoutMS); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return StatementTimeoutConfig{}, fmt.Errorf("invalid DB_STATEMENT_TIMEOUT_MS value: %q", value)
+		}
+		config.Default = time.Duration(ms) * time.Millisecond
+	}
+
+	if value := os.Getenv(envReportStatementTimeoutMS); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return StatementTimeoutConfig{}, fmt.Errorf("invalid DB_REPORT_STATEMENT_TIMEOUT_MS value: %q", value)
+		}
+		config.Reports = time.Duration(ms) * time.Millisecond
+	}
+
+	return config, nil
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStatementTimeoutConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		t.Setenv(envStatementTimeoutMS, "")
+		t.Setenv(envReportStatementTimeoutMS, "")
+
+		config, err := LoadStatementTimeoutConfig()
+		require.NoError(t, err)
+		assert.Equal(t, StatementTimeoutConfig{Default: 30 * time.Second, Reports: 55 * time.Second}, config)
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		t.Setenv(envStatementTimeoutMS, "5000")
+		t.Setenv(envReportStatementTimeoutMS, "0")
+
+		config, err := LoadStatementTimeoutConfig()
+		require.NoError(t, err)
+		assert.Equal(t, StatementTimeoutConfig{Default: 5 * time.Second}, config)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv(envStatementTimeoutMS, "-1")
+
+		_, err := LoadStatementTimeoutConfig()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the query spans
+const instrumentationName = "github.com/unsavory/silocore-go/internal/database"
+
+// startQuerySpan starts a client span for a query under the span in the
+// context, named after its operation and holding its redacted text
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := strings.ToUpper(queryOperation(query))
+	return otel.Tracer(instrumentationName).Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(RedactQuery(query)),
+		),
+	)
+}
+
+// endQuerySpan ends a query's span, recording its error. The span of a query
+// the driver skipped ends without one; database/sql runs the query through a
+// prepared statement instead.
+func endQuerySpan(span trace.Span, err error) {
+	if err != nil && err != driver.ErrSkip {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
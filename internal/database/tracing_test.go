@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQuerySpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	mockDB, mock, err := sqlmock.NewWithDSN("traced")
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	// Trace queries without recording their durations
+	db := sql.OpenDB(&instrumentedConnector{
+		Connector: mockConnector{dsn: "traced", driver: mockDB.Driver()},
+	})
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM usr").
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("DELETE FROM tenant").
+		WillReturnError(errors.New("permission denied"))
+
+	// Run the queries under a parent span
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	var id int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT id FROM usr WHERE email = $1 AND note = 'private'", "jane@example.com").Scan(&id))
+	_, err = db.ExecContext(ctx, "DELETE FROM tenant")
+	assert.Error(t, err)
+	parent.End()
+
+	// Verify the spans, with their redacted queries
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	selectSpan, deleteSpan := spans[0], spans[1]
+
+	assert.Equal(t, "SELECT", selectSpan.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), selectSpan.Parent().SpanID())
+	assert.Contains(t, selectSpan.Attributes(), attribute.String("db.query.text", "SELECT id FROM usr WHERE email = $1 AND note = '?'"))
+	assert.Equal(t, codes.Unset, selectSpan.Status().Code)
+
+	assert.Equal(t, "DELETE", deleteSpan.Name())
+	assert.Equal(t, codes.Error, deleteSpan.Status().Code)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
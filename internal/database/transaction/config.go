@@ -0,0 +1,71 @@
+package transaction
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variable names
+const (
+	envLazyTransactions = "DB_TX_LAZY"
+	envTxSkipRoutes     = "DB_TX_SKIP_ROUTES"
+	envRollbackStatus   = "DB_TX_ROLLBACK_STATUS"
+)
+
+// DefaultSkipRoutes are the routes served without a transaction unless
+// DB_TX_SKIP_ROUTES replaces them: static assets. Routes registered in the
+// router declare what they need with Required and None instead.
+var DefaultSkipRoutes = []string{
+	"/static/",
+}
+
+// LoadMiddlewareConfig loads the transaction middleware configuration from
+// environment variables. Transactions begin lazily, as routes declare with
+// Required and None, unless DB_TX_LAZY is false. CORS preflight requests never
+// get a transaction.
+func LoadMiddlewareConfig() (MiddlewareConfig, error) {
+	config := MiddlewareConfig{
+		Lazy:        true,
+		SkipRoutes:  DefaultSkipRoutes,
+		SkipMethods: []string{http.MethodOptions},
+	}
+
+	if value := os.Getenv(envLazyTransactions); value != "" {
+		lazy, err := strconv.ParseBool(value)
+		if err != nil {
+			return MiddlewareConfig{}, fmt.Errorf("invalid DB_TX_LAZY value: %q", value)
+		}
+		config.Lazy = lazy
+	}
+
+	if value := os.Getenv(envRollbackStatus); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil || status < http.StatusBadRequest || status > http.StatusInternalServerError {
+			return MiddlewareConfig{}, fmt.Errorf("invalid DB_TX_ROLLBACK_STATUS value: %q", value)
+		}
+		config.RollbackStatus = status
+	}
+
+	if value, ok := os.LookupEnv(envTxSkipRoutes); ok {
+		config.SkipRoutes = nil
+		for _, route := range strings.Split(value, ",") {
+			route = strings.Join(strings.Fields(route), " ")
+			if route == "" {
+				continue
+			}
+			path := route
+			if _, p, found := strings.Cut(route, " "); found {
+				path = p
+			}
+			if !strings.HasPrefix(path, "/") {
+				return MiddlewareConfig{}, fmt.Errorf("invalid DB_TX_SKIP_ROUTES route: %q", route)
+			}
+			config.SkipRoutes = append(config.SkipRoutes, route)
+		}
+	}
+
+	return config, nil
+}
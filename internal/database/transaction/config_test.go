@@ -0,0 +1,64 @@
+package transaction
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMiddlewareConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		t.Setenv(envLazyTransactions, "")
+		t.Setenv(envRollbackStatus, "")
+
+		config, err := LoadMiddlewareConfig()
+		require.NoError(t, err)
+		assert.True(t, config.Lazy)
+		assert.Zero(t, config.RollbackStatus)
+		assert.Equal(t, DefaultSkipRoutes, config.SkipRoutes)
+		assert.Equal(t, []string{http.MethodOptions}, config.SkipMethods)
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		t.Setenv(envLazyTransactions, "false")
+		t.Setenv(envTxSkipRoutes, "GET  /login, /assets/,")
+		t.Setenv(envRollbackStatus, "400")
+
+		config, err := LoadMiddlewareConfig()
+		require.NoError(t, err)
+		assert.False(t, config.Lazy)
+		assert.Equal(t, http.StatusBadRequest, config.RollbackStatus)
+		assert.Equal(t, []string{"GET /login", "/assets/"}, config.SkipRoutes)
+	})
+
+	t.Run("No skipped routes", func(t *testing.T) {
+		t.Setenv(envTxSkipRoutes, "")
+
+		config, err := LoadMiddlewareConfig()
+		require.NoError(t, err)
+		assert.Empty(t, config.SkipRoutes)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv(envLazyTransactions, "sometimes")
+
+		_, err := LoadMiddlewareConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid rollback status", func(t *testing.T) {
+		t.Setenv(envRollbackStatus, "200")
+
+		_, err := LoadMiddlewareConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid route", func(t *testing.T) {
+		t.Setenv(envTxSkipRoutes, "GET login")
+
+		_, err := LoadMiddlewareConfig()
+		assert.Error(t, err)
+	})
+}
@@ -1,7 +1,57 @@
 package transaction
 
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
 // ContextKey is the type for context keys
 type ContextKey string
 
 // TxKey is the context key for transactions
 const TxKey ContextKey = "transaction"
+
+// rollbackOnlyKey is the context key for marking a transaction to roll back
+const rollbackOnlyKey ContextKey = "rollback_only"
+
+// statementTimeoutKey is the context key for statement timeouts
+const statementTimeoutKey ContextKey = "statement_timeout"
+
+// WithStatementTimeout returns a context whose transactions bound their statements
+// by timeout instead of the manager's statement timeout
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey, timeout)
+}
+
+// StatementTimeoutFrom returns the statement timeout set in the context, if any
+func StatementTimeoutFrom(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(statementTimeoutKey).(time.Duration)
+	return timeout, ok
+}
+
+// withRollbackFlag adds an unset rollback-only flag to the context of a new
+// transaction
+func withRollbackFlag(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rollbackOnlyKey, new(atomic.Bool))
+}
+
+// SetRollbackOnly marks the transaction in the context to roll back instead of
+// committing when its request or WithTransaction function ends successfully,
+// e.g. for a handler that rejects a request after writing. ErrNoTransaction is
+// returned if the context has no transaction managed by a Manager.
+func SetRollbackOnly(ctx context.Context) error {
+	flag, ok := ctx.Value(rollbackOnlyKey).(*atomic.Bool)
+	if !ok {
+		return ErrNoTransaction
+	}
+	flag.Store(true)
+	return nil
+}
+
+// IsRollbackOnly reports whether the transaction in the context is marked to
+// roll back
+func IsRollbackOnly(ctx context.Context) bool {
+	flag, ok := ctx.Value(rollbackOnlyKey).(*atomic.Bool)
+	return ok && flag.Load()
+}
@@ -0,0 +1,138 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// FakeManager is a TxManager for unit tests of services. It never begins,
+// commits or rolls back a transaction: every context gets Tx, typically begun by
+// the test on a sqlmock database to expect the service's queries. It records the
+// tenant contexts set and the outcome of each function it runs, and runs commit
+// hooks for the functions that succeed.
+type FakeManager struct {
+	// Tx is the transaction of contexts without one. Nil means they have none.
+	Tx *sql.Tx
+
+	// DB is the database returned by GetDB
+	DB *sql.DB
+
+	mu         sync.Mutex
+	tenantIDs  []int64
+	committed  int
+	rolledBack int
+}
+
+// Ensure FakeManager implements TxManager
+var _ TxManager = (*FakeManager)(nil)
+
+// NewFakeManager creates a new FakeManager handing out tx
+func NewFakeManager(tx *sql.Tx) *FakeManager {
+	return &FakeManager{Tx: tx}
+}
+
+// Begin adds Tx to the context, unless it has a transaction already
+func (f *FakeManager) Begin(ctx context.Context) (context.Context, *sql.Tx, error) {
+	if tx, ok := ctx.Value(TxKey).(*sql.Tx); ok {
+		return ctx, tx, nil
+	}
+	if f.Tx == nil {
+		return ctx, nil, ErrNoTransaction
+	}
+	return withRollbackFlag(withCommitHooks(context.WithValue(ctx, TxKey, f.Tx))), f.Tx, nil
+}
+
+// GetTx returns the transaction in the context, or Tx
+func (f *FakeManager) GetTx(ctx context.Context) (*sql.Tx, error) {
+	if tx, ok := ctx.Value(TxKey).(*sql.Tx); ok {
+		return tx, nil
+	}
+	if f.Tx == nil {
+		return nil, ErrNoTransaction
+	}
+	return f.Tx, nil
+}
+
+// GetDB returns DB
+func (f *FakeManager) GetDB() *sql.DB {
+	return f.DB
+}
+
+// WithTransaction runs fn with Tx in the context, counting it committed if it
+// succeeds and rolled back if it fails or marks the transaction rollback-only
+func (f *FakeManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, _, err := f.Begin(withoutTransaction(ctx))
+	if err != nil {
+		return err
+	}
+
+	if err := fn(ctx); err != nil || IsRollbackOnly(ctx) {
+		f.mu.Lock()
+		f.rolledBack++
+		f.mu.Unlock()
+		return err
+	}
+
+	f.mu.Lock()
+	f.committed++
+	f.mu.Unlock()
+	runCommitHooks(ctx)
+	return nil
+}
+
+// WithRetry runs fn once, like WithTransaction
+func (f *FakeManager) WithRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	return f.WithTransaction(ctx, fn)
+}
+
+// WithTransactionRetry runs fn once, like WithTransaction
+func (f *FakeManager) WithTransactionRetry(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	return f.WithTransaction(ctx, fn)
+}
+
+// WithReadOnly runs fn like WithTransaction
+func (f *FakeManager) WithReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	return f.WithTransaction(ctx, fn)
+}
+
+// WithCrossTenantRead runs fn with Tx, requiring the ADMIN role like Manager
+func (f *FakeManager) WithCrossTenantRead(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if !authctx.IsAdmin(ctx) {
+		return ErrAdminRequired
+	}
+	return f.WithTransaction(ctx, func(ctx context.Context) error {
+		return fn(ctx, f.Tx)
+	})
+}
+
+// SetTenantContext records the tenant
+func (f *FakeManager) SetTenantContext(ctx context.Context, tenantID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tenantIDs = append(f.tenantIDs, tenantID)
+	return nil
+}
+
+// TenantIDs returns the tenants whose context was set, in order
+func (f *FakeManager) TenantIDs() []int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int64(nil), f.tenantIDs...)
+}
+
+// Committed returns how many functions succeeded
+func (f *FakeManager) Committed() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.committed
+}
+
+// RolledBack returns how many functions failed or rolled back
+func (f *FakeManager) RolledBack() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rolledBack
+}
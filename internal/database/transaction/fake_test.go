@@ -0,0 +1,88 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+func TestFakeManager(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	t.Run("Functions run in the fake's transaction", func(t *testing.T) {
+		fake := NewFakeManager(tx)
+
+		var ran bool
+		err := fake.WithTransaction(context.Background(), func(ctx context.Context) error {
+			got, err := fake.GetTx(ctx)
+			require.NoError(t, err)
+			assert.Same(t, tx, got)
+			require.NoError(t, AfterCommit(ctx, func() { ran = true }))
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.True(t, ran, "commit hooks run when the function succeeds")
+		assert.Equal(t, 1, fake.Committed())
+		assert.Equal(t, 0, fake.RolledBack())
+	})
+
+	t.Run("Failures and rollback-only roll back", func(t *testing.T) {
+		fake := NewFakeManager(tx)
+
+		var ran bool
+		err := fake.WithRetry(context.Background(), func(ctx context.Context) error {
+			require.NoError(t, AfterCommit(ctx, func() { ran = true }))
+			return errors.New("boom")
+		})
+		assert.EqualError(t, err, "boom")
+
+		err = fake.WithTransaction(context.Background(), func(ctx context.Context) error {
+			return SetRollbackOnly(ctx)
+		})
+		require.NoError(t, err)
+
+		assert.False(t, ran, "commit hooks are discarded on rollback")
+		assert.Equal(t, 0, fake.Committed())
+		assert.Equal(t, 2, fake.RolledBack())
+	})
+
+	t.Run("Tenant contexts are recorded", func(t *testing.T) {
+		fake := NewFakeManager(tx)
+
+		require.NoError(t, fake.SetTenantContext(context.Background(), 42))
+		require.NoError(t, fake.SetTenantContext(context.Background(), 7))
+
+		assert.Equal(t, []int64{42, 7}, fake.TenantIDs())
+	})
+
+	t.Run("Cross-tenant reads require an admin", func(t *testing.T) {
+		fake := NewFakeManager(tx)
+		read := func(ctx context.Context, got *sql.Tx) error {
+			assert.Same(t, tx, got)
+			return nil
+		}
+
+		assert.ErrorIs(t, fake.WithCrossTenantRead(context.Background(), read), ErrAdminRequired)
+		assert.NoError(t, fake.WithCrossTenantRead(authctx.WithRoles(context.Background(), []authctx.Role{authctx.RoleAdmin}), read))
+	})
+
+	t.Run("No transaction", func(t *testing.T) {
+		fake := NewFakeManager(nil)
+
+		_, err := fake.GetTx(context.Background())
+		assert.ErrorIs(t, err, ErrNoTransaction)
+	})
+}
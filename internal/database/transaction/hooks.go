@@ -0,0 +1,116 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// HooksKey is the context key for the commit hooks of a transaction
+const HooksKey ContextKey = "transaction_hooks"
+
+// commitHooks are the functions to run once a transaction commits. Savepoints
+// remember how many hooks were registered when they were created, so rolling
+// back to a savepoint discards the hooks registered since.
+type commitHooks struct {
+	mu         sync.Mutex
+	fns        []func()
+	savepoints map[string][]int
+}
+
+// withCommitHooks adds an empty set of commit hooks to the context
+func withCommitHooks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, HooksKey, &commitHooks{savepoints: map[string][]int{}})
+}
+
+// hooksFromContext returns the commit hooks of the transaction in the context,
+// or nil if the transaction isn't managed by a Manager
+func hooksFromContext(ctx context.Context) *commitHooks {
+	hooks, _ := ctx.Value(HooksKey).(*commitHooks)
+	return hooks
+}
+
+// AfterCommit registers fn to run after the transaction in the context commits.
+// It is discarded if the transaction rolls back. Hooks run in the order they were
+// registered, on the goroutine that commits, so long-running work should start
+// its own goroutine. ErrNoTransaction is returned if the context has no
+// transaction managed by a Manager.
+func AfterCommit(ctx context.Context, fn func()) error {
+	hooks := hooksFromContext(ctx)
+	if hooks == nil {
+		return ErrNoTransaction
+	}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+	return nil
+}
+
+// runCommitHooks runs the commit hooks in the context once, after its
+// transaction committed
+func runCommitHooks(ctx context.Context) {
+	hooks := hooksFromContext(ctx)
+	if hooks == nil {
+		return
+	}
+
+	hooks.mu.Lock()
+	fns := hooks.fns
+	hooks.fns = nil
+	hooks.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Savepoint creates a savepoint in the transaction of the context
+func Savepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint %s: %w", name, err)
+	}
+
+	if hooks := hooksFromContext(ctx); hooks != nil {
+		hooks.mu.Lock()
+		hooks.savepoints[name] = append(hooks.savepoints[name], len(hooks.fns))
+		hooks.mu.Unlock()
+	}
+	return nil
+}
+
+// RollbackToSavepoint rolls the transaction of the context back to a savepoint,
+// discarding the commit hooks registered since it was created. The savepoint is
+// kept, as in Postgres.
+func RollbackToSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to roll back to savepoint %s: %w", name, err)
+	}
+
+	if hooks := hooksFromContext(ctx); hooks != nil {
+		hooks.mu.Lock()
+		if marks := hooks.savepoints[name]; len(marks) > 0 {
+			hooks.fns = hooks.fns[:marks[len(marks)-1]]
+		}
+		hooks.mu.Unlock()
+	}
+	return nil
+}
+
+// ReleaseSavepoint releases a savepoint in the transaction of the context,
+// keeping the changes and commit hooks made since it was created
+func ReleaseSavepoint(ctx context.Context, tx *sql.Tx, name string) error {
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint %s: %w", name, err)
+	}
+
+	if hooks := hooksFromContext(ctx); hooks != nil {
+		hooks.mu.Lock()
+		if marks := hooks.savepoints[name]; len(marks) > 0 {
+			hooks.savepoints[name] = marks[:len(marks)-1]
+		}
+		hooks.mu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAfterCommit(t *testing.T) {
+	t.Run("Hooks run after commit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		var ran []string
+		err = manager.WithTransaction(context.Background(), func(ctx context.Context) error {
+			require.NoError(t, AfterCommit(ctx, func() { ran = append(ran, "first") }))
+			require.NoError(t, AfterCommit(ctx, func() { ran = append(ran, "second") }))
+			assert.Empty(t, ran)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, ran)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Hooks are discarded on rollback", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		ran := false
+		err = manager.WithTransaction(context.Background(), func(ctx context.Context) error {
+			require.NoError(t, AfterCommit(ctx, func() { ran = true }))
+			return assert.AnError
+		})
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.False(t, ran)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolling back to a savepoint discards its hooks", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT kept").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT kept").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT undone").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT undone").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT undone").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		var ran []string
+		err = manager.WithTransaction(context.Background(), func(ctx context.Context) error {
+			tx, err := manager.GetTx(ctx)
+			require.NoError(t, err)
+
+			require.NoError(t, Savepoint(ctx, tx, "kept"))
+			require.NoError(t, AfterCommit(ctx, func() { ran = append(ran, "kept") }))
+			require.NoError(t, ReleaseSavepoint(ctx, tx, "kept"))
+
+			require.NoError(t, Savepoint(ctx, tx, "undone"))
+			require.NoError(t, AfterCommit(ctx, func() { ran = append(ran, "undone") }))
+			require.NoError(t, RollbackToSavepoint(ctx, tx, "undone"))
+			require.NoError(t, ReleaseSavepoint(ctx, tx, "undone"))
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kept"}, ran)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No managed transaction", func(t *testing.T) {
+		err := AfterCommit(context.Background(), func() {})
+
+		assert.ErrorIs(t, err, ErrNoTransaction)
+	})
+}
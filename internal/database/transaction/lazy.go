@@ -0,0 +1,105 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+)
+
+// lazyTx is a request transaction that begins when it is first asked for.
+// Settings applied before then, such as the tenant context, are applied when it
+// begins.
+type lazyTx struct {
+	ctx   context.Context
+	begin func(ctx context.Context) (*sql.Tx, error)
+
+	mu    sync.Mutex
+	tx    *sql.Tx
+	err   error
+	setup []func(ctx context.Context, tx *sql.Tx) error
+}
+
+// get returns the transaction, beginning it on the first call. A transaction
+// that failed to begin returns the same error on every call.
+func (l *lazyTx) get() (*sql.Tx, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tx != nil || l.err != nil {
+		return l.tx, l.err
+	}
+
+	tx, err := l.begin(l.ctx)
+	if err != nil {
+		l.err = err
+		return nil, err
+	}
+	for _, fn := range l.setup {
+		if err := fn(l.ctx, tx); err != nil {
+			tx.Rollback()
+			l.err = err
+			return nil, err
+		}
+	}
+	l.setup = nil
+	l.tx = tx
+	return tx, nil
+}
+
+// started returns the transaction if it has begun, or nil
+func (l *lazyTx) started() *sql.Tx {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tx
+}
+
+// onBegin runs fn on the transaction now if it has begun, or when it begins
+func (l *lazyTx) onBegin(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case l.tx != nil:
+		return fn(ctx, l.tx)
+	case l.err != nil:
+		return l.err
+	default:
+		l.setup = append(l.setup, fn)
+		return nil
+	}
+}
+
+// txFromContext returns the transaction in the context, beginning it if it is
+// begun lazily. ok is false if the context has no transaction.
+func txFromContext(ctx context.Context) (tx *sql.Tx, ok bool, err error) {
+	switch v := ctx.Value(TxKey).(type) {
+	case *sql.Tx:
+		return v, true, nil
+	case *lazyTx:
+		tx, err := v.get()
+		return tx, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// onTx runs fn on the transaction in the context. For a transaction begun lazily
+// that hasn't begun yet, fn runs when it begins, so settings such as the tenant
+// context don't begin it. ErrNoTransaction is returned if there is none.
+func onTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	switch v := ctx.Value(TxKey).(type) {
+	case *sql.Tx:
+		return fn(ctx, v)
+	case *lazyTx:
+		return v.onBegin(ctx, fn)
+	default:
+		return ErrNoTransaction
+	}
+}
+
+// logLazyFailure logs a request transaction that failed to begin where the
+// error can't be returned
+func logLazyFailure(err error) {
+	log.Printf("[WARN] Request transaction failed to begin, querying outside it: %v", err)
+}
@@ -6,21 +6,106 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database"
 )
 
 // Common errors
 var (
 	ErrNoTransaction = errors.New("no transaction in context")
+
+	// ErrAdminRequired is returned when a cross-tenant read is attempted without the ADMIN role
+	ErrAdminRequired = errors.New("admin role required for cross-tenant access")
 )
 
+// TxManager manages the transactions services run their queries in. It is
+// implemented by Manager, and by FakeManager for unit tests of services.
+type TxManager interface {
+	// Begin starts a new transaction and adds it to the context
+	Begin(ctx context.Context) (context.Context, *sql.Tx, error)
+
+	// GetTx retrieves the transaction from the context
+	GetTx(ctx context.Context) (*sql.Tx, error)
+
+	// GetDB returns the database, for queries outside any transaction
+	GetDB() *sql.DB
+
+	// WithTransaction executes a function within the transaction in the context,
+	// or a new one
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// WithRetry executes a function within a transaction, running it again if it
+	// fails with a transient error
+	WithRetry(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// WithTransactionRetry executes a function within a transaction, attempting it
+	// up to attempts times if it fails with a deadlock or serialization failure
+	WithTransactionRetry(ctx context.Context, attempts int, fn func(ctx context.Context) error) error
+
+	// WithReadOnly executes a function within a read-only transaction whose
+	// queries all see the same snapshot
+	WithReadOnly(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// WithCrossTenantRead executes a function within a new read-only transaction
+	// without a tenant context
+	WithCrossTenantRead(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error
+
+	// SetTenantContext scopes the transaction in the context to a tenant
+	SetTenantContext(ctx context.Context, tenantID int64) error
+}
+
+// Ensure Manager implements TxManager
+var _ TxManager = (*Manager)(nil)
+
 // Manager provides transaction management functionality
 type Manager struct {
-	db *sql.DB
+	db            *sql.DB
+	router        *database.QueryRouter
+	retryPolicy   database.RetryPolicy
+	tenantSchemas bool
+
+	// statementTimeout bounds each statement of the transactions started, unless
+	// the context sets another timeout
+	statementTimeout time.Duration
 }
 
 // NewManager creates a new transaction manager
 func NewManager(db *sql.DB) *Manager {
-	return &Manager{db: db}
+	return &Manager{
+		db:          db,
+		retryPolicy: database.DefaultRetryPolicy,
+	}
+}
+
+// WithRetryPolicy sets how WithRetry retries transactions
+func (m *Manager) WithRetryPolicy(policy database.RetryPolicy) *Manager {
+	m.retryPolicy = policy
+	return m
+}
+
+// WithQueryRouter runs the transactions of requests that only read on the
+// router's replicas
+func (m *Manager) WithQueryRouter(router *database.QueryRouter) *Manager {
+	m.router = router
+	return m
+}
+
+// WithTenantSchemas resolves the tables of transactions with a tenant context in
+// the tenant's own schema before the shared ones, for schema-per-tenant mode
+func (m *Manager) WithTenantSchemas() *Manager {
+	m.tenantSchemas = true
+	return m
+}
+
+// WithStatementTimeout cancels statements of the transactions it starts that run
+// longer than timeout, so a slow query can't hold a connection until the request
+// times out. Zero keeps the database's setting.
+func (m *Manager) WithStatementTimeout(timeout time.Duration) *Manager {
+	m.statementTimeout = timeout
+	return m
 }
 
 // GetDB returns the database connection
@@ -30,39 +115,109 @@ func (m *Manager) GetDB() *sql.DB {
 
 // Begin starts a new transaction and adds it to the context
 func (m *Manager) Begin(ctx context.Context) (context.Context, *sql.Tx, error) {
+	return m.BeginWithOptions(ctx, nil)
+}
+
+// BeginWithOptions starts a new transaction with the given isolation level and
+// read-only setting and adds it to the context. Nil options use the database's
+// defaults. A transaction already in the context is returned as it is.
+func (m *Manager) BeginWithOptions(ctx context.Context, opts *sql.TxOptions) (context.Context, *sql.Tx, error) {
 	// Check if there's already a transaction in the context
-	if tx, ok := ctx.Value(TxKey).(*sql.Tx); ok {
+	if tx, ok, err := txFromContext(ctx); ok {
 		// Return the existing transaction
-		return ctx, tx, nil
+		return ctx, tx, err
 	}
 
 	// Start a new transaction
-	tx, err := m.db.BeginTx(ctx, nil)
+	tx, err := m.beginTx(ctx, m.db, opts)
 	if err != nil {
-		return ctx, nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return ctx, nil, err
 	}
 
-	// Add the transaction to the context
-	ctx = context.WithValue(ctx, TxKey, tx)
+	// Add the transaction, its commit hooks and rollback flag to the context
+	ctx = withRollbackFlag(withCommitHooks(context.WithValue(ctx, TxKey, tx)))
 	return ctx, tx, nil
 }
 
-// GetTx retrieves the transaction from the context
+// beginTx starts a transaction on db, bounding its statements by the statement
+// timeout
+func (m *Manager) beginTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := setStatementTimeout(ctx, tx, m.statementTimeoutFor(ctx)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// statementTimeoutFor returns the statement timeout of transactions started with
+// the context
+func (m *Manager) statementTimeoutFor(ctx context.Context) time.Duration {
+	if timeout, ok := StatementTimeoutFrom(ctx); ok {
+		return timeout
+	}
+	return m.statementTimeout
+}
+
+// setStatementTimeout bounds the statements of tx by timeout until the end of the
+// transaction. Zero keeps the current setting.
+func setStatementTimeout(ctx context.Context, tx *sql.Tx, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "SELECT set_config('statement_timeout', $1, TRUE)", strconv.FormatInt(timeout.Milliseconds(), 10))
+	if err != nil {
+		return fmt.Errorf("failed to set statement timeout: %w", err)
+	}
+	return nil
+}
+
+// Querier runs queries. It is implemented by *sql.DB and *sql.Tx.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// QuerierFor returns the transaction in the context, so queries run with its
+// tenant context under row level security, or db outside a transaction, e.g. in
+// background jobs
+func QuerierFor(ctx context.Context, db *sql.DB) Querier {
+	tx, ok, err := txFromContext(ctx)
+	if ok && err == nil {
+		return tx
+	}
+	if err != nil {
+		logLazyFailure(err)
+	}
+	return db
+}
+
+// GetTx retrieves the transaction from the context, beginning it if the request's
+// transaction is begun lazily
 func (m *Manager) GetTx(ctx context.Context) (*sql.Tx, error) {
-	tx, ok := ctx.Value(TxKey).(*sql.Tx)
+	tx, ok, err := txFromContext(ctx)
 	if !ok {
 		return nil, ErrNoTransaction
 	}
-	return tx, nil
+	return tx, err
 }
 
-// Commit commits the transaction in the context
+// Commit commits the transaction in the context and runs its commit hooks
 func (m *Manager) Commit(ctx context.Context) error {
 	tx, err := m.GetTx(ctx)
 	if err != nil {
 		return err
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	runCommitHooks(ctx)
+	return nil
 }
 
 // Rollback rolls back the transaction in the context
@@ -78,68 +233,216 @@ func (m *Manager) Rollback(ctx context.Context) error {
 // If there's already a transaction in the context, it will use that transaction
 // Otherwise, it will start a new transaction
 func (m *Manager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithTransactionOptions(ctx, nil, fn)
+}
+
+// WithTransactionOptions executes a function within a transaction like
+// WithTransaction, starting it with the given isolation level and read-only
+// setting. The options don't apply to a transaction already in the context.
+func (m *Manager) WithTransactionOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
 	// Check if there's already a transaction in the context
-	_, ok := ctx.Value(TxKey).(*sql.Tx)
-	if ok {
+	if _, ok, err := txFromContext(ctx); ok {
+		if err != nil {
+			return err
+		}
 		// Use the existing transaction
 		return fn(ctx)
 	}
 
+	// Trace the transaction, with its queries under it
+	ctx, span := startTxSpan(ctx, opts)
+
 	// Start a new transaction
-	tx, err := m.db.BeginTx(ctx, nil)
+	tx, err := m.beginTx(ctx, m.db, opts)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		endTxSpan(span, outcomeRollback, err)
+		return err
 	}
 
-	// Add the transaction to the context
-	ctx = context.WithValue(ctx, TxKey, tx)
+	// Add the transaction, its commit hooks and rollback flag to the context
+	ctx = withRollbackFlag(withCommitHooks(context.WithValue(ctx, TxKey, tx)))
 
 	// Execute the function
 	err = fn(ctx)
-	if err != nil {
-		// Rollback the transaction on error
+	if err != nil || IsRollbackOnly(ctx) {
+		// Rollback the transaction on error or when asked to
 		if rbErr := tx.Rollback(); rbErr != nil {
 			log.Printf("Error rolling back transaction: %v", rbErr)
 		}
+		endTxSpan(span, outcomeRollback, err)
 		return err
 	}
 
 	// Commit the transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		err = fmt.Errorf("failed to commit transaction: %w", err)
+		endTxSpan(span, outcomeRollback, err)
+		return err
 	}
+	endTxSpan(span, outcomeCommit, nil)
+	runCommitHooks(ctx)
 
 	return nil
 }
 
-// SetTenantContext sets the tenant context for the current database session
-func (m *Manager) SetTenantContext(ctx context.Context, tenantID int64) error {
-	tx, err := m.GetTx(ctx)
+// WithReadOnly executes a function within a read-only transaction whose queries
+// all see the same snapshot of the database, for query paths that read several
+// tables and must not write. A transaction already in the context is used as it is.
+func (m *Manager) WithReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithTransactionOptions(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, fn)
+}
+
+// WithSerializable executes a function within a serializable transaction, for
+// writes that depend on what they read, such as checking a limit before an
+// insert. Serialization failures are retried as by WithRetry. Within a
+// transaction already in the context, the function runs at its isolation level.
+func (m *Manager) WithSerializable(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok, err := txFromContext(ctx); ok {
+		if err != nil {
+			return err
+		}
+		return m.WithRetry(ctx, fn)
+	}
+
+	return database.Retry(ctx, m.retryPolicy, func(ctx context.Context) error {
+		return m.WithTransactionOptions(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, fn)
+	})
+}
+
+// WithRetry executes a function within a transaction like WithTransaction, running
+// it again if it fails with a transient error such as a deadlock or serialization
+// failure, up to the attempts of the manager's retry policy. See
+// WithTransactionRetry.
+func (m *Manager) WithRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithTransactionRetry(ctx, m.retryPolicy.MaxAttempts, fn)
+}
+
+// WithTransactionRetry executes a function within a transaction like
+// WithTransaction, attempting it up to attempts times, for writes that contend on
+// hot rows. Retries wait with the backoff of the manager's retry policy. Without a
+// transaction in the context, the whole transaction is retried when it fails with
+// a transient error. Within one, the function runs from a savepoint that is
+// rolled back before each retry, and only serialization failures and deadlocks
+// are retried; if the transaction itself is lost, the error is returned. The
+// function should add commit hooks only once it can no longer fail.
+func (m *Manager) WithTransactionRetry(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	policy := m.retryPolicy
+	policy.MaxAttempts = attempts
+
+	tx, ok, err := txFromContext(ctx)
+	if !ok {
+		return database.Retry(ctx, policy, func(ctx context.Context) error {
+			return m.WithTransaction(ctx, fn)
+		})
+	}
 	if err != nil {
 		return err
 	}
 
-	// Set tenant context in the database session
-	_, err = tx.ExecContext(ctx, "SELECT set_tenant_context($1)", tenantID)
-	if err != nil {
-		return fmt.Errorf("failed to set tenant context: %w", err)
+	return database.RetryWhen(ctx, policy, database.IsConflict, func(ctx context.Context) error {
+		return withSavepoint(ctx, tx, fn)
+	})
+}
+
+// withSavepoint runs a function within a savepoint of tx, rolling back to the
+// savepoint if it fails
+func withSavepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT retry"); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT retry"); rbErr != nil {
+			// The transaction is unusable, so the error must not be retried
+			return fmt.Errorf("failed to roll back to savepoint after %v: %v", err, rbErr)
+		}
+		return err
 	}
 
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT retry"); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
 	return nil
 }
 
-// ClearTenantContext clears the tenant context for the current database session
+// SetTenantContext sets the tenant context of the transaction in the context, so
+// row level security limits its queries to the tenant's rows, and in
+// schema-per-tenant mode its search path to the tenant's schema. The settings end
+// with the transaction and never leak to other users of the connection. A request
+// transaction begun lazily gets them when it begins.
+func (m *Manager) SetTenantContext(ctx context.Context, tenantID int64) error {
+	return onTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		// Set tenant context until the end of the transaction
+		_, err := tx.ExecContext(ctx, "SELECT set_config('core.tenant_context', $1, TRUE)", strconv.FormatInt(tenantID, 10))
+		if err != nil {
+			return fmt.Errorf("failed to set tenant context: %w", err)
+		}
+
+		// Resolve tables in the tenant's schema first
+		if m.tenantSchemas {
+			_, err = tx.ExecContext(ctx, "SELECT set_config('search_path', $1, TRUE)", database.TenantSearchPath(tenantID))
+			if err != nil {
+				return fmt.Errorf("failed to set tenant schema: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ClearTenantContext clears the tenant context of the transaction in the context
 func (m *Manager) ClearTenantContext(ctx context.Context) error {
-	tx, err := m.GetTx(ctx)
+	return onTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		// Clear tenant context until the end of the transaction
+		_, err := tx.ExecContext(ctx, "SELECT set_config('core.tenant_context', '', TRUE)")
+		if err != nil {
+			return fmt.Errorf("failed to clear tenant context: %w", err)
+		}
+
+		// Resolve tables in the shared schema only
+		if m.tenantSchemas {
+			_, err = tx.ExecContext(ctx, "SELECT set_config('search_path', 'public', TRUE)")
+			if err != nil {
+				return fmt.Errorf("failed to clear tenant schema: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// WithCrossTenantRead executes a function within a new read-only transaction that
+// has no tenant context, so row level security lets it read every tenant's rows.
+// It is the code path for platform reporting and requires the ADMIN role in ctx.
+// A transaction already in the context is not reused, since it may be scoped to a
+// tenant, and the tenant context is cleared for this transaction only.
+func (m *Manager) WithCrossTenantRead(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if !authctx.IsAdmin(ctx) {
+		return ErrAdminRequired
+	}
+
+	tx, err := m.beginTx(ctx, m.db, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Clear tenant context in the database session
-	_, err = tx.ExecContext(ctx, "SELECT clear_tenant_context()")
-	if err != nil {
+	// Clear the tenant context until the end of the transaction
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('core.tenant_context', '', TRUE)"); err != nil {
 		return fmt.Errorf("failed to clear tenant context: %w", err)
 	}
 
+	userID, _ := authctx.GetUserID(ctx)
+	log.Printf("[INFO] Cross-tenant read by admin user ID %d", userID)
+
+	if err := fn(context.WithValue(ctx, TxKey, tx), tx); err != nil {
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
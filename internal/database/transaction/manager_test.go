@@ -0,0 +1,199 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+func TestWithRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager := NewManager(db).WithRetryPolicy(database.RetryPolicy{MaxAttempts: 3})
+
+	// Expect the first transaction to fail at commit and the whole transaction
+	// to be retried
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE counter").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit().WillReturnError(&pgconn.PgError{Code: database.CodeSerializationFailure})
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE counter").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts := 0
+	err = manager.WithRetry(context.Background(), func(ctx context.Context) error {
+		attempts++
+		tx, err := manager.GetTx(ctx)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, "UPDATE counter SET value = value + 1")
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTransactionRetry(t *testing.T) {
+	t.Run("Deadlocks are retried from a savepoint up to the attempts", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+		mock.ExpectBegin()
+		ctx, _, err := manager.Begin(context.Background())
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			mock.ExpectExec("SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("UPDATE counter").WillReturnError(&pgconn.PgError{Code: database.CodeDeadlockDetected})
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+
+		attempts := 0
+		err = manager.WithTransactionRetry(ctx, 2, func(ctx context.Context) error {
+			attempts++
+			tx, err := manager.GetTx(ctx)
+			require.NoError(t, err)
+			_, err = tx.ExecContext(ctx, "UPDATE counter SET value = value + 1")
+			return err
+		})
+
+		assert.True(t, database.IsConflict(err))
+		assert.Equal(t, 2, attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("A lost connection is not retried within a transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+		mock.ExpectBegin()
+		ctx, _, err := manager.Begin(context.Background())
+		require.NoError(t, err)
+
+		mock.ExpectExec("SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		lost := &pgconn.PgError{Code: "08006"}
+		attempts := 0
+		err = manager.WithTransactionRetry(ctx, 3, func(ctx context.Context) error {
+			attempts++
+			return lost
+		})
+
+		assert.ErrorIs(t, err, lost)
+		assert.Equal(t, 1, attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetTenantContextWithTenantSchemas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager := NewManager(db).WithTenantSchemas()
+
+	mock.ExpectBegin()
+	ctx, _, err := manager.Begin(context.Background())
+	require.NoError(t, err)
+
+	// Expect the tenant's schema to be searched before the shared one, and only
+	// the shared one once the tenant context is cleared
+	mock.ExpectExec("SELECT set_config\\('core.tenant_context', \\$1, TRUE\\)").
+		WithArgs("42").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SELECT set_config\\('search_path', \\$1, TRUE\\)").
+		WithArgs("tenant_42, public").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SELECT set_config\\('core.tenant_context', '', TRUE\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SELECT set_config\\('search_path', 'public', TRUE\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, manager.SetTenantContext(ctx, 42))
+	require.NoError(t, manager.ClearTenantContext(ctx))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTransactionOptions(t *testing.T) {
+	t.Run("Read only", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectCommit()
+
+		var count int
+		err = manager.WithReadOnly(context.Background(), func(ctx context.Context) error {
+			return QuerierFor(ctx, db).QueryRowContext(ctx, "SELECT count(*) FROM tenant").Scan(&count)
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Existing transaction is used", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+
+		mock.ExpectBegin()
+		ctx, tx, err := manager.BeginWithOptions(context.Background(), nil)
+		require.NoError(t, err)
+
+		// No second transaction is started
+		err = manager.WithReadOnly(ctx, func(ctx context.Context) error {
+			inner, err := manager.GetTx(ctx)
+			require.NoError(t, err)
+			assert.Same(t, tx, inner)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Serializable retries serialization failures", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db).WithRetryPolicy(database.RetryPolicy{MaxAttempts: 3})
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO seat").WillReturnError(&pgconn.PgError{Code: database.CodeSerializationFailure})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO seat").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		attempts := 0
+		err = manager.WithSerializable(context.Background(), func(ctx context.Context) error {
+			attempts++
+			_, err := QuerierFor(ctx, db).ExecContext(ctx, "INSERT INTO seat (tenant_id) VALUES (1)")
+			return err
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
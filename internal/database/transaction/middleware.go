@@ -1,69 +1,170 @@
 package transaction
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database"
 )
 
-// Middleware creates middleware for transaction management
+// ReadConsistencyHeader set to "primary" makes a request read from the primary
+// instead of a replica
+const ReadConsistencyHeader = "X-Read-Consistency"
+
+// ReadYourWritesWindow is how long a client reads from the primary after a
+// write, so it sees its changes while replicas catch up
+const ReadYourWritesWindow = 5 * time.Second
+
+// primaryReadsCookie marks clients that wrote within ReadYourWritesWindow
+const primaryReadsCookie = "read_primary"
+
+// MiddlewareConfig configures which requests the transaction middleware runs in a
+// transaction, and when it begins it
+type MiddlewareConfig struct {
+	// Lazy begins a request's transaction when a handler first asks for it, so
+	// requests that never query the database don't hold a connection
+	Lazy bool
+
+	// SkipRoutes are served without a transaction. A route is a path, optionally
+	// after a method, e.g. "GET /login". A path ending in "/" matches every path
+	// under it.
+	SkipRoutes []string
+
+	// SkipMethods are served without a transaction, e.g. OPTIONS
+	SkipMethods []string
+
+	// RollbackStatus is the lowest response status that rolls the transaction
+	// back instead of committing it, e.g. 400 to discard the writes of rejected
+	// requests. Zero means 500, so only server errors roll back.
+	RollbackStatus int
+}
+
+// rollsBack reports whether a response with the given status rolls back
+func (c MiddlewareConfig) rollsBack(status int) bool {
+	threshold := c.RollbackStatus
+	if threshold == 0 {
+		threshold = http.StatusInternalServerError
+	}
+	return status < http.StatusOK || status >= threshold
+}
+
+// skips reports whether a request is served without a transaction
+func (c MiddlewareConfig) skips(r *http.Request) bool {
+	for _, method := range c.SkipMethods {
+		if r.Method == method {
+			return true
+		}
+	}
+
+	for _, route := range c.SkipRoutes {
+		method, path, found := strings.Cut(route, " ")
+		if !found {
+			method, path = "", route
+		} else if method != r.Method {
+			continue
+		}
+
+		if r.URL.Path == path || (strings.HasSuffix(path, "/") && strings.HasPrefix(r.URL.Path, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware creates middleware for transaction management. With a query router,
+// GET and HEAD requests run in a read-only transaction on a replica unless the
+// client asks for the primary or wrote recently.
 func (m *Manager) Middleware() func(http.Handler) http.Handler {
+	return m.MiddlewareWithConfig(MiddlewareConfig{})
+}
+
+// MiddlewareWithConfig creates middleware for transaction management like
+// Middleware, skipping the routes in the configuration and, if it is lazy,
+// beginning each request's transaction only when a handler asks for it. The
+// transaction commits unless the response status is the configuration's rollback
+// status or above, or the handler marked it with SetRollbackOnly.
+func (m *Manager) MiddlewareWithConfig(config MiddlewareConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Start a new transaction
-			ctx, tx, err := m.Begin(r.Context())
-			if err != nil {
-				log.Printf("Error starting transaction: %v", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			if config.skips(r) {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Create a response writer that captures the status code
-			rw := newResponseWriter(w)
-
-			// Set tenant context if available
-			tenantID, err := authctx.GetTenantID(ctx)
-			if err == nil && tenantID != nil {
-				if err := m.SetTenantContext(ctx, *tenantID); err != nil {
-					log.Printf("Error setting tenant context: %v", err)
-					tx.Rollback()
+			// Start a new transaction, on a replica if the request only reads,
+			// or prepare to start it when it is first asked for
+			ctx, begin := m.requestTx(w, r)
+			ctx, span := startTxSpan(ctx, nil)
+			var lazy *lazyTx
+			var tx *sql.Tx
+			if config.Lazy {
+				lazy = &lazyTx{ctx: ctx, begin: begin}
+				ctx = context.WithValue(ctx, TxKey, lazy)
+			} else {
+				var err error
+				tx, err = begin(ctx)
+				if err != nil {
+					log.Printf("Error starting transaction: %v", err)
+					endTxSpan(span, outcomeRollback, err)
 					http.Error(w, "Internal server error", http.StatusInternalServerError)
 					return
 				}
+				ctx = context.WithValue(ctx, TxKey, tx)
 			}
+			ctx = withRollbackFlag(withCommitHooks(ctx))
+
+			// Create a response writer that captures the status code
+			rw := newResponseWriter(w)
 
 			// Update the request with the new context
 			r = r.WithContext(ctx)
 
 			// Call the next handler
 			defer func() {
+				// A lazy transaction may never have begun
+				if lazy != nil {
+					tx = lazy.started()
+				}
+
 				// Recover from panics
 				if rec := recover(); rec != nil {
 					log.Printf("Panic in handler: %v", rec)
-					tx.Rollback()
-					panic(rec) // Re-panic after rollback
-				}
-
-				// Clear tenant context
-				if tenantID != nil {
-					if err := m.ClearTenantContext(ctx); err != nil {
-						log.Printf("Error clearing tenant context: %v", err)
+					if tx != nil {
+						tx.Rollback()
 					}
+					endTxSpan(span, outcomeRollback, fmt.Errorf("panic: %v", rec))
+					panic(rec) // Re-panic after rollback
 				}
 
 				// Commit or rollback based on the response status
-				if rw.statusCode >= 200 && rw.statusCode < 500 {
-					// Success or client error, commit the transaction
-					if err := tx.Commit(); err != nil {
+				if !config.rollsBack(rw.statusCode) && !IsRollbackOnly(ctx) {
+					// Success, or a client error below the rollback status, commit the transaction
+					if tx == nil {
+						runCommitHooks(ctx)
+					} else if err := tx.Commit(); err != nil {
 						log.Printf("Error committing transaction: %v", err)
+						endTxSpan(span, outcomeRollback, err)
 						http.Error(w, "Internal server error", http.StatusInternalServerError)
+						return
+					} else {
+						runCommitHooks(ctx)
 					}
+					endTxSpan(span, outcomeCommit, nil)
 				} else {
-					// Server error, rollback the transaction
-					if err := tx.Rollback(); err != nil {
-						log.Printf("Error rolling back transaction: %v", err)
+					// Server error or rollback requested, rollback the transaction
+					if tx != nil {
+						if err := tx.Rollback(); err != nil {
+							log.Printf("Error rolling back transaction: %v", err)
+						}
 					}
+					endTxSpan(span, outcomeRollback, nil)
 				}
 			}()
 
@@ -73,6 +174,84 @@ func (m *Manager) Middleware() func(http.Handler) http.Handler {
 	}
 }
 
+// TenantContext creates middleware that scopes the request's transaction to the
+// tenant in the context, so row level security limits every query in it to the
+// tenant's rows. It must run after authentication has resolved the tenant.
+func (m *Manager) TenantContext() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, err := authctx.GetTenantID(r.Context())
+			if err != nil || tenantID == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := m.SetTenantContext(r.Context(), *tenantID); err != nil {
+				log.Printf("Error setting tenant context: %v", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StatementTimeout creates middleware that bounds the statements of the request's
+// transaction, and of transactions the request starts, by timeout instead of the
+// manager's statement timeout, for route groups with slower or faster queries
+func (m *Manager) StatementTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := WithStatementTimeout(r.Context(), timeout)
+
+			err := onTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				return setStatementTimeout(ctx, tx, timeout)
+			})
+			if err != nil && !errors.Is(err, ErrNoTransaction) {
+				log.Printf("Error setting statement timeout: %v", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestTx returns the context of a request's transaction and a function
+// beginning it, on a replica if the request only reads. Requests that write send
+// the client to the primary for ReadYourWritesWindow.
+func (m *Manager) requestTx(w http.ResponseWriter, r *http.Request) (context.Context, func(ctx context.Context) (*sql.Tx, error)) {
+	ctx := r.Context()
+	primary := func(ctx context.Context) (*sql.Tx, error) {
+		return m.beginTx(ctx, m.db, nil)
+	}
+	if m.router == nil || !m.router.HasReplicas() {
+		return ctx, primary
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.SetCookie(w, &http.Cookie{
+			Name:     primaryReadsCookie,
+			Value:    "1",
+			Path:     "/",
+			MaxAge:   int(ReadYourWritesWindow.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return database.WithPrimaryReads(ctx), primary
+	}
+
+	if _, err := r.Cookie(primaryReadsCookie); err == nil || r.Header.Get(ReadConsistencyHeader) == "primary" {
+		return database.WithPrimaryReads(ctx), primary
+	}
+
+	return ctx, func(ctx context.Context) (*sql.Tx, error) {
+		return m.beginTx(ctx, m.router.Reader(ctx), &sql.TxOptions{ReadOnly: true})
+	}
+}
+
 // responseWriter is a wrapper around http.ResponseWriter that captures the status code
 type responseWriter struct {
 	http.ResponseWriter
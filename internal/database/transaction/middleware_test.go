@@ -0,0 +1,375 @@
+package transaction
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+func TestMiddlewareReplicaRouting(t *testing.T) {
+	// serve sends a request through the middleware of a manager with one replica,
+	// expecting its transaction on the primary or the replica
+	serve := func(t *testing.T, r *http.Request, onReplica bool) *httptest.ResponseRecorder {
+		primary, primaryMock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer primary.Close()
+		replica, replicaMock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer replica.Close()
+
+		expected := primaryMock
+		if onReplica {
+			expected = replicaMock
+		}
+		expected.ExpectBegin()
+		expected.ExpectCommit()
+
+		manager := NewManager(primary).WithQueryRouter(database.NewQueryRouter(primary, replica))
+		handler := manager.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := manager.GetTx(r.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, !onReplica, database.PrimaryReads(r.Context()))
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+		return w
+	}
+
+	t.Run("Reads go to a replica", func(t *testing.T) {
+		w := serve(t, httptest.NewRequest(http.MethodGet, "/orders/api", nil), true)
+
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("Writes go to the primary", func(t *testing.T) {
+		w := serve(t, httptest.NewRequest(http.MethodPost, "/orders/api", nil), false)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, primaryReadsCookie, cookies[0].Name)
+		assert.Equal(t, int(ReadYourWritesWindow.Seconds()), cookies[0].MaxAge)
+	})
+
+	t.Run("Reads after a write go to the primary", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/orders/api", nil)
+		r.AddCookie(&http.Cookie{Name: primaryReadsCookie, Value: "1"})
+
+		serve(t, r, false)
+	})
+
+	t.Run("Reads can ask for the primary", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/orders/api", nil)
+		r.Header.Set(ReadConsistencyHeader, "primary")
+
+		serve(t, r, false)
+	})
+}
+
+func TestTenantContext(t *testing.T) {
+	// serve sends a request with the given tenant through the transaction and
+	// tenant context middleware, checking that queries run in the transaction
+	serve := func(t *testing.T, tenantID *int64, expect func(mock sqlmock.Sqlmock)) *httptest.ResponseRecorder {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		expect(mock)
+		mock.ExpectCommit()
+
+		manager := NewManager(db)
+		handler := manager.TenantContext()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tx, err := manager.GetTx(r.Context())
+			require.NoError(t, err)
+			assert.Same(t, tx, QuerierFor(r.Context(), db))
+		}))
+
+		r := httptest.NewRequest(http.MethodPost, "/tenant/webhooks", nil)
+		r = r.WithContext(authctx.WithTenantID(r.Context(), tenantID))
+		w := httptest.NewRecorder()
+		manager.Middleware()(handler).ServeHTTP(w, r)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+		return w
+	}
+
+	t.Run("Sets the tenant context", func(t *testing.T) {
+		tenantID := int64(42)
+		w := serve(t, &tenantID, func(mock sqlmock.Sqlmock) {
+			mock.ExpectExec("SELECT set_config\\('core.tenant_context', \\$1, TRUE\\)").
+				WithArgs(strconv.FormatInt(tenantID, 10)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Without a tenant", func(t *testing.T) {
+		w := serve(t, nil, func(mock sqlmock.Sqlmock) {})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestQuerierFor(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Outside a transaction, queries run on the database
+	assert.Same(t, db, QuerierFor(context.Background(), db))
+}
+
+func TestStatementTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager := NewManager(db).WithStatementTimeout(30 * time.Second)
+
+	// Expect the request's transaction to be bounded by the manager's timeout,
+	// then the route group's, and a transaction the handler starts by the route
+	// group's too
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config\\('statement_timeout', \\$1, TRUE\\)").
+		WithArgs("30000").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SELECT set_config\\('statement_timeout', \\$1, TRUE\\)").
+		WithArgs("55000").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config\\('statement_timeout', \\$1, TRUE\\)").
+		WithArgs("55000").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectCommit()
+
+	handler := manager.Middleware()(manager.StatementTimeout(55 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout, ok := StatementTimeoutFrom(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, 55*time.Second, timeout)
+
+		// A separate transaction, as for cross-tenant reads
+		ctx := context.WithValue(r.Context(), TxKey, nil)
+		err := manager.WithTransaction(ctx, func(ctx context.Context) error { return nil })
+		assert.NoError(t, err)
+	})))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reports/orders", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddlewareWithConfig(t *testing.T) {
+	config := MiddlewareConfig{
+		SkipRoutes:  []string{"GET /login", "/static/"},
+		SkipMethods: []string{http.MethodOptions},
+	}
+
+	t.Run("Skipped routes", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+		handler := manager.MiddlewareWithConfig(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := manager.GetTx(r.Context())
+			assert.ErrorIs(t, err, ErrNoTransaction)
+		}))
+
+		// Expect no transaction for any of the skipped requests
+		for _, r := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/login", nil),
+			httptest.NewRequest(http.MethodGet, "/static/css/output.css", nil),
+			httptest.NewRequest(http.MethodOptions, "/orders/api", nil),
+		} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Other methods of a skipped route", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		manager := NewManager(db)
+		handler := manager.MiddlewareWithConfig(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lazy transaction never asked for", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		// Expect no transaction, though the tenant context is set and commit
+		// hooks still run
+		manager := NewManager(db)
+		committed := false
+		handler := manager.TenantContext()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, AfterCommit(r.Context(), func() { committed = true }))
+		}))
+
+		tenantID := int64(42)
+		r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		r = r.WithContext(authctx.WithTenantID(r.Context(), &tenantID))
+		manager.MiddlewareWithConfig(MiddlewareConfig{Lazy: true})(handler).ServeHTTP(httptest.NewRecorder(), r)
+
+		assert.True(t, committed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lazy transaction begun by the handler", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		// Expect the tenant context to be set once the transaction begins
+		tenantID := int64(42)
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT set_config\\('core.tenant_context', \\$1, TRUE\\)").
+			WithArgs(strconv.FormatInt(tenantID, 10)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		manager := NewManager(db)
+		handler := manager.TenantContext()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tx, err := manager.GetTx(r.Context())
+			require.NoError(t, err)
+			assert.Same(t, tx, QuerierFor(r.Context(), db))
+
+			// Asking again returns the same transaction
+			again, err := manager.GetTx(r.Context())
+			require.NoError(t, err)
+			assert.Same(t, tx, again)
+		}))
+
+		r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		r = r.WithContext(authctx.WithTenantID(r.Context(), &tenantID))
+		w := httptest.NewRecorder()
+		manager.MiddlewareWithConfig(MiddlewareConfig{Lazy: true})(handler).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lazy transaction rolled back on a server error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		manager := NewManager(db)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := manager.GetTx(r.Context())
+			require.NoError(t, err)
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		manager.MiddlewareWithConfig(MiddlewareConfig{Lazy: true})(handler).
+			ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMiddlewareRollbackPolicy(t *testing.T) {
+	// serve sends a request through the middleware, expecting its transaction to
+	// commit or roll back
+	serve := func(t *testing.T, config MiddlewareConfig, handler http.HandlerFunc, commit bool) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		if commit {
+			mock.ExpectCommit()
+		} else {
+			mock.ExpectRollback()
+		}
+
+		NewManager(db).MiddlewareWithConfig(config)(handler).
+			ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+
+	status := func(code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		}
+	}
+
+	t.Run("Client errors commit by default", func(t *testing.T) {
+		serve(t, MiddlewareConfig{}, status(http.StatusUnprocessableEntity), true)
+	})
+
+	t.Run("Server errors roll back by default", func(t *testing.T) {
+		serve(t, MiddlewareConfig{}, status(http.StatusBadGateway), false)
+	})
+
+	t.Run("Client errors roll back from the rollback status", func(t *testing.T) {
+		serve(t, MiddlewareConfig{RollbackStatus: http.StatusBadRequest}, status(http.StatusUnprocessableEntity), false)
+	})
+
+	t.Run("Successes commit with the rollback status", func(t *testing.T) {
+		serve(t, MiddlewareConfig{RollbackStatus: http.StatusBadRequest}, status(http.StatusCreated), true)
+	})
+
+	t.Run("Handler forces a rollback", func(t *testing.T) {
+		committed := false
+		serve(t, MiddlewareConfig{}, func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, AfterCommit(r.Context(), func() { committed = true }))
+			require.NoError(t, SetRollbackOnly(r.Context()))
+		}, false)
+
+		assert.False(t, committed, "commit hooks must not run after a rollback")
+	})
+}
+
+func TestSetRollbackOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager := NewManager(db)
+
+	t.Run("Rolls back WithTransaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := manager.WithTransaction(context.Background(), func(ctx context.Context) error {
+			return SetRollbackOnly(ctx)
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Without a transaction", func(t *testing.T) {
+		assert.ErrorIs(t, SetRollbackOnly(context.Background()), ErrNoTransaction)
+		assert.False(t, IsRollbackOnly(context.Background()))
+	})
+}
@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+// Required wraps the handler of a route that needs the request's transaction. A
+// transaction begun lazily begins before the handler runs, so the handler never
+// starts work it can't finish, and the request fails with a server error if
+// there is no transaction, e.g. because the route is skipped.
+func Required(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok, err := txFromContext(r.Context())
+		if !ok {
+			err = ErrNoTransaction
+		}
+		if err != nil {
+			log.Printf("Error starting transaction for %s %s: %v", r.Method, r.URL.Path, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// None wraps the handler of a route that doesn't use the database. A transaction
+// begun lazily never begins, and the handler runs without one, as on a skipped
+// route. A transaction begun eagerly still commits, empty.
+func None(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(withoutTransaction(r.Context())))
+	})
+}
+
+// withoutTransaction returns a context without the transaction, commit hooks and
+// rollback flag of its parent
+func withoutTransaction(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, TxKey, nil)
+	ctx = context.WithValue(ctx, HooksKey, nil)
+	return context.WithValue(ctx, rollbackOnlyKey, nil)
+}
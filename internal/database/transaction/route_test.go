@@ -0,0 +1,66 @@
+package transaction
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteDeclarations(t *testing.T) {
+	lazy := MiddlewareConfig{Lazy: true}
+
+	t.Run("Required begins the transaction before the handler", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		// The handler doesn't query, but its route declares it needs the transaction
+		handler := NewManager(db).MiddlewareWithConfig(lazy)(Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Required without a transaction", func(t *testing.T) {
+		served := false
+		handler := Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served = true
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.False(t, served)
+	})
+
+	t.Run("None never begins the transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		manager := NewManager(db)
+		handler := manager.MiddlewareWithConfig(lazy)(None(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := manager.GetTx(r.Context())
+			assert.ErrorIs(t, err, ErrNoTransaction)
+			assert.ErrorIs(t, AfterCommit(r.Context(), func() {}), ErrNoTransaction)
+			assert.Same(t, db, QuerierFor(r.Context(), db))
+		})))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
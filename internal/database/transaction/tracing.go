@@ -0,0 +1,54 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the transaction spans
+const instrumentationName = "github.com/unsavory/silocore-go/internal/database/transaction"
+
+// Attributes of transaction spans
+const (
+	attributeReadOnly  attribute.Key = "db.transaction.read_only"
+	attributeIsolation attribute.Key = "db.transaction.isolation"
+	attributeOutcome   attribute.Key = "db.transaction.outcome"
+)
+
+// Transaction outcomes
+const (
+	outcomeCommit   = "commit"
+	outcomeRollback = "rollback"
+)
+
+// startTxSpan starts a span for a transaction begun with opts, under which its
+// queries are traced
+func startTxSpan(ctx context.Context, opts *sql.TxOptions) (context.Context, trace.Span) {
+	if opts == nil {
+		opts = &sql.TxOptions{}
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, "transaction",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			attributeReadOnly.Bool(opts.ReadOnly),
+			attributeIsolation.String(opts.Isolation.String()),
+		),
+	)
+}
+
+// endTxSpan ends a transaction's span with its outcome and error, if any
+func endTxSpan(span trace.Span, outcome string, err error) {
+	span.SetAttributes(attributeOutcome.String(outcome))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
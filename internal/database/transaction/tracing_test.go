@@ -0,0 +1,65 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTransactionSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	manager := NewManager(db)
+
+	t.Run("Commit", func(t *testing.T) {
+		recorder.Reset()
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		var fnSpan trace.SpanContext
+		err := manager.WithTransaction(context.Background(), func(ctx context.Context) error {
+			fnSpan = trace.SpanContextFromContext(ctx)
+			return nil
+		})
+
+		require.NoError(t, err)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "transaction", spans[0].Name())
+		assert.Equal(t, spans[0].SpanContext(), fnSpan)
+		assert.Contains(t, spans[0].Attributes(), attributeOutcome.String(outcomeCommit))
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		recorder.Reset()
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := manager.WithTransaction(context.Background(), func(ctx context.Context) error {
+			return errors.New("failed")
+		})
+
+		require.Error(t, err)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Contains(t, spans[0].Attributes(), attributeOutcome.String(outcomeRollback))
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// typeMaps holds the pgx type maps used to encode and decode arrays. A type map
+// memoizes its plans and is not safe for concurrent use.
+var typeMaps = sync.Pool{
+	New: func() any { return pgtype.NewMap() },
+}
+
+// arrayParam is an array parameter, sent in PostgreSQL's text format
+type arrayParam struct {
+	value any
+}
+
+// Value encodes the array, or returns nil for a nil slice
+func (a arrayParam) Value() (driver.Value, error) {
+	m := typeMaps.Get().(*pgtype.Map)
+	defer typeMaps.Put(m)
+
+	dataType, ok := m.TypeForValue(a.value)
+	if !ok {
+		return nil, fmt.Errorf("unsupported array parameter %T", a.value)
+	}
+	buf, err := m.Encode(dataType.OID, pgtype.TextFormatCode, a.value, nil)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	return string(buf), nil
+}
+
+// Array returns a PostgreSQL array parameter for a slice such as a []string or
+// []int64
+func Array(a any) driver.Valuer {
+	return arrayParam{value: a}
+}
+
+// StringArray scans a PostgreSQL text array column into a []string
+type StringArray []string
+
+// Scan decodes a text array, or nil for NULL
+func (a *StringArray) Scan(src any) error {
+	var text []byte
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		text = src
+	case string:
+		text = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into StringArray", src)
+	}
+
+	m := typeMaps.Get().(*pgtype.Map)
+	defer typeMaps.Put(m)
+
+	var values []string
+	if err := m.Scan(pgtype.TextArrayOID, pgtype.TextFormatCode, text, &values); err != nil {
+		return err
+	}
+	*a = values
+	return nil
+}
+
+// registerTextParams lets numbers and booleans be sent for parameters
+// PostgreSQL infers as text, such as $1::TEXT or a comparison with a text
+// column. lib/pq sent every parameter as text for the server to convert; pgx
+// encodes parameters by their PostgreSQL type and has no plan for these. The
+// plan is only tried when no other one applies.
+func registerTextParams(m *pgtype.Map) {
+	m.TryWrapEncodePlanFuncs = append(m.TryWrapEncodePlanFuncs, tryWrapTextParamEncodePlan)
+}
+
+// textParam is a number or boolean formatted as text
+type textParam string
+
+// TextValue returns the formatted value
+func (p textParam) TextValue() (pgtype.Text, error) {
+	return pgtype.Text{String: string(p), Valid: true}, nil
+}
+
+// textParamEncodePlan encodes a number or boolean as a textParam
+type textParamEncodePlan struct {
+	next pgtype.EncodePlan
+}
+
+// SetNext sets the plan encoding the textParam
+func (plan *textParamEncodePlan) SetNext(next pgtype.EncodePlan) {
+	plan.next = next
+}
+
+// Encode formats value and encodes it
+func (plan *textParamEncodePlan) Encode(value any, buf []byte) ([]byte, error) {
+	text, _ := formatTextParam(value)
+	return plan.next.Encode(text, buf)
+}
+
+// tryWrapTextParamEncodePlan wraps numbers and booleans, including values of
+// types defined on them, as textParams
+func tryWrapTextParamEncodePlan(value any) (pgtype.WrappedEncodePlanNextSetter, any, bool) {
+	if text, ok := formatTextParam(value); ok {
+		return &textParamEncodePlan{}, text, true
+	}
+	return nil, nil, false
+}
+
+// formatTextParam formats a number or boolean as PostgreSQL reads it from text
+func formatTextParam(value any) (textParam, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return textParam(strconv.FormatInt(v.Int(), 10)), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return textParam(strconv.FormatUint(v.Uint(), 10)), true
+	case reflect.Float32, reflect.Float64:
+		return textParam(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())), true
+	case reflect.Bool:
+		return textParam(strconv.FormatBool(v.Bool())), true
+	}
+	return "", false
+}
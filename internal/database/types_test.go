@@ -0,0 +1,63 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArray(t *testing.T) {
+	value, err := Array([]string{"orders:read", "rush order", " vip", `say "hi"`, "NULL"}).Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{orders:read,rush order," vip","say \"hi\"","NULL"}`, value)
+
+	value, err = Array([]int64{1, 2, 3}).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "{1,2,3}", value)
+
+	value, err = Array([]string(nil)).Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestStringArray(t *testing.T) {
+	var scanned StringArray
+	require.NoError(t, scanned.Scan(`{a,"b,c"," d"}`))
+	assert.Equal(t, StringArray{"a", "b,c", " d"}, scanned)
+
+	require.NoError(t, scanned.Scan([]byte(`{}`)))
+	assert.Equal(t, StringArray{}, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Nil(t, scanned)
+}
+
+func TestTextParams(t *testing.T) {
+	type status int
+	m := pgtype.NewMap()
+
+	// Expect numbers to be rejected for text parameters until registered
+	_, err := m.Encode(pgtype.TextOID, pgtype.TextFormatCode, int64(42), nil)
+	assert.Error(t, err)
+
+	registerTextParams(m)
+	for value, expected := range map[any]string{
+		int64(42):  "42",
+		status(7):  "7",
+		uint16(3):  "3",
+		2.5:        "2.5",
+		true:       "true",
+		"as it is": "as it is",
+	} {
+		buf, err := m.Encode(pgtype.TextOID, pgtype.TextFormatCode, value, nil)
+		require.NoError(t, err, "%T", value)
+		assert.Equal(t, expected, string(buf))
+	}
+
+	// Expect numbers to keep their own encoding for numeric parameters
+	buf, err := m.Encode(pgtype.Int8OID, pgtype.BinaryFormatCode, int64(42), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 42}, buf)
+}
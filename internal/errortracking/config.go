@@ -0,0 +1,46 @@
+package errortracking
+
+import (
+	"log"
+	"os"
+)
+
+// Environment variable names
+const (
+	envSentryDSN         = "SENTRY_DSN"
+	envSentryEnvironment = "SENTRY_ENVIRONMENT"
+)
+
+// Config holds configuration for reporting errors
+type Config struct {
+	// SentryDSN is the DSN of the Sentry project errors are reported to. Errors
+	// are only logged if it is empty.
+	SentryDSN string
+
+	// Environment tags reports, e.g. production or staging
+	Environment string
+}
+
+// LoadConfig loads error tracking configuration from environment variables
+func LoadConfig() Config {
+	return Config{
+		SentryDSN:   os.Getenv(envSentryDSN),
+		Environment: os.Getenv(envSentryEnvironment),
+	}
+}
+
+// New creates the Reporter selected by the configuration.
+// Errors are logged rather than reported when SENTRY_DSN is not set.
+func New(config Config) (Reporter, error) {
+	if config.SentryDSN == "" {
+		log.Printf("[INFO] SENTRY_DSN is not set, errors will be logged instead of reported")
+		return LogReporter{}, nil
+	}
+
+	reporter, err := NewSentryReporter(config.SentryDSN, config.Environment)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] Reporting errors to Sentry")
+	return reporter, nil
+}
@@ -0,0 +1,197 @@
+package errortracking
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Event is an unexpected error, such as a panic, to be investigated
+type Event struct {
+	// Err is the error, or the value a panic was called with
+	Err error
+
+	// Stack is the stack trace of the goroutine the error occurred in
+	Stack []byte
+
+	// RequestID, Method and URL identify the request being served, if any
+	RequestID string
+	Method    string
+	URL       string
+
+	// UserID and TenantID are the authenticated user and their tenant, if known
+	UserID   *int64
+	TenantID *int64
+
+	Time time.Time
+}
+
+// Reporter sends events to an error tracker. Reports are best effort: Report
+// doesn't block on the tracker, and failures to report are only logged.
+type Reporter interface {
+	Report(ctx context.Context, event Event)
+}
+
+// LogReporter writes events to the log. It is used when no error tracker is
+// configured.
+type LogReporter struct{}
+
+// Ensure LogReporter implements Reporter
+var _ Reporter = LogReporter{}
+
+// Report logs the event with its stack trace
+func (LogReporter) Report(ctx context.Context, event Event) {
+	log.Printf("[ERROR] %s %s (request %s): %v\n%s", event.Method, event.URL, event.RequestID, event.Err, event.Stack)
+}
+
+// SentryReporter sends events to Sentry, or a server implementing its store API
+// such as GlitchTip
+type SentryReporter struct {
+	storeURL    string
+	auth        string
+	environment string
+	client      *http.Client
+}
+
+// Ensure SentryReporter implements Reporter
+var _ Reporter = (*SentryReporter)(nil)
+
+// NewSentryReporter creates a new SentryReporter for a project's DSN, e.g.
+// https://<key>@o123.ingest.sentry.io/456. Events are tagged with environment
+// if it isn't empty.
+func NewSentryReporter(dsn string, environment string) (*SentryReporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: missing public key")
+	}
+
+	// The project ID is the last segment of the path; any before it prefix the API
+	path := strings.TrimSuffix(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	if slash < 0 || path[slash+1:] == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: missing project ID")
+	}
+	prefix, projectID := path[:slash], path[slash+1:]
+
+	return &SentryReporter{
+		storeURL:    fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, prefix, projectID),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=silocore-go/1.0, sentry_key=%s", parsed.User.Username()),
+		environment: environment,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// sentryEvent is the body of the store API
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Message     string            `json:"message"`
+	Request     *sentryRequest    `json:"request,omitempty"`
+	User        *sentryUser       `json:"user,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+// sentryRequest is the request an event occurred in
+type sentryRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// sentryUser is the user an event occurred for
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+// Report sends the event to Sentry in the background
+func (s *SentryReporter) Report(ctx context.Context, event Event) {
+	log.Printf("[ERROR] %s %s (request %s): %v, reporting to Sentry", event.Method, event.URL, event.RequestID, event.Err)
+
+	body, err := json.Marshal(s.sentryEvent(event))
+	if err != nil {
+		log.Printf("[ERROR] Failed to encode error report: %v", err)
+		return
+	}
+
+	go func() {
+		if err := s.send(body); err != nil {
+			log.Printf("[ERROR] Failed to report error to Sentry: %v", err)
+		}
+	}()
+}
+
+// sentryEvent converts an event to the body of the store API
+func (s *SentryReporter) sentryEvent(event Event) sentryEvent {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	payload := sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   event.Time.UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Environment: s.environment,
+		Message:     fmt.Sprint(event.Err),
+		Tags:        map[string]string{},
+		Extra:       map[string]string{},
+	}
+	if event.Method != "" {
+		payload.Request = &sentryRequest{Method: event.Method, URL: event.URL}
+	}
+	if event.RequestID != "" {
+		payload.Tags["request_id"] = event.RequestID
+	}
+	if event.UserID != nil {
+		payload.User = &sentryUser{ID: fmt.Sprint(*event.UserID)}
+	}
+	if event.TenantID != nil {
+		payload.Tags["tenant_id"] = fmt.Sprint(*event.TenantID)
+	}
+	if len(event.Stack) > 0 {
+		payload.Extra["stack"] = string(event.Stack)
+	}
+	return payload
+}
+
+// send posts an encoded event to the store API
+func (s *SentryReporter) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// newEventID returns a random ID in the 32 hex digit format Sentry expects
+func newEventID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
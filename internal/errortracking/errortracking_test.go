@@ -0,0 +1,79 @@
+package errortracking
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSentryReporter(t *testing.T) {
+	t.Run("Builds the store URL from the DSN", func(t *testing.T) {
+		reporter, err := NewSentryReporter("https://abc123@o1.ingest.sentry.io/456", "")
+		require.NoError(t, err)
+		assert.Equal(t, "https://o1.ingest.sentry.io/api/456/store/", reporter.storeURL)
+		assert.Contains(t, reporter.auth, "sentry_key=abc123")
+	})
+
+	t.Run("Keeps the path prefix of self-hosted servers", func(t *testing.T) {
+		reporter, err := NewSentryReporter("http://key@localhost:9000/sentry/7", "")
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:9000/sentry/api/7/store/", reporter.storeURL)
+	})
+
+	t.Run("Rejects DSNs without a key or project", func(t *testing.T) {
+		_, err := NewSentryReporter("https://o1.ingest.sentry.io/456", "")
+		assert.Error(t, err)
+
+		_, err = NewSentryReporter("https://key@o1.ingest.sentry.io/", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestSentryReporter_Report(t *testing.T) {
+	received := make(chan sentryEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/store/", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("X-Sentry-Auth"), "Sentry sentry_version=7"))
+
+		var event sentryEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	reporter, err := NewSentryReporter(strings.Replace(server.URL, "://", "://key@", 1)+"/42", "staging")
+	require.NoError(t, err)
+
+	userID, tenantID := int64(7), int64(3)
+	reporter.Report(context.Background(), Event{
+		Err:       errors.New("runtime error: index out of range"),
+		Stack:     []byte("goroutine 1 [running]:"),
+		RequestID: "host/abc-000001",
+		Method:    http.MethodGet,
+		URL:       "/api/v1/orders",
+		UserID:    &userID,
+		TenantID:  &tenantID,
+	})
+
+	select {
+	case event := <-received:
+		assert.Len(t, event.EventID, 32)
+		assert.Equal(t, "error", event.Level)
+		assert.Equal(t, "staging", event.Environment)
+		assert.Equal(t, "runtime error: index out of range", event.Message)
+		assert.Equal(t, &sentryRequest{Method: http.MethodGet, URL: "/api/v1/orders"}, event.Request)
+		assert.Equal(t, &sentryUser{ID: "7"}, event.User)
+		assert.Equal(t, map[string]string{"request_id": "host/abc-000001", "tenant_id": "3"}, event.Tags)
+		assert.Equal(t, "goroutine 1 [running]:", event.Extra["stack"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not reported")
+	}
+}
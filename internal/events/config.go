@@ -0,0 +1,86 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// Brokers events can be published to
+const (
+	BrokerNATS  = "nats"
+	BrokerKafka = "kafka"
+)
+
+const (
+	// Default values
+	defaultNATSURL       = "nats://localhost:4222"
+	defaultSubjectPrefix = "silocore"
+	defaultKafkaTopic    = "silocore.events"
+
+	// Environment variable names
+	envEventBroker        = "EVENT_BROKER"
+	envNATSURL            = "NATS_URL"
+	envEventSubjectPrefix = "EVENT_SUBJECT_PREFIX"
+	envKafkaRESTURL       = "KAFKA_REST_URL"
+	envKafkaTopic         = "KAFKA_TOPIC"
+)
+
+// Config holds configuration for publishing events
+type Config struct {
+	// Broker is BrokerNATS, BrokerKafka, or empty to log events instead
+	Broker        string
+	NATSURL       string
+	SubjectPrefix string
+	KafkaRESTURL  string
+	KafkaTopic    string
+}
+
+// LoadConfig loads event publishing configuration from environment variables
+func LoadConfig() (Config, error) {
+	config := Config{
+		Broker:        os.Getenv(envEventBroker),
+		NATSURL:       os.Getenv(envNATSURL),
+		SubjectPrefix: os.Getenv(envEventSubjectPrefix),
+		KafkaRESTURL:  os.Getenv(envKafkaRESTURL),
+		KafkaTopic:    os.Getenv(envKafkaTopic),
+	}
+
+	if config.NATSURL == "" {
+		config.NATSURL = defaultNATSURL
+	}
+	if config.SubjectPrefix == "" {
+		config.SubjectPrefix = defaultSubjectPrefix
+	}
+	if config.KafkaTopic == "" {
+		config.KafkaTopic = defaultKafkaTopic
+	}
+
+	switch config.Broker {
+	case "", BrokerNATS:
+	case BrokerKafka:
+		if config.KafkaRESTURL == "" {
+			return Config{}, fmt.Errorf("KAFKA_REST_URL is required when EVENT_BROKER is kafka")
+		}
+	default:
+		return Config{}, fmt.Errorf("invalid EVENT_BROKER value %q: must be nats or kafka", config.Broker)
+	}
+
+	return config, nil
+}
+
+// New creates the Publisher selected by the configuration.
+// Events are logged rather than published when EVENT_BROKER is not set.
+func New(config Config) (Publisher, error) {
+	switch config.Broker {
+	case BrokerNATS:
+		log.Printf("[INFO] Publishing events to NATS at %s", config.NATSURL)
+		return NewNATSPublisher(config.NATSURL, config.SubjectPrefix)
+	case BrokerKafka:
+		log.Printf("[INFO] Publishing events to Kafka topic %s through %s", config.KafkaTopic, config.KafkaRESTURL)
+		return NewKafkaPublisher(config.KafkaRESTURL, config.KafkaTopic), nil
+	default:
+		log.Printf("[INFO] EVENT_BROKER is not set, events will be logged instead of published")
+		return LogPublisher{}, nil
+	}
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Event is a domain event, such as an order being created, published for
+// downstream systems
+type Event struct {
+	// ID identifies the event, so consumers can detect duplicates
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	TenantID int64  `json:"tenant_id"`
+	// Key identifies the entity the event is about. Events with the same key are
+	// kept in order by brokers that partition events.
+	Key         string      `json:"key"`
+	ActorUserID *int64      `json:"actor_user_id,omitempty"`
+	OccurredAt  time.Time   `json:"occurred_at"`
+	Data        interface{} `json:"data"`
+}
+
+// NewEvent creates an event with a new ID
+func NewEvent(eventType string, tenantID int64, key string, data interface{}) (Event, error) {
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return Event{}, fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	return Event{
+		ID:         "evt_" + hex.EncodeToString(idBytes),
+		Type:       eventType,
+		TenantID:   tenantID,
+		Key:        key,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}, nil
+}
+
+// Publisher publishes events to a message broker
+type Publisher interface {
+	// Publish sends an event to the broker
+	Publish(ctx context.Context, event Event) error
+
+	// Close releases the publisher's connections
+	Close() error
+}
+
+// LogPublisher writes events to the log instead of publishing them. It is used
+// in development when no broker is configured.
+type LogPublisher struct{}
+
+// Ensure LogPublisher implements Publisher
+var _ Publisher = LogPublisher{}
+
+// Publish logs the event
+func (LogPublisher) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+	log.Printf("[DEBUG] Event %s: %s", event.Type, payload)
+	return nil
+}
+
+// Close does nothing
+func (LogPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATSPublisher(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// The server greets the client, then reports the protocol lines it receives
+	received := make(chan string, 4)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "PUB ") {
+				size, _ := strconv.Atoi(line[strings.LastIndex(line, " ")+1:])
+				payload := make([]byte, size+2)
+				io.ReadFull(reader, payload)
+				line += " " + string(payload[:size])
+			}
+			received <- line
+		}
+	}()
+
+	publisher, err := NewNATSPublisher("nats://user:secret@"+listener.Addr().String(), "silocore")
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	event, err := NewEvent("order.created", 42, "7", map[string]int{"order_id": 7})
+	require.NoError(t, err)
+
+	// Execute
+	err = publisher.Publish(context.Background(), event)
+
+	// Assert
+	require.NoError(t, err)
+	connect := <-received
+	assert.True(t, strings.HasPrefix(connect, "CONNECT "))
+	assert.Contains(t, connect, `"user":"user"`)
+	assert.Contains(t, connect, `"pass":"secret"`)
+
+	pub := <-received
+	payload, _ := json.Marshal(event)
+	assert.Equal(t, "PUB silocore.order.created "+strconv.Itoa(len(payload))+" "+string(payload), pub)
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	event, err := NewEvent("order.deleted", 42, "7", nil)
+	require.NoError(t, err)
+
+	t.Run("Produced", func(t *testing.T) {
+		var path, contentType string
+		var body kafkaRecords
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			contentType = r.Header.Get("Content-Type")
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"offsets":[{"partition":0,"offset":12}]}`))
+		}))
+		defer server.Close()
+
+		// Execute
+		err := NewKafkaPublisher(server.URL, "silocore.events").Publish(context.Background(), event)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/topics/silocore.events", path)
+		assert.Equal(t, kafkaRESTContentType, contentType)
+		require.Len(t, body.Records, 1)
+		assert.Equal(t, "7", body.Records[0].Key)
+		assert.Equal(t, event.ID, body.Records[0].Value.ID)
+	})
+
+	t.Run("Record failed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"offsets":[{"error_code":50003,"error":"broker unavailable"}]}`))
+		}))
+		defer server.Close()
+
+		// Execute
+		err := NewKafkaPublisher(server.URL, "silocore.events").Publish(context.Background(), event)
+
+		// Assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "broker unavailable")
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		config, err := LoadConfig()
+
+		require.NoError(t, err)
+		assert.Empty(t, config.Broker)
+		assert.Equal(t, defaultNATSURL, config.NATSURL)
+		assert.Equal(t, defaultSubjectPrefix, config.SubjectPrefix)
+	})
+
+	t.Run("Kafka without REST URL", func(t *testing.T) {
+		t.Setenv(envEventBroker, BrokerKafka)
+
+		_, err := LoadConfig()
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown broker", func(t *testing.T) {
+		t.Setenv(envEventBroker, "rabbitmq")
+
+		_, err := LoadConfig()
+
+		assert.Error(t, err)
+	})
+}
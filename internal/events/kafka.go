@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// kafkaRESTContentType is the content type of JSON records sent to a Kafka REST Proxy
+const kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaPublisher publishes events to a Kafka topic through a Kafka REST Proxy.
+// Events are keyed by their Key, so the events of an entity stay in order in
+// their partition.
+type KafkaPublisher struct {
+	endpoint string
+	client   *http.Client
+}
+
+// Ensure KafkaPublisher implements Publisher
+var _ Publisher = (*KafkaPublisher)(nil)
+
+// kafkaRecords is the body of a request producing records
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+// kafkaRecord is a record to produce
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+// kafkaProduceResponse is the response to a request producing records. Records
+// that failed have an error code.
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaPublisher creates a new KafkaPublisher producing to topic through the
+// REST Proxy at restURL, e.g. http://localhost:8082
+func NewKafkaPublisher(restURL string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		endpoint: strings.TrimSuffix(restURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish produces an event to the topic
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: event.Key, Value: event}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka request: %w", err)
+	}
+	req.Header.Set("Content-Type", kafkaRESTContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish event %s to Kafka: %w", event.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to publish event %s to Kafka: status %d: %s", event.ID, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("failed to read Kafka response for event %s: %w", event.ID, err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("failed to publish event %s to Kafka: %s (error code %d)", event.ID, offset.Error, *offset.ErrorCode)
+		}
+	}
+
+	return nil
+}
+
+// Close does nothing; requests don't hold connections open
+func (p *KafkaPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,269 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+const (
+	// DefaultOutboxInterval is how often the dispatcher looks for pending events
+	DefaultOutboxInterval = time.Second
+
+	// DefaultOutboxRetention is how long published events are kept
+	DefaultOutboxRetention = 7 * 24 * time.Hour
+
+	// outboxBatchSize is the most events published in one run
+	outboxBatchSize = 100
+
+	// outboxPublishTimeout is how long publishing an event can take
+	outboxPublishTimeout = 10 * time.Second
+
+	// Delays before retrying an event that failed to publish, doubling with
+	// each attempt up to the maximum
+	outboxRetryDelay    = 5 * time.Second
+	outboxMaxRetryDelay = time.Hour
+)
+
+// Outbox writes events to the event_outbox table in the transaction of the
+// change they describe, for OutboxDispatcher to publish once it commits
+type Outbox struct {
+	txManager transaction.TxManager
+}
+
+// NewOutbox creates a new Outbox
+func NewOutbox(db *sql.DB) *Outbox {
+	return &Outbox{
+		txManager: transaction.NewManager(db),
+	}
+}
+
+// WithTxManager runs the Outbox's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (o *Outbox) WithTxManager(txManager transaction.TxManager) *Outbox {
+	o.txManager = txManager
+	return o
+}
+
+// Enqueue adds an event to the outbox in the transaction in the context. The
+// event's ID is its deduplication key: adding an event twice adds it once.
+func (o *Outbox) Enqueue(ctx context.Context, event Event) error {
+	tx, err := o.txManager.GetTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO event_outbox (event_id, tenant_id, event_type, event_key, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO NOTHING
+	`, event.ID, event.TenantID, event.Type, event.Key, payload)
+	if err != nil {
+		return fmt.Errorf("failed to add event %s to the outbox: %w", event.ID, err)
+	}
+	return nil
+}
+
+// OutboxDispatcher publishes the events in the outbox. Events are marked
+// published only after the publisher accepts them, so an event is published at
+// least once; it is published again if the dispatcher stops in between. The
+// events of an entity, those with the same tenant and key, are published in
+// order: an event waits while an earlier one is pending. Instances running
+// dispatchers side by side each publish different events.
+type OutboxDispatcher struct {
+	db        *sql.DB
+	publisher Publisher
+	now       func() time.Time
+}
+
+// NewOutboxDispatcher creates a new OutboxDispatcher publishing with publisher
+func NewOutboxDispatcher(db *sql.DB, publisher Publisher) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		db:        db,
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// outboxEvent is an event read back from the outbox, keeping its data as it
+// was encoded
+type outboxEvent struct {
+	Event
+	Data json.RawMessage `json:"data"`
+}
+
+// DispatchPending publishes a batch of pending events, oldest first, and returns
+// how many were published. An event that fails to publish is retried later with
+// exponential backoff, holding back the later events of its entity.
+func (d *OutboxDispatcher) DispatchPending(ctx context.Context) (int, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the batch, skipping events another instance is publishing and events
+	// waiting for an earlier event of their entity that is due later
+	rows, err := tx.QueryContext(ctx, `
+		SELECT o.id, o.tenant_id, o.event_key, o.payload, o.attempts
+		FROM event_outbox o
+		WHERE o.published_at IS NULL AND o.available_at <= $1
+			AND NOT EXISTS (
+				SELECT 1 FROM event_outbox earlier
+				WHERE earlier.tenant_id = o.tenant_id AND earlier.event_key = o.event_key
+					AND earlier.published_at IS NULL AND earlier.id < o.id AND earlier.available_at > $1
+			)
+		ORDER BY o.id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, d.now(), outboxBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query pending events: %w", err)
+	}
+
+	type pendingEvent struct {
+		id       int64
+		tenantID int64
+		key      string
+		payload  []byte
+		attempts int
+	}
+	var pending []pendingEvent
+	for rows.Next() {
+		var p pendingEvent
+		if err := rows.Scan(&p.id, &p.tenantID, &p.key, &p.payload, &p.attempts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan pending event: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to query pending events: %w", err)
+	}
+
+	// Entities whose event failed in this batch, whose later events must wait
+	type entity struct {
+		tenantID int64
+		key      string
+	}
+	held := make(map[entity]bool)
+
+	published := 0
+	for _, p := range pending {
+		if held[entity{p.tenantID, p.key}] {
+			continue
+		}
+
+		publishErr := d.publish(ctx, p.payload)
+		now := d.now()
+		if publishErr == nil {
+			_, err = tx.ExecContext(ctx,
+				"UPDATE event_outbox SET published_at = $1, attempts = attempts + 1, last_error = '' WHERE id = $2",
+				now, p.id)
+			published++
+		} else {
+			log.Printf("[WARN] Failed to publish outbox event %d (attempt %d): %v", p.id, p.attempts+1, publishErr)
+			held[entity{p.tenantID, p.key}] = true
+			_, err = tx.ExecContext(ctx,
+				"UPDATE event_outbox SET attempts = attempts + 1, last_error = $1, available_at = $2 WHERE id = $3",
+				publishErr.Error(), now.Add(outboxBackoff(p.attempts+1)), p.id)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to update outbox event %d: %w", p.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return published, nil
+}
+
+// PruneBefore deletes the events published before the given time and returns
+// how many were deleted
+func (d *OutboxDispatcher) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := d.db.ExecContext(ctx, "DELETE FROM event_outbox WHERE published_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// publish decodes an event from the outbox and publishes it
+func (d *OutboxDispatcher) publish(ctx context.Context, payload []byte) error {
+	var stored outboxEvent
+	if err := json.Unmarshal(payload, &stored); err != nil {
+		return fmt.Errorf("failed to decode event: %w", err)
+	}
+	event := stored.Event
+	event.Data = stored.Data
+
+	ctx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	defer cancel()
+	return d.publisher.Publish(ctx, event)
+}
+
+// outboxBackoff returns the delay before retrying an event that failed to
+// publish attempts times
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxRetryDelay
+	for i := 1; i < attempts && delay < outboxMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxRetryDelay)
+}
+
+// StartOutboxDispatcher starts a background job publishing pending events every
+// interval, draining the outbox when it has more than a batch, and pruning
+// events published longer ago than retention. The job stops when ctx is done.
+func StartOutboxDispatcher(ctx context.Context, dispatcher *OutboxDispatcher, interval time.Duration, retention time.Duration) {
+	log.Printf("[INFO] Starting outbox dispatcher every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		lastPrune := time.Time{}
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("[INFO] Stopping outbox dispatcher")
+				return
+			case <-ticker.C:
+				for {
+					published, err := dispatcher.DispatchPending(ctx)
+					if err != nil {
+						if !errors.Is(err, context.Canceled) {
+							log.Printf("[ERROR] Failed to dispatch outbox events: %v", err)
+						}
+						break
+					}
+					if published < outboxBatchSize {
+						break
+					}
+				}
+
+				if time.Since(lastPrune) >= time.Hour {
+					lastPrune = time.Now()
+					if pruned, err := dispatcher.PruneBefore(ctx, lastPrune.Add(-retention)); err != nil {
+						log.Printf("[ERROR] Failed to prune outbox: %v", err)
+					} else if pruned > 0 {
+						log.Printf("[INFO] Pruned %d published events from the outbox", pruned)
+					}
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// failingPublisher records the events it is given and fails to publish those
+// in fail
+type failingPublisher struct {
+	published []Event
+	fail      map[string]bool
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event Event) error {
+	if p.fail[event.ID] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func (p *failingPublisher) Close() error {
+	return nil
+}
+
+func TestOutboxEnqueue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	event, err := NewEvent("order.created", 42, "7", map[string]int{"order_id": 7})
+	require.NoError(t, err)
+	payload, _ := json.Marshal(event)
+
+	t.Run("In a transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		ctx := context.WithValue(context.Background(), transaction.TxKey, tx)
+
+		mock.ExpectExec("INSERT INTO event_outbox (.+) ON CONFLICT \\(event_id\\) DO NOTHING").
+			WithArgs(event.ID, int64(42), "order.created", "7", payload).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, NewOutbox(db).Enqueue(ctx, event))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Without a transaction", func(t *testing.T) {
+		err := NewOutbox(db).Enqueue(context.Background(), event)
+		assert.Error(t, err)
+	})
+}
+
+func TestOutboxDispatchPending(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	published, err := NewEvent("order.created", 42, "7", map[string]int{"order_id": 7})
+	require.NoError(t, err)
+	failed, err := NewEvent("order.updated", 42, "8", nil)
+	require.NoError(t, err)
+	held, err := NewEvent("order.deleted", 42, "8", nil)
+	require.NoError(t, err)
+	publishedPayload, _ := json.Marshal(published)
+	failedPayload, _ := json.Marshal(failed)
+	heldPayload, _ := json.Marshal(held)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	publisher := &failingPublisher{fail: map[string]bool{failed.ID: true}}
+	dispatcher := NewOutboxDispatcher(db, publisher)
+	dispatcher.now = func() time.Time { return now }
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.id, (.+) FROM event_outbox o (.+) FOR UPDATE SKIP LOCKED").
+		WithArgs(now, outboxBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "event_key", "payload", "attempts"}).
+			AddRow(1, 42, "7", publishedPayload, 0).
+			AddRow(2, 42, "8", failedPayload, 2).
+			AddRow(3, 42, "8", heldPayload, 0))
+	mock.ExpectExec("UPDATE event_outbox SET published_at = \\$1").
+		WithArgs(now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE event_outbox SET attempts = attempts \\+ 1, last_error = \\$1, available_at = \\$2").
+		WithArgs("broker unavailable", now.Add(4*outboxRetryDelay), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute test
+	count, err := dispatcher.DispatchPending(context.Background())
+
+	// Verify results: the event is published with its data as it was written,
+	// and the event after the failed one of the same order waits for it
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, publisher.published, 1)
+	assert.Equal(t, published.ID, publisher.published[0].ID)
+	data, _ := json.Marshal(publisher.published[0].Data)
+	assert.JSONEq(t, `{"order_id": 7}`, string(data))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, outboxRetryDelay, outboxBackoff(1))
+	assert.Equal(t, 2*outboxRetryDelay, outboxBackoff(2))
+	assert.Equal(t, outboxMaxRetryDelay, outboxBackoff(30))
+}
@@ -0,0 +1,210 @@
+// Package apierror writes the errors of JSON API routes in a uniform envelope:
+//
+//	{"error": {"code": "order_not_found", "message": "Order not found", "request_id": "..."}}
+//
+// The code is stable for clients to branch on, the message is for people, and
+// details, when present, describe what was wrong with the request.
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Error codes shared by all routes. Routes add their own, more specific codes,
+// such as order_not_found, for the errors of their services.
+const (
+	CodeBadRequest      = "bad_request"
+	CodeInvalidInput    = "invalid_input"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeTenantRequired  = "tenant_required"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodePayloadTooLarge = "payload_too_large"
+	CodeQuotaExceeded   = "quota_exceeded"
+	CodePlanRequired    = "plan_required"
+	CodeRateLimited     = "rate_limited"
+	CodeInternal        = "internal_error"
+	CodeUnavailable     = "service_unavailable"
+	CodeTimeout         = "request_timeout"
+)
+
+// Error is an error response of the API
+type Error struct {
+	// Status is the HTTP status of the response
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// Details describe what was wrong with the request, e.g. its invalid fields
+	Details interface{} `json:"details,omitempty"`
+
+	// RequestID identifies the request in the server's logs and traces
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Error returns the error's message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of the error with details
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// New creates an error with a status, code and message
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest creates a 400 Bad Request error for malformed requests
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// InvalidInput creates a 400 Bad Request error for well-formed requests whose
+// values a service rejected
+func InvalidInput(message string) *Error {
+	return New(http.StatusBadRequest, CodeInvalidInput, message)
+}
+
+// InvalidFields creates a 400 Bad Request error for requests with invalid
+// fields, listed in its details
+func InvalidFields(fields interface{}) *Error {
+	return InvalidInput("Request has invalid fields").WithDetails(fields)
+}
+
+// Unauthorized creates a 401 Unauthorized error
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden creates a 403 Forbidden error
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+// TenantRequired creates the 403 Forbidden error of requests without a tenant
+func TenantRequired() *Error {
+	return New(http.StatusForbidden, CodeTenantRequired, "Tenant context required")
+}
+
+// NotFound creates a 404 Not Found error
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict creates a 409 Conflict error
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// Internal creates a 500 Internal Server Error error. Its message says what
+// failed, never why, which is only logged.
+func Internal(message string) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, message)
+}
+
+// Timeout creates a 503 Service Unavailable error for requests whose deadline
+// passed before they were served
+func Timeout() *Error {
+	return New(http.StatusServiceUnavailable, CodeTimeout, "Request timed out")
+}
+
+// CodeForStatus returns the shared code of errors with a status, for errors
+// that only have a status and a message
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusPaymentRequired:
+		return CodeQuotaExceeded
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Mapping maps a sentinel error of a service to the error the API responds with
+type Mapping struct {
+	Err    error
+	Status int
+	Code   string
+
+	// Message is the response's message. If empty, it is the service error's
+	// message, for errors that say what was wrong, such as invalid input.
+	Message string
+}
+
+// Map returns the error of the first mapping whose sentinel err wraps, err
+// itself if it is an *Error, or nil if none matches
+func Map(err error, mappings []Mapping) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	for _, mapping := range mappings {
+		if errors.Is(err, mapping.Err) {
+			message := mapping.Message
+			if message == "" {
+				message = err.Error()
+			}
+			return New(mapping.Status, mapping.Code, message)
+		}
+	}
+	return nil
+}
+
+// envelope is the body of error responses
+type envelope struct {
+	Error *Error `json:"error"`
+}
+
+// Write writes an error response with the request's ID. Internal errors of
+// requests whose deadline has passed, typically a query cancelled with the
+// request's context, are written as Timeout errors instead.
+func Write(w http.ResponseWriter, r *http.Request, err *Error) {
+	if err.Status == http.StatusInternalServerError && r.Context().Err() == context.DeadlineExceeded {
+		err = Timeout()
+	}
+
+	body := *err
+	body.RequestID = middleware.GetReqID(r.Context())
+
+	header := w.Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Del("Content-Length")
+	w.WriteHeader(err.Status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if encodeErr := json.NewEncoder(w).Encode(envelope{Error: &body}); encodeErr != nil {
+		log.Printf("[ERROR] Failed to encode error response: %v", encodeErr)
+	}
+}
@@ -0,0 +1,98 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errNotFound = errors.New("widget not found")
+	errInvalid  = errors.New("invalid input")
+)
+
+var widgetErrors = []Mapping{
+	{Err: errNotFound, Status: http.StatusNotFound, Code: "widget_not_found", Message: "Widget not found"},
+	{Err: errInvalid, Status: http.StatusBadRequest, Code: CodeInvalidInput},
+}
+
+func TestMap(t *testing.T) {
+	t.Run("Maps wrapped sentinel errors", func(t *testing.T) {
+		apiErr := Map(fmt.Errorf("loading: %w", errNotFound), widgetErrors)
+		require.NotNil(t, apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.Status)
+		assert.Equal(t, "widget_not_found", apiErr.Code)
+		assert.Equal(t, "Widget not found", apiErr.Message)
+	})
+
+	t.Run("Uses the error's message when the mapping has none", func(t *testing.T) {
+		apiErr := Map(fmt.Errorf("%w: name is required", errInvalid), widgetErrors)
+		require.NotNil(t, apiErr)
+		assert.Equal(t, CodeInvalidInput, apiErr.Code)
+		assert.Equal(t, "invalid input: name is required", apiErr.Message)
+	})
+
+	t.Run("Passes API errors through", func(t *testing.T) {
+		conflict := Conflict("Widget was modified")
+		assert.Same(t, conflict, Map(fmt.Errorf("saving: %w", conflict), widgetErrors))
+	})
+
+	t.Run("Returns nil for unexpected errors", func(t *testing.T) {
+		assert.Nil(t, Map(errors.New("connection refused"), widgetErrors))
+	})
+}
+
+func TestWrite(t *testing.T) {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, InvalidInput("Invalid widget").WithDetails(map[string]string{"name": "is required"}))
+	})
+	handler = middleware.RequestID(handler)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/widgets", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body struct {
+		Error struct {
+			Code      string            `json:"code"`
+			Message   string            `json:"message"`
+			Details   map[string]string `json:"details"`
+			RequestID string            `json:"request_id"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, CodeInvalidInput, body.Error.Code)
+	assert.Equal(t, "Invalid widget", body.Error.Message)
+	assert.Equal(t, map[string]string{"name": "is required"}, body.Error.Details)
+	assert.NotEmpty(t, body.Error.RequestID)
+}
+
+func TestWrite_DeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil).WithContext(ctx)
+	Write(rec, req, Internal("Failed to list widgets"))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"request_timeout"`)
+}
+
+func TestCodeForStatus(t *testing.T) {
+	assert.Equal(t, CodeNotFound, CodeForStatus(http.StatusNotFound))
+	assert.Equal(t, CodeRateLimited, CodeForStatus(http.StatusTooManyRequests))
+	assert.Equal(t, CodeInternal, CodeForStatus(http.StatusBadGateway))
+	assert.Equal(t, CodeBadRequest, CodeForStatus(http.StatusUnprocessableEntity))
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// JSONErrors middleware rewrites plain text error responses, such as those of
+// http.Error in middleware, in the JSON error envelope of apierror, with the
+// code of their status, so API clients can parse every response. Other
+// responses are passed through as they are written.
+func JSONErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jw := &jsonErrorWriter{ResponseWriter: w}
+		next.ServeHTTP(jw, r)
+		if !jw.buffering {
+			return
+		}
+
+		message := strings.TrimSpace(jw.body.String())
+		apierror.Write(w, r, apierror.New(jw.status, apierror.CodeForStatus(jw.status), message))
+	})
+}
+
+// jsonErrorWriter holds back the body of plain text error responses, which are
+// written as JSON once complete
+type jsonErrorWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	buffering   bool
+	status      int
+	body        bytes.Buffer
+}
+
+// WriteHeader starts holding back the body of plain text error responses, and
+// writes the status of others
+func (w *jsonErrorWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if status >= http.StatusBadRequest && mediaType == "text/plain" {
+		w.buffering = true
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write holds back or writes a part of the body
+func (w *jsonErrorWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends streamed responses, such as exports, to the client as they are
+// written
+func (w *jsonErrorWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// DeprecatedAlias middleware marks the responses of routes kept as aliases of
+// newer ones as deprecated, with a Deprecation header and a Link header to the
+// successor route: the request path with oldPrefix replaced by newPrefix
+func DeprecatedAlias(oldPrefix, newPrefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rest, ok := strings.CutPrefix(r.URL.Path, oldPrefix); ok {
+				successor := newPrefix + rest
+				if r.URL.RawQuery != "" {
+					successor += "?" + r.URL.RawQuery
+				}
+				w.Header().Set("Deprecation", "true")
+				w.Header().Add("Link", "<"+successor+">; rel=\"successor-version\"")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// apiKeyHeader carries an API key as an alternative to the Authorization header
+const apiKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator looks up the API key presented with a request
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*tenantservice.APIKey, error)
+}
+
+// apiKeyFromRequest extracts an API key from the X-API-Key header or a bearer
+// token with the API key prefix. It returns an empty string if neither is set.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(token, tenantservice.APIKeyPrefix) {
+		return token
+	}
+
+	return ""
+}
+
+// APIKeyMiddleware creates middleware that authenticates requests presenting a
+// tenant API key. The request acts as the user who created the key, in the key's
+// tenant, limited to the key's scopes; RoleMiddleware still enforces membership
+// and tenant status. Requests without an API key are passed on to AuthMiddleware.
+func APIKeyMiddleware(authenticator APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := apiKeyFromRequest(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			apiKey, err := authenticator.AuthenticateAPIKey(r.Context(), key)
+			if err != nil {
+				if errors.Is(err, tenantservice.ErrInvalidAPIKey) {
+					log.Printf("[WARN] Invalid API key: %s %s", r.Method, r.URL.Path)
+					http.Error(w, "Invalid or expired API key", http.StatusUnauthorized)
+					return
+				}
+				log.Printf("[ERROR] Failed to authenticate API key: %s %s - %v", r.Method, r.URL.Path, err)
+				http.Error(w, "Failed to authenticate API key", http.StatusInternalServerError)
+				return
+			}
+
+			// A key is only valid for its own tenant's subdomain or path
+			if resolvedTenantID, err := authctx.GetResolvedTenantID(r.Context()); err == nil && resolvedTenantID != apiKey.TenantID {
+				log.Printf("[WARN] API key %d for tenant %d used against tenant %d: %s %s", apiKey.ID, apiKey.TenantID, resolvedTenantID, r.Method, r.URL.Path)
+				http.Error(w, "API key is not valid for this tenant", http.StatusForbidden)
+				return
+			}
+
+			ctx := authctx.WithUserID(r.Context(), apiKey.CreatedBy)
+			ctx = authctx.WithTenantID(ctx, &apiKey.TenantID)
+			ctx = authctx.WithAPIKeyID(ctx, apiKey.ID)
+			ctx = authctx.WithScopes(ctx, apiKey.Scopes)
+
+			log.Printf("[DEBUG] API key %d authenticated for tenant %d: %s", apiKey.ID, apiKey.TenantID, r.URL.Path)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireSession middleware rejects requests authenticated by an API key, for
+// routes such as tenant switching and API key management that only make sense
+// for a signed-in user
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if keyID, err := authctx.GetAPIKeyID(r.Context()); err == nil {
+			log.Printf("[WARN] API key %d used for a session-only route: %s %s", keyID, r.Method, r.URL.Path)
+			http.Error(w, "This route can't be used with an API key", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
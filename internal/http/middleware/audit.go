@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+const (
+	// maxAuditBodyBytes is the largest request body whose fields are recorded
+	// in the audit log
+	maxAuditBodyBytes = 16 << 10
+
+	// redacted replaces the values of credential fields
+	redacted = "[REDACTED]"
+)
+
+// credentialFields are substrings of the names of request fields whose values
+// are never recorded, compared without case, underscores and dashes
+var credentialFields = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"apikey",
+	"authorization",
+	"credential",
+	"signature",
+	"privatekey",
+}
+
+// auditChangesKey is the context key of the changes of an audited request
+type auditChangesKey struct{}
+
+// auditChanges holds the changes a handler reports for its request's audit entry
+type auditChanges struct {
+	changes map[string]interface{}
+}
+
+// SetAuditChanges sets the summary of the changes a request made, such as the
+// previous and new values of an order's fields, recorded by AuditRequests with
+// the request. It does nothing for requests that aren't audited.
+func SetAuditChanges(r *http.Request, changes map[string]interface{}) {
+	if holder, ok := r.Context().Value(auditChangesKey{}).(*auditChanges); ok {
+		holder.changes = changes
+	}
+}
+
+// auditBody copies the start of a request body as the handler reads it
+type auditBody struct {
+	io.ReadCloser
+	copy      bytes.Buffer
+	truncated bool
+}
+
+// Read reads from the body, copying up to maxAuditBodyBytes
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := maxAuditBodyBytes - b.copy.Len(); n > room {
+		b.copy.Write(p[:room])
+		b.truncated = true
+	} else {
+		b.copy.Write(p[:n])
+	}
+	return n, err
+}
+
+// AuditRequests creates middleware recording every POST, PUT, PATCH and DELETE
+// request made in a tenant context in the tenant's audit log, whatever its
+// outcome: the actor, route, status, latency, the fields of JSON and form
+// bodies, and the changes set by the handler with SetAuditChanges. The values
+// of credential fields, such as passwords and tokens, are redacted. It must run
+// after authentication, which sets the tenant. Failing to record a request is
+// only logged.
+func AuditRequests(recorder auditservice.AuditRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			holder := &auditChanges{}
+			r = r.WithContext(context.WithValue(r.Context(), auditChangesKey{}, holder))
+
+			// JSON and form bodies are copied as the handler reads them. Other
+			// bodies, such as uploads, aren't recorded.
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			var body *auditBody
+			if (mediaType == "application/json" || mediaType == "application/x-www-form-urlencoded") && r.Body != nil && r.Body != http.NoBody {
+				body = &auditBody{ReadCloser: r.Body}
+				r.Body = body
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			ctx := r.Context()
+			tenantID, err := authctx.GetTenantID(ctx)
+			if err != nil || tenantID == nil {
+				return
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := r.URL.Path
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			details := map[string]interface{}{
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"status":     status,
+				"latency_ms": time.Since(start).Milliseconds(),
+			}
+			if requestID := middleware.GetReqID(ctx); requestID != "" {
+				details["request_id"] = requestID
+			}
+			if fields := auditFields(r, mediaType, body); fields != nil {
+				details["request"] = fields
+			}
+			if holder.changes != nil {
+				details["changes"] = redact(holder.changes)
+			}
+
+			err = recorder.Record(ctx, auditservice.AuditEntry{
+				TenantID:     *tenantID,
+				Action:       auditservice.ActionRequest,
+				ResourceType: auditservice.ResourceRoute,
+				ResourceID:   r.Method + " " + route,
+				Details:      details,
+			})
+			if err != nil {
+				log.Printf("[ERROR] Failed to record %s %s for tenant ID %d: %v", r.Method, r.URL.Path, *tenantID, err)
+			}
+		})
+	}
+}
+
+// auditFields returns the redacted fields of a request's JSON or form body, or
+// nil if it had none or it was too large to record
+func auditFields(r *http.Request, mediaType string, body *auditBody) interface{} {
+	// Forms read by the CSRF middleware were parsed before they could be copied
+	form := r.PostForm
+	if body != nil && body.copy.Len() > 0 {
+		if body.truncated {
+			return nil
+		}
+		if mediaType == "application/json" {
+			var fields interface{}
+			if err := json.Unmarshal(body.copy.Bytes(), &fields); err != nil {
+				return nil
+			}
+			return redact(fields)
+		}
+		var err error
+		if form, err = url.ParseQuery(body.copy.String()); err != nil {
+			return nil
+		}
+	}
+
+	if len(form) == 0 {
+		return nil
+	}
+	fields := make(map[string]interface{}, len(form))
+	for name, values := range form {
+		if len(values) == 1 {
+			fields[name] = values[0]
+		} else {
+			fields[name] = values
+		}
+	}
+	return redact(fields)
+}
+
+// redact returns a copy of a decoded JSON value with the values of credential
+// fields replaced, at any depth
+func redact(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		redactedValue := make(map[string]interface{}, len(value))
+		for name, field := range value {
+			if isCredentialField(name) {
+				redactedValue[name] = redacted
+			} else {
+				redactedValue[name] = redact(field)
+			}
+		}
+		return redactedValue
+	case []interface{}:
+		redactedValue := make([]interface{}, len(value))
+		for i, item := range value {
+			redactedValue[i] = redact(item)
+		}
+		return redactedValue
+	default:
+		return value
+	}
+}
+
+// isCredentialField reports whether a field's value is a credential
+func isCredentialField(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	for _, credential := range credentialFields {
+		if strings.Contains(name, credential) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"strconv"
@@ -18,31 +19,41 @@ type JWTService interface {
 	ValidateToken(tokenString string) (*jwt.CustomClaims, error)
 }
 
+// TokenFromRequest extracts the access token from the Authorization header,
+// falling back to the auth_token cookie. It returns an empty string if neither is set.
+func TokenFromRequest(r *http.Request) string {
+	// First try to extract token from Authorization header
+	authHeader := r.Header.Get("Authorization")
+	if authHeader != "" {
+		// Check if the header has the Bearer prefix
+		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			log.Printf("[DEBUG] Token extracted from Authorization header: %s", r.URL.Path)
+			return parts[1]
+		}
+	}
+
+	// If no token in header, try to extract from cookie
+	cookie, err := r.Cookie("auth_token")
+	if err == nil && cookie.Value != "" {
+		log.Printf("[DEBUG] Token extracted from cookie: %s", r.URL.Path)
+		return cookie.Value
+	}
+
+	return ""
+}
+
 // AuthMiddleware creates middleware for JWT authentication
 func AuthMiddleware(jwtService JWTService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			var tokenString string
-
-			// First try to extract token from Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader != "" {
-				// Check if the header has the Bearer prefix
-				parts := strings.Split(authHeader, " ")
-				if len(parts) == 2 && parts[0] == "Bearer" {
-					tokenString = parts[1]
-					log.Printf("[DEBUG] Token extracted from Authorization header: %s", r.URL.Path)
-				}
+			// Requests already authenticated by APIKeyMiddleware carry no access token
+			if _, err := authctx.GetAPIKeyID(r.Context()); err == nil {
+				next.ServeHTTP(w, r)
+				return
 			}
 
-			// If no token in header, try to extract from cookie
-			if tokenString == "" {
-				cookie, err := r.Cookie("auth_token")
-				if err == nil && cookie.Value != "" {
-					tokenString = cookie.Value
-					log.Printf("[DEBUG] Token extracted from cookie: %s", r.URL.Path)
-				}
-			}
+			tokenString := TokenFromRequest(r)
 
 			// If no token found, return unauthorized
 			if tokenString == "" {
@@ -64,10 +75,25 @@ func AuthMiddleware(jwtService JWTService) func(http.Handler) http.Handler {
 			ctx = authctx.WithUserID(ctx, claims.UserID)
 			ctx = authctx.WithUsername(ctx, claims.Username)
 
+			tenantID := claims.TenantID
+
+			// A tenant resolved from the host or path must match the token's tenant claim,
+			// so a token issued for one tenant can't be replayed against another tenant's
+			// subdomain. Tokens without a tenant claim adopt the resolved tenant and
+			// RoleMiddleware enforces membership.
+			if resolvedTenantID, err := authctx.GetResolvedTenantID(ctx); err == nil {
+				if tenantID != nil && *tenantID != resolvedTenantID {
+					log.Printf("[WARN] Token for tenant %d used against tenant %d: user ID %d, %s %s", *tenantID, resolvedTenantID, claims.UserID, r.Method, r.URL.Path)
+					http.Error(w, "Token is not valid for this tenant", http.StatusForbidden)
+					return
+				}
+				tenantID = &resolvedTenantID
+			}
+
 			// Add tenant context if present
-			if claims.TenantID != nil {
-				ctx = authctx.WithTenantID(ctx, claims.TenantID)
-				log.Printf("[DEBUG] User ID %d authenticated with tenant context %d: %s", claims.UserID, *claims.TenantID, r.URL.Path)
+			if tenantID != nil {
+				ctx = authctx.WithTenantID(ctx, tenantID)
+				log.Printf("[DEBUG] User ID %d authenticated with tenant context %d: %s", claims.UserID, *tenantID, r.URL.Path)
 			} else {
 				log.Printf("[DEBUG] User ID %d authenticated without tenant context: %s", claims.UserID, r.URL.Path)
 			}
@@ -78,8 +104,31 @@ func AuthMiddleware(jwtService JWTService) func(http.Handler) http.Handler {
 	}
 }
 
-// RoleMiddleware creates middleware to fetch and set user roles in the context
-func RoleMiddleware(userService service.UserService, tenantMemberService tenantservice.TenantMemberService) func(http.Handler) http.Handler {
+// RequireBearerToken middleware rejects requests that don't present an access
+// token in the Authorization header, for API routes that don't accept the
+// auth_token cookie of browser sessions, which they don't protect against CSRF
+func RequireBearerToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if scheme != "Bearer" || token == "" {
+			log.Printf("[WARN] API request without a bearer token: %s %s", r.Method, r.URL.Path)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Authentication with a bearer token required", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TenantStatusChecker looks up the lifecycle status of a tenant
+type TenantStatusChecker interface {
+	GetTenantStatus(ctx context.Context, tenantID int64) (tenantservice.TenantStatus, error)
+}
+
+// RoleMiddleware creates middleware to fetch and set user roles in the context.
+// If tenantStatusChecker is set, non-admin access to tenants that aren't active is denied.
+func RoleMiddleware(userService service.UserService, tenantMemberService tenantservice.TenantMemberService, tenantStatusChecker TenantStatusChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
@@ -127,6 +176,29 @@ func RoleMiddleware(userService service.UserService, tenantMemberService tenants
 					return
 				}
 
+				// Only admins can access suspended tenants and tenants pending deletion, and
+				// archived tenants are read-only
+				if tenantStatusChecker != nil && !isAdmin {
+					status, err := tenantStatusChecker.GetTenantStatus(ctx, *tenantID)
+					if err != nil {
+						log.Printf("[ERROR] Failed to get status of tenant ID %d: %v", *tenantID, err)
+						http.Error(w, "Failed to verify tenant status", http.StatusInternalServerError)
+						return
+					}
+
+					if message := tenantStatusMessage(status, r.Method); message != "" {
+						log.Printf("[WARN] Access denied: tenant ID %d is %s: user ID %d, %s %s", *tenantID, status, userID, r.Method, r.URL.Path)
+						http.Error(w, message, http.StatusForbidden)
+						return
+					}
+				}
+
+				// Tenant members implicitly hold TENANT_MEMBER for the current tenant
+				if isMember {
+					roles = append(roles, authctx.RoleTenantMember)
+					ctx = authctx.WithRoles(ctx, roles)
+				}
+
 				// Fetch tenant-specific roles
 				tenantRoles, err := userService.GetUserTenantRoles(ctx, userID, *tenantID)
 				if err != nil {
@@ -146,6 +218,26 @@ func RoleMiddleware(userService service.UserService, tenantMemberService tenants
 	}
 }
 
+// tenantStatusMessage returns the error shown to users of a tenant that isn't active,
+// or an empty string if the tenant can be accessed. Archived tenants are read-only.
+func tenantStatusMessage(status tenantservice.TenantStatus, method string) string {
+	switch status {
+	case tenantservice.TenantStatusActive:
+		return ""
+	case tenantservice.TenantStatusArchived:
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+			return ""
+		}
+		return "This tenant is archived and read-only."
+	case tenantservice.TenantStatusSuspended:
+		return "This tenant has been suspended. Contact support to restore access."
+	case tenantservice.TenantStatusPendingDeletion:
+		return "This tenant is scheduled for deletion."
+	default:
+		return "This tenant is not available."
+	}
+}
+
 // RequireAdmin middleware ensures the user has the ADMIN role
 func RequireAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/unsavory/silocore-go/internal/auth/authz"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// Authorize creates middleware that consults the given Authorizer for access to
+// a resource and action. Roles and tenant membership are taken from the context
+// populated by AuthMiddleware and RoleMiddleware.
+func Authorize(authorizer authz.Authorizer, resource, action string, requiredRoles ...authctx.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			req := authz.RequestFromContext(ctx)
+			req.Resource = resource
+			req.Action = action
+			req.RequiredRoles = requiredRoles
+
+			// API keys are limited to their scopes whatever the roles of their creator
+			if req.Scopes != nil && !authz.ScopesAllow(req.Scopes, resource, action) {
+				log.Printf("[WARN] Access to %s:%s denied for user ID %d: not within API key scopes: %s %s", resource, action, req.UserID, r.Method, r.URL.Path)
+				apierror.Write(w, r, apierror.Forbidden("Access denied"))
+				return
+			}
+
+			if err := authorizer.Authorize(ctx, req); err != nil {
+				if errors.Is(err, authz.ErrForbidden) {
+					log.Printf("[WARN] Access to %s:%s denied for user ID %d: %s %s", resource, action, req.UserID, r.Method, r.URL.Path)
+					apierror.Write(w, r, apierror.Forbidden("Access denied"))
+					return
+				}
+				log.Printf("[ERROR] Failed to authorize user ID %d for %s:%s: %v", req.UserID, resource, action, err)
+				apierror.Write(w, r, apierror.Internal("Failed to authorize request"))
+				return
+			}
+
+			log.Printf("[DEBUG] Access to %s:%s granted to user ID %d: %s %s", resource, action, req.UserID, r.Method, r.URL.Path)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission creates middleware that ensures the user's roles grant the
+// action on the resource. Denied requests receive 403 with a JSON error body.
+func RequirePermission(authorizer authz.Authorizer, resource, action string) func(http.Handler) http.Handler {
+	return Authorize(authorizer, resource, action)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// FeatureChecker checks whether a feature is available on a tenant's plan
+type FeatureChecker interface {
+	HasFeature(ctx context.Context, tenantID int64, feature string) (bool, error)
+}
+
+// RequireFeature creates middleware that only allows requests from tenants whose
+// plan includes the feature. Tenants whose subscription has lapsed fall back to the
+// free plan, so paid features are blocked with 402 until they resubscribe.
+// Requests without a tenant context are passed through.
+func RequireFeature(checker FeatureChecker, feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, err := authctx.GetTenantID(r.Context())
+			if err != nil || tenantID == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, err := checker.HasFeature(r.Context(), *tenantID, feature)
+			if err != nil {
+				log.Printf("[ERROR] Failed to check feature '%s' for tenant ID %d: %v", feature, *tenantID, err)
+				apierror.Write(w, r, apierror.Internal("Failed to check tenant plan"))
+				return
+			}
+
+			if !allowed {
+				log.Printf("[WARN] Feature '%s' not available on plan of tenant ID %d: %s %s", feature, *tenantID, r.Method, r.URL.Path)
+				apierror.Write(w, r, apierror.New(http.StatusPaymentRequired, apierror.CodePlanRequired, "This feature requires an active paid subscription"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
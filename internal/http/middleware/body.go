@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const (
+	// defaultMaxBodyBytes is the default request body size limit, 1 MiB
+	defaultMaxBodyBytes = 1 << 20
+
+	// envMaxBodyBytes is the request body size limit in bytes, 0 to disable it
+	envMaxBodyBytes = "MAX_REQUEST_BODY_BYTES"
+)
+
+// BodyLimitConfig holds configuration for the request body size limit
+type BodyLimitConfig struct {
+	// MaxBytes is the largest request body accepted, 0 for no limit
+	MaxBytes int64
+}
+
+// LoadBodyLimitConfig loads the request body size limit from environment variables
+func LoadBodyLimitConfig() (BodyLimitConfig, error) {
+	config := BodyLimitConfig{MaxBytes: defaultMaxBodyBytes}
+
+	if value := os.Getenv(envMaxBodyBytes); value != "" {
+		maxBytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxBytes < 0 {
+			return BodyLimitConfig{}, fmt.Errorf("invalid MAX_REQUEST_BODY_BYTES value: %q", value)
+		}
+		config.MaxBytes = maxBytes
+	}
+
+	return config, nil
+}
+
+// limitedBody is a request body limited by MaxBodySize. It keeps the original
+// body, so routes accepting larger bodies can replace the limit.
+type limitedBody struct {
+	io.ReadCloser
+	original io.ReadCloser
+
+	// tooLarge fails reads of bodies declared larger than the limit before
+	// any of the body is read
+	tooLarge *http.MaxBytesError
+}
+
+// Read reads from the body up to the limit
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.tooLarge != nil {
+		return 0, b.tooLarge
+	}
+	return b.ReadCloser.Read(p)
+}
+
+// MaxBodySize middleware limits request bodies to limit bytes. Reading past the
+// limit, or any of a body whose Content-Length is over it, fails with an
+// *http.MaxBytesError, which handlers report with 413 Request Entity Too Large.
+// Applied again to a route, e.g. for uploads, it replaces the limit instead of
+// adding to it. A limit of zero or less passes requests through.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := r.Body
+			if limited, ok := body.(*limitedBody); ok {
+				body = limited.original
+			}
+
+			if body != nil && body != http.NoBody {
+				limited := &limitedBody{ReadCloser: http.MaxBytesReader(w, body, limit), original: body}
+				if r.ContentLength > limit {
+					limited.tooLarge = &http.MaxBytesError{Limit: limit}
+				}
+				r.Body = limited
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
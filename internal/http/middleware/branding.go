@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/views/components"
+)
+
+// TenantLogoPath is the path the current tenant's logo is served from
+const TenantLogoPath = "/tenant/branding/logo"
+
+// BrandingProvider looks up tenant branding
+type BrandingProvider interface {
+	GetBranding(ctx context.Context, tenantID int64) (*tenantservice.Branding, error)
+}
+
+// LoadBranding creates middleware that adds the current tenant's branding to the
+// request context so pages render with the tenant's name, logo and colors. Only
+// requests that accept HTML are looked up; failures fall back to the default brand.
+func LoadBranding(provider BrandingProvider) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, err := authctx.GetTenantID(r.Context())
+			if err != nil || tenantID == nil || !strings.Contains(r.Header.Get("Accept"), "text/html") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			branding, err := provider.GetBranding(r.Context(), *tenantID)
+			if err != nil {
+				log.Printf("[WARN] Failed to load branding for tenant ID %d: %v", *tenantID, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := components.WithBrand(r.Context(), BrandFromBranding(branding))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BrandFromBranding converts tenant branding to the brand used by the views
+func BrandFromBranding(branding *tenantservice.Branding) components.Brand {
+	brand := components.Brand{
+		Name:         branding.DisplayName,
+		PrimaryColor: branding.PrimaryColor,
+		AccentColor:  branding.AccentColor,
+	}
+
+	if branding.HasLogo {
+		// Version the URL so browsers fetch a replaced logo
+		brand.LogoURL = TenantLogoPath + "?v=" + strconv.FormatInt(branding.UpdatedAt.Unix(), 10)
+	}
+
+	return brand
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// Default compression levels. Brotli's middle levels compress dynamic
+	// responses better than gzip at a similar cost.
+	defaultGzipLevel   = 5
+	defaultBrotliLevel = 4
+
+	// Environment variable names
+	envCompressionGzipLevel     = "COMPRESSION_GZIP_LEVEL"
+	envCompressionBrotliLevel   = "COMPRESSION_BROTLI_LEVEL"
+	envCompressionExcludedTypes = "COMPRESSION_EXCLUDED_TYPES"
+)
+
+// compressibleTypes are the content types compressed unless excluded
+var compressibleTypes = []string{
+	"text/html",
+	"text/css",
+	"text/plain",
+	"text/csv",
+	"text/javascript",
+	"application/javascript",
+	"application/json",
+	"application/atom+xml",
+	"application/rss+xml",
+	"image/svg+xml",
+}
+
+// CompressionConfig holds configuration for response compression
+type CompressionConfig struct {
+	// GzipLevel is the level of gzip and deflate, from 1 to 9
+	GzipLevel int
+
+	// BrotliLevel is the level of Brotli, from 0 to 11
+	BrotliLevel int
+
+	// ExcludedTypes are content types sent uncompressed, e.g. application/json
+	// for clients that compress at a proxy
+	ExcludedTypes []string
+}
+
+// DefaultCompressionConfig returns the compression settings used when none are
+// configured
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		GzipLevel:   defaultGzipLevel,
+		BrotliLevel: defaultBrotliLevel,
+	}
+}
+
+// LoadCompressionConfig loads compression settings from environment variables
+func LoadCompressionConfig() (CompressionConfig, error) {
+	config := DefaultCompressionConfig()
+
+	if value := os.Getenv(envCompressionGzipLevel); value != "" {
+		level, err := strconv.Atoi(value)
+		if err != nil || level < gzip.BestSpeed || level > gzip.BestCompression {
+			return CompressionConfig{}, fmt.Errorf("invalid COMPRESSION_GZIP_LEVEL value: %q", value)
+		}
+		config.GzipLevel = level
+	}
+
+	if value := os.Getenv(envCompressionBrotliLevel); value != "" {
+		level, err := strconv.Atoi(value)
+		if err != nil || level < brotli.BestSpeed || level > brotli.BestCompression {
+			return CompressionConfig{}, fmt.Errorf("invalid COMPRESSION_BROTLI_LEVEL value: %q", value)
+		}
+		config.BrotliLevel = level
+	}
+
+	for _, contentType := range strings.Split(os.Getenv(envCompressionExcludedTypes), ",") {
+		if contentType = strings.ToLower(strings.TrimSpace(contentType)); contentType != "" {
+			config.ExcludedTypes = append(config.ExcludedTypes, contentType)
+		}
+	}
+
+	return config, nil
+}
+
+// Compress middleware compresses responses of compressible content types, but
+// not those excluded by the configuration, with the best encoding the client
+// accepts: Brotli, then gzip, then deflate. Responses vary by Accept-Encoding.
+func Compress(config CompressionConfig) func(http.Handler) http.Handler {
+	excluded := make(map[string]bool, len(config.ExcludedTypes))
+	for _, contentType := range config.ExcludedTypes {
+		excluded[contentType] = true
+	}
+	var types []string
+	for _, contentType := range compressibleTypes {
+		if !excluded[contentType] {
+			types = append(types, contentType)
+		}
+	}
+	if len(types) == 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	// Encoders set later take precedence over gzip and deflate
+	compressor := middleware.NewCompressor(config.GzipLevel, types...)
+	compressor.SetEncoder("br", func(w io.Writer, _ int) io.Writer {
+		return brotli.NewWriterLevel(w, config.BrotliLevel)
+	})
+	return compressor.Handler
+}
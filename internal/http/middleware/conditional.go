@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetLastModified sets the Last-Modified header of a response to the time its
+// resource was last updated, for ConditionalGET to answer If-Modified-Since
+func SetLastModified(w http.ResponseWriter, updatedAt time.Time) {
+	if !updatedAt.IsZero() {
+		w.Header().Set("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// ConditionalGET middleware gives successful JSON responses to GET and HEAD
+// requests an ETag hashed from their body, unless the handler set one, and
+// answers requests whose If-None-Match matches it, or whose If-Modified-Since is
+// no earlier than the Last-Modified set by the handler, with 304 Not Modified
+// and no body. Responses without a Cache-Control header get "private, no-cache",
+// so browsers revalidate them instead of reusing them while they may be stale.
+// Other responses are passed through as they are written.
+func ConditionalGET(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &conditionalWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		if !cw.buffering {
+			return
+		}
+
+		header := w.Header()
+		etag := header.Get("ETag")
+		if etag == "" {
+			// Weak, as compression changes the bytes but not the meaning
+			sum := sha256.Sum256(cw.body.Bytes())
+			etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", etag)
+		}
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", "private, no-cache")
+		}
+
+		if notModified(r, etag, header.Get("Last-Modified")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(cw.body.Bytes())
+	})
+}
+
+// notModified reports whether the client's copy of a response, identified by
+// its request's validators, is current
+func notModified(r *http.Request, etag, lastModified string) bool {
+	// If-None-Match takes precedence over If-Modified-Since
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified == "" {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// conditionalWriter holds back the body of successful JSON responses, which
+// are only written once their ETag is known, or once they are cached
+type conditionalWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+// WriteHeader starts holding back the body of successful JSON responses, and
+// writes the status of others
+func (w *conditionalWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if status == http.StatusOK && mediaType == "application/json" {
+		w.buffering = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write holds back or writes a part of the body
+func (w *conditionalWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/cache"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of unsafe requests that may be retried
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// maxIdempotencyKeyLength is the longest idempotency key accepted
+	maxIdempotencyKeyLength = 255
+
+	// maxIdempotentResponseBytes is the largest response saved for replay.
+	// Requests with larger responses can be retried with the same key.
+	maxIdempotentResponseBytes = 1 << 20
+
+	// codeIdempotencyKeyReused is the error code of a key sent with another request
+	codeIdempotencyKeyReused = "idempotency_key_reused"
+)
+
+// idempotencyWriter copies a response as it is written, for it to be replayed
+type idempotencyWriter struct {
+	http.ResponseWriter
+	status   int
+	header   http.Header
+	body     bytes.Buffer
+	tooLarge bool
+}
+
+// WriteHeader keeps the status and headers of the response, and writes them
+func (w *idempotencyWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	w.header = w.Header().Clone()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write copies a part of the body, and writes it
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.tooLarge {
+		if w.body.Len()+len(b) > maxIdempotentResponseBytes {
+			w.tooLarge = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// errReader fails reads with the error reading a body failed with
+type errReader struct {
+	err error
+}
+
+// Read returns the error
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// Idempotency middleware lets clients retry POST, PUT, PATCH and DELETE
+// requests safely by sending an Idempotency-Key header. The first response to a
+// request with a key is saved, unless it is a server error, and retries with
+// the same key by the same user in the same tenant to the same route are
+// answered with it, marked with Idempotent-Replayed: true, without running the
+// handler again. A key sent again while its first request is in progress is
+// rejected with 409 Conflict, and one sent with a different body with 422
+// Unprocessable Entity. Requests without a key or an authenticated user pass
+// through, as do all requests if store is nil. It must run after
+// authentication. Multipart uploads are keyed but not fingerprinted.
+func Idempotency(store *cache.IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if store == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				key = ""
+			}
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				apierror.Write(w, r, apierror.BadRequest("Idempotency-Key must be at most 255 characters"))
+				return
+			}
+
+			ctx := r.Context()
+			userID, err := authctx.GetUserID(ctx)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tenantID, _ := authctx.GetTenantID(ctx)
+			requestKey := cache.IdempotencyKey{
+				TenantID: tenantID,
+				UserID:   userID,
+				Key:      key,
+				Route:    r.Method + " " + r.URL.Path,
+			}
+
+			// Read the body to fingerprint it. A body that can't be read, e.g.
+			// because it's over the size limit, fails the handler's read too.
+			// Uploads are left for the handler to read within their own limit.
+			var body []byte
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "multipart/form-data" && r.Body != nil {
+				var readErr error
+				body, readErr = io.ReadAll(r.Body)
+				var rest io.Reader = errReader{readErr}
+				if readErr == nil {
+					rest = http.NoBody
+				}
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), rest))
+			}
+			fingerprint := sha256.Sum256(body)
+			requestFingerprint := hex.EncodeToString(fingerprint[:])
+
+			record, found, err := store.Get(ctx, requestKey)
+			if err != nil {
+				log.Printf("[WARN] Failed to read idempotency record of %s for user ID %d: %v", requestKey.Route, userID, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if found {
+				switch {
+				case record.Fingerprint != requestFingerprint:
+					apierror.Write(w, r, apierror.New(http.StatusUnprocessableEntity, codeIdempotencyKeyReused, "Idempotency-Key was already used for a different request"))
+				case record.InProgress:
+					apierror.Write(w, r, apierror.Conflict("A request with this Idempotency-Key is in progress"))
+				default:
+					replayResponse(w, record)
+				}
+				return
+			}
+
+			if err := store.Begin(ctx, requestKey, requestFingerprint); err != nil {
+				log.Printf("[WARN] Failed to record idempotency key of %s for user ID %d: %v", requestKey.Route, userID, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			iw := &idempotencyWriter{ResponseWriter: w}
+			next.ServeHTTP(iw, r)
+
+			if iw.status == 0 {
+				iw.status = http.StatusOK
+				iw.header = w.Header().Clone()
+			}
+			if iw.status >= http.StatusInternalServerError || iw.tooLarge {
+				err = store.Release(ctx, requestKey)
+			} else {
+				iw.header.Del("Set-Cookie")
+				err = store.Save(ctx, requestKey, cache.IdempotentResponse{
+					Fingerprint: requestFingerprint,
+					Status:      iw.status,
+					Header:      iw.header,
+					Body:        iw.body.Bytes(),
+				})
+			}
+			if err != nil {
+				log.Printf("[WARN] Failed to save idempotent response of %s for user ID %d: %v", requestKey.Route, userID, err)
+			}
+		})
+	}
+}
+
+// replayResponse answers a retried request with the response to its first try
+func replayResponse(w http.ResponseWriter, record *cache.IdempotentResponse) {
+	header := w.Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+const (
+	// envIPAllowlist lists the CIDR ranges allowed to use the platform, comma separated
+	envIPAllowlist = "IP_ALLOWLIST"
+
+	// envIPDenylist lists the CIDR ranges blocked from the platform, comma separated
+	envIPDenylist = "IP_DENYLIST"
+)
+
+// IPFilterConfig holds configuration for IP access lists
+type IPFilterConfig struct {
+	// Global applies to the authenticated requests of every tenant
+	Global tenantservice.IPAccessList
+}
+
+// LoadIPFilterConfig loads the global IP access list from environment variables
+func LoadIPFilterConfig() (IPFilterConfig, error) {
+	var config IPFilterConfig
+
+	var err error
+	if config.Global.Allow, err = parseIPPrefixes(os.Getenv(envIPAllowlist)); err != nil {
+		return IPFilterConfig{}, fmt.Errorf("invalid IP_ALLOWLIST value: %w", err)
+	}
+	if config.Global.Deny, err = parseIPPrefixes(os.Getenv(envIPDenylist)); err != nil {
+		return IPFilterConfig{}, fmt.Errorf("invalid IP_DENYLIST value: %w", err)
+	}
+
+	return config, nil
+}
+
+// parseIPPrefixes parses a comma separated list of CIDR ranges and addresses
+func parseIPPrefixes(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		prefix, err := tenantservice.ParseIPPrefix(part)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// IPFilter middleware rejects requests from IP addresses blocked by the global
+// access list, or by the IP rules of the request's tenant, with 403 Forbidden.
+// It must run after authentication, which sets the tenant, so a tenant's rules
+// only apply to requests made in its context. Blocked requests are logged, and
+// recorded in the tenant's audit log if recorder is set. If the tenant's rules
+// can't be loaded, requests are rejected rather than let through. Forwarding
+// headers are only believed from trusted proxies, see RealIP.
+func IPFilter(config *IPFilterConfig, rules tenantservice.IPRuleService, recorder auditservice.AuditRecorder) func(http.Handler) http.Handler {
+	if config == nil {
+		config = &IPFilterConfig{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			var tenantID *int64
+			if id, err := authctx.GetTenantID(ctx); err == nil {
+				tenantID = id
+			}
+
+			// Nothing to check without rules
+			if config.Global.Empty() && (rules == nil || tenantID == nil) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// The remote address is the connection's peer, or the client a
+			// trusted proxy forwarded the request for, as set by RealIP
+			addr, ok := parseRemoteAddr(r.RemoteAddr)
+			if !ok {
+				log.Printf("[WARN] Blocked request with unknown client address %q: %s %s", r.RemoteAddr, r.Method, r.URL.Path)
+				http.Error(w, "Access from your IP address is not allowed", http.StatusForbidden)
+				return
+			}
+
+			list := "global"
+			permitted := config.Global.Permits(addr)
+			if permitted && rules != nil && tenantID != nil {
+				tenantRules, err := rules.ListIPRules(ctx, *tenantID)
+				if err != nil {
+					log.Printf("[ERROR] Failed to load IP rules of tenant ID %d: %v", *tenantID, err)
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				list = "tenant"
+				permitted = tenantservice.NewIPAccessList(tenantRules).Permits(addr)
+			}
+			if permitted {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, _ := authctx.GetUserID(ctx)
+			log.Printf("[WARN] Blocked request from %s by the %s IP access list for user ID %d: %s %s", addr, list, userID, r.Method, r.URL.Path)
+			if recorder != nil && tenantID != nil {
+				err := recorder.Record(ctx, auditservice.AuditEntry{
+					TenantID:     *tenantID,
+					Action:       auditservice.ActionAccessBlocked,
+					ResourceType: auditservice.ResourceIPAddress,
+					ResourceID:   addr.String(),
+					Details: map[string]interface{}{
+						"list":   list,
+						"method": r.Method,
+						"path":   r.URL.Path,
+					},
+				})
+				if err != nil {
+					log.Printf("[ERROR] Failed to record blocked request from %s for tenant ID %d: %v", addr, *tenantID, err)
+				}
+			}
+			http.Error(w, "Access from your IP address is not allowed", http.StatusForbidden)
+		})
+	}
+}
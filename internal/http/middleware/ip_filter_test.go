@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+func TestIPFilterBehindProxies(t *testing.T) {
+	proxies := &ProxyConfig{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}
+	serve := func(list tenantservice.IPAccessList, remoteAddr string, forwardedFor ...string) int {
+		handler := RealIP(proxies)(IPFilter(&IPFilterConfig{Global: list}, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})))
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		r.RemoteAddr = remoteAddr
+		for _, value := range forwardedFor {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	deny := tenantservice.IPAccessList{Deny: []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")}}
+	allow := tenantservice.IPAccessList{Allow: []netip.Prefix{netip.MustParsePrefix("198.51.100.0/24")}}
+
+	t.Run("Spoofed X-Forwarded-For from an untrusted peer is ignored", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(deny, "203.0.113.7:51000", "198.51.100.1"))
+		assert.Equal(t, http.StatusForbidden, serve(allow, "203.0.113.7:51000", "198.51.100.1"))
+	})
+
+	t.Run("Trusted proxies forward the client address", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(deny, "10.0.0.2:443", "203.0.113.7"))
+		assert.Equal(t, http.StatusOK, serve(allow, "10.0.0.2:443", "198.51.100.1"))
+	})
+
+	t.Run("Addresses a client adds before the proxy's are ignored", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(deny, "10.0.0.2:443", "198.51.100.1, 203.0.113.7"))
+		assert.Equal(t, http.StatusForbidden, serve(allow, "10.0.0.3:443", "198.51.100.1", "203.0.113.7, 10.0.0.2"))
+	})
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// APIRequestLimiter counts API requests against a tenant's quota
+type APIRequestLimiter interface {
+	AllowAPIRequest(ctx context.Context, tenantID int64) (*tenantservice.RateLimit, error)
+}
+
+// EnforceAPIQuota creates middleware that counts requests made in a tenant context
+// against the tenant's API request budget, from its quota or plan. Responses to
+// limited tenants carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers. Requests over the limit receive 429 with a Retry-After header; requests
+// without a tenant context are not counted.
+func EnforceAPIQuota(limiter APIRequestLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, err := authctx.GetTenantID(r.Context())
+			if err != nil || tenantID == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rateLimit, err := limiter.AllowAPIRequest(r.Context(), *tenantID)
+			if rateLimit != nil && rateLimit.Limit != nil {
+				setRateLimitHeaders(w, rateLimit)
+			}
+
+			if err != nil {
+				if errors.Is(err, tenantservice.ErrRateLimitExceeded) {
+					log.Printf("[WARN] API request limit exceeded for tenant ID %d: %s %s", *tenantID, r.Method, r.URL.Path)
+					w.Header().Set("Retry-After", strconv.Itoa(secondsUntil(rateLimit.Reset)))
+					apierror.Write(w, r, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "API request limit exceeded for this tenant"))
+					return
+				}
+				log.Printf("[ERROR] Failed to check API quota for tenant ID %d: %v", *tenantID, err)
+				apierror.Write(w, r, apierror.Internal("Failed to check tenant quota"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setRateLimitHeaders describes a tenant's API request budget with the IETF
+// RateLimit header fields
+func setRateLimitHeaders(w http.ResponseWriter, rateLimit *tenantservice.RateLimit) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(*rateLimit.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(rateLimit.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(secondsUntil(rateLimit.Reset)))
+}
+
+// secondsUntil returns the whole number of seconds until t, rounded up
+func secondsUntil(t time.Time) int {
+	seconds := int(time.Until(t).Seconds()) + 1
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
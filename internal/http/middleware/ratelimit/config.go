@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Supported store backends
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Route groups limited separately
+const (
+	// GroupAuth is the login and registration forms, limited by IP address
+	GroupAuth = "auth"
+
+	// GroupAPI is the authenticated routes, limited by user
+	GroupAPI = "api"
+
+	// GroupTenant is the tenant and order routes, limited by tenant
+	GroupTenant = "tenant"
+)
+
+const (
+	// Default values
+	defaultKeyPrefix = "silocore:ratelimit:"
+
+	// Environment variable names
+	envRateLimitBackend = "RATE_LIMIT_BACKEND"
+	envRedisURL         = "REDIS_URL"
+)
+
+// DefaultLimits are the limits of the route groups unless configured otherwise
+var DefaultLimits = map[string]Limit{
+	GroupAuth:   {Requests: 10, Period: time.Minute},
+	GroupAPI:    {Requests: 600, Period: time.Minute},
+	GroupTenant: {Requests: 3000, Period: time.Minute},
+}
+
+// Config holds configuration for rate limiting
+type Config struct {
+	Backend  string
+	RedisURL string
+
+	// Limits are the limits of the route groups by name. Groups without one
+	// aren't limited.
+	Limits map[string]Limit
+}
+
+// LoadConfig loads rate limiting configuration from environment variables. The
+// limit of each group is read from RATE_LIMIT_{GROUP} as {requests}/{period},
+// such as 100/1m, with an optional burst as in 100/1m+20; 0 disables the group's
+// limit.
+func LoadConfig() (Config, error) {
+	config := Config{
+		Backend:  os.Getenv(envRateLimitBackend),
+		RedisURL: os.Getenv(envRedisURL),
+		Limits:   make(map[string]Limit),
+	}
+
+	if config.Backend == "" {
+		config.Backend = BackendMemory
+	}
+
+	for group, limit := range DefaultLimits {
+		name := "RATE_LIMIT_" + strings.ToUpper(group)
+		if value := os.Getenv(name); value != "" {
+			parsed, err := parseLimit(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid %s value: %q", name, value)
+			}
+			limit = parsed
+		}
+		if limit.Requests > 0 {
+			config.Limits[group] = limit
+		}
+	}
+
+	return config, nil
+}
+
+// parseLimit parses a limit written as {requests}/{period}[+{burst}], or 0 for
+// no limit
+func parseLimit(value string) (Limit, error) {
+	if value == "0" {
+		return Limit{}, nil
+	}
+
+	requests, rest, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("missing period")
+	}
+	period, burst, hasBurst := strings.Cut(rest, "+")
+
+	var limit Limit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid requests: %q", requests)
+	}
+	if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+		return Limit{}, fmt.Errorf("invalid period: %q", period)
+	}
+	if hasBurst {
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid burst: %q", burst)
+		}
+	}
+	return limit, nil
+}
+
+// New creates the Limiter selected by the configuration.
+// It returns nil if rate limiting is disabled.
+func New(config Config) (*Limiter, error) {
+	switch config.Backend {
+	case BackendNone:
+		log.Printf("[INFO] Rate limiting disabled")
+		return nil, nil
+	case "", BackendMemory:
+		log.Printf("[INFO] Rate limiting requests in memory")
+		return NewLimiter(NewMemoryStore(), config.Limits), nil
+	case BackendRedis:
+		if config.RedisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL environment variable is required for the redis rate limit backend")
+		}
+		options, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL value: %w", err)
+		}
+		log.Printf("[INFO] Rate limiting requests in Redis at %s", options.Addr)
+		return NewLimiter(NewRedisStore(redis.NewClient(options), defaultKeyPrefix), config.Limits), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend: %s", config.Backend)
+	}
+}
@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		config, err := LoadConfig()
+		require.NoError(t, err)
+
+		assert.Equal(t, BackendMemory, config.Backend)
+		assert.Equal(t, DefaultLimits, config.Limits)
+	})
+
+	t.Run("Group limits", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_BACKEND", "redis")
+		t.Setenv("RATE_LIMIT_AUTH", "5/30s+10")
+		t.Setenv("RATE_LIMIT_API", "100/1m")
+		t.Setenv("RATE_LIMIT_TENANT", "0")
+
+		config, err := LoadConfig()
+		require.NoError(t, err)
+
+		assert.Equal(t, BackendRedis, config.Backend)
+		assert.Equal(t, map[string]Limit{
+			GroupAuth: {Requests: 5, Period: 30 * time.Second, Burst: 10},
+			GroupAPI:  {Requests: 100, Period: time.Minute},
+		}, config.Limits)
+	})
+
+	t.Run("Invalid limits", func(t *testing.T) {
+		for _, value := range []string{"100", "abc/1m", "100/soon", "100/1m+", "-1/1m"} {
+			t.Setenv("RATE_LIMIT_API", value)
+
+			_, err := LoadConfig()
+			assert.EqualError(t, err, `invalid RATE_LIMIT_API value: "`+value+`"`)
+		}
+	})
+}
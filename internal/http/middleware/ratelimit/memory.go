@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often full buckets are dropped from a MemoryStore
+const memorySweepInterval = time.Minute
+
+// bucket is a client's token bucket in a MemoryStore
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore keeps token buckets in memory. Each instance limits its own
+// requests, so with several instances clients can make more requests in total.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// Ensure MemoryStore implements Store
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates a new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key, reporting whether it had one
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst())}
+		s.buckets[key] = b
+	} else {
+		b.tokens = limit.refill(b.tokens, now.Sub(b.updated))
+	}
+	b.limit = limit
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return limit.result(allowed, b.tokens), nil
+}
+
+// sweep drops the buckets that have refilled, which are the same as new ones,
+// at most once per memorySweepInterval
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.limit.refill(b.tokens, now.Sub(b.updated)) >= float64(b.limit.burst()) {
+			delete(s.buckets, key)
+		}
+	}
+}
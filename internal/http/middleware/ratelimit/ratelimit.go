@@ -0,0 +1,188 @@
+// Package ratelimit limits how often clients can make requests, with a token
+// bucket per client kept in a Store shared by the instances serving them.
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// Limit is the rate a client can make requests at. Its bucket holds Burst
+// tokens, refilled at Requests per Period; each request takes one.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+
+	// Burst is the number of requests that can be made at once. Zero means
+	// Requests.
+	Burst int
+}
+
+// burst returns the size of the limit's bucket
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// rate returns the tokens added to a bucket per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// refill returns the tokens of a bucket that had tokens elapsed ago
+func (l Limit) refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(l.burst()), tokens+elapsed.Seconds()*l.rate())
+}
+
+// result describes a bucket left with tokens after a request
+func (l Limit) result(allowed bool, tokens float64) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     l.burst(),
+		Remaining: int(tokens),
+		Reset:     l.until(float64(l.burst()) - tokens),
+	}
+	if !allowed {
+		result.RetryAfter = l.until(1 - tokens)
+	}
+	return result
+}
+
+// until returns how long it takes to add tokens to a bucket
+func (l Limit) until(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.rate() * float64(time.Second))
+}
+
+// Result is the state of a client's bucket after a request
+type Result struct {
+	// Allowed reports whether the request may be served
+	Allowed bool
+
+	// Limit is the number of requests the bucket holds, and Remaining the number
+	// left
+	Limit     int
+	Remaining int
+
+	// Reset is the time until the bucket is full again, and RetryAfter the time
+	// until a request that was not allowed would be
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets of clients
+type Store interface {
+	// Allow takes a token from the bucket of key, reporting whether it had one
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// KeyFunc returns the key of the client making a request, whose requests share
+// a bucket, or "" if the request is not limited
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys requests by the client's IP address. It is the connection's peer,
+// unless the router's RealIP middleware set it to the client a trusted proxy
+// forwarded the request for, so clients can't get new buckets by sending
+// X-Forwarded-For themselves.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ByUser keys requests by the authenticated user. Requests without a user are
+// not limited.
+func ByUser(r *http.Request) string {
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		return ""
+	}
+	return "user:" + strconv.FormatInt(userID, 10)
+}
+
+// ByTenant keys requests by their tenant, so a tenant's members share a bucket.
+// Requests without a tenant context are not limited.
+func ByTenant(r *http.Request) string {
+	tenantID, err := authctx.GetTenantID(r.Context())
+	if err != nil || tenantID == nil {
+		return ""
+	}
+	return "tenant:" + strconv.FormatInt(*tenantID, 10)
+}
+
+// Limiter limits the requests of route groups, each at its own limit
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+}
+
+// NewLimiter creates a new Limiter keeping buckets in store, with the limits of
+// route groups by name
+func NewLimiter(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Group creates middleware limiting the requests of a route group at the
+// group's limit, with a bucket per key. Responses carry X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset headers, the reset being the Unix
+// time the bucket is full again. Requests over the limit receive 429 with a
+// Retry-After header. Groups without a limit aren't limited, and requests are
+// served if the store fails.
+func (l *Limiter) Group(group string, key KeyFunc) func(http.Handler) http.Handler {
+	limit, ok := l.limits[group]
+	if !ok || limit.Requests <= 0 || limit.Period <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := key(r)
+			if client == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := l.store.Allow(r.Context(), group+":"+client, limit)
+			if err != nil {
+				log.Printf("[ERROR] Failed to check %s rate limit for %s: %v", group, client, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(result.Reset).Unix(), 10))
+
+			if !result.Allowed {
+				log.Printf("[WARN] %s rate limit exceeded for %s: %s %s", group, client, r.Method, r.URL.Path)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				apierror.Write(w, r, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "Too many requests"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
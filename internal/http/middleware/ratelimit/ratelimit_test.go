@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	custommw "github.com/unsavory/silocore-go/internal/http/middleware"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Period: time.Second}
+	ctx := context.Background()
+
+	// Expect the bucket to allow a burst of two requests
+	for i := 1; i >= 0; i-- {
+		result, err := store.Allow(ctx, "ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	// Expect the third request to wait for a token
+	result, err := store.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, time.Second, result.Reset)
+
+	// Expect other clients to have their own bucket
+	result, err = store.Allow(ctx, "ip:10.0.0.2", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// Expect a token to be added every half second
+	now = now.Add(500 * time.Millisecond)
+	result, err = store.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Expect refilled buckets to be swept
+	now = now.Add(memorySweepInterval)
+	_, err = store.Allow(ctx, "ip:10.0.0.3", limit)
+	require.NoError(t, err)
+	assert.Len(t, store.buckets, 1)
+}
+
+// failingStore is a Store that is unreachable
+type failingStore struct{}
+
+func (failingStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
+
+func TestLimiterGroup(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	limits := map[string]Limit{GroupAuth: {Requests: 1, Period: time.Minute}}
+
+	t.Run("Requests over the limit are rejected", func(t *testing.T) {
+		handler := NewLimiter(NewMemoryStore(), limits).Group(GroupAuth, ByIP)(ok)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+		reset, err := strconv.ParseInt(rec.Header().Get("X-RateLimit-Reset"), 10, 64)
+		require.NoError(t, err)
+		assert.InDelta(t, time.Now().Add(time.Minute).Unix(), reset, 1)
+
+		// Expect the same address on another port to share the bucket
+		rec = httptest.NewRecorder()
+		req.RemoteAddr = "10.0.0.1:5678"
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("Rotating X-Forwarded-For does not reset the limit", func(t *testing.T) {
+		proxies := &custommw.ProxyConfig{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}
+		handler := custommw.RealIP(proxies)(NewLimiter(NewMemoryStore(), limits).Group(GroupAuth, ByIP)(ok))
+		serve := func(remoteAddr, forwardedFor string) int {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			handler.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		// Expect a client not behind a trusted proxy to be limited by its own address
+		assert.Equal(t, http.StatusOK, serve("203.0.113.7:1234", "198.51.100.1"))
+		assert.Equal(t, http.StatusTooManyRequests, serve("203.0.113.7:1234", "198.51.100.2"))
+
+		// Expect a client behind a trusted proxy to be limited by the address the
+		// proxy saw, whatever it adds to the header
+		assert.Equal(t, http.StatusOK, serve("10.0.0.2:443", "192.0.2.9"))
+		assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.2:443", "198.51.100.3, 192.0.2.9"))
+		assert.Equal(t, http.StatusOK, serve("10.0.0.2:443", "192.0.2.10"))
+	})
+
+	t.Run("Requests without a key are not limited", func(t *testing.T) {
+		limits := map[string]Limit{GroupTenant: {Requests: 1, Period: time.Minute}}
+		handler := NewLimiter(NewMemoryStore(), limits).Group(GroupTenant, ByTenant)(ok)
+
+		for i := 0; i < 2; i++ {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"))
+		}
+	})
+
+	t.Run("Users are limited separately", func(t *testing.T) {
+		limits := map[string]Limit{GroupAPI: {Requests: 1, Period: time.Minute}}
+		handler := NewLimiter(NewMemoryStore(), limits).Group(GroupAPI, ByUser)(ok)
+
+		for _, userID := range []int64{1, 2} {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			handler.ServeHTTP(rec, req.WithContext(authctx.WithUserID(req.Context(), userID)))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Groups without a limit are not limited", func(t *testing.T) {
+		handler := NewLimiter(NewMemoryStore(), limits).Group(GroupAPI, ByIP)(ok)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("Requests are served if the store fails", func(t *testing.T) {
+		handler := NewLimiter(failingStore{}, limits).Group(GroupAuth, ByIP)(ok)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
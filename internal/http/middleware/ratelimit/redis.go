@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrStoreOperation is returned when the store can't be read or written
+var ErrStoreOperation = errors.New("rate limit store operation failed")
+
+// takeTokenScript refills a bucket kept in a hash, at ARGV[1] tokens per second
+// up to ARGV[2] tokens, by the time since it was last updated and takes a token
+// if it has one. It returns whether it took one and the tokens left. The
+// bucket expires once it would be full anyway, after ARGV[3] milliseconds.
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps token buckets in Redis, so instances sharing it limit
+// clients together. Buckets are updated atomically by a script using the Redis
+// server's clock.
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// Ensure RedisStore implements Store
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore creates a new RedisStore. All keys are namespaced with keyPrefix.
+func NewRedisStore(client *redis.Client, keyPrefix string) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Allow takes a token from the bucket of key, reporting whether it had one
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	// Expire the bucket once it has refilled, rounding up to a millisecond
+	ttl := limit.until(float64(limit.burst()))/time.Millisecond + 1
+
+	reply, err := takeTokenScript.Run(ctx, s.client, []string{s.keyPrefix + key},
+		strconv.FormatFloat(limit.rate(), 'f', -1, 64), limit.burst(), int64(ttl)).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrStoreOperation, err)
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("%w: unexpected reply %v", ErrStoreOperation, reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	remaining, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrStoreOperation, err)
+	}
+	return limit.result(allowed == 1, tokens), nil
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// envTrustedProxies lists the CIDR ranges of the proxies in front of the
+// server, comma separated
+const envTrustedProxies = "TRUSTED_PROXIES"
+
+// ProxyConfig holds configuration for finding the client address of requests
+// made through proxies
+type ProxyConfig struct {
+	// TrustedProxies are the addresses whose forwarding headers are believed.
+	// Without any, requests are attributed to the connection's peer.
+	TrustedProxies []netip.Prefix
+}
+
+// LoadProxyConfig loads the trusted proxies from environment variables
+func LoadProxyConfig() (ProxyConfig, error) {
+	proxies, err := parseIPPrefixes(os.Getenv(envTrustedProxies))
+	if err != nil {
+		return ProxyConfig{}, fmt.Errorf("invalid TRUSTED_PROXIES value: %w", err)
+	}
+	return ProxyConfig{TrustedProxies: proxies}, nil
+}
+
+// trusted reports whether addr is a trusted proxy
+func (c *ProxyConfig) trusted(addr netip.Addr) bool {
+	for _, prefix := range c.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientAddr returns the address of the client a request was made by. Forwarding
+// headers are only read from trusted proxies, and X-Forwarded-For is read from
+// the right, skipping the trusted proxies the request passed through, so an
+// address a client puts in the header itself is never used.
+func (c *ProxyConfig) clientAddr(r *http.Request) (netip.Addr, bool) {
+	peer, ok := parseRemoteAddr(r.RemoteAddr)
+	if !ok || !c.trusted(peer) {
+		return peer, ok
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			addr = addr.Unmap()
+			if !c.trusted(addr) || i == 0 {
+				return addr, true
+			}
+		}
+		return peer, true
+	}
+
+	for _, header := range []string{"X-Real-IP", "True-Client-IP"} {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get(header))); err == nil {
+			return addr.Unmap(), true
+		}
+	}
+	return peer, true
+}
+
+// RealIP middleware sets the remote address of requests made through trusted
+// proxies to the client address they forward, for the IP access lists, rate
+// limits and logs that read it. Requests from other peers keep their
+// connection's address, whatever headers they send.
+func RealIP(config *ProxyConfig) func(http.Handler) http.Handler {
+	if config == nil {
+		config = &ProxyConfig{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if addr, ok := config.clientAddr(r); ok {
+				r.RemoteAddr = addr.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// parseRemoteAddr parses the IP address of a request's remote address, with or
+// without a port
+func parseRemoteAddr(remoteAddr string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
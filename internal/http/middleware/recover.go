@@ -5,23 +5,99 @@ import (
 	"log"
 	"net/http"
 	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/errortracking"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	"github.com/unsavory/silocore-go/internal/views/pages"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Recover is a middleware that recovers from panics and logs the error
-func Recover(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if err := recover(); err != nil {
-				// Log the error and stack trace
-				log.Printf("[ERROR] Panic recovered: %v\n%s", err, debug.Stack())
-
-				// Return a 500 Internal Server Error response
-				w.Header().Set("Content-Type", "text/plain")
-				w.WriteHeader(http.StatusInternalServerError)
-				fmt.Fprintln(w, "Internal Server Error")
-			}
-		}()
-
-		next.ServeHTTP(w, r)
-	})
+// Recover middleware recovers from panics in later handlers. The panic is
+// reported to the error tracker with its stack trace, recorded on the request's
+// span, and answered with 500 Internal Server Error: in the JSON error envelope
+// with the request ID for API routes and JSON clients, or with the error page
+// for the pages. Handlers aborted with http.ErrAbortHandler are not recovered.
+//
+// Applied after authentication, it also reports the user and tenant.
+func Recover(reporter errortracking.Reporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				if rvr == http.ErrAbortHandler {
+					panic(rvr)
+				}
+
+				ctx := r.Context()
+				err, ok := rvr.(error)
+				if !ok {
+					err = fmt.Errorf("panic: %v", rvr)
+				}
+
+				event := errortracking.Event{
+					Err:       err,
+					Stack:     debug.Stack(),
+					RequestID: middleware.GetReqID(ctx),
+					Method:    r.Method,
+					URL:       r.URL.String(),
+					Time:      time.Now(),
+				}
+				if userID, err := authctx.GetUserID(ctx); err == nil {
+					event.UserID = &userID
+				}
+				if tenantID, err := authctx.GetTenantID(ctx); err == nil {
+					event.TenantID = tenantID
+				}
+				reporter.Report(ctx, event)
+
+				span := trace.SpanFromContext(ctx)
+				span.RecordError(err, trace.WithStackTrace(true))
+				span.SetStatus(codes.Error, "panic")
+
+				// The connection of upgraded requests, such as websockets, is the handler's
+				if r.Header.Get("Connection") == "Upgrade" {
+					return
+				}
+				writePanicResponse(w, r, event.RequestID)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writePanicResponse answers a request whose handler panicked
+func writePanicResponse(w http.ResponseWriter, r *http.Request, requestID string) {
+	if wantsJSON(r) {
+		apierror.Write(w, r, apierror.Internal("Internal server error"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusInternalServerError)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if err := pages.ServerError(pages.ServerErrorData{RequestID: requestID}).Render(r.Context(), w); err != nil {
+		log.Printf("[ERROR] Failed to render error page: %v", err)
+	}
+}
+
+// wantsJSON reports whether a request is for a JSON API route, such as those
+// under /api/v1 or /orders/api, or from a client that only accepts JSON
+func wantsJSON(r *http.Request) bool {
+	if strings.Contains(r.URL.Path+"/", "/api/") {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
 }
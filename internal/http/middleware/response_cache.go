@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+// ResponseCache middleware serves GET and HEAD requests of an expensive read
+// endpoint from the current tenant's cached response to the same path and
+// query, and caches successful JSON responses to requests it couldn't serve.
+// Responses carry X-Cache: HIT or MISS. Cached responses expire after the
+// response cache's TTL, or when the write services invalidate the tenant's
+// responses. Requests without a tenant pass through, as do all requests if
+// responses is nil.
+func ResponseCache(responses *cache.ResponseCache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if responses == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tenantID, err := authctx.GetTenantID(r.Context())
+			if err != nil || tenantID == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, found, err := responses.Get(r.Context(), *tenantID, r.URL)
+			if err != nil {
+				log.Printf("[WARN] Failed to read cached response to %s for tenant ID %d: %v", r.URL.Path, *tenantID, err)
+			} else if found {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(http.StatusOK)
+				w.Write(body)
+				return
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+			cw := &conditionalWriter{ResponseWriter: w}
+			next.ServeHTTP(cw, r)
+			if !cw.buffering {
+				return
+			}
+
+			if err := responses.Set(r.Context(), *tenantID, r.URL, cw.body.Bytes()); err != nil {
+				log.Printf("[WARN] Failed to cache response to %s for tenant ID %d: %v", r.URL.Path, *tenantID, err)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write(cw.body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// tenantPathPrefix is the path prefix that selects a tenant by slug, e.g. /t/acme/orders
+const tenantPathPrefix = "/t/"
+
+// TenantResolver looks up tenants by slug
+type TenantResolver interface {
+	GetTenantBySlug(ctx context.Context, slug string) (*tenantservice.Tenant, error)
+}
+
+// DomainResolver looks up the tenants of verified custom domains
+type DomainResolver interface {
+	GetTenantIDByDomain(ctx context.Context, domain string) (int64, error)
+}
+
+// ResolveTenant creates middleware that resolves the tenant from a {slug}.baseDomain
+// host, a verified custom domain or a /t/{slug} path prefix and adds it to the request
+// context. The path prefix is stripped so the remaining path is routed as usual. It
+// must run before AuthMiddleware, which rejects tokens scoped to a different tenant.
+// Subdomain resolution is disabled when baseDomain is empty and custom domain
+// resolution when domains is nil.
+func ResolveTenant(resolver TenantResolver, domains DomainResolver, baseDomain string) func(http.Handler) http.Handler {
+	baseDomain = strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			slug := slugFromHost(r.Host, baseDomain)
+
+			if slug == "" && domains != nil {
+				if domain := customDomainFromHost(r.Host, baseDomain); domain != "" {
+					tenantID, err := domains.GetTenantIDByDomain(r.Context(), domain)
+					if err == nil {
+						log.Printf("[DEBUG] Resolved domain '%s' to tenant ID %d: %s %s", domain, tenantID, r.Method, r.URL.Path)
+
+						ctx := authctx.WithResolvedTenantID(r.Context(), tenantID)
+						ctx = authctx.WithTenantID(ctx, &tenantID)
+						next.ServeHTTP(w, r.WithContext(ctx))
+						return
+					}
+					// Unknown hosts may be the application's own domain, so they're served without a tenant
+					if !errors.Is(err, tenantservice.ErrDomainNotFound) {
+						log.Printf("[ERROR] Failed to resolve domain '%s': %v", domain, err)
+						http.Error(w, "Failed to resolve tenant", http.StatusInternalServerError)
+						return
+					}
+				}
+			}
+
+			if slug == "" {
+				var rest string
+				slug, rest = slugFromPath(r.URL.Path)
+				if slug != "" {
+					// Route the request as if the prefix wasn't there
+					r.URL.Path = rest
+					r.URL.RawPath = ""
+				}
+			}
+
+			if slug == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tenant, err := resolver.GetTenantBySlug(r.Context(), slug)
+			if err != nil {
+				if errors.Is(err, tenantservice.ErrTenantNotFound) {
+					log.Printf("[WARN] Unknown tenant slug '%s': %s %s", slug, r.Method, r.URL.Path)
+					http.Error(w, "Tenant not found", http.StatusNotFound)
+					return
+				}
+				log.Printf("[ERROR] Failed to resolve tenant slug '%s': %v", slug, err)
+				http.Error(w, "Failed to resolve tenant", http.StatusInternalServerError)
+				return
+			}
+
+			log.Printf("[DEBUG] Resolved tenant slug '%s' to tenant ID %d: %s %s", slug, tenant.ID, r.Method, r.URL.Path)
+
+			ctx := authctx.WithResolvedTenantID(r.Context(), tenant.ID)
+			ctx = authctx.WithTenantID(ctx, &tenant.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// slugFromHost returns the tenant slug of a {slug}.baseDomain host, or an empty
+// string if the host is not a tenant subdomain
+func slugFromHost(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	label, ok := strings.CutSuffix(host, "."+baseDomain)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+
+	// Reserved names such as www are served without a tenant
+	if tenantservice.ValidateSlug(label) != nil {
+		return ""
+	}
+
+	return label
+}
+
+// customDomainFromHost returns the host if it could be a tenant's custom domain, or
+// an empty string for IP addresses, single-label hosts such as localhost and hosts
+// under baseDomain
+func customDomainFromHost(host, baseDomain string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = tenantservice.NormalizeDomain(host)
+
+	if !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return ""
+	}
+	if baseDomain != "" && (host == baseDomain || strings.HasSuffix(host, "."+baseDomain)) {
+		return ""
+	}
+
+	return host
+}
+
+// slugFromPath splits a /t/{slug}/rest path into the slug and the remaining path
+func slugFromPath(path string) (string, string) {
+	trimmed, ok := strings.CutPrefix(path, tenantPathPrefix)
+	if !ok {
+		return "", path
+	}
+
+	slug, rest, _ := strings.Cut(trimmed, "/")
+	if tenantservice.ValidateSlug(strings.ToLower(slug)) != nil {
+		return "", path
+	}
+
+	return strings.ToLower(slug), "/" + rest
+}
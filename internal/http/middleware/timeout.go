@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+const (
+	// Default request timeouts
+	defaultRequestTimeout       = 60 * time.Second
+	defaultAPIRequestTimeout    = 30 * time.Second
+	defaultExportRequestTimeout = 10 * time.Minute
+
+	// Environment variable names, in seconds with 0 for no timeout
+	envRequestTimeoutSeconds       = "REQUEST_TIMEOUT_SECONDS"
+	envAPIRequestTimeoutSeconds    = "API_REQUEST_TIMEOUT_SECONDS"
+	envExportRequestTimeoutSeconds = "EXPORT_REQUEST_TIMEOUT_SECONDS"
+)
+
+// TimeoutConfig holds the request timeouts of route groups. Zero means no
+// timeout.
+type TimeoutConfig struct {
+	// Default bounds requests of routes without a timeout of their own, such
+	// as the pages
+	Default time.Duration
+
+	// API bounds requests of the versioned JSON API, whose clients retry
+	API time.Duration
+
+	// Export bounds downloads streaming tenant data, such as order CSV exports
+	Export time.Duration
+}
+
+// DefaultTimeoutConfig returns the request timeouts used when none are configured
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		Default: defaultRequestTimeout,
+		API:     defaultAPIRequestTimeout,
+		Export:  defaultExportRequestTimeout,
+	}
+}
+
+// LoadTimeoutConfig loads request timeouts from environment variables
+func LoadTimeoutConfig() (TimeoutConfig, error) {
+	config := DefaultTimeoutConfig()
+
+	for _, setting := range []struct {
+		env     string
+		timeout *time.Duration
+	}{
+		{envRequestTimeoutSeconds, &config.Default},
+		{envAPIRequestTimeoutSeconds, &config.API},
+		{envExportRequestTimeoutSeconds, &config.Export},
+	} {
+		value := os.Getenv(setting.env)
+		if value == "" {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return TimeoutConfig{}, fmt.Errorf("invalid %s value: %q", setting.env, value)
+		}
+		*setting.timeout = time.Duration(seconds) * time.Second
+	}
+
+	return config, nil
+}
+
+// requestDeadlineKey is the context key of a request's deadline
+type requestDeadlineKey struct{}
+
+// requestDeadline is the context of a request bounded by Timeout. Unlike a
+// context.WithTimeout deadline, route groups can move it later as well as
+// earlier, so transactions begun with the context before routing live as long as
+// the route allows.
+type requestDeadline struct {
+	// Context is cancelled with the cause context.DeadlineExceeded when the
+	// deadline passes
+	context.Context
+	cancel context.CancelCauseFunc
+
+	start  time.Time
+	config TimeoutConfig
+
+	mu    sync.Mutex
+	at    time.Time
+	timer *time.Timer
+}
+
+// Deadline returns the request's deadline, or its parent's if that is earlier
+func (d *requestDeadline) Deadline() (time.Time, bool) {
+	d.mu.Lock()
+	at := d.at
+	d.mu.Unlock()
+
+	parent, ok := d.Context.Deadline()
+	if at.IsZero() || (ok && parent.Before(at)) {
+		return parent, ok
+	}
+	return at, true
+}
+
+// Err returns context.DeadlineExceeded once the deadline has passed
+func (d *requestDeadline) Err() error {
+	if context.Cause(d.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+	return d.Context.Err()
+}
+
+// Value returns the request deadline itself for requestDeadlineKey
+func (d *requestDeadline) Value(key any) any {
+	if key == (requestDeadlineKey{}) {
+		return d
+	}
+	return d.Context.Value(key)
+}
+
+// set moves the deadline to timeout after the request started, or removes it
+// for zero. A deadline that has passed stays passed.
+func (d *requestDeadline) set(timeout time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Context.Err() != nil {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.at, d.timer = time.Time{}, nil
+	if timeout <= 0 {
+		return
+	}
+
+	d.at = d.start.Add(timeout)
+	d.timer = time.AfterFunc(time.Until(d.at), func() {
+		d.cancel(context.DeadlineExceeded)
+	})
+}
+
+// stop releases the deadline's timer once the request is served
+func (d *requestDeadline) stop() {
+	d.mu.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.mu.Unlock()
+	d.cancel(context.Canceled)
+}
+
+// Timeout middleware bounds how long requests take by setting a deadline on
+// their context, config.Default after they start unless a route group sets
+// another with APITimeout or ExportTimeout. Queries and calls made with the
+// context are cancelled when it passes. A handler that returns without
+// responding after the deadline is answered with 503 Service Unavailable, in
+// the JSON error envelope for API routes and JSON clients.
+func Timeout(config TimeoutConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancelCause(r.Context())
+			deadline := &requestDeadline{
+				Context: ctx,
+				cancel:  cancel,
+				start:   time.Now(),
+				config:  config,
+			}
+			deadline.set(config.Default)
+			defer deadline.stop()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			r = r.WithContext(deadline)
+			next.ServeHTTP(ww, r)
+
+			if deadline.Err() == context.DeadlineExceeded && ww.Status() == 0 {
+				writeTimeoutResponse(ww, r)
+			}
+		})
+	}
+}
+
+// APITimeout middleware moves the deadline set by Timeout to the API timeout
+func APITimeout(next http.Handler) http.Handler {
+	return routeTimeout(next, func(config TimeoutConfig) time.Duration {
+		return config.API
+	})
+}
+
+// ExportTimeout middleware moves the deadline set by Timeout to the export
+// timeout. The request's transaction, if it has begun, is extended with it.
+func ExportTimeout(next http.Handler) http.Handler {
+	return routeTimeout(next, func(config TimeoutConfig) time.Duration {
+		return config.Export
+	})
+}
+
+// routeTimeout moves the request's deadline to the timeout of a route group.
+// Requests without one pass through.
+func routeTimeout(next http.Handler, timeout func(TimeoutConfig) time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if deadline, ok := r.Context().Value(requestDeadlineKey{}).(*requestDeadline); ok {
+			deadline.set(timeout(deadline.config))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeTimeoutResponse answers a request whose deadline passed before its
+// handler responded
+func writeTimeoutResponse(w http.ResponseWriter, r *http.Request) {
+	if wantsJSON(r) {
+		apierror.Write(w, r, apierror.Timeout())
+		return
+	}
+	http.Error(w, "Request timed out", http.StatusServiceUnavailable)
+}
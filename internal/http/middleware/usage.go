@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// APIUsageRecorder records API requests for usage metering
+type APIUsageRecorder interface {
+	RecordAPIRequest(tenantID int64, userID int64)
+}
+
+// MeterUsage creates middleware that records each request made in a tenant context
+// against the tenant's usage. It must run after AuthMiddleware.
+func MeterUsage(recorder APIUsageRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			userID, userErr := authctx.GetUserID(ctx)
+			tenantID, tenantErr := authctx.GetTenantID(ctx)
+			if userErr == nil && tenantErr == nil && tenantID != nil {
+				recorder.RecordAPIRequest(*tenantID, userID)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,17 +1,32 @@
 package router
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	custommw "github.com/unsavory/silocore-go/internal/http/middleware"
+	"github.com/unsavory/silocore-go/internal/http/validate"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/views/pages"
 )
 
 // AdminRouter handles admin-related routes
 type AdminRouter struct {
-	// Add dependencies as needed
+	tenantService tenantservice.TenantService
 }
 
 // NewAdminRouter creates a new AdminRouter with the required dependencies
-func NewAdminRouter() *AdminRouter {
-	return &AdminRouter{}
+func NewAdminRouter(tenantService tenantservice.TenantService) *AdminRouter {
+	return &AdminRouter{
+		tenantService: tenantService,
+	}
 }
 
 // Dashboard renders the admin dashboard
@@ -19,29 +34,365 @@ func (ar *AdminRouter) Dashboard(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Admin Dashboard"))
 }
 
-// ListTenants lists all tenants
+// tenantRequest is the request body for creating or updating a tenant. The admin
+// pages submit the same fields as a form.
+type tenantRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+
+	// OwnerUserID makes the user a member and TENANT_SUPER of a new tenant
+	OwnerUserID *int64 `json:"owner_user_id,omitempty" validate:"omitempty,min=1"`
+}
+
+// ListTenants handles GET /admin/tenants. Tenants can be filtered by search, status
+// and a created_from/created_to range (YYYY-MM-DD), and paged with limit and offset.
 func (ar *AdminRouter) ListTenants(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("List of all tenants"))
+	filter, ok := parseTenantFilter(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := ar.tenantService.ListTenants(r.Context(), filter)
+	if err != nil {
+		writeTenantError(w, err, "Failed to list tenants")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list)
 }
 
-// CreateTenant creates a new tenant
+// TenantsPage handles GET /admin/tenants/view and renders the tenant list page
+func (ar *AdminRouter) TenantsPage(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseTenantFilter(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := ar.tenantService.ListTenants(r.Context(), filter)
+	if err != nil {
+		writeTenantError(w, err, "Failed to list tenants")
+		return
+	}
+
+	data := pages.AdminTenantsPageData{
+		Tenants: make([]pages.AdminTenant, len(list.Tenants)),
+		Search:  filter.Search,
+		Status:  string(filter.Status),
+		Total:   list.Total,
+		Limit:   list.Limit,
+		Offset:  list.Offset,
+	}
+	for i, tenant := range list.Tenants {
+		data.Tenants[i] = adminTenantView(tenant)
+	}
+
+	component := pages.AdminTenants(data)
+	component.Render(r.Context(), w)
+}
+
+// CreateTenant handles POST /admin/tenants. When owner_user_id is given, the user
+// becomes the tenant's first member with TENANT_SUPER.
 func (ar *AdminRouter) CreateTenant(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("Create new tenant"))
+	req, ok := decodeTenantRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tenant := &tenantservice.Tenant{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+	}
+
+	var err error
+	if req.OwnerUserID != nil {
+		tenant, err = ar.tenantService.CreateTenantWithOwner(r.Context(), tenant, *req.OwnerUserID)
+	} else {
+		tenant, err = ar.tenantService.CreateTenant(r.Context(), tenant)
+	}
+	if err != nil {
+		writeTenantError(w, err, "Failed to create tenant")
+		return
+	}
+
+	log.Printf("[INFO] Tenant %s (ID: %d) created", tenant.Name, tenant.ID)
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Redirect", fmt.Sprintf("/admin/tenants/%d/view", tenant.ID))
+	}
+	w.Header().Set("Location", fmt.Sprintf("/admin/tenants/%d", tenant.ID))
+	writeJSON(w, http.StatusCreated, tenant)
 }
 
-// GetTenant gets a tenant
+// GetTenant handles GET /admin/tenants/{tenantID}
 func (ar *AdminRouter) GetTenant(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("Get tenant details"))
+	tenant, ok := ar.requireTenant(w, r)
+	if !ok {
+		return
+	}
+
+	custommw.SetLastModified(w, tenant.UpdatedAt)
+	writeJSON(w, http.StatusOK, tenant)
 }
 
-// UpdateTenant updates a tenant
+// TenantPage handles GET /admin/tenants/{tenantID}/view and renders the tenant detail page
+func (ar *AdminRouter) TenantPage(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := ar.requireTenant(w, r)
+	if !ok {
+		return
+	}
+
+	component := pages.AdminTenantDetail(pages.AdminTenantPageData{Tenant: adminTenantView(*tenant)})
+	component.Render(r.Context(), w)
+}
+
+// UpdateTenant handles PUT /admin/tenants/{tenantID}, updating the name and
+// description. The slug is kept, since it is part of the tenant's URLs.
 func (ar *AdminRouter) UpdateTenant(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("Update tenant"))
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	req, ok := decodeTenantRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := ar.tenantService.UpdateTenant(r.Context(), &tenantservice.Tenant{
+		ID:          tenantID,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeTenantError(w, err, "Failed to update tenant")
+		return
+	}
+
+	tenant, err := ar.tenantService.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		writeTenantError(w, err, "Failed to get tenant")
+		return
+	}
+
+	log.Printf("[INFO] Tenant ID %d updated", tenantID)
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Refresh", "true")
+	}
+	writeJSON(w, http.StatusOK, tenant)
 }
 
-// DeleteTenant deletes a tenant
+// DeleteTenant handles DELETE /admin/tenants/{tenantID}. The tenant is soft deleted
+// and can be restored until its retention window expires.
 func (ar *AdminRouter) DeleteTenant(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("Delete tenant"))
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	if err := ar.tenantService.DeleteTenant(r.Context(), tenantID); err != nil {
+		writeTenantError(w, err, "Failed to delete tenant")
+		return
+	}
+
+	log.Printf("[INFO] Tenant ID %d deleted", tenantID)
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Redirect", "/admin/tenants/view")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireTenant loads the tenant named by the tenantID URL parameter, writing an
+// error response if it is invalid or doesn't exist
+func (ar *AdminRouter) requireTenant(w http.ResponseWriter, r *http.Request) (*tenantservice.Tenant, bool) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return nil, false
+	}
+
+	tenant, err := ar.tenantService.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		writeTenantError(w, err, "Failed to get tenant")
+		return nil, false
+	}
+
+	return tenant, true
+}
+
+// SuspendTenant handles POST /admin/tenants/{tenantID}/suspend
+func (ar *AdminRouter) SuspendTenant(w http.ResponseWriter, r *http.Request) {
+	ar.changeTenantStatus(w, r, "suspend", ar.tenantService.SuspendTenant)
+}
+
+// ResumeTenant handles POST /admin/tenants/{tenantID}/resume
+func (ar *AdminRouter) ResumeTenant(w http.ResponseWriter, r *http.Request) {
+	ar.changeTenantStatus(w, r, "resume", ar.tenantService.ResumeTenant)
+}
+
+// RestoreTenant handles POST /admin/tenants/{tenantID}/restore
+func (ar *AdminRouter) RestoreTenant(w http.ResponseWriter, r *http.Request) {
+	ar.changeTenantStatus(w, r, "restore", ar.tenantService.RestoreTenant)
+}
+
+// changeTenantStatus applies a tenant status change and responds with the updated tenant
+func (ar *AdminRouter) changeTenantStatus(w http.ResponseWriter, r *http.Request, operation string, change func(ctx context.Context, tenantID int64) error) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	if err := change(r.Context(), tenantID); err != nil {
+		switch {
+		case errors.Is(err, tenantservice.ErrTenantNotFound):
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+		case errors.Is(err, tenantservice.ErrInvalidTenantStatus):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("[ERROR] Failed to %s tenant ID %d: %v", operation, tenantID, err)
+			http.Error(w, "Failed to update tenant status", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	tenant, err := ar.tenantService.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get tenant", http.StatusInternalServerError)
+		return
+	}
+
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Refresh", "true")
+	}
+	writeJSON(w, http.StatusOK, tenant)
+}
+
+// parseTenantFilter parses the tenant list query parameters, writing a 400 response
+// if any are invalid
+func parseTenantFilter(w http.ResponseWriter, r *http.Request) (tenantservice.TenantFilter, bool) {
+	query := r.URL.Query()
+	filter := tenantservice.TenantFilter{
+		Search: strings.TrimSpace(query.Get("search")),
+		Status: tenantservice.TenantStatus(query.Get("status")),
+	}
+
+	if value := query.Get("created_from"); value != "" {
+		from, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			http.Error(w, "Invalid created_from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.CreatedFrom = &from
+	}
+
+	if value := query.Get("created_to"); value != "" {
+		to, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			http.Error(w, "Invalid created_to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return filter, false
+		}
+		// The range includes the whole of the last day
+		to = to.AddDate(0, 0, 1)
+		filter.CreatedTo = &to
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.Limit = limit
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.Offset = offset
+	}
+
+	return filter, true
+}
+
+// decodeTenantRequest parses and validates a tenant request from a JSON body or a
+// form submitted by the admin pages
+func decodeTenantRequest(w http.ResponseWriter, r *http.Request) (tenantRequest, bool) {
+	var req tenantRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return req, false
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form submission", http.StatusBadRequest)
+			return req, false
+		}
+		req.Name = r.FormValue("name")
+		req.Slug = r.FormValue("slug")
+		req.Description = r.FormValue("description")
+		if value := r.FormValue("owner_user_id"); value != "" {
+			ownerID, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				http.Error(w, "Invalid owner user ID", http.StatusBadRequest)
+				return req, false
+			}
+			req.OwnerUserID = &ownerID
+		}
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Slug = strings.TrimSpace(req.Slug)
+	req.Description = strings.TrimSpace(req.Description)
+
+	errs := validate.Struct(&req)
+	if req.Slug != "" {
+		if err := tenantservice.ValidateSlug(strings.ToLower(req.Slug)); err != nil {
+			errs.Add("slug", strings.TrimPrefix(err.Error(), tenantservice.ErrInvalidInput.Error()+": slug "))
+		}
+	}
+	if errs != nil {
+		writeInvalidFields(w, r, errs)
+		return req, false
+	}
+
+	return req, true
+}
+
+// writeTenantError maps tenant service errors to HTTP responses
+func writeTenantError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, tenantservice.ErrTenantNotFound):
+		http.Error(w, "Tenant not found", http.StatusNotFound)
+	case errors.Is(err, tenantservice.ErrTenantExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, tenantservice.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[ERROR] %s: %v", message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// adminTenantView converts a tenant to its admin page view model
+func adminTenantView(tenant tenantservice.Tenant) pages.AdminTenant {
+	return pages.AdminTenant{
+		ID:          tenant.ID,
+		Name:        tenant.Name,
+		Slug:        tenant.Slug,
+		Status:      string(tenant.Status),
+		Description: tenant.Description,
+		CreatedAt:   tenant.CreatedAt,
+		UpdatedAt:   tenant.UpdatedAt,
+	}
+}
+
+// isHTMXRequest reports whether the request was made by htmx from one of the pages
+func isHTMXRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
 }
 
 // ListUsers lists all users
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// APIKeyRouter handles tenant API key routes
+type APIKeyRouter struct {
+	apiKeyService tenantservice.APIKeyService
+}
+
+// NewAPIKeyRouter creates a new APIKeyRouter with the required dependencies
+func NewAPIKeyRouter(apiKeyService tenantservice.APIKeyService) *APIKeyRouter {
+	return &APIKeyRouter{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// apiKeyRequest is the request body for creating an API key
+type apiKeyRequest struct {
+	Name string `json:"name"`
+	// Scopes are "resource:action" permissions such as "orders:read"
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// apiKeyResponse is the response body for a newly created API key
+type apiKeyResponse struct {
+	tenantservice.APIKey
+	// Key is only returned when the key is created
+	Key string `json:"key"`
+}
+
+// ListAPIKeys handles GET /tenant/api-keys
+func (ar *APIKeyRouter) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	apiKeys, err := ar.apiKeyService.ListAPIKeys(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list API keys for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to list API keys", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiKeys)
+}
+
+// CreateAPIKey handles POST /tenant/api-keys. The key acts as the requesting user,
+// limited to the requested scopes.
+func (ar *APIKeyRouter) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req apiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	apiKey, key, err := ar.apiKeyService.CreateAPIKey(r.Context(), tenantID, userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to create API key for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, apiKeyResponse{APIKey: *apiKey, Key: key})
+}
+
+// RevokeAPIKey handles DELETE /tenant/api-keys/{keyID}
+func (ar *APIKeyRouter) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	keyID, ok := parseIDParam(w, r, "keyID", "Invalid API key ID")
+	if !ok {
+		return
+	}
+
+	if err := ar.apiKeyService.RevokeAPIKey(r.Context(), tenantID, keyID); err != nil {
+		if errors.Is(err, tenantservice.ErrAPIKeyNotFound) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to revoke API key %d for tenant ID %d: %v", keyID, tenantID, err)
+		http.Error(w, "Failed to revoke API key", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
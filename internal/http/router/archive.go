@@ -0,0 +1,86 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// ArchiveRouter handles archiving tenants to object storage from the admin routes
+type ArchiveRouter struct {
+	archiveService tenantservice.ArchiveService
+}
+
+// NewArchiveRouter creates a new ArchiveRouter with the required dependencies
+func NewArchiveRouter(archiveService tenantservice.ArchiveService) *ArchiveRouter {
+	return &ArchiveRouter{
+		archiveService: archiveService,
+	}
+}
+
+// ArchiveTenant handles POST /admin/tenants/{tenantID}/archive, moving the tenant's
+// data to object storage and making the tenant read-only
+func (ar *ArchiveRouter) ArchiveTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	archive, err := ar.archiveService.ArchiveTenant(r.Context(), tenantID)
+	if err != nil {
+		writeArchiveError(w, err, "Failed to archive tenant")
+		return
+	}
+
+	log.Printf("[INFO] Tenant %d archived to %s", tenantID, archive.StorageKey)
+	writeJSON(w, http.StatusCreated, archive)
+}
+
+// RestoreArchivedTenant handles POST /admin/tenants/{tenantID}/archive/restore,
+// re-importing the tenant's latest archive and making the tenant active again
+func (ar *ArchiveRouter) RestoreArchivedTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	archive, err := ar.archiveService.RestoreArchivedTenant(r.Context(), tenantID)
+	if err != nil {
+		writeArchiveError(w, err, "Failed to restore archived tenant")
+		return
+	}
+
+	log.Printf("[INFO] Tenant %d restored from %s", tenantID, archive.StorageKey)
+	writeJSON(w, http.StatusOK, archive)
+}
+
+// ListArchives handles GET /admin/tenants/{tenantID}/archives
+func (ar *ArchiveRouter) ListArchives(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	archives, err := ar.archiveService.ListArchives(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list archives of tenant %d: %v", tenantID, err)
+		http.Error(w, "Failed to list archives", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, archives)
+}
+
+// writeArchiveError responds with the HTTP status matching an archive error
+func writeArchiveError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, tenantservice.ErrInvalidTenantStatus):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, tenantservice.ErrArchiveNotFound):
+		http.Error(w, "Archive not found", http.StatusNotFound)
+	default:
+		writeTenantError(w, err, message)
+	}
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+)
+
+// AuditRouter handles tenant audit log routes
+type AuditRouter struct {
+	auditService auditservice.AuditService
+}
+
+// NewAuditRouter creates a new AuditRouter with the required dependencies
+func NewAuditRouter(auditService auditservice.AuditService) *AuditRouter {
+	return &AuditRouter{
+		auditService: auditService,
+	}
+}
+
+// ListAuditEntries handles GET /tenant/audit. Entries can be filtered by actor_id,
+// action, resource_type, resource_id and an RFC 3339 from/to range, and paged with
+// limit and offset.
+func (ar *AuditRouter) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := auditservice.AuditFilter{
+		TenantID:     tenantID,
+		Action:       query.Get("action"),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+	}
+
+	if filter.ActorUserID, ok = parseOptionalIDQuery(w, r, "actor_id", "Invalid actor ID"); !ok {
+		return
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid from time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+		filter.From = &from
+	}
+
+	if value := query.Get("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid to time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+		filter.To = &to
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+		filter.Offset = offset
+	}
+
+	entries, err := ar.auditService.ListEntries(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, auditservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to list audit entries for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to list audit entries", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/unsavory/silocore-go/internal/auth/jwt"
 	"github.com/unsavory/silocore-go/internal/auth/service"
+	"github.com/unsavory/silocore-go/internal/http/validate"
 	"github.com/unsavory/silocore-go/internal/views/pages"
 )
 
@@ -101,15 +102,7 @@ func (ar *AuthRouter) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[INFO] Successfully authenticated user: %s (ID: %d)", email, userID)
 
 	// Set the token as a cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "auth_token",
-		Value:    tokenString,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   r.TLS != nil,
-		SameSite: http.SameSiteStrictMode,
-		Expires:  time.Now().Add(24 * time.Hour),
-	})
+	setAuthCookie(w, r, tokenString)
 	log.Printf("[DEBUG] Set auth_token cookie for user %s, expires in 24 hours", email)
 
 	// Redirect to orders page instead of home page
@@ -120,7 +113,10 @@ func (ar *AuthRouter) HandleLogin(w http.ResponseWriter, r *http.Request) {
 // RegisterPage renders the registration page
 func (ar *AuthRouter) RegisterPage(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[DEBUG] Rendering registration page: %s", r.URL.String())
-	data := pages.RegisterData{}
+	data := pages.RegisterData{
+		// Registration links sent with tenant invitations carry the invitation token
+		InvitationToken: r.URL.Query().Get("invite"),
+	}
 	component := pages.Register(data)
 	component.Render(r.Context(), w)
 }
@@ -148,35 +144,22 @@ func (ar *AuthRouter) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("[DEBUG] Registration form values: %+v", formValues)
 
-	firstName := strings.TrimSpace(r.FormValue("first_name"))
-	lastName := strings.TrimSpace(r.FormValue("last_name"))
-	email := strings.TrimSpace(r.FormValue("email"))
-	password := r.FormValue("password")                // Don't log passwords
-	confirmPassword := r.FormValue("confirm_password") // Don't log passwords
+	form := registerForm{
+		FirstName:       strings.TrimSpace(r.FormValue("first_name")),
+		LastName:        strings.TrimSpace(r.FormValue("last_name")),
+		Email:           strings.TrimSpace(r.FormValue("email")),
+		Password:        r.FormValue("password"),         // Don't log passwords
+		ConfirmPassword: r.FormValue("confirm_password"), // Don't log passwords
+	}
+	invitationToken := r.FormValue("invitation_token")
 
 	// Log extracted values (except passwords)
-	log.Printf("[DEBUG] Registration attempt - firstName: %s, lastName: %s, email: %s", firstName, lastName, email)
+	log.Printf("[DEBUG] Registration attempt - firstName: %s, lastName: %s, email: %s", form.FirstName, form.LastName, form.Email)
 
 	// Validate inputs
-	if firstName == "" || lastName == "" || email == "" || password == "" || confirmPassword == "" {
-		log.Printf("[WARN] Registration attempt with missing required fields")
-		data := pages.RegisterData{Error: "All fields are required"}
-		component := pages.Register(data)
-		component.Render(r.Context(), w)
-		return
-	}
-
-	if len(password) < 8 {
-		log.Printf("[WARN] Registration attempt with password too short for email: %s", email)
-		data := pages.RegisterData{Error: "Password must be at least 8 characters"}
-		component := pages.Register(data)
-		component.Render(r.Context(), w)
-		return
-	}
-
-	if password != confirmPassword {
-		log.Printf("[WARN] Registration attempt with mismatched passwords for email: %s", email)
-		data := pages.RegisterData{Error: "Passwords do not match"}
+	if errs := validate.Struct(form); errs != nil {
+		log.Printf("[WARN] Registration attempt with invalid fields for email %s: %v", form.Email, errs)
+		data := pages.RegisterData{InvitationToken: invitationToken, Error: "Please correct the fields below", FieldErrors: registerFieldErrors(errs)}
 		component := pages.Register(data)
 		component.Render(r.Context(), w)
 		return
@@ -185,7 +168,7 @@ func (ar *AuthRouter) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	// Check if the auth service is available
 	if ar.registrationService == nil {
 		log.Printf("[ERROR] Registration service not available for registration request")
-		data := pages.RegisterData{Error: "Registration service unavailable"}
+		data := pages.RegisterData{InvitationToken: invitationToken, Error: "Registration service unavailable"}
 		component := pages.Register(data)
 		component.Render(r.Context(), w)
 		return
@@ -195,24 +178,52 @@ func (ar *AuthRouter) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Attempt to register the user
-	err := ar.registerUser(ctx, firstName, lastName, email, password)
+	err := ar.registerUser(ctx, form.FirstName, form.LastName, form.Email, form.Password, invitationToken)
 	if err != nil {
-		log.Printf("[ERROR] Failed to register user %s: %v", email, err)
-		data := pages.RegisterData{Error: "Failed to register user: " + err.Error()}
+		log.Printf("[ERROR] Failed to register user %s: %v", form.Email, err)
+		data := pages.RegisterData{InvitationToken: invitationToken, Error: "Failed to register user: " + err.Error()}
 		component := pages.Register(data)
 		component.Render(r.Context(), w)
 		return
 	}
 
-	log.Printf("[INFO] Successfully registered new user: %s", email)
+	log.Printf("[INFO] Successfully registered new user: %s", form.Email)
 
 	// Redirect to login page with success message
-	log.Printf("[DEBUG] Redirecting newly registered user %s to login page", email)
+	log.Printf("[DEBUG] Redirecting newly registered user %s to login page", form.Email)
 	http.Redirect(w, r, "/login?message=Registration+successful!+You+can+now+log+in.", http.StatusSeeOther)
 }
 
+// registerForm is the registration form
+type registerForm struct {
+	FirstName       string `form:"first_name" validate:"required,max=255"`
+	LastName        string `form:"last_name" validate:"required,max=255"`
+	Email           string `form:"email" validate:"required,max=255,email"`
+	Password        string `form:"password" validate:"required,min=8"`
+	ConfirmPassword string `form:"confirm_password" validate:"required,eqfield=Password"`
+}
+
+// registerFieldLabels name the registration form's fields in its messages
+var registerFieldLabels = map[string]string{
+	"first_name":       "First name",
+	"last_name":        "Last name",
+	"email":            "Email",
+	"password":         "Password",
+	"confirm_password": "Confirmation",
+}
+
+// registerFieldErrors returns the messages shown below the invalid fields of
+// the registration form, e.g. "Password must be at least 8 characters"
+func registerFieldErrors(errs validate.Errors) map[string]string {
+	messages := make(map[string]string, len(errs))
+	for _, fieldErr := range errs {
+		messages[fieldErr.Field] = registerFieldLabels[fieldErr.Field] + " " + fieldErr.Message
+	}
+	return messages
+}
+
 // registerUser is a helper method to register a user
-func (ar *AuthRouter) registerUser(ctx context.Context, firstName, lastName, email, password string) error {
+func (ar *AuthRouter) registerUser(ctx context.Context, firstName, lastName, email, password, invitationToken string) error {
 	// Validate password
 	if err := service.ValidatePassword(password); err != nil {
 		log.Printf("[WARN] Password validation failed for email %s: %v", email, err)
@@ -221,8 +232,14 @@ func (ar *AuthRouter) registerUser(ctx context.Context, firstName, lastName, ema
 
 	log.Printf("[DEBUG] Attempting to register user with email: %s", email)
 
-	// Register the user
-	userID, err := ar.registrationService.RegisterUser(ctx, firstName, lastName, email, password)
+	// Register the user, joining the inviting tenant if an invitation was given
+	var userID int64
+	var err error
+	if invitationToken != "" {
+		userID, err = ar.registrationService.RegisterUserWithInvitation(ctx, firstName, lastName, email, password, invitationToken)
+	} else {
+		userID, err = ar.registrationService.RegisterUser(ctx, firstName, lastName, email, password)
+	}
 	if err != nil {
 		log.Printf("[ERROR] User registration failed for email %s: %v", email, err)
 		return err
@@ -253,3 +270,16 @@ func (ar *AuthRouter) HandleLogout(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[DEBUG] Redirecting logged out user to login page")
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
+
+// setAuthCookie stores an access token in the auth_token cookie read by AuthMiddleware
+func setAuthCookie(w http.ResponseWriter, r *http.Request, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+		Expires:  time.Now().Add(24 * time.Hour),
+	})
+}
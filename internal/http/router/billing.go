@@ -0,0 +1,175 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	billingservice "github.com/unsavory/silocore-go/internal/billing/service"
+)
+
+const (
+	// maxWebhookBodyBytes limits the size of Stripe webhook payloads
+	maxWebhookBodyBytes = 64 * 1024
+
+	// billingReturnPath is where users return to after checkout
+	billingReturnPath = "/tenant/billing"
+)
+
+// BillingRouter handles tenant billing routes and Stripe webhooks
+type BillingRouter struct {
+	billingService billingservice.BillingService
+}
+
+// NewBillingRouter creates a new BillingRouter with the required dependencies
+func NewBillingRouter(billingService billingservice.BillingService) *BillingRouter {
+	return &BillingRouter{
+		billingService: billingService,
+	}
+}
+
+// billingResponse is the response body for a tenant's billing status
+type billingResponse struct {
+	Subscription *billingservice.Subscription `json:"subscription"`
+	// Features are the features currently available to the tenant
+	Features []string `json:"features"`
+}
+
+// checkoutRequest is the request body for starting a checkout
+type checkoutRequest struct {
+	Plan string `json:"plan"`
+}
+
+// GetBilling handles GET /tenant/billing, reporting the tenant's subscription and available features
+func (br *BillingRouter) GetBilling(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var resp billingResponse
+
+	sub, err := br.billingService.GetSubscription(r.Context(), tenantID)
+	if err != nil && !errors.Is(err, billingservice.ErrSubscriptionNotFound) {
+		log.Printf("[ERROR] Failed to get subscription for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get subscription", http.StatusInternalServerError)
+		return
+	}
+	resp.Subscription = sub
+
+	resp.Features, err = br.billingService.GetTenantFeatures(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get features for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get tenant features", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ListPlans handles GET /tenant/billing/plans
+func (br *BillingRouter) ListPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := br.billingService.ListPlans(r.Context())
+	if err != nil {
+		log.Printf("[ERROR] Failed to list plans: %v", err)
+		http.Error(w, "Failed to list plans", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plans)
+}
+
+// CreateCheckout handles POST /tenant/billing/checkout. JSON requests receive the
+// Stripe checkout URL; browser form submissions are redirected to it.
+func (br *BillingRouter) CreateCheckout(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	isJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+
+	var req checkoutRequest
+	if isJSON {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form submission", http.StatusBadRequest)
+			return
+		}
+		req.Plan = r.FormValue("plan")
+	}
+
+	returnURL := requestBaseURL(r) + billingReturnPath
+	session, err := br.billingService.CreateCheckoutSession(r.Context(), tenantID, strings.TrimSpace(req.Plan),
+		returnURL+"?checkout=success", returnURL+"?checkout=canceled")
+	if err != nil {
+		switch {
+		case errors.Is(err, billingservice.ErrPlanNotFound):
+			http.Error(w, "Plan not found", http.StatusNotFound)
+		case errors.Is(err, billingservice.ErrInvalidInput):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, billingservice.ErrAlreadySubscribed):
+			http.Error(w, "Tenant already has an active subscription", http.StatusConflict)
+		case errors.Is(err, billingservice.ErrStripe):
+			log.Printf("[ERROR] Stripe checkout failed for tenant ID %d: %v", tenantID, err)
+			http.Error(w, "Payment provider unavailable", http.StatusBadGateway)
+		default:
+			log.Printf("[ERROR] Failed to create checkout for tenant ID %d: %v", tenantID, err)
+			http.Error(w, "Failed to create checkout", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if isJSON {
+		writeJSON(w, http.StatusOK, session)
+		return
+	}
+
+	// HTMX requests follow the HX-Redirect header instead of a redirect response
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", session.URL)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, session.URL, http.StatusSeeOther)
+}
+
+// HandleWebhook handles POST /billing/webhook, applying Stripe subscription events.
+// Failures other than invalid events return 500 so Stripe retries delivery.
+func (br *BillingRouter) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = br.billingService.HandleWebhook(r.Context(), payload, r.Header.Get("Stripe-Signature"))
+	if err != nil {
+		if errors.Is(err, billingservice.ErrInvalidSignature) || errors.Is(err, billingservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to handle Stripe webhook: %v", err)
+		http.Error(w, "Failed to handle webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// requestBaseURL returns the scheme and host the request was made to
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,176 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// logoFormField is the multipart form field logos are uploaded in
+const logoFormField = "logo"
+
+// BrandingRouter handles tenant branding routes
+type BrandingRouter struct {
+	brandingService tenantservice.BrandingService
+}
+
+// NewBrandingRouter creates a new BrandingRouter with the required dependencies
+func NewBrandingRouter(brandingService tenantservice.BrandingService) *BrandingRouter {
+	return &BrandingRouter{
+		brandingService: brandingService,
+	}
+}
+
+// brandingRequest is the request body for updating a tenant's branding
+type brandingRequest struct {
+	DisplayName  string `json:"display_name"`
+	PrimaryColor string `json:"primary_color"`
+	AccentColor  string `json:"accent_color"`
+}
+
+// GetBranding handles GET /tenant/branding
+func (br *BrandingRouter) GetBranding(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	br.writeBranding(w, r, tenantID)
+}
+
+// UpdateBranding handles PUT /tenant/branding. Empty values reset to the defaults.
+func (br *BrandingRouter) UpdateBranding(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req brandingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	branding := &tenantservice.Branding{
+		TenantID:     tenantID,
+		DisplayName:  req.DisplayName,
+		PrimaryColor: req.PrimaryColor,
+		AccentColor:  req.AccentColor,
+	}
+
+	if err := br.brandingService.UpdateBranding(r.Context(), branding); err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to update branding for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to update branding", http.StatusInternalServerError)
+		return
+	}
+
+	br.writeBranding(w, r, tenantID)
+}
+
+// GetLogo handles GET /tenant/branding/logo, serving the tenant's logo image
+func (br *BrandingRouter) GetLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	logo, err := br.brandingService.GetLogo(r.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrLogoNotFound) {
+			http.Error(w, "Logo not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to get logo for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get logo", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", logo.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(logo.Data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Logo URLs are versioned, but the logo is private to the tenant's members
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Write(logo.Data)
+}
+
+// UploadLogo handles POST /tenant/branding/logo with the image in the "logo" multipart field.
+// The image type is detected from its content rather than trusted from the client.
+func (br *BrandingRouter) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	// Allow for multipart overhead on top of the logo itself
+	r.Body = http.MaxBytesReader(w, r.Body, tenantservice.MaxLogoBytes+64*1024)
+	file, _, err := r.FormFile(logoFormField)
+	if err != nil {
+		http.Error(w, "Logo file is required and must be at most 512 KB", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, tenantservice.MaxLogoBytes+1))
+	if err != nil {
+		http.Error(w, "Failed to read logo", http.StatusBadRequest)
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	if err := br.brandingService.SetLogo(r.Context(), tenantID, contentType, data); err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to set logo for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to upload logo", http.StatusInternalServerError)
+		return
+	}
+
+	br.writeBranding(w, r, tenantID)
+}
+
+// DeleteLogo handles DELETE /tenant/branding/logo
+func (br *BrandingRouter) DeleteLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := br.brandingService.DeleteLogo(r.Context(), tenantID); err != nil {
+		if errors.Is(err, tenantservice.ErrLogoNotFound) {
+			http.Error(w, "Logo not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to delete logo for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to delete logo", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeBranding responds with a tenant's branding
+func (br *BrandingRouter) writeBranding(w http.ResponseWriter, r *http.Request, tenantID int64) {
+	branding, err := br.brandingService.GetBranding(r.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to get branding for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get branding", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, branding)
+}
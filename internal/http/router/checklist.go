@@ -0,0 +1,74 @@
+package router
+
+import (
+	"log"
+	"net/http"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/views/components"
+)
+
+// ChecklistRouter handles the tenant onboarding checklist routes
+type ChecklistRouter struct {
+	onboardingService tenantservice.OnboardingService
+}
+
+// NewChecklistRouter creates a new ChecklistRouter with the required dependencies
+func NewChecklistRouter(onboardingService tenantservice.OnboardingService) *ChecklistRouter {
+	return &ChecklistRouter{
+		onboardingService: onboardingService,
+	}
+}
+
+// GetOnboarding handles GET /tenant/onboarding. HTMX requests receive the
+// dashboard checklist; other requests receive the checklist as JSON.
+func (cr *ChecklistRouter) GetOnboarding(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	onboarding, err := cr.onboardingService.GetOnboarding(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get onboarding of tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get onboarding checklist", http.StatusInternalServerError)
+		return
+	}
+
+	cr.writeOnboarding(w, r, onboarding)
+}
+
+// DismissOnboarding handles POST /tenant/onboarding/dismiss, hiding the checklist
+func (cr *ChecklistRouter) DismissOnboarding(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	onboarding, err := cr.onboardingService.DismissOnboarding(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to dismiss onboarding of tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to dismiss onboarding checklist", http.StatusInternalServerError)
+		return
+	}
+
+	cr.writeOnboarding(w, r, onboarding)
+}
+
+// writeOnboarding responds with the checklist component for HTMX requests and JSON otherwise
+func (cr *ChecklistRouter) writeOnboarding(w http.ResponseWriter, r *http.Request, onboarding *tenantservice.Onboarding) {
+	if !isHTMXRequest(r) {
+		writeJSON(w, http.StatusOK, onboarding)
+		return
+	}
+
+	items := make([]components.OnboardingItem, 0, len(onboarding.Tasks))
+	for _, task := range onboarding.Tasks {
+		items = append(items, components.OnboardingItem{Title: task.Title, Done: task.Done})
+	}
+
+	visible := onboarding.Status == tenantservice.OnboardingStatusNotStarted || onboarding.Status == tenantservice.OnboardingStatusInProgress
+	if err := components.OnboardingChecklist(items, onboarding.Done, visible).Render(r.Context(), w); err != nil {
+		log.Printf("[ERROR] Failed to render onboarding checklist: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// CloneRouter handles cloning tenants from the admin routes
+type CloneRouter struct {
+	cloneService tenantservice.CloneService
+}
+
+// NewCloneRouter creates a new CloneRouter with the required dependencies
+func NewCloneRouter(cloneService tenantservice.CloneService) *CloneRouter {
+	return &CloneRouter{
+		cloneService: cloneService,
+	}
+}
+
+// cloneTenantRequest is the request body for cloning a tenant
+type cloneTenantRequest struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	// Description defaults to the source tenant's description
+	Description string `json:"description"`
+	tenantservice.CloneOptions
+}
+
+// CloneTenant handles POST /admin/tenants/{tenantID}/clone, creating a tenant with
+// the configuration of the tenant in the URL
+func (cr *CloneRouter) CloneTenant(w http.ResponseWriter, r *http.Request) {
+	sourceTenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	var req cloneTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	target := &tenantservice.Tenant{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        strings.TrimSpace(req.Slug),
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	report, err := cr.cloneService.CloneTenant(r.Context(), sourceTenantID, target, req.CloneOptions)
+	if err != nil {
+		writeTenantError(w, err, "Failed to clone tenant")
+		return
+	}
+
+	log.Printf("[INFO] Tenant %d cloned into %s (ID: %d): %v", sourceTenantID, report.Tenant.Name, report.Tenant.ID, report.Rows)
+	w.Header().Set("Location", fmt.Sprintf("/admin/tenants/%d", report.Tenant.ID))
+	writeJSON(w, http.StatusCreated, report)
+}
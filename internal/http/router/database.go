@@ -0,0 +1,44 @@
+package router
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+// DatabaseRouter handles database monitoring routes
+type DatabaseRouter struct {
+	db *sql.DB
+}
+
+// NewDatabaseRouter creates a new DatabaseRouter with the required dependencies
+func NewDatabaseRouter(db *sql.DB) *DatabaseRouter {
+	return &DatabaseRouter{
+		db: db,
+	}
+}
+
+// GetPoolStats handles GET /admin/database/pool, reporting the connection pool's
+// open, in use and idle connections and how often requests waited for one
+func (dr *DatabaseRouter) GetPoolStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, database.Stats(dr.db))
+}
+
+// QueryMetricsRouter handles query monitoring routes
+type QueryMetricsRouter struct {
+	metrics *database.QueryMetrics
+}
+
+// NewQueryMetricsRouter creates a new QueryMetricsRouter with the required dependencies
+func NewQueryMetricsRouter(metrics *database.QueryMetrics) *QueryMetricsRouter {
+	return &QueryMetricsRouter{
+		metrics: metrics,
+	}
+}
+
+// GetQueryStats handles GET /admin/database/queries, reporting query counts,
+// errors and duration histograms by operation and how many queries were slow
+func (qr *QueryMetricsRouter) GetQueryStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, qr.metrics.Stats())
+}
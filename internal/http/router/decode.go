@@ -0,0 +1,41 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	"github.com/unsavory/silocore-go/internal/http/validate"
+)
+
+// decodeJSON decodes a JSON request body into v and validates it, writing 400 Bad
+// Request and returning false if the body is malformed or has invalid fields
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid request body"))
+		return false
+	}
+	return validRequest(w, r, v)
+}
+
+// validRequest checks v against the rules of its validate tags, writing 400 Bad
+// Request listing every invalid field and returning false if any are invalid
+func validRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if errs := validate.Struct(v); errs != nil {
+		writeInvalidFields(w, r, errs)
+		return false
+	}
+	return true
+}
+
+// writeInvalidFields writes 400 Bad Request listing the invalid fields of a
+// request: as a JSON error for JSON requests, or as text for the forms of the
+// pages, which show the response as it is
+func writeInvalidFields(w http.ResponseWriter, r *http.Request, errs validate.Errors) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		apierror.Write(w, r, apierror.InvalidFields(errs))
+		return
+	}
+	http.Error(w, errs.Error(), http.StatusBadRequest)
+}
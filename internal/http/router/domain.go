@@ -0,0 +1,185 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// DomainRouter handles tenant custom domain routes
+type DomainRouter struct {
+	domainService tenantservice.DomainService
+}
+
+// NewDomainRouter creates a new DomainRouter with the required dependencies
+func NewDomainRouter(domainService tenantservice.DomainService) *DomainRouter {
+	return &DomainRouter{
+		domainService: domainService,
+	}
+}
+
+// addDomainRequest is the request body for adding a custom domain
+type addDomainRequest struct {
+	Domain string `json:"domain"`
+}
+
+// verificationRecord is the DNS record that proves a tenant controls a domain
+type verificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// domainResponse is the response body for a custom domain
+type domainResponse struct {
+	tenantservice.TenantDomain
+	Verified bool `json:"verified"`
+	// VerificationRecord is the TXT record to create before verifying the domain
+	VerificationRecord verificationRecord `json:"verification_record"`
+}
+
+// newDomainResponse creates the response body for a custom domain
+func newDomainResponse(domain tenantservice.TenantDomain) domainResponse {
+	return domainResponse{
+		TenantDomain: domain,
+		Verified:     domain.Verified(),
+		VerificationRecord: verificationRecord{
+			Type:  "TXT",
+			Name:  domain.VerificationRecordName(),
+			Value: domain.VerificationRecordValue(),
+		},
+	}
+}
+
+// ListDomains handles GET /tenant/domains
+func (dr *DomainRouter) ListDomains(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	domains, err := dr.domainService.ListDomains(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list domains for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to list domains", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]domainResponse, 0, len(domains))
+	for _, domain := range domains {
+		response = append(response, newDomainResponse(domain))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// AddDomain handles POST /tenant/domains
+func (dr *DomainRouter) AddDomain(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req addDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	domain, err := dr.domainService.AddDomain(r.Context(), tenantID, req.Domain)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to add domain for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to add domain", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/tenant/domains/%d", domain.ID))
+	writeJSON(w, http.StatusCreated, newDomainResponse(*domain))
+}
+
+// VerifyDomain handles POST /tenant/domains/{domainID}/verify
+func (dr *DomainRouter) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	domainID, ok := parseIDParam(w, r, "domainID", "Invalid domain ID")
+	if !ok {
+		return
+	}
+
+	domain, err := dr.domainService.VerifyDomain(r.Context(), tenantID, domainID)
+	if err != nil {
+		switch {
+		case errors.Is(err, tenantservice.ErrDomainNotFound):
+			http.Error(w, "Domain not found", http.StatusNotFound)
+		case errors.Is(err, tenantservice.ErrDomainNotVerified):
+			http.Error(w, "Verification TXT record not found", http.StatusUnprocessableEntity)
+		case errors.Is(err, tenantservice.ErrDomainTaken):
+			http.Error(w, "Domain is already in use by another tenant", http.StatusConflict)
+		default:
+			log.Printf("[ERROR] Failed to verify domain %d for tenant ID %d: %v", domainID, tenantID, err)
+			http.Error(w, "Failed to verify domain", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newDomainResponse(*domain))
+}
+
+// RemoveDomain handles DELETE /tenant/domains/{domainID}
+func (dr *DomainRouter) RemoveDomain(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	domainID, ok := parseIDParam(w, r, "domainID", "Invalid domain ID")
+	if !ok {
+		return
+	}
+
+	if err := dr.domainService.RemoveDomain(r.Context(), tenantID, domainID); err != nil {
+		if errors.Is(err, tenantservice.ErrDomainNotFound) {
+			http.Error(w, "Domain not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to remove domain %d for tenant ID %d: %v", domainID, tenantID, err)
+		http.Error(w, "Failed to remove domain", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CheckDomain handles GET /domains/check?domain=..., answering 200 OK for verified
+// custom domains and 404 Not Found otherwise. TLS proxies that issue certificates on
+// demand ask it before requesting a certificate for a domain.
+func (dr *DomainRouter) CheckDomain(w http.ResponseWriter, r *http.Request) {
+	domain := r.URL.Query().Get("domain")
+	if domain == "" {
+		http.Error(w, "Missing domain", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := dr.domainService.GetTenantIDByDomain(r.Context(), domain); err != nil {
+		if errors.Is(err, tenantservice.ErrDomainNotFound) {
+			http.Error(w, "Domain not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to check domain %s: %v", domain, err)
+		http.Error(w, "Failed to check domain", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// Error codes of the auth and tenant routes
+const (
+	codeRoleNotFound    = "role_not_found"
+	codeRoleConflict    = "role_conflict"
+	codeMemberNotFound  = "member_not_found"
+	codeNotTenantMember = "not_tenant_member"
+)
+
+var (
+	// errMemberNotFound is the error of members outside the request's tenant
+	errMemberNotFound = apierror.New(http.StatusNotFound, codeMemberNotFound, "Member not found")
+
+	// errNotTenantMember is the error of users switching to a tenant they don't belong to
+	errNotTenantMember = apierror.New(http.StatusForbidden, codeNotTenantMember, "Not a member of this tenant")
+)
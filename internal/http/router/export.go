@@ -0,0 +1,132 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// ExportRouter handles tenant data export routes
+type ExportRouter struct {
+	exportService tenantservice.ExportService
+}
+
+// NewExportRouter creates a new ExportRouter with the required dependencies
+func NewExportRouter(exportService tenantservice.ExportService) *ExportRouter {
+	return &ExportRouter{
+		exportService: exportService,
+	}
+}
+
+// RequestExport handles POST /tenant/export, starting an export of the tenant's data.
+// The export is generated in the background; poll its status for the download link.
+func (er *ExportRouter) RequestExport(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	export, err := er.exportService.RequestExport(r.Context(), tenantID, userID)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrExportInProgress) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("[ERROR] Failed to request export for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to request export", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/tenant/export/%d", export.ID))
+	writeJSON(w, http.StatusAccepted, export)
+}
+
+// ListExports handles GET /tenant/export
+func (er *ExportRouter) ListExports(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	exports, err := er.exportService.ListExports(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list exports for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to list exports", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, exports)
+}
+
+// GetExport handles GET /tenant/export/{exportID}, reporting progress and the
+// download link once the export is completed
+func (er *ExportRouter) GetExport(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	exportID, ok := parseIDParam(w, r, "exportID", "Invalid export ID")
+	if !ok {
+		return
+	}
+
+	export, err := er.exportService.GetExport(r.Context(), tenantID, exportID)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrExportNotFound) {
+			http.Error(w, "Export not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to get export %d for tenant ID %d: %v", exportID, tenantID, err)
+		http.Error(w, "Failed to get export", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, export)
+}
+
+// DownloadExport handles GET /exports/{exportID}/download. The link is authenticated
+// by its signature, so it works without a session until it expires.
+func (er *ExportRouter) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	exportID, ok := parseIDParam(w, r, "exportID", "Invalid export ID")
+	if !ok {
+		return
+	}
+
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid download link", http.StatusBadRequest)
+		return
+	}
+
+	archive, err := er.exportService.OpenDownload(r.Context(), exportID, expires, r.URL.Query().Get("signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, tenantservice.ErrInvalidDownloadLink):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, tenantservice.ErrExportNotFound):
+			http.Error(w, "Export not found", http.StatusNotFound)
+		default:
+			log.Printf("[ERROR] Failed to download export %d: %v", exportID, err)
+			http.Error(w, "Failed to download export", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", archive.Filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive.Data)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(archive.Data)
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthCheckTimeout is how long a readiness check waits for a dependency
+const HealthCheckTimeout = 2 * time.Second
+
+// Health statuses
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// HealthCheck checks that a dependency needed to serve requests is reachable
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// ComponentHealth is the outcome of a dependency's health check
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport is the readiness of the application and its dependencies
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// HealthRouter handles liveness and readiness checks
+type HealthRouter struct {
+	checks  []HealthCheck
+	timeout time.Duration
+}
+
+// NewHealthRouter creates a new HealthRouter checking the given dependencies
+func NewHealthRouter(checks ...HealthCheck) *HealthRouter {
+	return &HealthRouter{
+		checks:  checks,
+		timeout: HealthCheckTimeout,
+	}
+}
+
+// Live handles GET /health/live, reporting that the process is serving requests
+// without checking its dependencies
+func (hr *HealthRouter) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": HealthStatusUp})
+}
+
+// Ready handles GET /health/ready, checking the dependencies concurrently. It
+// responds with 503 if any of them is down, so load balancers stop sending the
+// instance requests until they recover.
+func (hr *HealthRouter) Ready(w http.ResponseWriter, r *http.Request) {
+	report := hr.check(r.Context())
+
+	status := http.StatusOK
+	if report.Status != HealthStatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// check runs the health checks, each with its own timeout
+func (hr *HealthRouter) check(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Status:     HealthStatusUp,
+		Components: make(map[string]ComponentHealth, len(hr.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range hr.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, hr.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Check(ctx)
+			component := ComponentHealth{
+				Status:    HealthStatusUp,
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				component.Status = HealthStatusDown
+				component.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[check.Name] = component
+			if err != nil {
+				report.Status = HealthStatusDown
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
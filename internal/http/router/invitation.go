@@ -0,0 +1,129 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// InvitationRouter handles tenant invitation routes
+type InvitationRouter struct {
+	invitationService tenantservice.InvitationService
+}
+
+// NewInvitationRouter creates a new InvitationRouter with the required dependencies
+func NewInvitationRouter(invitationService tenantservice.InvitationService) *InvitationRouter {
+	return &InvitationRouter{
+		invitationService: invitationService,
+	}
+}
+
+// invitationRequest is the request body for inviting a user to a tenant
+type invitationRequest struct {
+	Email  string `json:"email" validate:"required,email"`
+	RoleID *int64 `json:"role_id,omitempty" validate:"omitempty,min=1"`
+}
+
+// invitationResponse is the response body for a newly created invitation
+type invitationResponse struct {
+	tenantservice.Invitation
+	// RegistrationURL is the link the invited user registers with
+	RegistrationURL string `json:"registration_url"`
+}
+
+// ListInvitations handles GET /tenant/members/invitations
+func (ir *InvitationRouter) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	invitations, err := ir.invitationService.ListInvitations(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list invitations for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to list invitations", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, invitations)
+}
+
+// CreateInvitation handles POST /tenant/members/invitations
+func (ir *InvitationRouter) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req invitationRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	invitation, token, err := ir.invitationService.CreateInvitation(r.Context(), tenantID, req.Email, req.RoleID, userID)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, tenantservice.ErrQuotaExceeded) {
+			apierror.Write(w, r, apierror.New(http.StatusPaymentRequired, apierror.CodeQuotaExceeded, err.Error()))
+			return
+		}
+		log.Printf("[ERROR] Failed to create invitation for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to create invitation", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, invitationResponse{
+		Invitation:      *invitation,
+		RegistrationURL: "/register?invite=" + url.QueryEscape(token),
+	})
+}
+
+// RevokeInvitation handles DELETE /tenant/members/invitations/{invitationID}
+func (ir *InvitationRouter) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	invitationID, ok := parseIDParam(w, r, "invitationID", "Invalid invitation ID")
+	if !ok {
+		return
+	}
+
+	if err := ir.invitationService.RevokeInvitation(r.Context(), tenantID, invitationID); err != nil {
+		if errors.Is(err, tenantservice.ErrInvitationNotFound) {
+			http.Error(w, "Invitation not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to revoke invitation %d for tenant ID %d: %v", invitationID, tenantID, err)
+		http.Error(w, "Failed to revoke invitation", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireTenantID returns the tenant ID from the request context, writing a 403
+// response if it is missing
+func requireTenantID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	tenantID, err := authctx.GetTenantID(r.Context())
+	if err != nil || tenantID == nil {
+		apierror.Write(w, r, apierror.TenantRequired())
+		return 0, false
+	}
+	return *tenantID, true
+}
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// IPRuleRouter handles tenant IP rule routes
+type IPRuleRouter struct {
+	ipRuleService tenantservice.IPRuleService
+}
+
+// NewIPRuleRouter creates a new IPRuleRouter with the required dependencies
+func NewIPRuleRouter(ipRuleService tenantservice.IPRuleService) *IPRuleRouter {
+	return &IPRuleRouter{
+		ipRuleService: ipRuleService,
+	}
+}
+
+// addIPRuleRequest is the request body for adding an IP rule
+type addIPRuleRequest struct {
+	CIDR        string `json:"cidr"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+// ListIPRules handles GET /tenant/ip-rules
+func (ir *IPRuleRouter) ListIPRules(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := ir.ipRuleService.ListIPRules(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list IP rules for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to list IP rules", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// AddIPRule handles POST /tenant/ip-rules
+func (ir *IPRuleRouter) AddIPRule(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req addIPRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := ir.ipRuleService.AddIPRule(r.Context(), tenantID, tenantservice.IPRule{
+		CIDR:        req.CIDR,
+		Action:      req.Action,
+		Description: req.Description,
+	})
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to add IP rule for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to add IP rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/tenant/ip-rules/%d", rule.ID))
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// RemoveIPRule handles DELETE /tenant/ip-rules/{ruleID}
+func (ir *IPRuleRouter) RemoveIPRule(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	ruleID, ok := parseIDParam(w, r, "ruleID", "Invalid IP rule ID")
+	if !ok {
+		return
+	}
+
+	if err := ir.ipRuleService.RemoveIPRule(r.Context(), tenantID, ruleID); err != nil {
+		if errors.Is(err, tenantservice.ErrIPRuleNotFound) {
+			http.Error(w, "IP rule not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[ERROR] Failed to remove IP rule %d for tenant ID %d: %v", ruleID, tenantID, err)
+		http.Error(w, "Failed to remove IP rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	authservice "github.com/unsavory/silocore-go/internal/auth/service"
+	custommw "github.com/unsavory/silocore-go/internal/http/middleware"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// onboardingRedirectPath is where users land after creating a tenant
+const onboardingRedirectPath = "/tenant/"
+
+// OnboardingRouter handles self-service tenant creation
+type OnboardingRouter struct {
+	tenantService tenantservice.TenantService
+	authService   authservice.AuthService
+}
+
+// NewOnboardingRouter creates a new OnboardingRouter with the required dependencies
+func NewOnboardingRouter(tenantService tenantservice.TenantService, authService authservice.AuthService) *OnboardingRouter {
+	return &OnboardingRouter{
+		tenantService: tenantService,
+		authService:   authService,
+	}
+}
+
+// createTenantRequest is the request body for creating a tenant
+type createTenantRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description"`
+}
+
+// createTenantResponse is the response body for a newly created tenant
+type createTenantResponse struct {
+	Tenant *tenantservice.Tenant `json:"tenant"`
+	// AccessToken is scoped to the new tenant
+	AccessToken string `json:"access_token"`
+}
+
+// CreateTenant handles POST /tenants. The authenticated user becomes the tenant's
+// owner and their token is switched to the new tenant. Browser form submissions are
+// redirected to onboarding; JSON requests receive the tenant and the new token.
+func (or *OnboardingRouter) CreateTenant(w http.ResponseWriter, r *http.Request) {
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	isJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+
+	var req createTenantRequest
+	if isJSON {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form submission", http.StatusBadRequest)
+			return
+		}
+		req.Name = r.FormValue("name")
+		req.Description = r.FormValue("description")
+	}
+	if !validRequest(w, r, &req) {
+		return
+	}
+
+	tenant := &tenantservice.Tenant{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	tenant, err = or.tenantService.CreateTenantWithOwner(r.Context(), tenant, userID)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to create tenant for user ID %d: %v", userID, err)
+		http.Error(w, "Failed to create tenant", http.StatusInternalServerError)
+		return
+	}
+
+	// Switch the user's token to the new tenant
+	token, err := or.authService.SwitchTenantContext(r.Context(), userID, custommw.TokenFromRequest(r), &tenant.ID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to switch user ID %d to new tenant ID %d: %v", userID, tenant.ID, err)
+		http.Error(w, "Tenant created but failed to switch tenant context", http.StatusInternalServerError)
+		return
+	}
+
+	if isJSON {
+		writeJSON(w, http.StatusCreated, createTenantResponse{Tenant: tenant, AccessToken: token})
+		return
+	}
+
+	setAuthCookie(w, r, token)
+
+	// HTMX requests follow the HX-Redirect header instead of a redirect response
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", onboardingRedirectPath)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	http.Redirect(w, r, onboardingRedirectPath, http.StatusSeeOther)
+}
@@ -0,0 +1,55 @@
+package order
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// assignOrderRequest is the body of a request assigning an order
+type assignOrderRequest struct {
+	UserID *int64 `json:"user_id" validate:"required,min=1"`
+}
+
+// AssignOrder handles PUT /orders/api/{id}/assignee, assigning the order to a
+// member of the tenant
+func (h *Handler) AssignOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	var req assignOrderRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	h.assignOrder(w, r, orderID, req.UserID)
+}
+
+// UnassignOrder handles DELETE /orders/api/{id}/assignee
+func (h *Handler) UnassignOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	h.assignOrder(w, r, orderID, nil)
+}
+
+// assignOrder changes an order's assignee and writes the order as JSON
+func (h *Handler) assignOrder(w http.ResponseWriter, r *http.Request, orderID int64, userID *int64) {
+	order, err := h.orderService.AssignOrder(r.Context(), orderID, userID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to assign order")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
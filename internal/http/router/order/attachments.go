@@ -0,0 +1,145 @@
+package order
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	orderservice "github.com/unsavory/silocore-go/internal/order/service"
+)
+
+// attachmentFormField is the multipart field holding an uploaded attachment
+const attachmentFormField = "file"
+
+// maxAttachmentRequestBytes limits the size of attachment upload requests,
+// allowing for multipart overhead on top of the file itself
+const maxAttachmentRequestBytes = orderservice.MaxAttachmentBytes + 64*1024
+
+// ListAttachments handles GET /orders/api/{id}/attachments
+func (h *Handler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Get attachments from service
+	attachments, err := h.attachmentService.ListAttachments(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to list attachments")
+		return
+	}
+
+	// Return attachments as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// UploadAttachment handles POST /orders/api/{id}/attachments with the file in the
+// "file" multipart field
+func (h *Handler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentRequestBytes)
+	file, header, err := r.FormFile(attachmentFormField)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(fmt.Sprintf("File is required and must be at most %d MB", orderservice.MaxAttachmentBytes>>20)))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, orderservice.MaxAttachmentBytes+1))
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Failed to read file"))
+		return
+	}
+
+	// Fall back to sniffing the type if the client didn't send one
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	// Attach file to the order
+	attachment, err := h.attachmentService.AddAttachment(r.Context(), orderID, orderservice.AttachmentUpload{
+		Filename:    header.Filename,
+		ContentType: contentType,
+		Data:        data,
+	})
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to upload attachment")
+		return
+	}
+
+	// Return created attachment as JSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// DeleteAttachment handles DELETE /orders/api/{id}/attachments/{attachmentID}
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	// Parse order and attachment IDs from URL
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+	attachmentID, err := strconv.ParseInt(chi.URLParam(r, "attachmentID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid attachment ID"))
+		return
+	}
+
+	// Delete attachment
+	err = h.attachmentService.DeleteAttachment(r.Context(), orderID, attachmentID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to delete attachment")
+		return
+	}
+
+	// Return success
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DownloadAttachment handles GET /attachments/{attachmentID}/download. The link is
+// authenticated by its signature, so it works without a session until it expires.
+func (h *Handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	attachmentID, err := strconv.ParseInt(chi.URLParam(r, "attachmentID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid attachment ID"))
+		return
+	}
+
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid download link"))
+		return
+	}
+
+	content, err := h.attachmentService.OpenAttachment(r.Context(), attachmentID, expires, r.URL.Query().Get("signature"))
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to download attachment")
+		return
+	}
+
+	// Always download rather than render, so uploaded HTML can't run on our origin
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", content.Filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content.Data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Write(content.Data)
+}
@@ -0,0 +1,157 @@
+package order
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	ordermodel "github.com/unsavory/silocore-go/internal/order"
+	orderservice "github.com/unsavory/silocore-go/internal/order/service"
+	"github.com/unsavory/silocore-go/internal/views/pages"
+)
+
+// commentRequest is the body of a request adding a comment
+type commentRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+// ListComments handles GET /orders/api/{id}/comments. HTMX requests receive the
+// comment thread of the order page; other requests receive the comments as JSON.
+func (h *Handler) ListComments(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Get comments from service
+	comments, err := h.commentService.ListComments(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to list comments")
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.renderComments(w, r, orderID, comments)
+		return
+	}
+
+	// Return comments as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// AddComment handles POST /orders/api/{id}/comments with {"body": "..."}, or the
+// body form field from the order page, which gets the updated thread back
+func (h *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Parse request body
+	htmx := r.Header.Get("HX-Request") == "true"
+	var req commentRequest
+	if htmx {
+		req.Body = r.FormValue("body")
+		if !validRequest(w, r, &req) {
+			return
+		}
+	} else if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// Add comment
+	comment, err := h.commentService.AddComment(r.Context(), orderID, req.Body)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to add comment")
+		return
+	}
+
+	if htmx {
+		h.refreshComments(w, r, orderID)
+		return
+	}
+
+	// Return created comment as JSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// DeleteComment handles DELETE /orders/api/{id}/comments/{commentID}. Only the
+// author can delete a comment.
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	// Parse order and comment IDs from URL
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+	commentID, err := strconv.ParseInt(chi.URLParam(r, "commentID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid comment ID"))
+		return
+	}
+
+	// Delete comment
+	err = h.commentService.DeleteComment(r.Context(), orderID, commentID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to delete comment")
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.refreshComments(w, r, orderID)
+		return
+	}
+
+	// Return success
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refreshComments renders an order's comment thread after it changed
+func (h *Handler) refreshComments(w http.ResponseWriter, r *http.Request, orderID int64) {
+	comments, err := h.commentService.ListComments(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to list comments")
+		return
+	}
+	h.renderComments(w, r, orderID, comments)
+}
+
+// renderComments renders an order's comment thread for the current user
+func (h *Handler) renderComments(w http.ResponseWriter, r *http.Request, orderID int64, comments []orderservice.Comment) {
+	data := pages.OrderCommentsData{
+		OrderID:  strconv.FormatInt(orderID, 10),
+		Comments: make([]ordermodel.Comment, len(comments)),
+	}
+	if userID, err := authctx.GetUserID(r.Context()); err == nil {
+		data.UserID = strconv.FormatInt(userID, 10)
+	}
+
+	for i, comment := range comments {
+		data.Comments[i] = ordermodel.Comment{
+			ID:         strconv.FormatInt(comment.ID, 10),
+			AuthorName: comment.AuthorName,
+			Body:       comment.Body,
+			CreatedAt:  comment.CreatedAt,
+		}
+		if comment.AuthorID != nil {
+			data.Comments[i].AuthorID = strconv.FormatInt(*comment.AuthorID, 10)
+		}
+	}
+
+	if err := pages.OrderComments(data).Render(r.Context(), w); err != nil {
+		log.Printf("Error rendering order comments: %v", err)
+	}
+}
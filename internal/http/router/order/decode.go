@@ -0,0 +1,81 @@
+package order
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	"github.com/unsavory/silocore-go/internal/http/validate"
+)
+
+// decodeJSON decodes the JSON object in a request body into v, rejecting fields
+// v doesn't have and anything after the object, and validates it. If the body
+// can't be decoded it writes 413 Request Entity Too Large for bodies over the
+// size limit, or 400 Bad Request saying what is wrong with it, and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		// Read on to the end of the body, which must hold nothing else
+		var extra json.RawMessage
+		if err = decoder.Decode(&extra); err == io.EOF {
+			return validRequest(w, r, v)
+		} else if err == nil {
+			err = errTrailingData
+		}
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		apierror.Write(w, r, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge,
+			fmt.Sprintf("Request body must not be larger than %d bytes", maxBytesErr.Limit)))
+		return false
+	}
+	apierror.Write(w, r, apierror.BadRequest(jsonErrorMessage(err)))
+	return false
+}
+
+// validRequest checks v against the rules of its validate tags, writing 400 Bad
+// Request listing every invalid field and returning false if any are invalid
+func validRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if errs := validate.Struct(v); errs != nil {
+		apierror.Write(w, r, apierror.InvalidFields(errs))
+		return false
+	}
+	return true
+}
+
+// errTrailingData is returned for bodies with more than one JSON value
+var errTrailingData = errors.New("request body must only contain a single JSON object")
+
+// jsonErrorMessage describes why a request body couldn't be decoded
+func jsonErrorMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return "Request body must not be empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "Request body contains badly-formed JSON"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("Request body contains badly-formed JSON (at position %d)", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return fmt.Sprintf("Request body contains an invalid value for the %q field (at position %d)", typeErr.Field, typeErr.Offset)
+		}
+		return fmt.Sprintf("Request body contains an invalid value (at position %d)", typeErr.Offset)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return fmt.Sprintf("Request body contains unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+	case errors.Is(err, errTrailingData):
+		return "Request body must only contain a single JSON object"
+	default:
+		return "Invalid request body"
+	}
+}
@@ -0,0 +1,53 @@
+package order
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	orderservice "github.com/unsavory/silocore-go/internal/order/service"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// Error codes of the order routes
+const (
+	codeOrderNotFound      = "order_not_found"
+	codeOrderConflict      = "order_conflict"
+	codeAttachmentNotFound = "attachment_not_found"
+	codeAttachmentRejected = "attachment_rejected"
+	codeInvalidLink        = "invalid_download_link"
+	codeCommentNotFound    = "comment_not_found"
+	codeNotCommentAuthor   = "not_comment_author"
+	codeTagNotFound        = "tag_not_found"
+)
+
+// serviceErrors maps the errors of the order services to API errors
+var serviceErrors = []apierror.Mapping{
+	{Err: orderservice.ErrOrderNotFound, Status: http.StatusNotFound, Code: codeOrderNotFound, Message: "Order not found"},
+	{Err: orderservice.ErrConflict, Status: http.StatusConflict, Code: codeOrderConflict, Message: "Order was modified by someone else"},
+	{Err: orderservice.ErrAttachmentNotFound, Status: http.StatusNotFound, Code: codeAttachmentNotFound, Message: "Attachment not found"},
+	{Err: orderservice.ErrAttachmentRejected, Status: http.StatusUnprocessableEntity, Code: codeAttachmentRejected},
+	{Err: orderservice.ErrInvalidDownloadLink, Status: http.StatusForbidden, Code: codeInvalidLink},
+	{Err: orderservice.ErrCommentNotFound, Status: http.StatusNotFound, Code: codeCommentNotFound, Message: "Comment not found"},
+	{Err: orderservice.ErrNotCommentAuthor, Status: http.StatusForbidden, Code: codeNotCommentAuthor},
+	{Err: orderservice.ErrTagNotFound, Status: http.StatusNotFound, Code: codeTagNotFound, Message: "Tag not found"},
+	{Err: orderservice.ErrInvalidInput, Status: http.StatusBadRequest, Code: apierror.CodeInvalidInput},
+	{Err: orderservice.ErrNoTenantContext, Status: http.StatusForbidden, Code: apierror.CodeTenantRequired, Message: "Tenant context required"},
+	{Err: tenantservice.ErrQuotaExceeded, Status: http.StatusPaymentRequired, Code: apierror.CodeQuotaExceeded},
+}
+
+// writeServiceError writes the API error of an error from an order service.
+// Unexpected errors are logged and reported as 500 with failure as the message,
+// such as "Failed to get order".
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, failure string) {
+	if apiErr := apierror.Map(err, serviceErrors); apiErr != nil {
+		apierror.Write(w, r, apiErr)
+		return
+	}
+
+	log.Printf("[ERROR] %s: %s %s: %v", failure, r.Method, r.URL.Path, err)
+	apierror.Write(w, r, apierror.Internal(failure))
+}
+
+// errOrderNotFound is the error of orders outside the request's tenant
+var errOrderNotFound = apierror.New(http.StatusNotFound, codeOrderNotFound, "Order not found")
@@ -1,28 +1,47 @@
 package order
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	"github.com/unsavory/silocore-go/internal/http/middleware"
+	"github.com/unsavory/silocore-go/internal/http/validate"
 	ordermodel "github.com/unsavory/silocore-go/internal/order"
+	"github.com/unsavory/silocore-go/internal/order/invoice"
 	orderservice "github.com/unsavory/silocore-go/internal/order/service"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
 	"github.com/unsavory/silocore-go/internal/views/pages"
 )
 
 // Handler handles HTTP requests for orders
 type Handler struct {
-	orderService orderservice.OrderService
+	orderService      orderservice.OrderService
+	bulkOrderService  orderservice.BulkOrderService
+	attachmentService orderservice.AttachmentService
+	commentService    orderservice.CommentService
+	tagService        orderservice.TagService
+	invoiceRenderer   invoice.Renderer
 }
 
 // NewHandler creates a new order handler
-func NewHandler(orderService orderservice.OrderService) *Handler {
+func NewHandler(orderService orderservice.OrderService, bulkOrderService orderservice.BulkOrderService, attachmentService orderservice.AttachmentService, commentService orderservice.CommentService, tagService orderservice.TagService, invoiceRenderer invoice.Renderer) *Handler {
 	return &Handler{
-		orderService: orderService,
+		orderService:      orderService,
+		bulkOrderService:  bulkOrderService,
+		attachmentService: attachmentService,
+		commentService:    commentService,
+		tagService:        tagService,
+		invoiceRenderer:   invoiceRenderer,
 	}
 }
 
@@ -32,140 +51,152 @@ func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	orderIDStr := chi.URLParam(r, "id")
 	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
 		return
 	}
 
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
+	// Tenant context is guaranteed by the router middleware
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
 		return
 	}
 
 	// Get order from service
 	order, err := h.orderService.GetOrder(r.Context(), orderID)
 	if err != nil {
-		if errors.Is(err, orderservice.ErrOrderNotFound) {
-			http.Error(w, "Order not found", http.StatusNotFound)
-			return
-		}
-		if errors.Is(err, orderservice.ErrNoTenantContext) {
-			http.Error(w, "Tenant context required", http.StatusForbidden)
-			return
-		}
-		log.Printf("Error getting order: %v", err)
-		http.Error(w, "Failed to get order", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to get order")
 		return
 	}
 
 	// Verify order belongs to the tenant in context
-	if order.TenantID != *tenantID {
-		http.Error(w, "Order not found", http.StatusNotFound)
+	if order.TenantID != tenantID {
+		apierror.Write(w, r, errOrderNotFound)
 		return
 	}
 
 	// Return order as JSON
+	middleware.SetLastModified(w, order.UpdatedAt)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(order)
 }
 
-// ListOrders handles GET /orders
+// ListOrders handles GET /orders/api, returning a page of orders with the total
+// count. The limit defaults to 50.
 func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
+	// Parse query parameters
+	filter, apiErr := parseOrderFilter(r)
+	if apiErr != nil {
+		apierror.Write(w, r, apiErr)
 		return
 	}
 
-	// Parse query parameters
-	status := r.URL.Query().Get("status")
-	userIDStr := r.URL.Query().Get("user_id")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	// Get orders from service
+	list, err := h.orderService.ListOrdersPage(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to list orders")
+		return
+	}
 
-	// Create filter
-	filter := orderservice.OrderFilter{
-		Status: status,
+	// Return orders as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// orderExportColumns are the header of order CSV exports
+var orderExportColumns = []string{"order_id", "order_number", "status", "user_id", "total_amount", "notes", "created_at", "updated_at", "version"}
+
+// ExportOrders handles GET /orders/api/export, streaming the orders matching the
+// same filters as ListOrders as a CSV file, newest first
+func (h *Handler) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	// CSV is the only export format
+	if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+		apierror.Write(w, r, apierror.BadRequest("Unsupported export format, expected csv"))
+		return
 	}
 
-	// Parse user ID if provided
-	if userIDStr != "" {
-		userID, err := strconv.ParseInt(userIDStr, 10, 64)
-		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
-			return
-		}
-		filter.UserID = &userID
+	// Parse query parameters
+	filter, apiErr := parseOrderFilter(r)
+	if apiErr != nil {
+		apierror.Write(w, r, apiErr)
+		return
 	}
 
-	// Parse limit if provided
-	if limitStr != "" {
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil {
-			http.Error(w, "Invalid limit", http.StatusBadRequest)
-			return
-		}
-		filter.Limit = limit
-	} else {
-		// Default limit
-		filter.Limit = 50
+	// The response starts with the first chunk, so errors before it can still
+	// be reported with a status
+	writer := csv.NewWriter(w)
+	started := false
+	start := func() {
+		started = true
+		filename := fmt.Sprintf("orders-%s.csv", time.Now().UTC().Format(time.DateOnly))
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		writer.Write(orderExportColumns)
 	}
 
-	// Parse offset if provided
-	if offsetStr != "" {
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil {
-			http.Error(w, "Invalid offset", http.StatusBadRequest)
-			return
+	err := h.orderService.ExportOrders(r.Context(), filter, func(orders []orderservice.Order) error {
+		if !started {
+			start()
+		}
+		for _, order := range orders {
+			if err := writer.Write(orderExportRecord(order)); err != nil {
+				return err
+			}
 		}
-		filter.Offset = offset
-	}
 
-	// Get orders from service
-	orders, err := h.orderService.ListOrders(r.Context(), filter)
+		// Send each chunk to the client before reading the next one
+		writer.Flush()
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return writer.Error()
+	})
 	if err != nil {
-		if errors.Is(err, orderservice.ErrNoTenantContext) {
-			http.Error(w, "Tenant context required", http.StatusForbidden)
+		if started {
+			// The status was already sent; the client gets a truncated file
+			log.Printf("Error exporting orders: %v", err)
 			return
 		}
-		log.Printf("Error listing orders: %v", err)
-		http.Error(w, "Failed to list orders", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to export orders")
 		return
 	}
 
-	// Return orders as JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(orders)
+	// Export only the header if no orders match
+	if !started {
+		start()
+		writer.Flush()
+	}
+}
+
+// orderExportRecord returns the CSV record of an order
+func orderExportRecord(order orderservice.Order) []string {
+	return []string{
+		strconv.FormatInt(order.ID, 10),
+		order.OrderNumber,
+		order.Status,
+		strconv.FormatInt(order.UserID, 10),
+		strconv.FormatFloat(order.TotalAmount, 'f', 2, 64),
+		order.Notes,
+		order.CreatedAt.UTC().Format(time.RFC3339),
+		order.UpdatedAt.UTC().Format(time.RFC3339),
+		strconv.Itoa(order.Version),
+	}
 }
 
 // ListUserOrders handles GET /users/{id}/orders
 func (h *Handler) ListUserOrders(w http.ResponseWriter, r *http.Request) {
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
-		return
-	}
 
 	// Parse user ID from URL
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest("Invalid user ID"))
 		return
 	}
 
 	// Get orders from service
 	orders, err := h.orderService.ListUserOrders(r.Context(), userID)
 	if err != nil {
-		if errors.Is(err, orderservice.ErrNoTenantContext) {
-			http.Error(w, "Tenant context required", http.StatusForbidden)
-			return
-		}
-		log.Printf("Error listing user orders: %v", err)
-		http.Error(w, "Failed to list user orders", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to list user orders")
 		return
 	}
 
@@ -176,28 +207,25 @@ func (h *Handler) ListUserOrders(w http.ResponseWriter, r *http.Request) {
 
 // CreateOrder handles POST /orders
 func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
+	// Tenant context is guaranteed by the router middleware
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
 		return
 	}
 
 	// Parse request body
 	var order orderservice.Order
-	err = json.NewDecoder(r.Body).Decode(&order)
-	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &order) {
 		return
 	}
 
 	// Set tenant ID from context
-	order.TenantID = *tenantID
+	order.TenantID = tenantID
 
 	// Get user ID from context
 	userID, err := authctx.GetUserID(r.Context())
 	if err != nil {
-		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.Unauthorized("User ID not found in context"))
 		return
 	}
 	order.UserID = userID
@@ -205,16 +233,31 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	// Create order
 	createdOrder, err := h.orderService.CreateOrder(r.Context(), &order)
 	if err != nil {
-		if errors.Is(err, orderservice.ErrInvalidInput) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		if errors.Is(err, orderservice.ErrNoTenantContext) {
-			http.Error(w, "Tenant context required", http.StatusForbidden)
-			return
-		}
-		log.Printf("Error creating order: %v", err)
-		http.Error(w, "Failed to create order", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to create order")
+		return
+	}
+
+	// Return created order as JSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdOrder)
+}
+
+// DuplicateOrder handles POST /orders/api/{id}/duplicate, creating a new pending
+// order copied from the order
+func (h *Handler) DuplicateOrder(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Create the copy
+	createdOrder, err := orderservice.DuplicateOrder(r.Context(), h.orderService, orderID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to duplicate order")
 		return
 	}
 
@@ -224,12 +267,104 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(createdOrder)
 }
 
+// bulkCreateRequest is the body of POST /orders/api/bulk
+type bulkCreateRequest struct {
+	Orders []orderservice.Order `json:"orders"`
+	// Atomic creates either all orders or none
+	Atomic bool `json:"atomic"`
+}
+
+// bulkOrderResult is the outcome of one order of a bulk creation
+type bulkOrderResult struct {
+	Index int                 `json:"index"`
+	Order *orderservice.Order `json:"order,omitempty"`
+	Error string              `json:"error,omitempty"`
+}
+
+// bulkCreateResponse is the response of POST /orders/api/bulk
+type bulkCreateResponse struct {
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []bulkOrderResult `json:"results"`
+}
+
+// CreateOrders handles POST /orders/api/bulk. It responds 201 if every order was
+// created, 207 if only some were, and 422 if none were.
+func (h *Handler) CreateOrders(w http.ResponseWriter, r *http.Request) {
+	// Tenant context is guaranteed by the router middleware
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req bulkCreateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// Get user ID from context
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		apierror.Write(w, r, apierror.Unauthorized("User ID not found in context"))
+		return
+	}
+
+	// Create the orders for the current tenant and user
+	for i := range req.Orders {
+		req.Orders[i].TenantID = tenantID
+		req.Orders[i].UserID = userID
+	}
+
+	results, err := h.bulkOrderService.CreateOrders(r.Context(), req.Orders, req.Atomic)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to create orders")
+		return
+	}
+
+	response := bulkCreateResponse{Results: make([]bulkOrderResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = bulkOrderResult{Index: result.Index, Order: result.Order}
+		if result.Err != nil {
+			response.Results[i].Error = bulkOrderError(result.Err)
+			response.Failed++
+		} else {
+			response.Created++
+		}
+	}
+
+	status := http.StatusCreated
+	if response.Created == 0 {
+		status = http.StatusUnprocessableEntity
+	} else if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	// Return results as JSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// bulkOrderError returns the message of an order that failed in a bulk creation,
+// hiding the details of unexpected errors as CreateOrder does
+func bulkOrderError(err error) string {
+	switch {
+	case errors.Is(err, orderservice.ErrInvalidInput),
+		errors.Is(err, orderservice.ErrBulkRolledBack),
+		errors.Is(err, tenantservice.ErrQuotaExceeded):
+		return err.Error()
+	default:
+		log.Printf("Error creating order in bulk: %v", err)
+		return "Failed to create order"
+	}
+}
+
 // UpdateOrder handles PUT /orders/{id}
 func (h *Handler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
+	// Tenant context is guaranteed by the router middleware
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
 		return
 	}
 
@@ -237,39 +372,24 @@ func (h *Handler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	orderIDStr := chi.URLParam(r, "id")
 	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
 		return
 	}
 
 	// Parse request body
 	var order orderservice.Order
-	err = json.NewDecoder(r.Body).Decode(&order)
-	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &order) {
 		return
 	}
 
 	// Set order ID and tenant ID
 	order.ID = orderID
-	order.TenantID = *tenantID
+	order.TenantID = tenantID
 
 	// Update order
 	err = h.orderService.UpdateOrder(r.Context(), &order)
 	if err != nil {
-		if errors.Is(err, orderservice.ErrOrderNotFound) {
-			http.Error(w, "Order not found", http.StatusNotFound)
-			return
-		}
-		if errors.Is(err, orderservice.ErrInvalidInput) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		if errors.Is(err, orderservice.ErrNoTenantContext) {
-			http.Error(w, "Tenant context required", http.StatusForbidden)
-			return
-		}
-		log.Printf("Error updating order: %v", err)
-		http.Error(w, "Failed to update order", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to update order")
 		return
 	}
 
@@ -277,51 +397,121 @@ func (h *Handler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// PatchOrder handles PATCH /orders/api/{id}. Only the fields in the body are
+// updated; the updated order is returned.
+func (h *Handler) PatchOrder(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Parse request body, rejecting fields that can't be patched
+	var patch orderservice.OrderPatch
+	if !decodeJSON(w, r, &patch) {
+		return
+	}
+
+	// Keep the order as it was for the audit log's summary of the changes. If
+	// it can't be read, the patch fails too.
+	previous, _ := h.orderService.GetOrder(r.Context(), orderID)
+
+	// Patch order
+	order, err := h.orderService.PatchOrder(r.Context(), orderID, patch)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to update order")
+		return
+	}
+	if previous != nil {
+		middleware.SetAuditChanges(r, orderChanges(previous, order))
+	}
+
+	// Return updated order as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// orderChanges summarizes the fields of an order that changed, with their
+// previous and new values
+func orderChanges(previous, order *orderservice.Order) map[string]interface{} {
+	changes := map[string]interface{}{}
+	change := func(field string, from, to interface{}) {
+		if from != to {
+			changes[field] = map[string]interface{}{"from": from, "to": to}
+		}
+	}
+	change("user_id", previous.UserID, order.UserID)
+	change("order_number", previous.OrderNumber, order.OrderNumber)
+	change("status", previous.Status, order.Status)
+	change("notes", previous.Notes, order.Notes)
+	change("subtotal", previous.Subtotal, order.Subtotal)
+	change("discount_amount", previous.DiscountAmount, order.DiscountAmount)
+	change("tax_rate", previous.TaxRate, order.TaxRate)
+	change("tax_amount", previous.TaxAmount, order.TaxAmount)
+	change("total_amount", previous.TotalAmount, order.TotalAmount)
+	return changes
+}
+
 // DeleteOrder handles DELETE /orders/{id}
 func (h *Handler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
+
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Parse the expected version of the order
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid or missing version"))
+		return
+	}
+
+	// Delete order
+	err = h.orderService.DeleteOrder(r.Context(), orderID, version)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to delete order")
 		return
 	}
 
+	// Return success
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreOrder handles POST /orders/api/{id}/restore, taking an order out of the
+// trash and returning it
+func (h *Handler) RestoreOrder(w http.ResponseWriter, r *http.Request) {
 	// Parse order ID from URL
 	orderIDStr := chi.URLParam(r, "id")
 	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
 		return
 	}
 
-	// Delete order
-	err = h.orderService.DeleteOrder(r.Context(), orderID)
+	// Restore order
+	order, err := h.orderService.RestoreOrder(r.Context(), orderID)
 	if err != nil {
 		if errors.Is(err, orderservice.ErrOrderNotFound) {
-			http.Error(w, "Order not found", http.StatusNotFound)
-			return
-		}
-		if errors.Is(err, orderservice.ErrNoTenantContext) {
-			http.Error(w, "Tenant context required", http.StatusForbidden)
+			apierror.Write(w, r, apierror.New(http.StatusNotFound, codeOrderNotFound, "Deleted order not found"))
 			return
 		}
-		log.Printf("Error deleting order: %v", err)
-		http.Error(w, "Failed to delete order", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to restore order")
 		return
 	}
 
-	// Return success
-	w.WriteHeader(http.StatusNoContent)
+	// Return restored order as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
 }
 
 // CountOrders handles GET /orders/count
 func (h *Handler) CountOrders(w http.ResponseWriter, r *http.Request) {
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
-		return
-	}
 
 	// Parse query parameters
 	status := r.URL.Query().Get("status")
@@ -336,7 +526,7 @@ func (h *Handler) CountOrders(w http.ResponseWriter, r *http.Request) {
 	if userIDStr != "" {
 		userID, err := strconv.ParseInt(userIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.BadRequest("Invalid user ID"))
 			return
 		}
 		filter.UserID = &userID
@@ -345,12 +535,7 @@ func (h *Handler) CountOrders(w http.ResponseWriter, r *http.Request) {
 	// Count orders
 	count, err := h.orderService.CountOrders(r.Context(), filter)
 	if err != nil {
-		if errors.Is(err, orderservice.ErrNoTenantContext) {
-			http.Error(w, "Tenant context required", http.StatusForbidden)
-			return
-		}
-		log.Printf("Error counting orders: %v", err)
-		http.Error(w, "Failed to count orders", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to count orders")
 		return
 	}
 
@@ -359,32 +544,114 @@ func (h *Handler) CountOrders(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]int{"count": count})
 }
 
-// OrdersPage handles GET /orders/view and renders the orders page
+// GetOrderSummary handles GET /orders/api/summary
+func (h *Handler) GetOrderSummary(w http.ResponseWriter, r *http.Request) {
+	// Default to the last 30 days by day
+	filter := orderservice.OrderSummaryFilter{
+		To:       time.Now().UTC(),
+		Interval: r.URL.Query().Get("interval"),
+	}
+	filter.From = filter.To.AddDate(0, 0, -29)
+
+	var err error
+	if value := r.URL.Query().Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.DateOnly, value); err != nil {
+			apierror.Write(w, r, apierror.BadRequest("Invalid from date, expected YYYY-MM-DD"))
+			return
+		}
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.DateOnly, value); err != nil {
+			apierror.Write(w, r, apierror.BadRequest("Invalid to date, expected YYYY-MM-DD"))
+			return
+		}
+	}
+
+	summary, err := h.orderService.GetOrderSummary(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to summarize orders")
+		return
+	}
+
+	// Return summary as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// GetOrderSettings handles GET /orders/api/settings
+func (h *Handler) GetOrderSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.orderService.GetOrderSettings(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to get order settings")
+		return
+	}
+
+	// Return settings as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// UpdateOrderSettings handles PUT /orders/api/settings. An empty prefix resets
+// it to the default.
+func (h *Handler) UpdateOrderSettings(w http.ResponseWriter, r *http.Request) {
+	var settings orderservice.OrderSettings
+	if !decodeJSON(w, r, &settings) {
+		return
+	}
+
+	if err := h.orderService.UpdateOrderSettings(r.Context(), &settings); err != nil {
+		writeServiceError(w, r, err, "Failed to update order settings")
+		return
+	}
+
+	// Return updated settings as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// OrdersPage handles GET /orders and renders a page of orders
 func (h *Handler) OrdersPage(w http.ResponseWriter, r *http.Request) {
-	// Verify tenant context
-	tenantID, err := authctx.GetTenantID(r.Context())
-	if err != nil || tenantID == nil {
-		http.Error(w, "Tenant context required", http.StatusForbidden)
+	// Parse query parameters
+	filter, apiErr := parseOrderFilter(r)
+	if apiErr != nil {
+		http.Error(w, apiErr.Message, apiErr.Status)
 		return
 	}
 
 	// Get orders from service
-	serviceOrders, err := h.orderService.ListOrders(r.Context(), orderservice.OrderFilter{})
+	list, err := h.orderService.ListOrdersPage(r.Context(), filter)
 	if err != nil {
+		if errors.Is(err, orderservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Printf("Error fetching orders: %v", err)
 		http.Error(w, "Failed to fetch orders", http.StatusInternalServerError)
 		return
 	}
 
+	// Get the tags of the listed orders
+	orderIDs := make([]int64, len(list.Items))
+	for i, svcOrder := range list.Items {
+		orderIDs[i] = svcOrder.ID
+	}
+	tags, err := h.tagService.ListTagsForOrders(r.Context(), orderIDs)
+	if err != nil {
+		log.Printf("Error fetching order tags: %v", err)
+		http.Error(w, "Failed to fetch orders", http.StatusInternalServerError)
+		return
+	}
+
 	// Convert service orders to view model orders
-	viewOrders := make([]ordermodel.Order, len(serviceOrders))
-	for i, svcOrder := range serviceOrders {
+	viewOrders := make([]ordermodel.Order, len(list.Items))
+	for i, svcOrder := range list.Items {
 		viewOrders[i] = ordermodel.Order{
 			ID:        strconv.FormatInt(svcOrder.ID, 10),
 			TenantID:  strconv.FormatInt(svcOrder.TenantID, 10),
 			UserID:    strconv.FormatInt(svcOrder.UserID, 10),
 			Status:    svcOrder.Status,
 			Total:     svcOrder.TotalAmount,
+			Tags:      tags[svcOrder.ID],
 			CreatedAt: svcOrder.CreatedAt,
 			UpdatedAt: svcOrder.UpdatedAt,
 		}
@@ -393,9 +660,339 @@ func (h *Handler) OrdersPage(w http.ResponseWriter, r *http.Request) {
 	// Create page data
 	data := pages.OrdersPageData{
 		Orders: viewOrders,
+		Query:  r.URL.Query(),
+		Total:  list.Total,
+		Limit:  list.Limit,
+		Offset: list.Offset,
 	}
 
 	// Render the page
 	component := pages.Orders(data)
 	component.Render(r.Context(), w)
 }
+
+// GetOrderHistory handles GET /orders/api/{id}/history
+func (h *Handler) GetOrderHistory(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Get status history from service
+	history, err := h.orderService.GetOrderHistory(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to get order history")
+		return
+	}
+
+	// Return history as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// OrderPage handles GET /orders/{id} and renders the order with its items, status
+// history and attachments. HTMX requests from the orders page get the details only.
+func (h *Handler) OrderPage(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	data, ok := h.loadOrderDetail(w, r, orderID)
+	if !ok {
+		return
+	}
+
+	// Render the order details into the orders page, or the whole page
+	if r.Header.Get("HX-Request") == "true" {
+		pages.OrderDetail(data).Render(r.Context(), w)
+		return
+	}
+	pages.OrderDetailPage(data).Render(r.Context(), w)
+}
+
+// ChangeOrderStatus handles POST /orders/{id}/status from the status actions of the
+// order page, and renders the updated order details
+func (h *Handler) ChangeOrderStatus(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse the new status and the version of the order it was chosen for
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	status := r.FormValue("status")
+	version, err := strconv.Atoi(r.FormValue("version"))
+	if err != nil {
+		http.Error(w, "Invalid or missing version", http.StatusBadRequest)
+		return
+	}
+
+	patch := orderservice.OrderPatch{
+		Status:       &status,
+		Version:      &version,
+		StatusReason: strings.TrimSpace(r.FormValue("reason")),
+	}
+	if errs := validate.Struct(patch); errs != nil {
+		http.Error(w, errs.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Change the status, recording the reason in the history
+	_, err = h.orderService.PatchOrder(r.Context(), orderID, patch)
+	if err != nil {
+		if errors.Is(err, orderservice.ErrOrderNotFound) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, orderservice.ErrConflict) {
+			http.Error(w, "Order was modified by someone else, reload the page to see the changes", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, orderservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error changing order status: %v", err)
+		http.Error(w, "Failed to change order status", http.StatusInternalServerError)
+		return
+	}
+
+	data, ok := h.loadOrderDetail(w, r, orderID)
+	if !ok {
+		return
+	}
+	pages.OrderDetail(data).Render(r.Context(), w)
+}
+
+// loadOrderDetail fetches an order with its status history and attachments for the
+// order page, writing an error response if it fails
+func (h *Handler) loadOrderDetail(w http.ResponseWriter, r *http.Request, orderID int64) (pages.OrderDetailPageData, bool) {
+	// Get order from service
+	svcOrder, err := h.orderService.GetOrder(r.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, orderservice.ErrOrderNotFound) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return pages.OrderDetailPageData{}, false
+		}
+		log.Printf("Error fetching order: %v", err)
+		http.Error(w, "Failed to fetch order", http.StatusInternalServerError)
+		return pages.OrderDetailPageData{}, false
+	}
+
+	// Get status history from service
+	history, err := h.orderService.GetOrderHistory(r.Context(), orderID)
+	if err != nil {
+		log.Printf("Error fetching order history: %v", err)
+		http.Error(w, "Failed to fetch order history", http.StatusInternalServerError)
+		return pages.OrderDetailPageData{}, false
+	}
+
+	// Get attachments with fresh download links
+	attachments, err := h.attachmentService.ListAttachments(r.Context(), orderID)
+	if err != nil {
+		log.Printf("Error fetching order attachments: %v", err)
+		http.Error(w, "Failed to fetch order attachments", http.StatusInternalServerError)
+		return pages.OrderDetailPageData{}, false
+	}
+
+	return orderDetailView(svcOrder, history, attachments), true
+}
+
+// orderDetailView converts an order with its status history and attachments to the
+// order page's view model
+func orderDetailView(svcOrder *orderservice.Order, history []orderservice.OrderStatusChange, attachments []orderservice.Attachment) pages.OrderDetailPageData {
+	data := pages.OrderDetailPageData{
+		Order: ordermodel.Order{
+			ID:        strconv.FormatInt(svcOrder.ID, 10),
+			TenantID:  strconv.FormatInt(svcOrder.TenantID, 10),
+			UserID:    strconv.FormatInt(svcOrder.UserID, 10),
+			Status:    svcOrder.Status,
+			Total:     svcOrder.TotalAmount,
+			CreatedAt: svcOrder.CreatedAt,
+			UpdatedAt: svcOrder.UpdatedAt,
+		},
+		OrderNumber: svcOrder.OrderNumber,
+		Version:     svcOrder.Version,
+		Notes:       svcOrder.Notes,
+		Subtotal:    svcOrder.Subtotal,
+		Discount:    svcOrder.DiscountAmount,
+		TaxRate:     svcOrder.TaxRate,
+		Tax:         svcOrder.TaxAmount,
+		Items:       make([]ordermodel.Item, len(svcOrder.Items)),
+		History:     make([]ordermodel.StatusChange, len(history)),
+		Attachments: make([]ordermodel.Attachment, len(attachments)),
+	}
+
+	for i, item := range svcOrder.Items {
+		data.Items[i] = ordermodel.Item{
+			SKU:         item.SKU,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount(),
+		}
+	}
+
+	for i, change := range history {
+		data.History[i] = ordermodel.StatusChange{
+			ToStatus:  change.ToStatus,
+			Reason:    change.Reason,
+			ChangedAt: change.ChangedAt,
+		}
+		if change.FromStatus != nil {
+			data.History[i].FromStatus = *change.FromStatus
+		}
+		if change.ChangedBy != nil {
+			data.History[i].ChangedBy = strconv.FormatInt(*change.ChangedBy, 10)
+		}
+	}
+
+	for i, attachment := range attachments {
+		data.Attachments[i] = ordermodel.Attachment{
+			Filename:    attachment.Filename,
+			SizeBytes:   attachment.SizeBytes,
+			DownloadURL: attachment.DownloadURL,
+			CreatedAt:   attachment.CreatedAt,
+		}
+	}
+
+	return data
+}
+
+// parseOrderFilter parses the status, user_id, tag, archived, limit and offset
+// query parameters, returning the error to respond with if one is invalid
+func parseOrderFilter(r *http.Request) (orderservice.OrderFilter, *apierror.Error) {
+	query := r.URL.Query()
+	filter := orderservice.OrderFilter{
+		Status: query.Get("status"),
+		Search: query.Get("q"),
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+		Tags:   query["tag"],
+	}
+
+	// Parse user ID if provided
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid user ID")
+		}
+		filter.UserID = &userID
+	}
+
+	// Parse assigned_to if provided: "me" for the current user's orders, "none"
+	// for unassigned orders, or a user ID
+	switch assignedTo := query.Get("assigned_to"); assignedTo {
+	case "":
+	case "none":
+		filter.Unassigned = true
+	case "me":
+		userID, err := authctx.GetUserID(r.Context())
+		if err != nil {
+			return filter, apierror.Unauthorized("User ID not found in context")
+		}
+		filter.AssignedTo = &userID
+	default:
+		userID, err := strconv.ParseInt(assignedTo, 10, 64)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid assigned_to")
+		}
+		filter.AssignedTo = &userID
+	}
+
+	// Parse include_deleted if provided. The router only lets order managers set it.
+	if value := query.Get("include_deleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid include_deleted")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	// Parse archived if provided
+	if value := query.Get("archived"); value != "" {
+		archived, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid archived")
+		}
+		filter.Archived = archived
+	}
+
+	// Parse created date range if provided
+	if value := query.Get("created_from"); value != "" {
+		from, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid created_from date, expected YYYY-MM-DD")
+		}
+		filter.CreatedFrom = &from
+	}
+	if value := query.Get("created_to"); value != "" {
+		to, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid created_to date, expected YYYY-MM-DD")
+		}
+		// The range includes the whole of the last day
+		to = to.AddDate(0, 0, 1)
+		filter.CreatedTo = &to
+	}
+
+	// Parse amount range if provided
+	if value := query.Get("min_amount"); value != "" {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid min_amount")
+		}
+		filter.MinAmount = &amount
+	}
+	if value := query.Get("max_amount"); value != "" {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid max_amount")
+		}
+		filter.MaxAmount = &amount
+	}
+
+	// Parse limit if provided
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	// Parse offset if provided
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return filter, apierror.BadRequest("Invalid offset")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
+// tenantFromContext returns the tenant ID from the request context, writing a
+// 403 response if it is missing
+func tenantFromContext(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	tenantID, err := authctx.GetTenantID(r.Context())
+	if err != nil || tenantID == nil {
+		apierror.Write(w, r, apierror.TenantRequired())
+		return 0, false
+	}
+	return *tenantID, true
+}
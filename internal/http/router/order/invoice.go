@@ -0,0 +1,40 @@
+package order
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// GetInvoice handles GET /orders/api/{id}/invoice.pdf, returning the order's
+// invoice for viewing in the browser
+func (h *Handler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	invoice, err := h.invoiceRenderer.RenderInvoice(r.Context(), orderID)
+	if err != nil {
+		// The invoice of an order whose tenant is gone can't be rendered
+		if errors.Is(err, tenantservice.ErrTenantNotFound) {
+			apierror.Write(w, r, errOrderNotFound)
+			return
+		}
+		writeServiceError(w, r, err, "Failed to render invoice")
+		return
+	}
+
+	// ServeContent answers conditional requests from the invoice's modification time
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", invoice.Filename))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, invoice.Filename, invoice.ModTime, bytes.NewReader(invoice.Data))
+}
@@ -1,8 +1,15 @@
 package order
 
 import (
+	"net/http"
+	"strconv"
+
 	"github.com/go-chi/chi/v5"
+	"github.com/unsavory/silocore-go/internal/auth/authz"
+	"github.com/unsavory/silocore-go/internal/cache"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
 	"github.com/unsavory/silocore-go/internal/http/middleware"
+	"github.com/unsavory/silocore-go/internal/order/invoice"
 	orderservice "github.com/unsavory/silocore-go/internal/order/service"
 	"github.com/unsavory/silocore-go/internal/service"
 )
@@ -13,16 +20,25 @@ type OrderRouter struct {
 }
 
 // NewOrderRouter creates a new OrderRouter with the required dependencies
-func NewOrderRouter(orderService orderservice.OrderService) *OrderRouter {
+func NewOrderRouter(orderService orderservice.OrderService, bulkOrderService orderservice.BulkOrderService, attachmentService orderservice.AttachmentService, commentService orderservice.CommentService, tagService orderservice.TagService, invoiceRenderer invoice.Renderer) *OrderRouter {
 	return &OrderRouter{
-		handler: NewHandler(orderService),
+		handler: NewHandler(orderService, bulkOrderService, attachmentService, commentService, tagService, invoiceRenderer),
 	}
 }
 
+// RegisterPublicRoutes registers order routes that don't require authentication
+func RegisterPublicRoutes(r chi.Router, factory *service.Factory) {
+	orderRouter := NewOrderRouter(factory.OrderService(), factory.BulkOrderService(), factory.AttachmentService(), factory.CommentService(), factory.TagService(), factory.InvoiceRenderer())
+
+	// GET /attachments/{attachmentID}/download - authenticated by its signed link
+	r.Get("/attachments/{attachmentID}/download", orderRouter.handler.DownloadAttachment)
+}
+
 // RegisterRoutes registers order routes
 func RegisterRoutes(r chi.Router, factory *service.Factory) {
 	// Create order router with only the dependencies it needs
-	orderRouter := NewOrderRouter(factory.OrderService())
+	orderRouter := NewOrderRouter(factory.OrderService(), factory.BulkOrderService(), factory.AttachmentService(), factory.CommentService(), factory.TagService(), factory.InvoiceRenderer())
+	perms := newPermissions(factory)
 
 	// Register routes
 	r.Route("/orders", func(r chi.Router) {
@@ -30,31 +46,28 @@ func RegisterRoutes(r chi.Router, factory *service.Factory) {
 		// in the router hierarchy, but we include them here for completeness
 		// and to ensure proper security even if the parent router changes
 		r.Use(middleware.AuthMiddleware(factory.JWTService()))
-		r.Use(middleware.RoleMiddleware(factory.UserService(), factory.TenantMemberService()))
+		r.Use(middleware.RoleMiddleware(factory.UserService(), factory.TenantMemberService(), factory.TenantService()))
 		r.Use(middleware.RequireTenantContext)
+		r.Use(factory.TransactionManager().TenantContext())
 
-		// GET /orders - View page
-		r.Get("/", orderRouter.handler.OrdersPage)
-
-		// API routes
-		r.Route("/api", func(r chi.Router) {
-			// GET /orders/api
-			r.Get("/", orderRouter.handler.ListOrders)
+		// Every order route queries the tenant's orders
+		r.Use(transaction.Required)
 
-			// GET /orders/api/count
-			r.Get("/count", orderRouter.handler.CountOrders)
+		// GET /orders - View page
+		r.With(perms.read, perms.listDeleted).Get("/", orderRouter.handler.OrdersPage)
 
-			// POST /orders/api
-			r.Post("/", orderRouter.handler.CreateOrder)
+		// GET /orders/{id} - Order page
+		r.With(perms.read).Get("/{id}", orderRouter.handler.OrderPage)
 
-			// GET /orders/api/{id}
-			r.Get("/{id}", orderRouter.handler.GetOrder)
+		// POST /orders/{id}/status - Status actions of the order page
+		r.With(perms.update).Post("/{id}/status", orderRouter.handler.ChangeOrderStatus)
 
-			// PUT /orders/api/{id}
-			r.Put("/{id}", orderRouter.handler.UpdateOrder)
+		// API routes, used by the order pages and deprecated for API clients in
+		// favor of /api/v1/orders
+		r.Route("/api", func(r chi.Router) {
+			r.Use(middleware.DeprecatedAlias("/orders/api", "/api/v1/orders"))
 
-			// DELETE /orders/api/{id}
-			r.Delete("/{id}", orderRouter.handler.DeleteOrder)
+			registerAPIRoutes(r, orderRouter, perms, factory.ResponseCache())
 		})
 	})
 
@@ -62,10 +75,158 @@ func RegisterRoutes(r chi.Router, factory *service.Factory) {
 	r.Route("/users/{id}/orders", func(r chi.Router) {
 		// Apply middleware
 		r.Use(middleware.AuthMiddleware(factory.JWTService()))
-		r.Use(middleware.RoleMiddleware(factory.UserService(), factory.TenantMemberService()))
+		r.Use(middleware.RoleMiddleware(factory.UserService(), factory.TenantMemberService(), factory.TenantService()))
 		r.Use(middleware.RequireTenantContext)
+		r.Use(factory.TransactionManager().TenantContext())
 
 		// GET /users/{id}/orders
-		r.Get("/", orderRouter.handler.ListUserOrders)
+		r.With(perms.read).Get("/", orderRouter.handler.ListUserOrders)
 	})
 }
+
+// RegisterAPIRoutes registers the order routes of the versioned JSON API at
+// /orders, relative to the API's prefix. Requests must be authenticated, with a
+// tenant, and run in a transaction begun by the caller's middleware.
+func RegisterAPIRoutes(r chi.Router, factory *service.Factory) {
+	orderRouter := NewOrderRouter(factory.OrderService(), factory.BulkOrderService(), factory.AttachmentService(), factory.CommentService(), factory.TagService(), factory.InvoiceRenderer())
+	perms := newPermissions(factory)
+
+	r.Route("/orders", func(r chi.Router) {
+		r.Use(middleware.RequireTenantContext)
+		r.Use(factory.TransactionManager().TenantContext())
+
+		registerAPIRoutes(r, orderRouter, perms, factory.ResponseCache())
+	})
+}
+
+// permissions holds the permission checks of order operations
+type permissions struct {
+	read, create, update, delete, manage func(http.Handler) http.Handler
+
+	// listDeleted lets only order managers list orders in the trash
+	listDeleted func(http.Handler) http.Handler
+}
+
+// newPermissions creates the permission checks of order operations
+func newPermissions(factory *service.Factory) permissions {
+	authorizer := factory.Authorizer()
+	perms := permissions{
+		read:   middleware.RequirePermission(authorizer, authz.ResourceOrders, authz.ActionRead),
+		create: middleware.RequirePermission(authorizer, authz.ResourceOrders, authz.ActionCreate),
+		update: middleware.RequirePermission(authorizer, authz.ResourceOrders, authz.ActionUpdate),
+		delete: middleware.RequirePermission(authorizer, authz.ResourceOrders, authz.ActionDelete),
+		manage: middleware.RequirePermission(authorizer, authz.ResourceOrders, authz.ActionManage),
+	}
+	perms.listDeleted = whenIncludingDeleted(perms.manage)
+	return perms
+}
+
+// registerAPIRoutes registers the JSON order routes under a prefix, either the
+// deprecated /orders/api or /api/v1/orders. Summaries are cached in responses,
+// when set.
+func registerAPIRoutes(r chi.Router, orderRouter *OrderRouter, perms permissions, responses *cache.ResponseCache) {
+	// Answer clients polling JSON that hasn't changed with 304 Not Modified
+	r.Use(middleware.ConditionalGET)
+
+	// GET {prefix}
+	r.With(perms.read, perms.listDeleted).Get("/", orderRouter.handler.ListOrders)
+
+	// GET {prefix}/count
+	r.With(perms.read).Get("/count", orderRouter.handler.CountOrders)
+
+	// GET {prefix}/summary - cached, as it aggregates all of the tenant's orders
+	r.With(perms.read, middleware.ResponseCache(responses)).Get("/summary", orderRouter.handler.GetOrderSummary)
+
+	// GET {prefix}/export - streams every matching order, so it may run longer
+	r.With(middleware.ExportTimeout, perms.read, perms.listDeleted).Get("/export", orderRouter.handler.ExportOrders)
+
+	// POST {prefix}
+	r.With(perms.create).Post("/", orderRouter.handler.CreateOrder)
+
+	// POST {prefix}/bulk
+	r.With(perms.create).Post("/bulk", orderRouter.handler.CreateOrders)
+
+	// GET {prefix}/settings
+	r.With(perms.read).Get("/settings", orderRouter.handler.GetOrderSettings)
+
+	// PUT {prefix}/settings
+	r.With(perms.manage).Put("/settings", orderRouter.handler.UpdateOrderSettings)
+
+	// GET {prefix}/tags
+	r.With(perms.read).Get("/tags", orderRouter.handler.ListTags)
+
+	// PUT {prefix}/tags/{tag}
+	r.With(perms.manage).Put("/tags/{tag}", orderRouter.handler.RenameTag)
+
+	// DELETE {prefix}/tags/{tag}
+	r.With(perms.manage).Delete("/tags/{tag}", orderRouter.handler.DeleteTag)
+
+	// GET {prefix}/{id}
+	r.With(perms.read).Get("/{id}", orderRouter.handler.GetOrder)
+
+	// GET {prefix}/{id}/history
+	r.With(perms.read).Get("/{id}/history", orderRouter.handler.GetOrderHistory)
+
+	// GET {prefix}/{id}/invoice.pdf
+	r.With(perms.read).Get("/{id}/invoice.pdf", orderRouter.handler.GetInvoice)
+
+	// PUT {prefix}/{id}
+	r.With(perms.update).Put("/{id}", orderRouter.handler.UpdateOrder)
+
+	// PATCH {prefix}/{id}
+	r.With(perms.update).Patch("/{id}", orderRouter.handler.PatchOrder)
+
+	// DELETE {prefix}/{id}
+	r.With(perms.delete).Delete("/{id}", orderRouter.handler.DeleteOrder)
+
+	// PUT {prefix}/{id}/assignee
+	r.With(perms.update).Put("/{id}/assignee", orderRouter.handler.AssignOrder)
+
+	// DELETE {prefix}/{id}/assignee
+	r.With(perms.update).Delete("/{id}/assignee", orderRouter.handler.UnassignOrder)
+
+	// POST {prefix}/{id}/restore
+	r.With(perms.manage).Post("/{id}/restore", orderRouter.handler.RestoreOrder)
+
+	// POST {prefix}/{id}/duplicate
+	r.With(perms.read, perms.create).Post("/{id}/duplicate", orderRouter.handler.DuplicateOrder)
+
+	// GET {prefix}/{id}/attachments
+	r.With(perms.read).Get("/{id}/attachments", orderRouter.handler.ListAttachments)
+
+	// POST {prefix}/{id}/attachments, with the upload size limit
+	r.With(perms.update, middleware.MaxBodySize(maxAttachmentRequestBytes)).Post("/{id}/attachments", orderRouter.handler.UploadAttachment)
+
+	// DELETE {prefix}/{id}/attachments/{attachmentID}
+	r.With(perms.update).Delete("/{id}/attachments/{attachmentID}", orderRouter.handler.DeleteAttachment)
+
+	// GET {prefix}/{id}/comments
+	r.With(perms.read).Get("/{id}/comments", orderRouter.handler.ListComments)
+
+	// POST {prefix}/{id}/comments
+	r.With(perms.update).Post("/{id}/comments", orderRouter.handler.AddComment)
+
+	// DELETE {prefix}/{id}/comments/{commentID}
+	r.With(perms.update).Delete("/{id}/comments/{commentID}", orderRouter.handler.DeleteComment)
+
+	// GET {prefix}/{id}/tags
+	r.With(perms.read).Get("/{id}/tags", orderRouter.handler.GetOrderTags)
+
+	// PUT {prefix}/{id}/tags
+	r.With(perms.update).Put("/{id}/tags", orderRouter.handler.SetOrderTags)
+}
+
+// whenIncludingDeleted applies check only to requests that list deleted orders
+// with the include_deleted query parameter
+func whenIncludingDeleted(check func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		checked := check(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if includeDeleted, _ := strconv.ParseBool(r.URL.Query().Get("include_deleted")); includeDeleted {
+				checked.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
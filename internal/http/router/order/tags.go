@@ -0,0 +1,124 @@
+package order
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// renameTagRequest is the body of a request renaming a tag
+type renameTagRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+// orderTagsRequest is the body of a request replacing an order's tags
+type orderTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+// tagParam returns the tag in the URL path, unescaped if the router matched the
+// escaped path
+func tagParam(r *http.Request) string {
+	tag := chi.URLParam(r, "tag")
+	if r.URL.RawPath != "" {
+		if unescaped, err := url.PathUnescape(tag); err == nil {
+			return unescaped
+		}
+	}
+	return tag
+}
+
+// ListTags handles GET /orders/api/tags
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.tagService.ListTags(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to list tags")
+		return
+	}
+
+	// Return tags as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// RenameTag handles PUT /orders/api/tags/{tag} with {"name": "..."}
+func (h *Handler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	var req renameTagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	changed, err := h.tagService.RenameTag(r.Context(), tagParam(r), req.Name)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to rename tag")
+		return
+	}
+
+	// Return the number of orders changed as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"orders": changed})
+}
+
+// DeleteTag handles DELETE /orders/api/tags/{tag}, removing the tag from every order
+func (h *Handler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	changed, err := h.tagService.DeleteTag(r.Context(), tagParam(r))
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to delete tag")
+		return
+	}
+
+	// Return the number of orders changed as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"orders": changed})
+}
+
+// GetOrderTags handles GET /orders/api/{id}/tags
+func (h *Handler) GetOrderTags(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	tags, err := h.tagService.GetOrderTags(r.Context(), orderID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to get order tags")
+		return
+	}
+
+	// Return tags as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"tags": tags})
+}
+
+// SetOrderTags handles PUT /orders/api/{id}/tags with {"tags": [...]}
+func (h *Handler) SetOrderTags(w http.ResponseWriter, r *http.Request) {
+	// Parse order ID from URL
+	orderIDStr := chi.URLParam(r, "id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Invalid order ID"))
+		return
+	}
+
+	var req orderTagsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	tags, err := h.tagService.SetOrderTags(r.Context(), orderID, req.Tags)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to set order tags")
+		return
+	}
+
+	// Return the order's tags as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"tags": tags})
+}
@@ -0,0 +1,140 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/views/pages"
+)
+
+// ownershipConfirmPath is where new owners confirm a transfer from the emailed link
+const ownershipConfirmPath = "/tenants/ownership/confirm"
+
+// OwnershipRouter handles tenant ownership transfer routes
+type OwnershipRouter struct {
+	ownershipService tenantservice.OwnershipService
+}
+
+// NewOwnershipRouter creates a new OwnershipRouter with the required dependencies
+func NewOwnershipRouter(ownershipService tenantservice.OwnershipService) *OwnershipRouter {
+	return &OwnershipRouter{
+		ownershipService: ownershipService,
+	}
+}
+
+// transferOwnershipRequest is the request body for starting an ownership transfer
+type transferOwnershipRequest struct {
+	NewOwnerUserID int64 `json:"new_owner_user_id" validate:"required,min=1"`
+	// DemoteCurrentOwner revokes TENANT_SUPER from the requesting user once the transfer is confirmed
+	DemoteCurrentOwner bool `json:"demote_current_owner"`
+}
+
+// confirmOwnershipRequest is the request body for confirming an ownership transfer
+type confirmOwnershipRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// TransferOwnership handles POST /tenant/ownership/transfer. The new owner is emailed
+// a confirmation link; nothing changes until they confirm.
+func (or *OwnershipRouter) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req transferOwnershipRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	confirmURL := requestBaseURL(r) + ownershipConfirmPath
+	transfer, err := or.ownershipService.RequestTransfer(r.Context(), tenantID, userID, req.NewOwnerUserID, req.DemoteCurrentOwner, confirmURL)
+	if err != nil {
+		switch {
+		case errors.Is(err, tenantservice.ErrInvalidInput):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, tenantservice.ErrTransferEmailFailed):
+			http.Error(w, "Failed to send the confirmation email", http.StatusBadGateway)
+		default:
+			log.Printf("[ERROR] Failed to request ownership transfer for tenant ID %d: %v", tenantID, err)
+			http.Error(w, "Failed to request ownership transfer", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, transfer)
+}
+
+// ConfirmPage handles GET /tenants/ownership/confirm, the page the emailed link opens
+func (or *OwnershipRouter) ConfirmPage(w http.ResponseWriter, r *http.Request) {
+	data := pages.OwnershipConfirmData{Token: r.URL.Query().Get("token")}
+	if err := pages.OwnershipConfirm(data).Render(r.Context(), w); err != nil {
+		log.Printf("[ERROR] Failed to render ownership confirmation page: %v", err)
+	}
+}
+
+// ConfirmOwnership handles POST /tenants/ownership/confirm as the new owner. Browser
+// form submissions are redirected to the tenant; JSON requests receive the transfer.
+func (or *OwnershipRouter) ConfirmOwnership(w http.ResponseWriter, r *http.Request) {
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	isJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+
+	var req confirmOwnershipRequest
+	if isJSON {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form submission", http.StatusBadRequest)
+			return
+		}
+		req.Token = r.FormValue("token")
+	}
+	if !validRequest(w, r, &req) {
+		return
+	}
+
+	transfer, err := or.ownershipService.ConfirmTransfer(r.Context(), userID, strings.TrimSpace(req.Token))
+	if err != nil {
+		switch {
+		case errors.Is(err, tenantservice.ErrTransferNotFound):
+			http.Error(w, "Ownership transfer not found or expired", http.StatusNotFound)
+		case errors.Is(err, tenantservice.ErrInvalidInput):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("[ERROR] Failed to confirm ownership transfer for user ID %d: %v", userID, err)
+			http.Error(w, "Failed to confirm ownership transfer", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !isJSON {
+		if isHTMXRequest(r) {
+			w.Header().Set("HX-Redirect", onboardingRedirectPath)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Redirect(w, r, onboardingRedirectPath, http.StatusSeeOther)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transfer)
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// QuotaRouter handles tenant quota routes
+type QuotaRouter struct {
+	quotaService tenantservice.QuotaService
+}
+
+// NewQuotaRouter creates a new QuotaRouter with the required dependencies
+func NewQuotaRouter(quotaService tenantservice.QuotaService) *QuotaRouter {
+	return &QuotaRouter{
+		quotaService: quotaService,
+	}
+}
+
+// GetUsage handles GET /tenant/quota, reporting the current tenant's usage against its quota
+func (qr *QuotaRouter) GetUsage(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	qr.writeUsage(w, r, tenantID)
+}
+
+// GetTenantQuota handles GET /admin/tenants/{tenantID}/quota
+func (qr *QuotaRouter) GetTenantQuota(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	qr.writeUsage(w, r, tenantID)
+}
+
+// SetTenantQuota handles PUT /admin/tenants/{tenantID}/quota. Omitted or null limits are unlimited.
+func (qr *QuotaRouter) SetTenantQuota(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	var quota tenantservice.Quota
+	if err := json.NewDecoder(r.Body).Decode(&quota); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	quota.TenantID = tenantID
+
+	if err := qr.quotaService.SetQuota(r.Context(), &quota); err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to set quota for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to set quota", http.StatusInternalServerError)
+		return
+	}
+
+	qr.writeUsage(w, r, tenantID)
+}
+
+// GetRateLimitMetrics handles GET /admin/rate-limits, reporting the API requests
+// allowed and limited per tenant since the server started
+func (qr *QuotaRouter) GetRateLimitMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := qr.quotaService.GetRateLimitMetrics(r.Context())
+	if err != nil {
+		log.Printf("[ERROR] Failed to get rate limit metrics: %v", err)
+		http.Error(w, "Failed to get rate limit metrics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+// writeUsage responds with a tenant's usage against its quota
+func (qr *QuotaRouter) writeUsage(w http.ResponseWriter, r *http.Request, tenantID int64) {
+	usage, err := qr.quotaService.GetUsage(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get quota usage for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get quota usage", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// ReportRouter handles platform reporting routes for admins
+type ReportRouter struct {
+	reportService tenantservice.ReportService
+}
+
+// NewReportRouter creates a new ReportRouter with the required dependencies
+func NewReportRouter(reportService tenantservice.ReportService) *ReportRouter {
+	return &ReportRouter{
+		reportService: reportService,
+	}
+}
+
+// GetOrdersReport handles GET /admin/reports/orders. The optional from and to
+// query parameters (YYYY-MM-DD) default to the last 30 days.
+func (rr *ReportRouter) GetOrdersReport(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := rr.reportService.GetOrdersReport(r.Context(), from, to)
+	if err != nil {
+		writeReportError(w, "orders", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// GetGrowthReport handles GET /admin/reports/growth. The optional from and to
+// query parameters (YYYY-MM-DD) default to the last 30 days.
+func (rr *ReportRouter) GetGrowthReport(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := rr.reportService.GetGrowthReport(r.Context(), from, to)
+	if err != nil {
+		writeReportError(w, "growth", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// GetActiveUsersReport handles GET /admin/reports/active-users. The optional from
+// and to query parameters (YYYY-MM-DD) default to the last 30 days.
+func (rr *ReportRouter) GetActiveUsersReport(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := rr.reportService.GetActiveUsersReport(r.Context(), from, to)
+	if err != nil {
+		writeReportError(w, "active users", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// writeReportError maps report service errors to HTTP responses
+func writeReportError(w http.ResponseWriter, report string, err error) {
+	switch {
+	case errors.Is(err, transaction.ErrAdminRequired):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, tenantservice.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[ERROR] Failed to get %s report: %v", report, err)
+		http.Error(w, "Failed to get report", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,435 @@
+package router
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	authservice "github.com/unsavory/silocore-go/internal/auth/service"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+)
+
+// RoleRouter handles role management routes for platform administrators
+type RoleRouter struct {
+	roleService authservice.RoleService
+}
+
+// NewRoleRouter creates a new RoleRouter with the required dependencies
+func NewRoleRouter(roleService authservice.RoleService) *RoleRouter {
+	return &RoleRouter{
+		roleService: roleService,
+	}
+}
+
+// roleRequest is the request body for creating or updating a role
+type roleRequest struct {
+	Name        string `json:"name" validate:"required,max=64"`
+	Description string `json:"description"`
+}
+
+// roleAssignmentRequest is the request body for assigning a role to a user
+type roleAssignmentRequest struct {
+	RoleID int64 `json:"role_id" validate:"required,min=1"`
+}
+
+// ListRoles handles GET /admin/roles
+func (rr *RoleRouter) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := rr.roleService.GetRoles(r.Context())
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to list roles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// CreateRole handles POST /admin/roles
+func (rr *RoleRouter) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var req roleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	role, err := rr.roleService.CreateRole(r.Context(), &authservice.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to create role")
+		return
+	}
+
+	log.Printf("[INFO] Role %s (ID: %d) created", role.Name, role.ID)
+	writeJSON(w, http.StatusCreated, role)
+}
+
+// GetRole handles GET /admin/roles/{roleID}
+func (rr *RoleRouter) GetRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	role, err := rr.roleService.GetRole(r.Context(), roleID)
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to get role")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, role)
+}
+
+// UpdateRole handles PUT /admin/roles/{roleID}
+func (rr *RoleRouter) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	var req roleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	err := rr.roleService.UpdateRole(r.Context(), &authservice.Role{
+		ID:          roleID,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to update role")
+		return
+	}
+
+	log.Printf("[INFO] Role ID %d updated", roleID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteRole handles DELETE /admin/roles/{roleID}
+func (rr *RoleRouter) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	if err := rr.roleService.DeleteRole(r.Context(), roleID); err != nil {
+		writeRoleError(w, r, err, "Failed to delete role")
+		return
+	}
+
+	log.Printf("[INFO] Role ID %d deleted", roleID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListInheritedRoles handles GET /admin/roles/{roleID}/inherits
+func (rr *RoleRouter) ListInheritedRoles(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	roles, err := rr.roleService.GetInheritedRoles(r.Context(), roleID)
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to list inherited roles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// AddInheritedRole handles POST /admin/roles/{roleID}/inherits
+func (rr *RoleRouter) AddInheritedRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	inheritedRoleID, ok := decodeRoleAssignment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := rr.roleService.AddRoleInheritance(r.Context(), roleID, inheritedRoleID); err != nil {
+		writeRoleError(w, r, err, "Failed to add role inheritance")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveInheritedRole handles DELETE /admin/roles/{roleID}/inherits/{inheritedRoleID}
+func (rr *RoleRouter) RemoveInheritedRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	inheritedRoleID, ok := parseIDParam(w, r, "inheritedRoleID", "Invalid inherited role ID")
+	if !ok {
+		return
+	}
+
+	if err := rr.roleService.RemoveRoleInheritance(r.Context(), roleID, inheritedRoleID); err != nil {
+		writeRoleError(w, r, err, "Failed to remove role inheritance")
+		return
+	}
+
+	log.Printf("[INFO] Role ID %d no longer inherits role ID %d", roleID, inheritedRoleID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListUserRoles handles GET /admin/users/{userID}/roles
+func (rr *RoleRouter) ListUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "userID", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	roles, err := rr.roleService.GetUserRoles(r.Context(), userID)
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to list user roles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// AssignUserRole handles POST /admin/users/{userID}/roles
+func (rr *RoleRouter) AssignUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "userID", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	roleID, ok := decodeRoleAssignment(w, r)
+	if !ok {
+		return
+	}
+
+	// Ensure the role exists before assigning it
+	if _, err := rr.roleService.GetRole(r.Context(), roleID); err != nil {
+		writeRoleError(w, r, err, "Failed to assign role")
+		return
+	}
+
+	if err := rr.roleService.AssignUserRole(r.Context(), userID, roleID); err != nil {
+		writeRoleError(w, r, err, "Failed to assign role")
+		return
+	}
+
+	log.Printf("[INFO] Role ID %d assigned to user ID %d", roleID, userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeUserRole handles DELETE /admin/users/{userID}/roles/{roleID}
+func (rr *RoleRouter) RevokeUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "userID", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	if err := rr.roleService.RevokeUserRole(r.Context(), userID, roleID); err != nil {
+		writeRoleError(w, r, err, "Failed to revoke role")
+		return
+	}
+
+	log.Printf("[INFO] Role ID %d revoked from user ID %d", roleID, userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListUserTenantRoles handles GET /admin/users/{userID}/tenants/{tenantID}/roles
+func (rr *RoleRouter) ListUserTenantRoles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "userID", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	roles, err := rr.roleService.GetUserTenantRoles(r.Context(), userID, tenantID)
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to list tenant roles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// AssignTenantRole handles POST /admin/users/{userID}/tenants/{tenantID}/roles
+func (rr *RoleRouter) AssignTenantRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "userID", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	roleID, ok := decodeRoleAssignment(w, r)
+	if !ok {
+		return
+	}
+
+	// Ensure the role exists before assigning it
+	if _, err := rr.roleService.GetRole(r.Context(), roleID); err != nil {
+		writeRoleError(w, r, err, "Failed to assign tenant role")
+		return
+	}
+
+	if err := rr.roleService.AssignTenantRole(r.Context(), userID, tenantID, roleID); err != nil {
+		writeRoleError(w, r, err, "Failed to assign tenant role")
+		return
+	}
+
+	log.Printf("[INFO] Role ID %d assigned to user ID %d in tenant ID %d", roleID, userID, tenantID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeTenantRole handles DELETE /admin/users/{userID}/tenants/{tenantID}/roles/{roleID}
+func (rr *RoleRouter) RevokeTenantRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "userID", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	if err := rr.roleService.RevokeTenantRole(r.Context(), userID, tenantID, roleID); err != nil {
+		writeRoleError(w, r, err, "Failed to revoke tenant role")
+		return
+	}
+
+	log.Printf("[INFO] Role ID %d revoked from user ID %d in tenant ID %d", roleID, userID, tenantID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRoleAudit handles GET /admin/roles/audit
+func (rr *RoleRouter) ListRoleAudit(w http.ResponseWriter, r *http.Request) {
+	var filter authservice.RoleAuditFilter
+	var ok bool
+
+	if filter.TargetUserID, ok = parseOptionalIDQuery(w, r, "user_id", "Invalid user ID"); !ok {
+		return
+	}
+	if filter.ActorUserID, ok = parseOptionalIDQuery(w, r, "actor_id", "Invalid actor ID"); !ok {
+		return
+	}
+	if filter.TenantID, ok = parseOptionalIDQuery(w, r, "tenant_id", "Invalid tenant ID"); !ok {
+		return
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			apierror.Write(w, r, apierror.BadRequest("Invalid limit"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			apierror.Write(w, r, apierror.BadRequest("Invalid offset"))
+			return
+		}
+		filter.Offset = offset
+	}
+
+	entries, err := rr.roleService.GetRoleAuditLog(r.Context(), filter)
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to get role audit log")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// decodeRoleAssignment decodes and validates a role assignment request body
+func decodeRoleAssignment(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	var req roleAssignmentRequest
+	if !decodeJSON(w, r, &req) {
+		return 0, false
+	}
+
+	return req.RoleID, true
+}
+
+// roleErrors maps role service errors to API errors
+var roleErrors = []apierror.Mapping{
+	{Err: authservice.ErrRoleNotFound, Status: http.StatusNotFound, Code: codeRoleNotFound},
+	{Err: authservice.ErrRoleAssignmentNotFound, Status: http.StatusNotFound, Code: codeRoleNotFound},
+	{Err: authservice.ErrInheritanceNotFound, Status: http.StatusNotFound, Code: codeRoleNotFound},
+	{Err: authservice.ErrInvalidRole, Status: http.StatusBadRequest, Code: apierror.CodeInvalidInput},
+	{Err: authservice.ErrRoleAlreadyExists, Status: http.StatusConflict, Code: codeRoleConflict},
+	{Err: authservice.ErrLastAdmin, Status: http.StatusConflict, Code: codeRoleConflict},
+	{Err: authservice.ErrSystemRole, Status: http.StatusConflict, Code: codeRoleConflict},
+	{Err: authservice.ErrRoleCycle, Status: http.StatusConflict, Code: codeRoleConflict},
+}
+
+// writeRoleError maps role service errors to API errors, logging unexpected
+// errors and reporting them with the fallback message
+func writeRoleError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	if apiErr := apierror.Map(err, roleErrors); apiErr != nil {
+		apierror.Write(w, r, apiErr)
+		return
+	}
+
+	log.Printf("[ERROR] %s: %v", fallback, err)
+	apierror.Write(w, r, apierror.Internal(fallback))
+}
+
+// parseIDParam parses a numeric URL parameter, writing a 400 response on failure
+func parseIDParam(w http.ResponseWriter, r *http.Request, name string, message string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		apierror.Write(w, r, apierror.BadRequest(message))
+		return 0, false
+	}
+	return id, true
+}
+
+// parseOptionalIDQuery parses an optional int64 query parameter, writing a 400 response if it is invalid
+func parseOptionalIDQuery(w http.ResponseWriter, r *http.Request, name string, message string) (*int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(message))
+		return nil, false
+	}
+
+	return &id, true
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[ERROR] Failed to encode JSON response: %v", err)
+	}
+}
@@ -1,18 +1,20 @@
 package router
 
 import (
-	"time"
-
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/unsavory/silocore-go/internal/errortracking"
+	custommw "github.com/unsavory/silocore-go/internal/http/middleware"
 )
 
 // Options contains configuration for the router
 type Options struct {
 	EnableCORS        bool
 	EnableCompression bool
-	Timeout           time.Duration
+	Compression       custommw.CompressionConfig
+	Timeouts          custommw.TimeoutConfig
+	Proxies           custommw.ProxyConfig
 	Dependencies      RouterDependencies
 }
 
@@ -21,7 +23,8 @@ func DefaultOptions() Options {
 	return Options{
 		EnableCORS:        true,
 		EnableCompression: true,
-		Timeout:           60 * time.Second,
+		Compression:       custommw.DefaultCompressionConfig(),
+		Timeouts:          custommw.DefaultTimeoutConfig(),
 		Dependencies:      RouterDependencies{}, // This should be provided by the caller
 	}
 }
@@ -30,23 +33,31 @@ func DefaultOptions() Options {
 func New(opts Options) *chi.Mux {
 	r := chi.NewRouter()
 
+	reporter := opts.Dependencies.ErrorReporter
+	if reporter == nil {
+		reporter = errortracking.LogReporter{}
+	}
+
 	// Apply global middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	// Attribute requests to the clients that trusted proxies forward them for
+	r.Use(custommw.RealIP(&opts.Proxies))
 	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(opts.Timeout))
+	r.Use(custommw.Recover(reporter))
+
+	// Bound requests by the default timeout, which API and export routes move
+	r.Use(custommw.Timeout(opts.Timeouts))
 
 	if opts.EnableCompression {
-		r.Use(middleware.Compress(5))
+		r.Use(custommw.Compress(opts.Compression))
 	}
 
 	if opts.EnableCORS {
 		r.Use(cors.Handler(cors.Options{
 			AllowedOrigins:   []string{"https://*", "http://*"}, // Restrict as needed in configuration
-			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"Link"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key", "Idempotency-Key"},
+			ExposedHeaders:   []string{"Link", "Idempotent-Replayed"},
 			AllowCredentials: true,
 			MaxAge:           300, // Maximum value not readily exceeded by browsers
 		}))
@@ -2,15 +2,27 @@ package router
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	"github.com/unsavory/silocore-go/internal/auth/authz"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
 	"github.com/unsavory/silocore-go/internal/auth/jwt"
 	authservice "github.com/unsavory/silocore-go/internal/auth/service"
+	billingservice "github.com/unsavory/silocore-go/internal/billing/service"
+	"github.com/unsavory/silocore-go/internal/cache"
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/errortracking"
 	custommw "github.com/unsavory/silocore-go/internal/http/middleware"
+	"github.com/unsavory/silocore-go/internal/http/middleware/ratelimit"
 	"github.com/unsavory/silocore-go/internal/http/router/order"
+	"github.com/unsavory/silocore-go/internal/metrics"
 	orderservice "github.com/unsavory/silocore-go/internal/order/service"
 	"github.com/unsavory/silocore-go/internal/service"
 	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/tracing"
 )
 
 // RouterDependencies contains all dependencies needed for the router
@@ -18,21 +30,108 @@ type RouterDependencies struct {
 	Factory             *service.Factory
 	JWTService          custommw.JWTService
 	UserService         authservice.UserService
+	RoleService         authservice.RoleService
 	AuthService         authservice.AuthService
 	OrderService        orderservice.OrderService
 	RegistrationService authservice.RegistrationService
 	JWTAuthService      *jwt.Service
+	TenantService       tenantservice.TenantService
 	TenantMemberService tenantservice.TenantMemberService
+	InvitationService   tenantservice.InvitationService
+	QuotaService        tenantservice.QuotaService
+	UsageService        tenantservice.UsageService
+	BrandingService     tenantservice.BrandingService
+	ExportService       tenantservice.ExportService
+	AuditService        auditservice.AuditService
+	OwnershipService    tenantservice.OwnershipService
+	APIKeyService       tenantservice.APIKeyService
+	WebhookService      tenantservice.WebhookService
+	DomainService       tenantservice.DomainService
+	IPRuleService       tenantservice.IPRuleService
+	CloneService        tenantservice.CloneService
+	ArchiveService      tenantservice.ArchiveService
+	OnboardingService   tenantservice.OnboardingService
+	StatsService        tenantservice.StatsService
+	ReportService       tenantservice.ReportService
+	Authorizer          authz.Authorizer
+
+	// BillingService enables Stripe billing and plan feature gates when set
+	BillingService billingservice.BillingService
+
+	// HealthChecks are the dependencies checked by the readiness endpoint
+	HealthChecks []HealthCheck
+
+	// QueryMetrics enables the admin query duration report when set
+	QueryMetrics *database.QueryMetrics
+
+	// TenantBaseDomain enables resolving tenants from {slug}.TenantBaseDomain hosts
+	TenantBaseDomain string
+
+	// ReportStatementTimeout bounds the statements of platform reports instead
+	// of the transaction manager's statement timeout, unless it is zero
+	ReportStatementTimeout time.Duration
+
+	// TransactionConfig selects the routes served without a transaction and
+	// whether transactions begin lazily. The zero value runs every route in an
+	// eagerly begun transaction.
+	TransactionConfig transaction.MiddlewareConfig
+
+	// RateLimiter limits the requests of the login and registration forms by IP
+	// address, of authenticated routes by user and of tenant and order routes by
+	// tenant, when set
+	RateLimiter *ratelimit.Limiter
+
+	// Metrics records request metrics and serves them at /metrics when set,
+	// protected as configured by MetricsConfig
+	Metrics       *metrics.Metrics
+	MetricsConfig metrics.Config
+
+	// MaxBodyBytes limits the size of request bodies when positive. Order
+	// attachment uploads have their own limit.
+	MaxBodyBytes int64
+
+	// CSRFConfig configures the CSRF protection of browser requests. Nil uses
+	// custommw.DefaultCSRFConfig.
+	CSRFConfig *custommw.CSRFConfig
+
+	// IPFilterConfig holds the global IP access list, applied with the tenants'
+	// IP rules to authenticated requests. Nil applies only the tenants' rules.
+	IPFilterConfig *custommw.IPFilterConfig
+
+	// ErrorReporter receives the panics of handlers. Nil logs them.
+	ErrorReporter errortracking.Reporter
+
+	// IdempotencyStore keeps responses to requests with an Idempotency-Key for
+	// their retries. Nil ignores the header.
+	IdempotencyStore *cache.IdempotencyStore
 }
 
 // RegisterRoutes registers all application routes with proper authentication and authorization
 func RegisterRoutes(r chi.Router, deps RouterDependencies) {
+	// Fall back to the role-based authorizer if none is configured
+	if deps.Authorizer == nil {
+		deps.Authorizer = authz.NewRoleAuthorizer()
+	}
+	if deps.ErrorReporter == nil {
+		deps.ErrorReporter = errortracking.LogReporter{}
+	}
+
 	// Create a new router to apply middleware
 	router := chi.NewRouter()
 
-	// Apply transaction middleware to all routes if factory is available
+	// Limit the size of request bodies, before the CSRF middleware reads forms
+	router.Use(custommw.MaxBodySize(deps.MaxBodyBytes))
+
+	// Reject forged form submissions and HTMX requests before they begin a
+	// transaction
+	router.Use(custommw.CSRF(deps.CSRFConfig))
+
+	// Apply transaction middleware to all routes but the skipped ones if factory
+	// is available. Routes declare whether they need the transaction with
+	// transaction.Required and transaction.None; others begin it lazily, when
+	// transactions are lazy, once they first query.
 	if deps.Factory != nil {
-		router.Use(deps.Factory.TransactionManager().Middleware())
+		router.Use(deps.Factory.TransactionManager().MiddlewareWithConfig(deps.TransactionConfig))
 	}
 
 	// Register public routes (no authentication required)
@@ -40,94 +139,374 @@ func RegisterRoutes(r chi.Router, deps RouterDependencies) {
 
 	// Register protected routes (require authentication)
 	router.Group(func(r chi.Router) {
+		// Authenticate requests presenting a tenant API key
+		if deps.APIKeyService != nil {
+			r.Use(custommw.APIKeyMiddleware(deps.APIKeyService))
+		}
+
 		// Apply authentication middleware to all routes in this group
 		r.Use(custommw.AuthMiddleware(deps.JWTService))
 
 		// Apply role middleware to fetch and set user roles
-		r.Use(custommw.RoleMiddleware(deps.UserService, deps.TenantMemberService))
+		r.Use(custommw.RoleMiddleware(deps.UserService, deps.TenantMemberService, deps.TenantService))
+
+		// Recover from panics again once the user and tenant are known, so they
+		// are reported with them
+		r.Use(custommw.Recover(deps.ErrorReporter))
+
+		// Reject requests from IP addresses blocked globally or by the tenant,
+		// once authentication has set the tenant
+		r.Use(custommw.IPFilter(deps.IPFilterConfig, deps.IPRuleService, deps.AuditService))
+
+		// Limit each user's requests
+		r.Use(rateLimit(deps, ratelimit.GroupAPI, ratelimit.ByUser))
+
+		// Count requests against the tenant's API request limit
+		if deps.QuotaService != nil {
+			r.Use(custommw.EnforceAPIQuota(deps.QuotaService))
+		}
+
+		// Meter requests for usage reporting
+		if deps.UsageService != nil {
+			r.Use(custommw.MeterUsage(deps.UsageService))
+		}
+
+		// Render pages with the tenant's branding
+		if deps.BrandingService != nil {
+			r.Use(custommw.LoadBranding(deps.BrandingService))
+		}
 
 		// Admin routes
-		registerAdminRoutes(r)
+		registerAdminRoutes(r, deps)
+
+		// Self-service tenant creation
+		if deps.TenantService != nil && deps.AuthService != nil {
+			onboardingRouter := NewOnboardingRouter(deps.TenantService, deps.AuthService)
+			r.With(custommw.RequireSession).Post("/tenants", onboardingRouter.CreateTenant)
+		}
+
+		// Tenant switching for the header's tenant dropdown
+		if deps.TenantMemberService != nil && deps.TenantService != nil && deps.AuthService != nil {
+			switchRouter := NewTenantSwitchRouter(deps.TenantMemberService, deps.TenantService, deps.AuthService)
+			r.Route("/api/tenant/switch", func(r chi.Router) {
+				r.Use(custommw.RequireSession)
+
+				r.Get("/", switchRouter.ListTenants)
+				r.Post("/{tenantID}", switchRouter.SwitchTenant)
+				r.Put("/{tenantID}/default", switchRouter.SetDefaultTenant)
+			})
+		}
+
+		// Confirmation of tenant ownership transfers by the new owner
+		if deps.OwnershipService != nil {
+			ownershipRouter := NewOwnershipRouter(deps.OwnershipService)
+			r.With(custommw.RequireSession).Get(ownershipConfirmPath, ownershipRouter.ConfirmPage)
+			r.With(custommw.RequireSession).Post(ownershipConfirmPath, ownershipRouter.ConfirmOwnership)
+		}
 
 		// Tenant routes
-		registerTenantRoutes(r, deps.UserService, deps.TenantMemberService)
+		registerTenantRoutes(r, deps)
 
-		// Order routes
+		// Order routes, limited by tenant like the tenant routes, with retries
+		// of their changes replayed and the changes recorded in the tenant's
+		// audit log
 		if deps.Factory != nil {
-			order.RegisterRoutes(r, deps.Factory)
+			r.Group(func(r chi.Router) {
+				r.Use(rateLimit(deps, ratelimit.GroupTenant, ratelimit.ByTenant))
+				r.Use(custommw.Idempotency(deps.IdempotencyStore))
+				r.Use(auditRequests(deps))
+				order.RegisterRoutes(r, deps.Factory)
+			})
 		}
 	})
 
+	// Trace requests, continuing the trace of the caller
+	r.Use(tracing.Middleware)
+
+	// Record the count and duration of requests by route
+	if deps.Metrics != nil {
+		r.Use(deps.Metrics.Middleware)
+	}
+
+	// Resolve the tenant from the subdomain, custom domain or /t/{slug} prefix before
+	// routing and authentication
+	if deps.TenantService != nil {
+		r.Use(custommw.ResolveTenant(deps.TenantService, deps.DomainService, deps.TenantBaseDomain))
+	}
+
+	// Health checks skip the transaction middleware, so they answer while the
+	// database is down
+	healthRouter := NewHealthRouter(deps.HealthChecks...)
+	r.Get("/health", healthRouter.Ready)
+	r.Get("/health/ready", healthRouter.Ready)
+	r.Get("/health/live", healthRouter.Live)
+
+	// Prometheus scrapes metrics without a transaction too
+	if deps.Metrics != nil {
+		r.Method(http.MethodGet, "/metrics", deps.Metrics.Handler(deps.MetricsConfig))
+	}
+
+	// The versioned JSON API has its own middleware stack
+	registerAPIv1Routes(r, deps)
+
 	// Mount the router
 	r.Mount("/", router)
 }
 
+// registerAPIv1Routes registers the versioned JSON API under /api/v1. Unlike
+// the routes serving pages, it authenticates only with JWT bearer tokens, so
+// it needs no CSRF protection, reports errors as JSON and has its own request
+// timeout. Authentication runs before any transaction begins, and transactions
+// begin lazily, so reads only hold a connection, on a replica when there is
+// one, while they query.
+func registerAPIv1Routes(r chi.Router, deps RouterDependencies) {
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(custommw.JSONErrors)
+		r.Use(custommw.APITimeout)
+		r.Use(custommw.MaxBodySize(deps.MaxBodyBytes))
+
+		// Browser sessions' cookies aren't accepted, nor are API keys
+		r.Use(custommw.RequireBearerToken)
+		r.Use(custommw.AuthMiddleware(deps.JWTService))
+		r.Use(custommw.RoleMiddleware(deps.UserService, deps.TenantMemberService, deps.TenantService))
+		r.Use(custommw.Recover(deps.ErrorReporter))
+		r.Use(custommw.IPFilter(deps.IPFilterConfig, deps.IPRuleService, deps.AuditService))
+		r.Use(rateLimit(deps, ratelimit.GroupAPI, ratelimit.ByUser))
+		if deps.QuotaService != nil {
+			r.Use(custommw.EnforceAPIQuota(deps.QuotaService))
+		}
+		if deps.UsageService != nil {
+			r.Use(custommw.MeterUsage(deps.UsageService))
+		}
+
+		if deps.Factory != nil {
+			r.Use(deps.Factory.TransactionManager().MiddlewareWithConfig(transaction.MiddlewareConfig{
+				Lazy:           true,
+				RollbackStatus: deps.TransactionConfig.RollbackStatus,
+			}))
+
+			// Order routes, limited by tenant like the tenant routes, with retries
+			// of their changes replayed and the changes recorded in the tenant's
+			// audit log
+			r.Group(func(r chi.Router) {
+				r.Use(rateLimit(deps, ratelimit.GroupTenant, ratelimit.ByTenant))
+				r.Use(custommw.Idempotency(deps.IdempotencyStore))
+				r.Use(auditRequests(deps))
+				order.RegisterAPIRoutes(r, deps.Factory)
+			})
+		}
+	})
+}
+
+// rateLimit returns middleware limiting the requests of a route group by key, or
+// passing them through without a rate limiter
+func rateLimit(deps RouterDependencies, group string, key ratelimit.KeyFunc) func(http.Handler) http.Handler {
+	if deps.RateLimiter == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	return deps.RateLimiter.Group(group, key)
+}
+
+// auditRequests returns middleware recording the mutating requests of a route
+// group in the tenant's audit log, or passing them through without an audit
+// service
+func auditRequests(deps RouterDependencies) func(http.Handler) http.Handler {
+	if deps.AuditService == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	return custommw.AuditRequests(deps.AuditService)
+}
+
 // registerPublicRoutes registers routes that don't require authentication
 func registerPublicRoutes(r chi.Router, deps RouterDependencies) {
 	// Home page
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+	r.With(transaction.None).Get("/", func(w http.ResponseWriter, r *http.Request) {
 		// This could be a templ template rendering the home page
 		w.Write([]byte("Welcome to SiloCore"))
 	})
 
-	// Authentication routes
+	// Authentication routes. Pages render without the database; submitting a
+	// form needs a transaction and is limited by IP address, slowing down
+	// password guessing.
 	if deps.AuthService != nil && deps.JWTAuthService != nil {
 		// Create auth router with only the dependencies it needs
 		authRouter := NewAuthRouter(deps.AuthService, deps.RegistrationService, deps.JWTAuthService)
+		limitAuth := rateLimit(deps, ratelimit.GroupAuth, ratelimit.ByIP)
 
 		// Mount auth routes
-		r.Get("/login", authRouter.LoginPage)
-		r.Post("/login", authRouter.HandleLogin)
-		r.Get("/register", authRouter.RegisterPage)
-		r.Post("/register", authRouter.HandleRegister)
-		r.Get("/logout", authRouter.HandleLogout)
+		r.With(transaction.None).Get("/login", authRouter.LoginPage)
+		r.With(limitAuth, transaction.Required).Post("/login", authRouter.HandleLogin)
+		r.With(transaction.None).Get("/register", authRouter.RegisterPage)
+		r.With(limitAuth, transaction.Required).Post("/register", authRouter.HandleRegister)
+		r.With(transaction.None).Post("/logout", authRouter.HandleLogout)
 	} else {
 		// Fallback for when services aren't available
-		r.Get("/login", func(w http.ResponseWriter, r *http.Request) {
+		r.With(transaction.None).Get("/login", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("Login Page"))
 		})
-		r.Post("/login", func(w http.ResponseWriter, r *http.Request) {
+		r.With(transaction.None).Post("/login", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("Login Handler"))
 		})
-		r.Get("/register", func(w http.ResponseWriter, r *http.Request) {
+		r.With(transaction.None).Get("/register", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("Register Page"))
 		})
-		r.Post("/register", func(w http.ResponseWriter, r *http.Request) {
+		r.With(transaction.None).Post("/register", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("Register Handler"))
 		})
 	}
 
-	// Health check endpoint
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	// Stripe webhooks are authenticated by their signature
+	if deps.BillingService != nil {
+		billingRouter := NewBillingRouter(deps.BillingService)
+		r.With(transaction.Required).Post("/billing/webhook", billingRouter.HandleWebhook)
+	}
+
+	// Tenant export downloads are authenticated by their signed link
+	if deps.ExportService != nil {
+		exportRouter := NewExportRouter(deps.ExportService)
+		r.With(custommw.ExportTimeout).Get("/exports/{exportID}/download", exportRouter.DownloadExport)
+	}
+
+	// Order attachment downloads are authenticated by their signed link
+	if deps.Factory != nil {
+		order.RegisterPublicRoutes(r, deps.Factory)
+	}
+
+	// TLS proxies ask whether to issue certificates for custom domains
+	if deps.DomainService != nil {
+		domainRouter := NewDomainRouter(deps.DomainService)
+		r.Get("/domains/check", domainRouter.CheckDomain)
+	}
 }
 
 // registerAdminRoutes registers routes that require ADMIN role
-func registerAdminRoutes(r chi.Router) {
+func registerAdminRoutes(r chi.Router, deps RouterDependencies) {
 	r.Route("/admin", func(r chi.Router) {
 		// Apply admin middleware to all routes in this group
-		r.Use(custommw.RequireAdmin)
+		r.Use(custommw.Authorize(deps.Authorizer, "admin", "access", authctx.RoleAdmin))
 
 		// Create admin router with only the dependencies it needs
-		adminRouter := NewAdminRouter()
+		adminRouter := NewAdminRouter(deps.TenantService)
 
 		// Dashboard
 		r.Get("/", adminRouter.Dashboard)
 
 		// Tenant management
 		r.Route("/tenants", func(r chi.Router) {
+			// Answer clients polling JSON that hasn't changed with 304 Not Modified
+			r.Use(custommw.ConditionalGET)
+
 			r.Get("/", adminRouter.ListTenants)
 			r.Post("/", adminRouter.CreateTenant)
 
+			// Admin tenant pages
+			r.Get("/view", adminRouter.TenantsPage)
+
 			r.Route("/{tenantID}", func(r chi.Router) {
 				r.Get("/", adminRouter.GetTenant)
+				r.Get("/view", adminRouter.TenantPage)
 				r.Put("/", adminRouter.UpdateTenant)
 				r.Delete("/", adminRouter.DeleteTenant)
+
+				// Tenant status lifecycle
+				if deps.TenantService != nil {
+					r.Post("/suspend", adminRouter.SuspendTenant)
+					r.Post("/resume", adminRouter.ResumeTenant)
+					r.Post("/restore", adminRouter.RestoreTenant)
+				}
+
+				// Tenant cloning
+				if deps.CloneService != nil {
+					cloneRouter := NewCloneRouter(deps.CloneService)
+					r.Post("/clone", cloneRouter.CloneTenant)
+				}
+
+				// Tenant archival to object storage
+				if deps.ArchiveService != nil {
+					archiveRouter := NewArchiveRouter(deps.ArchiveService)
+					r.Post("/archive", archiveRouter.ArchiveTenant)
+					r.Post("/archive/restore", archiveRouter.RestoreArchivedTenant)
+					r.Get("/archives", archiveRouter.ListArchives)
+				}
+
+				// Tenant quotas
+				if deps.QuotaService != nil {
+					quotaRouter := NewQuotaRouter(deps.QuotaService)
+					r.Get("/quota", quotaRouter.GetTenantQuota)
+					r.Put("/quota", quotaRouter.SetTenantQuota)
+				}
+
+				// Tenant usage metering
+				if deps.UsageService != nil {
+					usageRouter := NewUsageRouter(deps.UsageService)
+					r.Get("/usage", usageRouter.GetTenantUsage)
+				}
 			})
 		})
 
+		// API request rate limit metrics per tenant
+		if deps.QuotaService != nil {
+			quotaRouter := NewQuotaRouter(deps.QuotaService)
+			r.Get("/rate-limits", quotaRouter.GetRateLimitMetrics)
+		}
+
+		// Database connection pool metrics
+		if deps.Factory != nil {
+			databaseRouter := NewDatabaseRouter(deps.Factory.TransactionManager().GetDB())
+			r.Get("/database/pool", databaseRouter.GetPoolStats)
+		}
+
+		// Query durations by operation
+		if deps.QueryMetrics != nil {
+			queryRouter := NewQueryMetricsRouter(deps.QueryMetrics)
+			r.Get("/database/queries", queryRouter.GetQueryStats)
+		}
+
+		// Platform reports across all tenants
+		if deps.ReportService != nil {
+			reportRouter := NewReportRouter(deps.ReportService)
+
+			r.Route("/reports", func(r chi.Router) {
+				// Reports aggregate across tenants and may run longer
+				if deps.Factory != nil && deps.ReportStatementTimeout > 0 {
+					r.Use(deps.Factory.TransactionManager().StatementTimeout(deps.ReportStatementTimeout))
+				}
+
+				r.Get("/orders", reportRouter.GetOrdersReport)
+				r.Get("/growth", reportRouter.GetGrowthReport)
+				r.Get("/active-users", reportRouter.GetActiveUsersReport)
+			})
+		}
+
+		// Role management
+		var roleRouter *RoleRouter
+		if deps.RoleService != nil {
+			roleRouter = NewRoleRouter(deps.RoleService)
+
+			r.Route("/roles", func(r chi.Router) {
+				r.Get("/", roleRouter.ListRoles)
+				r.Post("/", roleRouter.CreateRole)
+
+				// Audit trail of role assignments and revocations
+				r.Get("/audit", roleRouter.ListRoleAudit)
+
+				r.Route("/{roleID}", func(r chi.Router) {
+					r.Get("/", roleRouter.GetRole)
+					r.Put("/", roleRouter.UpdateRole)
+					r.Delete("/", roleRouter.DeleteRole)
+
+					// Role inheritance
+					r.Get("/inherits", roleRouter.ListInheritedRoles)
+					r.Post("/inherits", roleRouter.AddInheritedRole)
+					r.Delete("/inherits/{inheritedRoleID}", roleRouter.RemoveInheritedRole)
+				})
+			})
+		}
+
 		// User management
 		r.Route("/users", func(r chi.Router) {
 			r.Get("/", adminRouter.ListUsers)
@@ -137,51 +516,241 @@ func registerAdminRoutes(r chi.Router) {
 				r.Get("/", adminRouter.GetUser)
 				r.Put("/", adminRouter.UpdateUser)
 				r.Delete("/", adminRouter.DeleteUser)
+
+				if roleRouter != nil {
+					// System-wide role assignments
+					r.Route("/roles", func(r chi.Router) {
+						r.Get("/", roleRouter.ListUserRoles)
+						r.Post("/", roleRouter.AssignUserRole)
+						r.Delete("/{roleID}", roleRouter.RevokeUserRole)
+					})
+
+					// Tenant-specific role assignments
+					r.Route("/tenants/{tenantID}/roles", func(r chi.Router) {
+						r.Get("/", roleRouter.ListUserTenantRoles)
+						r.Post("/", roleRouter.AssignTenantRole)
+						r.Delete("/{roleID}", roleRouter.RevokeTenantRole)
+					})
+				}
 			})
 		})
 	})
 }
 
 // registerTenantRoutes registers routes that require tenant context
-func registerTenantRoutes(r chi.Router, userService authservice.UserService, tenantMemberService tenantservice.TenantMemberService) {
+func registerTenantRoutes(r chi.Router, deps RouterDependencies) {
 	r.Route("/tenant", func(r chi.Router) {
 		// Apply tenant context middleware to all routes in this group
 		r.Use(custommw.RequireTenantContext)
 
+		// Limit each tenant's requests
+		r.Use(rateLimit(deps, ratelimit.GroupTenant, ratelimit.ByTenant))
+
 		// If tenantMemberService is provided, require tenant membership
-		if tenantMemberService != nil {
-			r.Use(custommw.RequireTenantMember(tenantMemberService))
+		if deps.TenantMemberService != nil {
+			r.Use(custommw.RequireTenantMember(deps.TenantMemberService))
+		}
+
+		// Answer retries of changes sent with an Idempotency-Key with the first
+		// response, and record changes made to the tenant in its audit log
+		r.Use(custommw.Idempotency(deps.IdempotencyStore))
+		r.Use(auditRequests(deps))
+
+		// Scope the request's transaction to the tenant for row level security
+		if deps.Factory != nil {
+			r.Use(deps.Factory.TransactionManager().TenantContext())
+		}
+
+		// Answer clients polling JSON that hasn't changed with 304 Not Modified
+		r.Use(custommw.ConditionalGET)
+
+		// Statistics are cached, as they aggregate all of the tenant's orders
+		var responseCache *cache.ResponseCache
+		if deps.Factory != nil {
+			responseCache = deps.Factory.ResponseCache()
 		}
 
 		// Create tenant router with only the dependencies it needs
-		tenantRouter := NewTenantRouter(userService)
+		tenantRouter := NewTenantRouter(deps.UserService, deps.RoleService, deps.TenantService, deps.TenantMemberService, deps.StatsService)
 
-		// Dashboard
-		r.Get("/", tenantRouter.Dashboard)
+		// Permission checks for tenant operations
+		requirePermission := func(resource, action string) func(http.Handler) http.Handler {
+			return custommw.RequirePermission(deps.Authorizer, resource, action)
+		}
+
+		// Dashboard and statistics
+		r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/", tenantRouter.Dashboard)
+		r.With(requirePermission(authz.ResourceTenant, authz.ActionRead), custommw.ResponseCache(responseCache)).Get("/stats", tenantRouter.GetStats)
+
+		// Tenant quota usage
+		if deps.QuotaService != nil {
+			quotaRouter := NewQuotaRouter(deps.QuotaService)
+			r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/quota", quotaRouter.GetUsage)
+		}
+
+		// Tenant onboarding checklist
+		if deps.OnboardingService != nil {
+			checklistRouter := NewChecklistRouter(deps.OnboardingService)
+			r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/onboarding", checklistRouter.GetOnboarding)
+			r.With(requirePermission(authz.ResourceTenant, authz.ActionManage)).Post("/onboarding/dismiss", checklistRouter.DismissOnboarding)
+		}
+
+		// Tenant billing
+		if deps.BillingService != nil {
+			billingRouter := NewBillingRouter(deps.BillingService)
+
+			r.Route("/billing", func(r chi.Router) {
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/", billingRouter.GetBilling)
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/plans", billingRouter.ListPlans)
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionManage)).Post("/checkout", billingRouter.CreateCheckout)
+			})
+		}
+
+		// Tenant branding
+		if deps.BrandingService != nil {
+			brandingRouter := NewBrandingRouter(deps.BrandingService)
+
+			r.Route("/branding", func(r chi.Router) {
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/", brandingRouter.GetBranding)
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionUpdate)).Put("/", brandingRouter.UpdateBranding)
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/logo", brandingRouter.GetLogo)
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionUpdate)).Post("/logo", brandingRouter.UploadLogo)
+				r.With(requirePermission(authz.ResourceTenant, authz.ActionUpdate)).Delete("/logo", brandingRouter.DeleteLogo)
+			})
+		}
+
+		// Tenant data export
+		if deps.ExportService != nil {
+			exportRouter := NewExportRouter(deps.ExportService)
+
+			r.Route("/export", func(r chi.Router) {
+				r.Use(requirePermission(authz.ResourceTenant, authz.ActionManage))
+
+				r.Get("/", exportRouter.ListExports)
+				r.Post("/", exportRouter.RequestExport)
+				r.Get("/{exportID}", exportRouter.GetExport)
+			})
+		}
+
+		// Tenant audit log
+		if deps.AuditService != nil {
+			auditRouter := NewAuditRouter(deps.AuditService)
+			r.With(requirePermission(authz.ResourceTenant, authz.ActionManage)).Get("/audit", auditRouter.ListAuditEntries)
+		}
+
+		// Tenant ownership transfer
+		if deps.OwnershipService != nil {
+			ownershipRouter := NewOwnershipRouter(deps.OwnershipService)
+			r.With(custommw.RequireSession, requirePermission(authz.ResourceTenant, authz.ActionManage)).Post("/ownership/transfer", ownershipRouter.TransferOwnership)
+		}
+
+		// Tenant API keys, managed by signed-in tenant owners
+		if deps.APIKeyService != nil {
+			apiKeyRouter := NewAPIKeyRouter(deps.APIKeyService)
+
+			r.Route("/api-keys", func(r chi.Router) {
+				r.Use(custommw.RequireSession)
+				r.Use(requirePermission(authz.ResourceTenant, authz.ActionManage))
+
+				r.Get("/", apiKeyRouter.ListAPIKeys)
+				r.Post("/", apiKeyRouter.CreateAPIKey)
+				r.Delete("/{keyID}", apiKeyRouter.RevokeAPIKey)
+			})
+		}
+
+		// Tenant webhooks
+		if deps.WebhookService != nil {
+			webhookRouter := NewWebhookRouter(deps.WebhookService)
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Use(requirePermission(authz.ResourceTenant, authz.ActionManage))
+
+				r.Get("/", webhookRouter.ListWebhooks)
+				r.Post("/", webhookRouter.CreateWebhook)
+				r.Get("/{webhookID}", webhookRouter.GetWebhook)
+				r.Put("/{webhookID}", webhookRouter.UpdateWebhook)
+				r.Delete("/{webhookID}", webhookRouter.DeleteWebhook)
+				r.Get("/{webhookID}/deliveries", webhookRouter.ListWebhookDeliveries)
+			})
+		}
+
+		// Tenant custom domains
+		if deps.DomainService != nil {
+			domainRouter := NewDomainRouter(deps.DomainService)
+
+			r.Route("/domains", func(r chi.Router) {
+				r.Use(requirePermission(authz.ResourceTenant, authz.ActionManage))
+
+				r.Get("/", domainRouter.ListDomains)
+				r.Post("/", domainRouter.AddDomain)
+				r.Post("/{domainID}/verify", domainRouter.VerifyDomain)
+				r.Delete("/{domainID}", domainRouter.RemoveDomain)
+			})
+		}
+
+		// Tenant IP allow and deny rules
+		if deps.IPRuleService != nil {
+			ipRuleRouter := NewIPRuleRouter(deps.IPRuleService)
+
+			r.Route("/ip-rules", func(r chi.Router) {
+				r.Use(requirePermission(authz.ResourceTenant, authz.ActionManage))
+
+				r.Get("/", ipRuleRouter.ListIPRules)
+				r.Post("/", ipRuleRouter.AddIPRule)
+				r.Delete("/{ruleID}", ipRuleRouter.RemoveIPRule)
+			})
+		}
 
 		// Tenant profile
 		r.Route("/profile", func(r chi.Router) {
-			r.Get("/", tenantRouter.GetProfile)
-			r.Put("/", tenantRouter.UpdateProfile)
+			r.With(requirePermission(authz.ResourceTenant, authz.ActionRead)).Get("/", tenantRouter.GetProfile)
+			r.With(requirePermission(authz.ResourceTenant, authz.ActionUpdate)).Put("/", tenantRouter.UpdateProfile)
 		})
 
 		// Tenant members
 		r.Route("/members", func(r chi.Router) {
-			r.Get("/", tenantRouter.ListMembers)
-			r.Post("/", tenantRouter.AddMember)
+			r.With(requirePermission(authz.ResourceMembers, authz.ActionRead)).Get("/", tenantRouter.ListMembers)
+			r.With(requirePermission(authz.ResourceMembers, authz.ActionCreate)).Post("/", tenantRouter.AddMember)
+
+			// Invitations to join the tenant
+			if deps.InvitationService != nil {
+				invitationRouter := NewInvitationRouter(deps.InvitationService)
+
+				r.Route("/invitations", func(r chi.Router) {
+					r.Use(requirePermission(authz.ResourceMembers, authz.ActionCreate))
+
+					// Invitations are a paid feature when billing is enabled
+					if deps.BillingService != nil {
+						r.Use(custommw.RequireFeature(deps.BillingService, billingservice.FeatureInvitations))
+					}
+
+					r.Get("/", invitationRouter.ListInvitations)
+					r.Post("/", invitationRouter.CreateInvitation)
+					r.Delete("/{invitationID}", invitationRouter.RevokeInvitation)
+				})
+			}
 
 			// Tenant super routes
 			r.Route("/admin", func(r chi.Router) {
-				// Apply tenant super middleware
-				r.Use(custommw.RequireTenantSuper)
+				r.Use(requirePermission(authz.ResourceTenant, authz.ActionManage))
 
 				r.Get("/", tenantRouter.AdminDashboard)
 			})
 
 			r.Route("/{memberID}", func(r chi.Router) {
-				r.Get("/", tenantRouter.GetMember)
-				r.Put("/", tenantRouter.UpdateMember)
-				r.Delete("/", tenantRouter.RemoveMember)
+				r.With(requirePermission(authz.ResourceMembers, authz.ActionRead)).Get("/", tenantRouter.GetMember)
+				r.With(requirePermission(authz.ResourceMembers, authz.ActionUpdate)).Put("/", tenantRouter.UpdateMember)
+				r.With(requirePermission(authz.ResourceMembers, authz.ActionDelete)).Delete("/", tenantRouter.RemoveMember)
+
+				// Member tenant roles
+				if deps.RoleService != nil && deps.TenantMemberService != nil {
+					r.Route("/roles", func(r chi.Router) {
+						r.Use(requirePermission(authz.ResourceMembers, authz.ActionManage))
+
+						r.Get("/", tenantRouter.GetMemberRoles)
+						r.Put("/", tenantRouter.UpdateMemberRoles)
+					})
+				}
 			})
 		})
 	})
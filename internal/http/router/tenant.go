@@ -1,26 +1,101 @@
 package router
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"sort"
+	"strconv"
 
 	authservice "github.com/unsavory/silocore-go/internal/auth/service"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/views/pages"
 )
 
 // TenantRouter handles tenant-related routes
 type TenantRouter struct {
-	userService authservice.UserService
+	userService         authservice.UserService
+	roleService         authservice.RoleService
+	tenantService       tenantservice.TenantService
+	tenantMemberService tenantservice.TenantMemberService
+	statsService        tenantservice.StatsService
 }
 
 // NewTenantRouter creates a new TenantRouter with the required dependencies
-func NewTenantRouter(userService authservice.UserService) *TenantRouter {
+func NewTenantRouter(userService authservice.UserService, roleService authservice.RoleService, tenantService tenantservice.TenantService, tenantMemberService tenantservice.TenantMemberService, statsService tenantservice.StatsService) *TenantRouter {
 	return &TenantRouter{
-		userService: userService,
+		userService:         userService,
+		roleService:         roleService,
+		tenantService:       tenantService,
+		tenantMemberService: tenantMemberService,
+		statsService:        statsService,
 	}
 }
 
-// Dashboard renders the tenant dashboard
+// memberRolesRequest is the request body for replacing a member's tenant roles
+type memberRolesRequest struct {
+	RoleIDs []int64 `json:"role_ids" validate:"dive,min=1"`
+}
+
+// Dashboard renders the tenant dashboard with the tenant's statistics
 func (tr *TenantRouter) Dashboard(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("Tenant Dashboard"))
+	stats, ok := tr.requireStats(w, r)
+	if !ok {
+		return
+	}
+
+	data := pages.TenantDashboardData{
+		Members:       stats.Members,
+		Orders:        stats.Orders.Total,
+		Revenue:       stats.Revenue.Total,
+		RecentRevenue: stats.Revenue.Recent,
+	}
+	for status, count := range stats.Orders.ByStatus {
+		data.OrdersByStatus = append(data.OrdersByStatus, pages.StatusCount{Status: status, Count: count})
+	}
+	sort.Slice(data.OrdersByStatus, func(i, j int) bool { return data.OrdersByStatus[i].Status < data.OrdersByStatus[j].Status })
+	for _, activity := range stats.RecentActivity {
+		data.RecentActivity = append(data.RecentActivity, pages.DashboardActivity{
+			Action:       activity.Action,
+			ResourceType: activity.ResourceType,
+			ResourceID:   activity.ResourceID,
+			CreatedAt:    activity.CreatedAt,
+		})
+	}
+
+	if err := pages.TenantDashboard(data).Render(r.Context(), w); err != nil {
+		log.Printf("[ERROR] Failed to render tenant dashboard: %v", err)
+	}
+}
+
+// GetStats handles GET /tenant/stats, returning the member count, order counts and
+// revenue by status, and recent activity of the current tenant
+func (tr *TenantRouter) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, ok := tr.requireStats(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// requireStats computes the current tenant's statistics, writing an error response
+// if they can't be computed
+func (tr *TenantRouter) requireStats(w http.ResponseWriter, r *http.Request) (*tenantservice.TenantStats, bool) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	stats, err := tr.statsService.GetTenantStats(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get statistics of tenant ID %d: %v", tenantID, err)
+		apierror.Write(w, r, apierror.Internal("Failed to get tenant statistics"))
+		return nil, false
+	}
+
+	return stats, true
 }
 
 // GetProfile renders the tenant profile
@@ -33,9 +108,47 @@ func (tr *TenantRouter) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Update tenant profile"))
 }
 
-// ListMembers lists all tenant members
+// ListMembers handles GET /tenant/members, listing members with their names,
+// emails, join dates and tenant roles. Members can be searched by email and paged
+// with limit and offset.
 func (tr *TenantRouter) ListMembers(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("List tenant members"))
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := tenantservice.TenantMemberFilter{
+		TenantID: tenantID,
+		Email:    query.Get("email"),
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			apierror.Write(w, r, apierror.BadRequest("Invalid limit"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			apierror.Write(w, r, apierror.BadRequest("Invalid offset"))
+			return
+		}
+		filter.Offset = offset
+	}
+
+	members, err := tr.tenantService.GetTenantMembers(r.Context(), filter)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list members of tenant ID %d: %v", tenantID, err)
+		apierror.Write(w, r, apierror.Internal("Failed to list members"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, members)
 }
 
 // AddMember adds a new tenant member
@@ -62,3 +175,97 @@ func (tr *TenantRouter) UpdateMember(w http.ResponseWriter, r *http.Request) {
 func (tr *TenantRouter) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Remove member"))
 }
+
+// GetMemberRoles handles GET /tenant/members/{memberID}/roles
+func (tr *TenantRouter) GetMemberRoles(w http.ResponseWriter, r *http.Request) {
+	tenantID, memberID, ok := tr.requireMember(w, r)
+	if !ok {
+		return
+	}
+
+	roles, err := tr.roleService.GetUserTenantRoles(r.Context(), memberID, tenantID)
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to get member roles")
+		return
+	}
+
+	if roles == nil {
+		roles = []authservice.Role{}
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// UpdateMemberRoles handles PUT /tenant/members/{memberID}/roles.
+// The member's tenant roles are replaced with the roles in the request.
+func (tr *TenantRouter) UpdateMemberRoles(w http.ResponseWriter, r *http.Request) {
+	tenantID, memberID, ok := tr.requireMember(w, r)
+	if !ok {
+		return
+	}
+
+	var req memberRolesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	ctx := r.Context()
+
+	// Validate every requested role before changing anything
+	for _, roleID := range req.RoleIDs {
+		role, err := tr.roleService.GetRole(ctx, roleID)
+		if err != nil {
+			writeRoleError(w, r, err, "Failed to get role")
+			return
+		}
+		if !authservice.IsTenantAssignableRole(role.Name) {
+			writeRoleError(w, r, fmt.Errorf("%w: %s cannot be assigned as a tenant role", authservice.ErrInvalidRole, role.Name), "Invalid role")
+			return
+		}
+	}
+
+	if _, err := tr.roleService.ReplaceTenantRoles(ctx, memberID, tenantID, req.RoleIDs); err != nil {
+		writeRoleError(w, r, err, "Failed to update member roles")
+		return
+	}
+
+	log.Printf("[INFO] Tenant roles for member ID %d in tenant ID %d updated", memberID, tenantID)
+
+	roles, err := tr.roleService.GetUserTenantRoles(ctx, memberID, tenantID)
+	if err != nil {
+		writeRoleError(w, r, err, "Failed to get member roles")
+		return
+	}
+
+	if roles == nil {
+		roles = []authservice.Role{}
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// requireMember resolves the tenant from context and the member from the URL,
+// verifying the member belongs to the tenant
+func (tr *TenantRouter) requireMember(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+
+	memberID, ok := parseIDParam(w, r, "memberID", "Invalid member ID")
+	if !ok {
+		return 0, 0, false
+	}
+
+	isMember, err := tr.tenantMemberService.IsTenantMember(r.Context(), memberID, tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to verify membership of user ID %d in tenant ID %d: %v", memberID, tenantID, err)
+		apierror.Write(w, r, apierror.Internal("Failed to verify tenant membership"))
+		return 0, 0, false
+	}
+
+	if !isMember {
+		apierror.Write(w, r, errMemberNotFound)
+		return 0, 0, false
+	}
+
+	return tenantID, memberID, true
+}
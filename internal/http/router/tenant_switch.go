@@ -0,0 +1,157 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	authservice "github.com/unsavory/silocore-go/internal/auth/service"
+	"github.com/unsavory/silocore-go/internal/http/apierror"
+	custommw "github.com/unsavory/silocore-go/internal/http/middleware"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"github.com/unsavory/silocore-go/internal/views/components"
+)
+
+// TenantSwitchRouter handles switching the tenant context of the signed-in user
+type TenantSwitchRouter struct {
+	tenantMemberService tenantservice.TenantMemberService
+	tenantService       tenantservice.TenantService
+	authService         authservice.AuthService
+}
+
+// NewTenantSwitchRouter creates a new TenantSwitchRouter with the required dependencies
+func NewTenantSwitchRouter(tenantMemberService tenantservice.TenantMemberService, tenantService tenantservice.TenantService, authService authservice.AuthService) *TenantSwitchRouter {
+	return &TenantSwitchRouter{
+		tenantMemberService: tenantMemberService,
+		tenantService:       tenantService,
+		authService:         authService,
+	}
+}
+
+// switchTenantResponse is the response body for a tenant switch made with a JSON request
+type switchTenantResponse struct {
+	// AccessToken is scoped to the selected tenant
+	AccessToken string `json:"access_token"`
+}
+
+// ListTenants handles GET /api/tenant/switch, rendering the user's tenants for the
+// header's tenant dropdown
+func (sr *TenantSwitchRouter) ListTenants(w http.ResponseWriter, r *http.Request) {
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	memberships, err := sr.tenantMemberService.GetUserTenantMemberships(r.Context(), userID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to get tenant memberships for user ID %d: %v", userID, err)
+		http.Error(w, "Failed to list tenants", http.StatusInternalServerError)
+		return
+	}
+
+	var currentTenantID int64
+	if tenantID, err := authctx.GetTenantID(r.Context()); err == nil && tenantID != nil {
+		currentTenantID = *tenantID
+	}
+
+	options := make([]components.TenantOption, 0, len(memberships))
+	for _, membership := range memberships {
+		tenant, err := sr.tenantService.GetTenant(r.Context(), membership.TenantID)
+		if err != nil {
+			// Deleted tenants keep their members until they are purged
+			if errors.Is(err, tenantservice.ErrTenantNotFound) {
+				continue
+			}
+			log.Printf("[ERROR] Failed to get tenant ID %d for user ID %d: %v", membership.TenantID, userID, err)
+			http.Error(w, "Failed to list tenants", http.StatusInternalServerError)
+			return
+		}
+
+		options = append(options, components.TenantOption{
+			ID:      tenant.ID,
+			Name:    tenant.Name,
+			Current: tenant.ID == currentTenantID,
+			Default: membership.IsDefault,
+		})
+	}
+
+	if err := components.TenantSwitcher(options).Render(r.Context(), w); err != nil {
+		log.Printf("[ERROR] Failed to render tenant switcher: %v", err)
+	}
+}
+
+// SwitchTenant handles POST /api/tenant/switch/{tenantID}. The auth cookie is replaced
+// with a token scoped to the tenant and HTMX requests reload the page. JSON requests
+// receive the new token instead.
+func (sr *TenantSwitchRouter) SwitchTenant(w http.ResponseWriter, r *http.Request) {
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		apierror.Write(w, r, apierror.Unauthorized("Authentication required"))
+		return
+	}
+
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	token, err := sr.authService.SwitchTenantContext(r.Context(), userID, custommw.TokenFromRequest(r), &tenantID)
+	if err != nil {
+		if errors.Is(err, authservice.ErrUnauthorized) {
+			apierror.Write(w, r, errNotTenantMember)
+			return
+		}
+		log.Printf("[ERROR] Failed to switch user ID %d to tenant ID %d: %v", userID, tenantID, err)
+		apierror.Write(w, r, apierror.Internal("Failed to switch tenant"))
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Accept"), "application/json") {
+		writeJSON(w, http.StatusOK, switchTenantResponse{AccessToken: token})
+		return
+	}
+
+	setAuthCookie(w, r, token)
+
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Refresh", "true")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	http.Redirect(w, r, onboardingRedirectPath, http.StatusSeeOther)
+}
+
+// SetDefaultTenant handles PUT /api/tenant/switch/{tenantID}/default, making the tenant
+// the one the user lands in after logging in. HTMX requests reload the page so the
+// dropdown shows the new default.
+func (sr *TenantSwitchRouter) SetDefaultTenant(w http.ResponseWriter, r *http.Request) {
+	userID, err := authctx.GetUserID(r.Context())
+	if err != nil {
+		apierror.Write(w, r, apierror.Unauthorized("Authentication required"))
+		return
+	}
+
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	if err := sr.tenantMemberService.SetUserDefaultTenant(r.Context(), userID, tenantID); err != nil {
+		if errors.Is(err, tenantservice.ErrMemberNotFound) {
+			apierror.Write(w, r, errNotTenantMember)
+			return
+		}
+		log.Printf("[ERROR] Failed to set default tenant ID %d for user ID %d: %v", tenantID, userID, err)
+		apierror.Write(w, r, apierror.Internal("Failed to set default tenant"))
+		return
+	}
+
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Refresh", "true")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
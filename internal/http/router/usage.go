@@ -0,0 +1,76 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// defaultUsageReportDays is the number of days reported when no range is given
+const defaultUsageReportDays = 30
+
+// UsageRouter handles tenant usage metering routes
+type UsageRouter struct {
+	usageService tenantservice.UsageService
+}
+
+// NewUsageRouter creates a new UsageRouter with the required dependencies
+func NewUsageRouter(usageService tenantservice.UsageService) *UsageRouter {
+	return &UsageRouter{
+		usageService: usageService,
+	}
+}
+
+// GetTenantUsage handles GET /admin/tenants/{tenantID}/usage. The optional from and
+// to query parameters (YYYY-MM-DD) default to the last 30 days.
+func (ur *UsageRouter) GetTenantUsage(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := parseIDParam(w, r, "tenantID", "Invalid tenant ID")
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := ur.usageService.GetUsageReport(r.Context(), tenantID, from, to)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to get usage for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to get usage", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// parseDateRange parses the optional from and to query parameters (YYYY-MM-DD) of
+// a report, defaulting to the last defaultUsageReportDays days. It writes a
+// 400 response and returns false if a date is invalid.
+func parseDateRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -(defaultUsageReportDays - 1))
+
+	var err error
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = time.Parse(time.DateOnly, value); err != nil {
+			http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = time.Parse(time.DateOnly, value); err != nil {
+			http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,209 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// WebhookRouter handles tenant webhook routes
+type WebhookRouter struct {
+	webhookService tenantservice.WebhookService
+}
+
+// NewWebhookRouter creates a new WebhookRouter with the required dependencies
+func NewWebhookRouter(webhookService tenantservice.WebhookService) *WebhookRouter {
+	return &WebhookRouter{
+		webhookService: webhookService,
+	}
+}
+
+// webhookRequest is the request body for creating or updating a webhook
+type webhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+	// Secret is only used when creating a webhook; one is generated if omitted
+	Secret string `json:"secret,omitempty"`
+}
+
+// webhookResponse is the response body for a newly created webhook
+type webhookResponse struct {
+	tenantservice.Webhook
+	// Secret signs deliveries and is only returned when the webhook is created
+	Secret string `json:"secret"`
+}
+
+// ListWebhooks handles GET /tenant/webhooks
+func (wr *WebhookRouter) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	webhooks, err := wr.webhookService.ListWebhooks(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to list webhooks for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to list webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+// CreateWebhook handles POST /tenant/webhooks
+func (wr *WebhookRouter) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook := &tenantservice.Webhook{
+		TenantID:   tenantID,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+
+	webhook, err := wr.webhookService.CreateWebhook(r.Context(), webhook)
+	if err != nil {
+		if errors.Is(err, tenantservice.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[ERROR] Failed to create webhook for tenant ID %d: %v", tenantID, err)
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/tenant/webhooks/%d", webhook.ID))
+	writeJSON(w, http.StatusCreated, webhookResponse{Webhook: *webhook, Secret: webhook.Secret})
+}
+
+// GetWebhook handles GET /tenant/webhooks/{webhookID}
+func (wr *WebhookRouter) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "webhookID", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	webhook, err := wr.webhookService.GetWebhook(r.Context(), tenantID, webhookID)
+	if err != nil {
+		writeWebhookError(w, err, "get", webhookID, tenantID)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+// UpdateWebhook handles PUT /tenant/webhooks/{webhookID}. The secret can't be changed.
+func (wr *WebhookRouter) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "webhookID", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook := &tenantservice.Webhook{
+		ID:         webhookID,
+		TenantID:   tenantID,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+
+	if err := wr.webhookService.UpdateWebhook(r.Context(), webhook); err != nil {
+		writeWebhookError(w, err, "update", webhookID, tenantID)
+		return
+	}
+
+	webhook, err := wr.webhookService.GetWebhook(r.Context(), tenantID, webhookID)
+	if err != nil {
+		writeWebhookError(w, err, "get", webhookID, tenantID)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles DELETE /tenant/webhooks/{webhookID}
+func (wr *WebhookRouter) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "webhookID", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := wr.webhookService.DeleteWebhook(r.Context(), tenantID, webhookID); err != nil {
+		writeWebhookError(w, err, "delete", webhookID, tenantID)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /tenant/webhooks/{webhookID}/deliveries,
+// returning the webhook's latest delivery attempts, newest first
+func (wr *WebhookRouter) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "webhookID", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	deliveries, err := wr.webhookService.ListWebhookDeliveries(r.Context(), tenantID, webhookID)
+	if err != nil {
+		writeWebhookError(w, err, "list deliveries of", webhookID, tenantID)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// writeWebhookError maps webhook service errors to responses
+func writeWebhookError(w http.ResponseWriter, err error, operation string, webhookID int64, tenantID int64) {
+	switch {
+	case errors.Is(err, tenantservice.ErrWebhookNotFound):
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+	case errors.Is(err, tenantservice.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[ERROR] Failed to %s webhook %d for tenant ID %d: %v", operation, webhookID, tenantID, err)
+		http.Error(w, fmt.Sprintf("Failed to %s webhook", operation), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,252 @@
+// Package validate checks request DTOs against rules declared in their
+// `validate` struct tags, in the style of go-playground/validator:
+//
+//	type commentRequest struct {
+//		Body string `json:"body" validate:"required,max=5000"`
+//	}
+//
+// Struct reports every invalid field rather than the first, named as clients
+// send it: by its json tag, or its form tag for form submissions. Rules are
+// separated by commas and checked in order; a field fails on its first broken
+// rule.
+//
+//   - required: not the zero value. Strings must have a non-space character and
+//     pointers must be non-nil.
+//   - omitempty: skip the field's other rules if it is the zero value.
+//   - min=n, max=n: the length of strings (in characters), slices and maps, or
+//     the value of numbers.
+//   - email: an email address such as jane@example.com.
+//   - oneof=a b c: one of the space-separated strings.
+//   - eqfield=Name: equal to the struct's field Name, such as a confirmed password.
+//   - dive: apply the rules after it to each element of a slice.
+//
+// Pointers are checked by the value they point to, and structs, slices of
+// structs with dive, and pointers to structs are checked field by field, e.g.
+// items[2].sku. Unknown rules panic, as they are programming errors.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError is a field that broke one of its rules
+type FieldError struct {
+	// Field is the path of the field, e.g. name or items[2].sku
+	Field string `json:"field"`
+
+	// Message says what is wrong with the field, e.g. "is required"
+	Message string `json:"message"`
+}
+
+// Errors are the invalid fields of a value, in the order of its fields
+type Errors []FieldError
+
+// Error lists the invalid fields, e.g. "name is required; email must be a valid
+// email address"
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Struct checks the fields of v, a struct or a pointer to one, against their
+// rules, returning every invalid field or nil if all are valid
+func Struct(v interface{}) Errors {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: Struct called with %s, not a struct", value.Type()))
+	}
+
+	var errs Errors
+	checkStruct(value, "", &errs)
+	return errs
+}
+
+// timeType is skipped when checking nested structs, as it has no fields to check
+var timeType = reflect.TypeOf(time.Time{})
+
+// checkStruct checks the fields of a struct, whose path is prefix
+func checkStruct(value reflect.Value, prefix string, errs *Errors) {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		path := prefix + fieldName(field)
+		rules := splitRules(field.Tag.Get("validate"))
+		checkValue(value.Field(i), value, path, rules, errs)
+	}
+}
+
+// checkValue checks a value against its rules. parent is the struct holding the
+// field, for rules comparing fields.
+func checkValue(value, parent reflect.Value, path string, rules []string, errs *Errors) {
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "omitempty":
+			if value.IsZero() {
+				return
+			}
+			continue
+		case "required":
+			if isEmpty(value) {
+				errs.Add(path, "is required")
+				return
+			}
+			continue
+		case "dive":
+			elems := indirect(value)
+			if elems.Kind() != reflect.Slice && elems.Kind() != reflect.Array {
+				panic(fmt.Sprintf("validate: dive on %s of %s, not a slice", path, value.Type()))
+			}
+			for j := 0; j < elems.Len(); j++ {
+				checkValue(elems.Index(j), parent, fmt.Sprintf("%s[%d]", path, j), rules[i+1:], errs)
+			}
+			return
+		}
+
+		// The remaining rules check the value pointed to
+		target := indirect(value)
+		if !target.IsValid() {
+			return
+		}
+		if message, ok := checkRule(name, param, target, parent, path); !ok {
+			errs.Add(path, message)
+			return
+		}
+	}
+
+	if target := indirect(value); target.IsValid() && target.Kind() == reflect.Struct && target.Type() != timeType {
+		checkStruct(target, path+".", errs)
+	}
+}
+
+// checkRule checks a value against a rule other than omitempty, required and
+// dive, returning what is wrong with it if it breaks the rule
+func checkRule(name, param string, value, parent reflect.Value, path string) (string, bool) {
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid %s=%s on %s", name, param, path))
+		}
+		size, unit := measure(value, path)
+		if name == "min" && size < limit {
+			return "must be at least " + param + unit, false
+		}
+		if name == "max" && size > limit {
+			return "must be at most " + param + unit, false
+		}
+	case "email":
+		address, err := mail.ParseAddress(value.String())
+		if err != nil || address.Address != value.String() {
+			return "must be a valid email address", false
+		}
+	case "oneof":
+		options := strings.Fields(param)
+		for _, option := range options {
+			if value.String() == option {
+				return "", true
+			}
+		}
+		return "must be one of " + strings.Join(options, ", "), false
+	case "eqfield":
+		other := parent.FieldByName(param)
+		if !other.IsValid() {
+			panic(fmt.Sprintf("validate: eqfield on %s names unknown field %s", path, param))
+		}
+		if !reflect.DeepEqual(value.Interface(), indirect(other).Interface()) {
+			otherField, _ := parent.Type().FieldByName(param)
+			return "must match " + fieldName(otherField), false
+		}
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q on %s", name, path))
+	}
+	return "", true
+}
+
+// measure returns the size min and max compare: the length of strings, slices
+// and maps, or the value of numbers, with the unit to describe it with
+func measure(value reflect.Value, path string) (float64, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), ""
+	}
+	panic(fmt.Sprintf("validate: min or max on %s of %s", path, value.Type()))
+}
+
+// isEmpty reports whether a value fails required
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+// indirect follows pointers to the value they point to, returning the zero
+// Value for nil pointers
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// fieldName returns the name clients give a field: its json tag, its form tag,
+// or its Go name
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// splitRules splits a validate tag into its rules
+func splitRules(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// Add adds an invalid field, such as one found by a check rules can't express,
+// so every invalid field is reported together
+func (e *Errors) Add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
@@ -0,0 +1,87 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lineItem struct {
+	SKU      string `json:"sku" validate:"required,max=8"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+type orderRequest struct {
+	Name     string     `json:"name" validate:"required,max=10"`
+	Email    string     `json:"email" validate:"omitempty,email"`
+	Status   *string    `json:"status" validate:"omitempty,oneof=open closed"`
+	Discount float64    `json:"discount" validate:"min=0"`
+	Tags     []string   `json:"tags" validate:"max=2,dive,required,max=5"`
+	Items    []lineItem `json:"items" validate:"dive"`
+	Owner    *int64     `json:"owner_id" validate:"required"`
+	Internal string     `json:"-"`
+}
+
+type registerForm struct {
+	Password        string `form:"password" validate:"required,min=8"`
+	ConfirmPassword string `form:"confirm_password" validate:"eqfield=Password"`
+}
+
+func TestStruct(t *testing.T) {
+	owner := int64(7)
+
+	t.Run("Accepts valid values", func(t *testing.T) {
+		status := "open"
+		req := orderRequest{
+			Name:   "Order",
+			Email:  "jane@example.com",
+			Status: &status,
+			Tags:   []string{"vip"},
+			Items:  []lineItem{{SKU: "A-1", Quantity: 2}},
+			Owner:  &owner,
+		}
+		assert.Nil(t, Struct(&req))
+	})
+
+	t.Run("Lists every invalid field", func(t *testing.T) {
+		status := "lost"
+		req := orderRequest{
+			Name:     " ",
+			Email:    "jane",
+			Status:   &status,
+			Discount: -1,
+			Tags:     []string{"vip", "priority"},
+			Items:    []lineItem{{SKU: "A-1", Quantity: 1}, {Quantity: 0}},
+		}
+
+		assert.Equal(t, Errors{
+			{Field: "name", Message: "is required"},
+			{Field: "email", Message: "must be a valid email address"},
+			{Field: "status", Message: "must be one of open, closed"},
+			{Field: "discount", Message: "must be at least 0"},
+			{Field: "tags[1]", Message: "must be at most 5 characters"},
+			{Field: "items[1].sku", Message: "is required"},
+			{Field: "items[1].quantity", Message: "must be at least 1"},
+			{Field: "owner_id", Message: "is required"},
+		}, Struct(req))
+	})
+
+	t.Run("Checks the lengths of strings and slices", func(t *testing.T) {
+		req := orderRequest{Name: "Überlänge!", Tags: []string{"a", "b", "c"}, Owner: &owner}
+		assert.Equal(t, Errors{{Field: "tags", Message: "must be at most 2 items"}}, Struct(&req))
+	})
+
+	t.Run("Compares fields", func(t *testing.T) {
+		errs := Struct(registerForm{Password: "secret12", ConfirmPassword: "secret13"})
+		assert.Equal(t, Errors{{Field: "confirm_password", Message: "must match password"}}, errs)
+		assert.Equal(t, "confirm_password must match password", errs.Error())
+	})
+
+	t.Run("Panics on unknown rules", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Struct(struct {
+				Name string `validate:"requred"`
+			}{})
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+const (
+	// Default values
+	defaultSMTPPort = 587
+	defaultFrom     = "SiloCore <no-reply@localhost>"
+
+	// Environment variable names
+	envSMTPHost     = "SMTP_HOST"
+	envSMTPPort     = "SMTP_PORT"
+	envSMTPUsername = "SMTP_USERNAME"
+	envSMTPPassword = "SMTP_PASSWORD"
+	envMailFrom     = "MAIL_FROM"
+)
+
+// Config holds configuration for sending email
+type Config struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// LoadConfig loads mail configuration from environment variables
+func LoadConfig() (Config, error) {
+	config := Config{
+		SMTPHost:     os.Getenv(envSMTPHost),
+		SMTPPort:     defaultSMTPPort,
+		SMTPUsername: os.Getenv(envSMTPUsername),
+		SMTPPassword: os.Getenv(envSMTPPassword),
+		From:         os.Getenv(envMailFrom),
+	}
+
+	if config.From == "" {
+		config.From = defaultFrom
+	}
+
+	if portStr := os.Getenv(envSMTPPort); portStr != "" {
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SMTP_PORT value: %w", err)
+		}
+		config.SMTPPort = port
+	}
+
+	return config, nil
+}
+
+// New creates the Sender selected by the configuration.
+// Email is logged rather than delivered when SMTP_HOST is not set.
+func New(config Config) Sender {
+	if config.SMTPHost == "" {
+		log.Printf("[INFO] SMTP_HOST is not set, email will be logged instead of sent")
+		return LogSender{}
+	}
+
+	log.Printf("[INFO] Sending email through %s:%d", config.SMTPHost, config.SMTPPort)
+	return NewSMTPSender(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From)
+}
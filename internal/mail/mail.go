@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// LogSender writes email to the log instead of delivering it. It is used in
+// development when no SMTP server is configured.
+type LogSender struct{}
+
+// Ensure LogSender implements Sender
+var _ Sender = LogSender{}
+
+// Send logs the message
+func (LogSender) Send(ctx context.Context, message Message) error {
+	log.Printf("[INFO] Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// SMTPSender delivers email through an SMTP server
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+	now  func() time.Time
+}
+
+// Ensure SMTPSender implements Sender
+var _ Sender = (*SMTPSender)(nil)
+
+// NewSMTPSender creates a new SMTPSender. Authentication is skipped when no
+// username is given.
+func NewSMTPSender(host string, port int, username string, password string, from string) *SMTPSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPSender{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+		now:  time.Now,
+	}
+}
+
+// Send delivers the message
+func (s *SMTPSender) Send(ctx context.Context, message Message) error {
+	if err := validateHeader(message.To); err != nil {
+		return err
+	}
+	if err := validateHeader(message.Subject); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{message.To}, s.format(message)); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", message.To, err)
+	}
+	return nil
+}
+
+// format renders the message with its headers
+func (s *SMTPSender) format(message Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", s.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// validateHeader rejects header values that could inject further headers
+func validateHeader(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("invalid email header value %q", value)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPSenderFormat(t *testing.T) {
+	sender := NewSMTPSender("smtp.example.com", 587, "", "", "SiloCore <no-reply@example.com>")
+	sender.now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	message := sender.format(Message{
+		To:      "ada@example.com",
+		Subject: "Hello",
+		Body:    "Line one\nLine two",
+	})
+
+	assert.Equal(t, "From: SiloCore <no-reply@example.com>\r\n"+
+		"To: ada@example.com\r\n"+
+		"Subject: Hello\r\n"+
+		"Date: Sat, 01 Mar 2025 12:00:00 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n"+
+		"\r\n"+
+		"Line one\r\nLine two", string(message))
+}
+
+func TestSMTPSenderRejectsHeaderInjection(t *testing.T) {
+	sender := NewSMTPSender("smtp.example.com", 587, "", "", "no-reply@example.com")
+
+	err := sender.Send(context.Background(), Message{
+		To:      "ada@example.com\r\nBcc: eve@example.com",
+		Subject: "Hello",
+	})
+
+	assert.Error(t, err)
+}
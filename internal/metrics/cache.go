@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+// InstrumentCache counts the lookups of a cache by whether they hit, missed or
+// failed, and its writes and deletions, labeled with the cache's name. It
+// returns nil for a nil cache, which disables caching.
+func (m *Metrics) InstrumentCache(name string, c cache.Cache) cache.Cache {
+	if c == nil {
+		return nil
+	}
+	return &instrumentedCache{Cache: c, name: name, metrics: m}
+}
+
+// instrumentedCache is a Cache counting its operations
+type instrumentedCache struct {
+	cache.Cache
+	name    string
+	metrics *Metrics
+}
+
+// observe counts an operation with its result
+func (c *instrumentedCache) observe(operation, result string) {
+	c.metrics.cacheOps.WithLabelValues(c.name, operation, result).Inc()
+}
+
+// observeErr counts an operation by whether it failed
+func (c *instrumentedCache) observeErr(operation string, err error) {
+	if err != nil {
+		c.observe(operation, "error")
+		return
+	}
+	c.observe(operation, "ok")
+}
+
+// Get retrieves a value, reporting whether it was found
+func (c *instrumentedCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, found, err := c.Cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		c.observe("get", "error")
+	case found:
+		c.observe("get", "hit")
+	default:
+		c.observe("get", "miss")
+	}
+	return value, found, err
+}
+
+// Set stores a value that expires after ttl (or the cache default if ttl is 0)
+func (c *instrumentedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := c.Cache.Set(ctx, key, value, ttl)
+	c.observeErr("set", err)
+	return err
+}
+
+// Delete removes the given keys
+func (c *instrumentedCache) Delete(ctx context.Context, keys ...string) error {
+	err := c.Cache.Delete(ctx, keys...)
+	c.observeErr("delete", err)
+	return err
+}
+
+// DeletePrefix removes all keys starting with prefix
+func (c *instrumentedCache) DeletePrefix(ctx context.Context, prefix string) error {
+	err := c.Cache.DeletePrefix(ctx, prefix)
+	c.observeErr("delete_prefix", err)
+	return err
+}
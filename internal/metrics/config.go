@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Environment variable names
+const (
+	envMetricsEnabled  = "METRICS_ENABLED"
+	envMetricsUsername = "METRICS_USERNAME"
+	envMetricsPassword = "METRICS_PASSWORD"
+)
+
+// Config holds configuration for the metrics endpoint
+type Config struct {
+	Enabled bool
+
+	// Username and Password protect the endpoint with basic authentication,
+	// unless Username is empty
+	Username string
+	Password string
+}
+
+// LoadConfig loads metrics configuration from environment variables
+func LoadConfig() (Config, error) {
+	config := Config{
+		Enabled:  true,
+		Username: os.Getenv(envMetricsUsername),
+		Password: os.Getenv(envMetricsPassword),
+	}
+
+	if value := os.Getenv(envMetricsEnabled); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid METRICS_ENABLED value: %q", value)
+		}
+		config.Enabled = enabled
+	}
+
+	if config.Username != "" && config.Password == "" {
+		return Config{}, fmt.Errorf("METRICS_PASSWORD environment variable is required with METRICS_USERNAME")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+// queryDuration describes the query duration histogram
+var queryDuration = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "db", "query_duration_seconds"),
+	"Time taken by database queries, by operation.",
+	[]string{"operation"}, nil,
+)
+
+// queryErrors describes the count of failed queries
+var queryErrors = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "db", "query_errors_total"),
+	"Database queries that failed, by operation.",
+	[]string{"operation"}, nil,
+)
+
+// slowQueries describes the count of slow queries
+var slowQueries = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "db", "slow_queries_total"),
+	"Database queries slower than the slow query threshold.",
+	nil, nil,
+)
+
+// RegisterDatabasePool exposes the connection pool statistics of a database,
+// such as the primary or a replica, labeled with its name
+func (m *Metrics) RegisterDatabasePool(name string, db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterQueries exposes the query durations recorded by queries as histograms
+func (m *Metrics) RegisterQueries(queries *database.QueryMetrics) {
+	m.registry.MustRegister(queryCollector{queries: queries})
+}
+
+// queryCollector collects the query durations recorded by QueryMetrics
+type queryCollector struct {
+	queries *database.QueryMetrics
+}
+
+// Describe sends the descriptions of the query metrics
+func (c queryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queryDuration
+	ch <- queryErrors
+	ch <- slowQueries
+}
+
+// Collect sends the query durations recorded so far
+func (c queryCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.queries.Stats()
+	for operation, op := range stats.Operations {
+		// The recorded buckets are cumulative, keyed by their upper bound
+		buckets := make(map[float64]uint64, len(op.Buckets))
+		for bound, count := range op.Buckets {
+			d, err := time.ParseDuration(bound)
+			if err != nil {
+				continue
+			}
+			buckets[d.Seconds()] = uint64(count)
+		}
+
+		ch <- prometheus.MustNewConstHistogram(queryDuration, uint64(op.Count), op.TotalMS/1000, buckets, operation)
+		ch <- prometheus.MustNewConstMetric(queryErrors, prometheus.CounterValue, float64(op.Errors), operation)
+	}
+	ch <- prometheus.MustNewConstMetric(slowQueries, prometheus.CounterValue, float64(stats.SlowQueries))
+}
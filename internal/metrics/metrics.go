@@ -0,0 +1,123 @@
+// Package metrics exposes the server's Prometheus metrics: HTTP requests,
+// database pools and queries, and cache lookups.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of the server's metrics
+const namespace = "silocore"
+
+// unmatchedRoute labels requests that matched no route, so unknown paths don't
+// add label values. Through the router mounted at /, they match "/*".
+const unmatchedRoute = "unmatched"
+
+// Metrics holds the server's Prometheus metrics
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+	cacheOps *prometheus.CounterVec
+}
+
+// New creates a new Metrics, with the Go runtime and process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by route pattern, method and status.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by route pattern, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being served, by method.",
+		}, []string{"method"}),
+		cacheOps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_operations_total",
+			Help:      "Cache operations, by cache, operation and result.",
+		}, []string{"cache", "operation", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.inFlight,
+		m.cacheOps,
+	)
+	return m
+}
+
+// Middleware records the count and duration of requests by route pattern,
+// method and status, and the requests in flight by method. The route pattern
+// is only known once the request is routed, so it must wrap the router.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight := m.inFlight.WithLabelValues(r.Method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" && pattern != "/*" {
+				route = pattern
+			}
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := prometheus.Labels{"route": route, "method": r.Method, "status": strconv.Itoa(status)}
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Handler serves the metrics in the Prometheus text format. If the
+// configuration has a username, requests must give it and the password with
+// basic authentication.
+func (m *Metrics) Handler(config Config) http.Handler {
+	handler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	if config.Username == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(config.Username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(config.Password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/cache"
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+
+	// Mount a subrouter like the application's routes
+	sub := chi.NewRouter()
+	sub.Get("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Mount("/", sub)
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Expect requests to be labeled by route pattern, not path
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("/orders/{id}", "GET", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(unmatchedRoute, "GET", "404")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.inFlight.WithLabelValues("GET")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.duration))
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	queries := database.NewQueryMetrics(database.QueryLogConfig{})
+	queries.Observe("SELECT 1", 0, 3*time.Millisecond, nil)
+	m.RegisterQueries(queries)
+
+	c := m.InstrumentCache("roles", cache.NewLRUCache(10, time.Minute))
+	require.NoError(t, c.Set(context.Background(), "a", []byte("1"), 0))
+	_, _, err := c.Get(context.Background(), "a")
+	require.NoError(t, err)
+	_, _, err = c.Get(context.Background(), "b")
+	require.NoError(t, err)
+
+	t.Run("Serves metrics", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		m.Handler(Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		assert.Contains(t, body, `silocore_db_query_duration_seconds_bucket{operation="select",le="0.005"} 1`)
+		assert.Contains(t, body, `silocore_cache_operations_total{cache="roles",operation="get",result="hit"} 1`)
+		assert.Contains(t, body, `silocore_cache_operations_total{cache="roles",operation="get",result="miss"} 1`)
+		assert.True(t, strings.Contains(body, "go_goroutines"))
+	})
+
+	t.Run("Requires basic auth when configured", func(t *testing.T) {
+		handler := m.Handler(Config{Username: "prometheus", Password: "secret"})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.SetBasicAuth("prometheus", "wrong")
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = httptest.NewRecorder()
+		req.SetBasicAuth("prometheus", "secret")
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		config, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, Config{Enabled: true}, config)
+	})
+
+	t.Run("Basic auth", func(t *testing.T) {
+		t.Setenv("METRICS_USERNAME", "prometheus")
+		t.Setenv("METRICS_PASSWORD", "secret")
+
+		config, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, Config{Enabled: true, Username: "prometheus", Password: "secret"}, config)
+	})
+
+	t.Run("Username without password", func(t *testing.T) {
+		t.Setenv("METRICS_USERNAME", "prometheus")
+
+		_, err := LoadConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid enabled", func(t *testing.T) {
+		t.Setenv("METRICS_ENABLED", "maybe")
+
+		_, err := LoadConfig()
+		assert.EqualError(t, err, `invalid METRICS_ENABLED value: "maybe"`)
+	})
+}
@@ -0,0 +1,333 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"strings"
+	"time"
+
+	// Decoders for tenant logos. WebP logos are left off invoices.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/unsavory/silocore-go/internal/cache"
+	orderservice "github.com/unsavory/silocore-go/internal/order/service"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// Defaults for the cache of rendered invoices
+const (
+	DefaultCacheSize = 100
+	DefaultCacheTTL  = 24 * time.Hour
+)
+
+// cacheKeyPrefix is the cache key prefix for rendered invoices
+const cacheKeyPrefix = "invoice:"
+
+// Invoice is a rendered order invoice
+type Invoice struct {
+	Filename string
+	Data     []byte
+	// ModTime is when the order or the branding the invoice shows last changed
+	ModTime time.Time
+}
+
+// Renderer renders order invoices
+type Renderer interface {
+	// RenderInvoice renders the invoice of an order of the current tenant as a
+	// PDF document
+	RenderInvoice(ctx context.Context, orderID int64) (*Invoice, error)
+}
+
+// PDFRenderer renders invoices as PDF documents with the tenant's name, logo and
+// colors
+type PDFRenderer struct {
+	orderService    orderservice.OrderService
+	brandingService tenantservice.BrandingService
+	cache           cache.Cache
+}
+
+// Ensure PDFRenderer implements Renderer
+var _ Renderer = (*PDFRenderer)(nil)
+
+// NewPDFRenderer creates a new PDFRenderer
+func NewPDFRenderer(orderService orderservice.OrderService, brandingService tenantservice.BrandingService) *PDFRenderer {
+	return &PDFRenderer{
+		orderService:    orderService,
+		brandingService: brandingService,
+	}
+}
+
+// WithCache keeps rendered invoices in c until their order or the tenant's
+// branding changes
+func (r *PDFRenderer) WithCache(c cache.Cache) *PDFRenderer {
+	r.cache = c
+	return r
+}
+
+// RenderInvoice renders the invoice of an order of the current tenant as a PDF
+// document
+func (r *PDFRenderer) RenderInvoice(ctx context.Context, orderID int64) (*Invoice, error) {
+	order, err := r.orderService.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	branding, err := r.brandingService.GetBranding(ctx, order.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &Invoice{
+		Filename: fmt.Sprintf("invoice-%s.pdf", order.OrderNumber),
+		ModTime:  order.UpdatedAt,
+	}
+	if branding.UpdatedAt.After(invoice.ModTime) {
+		invoice.ModTime = branding.UpdatedAt
+	}
+
+	// Every change to an order bumps its version, so cached invoices never go
+	// stale
+	key := fmt.Sprintf("%s%d:%d:%d:%d", cacheKeyPrefix, order.TenantID, order.ID, order.Version, branding.UpdatedAt.UnixNano())
+	if r.cache != nil {
+		data, found, err := r.cache.Get(ctx, key)
+		if err != nil {
+			log.Printf("[WARN] Failed to read %s from cache: %v", key, err)
+		} else if found {
+			invoice.Data = data
+			return invoice, nil
+		}
+	}
+
+	var logo image.Image
+	if branding.HasLogo {
+		logo = r.loadLogo(ctx, order.TenantID)
+	}
+
+	invoice.Data = renderPDF(order, branding, logo)
+
+	if r.cache != nil {
+		if err := r.cache.Set(ctx, key, invoice.Data, 0); err != nil {
+			log.Printf("[WARN] Failed to cache %s: %v", key, err)
+		}
+	}
+
+	return invoice, nil
+}
+
+// loadLogo returns a tenant's decoded logo, or nil if it can't be loaded or
+// decoded. Invoices are still rendered without it.
+func (r *PDFRenderer) loadLogo(ctx context.Context, tenantID int64) image.Image {
+	logo, err := r.brandingService.GetLogo(ctx, tenantID)
+	if err != nil {
+		log.Printf("[WARN] Failed to load logo of tenant %d for invoice: %v", tenantID, err)
+		return nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(logo.Data))
+	if err != nil {
+		log.Printf("[WARN] Failed to decode %s logo of tenant %d for invoice: %v", logo.ContentType, tenantID, err)
+		return nil
+	}
+	return img
+}
+
+// Invoice layout in points
+const (
+	marginX      = 50.0
+	contentRight = pageWidth - marginX
+	headerTop    = pageHeight - 50
+	footerY      = 40.0
+	rowHeight    = 18.0
+	logoHeight   = 48.0
+	logoMaxWidth = 160.0
+	bodySize     = 10.0
+)
+
+// Invoice colors. The primary color is the tenant's if set.
+var (
+	defaultPrimary = color{r: 0.114, g: 0.306, b: 0.847}
+	textColor      = color{r: 0.122, g: 0.161, b: 0.216}
+	mutedColor     = color{r: 0.420, g: 0.447, b: 0.502}
+	ruleColor      = color{r: 0.820, g: 0.835, b: 0.859}
+)
+
+// Item table columns: SKU and description are left aligned, the rest right aligned
+const (
+	colSKU         = marginX
+	colDescription = marginX + 90
+	colQuantity    = 380.0
+	colUnitPrice   = 465.0
+	colAmount      = contentRight
+)
+
+// renderPDF lays out an order's invoice, continuing the items on further pages
+// when they don't fit
+func renderPDF(order *orderservice.Order, branding *tenantservice.Branding, logo image.Image) []byte {
+	doc := &document{}
+	primary := parseColor(branding.PrimaryColor, defaultPrimary)
+
+	p := doc.addPage()
+	y := renderHeader(doc, p, order, branding, logo, primary)
+
+	// Items, with the table header repeated on each page
+	itemsHeader := func(p *page, y float64) float64 {
+		p.text(colSKU, y, helveticaBold, bodySize, textColor, "SKU")
+		p.text(colDescription, y, helveticaBold, bodySize, textColor, "Description")
+		p.textRight(colQuantity, y, helveticaBold, bodySize, textColor, "Qty")
+		p.textRight(colUnitPrice, y, helveticaBold, bodySize, textColor, "Unit price")
+		p.textRight(colAmount, y, helveticaBold, bodySize, textColor, "Amount")
+		p.line(marginX, contentRight, y-6, 1, primary)
+		return y - rowHeight - 4
+	}
+	if len(order.Items) > 0 {
+		y = itemsHeader(p, y)
+		for _, item := range order.Items {
+			if y < footerY+rowHeight*2 {
+				p = doc.addPage()
+				y = itemsHeader(p, headerTop)
+			}
+			p.text(colSKU, y, helvetica, bodySize, textColor, truncate(item.SKU, helvetica, bodySize, colDescription-colSKU-10))
+			p.text(colDescription, y, helvetica, bodySize, textColor, truncate(item.Description, helvetica, bodySize, colQuantity-colDescription-40))
+			p.textRight(colQuantity, y, helvetica, bodySize, textColor, fmt.Sprint(item.Quantity))
+			p.textRight(colUnitPrice, y, helvetica, bodySize, textColor, formatAmount(item.UnitPrice))
+			p.textRight(colAmount, y, helvetica, bodySize, textColor, formatAmount(item.Amount()))
+			p.line(marginX, contentRight, y-6, 0.5, ruleColor)
+			y -= rowHeight
+		}
+		y -= rowHeight / 2
+	}
+
+	// Totals, kept together on one page
+	type totalLine struct {
+		label  string
+		amount string
+	}
+	totals := []totalLine{{"Subtotal", formatAmount(order.Subtotal)}}
+	if order.DiscountAmount != 0 {
+		totals = append(totals, totalLine{"Discount", "-" + formatAmount(order.DiscountAmount)})
+	}
+	if order.TaxAmount != 0 || order.TaxRate != 0 {
+		totals = append(totals, totalLine{fmt.Sprintf("Tax (%g%%)", order.TaxRate), formatAmount(order.TaxAmount)})
+	}
+	if y < footerY+rowHeight*float64(len(totals)+3) {
+		p = doc.addPage()
+		y = headerTop
+	}
+	for _, line := range totals {
+		p.text(colUnitPrice-80, y, helvetica, bodySize, mutedColor, line.label)
+		p.textRight(colAmount, y, helvetica, bodySize, textColor, line.amount)
+		y -= rowHeight
+	}
+	p.line(colUnitPrice-80, contentRight, y+rowHeight-6, 1, primary)
+	y -= 4
+	p.text(colUnitPrice-80, y, helveticaBold, 12, textColor, "Total")
+	p.textRight(colAmount, y, helveticaBold, 12, primary, formatAmount(order.TotalAmount))
+	y -= rowHeight * 2
+
+	// Notes, wrapped to the page width
+	if notes := strings.TrimSpace(order.Notes); notes != "" {
+		lines := wrap(notes, helvetica, bodySize, contentRight-marginX)
+		if y < footerY+rowHeight*2 {
+			p = doc.addPage()
+			y = headerTop
+		}
+		p.text(marginX, y, helveticaBold, bodySize, textColor, "Notes")
+		y -= rowHeight
+		for _, line := range lines {
+			if y < footerY+rowHeight {
+				p = doc.addPage()
+				y = headerTop
+			}
+			p.text(marginX, y, helvetica, bodySize, mutedColor, line)
+			y -= 14
+		}
+	}
+
+	// Footers, once the number of pages is known
+	for i, p := range doc.pages {
+		p.line(marginX, contentRight, footerY+12, 0.5, ruleColor)
+		p.text(marginX, footerY, helvetica, 8, mutedColor, fmt.Sprintf("%s · Invoice %s", branding.DisplayName, order.OrderNumber))
+		p.textRight(contentRight, footerY, helvetica, 8, mutedColor, fmt.Sprintf("Page %d of %d", i+1, len(doc.pages)))
+	}
+
+	return doc.bytes()
+}
+
+// renderHeader draws the tenant's logo and name and the invoice details on the
+// first page, returning where the items start
+func renderHeader(doc *document, p *page, order *orderservice.Order, branding *tenantservice.Branding, logo image.Image, primary color) float64 {
+	nameX := marginX
+	if logo != nil {
+		bounds := logo.Bounds()
+		width := logoHeight * float64(bounds.Dx()) / float64(bounds.Dy())
+		height := logoHeight
+		if width > logoMaxWidth {
+			height = height * logoMaxWidth / width
+			width = logoMaxWidth
+		}
+		p.image(doc.addImage(logo), marginX, headerTop-height, width, height)
+		nameX += width + 12
+	}
+	p.text(nameX, headerTop-30, helveticaBold, 18, textColor, branding.DisplayName)
+	p.textRight(contentRight, headerTop-30, helveticaBold, 24, primary, "INVOICE")
+	p.rect(marginX, headerTop-logoHeight-16, contentRight-marginX, 3, primary)
+
+	// Invoice details
+	y := headerTop - logoHeight - 44
+	details := [][2]string{
+		{"Invoice number", order.OrderNumber},
+		{"Order date", order.CreatedAt.Format("Jan 02, 2006")},
+		{"Status", order.Status},
+		{"Customer", fmt.Sprintf("User %d", order.UserID)},
+	}
+	for _, detail := range details {
+		p.text(marginX, y, helvetica, bodySize, mutedColor, detail[0])
+		p.text(marginX+100, y, helvetica, bodySize, textColor, detail[1])
+		y -= 14
+	}
+
+	return y - rowHeight
+}
+
+// formatAmount formats an amount like the order pages
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// truncate shortens s with an ellipsis to fit in width
+func truncate(s string, f *font, size float64, width float64) string {
+	if f.textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && f.textWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// wrap breaks s into lines that fit in width, keeping its line breaks. Words
+// longer than a line are truncated.
+func wrap(s string, f *font, size float64, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && f.textWidth(line+" "+word, size) <= width {
+				line += " " + word
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = truncate(word, f, size, width)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,170 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/cache"
+	orderservice "github.com/unsavory/silocore-go/internal/order/service"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+)
+
+// stubOrderService returns a fixed order
+type stubOrderService struct {
+	orderservice.OrderService
+	order *orderservice.Order
+}
+
+func (s *stubOrderService) GetOrder(ctx context.Context, orderID int64) (*orderservice.Order, error) {
+	if orderID != s.order.ID {
+		return nil, orderservice.ErrOrderNotFound
+	}
+	order := *s.order
+	return &order, nil
+}
+
+// stubBrandingService returns fixed branding, counting logo loads
+type stubBrandingService struct {
+	tenantservice.BrandingService
+	branding  tenantservice.Branding
+	logo      []byte
+	logoLoads int
+}
+
+func (s *stubBrandingService) GetBranding(ctx context.Context, tenantID int64) (*tenantservice.Branding, error) {
+	branding := s.branding
+	return &branding, nil
+}
+
+func (s *stubBrandingService) GetLogo(ctx context.Context, tenantID int64) (*tenantservice.Logo, error) {
+	s.logoLoads++
+	return &tenantservice.Logo{ContentType: "image/png", Data: s.logo}, nil
+}
+
+func testOrder() *orderservice.Order {
+	return &orderservice.Order{
+		ID:             7,
+		TenantID:       42,
+		UserID:         3,
+		OrderNumber:    "ORD-2025-000007",
+		Status:         "pending",
+		Subtotal:       30.55,
+		DiscountAmount: 0.55,
+		TaxRate:        10,
+		TaxAmount:      3,
+		TotalAmount:    33,
+		Notes:          "Leave at the (back) door",
+		Version:        1,
+		CreatedAt:      time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:      time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC),
+		Items: []orderservice.OrderItem{
+			{SKU: "WIDGET-1", Description: "Widget", Quantity: 2, UnitPrice: 10.5},
+			{SKU: "GADGET-2", Description: "Gadget", Quantity: 1, UnitPrice: 9.55},
+		},
+	}
+}
+
+func testLogo(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2))))
+	return buf.Bytes()
+}
+
+// assertValidPDF checks the structure of a PDF file and that the cross-reference
+// table points at its objects
+func assertValidPDF(t *testing.T, data []byte) {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, match)
+	xref, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1) {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "object %d", i+1)
+	}
+}
+
+func TestRenderInvoice(t *testing.T) {
+	orders := &stubOrderService{order: testOrder()}
+	branding := &stubBrandingService{
+		branding: tenantservice.Branding{
+			TenantID:     42,
+			DisplayName:  "Acme",
+			PrimaryColor: "#1d4ed8",
+			HasLogo:      true,
+			UpdatedAt:    time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC),
+		},
+		logo: testLogo(t),
+	}
+	renderer := NewPDFRenderer(orders, branding).WithCache(cache.NewLRUCache(10, time.Hour))
+
+	// Execute test
+	invoice, err := renderer.RenderInvoice(context.Background(), 7)
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, "invoice-ORD-2025-000007.pdf", invoice.Filename)
+	assert.Equal(t, branding.branding.UpdatedAt, invoice.ModTime)
+	assertValidPDF(t, invoice.Data)
+	for _, text := range []string{"(Acme)", "(INVOICE)", "(ORD-2025-000007)", "(WIDGET-1)", "($21.00)", "(-$0.55)", "(Tax \\(10%\\))", "($33.00)", "(Leave at the \\(back\\) door)", "/Subtype /Image /Width 4 /Height 2"} {
+		assert.Contains(t, string(invoice.Data), text)
+	}
+
+	t.Run("Cached until the order changes", func(t *testing.T) {
+		cached, err := renderer.RenderInvoice(context.Background(), 7)
+		require.NoError(t, err)
+		assert.Equal(t, invoice.Data, cached.Data)
+		assert.Equal(t, 1, branding.logoLoads)
+
+		orders.order.Version = 2
+		orders.order.Status = "shipped"
+		updated, err := renderer.RenderInvoice(context.Background(), 7)
+		require.NoError(t, err)
+		assert.Contains(t, string(updated.Data), "(shipped)")
+		assert.Equal(t, 2, branding.logoLoads)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		_, err := renderer.RenderInvoice(context.Background(), 8)
+		assert.ErrorIs(t, err, orderservice.ErrOrderNotFound)
+	})
+}
+
+func TestRenderInvoiceOverflowingPage(t *testing.T) {
+	order := testOrder()
+	order.Items = nil
+	for i := 0; i < 60; i++ {
+		order.Items = append(order.Items, orderservice.OrderItem{SKU: "SKU-" + strconv.Itoa(i), Description: "Item", Quantity: 1, UnitPrice: 1})
+	}
+
+	data := renderPDF(order, &tenantservice.Branding{DisplayName: "Acme"}, nil)
+
+	assertValidPDF(t, data)
+	assert.Contains(t, string(data), "/Count 2")
+	assert.Contains(t, string(data), "(Page 2 of 2)")
+	assert.Contains(t, string(data), "(SKU-59)")
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\(b\)c\\d`, escapeText(`a(b)c\d`))
+	assert.Equal(t, `Caf\351 \200 ?`, escapeText("Café € 日"))
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap("one two three\nfour", helvetica, 10, helvetica.textWidth("one two", 10))
+	assert.Equal(t, []string{"one two", "three", "four"}, lines)
+}
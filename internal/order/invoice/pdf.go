@@ -0,0 +1,257 @@
+package invoice
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+)
+
+// font is one of the standard PDF fonts, which viewers provide so nothing has to
+// be embedded
+type font struct {
+	resource string
+	name     string
+	widths   [95]int
+}
+
+// helvetica and helveticaBold hold the widths of the printable ASCII characters
+// in thousandths of the font size, from the fonts' metrics
+var (
+	helvetica = &font{resource: "F1", name: "Helvetica", widths: [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}}
+	helveticaBold = &font{resource: "F2", name: "Helvetica-Bold", widths: [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}}
+)
+
+// defaultCharWidth is the width assumed for characters outside printable ASCII
+const defaultCharWidth = 556
+
+// textWidth returns the width of s in points at the given font size
+func (f *font) textWidth(s string, size float64) float64 {
+	width := 0
+	for _, c := range winAnsi(s) {
+		if c >= 32 && c <= 126 {
+			width += f.widths[c-32]
+		} else {
+			width += defaultCharWidth
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// color is an RGB color with components from 0 to 1
+type color struct {
+	r, g, b float64
+}
+
+// parseColor parses a hex color such as #1d4ed8, returning fallback if it is
+// empty or invalid
+func parseColor(hex string, fallback color) color {
+	if len(hex) != 7 || hex[0] != '#' {
+		return fallback
+	}
+	value, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return fallback
+	}
+	return color{
+		r: float64(value>>16&0xff) / 255,
+		g: float64(value>>8&0xff) / 255,
+		b: float64(value&0xff) / 255,
+	}
+}
+
+// pdfImage is an image embedded in a document as compressed RGB samples
+type pdfImage struct {
+	width  int
+	height int
+	data   []byte
+}
+
+// document is a minimal PDF writer for text, rules and images on A4 pages
+type document struct {
+	pages  []*page
+	images []pdfImage
+}
+
+// page is the content stream of a page
+type page struct {
+	content bytes.Buffer
+}
+
+// addPage starts a new page
+func (d *document) addPage() *page {
+	p := &page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// addImage embeds an image, flattening transparency onto white, and returns the
+// name it is drawn with
+func (d *document) addImage(img image.Image) string {
+	bounds := img.Bounds()
+	samples := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Colors are premultiplied by alpha, so add white for the rest
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			samples = append(samples, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write(samples)
+	writer.Close()
+
+	d.images = append(d.images, pdfImage{width: bounds.Dx(), height: bounds.Dy(), data: compressed.Bytes()})
+	return fmt.Sprintf("Im%d", len(d.images))
+}
+
+// text draws s with its baseline starting at x, y
+func (p *page) text(x, y float64, f *font, size float64, c color, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n", f.resource, size, c.r, c.g, c.b, x, y, escapeText(s))
+}
+
+// textRight draws s with its baseline ending at x, y
+func (p *page) textRight(x, y float64, f *font, size float64, c color, s string) {
+	p.text(x-f.textWidth(s, size), y, f, size, c, s)
+}
+
+// rect fills a rectangle with its lower left corner at x, y
+func (p *page) rect(x, y, width, height float64, c color) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n", c.r, c.g, c.b, x, y, width, height)
+}
+
+// line draws a horizontal rule from x1 to x2 at y
+func (p *page) line(x1, x2, y, width float64, c color) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S\n", c.r, c.g, c.b, width, x1, y, x2, y)
+}
+
+// image draws an embedded image scaled to width and height with its lower left
+// corner at x, y
+func (p *page) image(name string, x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n", width, height, x, y, name)
+}
+
+// bytes writes the document as a PDF file
+func (d *document) bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	stream := func(dict string, data []byte) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", len(offsets), dict, len(data))
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Catalog, page tree and fonts come first, then the images, then each page
+	// followed by its content
+	const firstImage = 5
+	firstPage := firstImage + len(d.images)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, f := range []*font{helvetica, helveticaBold} {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.name))
+	}
+	for _, img := range d.images {
+		stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode", img.width, img.height), img.data)
+	}
+
+	var xobjects strings.Builder
+	for i := range d.images {
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", i+1, firstImage+i)
+	}
+	resources := fmt.Sprintf("<< /Font << /%s 3 0 R /%s 4 0 R >> /XObject <<%s >> >>", helvetica.resource, helveticaBold.resource, xobjects.String())
+	for i, p := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources %s /Contents %d 0 R >>", pageWidth, pageHeight, resources, firstPage+2*i+1))
+		stream("", p.content.Bytes())
+	}
+
+	// Cross-reference table locating each object
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// winAnsiSpecials maps the characters of the WinAnsi encoding outside Latin-1
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// winAnsi encodes s in the WinAnsi encoding of the standard fonts. Characters it
+// lacks become question marks.
+func winAnsi(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+	for _, c := range s {
+		switch {
+		case c >= 32 && c <= 126, c >= 0xa0 && c <= 0xff:
+			encoded = append(encoded, byte(c))
+		case winAnsiSpecials[c] != 0:
+			encoded = append(encoded, winAnsiSpecials[c])
+		case c == '\t' || c == '\n' || c == '\r':
+			encoded = append(encoded, ' ')
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// escapeText encodes s as the content of a PDF string
+func escapeText(s string) string {
+	var escaped strings.Builder
+	for _, c := range winAnsi(s) {
+		switch c {
+		case '(', ')', '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(c)
+		default:
+			if c >= 0x80 {
+				fmt.Fprintf(&escaped, "\\%03o", c)
+			} else {
+				escaped.WriteByte(c)
+			}
+		}
+	}
+	return escaped.String()
+}
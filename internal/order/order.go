@@ -11,6 +11,42 @@ type Order struct {
 	UserID    string    `json:"user_id"`
 	Status    string    `json:"status"`
 	Total     float64   `json:"total"`
+	Tags      []string  `json:"tags"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// Item represents a line item of an order
+type Item struct {
+	SKU         string  `json:"sku"`
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Amount      float64 `json:"amount"`
+}
+
+// StatusChange represents an entry of an order's status history
+type StatusChange struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  string    `json:"changed_by"`
+	Reason     string    `json:"reason"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// Attachment represents a file attached to an order
+type Attachment struct {
+	Filename    string    `json:"filename"`
+	SizeBytes   int64     `json:"size_bytes"`
+	DownloadURL string    `json:"download_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Comment represents a comment on an order
+type Comment struct {
+	ID         string    `json:"id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
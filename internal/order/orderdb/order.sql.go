@@ -0,0 +1,460 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: order.sql
+
+package orderdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const countArchivedOrders = `-- name: CountArchivedOrders :one
+SELECT COUNT(*)
+FROM order_archive
+WHERE tenant_id = $1
+    AND ($2::boolean OR deleted_at IS NULL)
+    AND ($3::text IS NULL OR status = $3)
+    AND ($4::integer IS NULL OR user_id = $4)
+    AND ($5::integer IS NULL OR assigned_to = $5)
+    AND (NOT $6::boolean OR assigned_to IS NULL)
+    AND ($7::text IS NULL OR order_number ILIKE $7 OR notes ILIKE $7)
+    AND ($8::timestamptz IS NULL OR created_at >= $8)
+    AND ($9::timestamptz IS NULL OR created_at < $9)
+    AND ($10::numeric IS NULL OR total_amount >= $10)
+    AND ($11::numeric IS NULL OR total_amount <= $11)
+    AND ($12::jsonb IS NULL OR details -> 'tags' @> $12)
+`
+
+type CountArchivedOrdersParams struct {
+	TenantID       int64
+	IncludeDeleted bool
+	Status         sql.NullString
+	UserID         sql.NullInt64
+	AssignedTo     sql.NullInt64
+	Unassigned     bool
+	Search         sql.NullString
+	CreatedFrom    sql.NullTime
+	CreatedTo      sql.NullTime
+	MinAmount      sql.NullFloat64
+	MaxAmount      sql.NullFloat64
+	Tags           json.RawMessage
+}
+
+// CountArchivedOrders is CountOrders for the archive
+func (q *Queries) CountArchivedOrders(ctx context.Context, arg CountArchivedOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countArchivedOrders,
+		arg.TenantID,
+		arg.IncludeDeleted,
+		arg.Status,
+		arg.UserID,
+		arg.AssignedTo,
+		arg.Unassigned,
+		arg.Search,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOrders = `-- name: CountOrders :one
+SELECT COUNT(*)
+FROM "order"
+WHERE "order".tenant_id = $1
+    AND ($2::boolean OR deleted_at IS NULL)
+    AND ($3::text IS NULL OR status = $3)
+    AND ($4::integer IS NULL OR user_id = $4)
+    AND ($5::integer IS NULL OR assigned_to = $5)
+    AND (NOT $6::boolean OR assigned_to IS NULL)
+    AND ($7::text IS NULL OR order_number ILIKE $7 OR notes ILIKE $7)
+    AND ($8::timestamptz IS NULL OR created_at >= $8)
+    AND ($9::timestamptz IS NULL OR created_at < $9)
+    AND ($10::numeric IS NULL OR total_amount >= $10)
+    AND ($11::numeric IS NULL OR total_amount <= $11)
+    AND ($12::jsonb IS NULL OR $12 <@ (
+        SELECT jsonb_agg(tag) FROM order_tag WHERE order_tag.order_id = "order".order_id
+    ))
+`
+
+type CountOrdersParams struct {
+	TenantID       int64
+	IncludeDeleted bool
+	Status         sql.NullString
+	UserID         sql.NullInt64
+	AssignedTo     sql.NullInt64
+	Unassigned     bool
+	Search         sql.NullString
+	CreatedFrom    sql.NullTime
+	CreatedTo      sql.NullTime
+	MinAmount      sql.NullFloat64
+	MaxAmount      sql.NullFloat64
+	Tags           json.RawMessage
+}
+
+// CountOrders counts the tenant's orders matching the filters that are set
+func (q *Queries) CountOrders(ctx context.Context, arg CountOrdersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOrders,
+		arg.TenantID,
+		arg.IncludeDeleted,
+		arg.Status,
+		arg.UserID,
+		arg.AssignedTo,
+		arg.Unassigned,
+		arg.Search,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getOrder = `-- name: GetOrder :one
+SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at,
+    subtotal, discount_amount, tax_rate, tax_amount, assigned_to
+FROM "order"
+WHERE order_id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+`
+
+type GetOrderParams struct {
+	OrderID  int64
+	TenantID int64
+}
+
+// GetOrder retrieves an order of the tenant that isn't in the trash
+func (q *Queries) GetOrder(ctx context.Context, arg GetOrderParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrder, arg.OrderID, arg.TenantID)
+	var i Order
+	err := row.Scan(
+		&i.OrderID,
+		&i.TenantID,
+		&i.UserID,
+		&i.OrderNumber,
+		&i.Status,
+		&i.TotalAmount,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Subtotal,
+		&i.DiscountAmount,
+		&i.TaxRate,
+		&i.TaxAmount,
+		&i.AssignedTo,
+	)
+	return i, err
+}
+
+const listArchivedOrders = `-- name: ListArchivedOrders :many
+SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at,
+    subtotal, discount_amount, tax_rate, tax_amount, assigned_to
+FROM order_archive
+WHERE tenant_id = $1
+    AND ($2::boolean OR deleted_at IS NULL)
+    AND ($3::text IS NULL OR status = $3)
+    AND ($4::integer IS NULL OR user_id = $4)
+    AND ($5::integer IS NULL OR assigned_to = $5)
+    AND (NOT $6::boolean OR assigned_to IS NULL)
+    AND ($7::text IS NULL OR order_number ILIKE $7 OR notes ILIKE $7)
+    AND ($8::timestamptz IS NULL OR created_at >= $8)
+    AND ($9::timestamptz IS NULL OR created_at < $9)
+    AND ($10::numeric IS NULL OR total_amount >= $10)
+    AND ($11::numeric IS NULL OR total_amount <= $11)
+    AND ($12::jsonb IS NULL OR details -> 'tags' @> $12)
+    AND ($13::timestamptz IS NULL
+        OR (created_at, order_id) < ($13, $14::integer))
+ORDER BY
+    CASE WHEN $15::text = 'created_at' THEN created_at END,
+    CASE WHEN $15 = 'updated_at' THEN updated_at END,
+    CASE WHEN $15 = '-updated_at' THEN updated_at END DESC,
+    CASE WHEN $15 = 'total_amount' THEN total_amount END,
+    CASE WHEN $15 = '-total_amount' THEN total_amount END DESC,
+    CASE WHEN $15 = 'status' THEN status END,
+    CASE WHEN $15 = '-status' THEN status END DESC,
+    CASE WHEN $15 = 'order_number' THEN order_number END,
+    CASE WHEN $15 = '-order_number' THEN order_number END DESC,
+    CASE WHEN $15 NOT LIKE '-%' THEN order_id END,
+    created_at DESC, order_id DESC
+LIMIT $17::integer OFFSET $16::integer
+`
+
+type ListArchivedOrdersParams struct {
+	TenantID        int64
+	IncludeDeleted  bool
+	Status          sql.NullString
+	UserID          sql.NullInt64
+	AssignedTo      sql.NullInt64
+	Unassigned      bool
+	Search          sql.NullString
+	CreatedFrom     sql.NullTime
+	CreatedTo       sql.NullTime
+	MinAmount       sql.NullFloat64
+	MaxAmount       sql.NullFloat64
+	Tags            json.RawMessage
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullInt64
+	Sort            string
+	RowOffset       int64
+	RowLimit        sql.NullInt64
+}
+
+type ListArchivedOrdersRow struct {
+	OrderID        int64
+	TenantID       int64
+	UserID         int64
+	OrderNumber    string
+	Status         string
+	TotalAmount    float64
+	Notes          sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Version        int64
+	DeletedAt      sql.NullTime
+	Subtotal       float64
+	DiscountAmount float64
+	TaxRate        float64
+	TaxAmount      float64
+	AssignedTo     sql.NullInt64
+}
+
+// ListArchivedOrders is ListOrders for the archive. Archived orders keep their
+// tags in their details.
+func (q *Queries) ListArchivedOrders(ctx context.Context, arg ListArchivedOrdersParams) ([]ListArchivedOrdersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listArchivedOrders,
+		arg.TenantID,
+		arg.IncludeDeleted,
+		arg.Status,
+		arg.UserID,
+		arg.AssignedTo,
+		arg.Unassigned,
+		arg.Search,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Sort,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListArchivedOrdersRow
+	for rows.Next() {
+		var i ListArchivedOrdersRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.TenantID,
+			&i.UserID,
+			&i.OrderNumber,
+			&i.Status,
+			&i.TotalAmount,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Subtotal,
+			&i.DiscountAmount,
+			&i.TaxRate,
+			&i.TaxAmount,
+			&i.AssignedTo,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderItems = `-- name: ListOrderItems :many
+SELECT item_id, order_id, sku, description, quantity, unit_price
+FROM order_item
+WHERE order_id = $1 AND tenant_id = $2
+ORDER BY item_id
+`
+
+type ListOrderItemsParams struct {
+	OrderID  int64
+	TenantID int64
+}
+
+type ListOrderItemsRow struct {
+	ItemID      int64
+	OrderID     int64
+	Sku         string
+	Description string
+	Quantity    int64
+	UnitPrice   float64
+}
+
+// ListOrderItems retrieves an order's items in the order they were added
+func (q *Queries) ListOrderItems(ctx context.Context, arg ListOrderItemsParams) ([]ListOrderItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderItems, arg.OrderID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrderItemsRow
+	for rows.Next() {
+		var i ListOrderItemsRow
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.OrderID,
+			&i.Sku,
+			&i.Description,
+			&i.Quantity,
+			&i.UnitPrice,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrders = `-- name: ListOrders :many
+SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at,
+    subtotal, discount_amount, tax_rate, tax_amount, assigned_to
+FROM "order"
+WHERE "order".tenant_id = $1
+    AND ($2::boolean OR deleted_at IS NULL)
+    AND ($3::text IS NULL OR status = $3)
+    AND ($4::integer IS NULL OR user_id = $4)
+    AND ($5::integer IS NULL OR assigned_to = $5)
+    AND (NOT $6::boolean OR assigned_to IS NULL)
+    AND ($7::text IS NULL OR order_number ILIKE $7 OR notes ILIKE $7)
+    AND ($8::timestamptz IS NULL OR created_at >= $8)
+    AND ($9::timestamptz IS NULL OR created_at < $9)
+    AND ($10::numeric IS NULL OR total_amount >= $10)
+    AND ($11::numeric IS NULL OR total_amount <= $11)
+    AND ($12::jsonb IS NULL OR $12 <@ (
+        SELECT jsonb_agg(tag) FROM order_tag WHERE order_tag.order_id = "order".order_id
+    ))
+    AND ($13::timestamptz IS NULL
+        OR (created_at, order_id) < ($13, $14::integer))
+ORDER BY
+    CASE WHEN $15::text = 'created_at' THEN created_at END,
+    CASE WHEN $15 = 'updated_at' THEN updated_at END,
+    CASE WHEN $15 = '-updated_at' THEN updated_at END DESC,
+    CASE WHEN $15 = 'total_amount' THEN total_amount END,
+    CASE WHEN $15 = '-total_amount' THEN total_amount END DESC,
+    CASE WHEN $15 = 'status' THEN status END,
+    CASE WHEN $15 = '-status' THEN status END DESC,
+    CASE WHEN $15 = 'order_number' THEN order_number END,
+    CASE WHEN $15 = '-order_number' THEN order_number END DESC,
+    CASE WHEN $15 NOT LIKE '-%' THEN order_id END,
+    created_at DESC, order_id DESC
+LIMIT $17::integer OFFSET $16::integer
+`
+
+type ListOrdersParams struct {
+	TenantID        int64
+	IncludeDeleted  bool
+	Status          sql.NullString
+	UserID          sql.NullInt64
+	AssignedTo      sql.NullInt64
+	Unassigned      bool
+	Search          sql.NullString
+	CreatedFrom     sql.NullTime
+	CreatedTo       sql.NullTime
+	MinAmount       sql.NullFloat64
+	MaxAmount       sql.NullFloat64
+	Tags            json.RawMessage
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullInt64
+	Sort            string
+	RowOffset       int64
+	RowLimit        sql.NullInt64
+}
+
+// ListOrders retrieves the tenant's orders matching the filters that are set;
+// unset filters are NULL. Orders are sorted by the column in sort, prefixed
+// with "-" for descending order, and then newest first. Lists newest first
+// continue after the cursor's order if one is given. The planner folds away the
+// unset filters and sorts when it plans the query with its parameters.
+func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, listOrders,
+		arg.TenantID,
+		arg.IncludeDeleted,
+		arg.Status,
+		arg.UserID,
+		arg.AssignedTo,
+		arg.Unassigned,
+		arg.Search,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Sort,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.TenantID,
+			&i.UserID,
+			&i.OrderNumber,
+			&i.Status,
+			&i.TotalAmount,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Subtotal,
+			&i.DiscountAmount,
+			&i.TaxRate,
+			&i.TaxAmount,
+			&i.AssignedTo,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
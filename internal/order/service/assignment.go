@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// AssignOrder assigns an order of the current tenant to a member of the tenant,
+// or unassigns it if userID is nil, and returns the order. Changing the assignee
+// bumps the order's version and publishes an order.assigned or order.unassigned
+// event; assigning an order to its current assignee changes nothing.
+func (s *DBOrderService) AssignOrder(ctx context.Context, orderID int64, userID *int64) (*Order, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Orders can only be assigned to members of their tenant
+	if userID != nil {
+		var member int
+		err = tx.QueryRowContext(ctx, `SELECT 1 FROM tenant_member WHERE tenant_id = $1 AND user_id = $2`, *tenantID, *userID).
+			Scan(&member)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: user %d is not a member of the tenant", ErrInvalidInput, *userID)
+			}
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+	}
+
+	// Lock the order and get its current assignee
+	var current sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT assigned_to FROM "order" WHERE order_id = $1 AND tenant_id = $2 AND deleted_at IS NULL FOR UPDATE`, orderID, *tenantID).
+		Scan(&current)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	var previous *int64
+	if current.Valid {
+		previous = &current.Int64
+	}
+	if sameAssignee(previous, userID) {
+		return s.GetOrder(ctx, orderID)
+	}
+
+	// Update the assignee with explicit tenant_id filter
+	query := `
+		UPDATE "order"
+		SET assigned_to = $1, updated_at = $2, version = version + 1
+		WHERE order_id = $3 AND tenant_id = $4
+	`
+	if _, err := tx.ExecContext(ctx, query, userID, time.Now(), orderID, *tenantID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	order, err := s.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := EventOrderAssigned
+	if userID == nil {
+		eventType = EventOrderUnassigned
+	}
+	err = s.publishEvent(ctx, eventType, order.TenantID, order.ID, OrderAssignedData{
+		OrderID:          order.ID,
+		OrderNumber:      order.OrderNumber,
+		AssignedTo:       userID,
+		PreviousAssignee: previous,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] Order %d of tenant %d reassigned from %s to %s", order.ID, order.TenantID, formatAssignee(previous), formatAssignee(userID))
+	return order, nil
+}
+
+// sameAssignee reports whether two assignees are the same user, or both no one
+func sameAssignee(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// formatAssignee describes an assignee for the log
+func formatAssignee(userID *int64) string {
+	if userID == nil {
+		return "no one"
+	}
+	return fmt.Sprintf("user %d", *userID)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/events"
+)
+
+func TestAssignOrder(t *testing.T) {
+	orderColumns := []string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}
+	tenantID := int64(42)
+	assignee := int64(7)
+	previous := int64(5)
+	now := time.Now()
+
+	// expectGetOrder expects the order to be read back with its assignee
+	expectGetOrder := func(mock sqlmock.Sqlmock, version int, assignedTo interface{}) {
+		mock.ExpectQuery("SELECT order_id, (.+) FROM \"order\"").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(1, tenantID, 100, "ORD-001", "pending", 10.0, "", now, now, version, nil, 10.0, 0, 0, 0, assignedTo))
+		mock.ExpectQuery("SELECT item_id, order_id, sku, description, quantity, unit_price FROM order_item").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"item_id", "order_id", "sku", "description", "quantity", "unit_price"}))
+	}
+
+	t.Run("Assign", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		publisher := &recordingPublisher{published: make(chan events.Event, 1)}
+		service.WithEventPublisher(publisher)
+		manager := transaction.NewManager(db)
+
+		mock.ExpectBegin()
+		ctx, _, err := manager.Begin(createContextWithTenant(tenantID))
+		require.NoError(t, err)
+
+		// Expect the membership check, the order to be locked and reassigned,
+		// then read back
+		mock.ExpectQuery("SELECT 1 FROM tenant_member").
+			WithArgs(tenantID, assignee).
+			WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+		mock.ExpectQuery("SELECT assigned_to FROM \"order\" (.+) FOR UPDATE").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}).AddRow(previous))
+		mock.ExpectExec("UPDATE \"order\" SET assigned_to = \\$1, (.+) version = version \\+ 1").
+			WithArgs(&assignee, sqlmock.AnyArg(), int64(1), tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectGetOrder(mock, 3, assignee)
+		mock.ExpectCommit()
+
+		// Execute test
+		order, err := service.AssignOrder(ctx, 1, &assignee)
+		require.NoError(t, err)
+		require.NoError(t, manager.Commit(ctx))
+
+		// Verify results
+		require.NotNil(t, order.AssignedTo)
+		assert.Equal(t, assignee, *order.AssignedTo)
+		select {
+		case event := <-publisher.published:
+			assert.Equal(t, EventOrderAssigned, event.Type)
+			assert.Equal(t, OrderAssignedData{OrderID: 1, OrderNumber: "ORD-001", AssignedTo: &assignee, PreviousAssignee: &previous}, event.Data)
+		case <-time.After(time.Second):
+			t.Fatal("event was not published")
+		}
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unassign", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect no membership check for unassigning
+		mock.ExpectQuery("SELECT assigned_to FROM \"order\"").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}).AddRow(previous))
+		mock.ExpectExec("UPDATE \"order\" SET assigned_to").
+			WithArgs(nil, sqlmock.AnyArg(), int64(1), tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectGetOrder(mock, 3, nil)
+
+		// Execute test
+		order, err := service.AssignOrder(ctx, 1, nil)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Nil(t, order.AssignedTo)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already assigned", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect the order to be returned without an update
+		mock.ExpectQuery("SELECT 1 FROM tenant_member").
+			WithArgs(tenantID, assignee).
+			WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+		mock.ExpectQuery("SELECT assigned_to FROM \"order\"").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}).AddRow(assignee))
+		expectGetOrder(mock, 2, assignee)
+
+		// Execute test
+		order, err := service.AssignOrder(ctx, 1, &assignee)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, 2, order.Version)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not a member", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		mock.ExpectQuery("SELECT 1 FROM tenant_member").
+			WithArgs(tenantID, assignee).
+			WillReturnRows(sqlmock.NewRows([]string{"?column?"}))
+
+		// Execute test
+		order, err := service.AssignOrder(ctx, 1, &assignee)
+
+		// Verify results
+		assert.Nil(t, order)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		mock.ExpectQuery("SELECT assigned_to FROM \"order\"").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"assigned_to"}))
+
+		// Execute test
+		order, err := service.AssignOrder(ctx, 1, nil)
+
+		// Verify results
+		assert.Nil(t, order)
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/antivirus"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/storage"
+)
+
+// Attachment errors
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentRejected is returned when the virus scan finds malware in an upload
+	ErrAttachmentRejected = errors.New("attachment rejected by virus scan")
+	// ErrInvalidDownloadLink is returned when an attachment download link is forged or expired
+	ErrInvalidDownloadLink = errors.New("invalid or expired download link")
+)
+
+const (
+	// MaxAttachmentBytes is the size limit of a single attachment
+	MaxAttachmentBytes = 25 << 20
+
+	// MaxAttachmentFilenameLength is the longest filename an attachment can have
+	MaxAttachmentFilenameLength = 255
+
+	// DefaultAttachmentLinkTTL is how long attachment download links are valid
+	DefaultAttachmentLinkTTL = 15 * time.Minute
+)
+
+// Attachment is a file attached to an order
+type Attachment struct {
+	ID          int64     `json:"id"`
+	OrderID     int64     `json:"order_id"`
+	TenantID    int64     `json:"tenant_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	UploadedBy  *int64    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	// DownloadURL is a signed link to the file that expires after the link TTL
+	DownloadURL string `json:"download_url"`
+}
+
+// AttachmentUpload is a file to attach to an order
+type AttachmentUpload struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// AttachmentContent is the file of an attachment, opened from a download link
+type AttachmentContent struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// StorageQuotaChecker checks whether a tenant can store more data
+type StorageQuotaChecker interface {
+	CheckStorageQuota(ctx context.Context, tenantID int64, size int64) error
+}
+
+// AttachmentService defines the interface for order attachment operations
+type AttachmentService interface {
+	// AddAttachment scans a file and attaches it to an order of the current tenant
+	AddAttachment(ctx context.Context, orderID int64, upload AttachmentUpload) (*Attachment, error)
+
+	// ListAttachments lists the attachments of an order of the current tenant,
+	// oldest first, with fresh download links
+	ListAttachments(ctx context.Context, orderID int64) ([]Attachment, error)
+
+	// DeleteAttachment removes an attachment from an order of the current tenant
+	DeleteAttachment(ctx context.Context, orderID int64, attachmentID int64) error
+
+	// OpenAttachment retrieves the file of an attachment from a signed download link
+	OpenAttachment(ctx context.Context, attachmentID int64, expires int64, signature string) (*AttachmentContent, error)
+}
+
+// DBAttachmentService implements AttachmentService, keeping attachment records in
+// the database and their files in an object store
+type DBAttachmentService struct {
+	txManager  transaction.TxManager
+	store      storage.Store
+	scanner    antivirus.Scanner
+	quota      StorageQuotaChecker
+	signingKey []byte
+	linkTTL    time.Duration
+	now        func() time.Time
+}
+
+// Ensure DBAttachmentService implements AttachmentService
+var _ AttachmentService = (*DBAttachmentService)(nil)
+
+// NewDBAttachmentService creates a new DBAttachmentService keeping files in store.
+// Download links are signed with a key derived from secret.
+func NewDBAttachmentService(db *sql.DB, store storage.Store, secret []byte) *DBAttachmentService {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("order-attachment-download"))
+
+	return &DBAttachmentService{
+		txManager:  transaction.NewManager(db),
+		store:      store,
+		scanner:    antivirus.NoopScanner{},
+		signingKey: mac.Sum(nil),
+		linkTTL:    DefaultAttachmentLinkTTL,
+		now:        time.Now,
+	}
+}
+
+// WithTxManager runs the DBAttachmentService's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (s *DBAttachmentService) WithTxManager(txManager transaction.TxManager) *DBAttachmentService {
+	s.txManager = txManager
+	return s
+}
+
+// WithScanner scans uploads with scanner before storing them
+func (s *DBAttachmentService) WithScanner(scanner antivirus.Scanner) *DBAttachmentService {
+	s.scanner = scanner
+	return s
+}
+
+// WithStorageQuota rejects uploads that would take a tenant over its storage limit
+func (s *DBAttachmentService) WithStorageQuota(quota StorageQuotaChecker) *DBAttachmentService {
+	s.quota = quota
+	return s
+}
+
+// AddAttachment scans a file and attaches it to an order of the current tenant.
+// Deleted orders can't get new attachments.
+func (s *DBAttachmentService) AddAttachment(ctx context.Context, orderID int64, upload AttachmentUpload) (*Attachment, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	filename := path.Base(strings.ReplaceAll(strings.TrimSpace(upload.Filename), "\\", "/"))
+	if filename == "" || filename == "." || filename == "/" {
+		return nil, fmt.Errorf("%w: filename is required", ErrInvalidInput)
+	}
+	if len(filename) > MaxAttachmentFilenameLength {
+		return nil, fmt.Errorf("%w: filename is longer than %d characters", ErrInvalidInput, MaxAttachmentFilenameLength)
+	}
+	if len(upload.Data) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidInput)
+	}
+	if len(upload.Data) > MaxAttachmentBytes {
+		return nil, fmt.Errorf("%w: file is larger than %d MB", ErrInvalidInput, MaxAttachmentBytes>>20)
+	}
+	contentType := upload.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := checkOrderExists(ctx, tx, *tenantID, orderID); err != nil {
+		return nil, err
+	}
+
+	size := int64(len(upload.Data))
+	if s.quota != nil {
+		if err := s.quota.CheckStorageQuota(ctx, *tenantID, size); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.scanner.Scan(ctx, upload.Data); err != nil {
+		if errors.Is(err, antivirus.ErrInfected) {
+			log.Printf("[WARN] Rejected infected attachment %q for order %d of tenant %d: %v", filename, orderID, *tenantID, err)
+			return nil, fmt.Errorf("%w: %v", ErrAttachmentRejected, err)
+		}
+		return nil, fmt.Errorf("failed to scan attachment: %w", err)
+	}
+
+	storageKey, err := attachmentStorageKey(*tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, storageKey, upload.Data); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment := &Attachment{
+		OrderID:     orderID,
+		TenantID:    *tenantID,
+		Filename:    filename,
+		ContentType: contentType,
+		SizeBytes:   size,
+	}
+	if userID, err := authctx.GetUserID(ctx); err == nil {
+		attachment.UploadedBy = &userID
+	}
+
+	query := `
+		INSERT INTO order_attachment (order_id, tenant_id, filename, content_type, size_bytes, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING attachment_id, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, orderID, *tenantID, filename, contentType, size, storageKey, attachment.UploadedBy).
+		Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		s.deleteObject(storageKey)
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	attachment.DownloadURL = s.downloadURL(attachment.ID)
+
+	log.Printf("[INFO] Attached %s (%d bytes) to order %d of tenant %d", filename, size, orderID, *tenantID)
+	return attachment, nil
+}
+
+// ListAttachments lists the attachments of an order of the current tenant,
+// oldest first, with fresh download links
+func (s *DBAttachmentService) ListAttachments(ctx context.Context, orderID int64) ([]Attachment, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := checkOrderExists(ctx, tx, *tenantID, orderID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT attachment_id, order_id, tenant_id, filename, content_type, size_bytes, uploaded_by, created_at
+		FROM order_attachment
+		WHERE order_id = $1 AND tenant_id = $2
+		ORDER BY created_at, attachment_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, orderID, *tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		var attachment Attachment
+		var uploadedBy sql.NullInt64
+		err := rows.Scan(&attachment.ID, &attachment.OrderID, &attachment.TenantID, &attachment.Filename,
+			&attachment.ContentType, &attachment.SizeBytes, &uploadedBy, &attachment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if uploadedBy.Valid {
+			attachment.UploadedBy = &uploadedBy.Int64
+		}
+		attachment.DownloadURL = s.downloadURL(attachment.ID)
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return attachments, nil
+}
+
+// DeleteAttachment removes an attachment from an order of the current tenant.
+// Its file is deleted from the object store once the transaction commits.
+func (s *DBAttachmentService) DeleteAttachment(ctx context.Context, orderID int64, attachmentID int64) error {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	query := `
+		DELETE FROM order_attachment
+		WHERE attachment_id = $1 AND order_id = $2 AND tenant_id = $3
+		RETURNING storage_key
+	`
+
+	var storageKey string
+	if err := tx.QueryRowContext(ctx, query, attachmentID, orderID, *tenantID).Scan(&storageKey); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAttachmentNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Keep the file until the deletion is committed, so a rollback leaves a
+	// working attachment
+	err = transaction.AfterCommit(ctx, func() {
+		s.deleteObject(storageKey)
+	})
+	if err != nil {
+		log.Printf("[WARN] Not deleting file %s of attachment %d: %v", storageKey, attachmentID, err)
+	}
+
+	log.Printf("[INFO] Deleted attachment %d of order %d of tenant %d", attachmentID, orderID, *tenantID)
+	return nil
+}
+
+// OpenAttachment retrieves the file of an attachment from a signed download link
+func (s *DBAttachmentService) OpenAttachment(ctx context.Context, attachmentID int64, expires int64, signature string) (*AttachmentContent, error) {
+	expected, err := hex.DecodeString(s.sign(attachmentID, expires))
+	if err != nil {
+		return nil, ErrInvalidDownloadLink
+	}
+	provided, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(provided, expected) {
+		log.Printf("[WARN] Rejected download of attachment %d with an invalid signature", attachmentID)
+		return nil, ErrInvalidDownloadLink
+	}
+
+	if !s.now().Before(time.Unix(expires, 0)) {
+		return nil, ErrInvalidDownloadLink
+	}
+
+	query := `
+		SELECT filename, content_type, storage_key
+		FROM order_attachment
+		WHERE attachment_id = $1
+	`
+
+	var content AttachmentContent
+	var storageKey string
+	err = s.txManager.GetDB().QueryRowContext(ctx, query, attachmentID).Scan(&content.Filename, &content.ContentType, &storageKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	content.Data, err = s.store.Get(ctx, storageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Printf("[ERROR] File %s of attachment %d is missing", storageKey, attachmentID)
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	return &content, nil
+}
+
+// downloadURL returns a signed download link to an attachment
+func (s *DBAttachmentService) downloadURL(attachmentID int64) string {
+	expires := s.now().Add(s.linkTTL).Unix()
+	return fmt.Sprintf("/attachments/%d/download?expires=%d&signature=%s", attachmentID, expires, s.sign(attachmentID, expires))
+}
+
+// sign returns the hex HMAC of an attachment ID and link expiry
+func (s *DBAttachmentService) sign(attachmentID int64, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(strconv.FormatInt(attachmentID, 10) + "." + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deleteObject deletes the file of an attachment, logging failures
+func (s *DBAttachmentService) deleteObject(storageKey string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := s.store.Delete(ctx, storageKey); err != nil {
+		log.Printf("[WARN] Failed to delete attachment file %s: %v", storageKey, err)
+	}
+}
+
+// attachmentStorageKey returns a new, unguessable key for a file attached to an order
+func attachmentStorageKey(tenantID int64, orderID int64) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate attachment key: %w", err)
+	}
+	return fmt.Sprintf("tenants/%d/orders/%d/attachments/%s", tenantID, orderID, hex.EncodeToString(random)), nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/antivirus"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/storage"
+)
+
+// fakeScanner reports every scanned file as infected if infected is set
+type fakeScanner struct {
+	infected bool
+}
+
+func (s fakeScanner) Scan(ctx context.Context, data []byte) error {
+	if s.infected {
+		return fmt.Errorf("%w: Eicar-Test-Signature", antivirus.ErrInfected)
+	}
+	return nil
+}
+
+// fakeStorageQuota rejects uploads once used plus the upload exceeds limit
+type fakeStorageQuota struct {
+	used  int64
+	limit int64
+}
+
+func (q fakeStorageQuota) CheckStorageQuota(ctx context.Context, tenantID int64, size int64) error {
+	if q.used+size > q.limit {
+		return fmt.Errorf("storage limit of %d bytes reached", q.limit)
+	}
+	return nil
+}
+
+// capturedArg matches any query argument, keeping its value
+type capturedArg struct {
+	value driver.Value
+}
+
+func (a *capturedArg) Match(value driver.Value) bool {
+	a.value = value
+	return true
+}
+
+func setupAttachmentService(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *DBAttachmentService, *storage.FileStore) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	store := storage.NewFileStore(t.TempDir())
+	return db, mock, NewDBAttachmentService(db, store, []byte("secret")), store
+}
+
+// expectOrderExists expects the check that an order exists and isn't deleted
+func expectOrderExists(mock sqlmock.Sqlmock, orderID int64, tenantID int64) {
+	mock.ExpectQuery("SELECT 1 FROM \"order\" WHERE order_id = \\$1 AND tenant_id = \\$2 AND deleted_at IS NULL").
+		WithArgs(orderID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+}
+
+func TestAddAttachment(t *testing.T) {
+	tenantID := int64(42)
+	upload := AttachmentUpload{Filename: `C:\invoices\invoice.pdf`, ContentType: "application/pdf", Data: []byte("%PDF-1.7")}
+
+	t.Run("Stored", func(t *testing.T) {
+		db, mock, service, store := setupAttachmentService(t)
+		defer db.Close()
+		service.WithScanner(fakeScanner{}).WithStorageQuota(fakeStorageQuota{limit: 100})
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		expectOrderExists(mock, 1, tenantID)
+		storageKey := &capturedArg{}
+		mock.ExpectQuery("INSERT INTO order_attachment").
+			WithArgs(int64(1), tenantID, "invoice.pdf", "application/pdf", int64(8), storageKey, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"attachment_id", "created_at"}).AddRow(5, time.Now()))
+
+		// Execute test
+		attachment, err := service.AddAttachment(ctx, 1, upload)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), attachment.ID)
+		assert.Equal(t, "invoice.pdf", attachment.Filename)
+		assert.Contains(t, attachment.DownloadURL, "/attachments/5/download?expires=")
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// The file is kept under the tenant and order
+		assert.Regexp(t, "^tenants/42/orders/1/attachments/[0-9a-f]{32}$", storageKey.value)
+		data, err := store.Get(context.Background(), storageKey.value.(string))
+		require.NoError(t, err)
+		assert.Equal(t, upload.Data, data)
+	})
+
+	t.Run("Infected", func(t *testing.T) {
+		db, mock, service, _ := setupAttachmentService(t)
+		defer db.Close()
+		service.WithScanner(fakeScanner{infected: true})
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		expectOrderExists(mock, 1, tenantID)
+
+		// Execute test
+		_, err := service.AddAttachment(ctx, 1, upload)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrAttachmentRejected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Storage quota exceeded", func(t *testing.T) {
+		db, mock, service, _ := setupAttachmentService(t)
+		defer db.Close()
+		service.WithStorageQuota(fakeStorageQuota{used: 95, limit: 100})
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		expectOrderExists(mock, 1, tenantID)
+
+		// Execute test
+		_, err := service.AddAttachment(ctx, 1, upload)
+
+		// Verify results
+		assert.ErrorContains(t, err, "storage limit")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Deleted order", func(t *testing.T) {
+		db, mock, service, _ := setupAttachmentService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		mock.ExpectQuery("SELECT 1 FROM \"order\"").
+			WithArgs(int64(1), tenantID).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute test
+		_, err := service.AddAttachment(ctx, 1, upload)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		db, _, service, _ := setupAttachmentService(t)
+		defer db.Close()
+
+		// Execute test
+		_, err := service.AddAttachment(createContextWithTenant(tenantID), 1, AttachmentUpload{Filename: "empty.txt"})
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestDeleteAttachmentAfterCommit(t *testing.T) {
+	db, mock, service, store := setupAttachmentService(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	storageKey := "tenants/42/orders/1/attachments/abc"
+	require.NoError(t, store.Put(context.Background(), storageKey, []byte("data")))
+
+	manager := transaction.NewManager(db)
+	mock.ExpectBegin()
+	ctx, _, err := manager.Begin(createContextWithTenant(tenantID))
+	require.NoError(t, err)
+	mock.ExpectQuery("DELETE FROM order_attachment").
+		WithArgs(int64(5), int64(1), tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow(storageKey))
+	mock.ExpectCommit()
+
+	// Execute test
+	require.NoError(t, service.DeleteAttachment(ctx, 1, 5))
+	_, err = store.Get(context.Background(), storageKey)
+	require.NoError(t, err, "the file must be kept until the deletion commits")
+	require.NoError(t, manager.Commit(ctx))
+
+	// Verify results
+	_, err = store.Get(context.Background(), storageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenAttachment(t *testing.T) {
+	db, mock, service, store := setupAttachmentService(t)
+	defer db.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	require.NoError(t, store.Put(context.Background(), "tenants/42/orders/1/attachments/abc", []byte("%PDF-1.7")))
+
+	link, err := url.Parse(service.downloadURL(5))
+	require.NoError(t, err)
+	expires, err := strconv.ParseInt(link.Query().Get("expires"), 10, 64)
+	require.NoError(t, err)
+	signature := link.Query().Get("signature")
+
+	t.Run("Valid link", func(t *testing.T) {
+		mock.ExpectQuery("SELECT filename, content_type, storage_key FROM order_attachment WHERE attachment_id = \\$1").
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows([]string{"filename", "content_type", "storage_key"}).
+				AddRow("invoice.pdf", "application/pdf", "tenants/42/orders/1/attachments/abc"))
+
+		// Execute test
+		content, err := service.OpenAttachment(context.Background(), 5, expires, signature)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, "invoice.pdf", content.Filename)
+		assert.Equal(t, []byte("%PDF-1.7"), content.Data)
+	})
+
+	t.Run("Forged link", func(t *testing.T) {
+		// Execute test
+		_, err := service.OpenAttachment(context.Background(), 6, expires, signature)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrInvalidDownloadLink)
+	})
+
+	t.Run("Expired link", func(t *testing.T) {
+		now = now.Add(DefaultAttachmentLinkTTL)
+
+		// Execute test
+		_, err := service.OpenAttachment(context.Background(), 5, expires, signature)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrInvalidDownloadLink)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// AuditingOrderService decorates an OrderService, recording order mutations in
+// the tenant's audit log. Failing to record an entry does not fail the mutation.
+type AuditingOrderService struct {
+	OrderService
+	recorder auditservice.AuditRecorder
+}
+
+// Ensure AuditingOrderService implements OrderService
+var _ OrderService = (*AuditingOrderService)(nil)
+
+// NewAuditingOrderService creates a new AuditingOrderService
+func NewAuditingOrderService(orderService OrderService, recorder auditservice.AuditRecorder) *AuditingOrderService {
+	return &AuditingOrderService{
+		OrderService: orderService,
+		recorder:     recorder,
+	}
+}
+
+// CreateOrder creates a new order and records it in the audit log
+func (s *AuditingOrderService) CreateOrder(ctx context.Context, order *Order) (*Order, error) {
+	created, err := s.OrderService.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, created.TenantID, auditservice.ActionOrderCreated, created.ID, map[string]interface{}{
+		"order_number": created.OrderNumber,
+		"status":       created.Status,
+		"total_amount": created.TotalAmount,
+		"items":        len(created.Items),
+	})
+	return created, nil
+}
+
+// UpdateOrder updates an order and records it in the audit log, along with its
+// status change if any
+func (s *AuditingOrderService) UpdateOrder(ctx context.Context, order *Order) error {
+	previousStatus := s.currentStatus(ctx, order.ID)
+	if err := s.OrderService.UpdateOrder(ctx, order); err != nil {
+		return err
+	}
+
+	s.record(ctx, order.TenantID, auditservice.ActionOrderUpdated, order.ID, map[string]interface{}{
+		"order_number": order.OrderNumber,
+		"status":       order.Status,
+		"total_amount": order.TotalAmount,
+		"version":      order.Version,
+	})
+	s.recordStatusChange(ctx, order, previousStatus, order.StatusReason)
+	return nil
+}
+
+// PatchOrder updates fields of an order and records it in the audit log, along
+// with its status change if any
+func (s *AuditingOrderService) PatchOrder(ctx context.Context, orderID int64, patch OrderPatch) (*Order, error) {
+	var previousStatus string
+	if patch.Status != nil {
+		previousStatus = s.currentStatus(ctx, orderID)
+	}
+
+	order, err := s.OrderService.PatchOrder(ctx, orderID, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, order.TenantID, auditservice.ActionOrderUpdated, order.ID, map[string]interface{}{
+		"order_number": order.OrderNumber,
+		"status":       order.Status,
+		"total_amount": order.TotalAmount,
+		"version":      order.Version,
+	})
+	if patch.Status != nil {
+		s.recordStatusChange(ctx, order, previousStatus, patch.StatusReason)
+	}
+	return order, nil
+}
+
+// DeleteOrder deletes an order and records it in the audit log
+func (s *AuditingOrderService) DeleteOrder(ctx context.Context, orderID int64, version int) error {
+	if err := s.OrderService.DeleteOrder(ctx, orderID, version); err != nil {
+		return err
+	}
+
+	// DeleteOrder only succeeds within a tenant context
+	if tenantID, err := authctx.GetTenantID(ctx); err == nil && tenantID != nil {
+		s.record(ctx, *tenantID, auditservice.ActionOrderDeleted, orderID, nil)
+	}
+	return nil
+}
+
+// RestoreOrder restores an order from the trash and records it in the audit log
+func (s *AuditingOrderService) RestoreOrder(ctx context.Context, orderID int64) (*Order, error) {
+	order, err := s.OrderService.RestoreOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, order.TenantID, auditservice.ActionOrderRestored, order.ID, map[string]interface{}{
+		"order_number": order.OrderNumber,
+		"version":      order.Version,
+	})
+	return order, nil
+}
+
+// AssignOrder assigns or unassigns an order and records it in the audit log if
+// its assignee changed
+func (s *AuditingOrderService) AssignOrder(ctx context.Context, orderID int64, userID *int64) (*Order, error) {
+	var previous *int64
+	if current, err := s.OrderService.GetOrder(ctx, orderID); err == nil {
+		previous = current.AssignedTo
+	}
+
+	order, err := s.OrderService.AssignOrder(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sameAssignee(previous, order.AssignedTo) {
+		return order, nil
+	}
+
+	action := auditservice.ActionOrderAssigned
+	if order.AssignedTo == nil {
+		action = auditservice.ActionOrderUnassigned
+	}
+	s.record(ctx, order.TenantID, action, order.ID, map[string]interface{}{
+		"order_number":      order.OrderNumber,
+		"assigned_to":       order.AssignedTo,
+		"previous_assignee": previous,
+	})
+	return order, nil
+}
+
+// currentStatus returns the status of an order before it is changed, or "" if it
+// can't be read, in which case the change itself fails
+func (s *AuditingOrderService) currentStatus(ctx context.Context, orderID int64) string {
+	order, err := s.OrderService.GetOrder(ctx, orderID)
+	if err != nil {
+		return ""
+	}
+	return order.Status
+}
+
+// recordStatusChange records an order.status_changed entry if the order's status
+// differs from its previous status
+func (s *AuditingOrderService) recordStatusChange(ctx context.Context, order *Order, previousStatus string, reason string) {
+	if previousStatus == "" || previousStatus == order.Status {
+		return
+	}
+
+	s.record(ctx, order.TenantID, auditservice.ActionOrderStatusChanged, order.ID, map[string]interface{}{
+		"order_number": order.OrderNumber,
+		"from_status":  previousStatus,
+		"to_status":    order.Status,
+		"reason":       reason,
+	})
+}
+
+// record writes an audit entry for an order, logging rather than returning failures
+func (s *AuditingOrderService) record(ctx context.Context, tenantID int64, action string, orderID int64, details map[string]interface{}) {
+	entry := auditservice.AuditEntry{
+		TenantID:     tenantID,
+		Action:       action,
+		ResourceType: auditservice.ResourceOrder,
+		ResourceID:   strconv.FormatInt(orderID, 10),
+		Details:      details,
+	}
+	if err := s.recorder.Record(ctx, entry); err != nil {
+		log.Printf("[WARN] Failed to record %s audit entry for order %d in tenant %d: %v", action, orderID, tenantID, err)
+	}
+}
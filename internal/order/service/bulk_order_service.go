@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// MaxBulkOrders is the most orders a single bulk creation can contain
+const MaxBulkOrders = 100
+
+// ErrBulkRolledBack is the error of an order that was created and then rolled
+// back because another order of the same atomic batch failed
+var ErrBulkRolledBack = errors.New("not created because another order in the batch failed")
+
+// BulkOrderResult is the outcome of one order of a bulk creation. Order is set
+// if the order was created, Err otherwise.
+type BulkOrderResult struct {
+	Index int
+	Order *Order
+	Err   error
+}
+
+// BulkOrderService creates batches of orders in one transaction
+type BulkOrderService interface {
+	// CreateOrders creates the orders, returning a result for each in the order
+	// they were given. If atomic is set and any order fails, none are created.
+	// Otherwise the valid orders are created and the rest fail on their own.
+	CreateOrders(ctx context.Context, orders []Order, atomic bool) ([]BulkOrderResult, error)
+}
+
+// DBBulkOrderService implements BulkOrderService on top of an OrderService,
+// isolating the orders of a batch from each other with savepoints. Rolling back
+// to a savepoint also discards the commit hooks of the orders rolled back, so
+// their events aren't published.
+type DBBulkOrderService struct {
+	orderService OrderService
+	txManager    transaction.TxManager
+}
+
+// Ensure DBBulkOrderService implements BulkOrderService
+var _ BulkOrderService = (*DBBulkOrderService)(nil)
+
+// NewDBBulkOrderService creates a new DBBulkOrderService. Orders are created
+// through orderService, so its quota checks, metering and auditing apply to
+// each order of a batch.
+func NewDBBulkOrderService(db *sql.DB, orderService OrderService) *DBBulkOrderService {
+	return &DBBulkOrderService{
+		orderService: orderService,
+		txManager:    transaction.NewManager(db),
+	}
+}
+
+// WithTxManager runs the DBBulkOrderService's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (s *DBBulkOrderService) WithTxManager(txManager transaction.TxManager) *DBBulkOrderService {
+	s.txManager = txManager
+	return s
+}
+
+// CreateOrders creates a batch of orders in the transaction of the context
+func (s *DBBulkOrderService) CreateOrders(ctx context.Context, orders []Order, atomic bool) ([]BulkOrderResult, error) {
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: at least one order is required", ErrInvalidInput)
+	}
+	if len(orders) > MaxBulkOrders {
+		return nil, fmt.Errorf("%w: at most %d orders can be created at once", ErrInvalidInput, MaxBulkOrders)
+	}
+
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Mark the start of the batch so an atomic batch can be undone
+	if err := transaction.Savepoint(ctx, tx, "bulk_orders"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	results := make([]BulkOrderResult, len(orders))
+	failed := false
+	for i := range orders {
+		results[i].Index = i
+
+		// A failed order rolls back to its savepoint, leaving the transaction usable
+		if err := transaction.Savepoint(ctx, tx, "bulk_order"); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		created, err := s.orderService.CreateOrder(ctx, &orders[i])
+		if err != nil {
+			if rbErr := transaction.RollbackToSavepoint(ctx, tx, "bulk_order"); rbErr != nil {
+				return nil, fmt.Errorf("%w: %v", ErrDBOperation, rbErr)
+			}
+			results[i].Err = err
+			failed = true
+			continue
+		}
+
+		if err := transaction.ReleaseSavepoint(ctx, tx, "bulk_order"); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		results[i].Order = created
+	}
+
+	// Undo the whole batch if an order of an atomic batch failed
+	if atomic && failed {
+		if err := transaction.RollbackToSavepoint(ctx, tx, "bulk_orders"); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Order = nil
+				results[i].Err = ErrBulkRolledBack
+			}
+		}
+	}
+
+	if err := transaction.ReleaseSavepoint(ctx, tx, "bulk_orders"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return results, nil
+}
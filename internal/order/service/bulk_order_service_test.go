@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubOrderService creates orders by numbering them, failing orders without an
+// order number
+type stubOrderService struct {
+	OrderService
+	created int
+}
+
+func (s *stubOrderService) CreateOrder(ctx context.Context, order *Order) (*Order, error) {
+	if order.OrderNumber == "" {
+		return nil, ErrInvalidInput
+	}
+	s.created++
+	created := *order
+	created.ID = int64(s.created)
+	return &created, nil
+}
+
+func TestBulkCreateOrders(t *testing.T) {
+	tenantID := int64(42)
+	orders := []Order{
+		{TenantID: tenantID, UserID: 100, OrderNumber: "ORD-001"},
+		{TenantID: tenantID, UserID: 100},
+		{TenantID: tenantID, UserID: 100, OrderNumber: "ORD-003"},
+	}
+
+	// expectBatch expects the savepoints of a batch whose second order fails
+	expectBatch := func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec("SAVEPOINT bulk_orders").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT bulk_order").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT bulk_order").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT bulk_order").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT bulk_order").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT bulk_order").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT bulk_order").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("Partial failure", func(t *testing.T) {
+		db, mock, _ := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		service := NewDBBulkOrderService(db, &stubOrderService{})
+
+		// Expect the failed order to be rolled back on its own
+		expectBatch(mock)
+		mock.ExpectExec("RELEASE SAVEPOINT bulk_orders").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute test
+		results, err := service.CreateOrders(ctx, orders, false)
+
+		// Verify results
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.NotNil(t, results[0].Order)
+		assert.ErrorIs(t, results[1].Err, ErrInvalidInput)
+		assert.Nil(t, results[1].Order)
+		assert.Equal(t, 2, results[2].Index)
+		assert.NotNil(t, results[2].Order)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Atomic failure", func(t *testing.T) {
+		db, mock, _ := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		service := NewDBBulkOrderService(db, &stubOrderService{})
+
+		// Expect the whole batch to be rolled back
+		expectBatch(mock)
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT bulk_orders").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT bulk_orders").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute test
+		results, err := service.CreateOrders(ctx, orders, true)
+
+		// Verify results
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.ErrorIs(t, results[0].Err, ErrBulkRolledBack)
+		assert.Nil(t, results[0].Order)
+		assert.ErrorIs(t, results[1].Err, ErrInvalidInput)
+		assert.ErrorIs(t, results[2].Err, ErrBulkRolledBack)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Too many orders", func(t *testing.T) {
+		db, _, _ := setupMock(t)
+		defer db.Close()
+
+		service := NewDBBulkOrderService(db, &stubOrderService{})
+
+		// Execute test
+		results, err := service.CreateOrders(createContextWithTenant(tenantID), make([]Order, MaxBulkOrders+1), false)
+
+		// Verify results
+		assert.Nil(t, results)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
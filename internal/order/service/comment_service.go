@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// Comment errors
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrNotCommentAuthor is returned when a user deletes someone else's comment
+	ErrNotCommentAuthor = errors.New("only the author can delete a comment")
+)
+
+// MaxCommentLength is the longest comment body, in characters
+const MaxCommentLength = 5000
+
+// Comment is a comment on an order
+type Comment struct {
+	ID       int64 `json:"id"`
+	OrderID  int64 `json:"order_id"`
+	TenantID int64 `json:"tenant_id"`
+	// AuthorID is nil once the author's account is deleted
+	AuthorID   *int64    `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CommentService defines the interface for order comment operations
+type CommentService interface {
+	// ListComments lists the comments on an order of the current tenant, oldest first
+	ListComments(ctx context.Context, orderID int64) ([]Comment, error)
+
+	// AddComment adds a comment by the current user to an order of the current tenant
+	AddComment(ctx context.Context, orderID int64, body string) (*Comment, error)
+
+	// DeleteComment deletes a comment of the current user from an order of the
+	// current tenant
+	DeleteComment(ctx context.Context, orderID int64, commentID int64) error
+}
+
+// DBCommentService implements CommentService using a database
+type DBCommentService struct {
+	txManager transaction.TxManager
+}
+
+// Ensure DBCommentService implements CommentService
+var _ CommentService = (*DBCommentService)(nil)
+
+// NewDBCommentService creates a new DBCommentService
+func NewDBCommentService(db *sql.DB) *DBCommentService {
+	return &DBCommentService{
+		txManager: transaction.NewManager(db),
+	}
+}
+
+// WithTxManager runs the DBCommentService's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (s *DBCommentService) WithTxManager(txManager transaction.TxManager) *DBCommentService {
+	s.txManager = txManager
+	return s
+}
+
+// ListComments lists the comments on an order of the current tenant, oldest first
+func (s *DBCommentService) ListComments(ctx context.Context, orderID int64) ([]Comment, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := checkOrderExists(ctx, tx, *tenantID, orderID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT c.comment_id, c.order_id, c.tenant_id, c.author_id, COALESCE(u.first_name || ' ' || u.last_name, ''), c.body, c.created_at
+		FROM order_comment c
+		LEFT JOIN usr u ON u.id = c.author_id
+		WHERE c.order_id = $1 AND c.tenant_id = $2
+		ORDER BY c.created_at, c.comment_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, orderID, *tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		var comment Comment
+		var authorID sql.NullInt64
+		err := rows.Scan(&comment.ID, &comment.OrderID, &comment.TenantID, &authorID, &comment.AuthorName, &comment.Body, &comment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if authorID.Valid {
+			comment.AuthorID = &authorID.Int64
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return comments, nil
+}
+
+// AddComment adds a comment by the current user to an order of the current
+// tenant. Deleted orders can't be commented on.
+func (s *DBCommentService) AddComment(ctx context.Context, orderID int64, body string) (*Comment, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	userID, err := authctx.GetUserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: comments need an author", ErrInvalidInput)
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment is empty", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return nil, fmt.Errorf("%w: comment is longer than %d characters", ErrInvalidInput, MaxCommentLength)
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := checkOrderExists(ctx, tx, *tenantID, orderID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO order_comment (order_id, tenant_id, author_id, body)
+			VALUES ($1, $2, $3, $4)
+			RETURNING comment_id, created_at
+		)
+		SELECT inserted.comment_id, inserted.created_at, COALESCE((SELECT first_name || ' ' || last_name FROM usr WHERE id = $3), '')
+		FROM inserted
+	`
+
+	comment := &Comment{
+		OrderID:  orderID,
+		TenantID: *tenantID,
+		AuthorID: &userID,
+		Body:     body,
+	}
+	err = tx.QueryRowContext(ctx, query, orderID, *tenantID, userID, body).Scan(&comment.ID, &comment.CreatedAt, &comment.AuthorName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] User %d commented on order %d of tenant %d", userID, orderID, *tenantID)
+	return comment, nil
+}
+
+// DeleteComment deletes a comment of the current user from an order of the
+// current tenant
+func (s *DBCommentService) DeleteComment(ctx context.Context, orderID int64, commentID int64) error {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return ErrNoTenantContext
+	}
+
+	userID, err := authctx.GetUserID(ctx)
+	if err != nil {
+		return ErrNotCommentAuthor
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	var authorID sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT author_id FROM order_comment WHERE comment_id = $1 AND order_id = $2 AND tenant_id = $3 FOR UPDATE",
+		commentID, orderID, *tenantID).Scan(&authorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if !authorID.Valid || authorID.Int64 != userID {
+		return ErrNotCommentAuthor
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_comment WHERE comment_id = $1", commentID); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] User %d deleted comment %d on order %d of tenant %d", userID, commentID, orderID, *tenantID)
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+func setupCommentService(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *DBCommentService) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return db, mock, NewDBCommentService(db)
+}
+
+func TestListComments(t *testing.T) {
+	db, mock, service := setupCommentService(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	createdAt := time.Now()
+	ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+	expectOrderExists(mock, 1, tenantID)
+	mock.ExpectQuery("SELECT c.comment_id, c.order_id, c.tenant_id, c.author_id").
+		WithArgs(int64(1), tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"comment_id", "order_id", "tenant_id", "author_id", "author_name", "body", "created_at"}).
+			AddRow(1, 1, tenantID, 7, "Jane Doe", "Customer called", createdAt).
+			AddRow(2, 1, tenantID, nil, "", "Shipped early", createdAt))
+
+	// Execute test
+	comments, err := service.ListComments(ctx, 1)
+
+	// Verify results
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	require.NotNil(t, comments[0].AuthorID)
+	assert.Equal(t, int64(7), *comments[0].AuthorID)
+	assert.Equal(t, "Jane Doe", comments[0].AuthorName)
+	assert.Nil(t, comments[1].AuthorID, "comments of deleted users have no author")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddComment(t *testing.T) {
+	tenantID := int64(42)
+	userID := int64(7)
+
+	t.Run("Added", func(t *testing.T) {
+		db, mock, service := setupCommentService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, authctx.WithUserID(createContextWithTenant(tenantID), userID), db, mock)
+		expectOrderExists(mock, 1, tenantID)
+		mock.ExpectQuery("INSERT INTO order_comment").
+			WithArgs(int64(1), tenantID, userID, "Customer called").
+			WillReturnRows(sqlmock.NewRows([]string{"comment_id", "created_at", "author_name"}).AddRow(3, time.Now(), "Jane Doe"))
+
+		// Execute test
+		comment, err := service.AddComment(ctx, 1, "  Customer called\n")
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), comment.ID)
+		assert.Equal(t, "Customer called", comment.Body)
+		assert.Equal(t, "Jane Doe", comment.AuthorName)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		db, _, service := setupCommentService(t)
+		defer db.Close()
+
+		// Execute test
+		_, err := service.AddComment(authctx.WithUserID(createContextWithTenant(tenantID), userID), 1, " \n ")
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("No author", func(t *testing.T) {
+		db, _, service := setupCommentService(t)
+		defer db.Close()
+
+		// Execute test
+		_, err := service.AddComment(createContextWithTenant(tenantID), 1, "Customer called")
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	tenantID := int64(42)
+	userID := int64(7)
+
+	t.Run("Deleted by author", func(t *testing.T) {
+		db, mock, service := setupCommentService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, authctx.WithUserID(createContextWithTenant(tenantID), userID), db, mock)
+		mock.ExpectQuery("SELECT author_id FROM order_comment").
+			WithArgs(int64(3), int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(userID))
+		mock.ExpectExec("DELETE FROM order_comment WHERE comment_id = \\$1").
+			WithArgs(int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute test
+		err := service.DeleteComment(ctx, 1, 3)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Someone else's comment", func(t *testing.T) {
+		db, mock, service := setupCommentService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, authctx.WithUserID(createContextWithTenant(tenantID), userID), db, mock)
+		mock.ExpectQuery("SELECT author_id FROM order_comment").
+			WithArgs(int64(3), int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(8))
+
+		// Execute test
+		err := service.DeleteComment(ctx, 1, 3)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrNotCommentAuthor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		db, mock, service := setupCommentService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, authctx.WithUserID(createContextWithTenant(tenantID), userID), db, mock)
+		mock.ExpectQuery("SELECT author_id FROM order_comment").
+			WithArgs(int64(3), int64(1), tenantID).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute test
+		err := service.DeleteComment(ctx, 1, 3)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+}
+
+func TestCommentServiceWithFakeManager(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	service := NewDBCommentService(db).WithTxManager(transaction.NewFakeManager(tx))
+
+	// Expect the comments to be read in the fake's transaction, though the
+	// context has none
+	tenantID := int64(42)
+	expectOrderExists(mock, 1, tenantID)
+	mock.ExpectQuery("SELECT c.comment_id, c.order_id, c.tenant_id, c.author_id").
+		WithArgs(int64(1), tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"comment_id", "order_id", "tenant_id", "author_id", "author_name", "body", "created_at"}).
+			AddRow(1, 1, tenantID, 7, "Jane Doe", "Customer called", time.Now()))
+
+	// Execute test
+	comments, err := service.ListComments(createContextWithTenant(tenantID), 1)
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
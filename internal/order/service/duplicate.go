@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// DuplicateOrder creates a new pending order copied from an existing order of the
+// current tenant, for repeat orders. The copy keeps the original's customer,
+// items, notes, discount and tax rate, and gets the tenant's next order number.
+// It is created through orderService, so quotas, metering and auditing apply as
+// for any new order.
+func DuplicateOrder(ctx context.Context, orderService OrderService, orderID int64) (*Order, error) {
+	original, err := orderService.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicate := &Order{
+		TenantID:       original.TenantID,
+		UserID:         original.UserID,
+		Status:         "pending",
+		Notes:          original.Notes,
+		Subtotal:       original.Subtotal,
+		DiscountAmount: original.DiscountAmount,
+		TaxRate:        original.TaxRate,
+		Items:          make([]OrderItem, len(original.Items)),
+		StatusReason:   fmt.Sprintf("Duplicated from order %s", original.OrderNumber),
+	}
+	for i, item := range original.Items {
+		duplicate.Items[i] = OrderItem{
+			SKU:         item.SKU,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		}
+	}
+
+	return orderService.CreateOrder(ctx, duplicate)
+}
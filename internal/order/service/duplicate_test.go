@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedOrderService returns a fixed order and keeps the orders it creates
+type fixedOrderService struct {
+	OrderService
+	order   *Order
+	created []*Order
+}
+
+func (s *fixedOrderService) GetOrder(ctx context.Context, orderID int64) (*Order, error) {
+	if s.order == nil || s.order.ID != orderID {
+		return nil, ErrOrderNotFound
+	}
+	return s.order, nil
+}
+
+func (s *fixedOrderService) CreateOrder(ctx context.Context, order *Order) (*Order, error) {
+	order.ID = 100 + int64(len(s.created))
+	s.created = append(s.created, order)
+	return order, nil
+}
+
+func TestDuplicateOrder(t *testing.T) {
+	original := &Order{
+		ID:             7,
+		TenantID:       42,
+		UserID:         3,
+		OrderNumber:    "ORD-2025-000007",
+		Status:         "delivered",
+		TotalAmount:    19.8,
+		Notes:          "Leave at the front desk",
+		CreatedAt:      time.Now().Add(-24 * time.Hour),
+		Version:        5,
+		Subtotal:       20,
+		DiscountAmount: 2,
+		TaxRate:        10,
+		TaxAmount:      1.8,
+		Items:          []OrderItem{{ID: 11, OrderID: 7, SKU: "SKU-1", Description: "Widget", Quantity: 2, UnitPrice: 10}},
+	}
+	orders := &fixedOrderService{order: original}
+
+	t.Run("Copied", func(t *testing.T) {
+		// Execute test
+		duplicate, err := DuplicateOrder(context.Background(), orders, 7)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), duplicate.ID)
+		assert.Equal(t, "pending", duplicate.Status)
+		assert.Empty(t, duplicate.OrderNumber, "the copy is numbered when it is created")
+		assert.Zero(t, duplicate.TotalAmount, "the copy's total is recomputed")
+		assert.Equal(t, original.UserID, duplicate.UserID)
+		assert.Equal(t, original.DiscountAmount, duplicate.DiscountAmount)
+		assert.Equal(t, []OrderItem{{SKU: "SKU-1", Description: "Widget", Quantity: 2, UnitPrice: 10}}, duplicate.Items)
+		assert.Equal(t, "Duplicated from order ORD-2025-000007", duplicate.StatusReason)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		// Execute test
+		_, err := DuplicateOrder(context.Background(), orders, 8)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+	})
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// ResponseCacheInvalidator invalidates cached responses computed from orders,
+// such as order summaries and tenant statistics
+type ResponseCacheInvalidator interface {
+	// InvalidateTenant removes all cached responses of a tenant
+	InvalidateTenant(ctx context.Context, tenantID int64) error
+
+	// InvalidateAll removes the cached responses of all tenants
+	InvalidateAll(ctx context.Context) error
+}
+
+// InvalidatingOrderService decorates an OrderService, invalidating the tenant's
+// cached responses whenever its orders change. Changes made in a transaction
+// invalidate them once it commits, so requests running in the meantime can't
+// cache responses computed from the orders before the change.
+type InvalidatingOrderService struct {
+	OrderService
+	invalidator ResponseCacheInvalidator
+}
+
+// Ensure InvalidatingOrderService implements OrderService
+var _ OrderService = (*InvalidatingOrderService)(nil)
+
+// NewInvalidatingOrderService creates a new InvalidatingOrderService
+func NewInvalidatingOrderService(orderService OrderService, invalidator ResponseCacheInvalidator) *InvalidatingOrderService {
+	return &InvalidatingOrderService{
+		OrderService: orderService,
+		invalidator:  invalidator,
+	}
+}
+
+// CreateOrder creates a new order and invalidates the tenant's cached responses
+func (s *InvalidatingOrderService) CreateOrder(ctx context.Context, order *Order) (*Order, error) {
+	created, err := s.OrderService.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateTenant(ctx, created.TenantID)
+	return created, nil
+}
+
+// UpdateOrder updates an order and invalidates the tenant's cached responses
+func (s *InvalidatingOrderService) UpdateOrder(ctx context.Context, order *Order) error {
+	if err := s.OrderService.UpdateOrder(ctx, order); err != nil {
+		return err
+	}
+	s.invalidateTenant(ctx, order.TenantID)
+	return nil
+}
+
+// PatchOrder patches an order and invalidates the tenant's cached responses
+func (s *InvalidatingOrderService) PatchOrder(ctx context.Context, orderID int64, patch OrderPatch) (*Order, error) {
+	order, err := s.OrderService.PatchOrder(ctx, orderID, patch)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateTenant(ctx, order.TenantID)
+	return order, nil
+}
+
+// AssignOrder assigns an order and invalidates the tenant's cached responses
+func (s *InvalidatingOrderService) AssignOrder(ctx context.Context, orderID int64, userID *int64) (*Order, error) {
+	order, err := s.OrderService.AssignOrder(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateTenant(ctx, order.TenantID)
+	return order, nil
+}
+
+// DeleteOrder moves an order to the trash and invalidates the current tenant's
+// cached responses
+func (s *InvalidatingOrderService) DeleteOrder(ctx context.Context, orderID int64, version int) error {
+	if err := s.OrderService.DeleteOrder(ctx, orderID, version); err != nil {
+		return err
+	}
+	if tenantID, err := authctx.GetTenantID(ctx); err == nil && tenantID != nil {
+		s.invalidateTenant(ctx, *tenantID)
+	}
+	return nil
+}
+
+// RestoreOrder restores an order and invalidates the tenant's cached responses
+func (s *InvalidatingOrderService) RestoreOrder(ctx context.Context, orderID int64) (*Order, error) {
+	order, err := s.OrderService.RestoreOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateTenant(ctx, order.TenantID)
+	return order, nil
+}
+
+// PurgeDeletedOrders purges orders of all tenants and invalidates all cached
+// responses if any were purged
+func (s *InvalidatingOrderService) PurgeDeletedOrders(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := s.OrderService.PurgeDeletedOrders(ctx, before)
+	if err == nil && purged > 0 {
+		s.invalidateAll(ctx)
+	}
+	return purged, err
+}
+
+// ArchiveOrders archives orders of all tenants and invalidates all cached
+// responses if any were archived
+func (s *InvalidatingOrderService) ArchiveOrders(ctx context.Context, before time.Time) (int64, error) {
+	archived, err := s.OrderService.ArchiveOrders(ctx, before)
+	if err == nil && archived > 0 {
+		s.invalidateAll(ctx)
+	}
+	return archived, err
+}
+
+// invalidateTenant invalidates a tenant's cached responses once the change
+// commits, logging failures
+func (s *InvalidatingOrderService) invalidateTenant(ctx context.Context, tenantID int64) {
+	afterCommit(ctx, func(ctx context.Context) {
+		if err := s.invalidator.InvalidateTenant(ctx, tenantID); err != nil {
+			log.Printf("[ERROR] Failed to invalidate cached responses for tenant ID %d: %v", tenantID, err)
+		}
+	})
+}
+
+// invalidateAll invalidates all cached responses once the change commits,
+// logging failures
+func (s *InvalidatingOrderService) invalidateAll(ctx context.Context) {
+	afterCommit(ctx, func(ctx context.Context) {
+		if err := s.invalidator.InvalidateAll(ctx); err != nil {
+			log.Printf("[ERROR] Failed to invalidate cached responses: %v", err)
+		}
+	})
+}
+
+// afterCommit runs fn once the transaction in the context commits, or right away
+// if there is none
+func afterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	// The request may be over by the time the transaction commits
+	ctx = context.WithoutCancel(ctx)
+	if err := transaction.AfterCommit(ctx, func() { fn(ctx) }); err != nil {
+		fn(ctx)
+	}
+}
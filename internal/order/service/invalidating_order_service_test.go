@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// recordingInvalidator records the tenants whose cached responses were invalidated
+type recordingInvalidator struct {
+	tenants []int64
+	all     int
+}
+
+func (i *recordingInvalidator) InvalidateTenant(ctx context.Context, tenantID int64) error {
+	i.tenants = append(i.tenants, tenantID)
+	return nil
+}
+
+func (i *recordingInvalidator) InvalidateAll(ctx context.Context) error {
+	i.all++
+	return nil
+}
+
+// purgingOrderService purges a fixed number of orders
+type purgingOrderService struct {
+	stubOrderService
+	purged int64
+}
+
+func (s *purgingOrderService) PurgeDeletedOrders(ctx context.Context, before time.Time) (int64, error) {
+	return s.purged, nil
+}
+
+func TestInvalidatingOrderService(t *testing.T) {
+	tenantID := int64(42)
+
+	t.Run("Invalidates the tenant's responses", func(t *testing.T) {
+		invalidator := &recordingInvalidator{}
+		service := NewInvalidatingOrderService(&stubOrderService{}, invalidator)
+
+		_, err := service.CreateOrder(context.Background(), &Order{TenantID: tenantID, OrderNumber: "ORD-001"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{tenantID}, invalidator.tenants)
+	})
+
+	t.Run("Failed changes don't invalidate", func(t *testing.T) {
+		invalidator := &recordingInvalidator{}
+		service := NewInvalidatingOrderService(&stubOrderService{}, invalidator)
+
+		_, err := service.CreateOrder(context.Background(), &Order{TenantID: tenantID})
+
+		assert.ErrorIs(t, err, ErrInvalidInput)
+		assert.Empty(t, invalidator.tenants)
+	})
+
+	t.Run("Invalidates once the transaction commits", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		invalidator := &recordingInvalidator{}
+		service := NewInvalidatingOrderService(&stubOrderService{}, invalidator)
+
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+		err = transaction.NewManager(db).WithTransaction(context.Background(), func(ctx context.Context) error {
+			_, err := service.CreateOrder(ctx, &Order{TenantID: tenantID, OrderNumber: "ORD-001"})
+			assert.Empty(t, invalidator.tenants)
+			return err
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{tenantID}, invalidator.tenants)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Purging invalidates all responses", func(t *testing.T) {
+		invalidator := &recordingInvalidator{}
+		service := NewInvalidatingOrderService(&purgingOrderService{purged: 3}, invalidator)
+
+		_, err := service.PurgeDeletedOrders(context.Background(), time.Now())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, invalidator.all)
+
+		// Nothing to invalidate if nothing was purged
+		service = NewInvalidatingOrderService(&purgingOrderService{}, invalidator)
+		_, err = service.PurgeDeletedOrders(context.Background(), time.Now())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, invalidator.all)
+	})
+}
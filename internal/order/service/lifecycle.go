@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultOrderRetention is how long deleted orders can be restored
+	DefaultOrderRetention = 30 * 24 * time.Hour
+
+	// DefaultOrderPurgeInterval is how often expired orders are purged
+	DefaultOrderPurgeInterval = time.Hour
+
+	// DefaultOrderArchiveAfter is how long an order stays unchanged before it is
+	// archived
+	DefaultOrderArchiveAfter = 365 * 24 * time.Hour
+
+	// DefaultOrderArchiveInterval is how often old orders are archived
+	DefaultOrderArchiveInterval = 24 * time.Hour
+
+	// Environment variable names
+	envOrderRetentionDays    = "ORDER_RETENTION_DAYS"
+	envOrderPurgeIntervalM   = "ORDER_PURGE_INTERVAL_MINUTES"
+	envOrderArchiveAfterDays = "ORDER_ARCHIVE_AFTER_DAYS"
+	envOrderArchiveInterval  = "ORDER_ARCHIVE_INTERVAL_MINUTES"
+)
+
+// LifecycleConfig holds configuration for deleted and archived orders. Orders
+// are not archived if ArchiveAfter is zero.
+type LifecycleConfig struct {
+	Retention       time.Duration
+	PurgeInterval   time.Duration
+	ArchiveAfter    time.Duration
+	ArchiveInterval time.Duration
+}
+
+// LoadLifecycleConfig loads order lifecycle configuration from environment variables
+func LoadLifecycleConfig() (LifecycleConfig, error) {
+	config := LifecycleConfig{
+		Retention:       DefaultOrderRetention,
+		PurgeInterval:   DefaultOrderPurgeInterval,
+		ArchiveAfter:    DefaultOrderArchiveAfter,
+		ArchiveInterval: DefaultOrderArchiveInterval,
+	}
+
+	if daysStr := os.Getenv(envOrderRetentionDays); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			return LifecycleConfig{}, fmt.Errorf("invalid ORDER_RETENTION_DAYS value: %q", daysStr)
+		}
+		config.Retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	if minutesStr := os.Getenv(envOrderPurgeIntervalM); minutesStr != "" {
+		minutes, err := strconv.Atoi(minutesStr)
+		if err != nil || minutes <= 0 {
+			return LifecycleConfig{}, fmt.Errorf("invalid ORDER_PURGE_INTERVAL_MINUTES value: %q", minutesStr)
+		}
+		config.PurgeInterval = time.Duration(minutes) * time.Minute
+	}
+
+	if daysStr := os.Getenv(envOrderArchiveAfterDays); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			return LifecycleConfig{}, fmt.Errorf("invalid ORDER_ARCHIVE_AFTER_DAYS value: %q", daysStr)
+		}
+		config.ArchiveAfter = time.Duration(days) * 24 * time.Hour
+	}
+
+	if minutesStr := os.Getenv(envOrderArchiveInterval); minutesStr != "" {
+		minutes, err := strconv.Atoi(minutesStr)
+		if err != nil || minutes <= 0 {
+			return LifecycleConfig{}, fmt.Errorf("invalid ORDER_ARCHIVE_INTERVAL_MINUTES value: %q", minutesStr)
+		}
+		config.ArchiveInterval = time.Duration(minutes) * time.Minute
+	}
+
+	return config, nil
+}
+
+// StartPurgeJob purges orders deleted more than the retention ago every purge
+// interval until ctx is cancelled
+func StartPurgeJob(ctx context.Context, orderService OrderService, config LifecycleConfig) {
+	log.Printf("[INFO] Starting order purge job every %s", config.PurgeInterval)
+
+	go func() {
+		ticker := time.NewTicker(config.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("[INFO] Stopping order purge job")
+				return
+			case <-ticker.C:
+				purged, err := orderService.PurgeDeletedOrders(ctx, time.Now().Add(-config.Retention))
+				if err != nil {
+					log.Printf("[ERROR] Failed to purge deleted orders: %v", err)
+					continue
+				}
+				if purged > 0 {
+					log.Printf("[INFO] Purged %d deleted orders", purged)
+				}
+			}
+		}
+	}()
+}
+
+// StartArchiveJob archives orders unchanged for longer than the configured age
+// every archive interval until ctx is cancelled. It does nothing if archiving is
+// disabled.
+func StartArchiveJob(ctx context.Context, orderService OrderService, config LifecycleConfig) {
+	if config.ArchiveAfter == 0 {
+		log.Printf("[INFO] Order archiving is disabled")
+		return
+	}
+
+	log.Printf("[INFO] Starting order archive job every %s", config.ArchiveInterval)
+
+	go func() {
+		ticker := time.NewTicker(config.ArchiveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("[INFO] Stopping order archive job")
+				return
+			case <-ticker.C:
+				archived, err := orderService.ArchiveOrders(ctx, time.Now().Add(-config.ArchiveAfter))
+				if archived > 0 {
+					log.Printf("[INFO] Archived %d orders", archived)
+				}
+				if err != nil {
+					log.Printf("[ERROR] Failed to archive orders: %v", err)
+				}
+			}
+		}
+	}()
+}
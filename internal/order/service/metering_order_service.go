@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+)
+
+// OrderUsageRecorder records created orders for usage metering
+type OrderUsageRecorder interface {
+	RecordOrderCreated(tenantID int64)
+}
+
+// MeteringOrderService decorates an OrderService, recording created orders
+type MeteringOrderService struct {
+	OrderService
+	recorder OrderUsageRecorder
+}
+
+// Ensure MeteringOrderService implements OrderService
+var _ OrderService = (*MeteringOrderService)(nil)
+
+// NewMeteringOrderService creates a new MeteringOrderService
+func NewMeteringOrderService(orderService OrderService, recorder OrderUsageRecorder) *MeteringOrderService {
+	return &MeteringOrderService{
+		OrderService: orderService,
+		recorder:     recorder,
+	}
+}
+
+// CreateOrder creates a new order and records it against the tenant's usage
+func (s *MeteringOrderService) CreateOrder(ctx context.Context, order *Order) (*Order, error) {
+	created, err := s.OrderService.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recorder.RecordOrderCreated(created.TenantID)
+	return created, nil
+}
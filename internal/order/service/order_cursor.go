@@ -0,0 +1,41 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OrderCursor is the position of an order in lists ordered by creation time,
+// newest first. Keyset pagination continues after the cursor's order, so pages
+// stay fast and stable however deep the client pages.
+type OrderCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"id"`
+}
+
+// orderCursorAfter returns the cursor of the given order
+func orderCursorAfter(order Order) OrderCursor {
+	return OrderCursor{CreatedAt: order.CreatedAt, ID: order.ID}
+}
+
+// EncodeOrderCursor encodes a cursor as an opaque token
+func EncodeOrderCursor(cursor OrderCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeOrderCursor decodes a token created by EncodeOrderCursor
+func DecodeOrderCursor(token string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+	}
+
+	var cursor OrderCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 || cursor.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+	}
+	return &cursor, nil
+}
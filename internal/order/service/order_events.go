@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/events"
+)
+
+// Order event types published by DBOrderService
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderUpdated       = "order.updated"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderDeleted       = "order.deleted"
+	EventOrderRestored      = "order.restored"
+	EventOrderAssigned      = "order.assigned"
+	EventOrderUnassigned    = "order.unassigned"
+)
+
+// orderPublishTimeout is how long publishing an order event can take
+const orderPublishTimeout = 10 * time.Second
+
+// OrderStatusChangedData is the data of an order.status_changed event
+type OrderStatusChangedData struct {
+	OrderID     int64  `json:"order_id"`
+	OrderNumber string `json:"order_number"`
+	FromStatus  string `json:"from_status"`
+	ToStatus    string `json:"to_status"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// OrderAssignedData is the data of order.assigned and order.unassigned events.
+// AssignedTo is nil when the order is unassigned.
+type OrderAssignedData struct {
+	OrderID          int64  `json:"order_id"`
+	OrderNumber      string `json:"order_number"`
+	AssignedTo       *int64 `json:"assigned_to"`
+	PreviousAssignee *int64 `json:"previous_assignee"`
+}
+
+// OrderDeletedData is the data of an order.deleted event
+type OrderDeletedData struct {
+	OrderID int64 `json:"order_id"`
+	Version int   `json:"version"`
+}
+
+// WithEventPublisher publishes an event for each order change after its
+// transaction commits. Events aren't published without a publisher or outbox.
+func (s *DBOrderService) WithEventPublisher(publisher events.Publisher) *DBOrderService {
+	s.publisher = publisher
+	return s
+}
+
+// WithOutbox writes an event for each order change to the outbox in the change's
+// transaction, for the outbox dispatcher to publish. Unlike publishing after the
+// commit, an event can't be lost if the process stops or the broker is down.
+// The outbox is used instead of the publisher.
+func (s *DBOrderService) WithOutbox(outbox *events.Outbox) *DBOrderService {
+	s.outbox = outbox
+	return s
+}
+
+// publishEvent writes an order event to the outbox, if there is one, or
+// publishes it once the transaction in the context commits. Failing to write to
+// the outbox fails the change; failing to publish is only logged.
+func (s *DBOrderService) publishEvent(ctx context.Context, eventType string, tenantID int64, orderID int64, data interface{}) error {
+	if s.outbox == nil && s.publisher == nil {
+		return nil
+	}
+
+	event, err := events.NewEvent(eventType, tenantID, strconv.FormatInt(orderID, 10), data)
+	if err != nil {
+		if s.outbox != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		log.Printf("[WARN] Failed to create %s event for order %d: %v", eventType, orderID, err)
+		return nil
+	}
+	if userID, err := authctx.GetUserID(ctx); err == nil {
+		event.ActorUserID = &userID
+	}
+
+	if s.outbox != nil {
+		if err := s.outbox.Enqueue(ctx, event); err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		return nil
+	}
+
+	publisher := s.publisher
+	err = transaction.AfterCommit(ctx, func() {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), orderPublishTimeout)
+			defer cancel()
+
+			if err := publisher.Publish(ctx, event); err != nil {
+				log.Printf("[WARN] Failed to publish %s event %s for order %d: %v", eventType, event.ID, orderID, err)
+			}
+		}()
+	})
+	if err != nil {
+		log.Printf("[WARN] Not publishing %s event for order %d: %v", eventType, orderID, err)
+	}
+	return nil
+}
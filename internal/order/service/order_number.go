@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// Order number prefixes
+const (
+	// DefaultOrderNumberPrefix prefixes the order numbers of tenants without
+	// their own prefix
+	DefaultOrderNumberPrefix = "ORD"
+
+	// MaxOrderNumberPrefixLength is the longest prefix a tenant can set
+	MaxOrderNumberPrefixLength = 16
+)
+
+// orderNumberPrefixPattern matches valid prefixes: upper case letters, digits
+// and dashes, starting and ending with a letter or digit
+var orderNumberPrefixPattern = regexp.MustCompile(`^[A-Z0-9]([A-Z0-9-]*[A-Z0-9])?$`)
+
+// OrderSettings holds a tenant's order settings
+type OrderSettings struct {
+	// OrderNumberPrefix prefixes the numbers generated for the tenant's orders
+	OrderNumberPrefix string `json:"order_number_prefix" validate:"max=16"`
+}
+
+// formatOrderNumber formats the nth order number of a year, e.g. ORD-2024-000123
+func formatOrderNumber(prefix string, year int, n int64) string {
+	return fmt.Sprintf("%s-%d-%06d", prefix, year, n)
+}
+
+// nextOrderNumber takes the tenant's next order number for the year of now. The
+// tenant's counter stays locked until the transaction ends and is rolled back
+// with it, so concurrent orders wait for each other and numbers have no gaps.
+func (s *DBOrderService) nextOrderNumber(ctx context.Context, tx *sql.Tx, tenantID int64, now time.Time) (string, error) {
+	year := now.UTC().Year()
+
+	query := `
+		INSERT INTO order_number_sequence (tenant_id, year, last_value)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (tenant_id, year) DO UPDATE SET last_value = order_number_sequence.last_value + 1
+		RETURNING last_value, COALESCE((SELECT order_number_prefix FROM tenant_order_setting WHERE tenant_id = $1), $3)
+	`
+
+	var n int64
+	var prefix string
+	if err := tx.QueryRowContext(ctx, query, tenantID, year, DefaultOrderNumberPrefix).Scan(&n, &prefix); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+
+	return formatOrderNumber(prefix, year, n), nil
+}
+
+// GetOrderSettings retrieves the order settings of the current tenant
+func (s *DBOrderService) GetOrderSettings(ctx context.Context) (*OrderSettings, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	settings := &OrderSettings{OrderNumberPrefix: DefaultOrderNumberPrefix}
+	err = tx.QueryRowContext(ctx, "SELECT order_number_prefix FROM tenant_order_setting WHERE tenant_id = $1", *tenantID).
+		Scan(&settings.OrderNumberPrefix)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return settings, nil
+}
+
+// UpdateOrderSettings updates the order settings of the current tenant. An empty
+// prefix resets it to the default. The new prefix applies to orders numbered
+// from then on.
+func (s *DBOrderService) UpdateOrderSettings(ctx context.Context, settings *OrderSettings) error {
+	settings.OrderNumberPrefix = strings.ToUpper(strings.TrimSpace(settings.OrderNumberPrefix))
+	if settings.OrderNumberPrefix == "" {
+		settings.OrderNumberPrefix = DefaultOrderNumberPrefix
+	}
+	if len(settings.OrderNumberPrefix) > MaxOrderNumberPrefixLength {
+		return fmt.Errorf("%w: order number prefix must be at most %d characters", ErrInvalidInput, MaxOrderNumberPrefixLength)
+	}
+	if !orderNumberPrefixPattern.MatchString(settings.OrderNumberPrefix) {
+		return fmt.Errorf("%w: order number prefix can only contain letters, digits and dashes between them", ErrInvalidInput)
+	}
+
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	query := `
+		INSERT INTO tenant_order_setting (tenant_id, order_number_prefix, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			order_number_prefix = EXCLUDED.order_number_prefix,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := tx.ExecContext(ctx, query, *tenantID, settings.OrderNumberPrefix); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return nil
+}
@@ -3,12 +3,17 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	authctx "github.com/unsavory/silocore-go/internal/auth/context"
 	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/events"
+	"github.com/unsavory/silocore-go/internal/order/orderdb"
 )
 
 // Common errors
@@ -17,56 +22,370 @@ var (
 	ErrDBOperation     = errors.New("database operation failed")
 	ErrInvalidInput    = errors.New("invalid input")
 	ErrNoTenantContext = errors.New("tenant context is required")
+	// ErrConflict is returned when an order was changed since the version the
+	// caller expected
+	ErrConflict = errors.New("order was modified concurrently")
 )
 
+// writeAttempts is how many times writes that contend on hot rows, such as an
+// order or the tenant's order number sequence, are attempted when they deadlock
+// or fail to serialize with concurrent writes
+const writeAttempts = 3
+
 // Order represents an order in the system
 type Order struct {
 	ID          int64     `json:"id"`
 	TenantID    int64     `json:"tenant_id"`
 	UserID      int64     `json:"user_id"`
-	OrderNumber string    `json:"order_number"`
-	Status      string    `json:"status"`
-	TotalAmount float64   `json:"total_amount"`
+	OrderNumber string    `json:"order_number" validate:"max=64"`
+	Status      string    `json:"status" validate:"omitempty,oneof=pending processing completed cancelled"`
+	TotalAmount float64   `json:"total_amount" validate:"min=0"`
 	Notes       string    `json:"notes"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Subtotal is the sum of the items' amounts, or given for orders without
+	// items. The total is the subtotal less DiscountAmount plus TaxAmount, which
+	// is charged at TaxRate percent of the discounted subtotal. The subtotal, tax
+	// and total are computed by the service.
+	Subtotal       float64 `json:"subtotal" validate:"min=0"`
+	DiscountAmount float64 `json:"discount_amount" validate:"min=0"`
+	TaxRate        float64 `json:"tax_rate" validate:"min=0,max=100"`
+	TaxAmount      float64 `json:"tax_amount"`
+
+	// AssignedTo is the tenant member working on the order, or nil if it is
+	// unassigned. It is changed with AssignOrder.
+	AssignedTo *int64 `json:"assigned_to"`
+
+	// Version is incremented by every update. Updates and deletes must give the
+	// version they expect the order to have.
+	Version int `json:"version"`
+
+	// DeletedAt is set on orders in the trash, which are only listed with
+	// OrderFilter.IncludeDeleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Items are the order's line items. They are returned by GetOrder, not by
+	// ListOrders.
+	Items []OrderItem `json:"items,omitempty" validate:"dive"`
+
+	// StatusReason is recorded in the status history when the order is created
+	// or its status changes. It isn't stored on the order.
+	StatusReason string `json:"status_reason,omitempty"`
+}
+
+// OrderItem represents a line item of an order
+type OrderItem struct {
+	ID          int64   `json:"id"`
+	OrderID     int64   `json:"order_id"`
+	SKU         string  `json:"sku" validate:"required,max=64"`
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity" validate:"min=1"`
+	UnitPrice   float64 `json:"unit_price" validate:"min=0"`
+}
+
+// OrderPatch is a partial update of an order. Nil fields are left unchanged.
+type OrderPatch struct {
+	UserID      *int64   `json:"user_id"`
+	OrderNumber *string  `json:"order_number" validate:"omitempty,max=64"`
+	Status      *string  `json:"status" validate:"omitempty,oneof=pending processing completed cancelled"`
+	TotalAmount *float64 `json:"total_amount" validate:"omitempty,min=0"`
+	Notes       *string  `json:"notes"`
+
+	Subtotal       *float64 `json:"subtotal" validate:"omitempty,min=0"`
+	DiscountAmount *float64 `json:"discount_amount" validate:"omitempty,min=0"`
+	TaxRate        *float64 `json:"tax_rate" validate:"omitempty,min=0,max=100"`
+
+	// Version is the version the order is expected to have. It is required.
+	Version *int `json:"version" validate:"required"`
+
+	// StatusReason is recorded in the status history if the status changes
+	StatusReason string `json:"status_reason,omitempty"`
+}
+
+// OrderStatusChange is an entry of an order's status history. FromStatus is nil
+// for the order's first status.
+type OrderStatusChange struct {
+	ID         int64     `json:"id"`
+	OrderID    int64     `json:"order_id"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *int64    `json:"changed_by"`
+	Reason     string    `json:"reason"`
+	ChangedAt  time.Time `json:"changed_at"`
 }
 
+// Amount returns the item's quantity times its unit price
+func (i OrderItem) Amount() float64 {
+	return float64(i.Quantity) * i.UnitPrice
+}
+
+// Order list page sizes
+const (
+	DefaultOrderListLimit = 50
+	MaxOrderListLimit     = 500
+)
+
+// OrderExportChunkSize is the number of orders an export reads at a time
+const OrderExportChunkSize = 1000
+
+// OrderArchiveBatchSize is how many orders the archive job moves per statement
+const OrderArchiveBatchSize = 1000
+
 // OrderFilter represents filters for listing orders
 type OrderFilter struct {
 	Status string
 	UserID *int64
+	// AssignedTo restricts the list to orders assigned to the user, and
+	// Unassigned to orders assigned to no one
+	AssignedTo *int64
+	Unassigned bool
+	// Search matches orders whose order number or notes contain the term,
+	// ignoring case
+	Search string
+	// CreatedFrom and CreatedTo restrict the list to orders created in
+	// [CreatedFrom, CreatedTo)
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// MinAmount and MaxAmount restrict the list to orders with a total in
+	// [MinAmount, MaxAmount]
+	MinAmount *float64
+	MaxAmount *float64
+	// Tags restricts the list to orders with all of the tags
+	Tags   []string
 	Limit  int
 	Offset int
+	// Cursor is a token from OrderList.NextCursor. Lists continue after the
+	// cursor's order instead of skipping Offset orders.
+	Cursor string
+	// Sort is a column from OrderSortColumns, prefixed with "-" to sort in
+	// descending order. Lists default to DefaultOrderSort.
+	Sort string
+	// IncludeDeleted includes orders in the trash
+	IncludeDeleted bool
+	// Archived lists the orders moved to the archive instead
+	Archived bool
+}
+
+// DefaultOrderSort lists the newest orders first. Cursors only page lists in
+// this order.
+const DefaultOrderSort = "-created_at"
+
+// OrderSortColumns are the columns orders can be sorted by
+var OrderSortColumns = []string{"created_at", "updated_at", "total_amount", "status", "order_number"}
+
+// orderSort returns the filter's sort, checking that it is one the order queries
+// know. Ties are broken by the newest order first.
+func orderSort(filter OrderFilter) (string, error) {
+	sort := filter.Sort
+	if sort == "" {
+		sort = DefaultOrderSort
+	}
+	if filter.Cursor != "" && sort != DefaultOrderSort {
+		return "", fmt.Errorf("%w: cursor requires sort %s", ErrInvalidInput, DefaultOrderSort)
+	}
+
+	if !slices.Contains(OrderSortColumns, strings.TrimPrefix(sort, "-")) {
+		return "", fmt.Errorf("%w: invalid sort %q", ErrInvalidInput, filter.Sort)
+	}
+	return sort, nil
+}
+
+// validateOrderFilter checks that the filter's ranges are not empty
+func validateOrderFilter(filter OrderFilter) error {
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return fmt.Errorf("%w: created range is empty", ErrInvalidInput)
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return fmt.Errorf("%w: min amount is greater than max amount", ErrInvalidInput)
+	}
+	if filter.AssignedTo != nil && filter.Unassigned {
+		return fmt.Errorf("%w: assigned to and unassigned cannot be combined", ErrInvalidInput)
+	}
+	return nil
+}
+
+// orderFilterParams returns the parameters of the order queries for the tenant's
+// orders matching a filter. The sort, cursor and page are left to ListOrders.
+func orderFilterParams(tenantID int64, filter OrderFilter) orderdb.ListOrdersParams {
+	params := orderdb.ListOrdersParams{
+		TenantID:       tenantID,
+		IncludeDeleted: filter.IncludeDeleted,
+		Unassigned:     filter.Unassigned,
+	}
+
+	if filter.Status != "" {
+		params.Status = sql.NullString{String: filter.Status, Valid: true}
+	}
+	if filter.UserID != nil {
+		params.UserID = sql.NullInt64{Int64: *filter.UserID, Valid: true}
+	}
+	if filter.AssignedTo != nil {
+		params.AssignedTo = sql.NullInt64{Int64: *filter.AssignedTo, Valid: true}
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		params.Search = sql.NullString{String: "%" + escapeLikePattern(search) + "%", Valid: true}
+	}
+	if filter.CreatedFrom != nil {
+		params.CreatedFrom = sql.NullTime{Time: *filter.CreatedFrom, Valid: true}
+	}
+	if filter.CreatedTo != nil {
+		params.CreatedTo = sql.NullTime{Time: *filter.CreatedTo, Valid: true}
+	}
+	if filter.MinAmount != nil {
+		params.MinAmount = sql.NullFloat64{Float64: *filter.MinAmount, Valid: true}
+	}
+	if filter.MaxAmount != nil {
+		params.MaxAmount = sql.NullFloat64{Float64: *filter.MaxAmount, Valid: true}
+	}
+
+	// The queries match orders with all of the tags, given as a JSON array
+	if len(filter.Tags) > 0 {
+		tags := make([]string, len(filter.Tags))
+		for i, tag := range filter.Tags {
+			tags[i] = normalizeTag(tag)
+		}
+		params.Tags, _ = json.Marshal(tags)
+	}
+
+	return params
+}
+
+// orderCountParams returns the parameters of the count queries for the orders
+// listed with params
+func orderCountParams(params orderdb.ListOrdersParams) orderdb.CountOrdersParams {
+	return orderdb.CountOrdersParams{
+		TenantID:       params.TenantID,
+		IncludeDeleted: params.IncludeDeleted,
+		Status:         params.Status,
+		UserID:         params.UserID,
+		AssignedTo:     params.AssignedTo,
+		Unassigned:     params.Unassigned,
+		Search:         params.Search,
+		CreatedFrom:    params.CreatedFrom,
+		CreatedTo:      params.CreatedTo,
+		MinAmount:      params.MinAmount,
+		MaxAmount:      params.MaxAmount,
+		Tags:           params.Tags,
+	}
+}
+
+// orderFromRow converts an order read by the order queries
+func orderFromRow(row orderdb.Order) Order {
+	order := Order{
+		ID:             row.OrderID,
+		TenantID:       row.TenantID,
+		UserID:         row.UserID,
+		OrderNumber:    row.OrderNumber,
+		Status:         row.Status,
+		TotalAmount:    row.TotalAmount,
+		Notes:          row.Notes.String,
+		CreatedAt:      row.CreatedAt,
+		UpdatedAt:      row.UpdatedAt,
+		Version:        int(row.Version),
+		Subtotal:       row.Subtotal,
+		DiscountAmount: row.DiscountAmount,
+		TaxRate:        row.TaxRate,
+		TaxAmount:      row.TaxAmount,
+	}
+	if row.DeletedAt.Valid {
+		order.DeletedAt = &row.DeletedAt.Time
+	}
+	if row.AssignedTo.Valid {
+		order.AssignedTo = &row.AssignedTo.Int64
+	}
+	return order
+}
+
+// escapeLikePattern escapes the LIKE wildcards in a search term so it matches literally
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// OrderList is a page of orders with the total number of orders matching the filter
+type OrderList struct {
+	Items  []Order `json:"items"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+	// NextOffset is the offset of the next page, or nil on the last page or
+	// when paging by cursor
+	NextOffset *int `json:"next_offset"`
+	// NextCursor continues the list after this page. It is empty on the last page
+	// and for lists not in DefaultOrderSort order.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // OrderService defines the interface for order-related operations
 type OrderService interface {
-	// GetOrder retrieves an order by ID
+	// GetOrder retrieves an order by ID, with its items
 	GetOrder(ctx context.Context, orderID int64) (*Order, error)
 
 	// ListOrders retrieves orders for the current tenant with optional filters
 	ListOrders(ctx context.Context, filter OrderFilter) ([]Order, error)
 
+	// ListOrdersPage retrieves a page of orders for the current tenant with the
+	// total number of orders matching the filter
+	ListOrdersPage(ctx context.Context, filter OrderFilter) (*OrderList, error)
+
+	// ExportOrders passes every order matching the filter to write, newest first,
+	// in chunks of at most OrderExportChunkSize orders
+	ExportOrders(ctx context.Context, filter OrderFilter, write func([]Order) error) error
+
 	// ListUserOrders retrieves orders for a specific user in the current tenant
 	ListUserOrders(ctx context.Context, userID int64) ([]Order, error)
 
-	// CreateOrder creates a new order
+	// CreateOrder creates a new order with its items, numbering it if it has no
+	// order number
 	CreateOrder(ctx context.Context, order *Order) (*Order, error)
 
-	// UpdateOrder updates an existing order
+	// UpdateOrder updates an existing order at its expected version
 	UpdateOrder(ctx context.Context, order *Order) error
 
-	// DeleteOrder deletes an order
-	DeleteOrder(ctx context.Context, orderID int64) error
+	// PatchOrder updates the fields of an order set in the patch and returns
+	// the updated order
+	PatchOrder(ctx context.Context, orderID int64, patch OrderPatch) (*Order, error)
+
+	// AssignOrder assigns an order to a member of the current tenant, or
+	// unassigns it if userID is nil, and returns the order
+	AssignOrder(ctx context.Context, orderID int64, userID *int64) (*Order, error)
+
+	// DeleteOrder moves an order at the given version to the trash
+	DeleteOrder(ctx context.Context, orderID int64, version int) error
+
+	// RestoreOrder takes an order out of the trash and returns it
+	RestoreOrder(ctx context.Context, orderID int64) (*Order, error)
+
+	// PurgeDeletedOrders permanently deletes the orders of all tenants that were
+	// moved to the trash before the given time, returning how many were deleted
+	PurgeDeletedOrders(ctx context.Context, before time.Time) (int64, error)
+
+	// ArchiveOrders moves the orders of all tenants that haven't changed since
+	// the given time into the archive, returning how many were moved
+	ArchiveOrders(ctx context.Context, before time.Time) (int64, error)
 
 	// CountOrders counts orders for the current tenant with optional filters
 	CountOrders(ctx context.Context, filter OrderFilter) (int, error)
+
+	// GetOrderSummary counts the orders of the current tenant created in a date
+	// range and sums their revenue, by status and by day, week or month
+	GetOrderSummary(ctx context.Context, filter OrderSummaryFilter) (*OrderSummary, error)
+
+	// GetOrderHistory retrieves the status changes of an order, oldest first
+	GetOrderHistory(ctx context.Context, orderID int64) ([]OrderStatusChange, error)
+
+	// GetOrderSettings retrieves the order settings of the current tenant
+	GetOrderSettings(ctx context.Context) (*OrderSettings, error)
+
+	// UpdateOrderSettings updates the order settings of the current tenant
+	UpdateOrderSettings(ctx context.Context, settings *OrderSettings) error
 }
 
 // DBOrderService implements OrderService using a database
 type DBOrderService struct {
-	txManager *transaction.Manager
+	txManager transaction.TxManager
+	publisher events.Publisher
+	outbox    *events.Outbox
 }
 
 // NewDBOrderService creates a new DBOrderService
@@ -76,7 +395,14 @@ func NewDBOrderService(db *sql.DB) *DBOrderService {
 	}
 }
 
-// GetOrder retrieves an order by ID
+// WithTxManager runs the DBOrderService's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (s *DBOrderService) WithTxManager(txManager transaction.TxManager) *DBOrderService {
+	s.txManager = txManager
+	return s
+}
+
+// GetOrder retrieves an order by ID, with its items
 func (s *DBOrderService) GetOrder(ctx context.Context, orderID int64) (*Order, error) {
 	// Verify tenant context
 	tenantID, err := authctx.GetTenantID(ctx)
@@ -91,31 +417,20 @@ func (s *DBOrderService) GetOrder(ctx context.Context, orderID int64) (*Order, e
 	}
 
 	// Query with explicit tenant_id filter for additional security
-	query := `
-		SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at
-		FROM "order"
-		WHERE order_id = $1 AND tenant_id = $2
-	`
-
-	var order Order
-	err = tx.QueryRowContext(ctx, query, orderID, *tenantID).Scan(
-		&order.ID,
-		&order.TenantID,
-		&order.UserID,
-		&order.OrderNumber,
-		&order.Status,
-		&order.TotalAmount,
-		&order.Notes,
-		&order.CreatedAt,
-		&order.UpdatedAt,
-	)
-
+	row, err := orderdb.New(tx).GetOrder(ctx, orderdb.GetOrderParams{OrderID: orderID, TenantID: *tenantID})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrOrderNotFound
 		}
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
+	order := orderFromRow(row)
+
+	// Get the order's items
+	order.Items, err = s.listOrderItems(ctx, tx, order.ID, *tenantID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &order, nil
 }
@@ -128,86 +443,162 @@ func (s *DBOrderService) ListOrders(ctx context.Context, filter OrderFilter) ([]
 		return nil, ErrNoTenantContext
 	}
 
+	// Validate the filter and sort before querying
+	if err := validateOrderFilter(filter); err != nil {
+		return nil, err
+	}
+	sort, err := orderSort(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get transaction from context
 	tx, err := s.txManager.GetTx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	// Base query with explicit tenant_id filter
-	query := `
-		SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at
-		FROM "order"
-		WHERE tenant_id = $1
-	`
-
-	// Build query with additional filters
-	var args []interface{}
-	args = append(args, *tenantID)
-	argPos := 2
-
-	// Add status filter if provided
-	if filter.Status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argPos)
-		args = append(args, filter.Status)
-		argPos++
-	}
+	// Explicit tenant_id filter
+	params := orderFilterParams(*tenantID, filter)
+	params.Sort = sort
 
-	// Add user filter if provided
-	if filter.UserID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argPos)
-		args = append(args, *filter.UserID)
-		argPos++
+	// Continue after the cursor's order if provided
+	if filter.Cursor != "" {
+		if filter.Offset > 0 {
+			return nil, fmt.Errorf("%w: cursor and offset cannot be combined", ErrInvalidInput)
+		}
+		cursor, err := DecodeOrderCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		params.CursorCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		params.CursorID = sql.NullInt64{Int64: cursor.ID, Valid: true}
 	}
 
-	// Add order by
-	query += " ORDER BY created_at DESC"
-
 	// Add limit and offset
 	if filter.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argPos)
-		args = append(args, filter.Limit)
-		argPos++
-
-		if filter.Offset > 0 {
-			query += fmt.Sprintf(" OFFSET $%d", argPos)
-			args = append(args, filter.Offset)
-		}
+		params.RowLimit = sql.NullInt64{Int64: int64(filter.Limit), Valid: true}
+		params.RowOffset = int64(filter.Offset)
 	}
 
 	// Execute query
-	rows, err := tx.QueryContext(ctx, query, args...)
+	queries := orderdb.New(tx)
+	var rows []orderdb.Order
+	if filter.Archived {
+		var archived []orderdb.ListArchivedOrdersRow
+		archived, err = queries.ListArchivedOrders(ctx, orderdb.ListArchivedOrdersParams(params))
+		for _, row := range archived {
+			rows = append(rows, orderdb.Order(row))
+		}
+	} else {
+		rows, err = queries.ListOrders(ctx, params)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
-	defer rows.Close()
 
 	// Process results
 	var orders []Order
-	for rows.Next() {
-		var order Order
-		err := rows.Scan(
-			&order.ID,
-			&order.TenantID,
-			&order.UserID,
-			&order.OrderNumber,
-			&order.Status,
-			&order.TotalAmount,
-			&order.Notes,
-			&order.CreatedAt,
-			&order.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
-		}
-		orders = append(orders, order)
+	for _, row := range rows {
+		orders = append(orders, orderFromRow(row))
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	return orders, nil
+}
+
+// ListOrdersPage retrieves a page of orders for the current tenant with the total
+// number of orders matching the filter. Pages are selected by offset, or by the
+// cursor of the previous page. The limit defaults to DefaultOrderListLimit and is
+// capped at MaxOrderListLimit.
+func (s *DBOrderService) ListOrdersPage(ctx context.Context, filter OrderFilter) (*OrderList, error) {
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset cannot be negative", ErrInvalidInput)
+	}
+	if filter.Cursor != "" && filter.Offset > 0 {
+		return nil, fmt.Errorf("%w: cursor and offset cannot be combined", ErrInvalidInput)
+	}
+	if _, err := orderSort(filter); err != nil {
+		return nil, err
 	}
 
-	return orders, nil
+	total, err := s.CountOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultOrderListLimit
+	}
+	if filter.Limit > MaxOrderListLimit {
+		filter.Limit = MaxOrderListLimit
+	}
+
+	list := &OrderList{
+		Items:  []Order{},
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	// Skip the page query when the offset is past the last match
+	if filter.Cursor == "" && filter.Offset >= total {
+		return list, nil
+	}
+
+	// Fetch one more order than the limit to tell whether there is a next page
+	pageFilter := filter
+	pageFilter.Limit++
+	orders, err := s.ListOrders(ctx, pageFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(orders) > filter.Limit
+	if hasMore {
+		orders = orders[:filter.Limit]
+	}
+	list.Items = append(list.Items, orders...)
+
+	if hasMore {
+		if filter.Sort == "" || filter.Sort == DefaultOrderSort {
+			list.NextCursor = EncodeOrderCursor(orderCursorAfter(orders[len(orders)-1]))
+		}
+		if filter.Cursor == "" {
+			next := filter.Offset + len(orders)
+			list.NextOffset = &next
+		}
+	}
+
+	return list, nil
+}
+
+// ExportOrders passes every order matching the filter to write, newest first, in
+// chunks of at most OrderExportChunkSize orders. Chunks are read by cursor so
+// only one is held in memory at a time. The filter's sort and paging are ignored.
+func (s *DBOrderService) ExportOrders(ctx context.Context, filter OrderFilter, write func([]Order) error) error {
+	filter.Sort = ""
+	filter.Offset = 0
+	filter.Cursor = ""
+	filter.Limit = OrderExportChunkSize
+
+	for {
+		orders, err := s.ListOrders(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		if err := write(orders); err != nil {
+			return err
+		}
+
+		if len(orders) < OrderExportChunkSize {
+			return nil
+		}
+		filter.Cursor = EncodeOrderCursor(orderCursorAfter(orders[len(orders)-1]))
+	}
 }
 
 // ListUserOrders retrieves orders for a specific user in the current tenant
@@ -218,7 +609,9 @@ func (s *DBOrderService) ListUserOrders(ctx context.Context, userID int64) ([]Or
 	return s.ListOrders(ctx, filter)
 }
 
-// CreateOrder creates a new order
+// CreateOrder creates a new order with its items. Its subtotal, tax and total are
+// computed with applyTotals; a given total that doesn't match is rejected. An
+// order without an order number is given the tenant's next number.
 func (s *DBOrderService) CreateOrder(ctx context.Context, order *Order) (*Order, error) {
 	// Validate input
 	if order.TenantID <= 0 {
@@ -227,16 +620,26 @@ func (s *DBOrderService) CreateOrder(ctx context.Context, order *Order) (*Order,
 	if order.UserID <= 0 {
 		return nil, fmt.Errorf("%w: user ID is required", ErrInvalidInput)
 	}
-	if order.OrderNumber == "" {
-		return nil, fmt.Errorf("%w: order number is required", ErrInvalidInput)
-	}
 	if order.Status == "" {
 		// Set default status if not provided
 		order.Status = "pending"
 	}
+	for i, item := range order.Items {
+		if err := validateOrderItem(item); err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidInput, i+1, err)
+		}
+	}
 	if order.TotalAmount < 0 {
 		return nil, fmt.Errorf("%w: total amount cannot be negative", ErrInvalidInput)
 	}
+	var itemsSubtotal *float64
+	if len(order.Items) > 0 {
+		subtotal := orderItemsTotal(order.Items)
+		itemsSubtotal = &subtotal
+	}
+	if err := applyTotals(order, itemsSubtotal); err != nil {
+		return nil, err
+	}
 
 	// Ensure the tenant ID in the order matches the tenant ID in the context
 	tenantID, err := authctx.GetTenantID(ctx)
@@ -253,17 +656,45 @@ func (s *DBOrderService) CreateOrder(ctx context.Context, order *Order) (*Order,
 	order.CreatedAt = now
 	order.UpdatedAt = now
 
+	// Insert the order, retrying from a savepoint if it deadlocks with
+	// concurrent writes, e.g. on the tenant's order number sequence
+	numbered := order.OrderNumber != ""
+	err = s.txManager.WithTransactionRetry(ctx, writeAttempts, func(ctx context.Context) error {
+		return s.insertOrder(ctx, order, numbered, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.publishEvent(ctx, EventOrderCreated, order.TenantID, order.ID, *order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// insertOrder inserts an order with its items and status history in the
+// transaction in the context, numbering it unless numbered is set
+func (s *DBOrderService) insertOrder(ctx context.Context, order *Order, numbered bool, now time.Time) error {
 	// Get transaction from context
 	tx, err := s.txManager.GetTx(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Number the order unless the client gave it a number
+	if !numbered {
+		order.OrderNumber, err = s.nextOrderNumber(ctx, tx, order.TenantID, now)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Insert order
 	query := `
-		INSERT INTO "order" (tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING order_id
+		INSERT INTO "order" (tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at,
+			subtotal, discount_amount, tax_rate, tax_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING order_id, version
 	`
 
 	err = tx.QueryRowContext(
@@ -277,16 +708,51 @@ func (s *DBOrderService) CreateOrder(ctx context.Context, order *Order) (*Order,
 		order.Notes,
 		order.CreatedAt,
 		order.UpdatedAt,
-	).Scan(&order.ID)
+		order.Subtotal,
+		order.DiscountAmount,
+		order.TaxRate,
+		order.TaxAmount,
+	).Scan(&order.ID, &order.Version)
 
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return fmt.Errorf("%w: %w", ErrDBOperation, err)
 	}
 
-	return order, nil
+	// Insert the order's items
+	itemQuery := `
+		INSERT INTO order_item (order_id, tenant_id, sku, description, quantity, unit_price)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING item_id
+	`
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.OrderID = order.ID
+
+		err = tx.QueryRowContext(
+			ctx,
+			itemQuery,
+			item.OrderID,
+			order.TenantID,
+			item.SKU,
+			item.Description,
+			item.Quantity,
+			item.UnitPrice,
+		).Scan(&item.ID)
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDBOperation, err)
+		}
+	}
+
+	// Start the order's status history
+	return s.recordStatusChange(ctx, tx, order, nil)
 }
 
-// UpdateOrder updates an existing order
+// UpdateOrder updates an existing order, recording a status change in its
+// history. Its subtotal, tax and total are recomputed with applyTotals, the
+// subtotal of an order with items from its stored items. The update fails with
+// ErrConflict unless the order is at order.Version, which is incremented on success.
 func (s *DBOrderService) UpdateOrder(ctx context.Context, order *Order) error {
 	// Validate input
 	if order.ID <= 0 {
@@ -307,6 +773,9 @@ func (s *DBOrderService) UpdateOrder(ctx context.Context, order *Order) error {
 	if order.TotalAmount < 0 {
 		return fmt.Errorf("%w: total amount cannot be negative", ErrInvalidInput)
 	}
+	if order.Version <= 0 {
+		return fmt.Errorf("%w: version is required", ErrInvalidInput)
+	}
 
 	// Ensure the tenant ID in the order matches the tenant ID in the context
 	tenantID, err := authctx.GetTenantID(ctx)
@@ -321,16 +790,81 @@ func (s *DBOrderService) UpdateOrder(ctx context.Context, order *Order) error {
 	// Update timestamp
 	order.UpdatedAt = time.Now()
 
+	// Update the order, retrying from a savepoint if it deadlocks with
+	// concurrent writes to the order
+	var previousStatus string
+	err = s.txManager.WithTransactionRetry(ctx, writeAttempts, func(ctx context.Context) (err error) {
+		previousStatus, err = s.updateOrder(ctx, order)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	order.Version++
+
+	if err := s.publishEvent(ctx, EventOrderUpdated, order.TenantID, order.ID, *order); err != nil {
+		return err
+	}
+	if order.Status != previousStatus {
+		return s.publishEvent(ctx, EventOrderStatusChanged, order.TenantID, order.ID, OrderStatusChangedData{
+			OrderID:     order.ID,
+			OrderNumber: order.OrderNumber,
+			FromStatus:  previousStatus,
+			ToStatus:    order.Status,
+			Reason:      order.StatusReason,
+		})
+	}
+	return nil
+}
+
+// updateOrder locks an order at order.Version and updates it in the transaction
+// in the context, recording a status change in its history, and returns the
+// order's previous status. The order's version is left as it was, so the update
+// can be retried.
+func (s *DBOrderService) updateOrder(ctx context.Context, order *Order) (string, error) {
 	// Get transaction from context
 	tx, err := s.txManager.GetTx(ctx)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return "", fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	// Update order with explicit tenant_id filter
+	// Lock the order and get its current status, version and items' subtotal
+	var previousStatus string
+	var version int
+	var itemsSubtotal sql.NullFloat64
 	query := `
+		SELECT status, version, (SELECT SUM(quantity * unit_price) FROM order_item WHERE order_item.order_id = "order".order_id)
+		FROM "order"
+		WHERE order_id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	err = tx.QueryRowContext(ctx, query, order.ID, order.TenantID).
+		Scan(&previousStatus, &version, &itemsSubtotal)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrOrderNotFound
+		}
+		return "", fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+
+	if version != order.Version {
+		return "", fmt.Errorf("%w: order %d is at version %d, not %d", ErrConflict, order.ID, version, order.Version)
+	}
+
+	// Recompute the totals
+	var subtotal *float64
+	if itemsSubtotal.Valid {
+		subtotal = &itemsSubtotal.Float64
+	}
+	if err := applyTotals(order, subtotal); err != nil {
+		return "", err
+	}
+
+	// Update order with explicit tenant_id filter
+	query = `
 		UPDATE "order"
-		SET user_id = $1, order_number = $2, status = $3, total_amount = $4, notes = $5, updated_at = $6
+		SET user_id = $1, order_number = $2, status = $3, total_amount = $4, notes = $5, updated_at = $6, version = version + 1,
+			subtotal = $9, discount_amount = $10, tax_rate = $11, tax_amount = $12
 		WHERE order_id = $7 AND tenant_id = $8
 	`
 
@@ -345,27 +879,138 @@ func (s *DBOrderService) UpdateOrder(ctx context.Context, order *Order) error {
 		order.UpdatedAt,
 		order.ID,
 		order.TenantID,
+		order.Subtotal,
+		order.DiscountAmount,
+		order.TaxRate,
+		order.TaxAmount,
 	)
 
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return "", fmt.Errorf("%w: %w", ErrDBOperation, err)
 	}
 
 	// Check if the order was found
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return "", fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
 	if rowsAffected == 0 {
-		return ErrOrderNotFound
+		return "", ErrOrderNotFound
 	}
 
-	return nil
+	// Record the status change
+	if order.Status != previousStatus {
+		if err := s.recordStatusChange(ctx, tx, order, &previousStatus); err != nil {
+			return "", err
+		}
+	}
+
+	return previousStatus, nil
+}
+
+// PatchOrder updates the fields of an order set in the patch and returns the
+// updated order. The order is locked while the patch is applied, so concurrent
+// patches of different fields don't overwrite each other.
+func (s *DBOrderService) PatchOrder(ctx context.Context, orderID int64, patch OrderPatch) (*Order, error) {
+	if patch.Version == nil {
+		return nil, fmt.Errorf("%w: version is required", ErrInvalidInput)
+	}
+
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Lock the order and get its current fields
+	query := `
+		SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at,
+			subtotal, discount_amount, tax_rate, tax_amount, assigned_to
+		FROM "order"
+		WHERE order_id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var order Order
+	err = tx.QueryRowContext(ctx, query, orderID, *tenantID).Scan(
+		&order.ID,
+		&order.TenantID,
+		&order.UserID,
+		&order.OrderNumber,
+		&order.Status,
+		&order.TotalAmount,
+		&order.Notes,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.Version,
+		&order.DeletedAt,
+		&order.Subtotal,
+		&order.DiscountAmount,
+		&order.TaxRate,
+		&order.TaxAmount,
+		&order.AssignedTo,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Apply the patch
+	if patch.UserID != nil {
+		order.UserID = *patch.UserID
+	}
+	if patch.OrderNumber != nil {
+		order.OrderNumber = *patch.OrderNumber
+	}
+	if patch.Status != nil {
+		order.Status = *patch.Status
+	}
+	// Recompute the total unless the patch gives one to check. A total given
+	// without a subtotal prices orders without items by their total alone.
+	order.TotalAmount = 0
+	if patch.TotalAmount != nil {
+		order.TotalAmount = *patch.TotalAmount
+		order.Subtotal = 0
+	}
+	if patch.Subtotal != nil {
+		order.Subtotal = *patch.Subtotal
+	}
+	if patch.DiscountAmount != nil {
+		order.DiscountAmount = *patch.DiscountAmount
+	}
+	if patch.TaxRate != nil {
+		order.TaxRate = *patch.TaxRate
+	}
+	if patch.Notes != nil {
+		order.Notes = *patch.Notes
+	}
+	order.StatusReason = patch.StatusReason
+	order.Version = *patch.Version
+
+	if err := s.UpdateOrder(ctx, &order); err != nil {
+		return nil, err
+	}
+
+	// Return the order with its derived total and items
+	return s.GetOrder(ctx, orderID)
 }
 
-// DeleteOrder deletes an order
-func (s *DBOrderService) DeleteOrder(ctx context.Context, orderID int64) error {
+// DeleteOrder moves an order to the trash, failing with ErrConflict unless it is
+// at the given version. Orders in the trash can be restored until they are purged.
+func (s *DBOrderService) DeleteOrder(ctx context.Context, orderID int64, version int) error {
+	if version <= 0 {
+		return fmt.Errorf("%w: version is required", ErrInvalidInput)
+	}
+
 	// Verify tenant context
 	tenantID, err := authctx.GetTenantID(ctx)
 	if err != nil || tenantID == nil {
@@ -378,13 +1023,29 @@ func (s *DBOrderService) DeleteOrder(ctx context.Context, orderID int64) error {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	// Delete with explicit tenant_id filter
+	// Lock the order and check its version
+	var currentVersion int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM "order" WHERE order_id = $1 AND tenant_id = $2 AND deleted_at IS NULL FOR UPDATE`, orderID, *tenantID).
+		Scan(&currentVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if currentVersion != version {
+		return fmt.Errorf("%w: order %d is at version %d, not %d", ErrConflict, orderID, currentVersion, version)
+	}
+
+	// Move the order to the trash with explicit tenant_id filter
 	query := `
-		DELETE FROM "order"
-		WHERE order_id = $1 AND tenant_id = $2
+		UPDATE "order"
+		SET deleted_at = $1, updated_at = $1, version = version + 1
+		WHERE order_id = $2 AND tenant_id = $3
 	`
 
-	result, err := tx.ExecContext(ctx, query, orderID, *tenantID)
+	result, err := tx.ExecContext(ctx, query, time.Now(), orderID, *tenantID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
@@ -399,6 +1060,136 @@ func (s *DBOrderService) DeleteOrder(ctx context.Context, orderID int64) error {
 		return ErrOrderNotFound
 	}
 
+	return s.publishEvent(ctx, EventOrderDeleted, *tenantID, orderID, OrderDeletedData{OrderID: orderID, Version: version + 1})
+}
+
+// RestoreOrder takes an order out of the trash and returns it
+func (s *DBOrderService) RestoreOrder(ctx context.Context, orderID int64) (*Order, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Restore with explicit tenant_id filter
+	query := `
+		UPDATE "order"
+		SET deleted_at = NULL, updated_at = $1, version = version + 1
+		WHERE order_id = $2 AND tenant_id = $3 AND deleted_at IS NOT NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, time.Now(), orderID, *tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Check if a deleted order was found
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, ErrOrderNotFound
+	}
+
+	order, err := s.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.publishEvent(ctx, EventOrderRestored, order.TenantID, order.ID, *order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// PurgeDeletedOrders permanently deletes the orders of all tenants that were
+// moved to the trash before the given time, with their items and history. It
+// runs in its own transaction, outside any tenant context.
+func (s *DBOrderService) PurgeDeletedOrders(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.txManager.GetDB().ExecContext(ctx, `DELETE FROM "order" WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return purged, nil
+}
+
+// ArchiveOrders moves the orders of all tenants that haven't changed since the given
+// time into order_archive, in batches of OrderArchiveBatchSize, returning how many
+// were moved. Each batch is copied and deleted in one statement; the items, status
+// history, comments and tags deleted with an order are kept in its details. Orders
+// in the trash are left to the purge job, and orders with attachments stay so
+// their files can still be downloaded.
+func (s *DBOrderService) ArchiveOrders(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		WITH archived AS (
+			DELETE FROM "order"
+			WHERE order_id IN (
+				SELECT o.order_id FROM "order" o
+				WHERE o.updated_at < $1 AND o.deleted_at IS NULL
+					AND NOT EXISTS (SELECT 1 FROM order_attachment a WHERE a.order_id = o.order_id)
+				ORDER BY o.updated_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		INSERT INTO order_archive (order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version,
+			subtotal, discount_amount, tax_rate, tax_amount, assigned_to, details)
+		SELECT a.order_id, a.tenant_id, a.user_id, a.order_number, a.status, a.total_amount, a.notes, a.created_at, a.updated_at, a.version,
+			a.subtotal, a.discount_amount, a.tax_rate, a.tax_amount, a.assigned_to,
+			jsonb_build_object(
+				'items', COALESCE((SELECT jsonb_agg(to_jsonb(i) - 'tenant_id' ORDER BY i.item_id) FROM order_item i WHERE i.order_id = a.order_id), '[]'),
+				'history', COALESCE((SELECT jsonb_agg(to_jsonb(h) - 'tenant_id' ORDER BY h.history_id) FROM order_status_history h WHERE h.order_id = a.order_id), '[]'),
+				'comments', COALESCE((SELECT jsonb_agg(to_jsonb(c) - 'tenant_id' ORDER BY c.comment_id) FROM order_comment c WHERE c.order_id = a.order_id), '[]'),
+				'tags', COALESCE((SELECT jsonb_agg(t.tag ORDER BY t.tag) FROM order_tag t WHERE t.order_id = a.order_id), '[]')
+			)
+		FROM archived a`
+
+	var archived int64
+	for {
+		result, err := s.txManager.GetDB().ExecContext(ctx, query, before, OrderArchiveBatchSize)
+		if err != nil {
+			return archived, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return archived, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		archived += moved
+		if moved < OrderArchiveBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// checkOrderExists verifies that an order of the tenant exists and isn't deleted,
+// for records that belong to an order
+func checkOrderExists(ctx context.Context, tx *sql.Tx, tenantID int64, orderID int64) error {
+	var exists int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM "order" WHERE order_id = $1 AND tenant_id = $2 AND deleted_at IS NULL`, orderID, tenantID).
+		Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
 	return nil
 }
 
@@ -410,43 +1201,173 @@ func (s *DBOrderService) CountOrders(ctx context.Context, filter OrderFilter) (i
 		return 0, ErrNoTenantContext
 	}
 
+	if err := validateOrderFilter(filter); err != nil {
+		return 0, err
+	}
+
 	// Get transaction from context
 	tx, err := s.txManager.GetTx(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	// Base query with explicit tenant_id filter
+	// Explicit tenant_id filter
+	params := orderCountParams(orderFilterParams(*tenantID, filter))
+
+	// Execute query
+	var count int64
+	queries := orderdb.New(tx)
+	if filter.Archived {
+		count, err = queries.CountArchivedOrders(ctx, orderdb.CountArchivedOrdersParams(params))
+	} else {
+		count, err = queries.CountOrders(ctx, params)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return int(count), nil
+}
+
+// GetOrderHistory retrieves the status changes of an order, oldest first
+func (s *DBOrderService) GetOrderHistory(ctx context.Context, orderID int64) ([]OrderStatusChange, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Query with explicit tenant_id filter for additional security
 	query := `
-		SELECT COUNT(*)
-		FROM "order"
-		WHERE tenant_id = $1
+		SELECT history_id, order_id, from_status, to_status, changed_by, reason, changed_at
+		FROM order_status_history
+		WHERE order_id = $1 AND tenant_id = $2
+		ORDER BY changed_at, history_id
 	`
 
-	// Build query with additional filters
-	var args []interface{}
-	args = append(args, *tenantID)
-	argPos := 2
+	rows, err := tx.QueryContext(ctx, query, orderID, *tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
 
-	// Add status filter if provided
-	if filter.Status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argPos)
-		args = append(args, filter.Status)
-		argPos++
+	history := []OrderStatusChange{}
+	for rows.Next() {
+		var change OrderStatusChange
+		var fromStatus sql.NullString
+		var changedBy sql.NullInt64
+		err := rows.Scan(
+			&change.ID,
+			&change.OrderID,
+			&fromStatus,
+			&change.ToStatus,
+			&changedBy,
+			&change.Reason,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if fromStatus.Valid {
+			change.FromStatus = &fromStatus.String
+		}
+		if changedBy.Valid {
+			change.ChangedBy = &changedBy.Int64
+		}
+		history = append(history, change)
 	}
 
-	// Add user filter if provided
-	if filter.UserID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argPos)
-		args = append(args, *filter.UserID)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	// Execute query
-	var count int
-	err = tx.QueryRowContext(ctx, query, args...).Scan(&count)
+	// Tell an order without history, such as a cloned one, from a missing order
+	if len(history) == 0 {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM "order" WHERE order_id = $1 AND tenant_id = $2)`, orderID, *tenantID).
+			Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if !exists {
+			return nil, ErrOrderNotFound
+		}
+	}
+
+	return history, nil
+}
+
+// recordStatusChange adds an order's current status to its history, made by the
+// user in the context
+func (s *DBOrderService) recordStatusChange(ctx context.Context, tx *sql.Tx, order *Order, fromStatus *string) error {
+	var changedBy *int64
+	if userID, err := authctx.GetUserID(ctx); err == nil {
+		changedBy = &userID
+	}
+
+	query := `
+		INSERT INTO order_status_history (order_id, tenant_id, from_status, to_status, changed_by, reason, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := tx.ExecContext(ctx, query, order.ID, order.TenantID, fromStatus, order.Status, changedBy, order.StatusReason, order.UpdatedAt)
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return fmt.Errorf("%w: %w", ErrDBOperation, err)
+	}
+	return nil
+}
+
+// listOrderItems retrieves an order's items in the order they were added
+func (s *DBOrderService) listOrderItems(ctx context.Context, tx *sql.Tx, orderID int64, tenantID int64) ([]OrderItem, error) {
+	rows, err := orderdb.New(tx).ListOrderItems(ctx, orderdb.ListOrderItemsParams{OrderID: orderID, TenantID: tenantID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	items := []OrderItem{}
+	for _, row := range rows {
+		items = append(items, OrderItem{
+			ID:          row.ItemID,
+			OrderID:     row.OrderID,
+			SKU:         row.Sku,
+			Description: row.Description,
+			Quantity:    int(row.Quantity),
+			UnitPrice:   row.UnitPrice,
+		})
 	}
 
-	return count, nil
+	return items, nil
+}
+
+// validateOrderItem checks the fields of an item of a new order
+func validateOrderItem(item OrderItem) error {
+	if item.SKU == "" {
+		return errors.New("SKU is required")
+	}
+	if len(item.SKU) > 64 {
+		return errors.New("SKU must be at most 64 characters")
+	}
+	if item.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	if item.UnitPrice < 0 {
+		return errors.New("unit price cannot be negative")
+	}
+	return nil
+}
+
+// orderItemsTotal sums the amounts of an order's items, rounded to cents like
+// the stored total
+func orderItemsTotal(items []OrderItem) float64 {
+	var total float64
+	for _, item := range items {
+		total += item.Amount()
+	}
+	return roundAmount(total)
 }
@@ -3,14 +3,23 @@ package service
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database"
 	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/events"
+	"github.com/unsavory/silocore-go/internal/order/orderdb"
 )
 
 func setupMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *DBOrderService) {
@@ -27,14 +36,34 @@ func createContextWithTenant(tenantID int64) context.Context {
 	return authctx.WithTenantID(ctx, &tenantID)
 }
 
-// setupTransaction sets up a transaction in the context
-func setupTransaction(ctx context.Context, mock sqlmock.Sqlmock) context.Context {
-	// We don't need to create a real transaction, just mock the expectations
-	// The actual transaction will be created by the service when it calls Begin
-	mockTx := mock.ExpectBegin()
+// setupTransaction begins a transaction and adds it to the context, as the
+// transaction middleware does for requests
+func setupTransaction(t *testing.T, ctx context.Context, db *sql.DB, mock sqlmock.Sqlmock) context.Context {
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	return context.WithValue(ctx, transaction.TxKey, tx)
+}
 
-	// Use the mock transaction directly
-	return context.WithValue(ctx, transaction.TxKey, mockTx)
+// expectSavepoint expects a write to run in a retry savepoint, returning a
+// function that expects the savepoint to be released
+func expectSavepoint(mock sqlmock.Sqlmock) func() {
+	mock.ExpectExec("SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+	return func() {
+		mock.ExpectExec("RELEASE SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
+// queryArgs returns the arguments a generated query is run with for params,
+// which it passes in the order of their fields
+func queryArgs(params any) []driver.Value {
+	v := reflect.ValueOf(params)
+	args := make([]driver.Value, v.NumField())
+	for i := range args {
+		args[i] = v.Field(i).Interface()
+	}
+	return args
 }
 
 func TestGetOrder(t *testing.T) {
@@ -50,29 +79,21 @@ func TestGetOrder(t *testing.T) {
 	// Create context with tenant
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
 	// Expect query for order
 	mock.ExpectQuery("SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at").
 		WithArgs(orderID, tenantID).
-		WillReturnRows(sqlmock.NewRows([]string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at"}).
-			AddRow(orderID, tenantID, userID, "ORD-001", "pending", 100.50, "Test order", now, now))
-
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect commit
-	mock.ExpectCommit()
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}).
+			AddRow(orderID, tenantID, userID, "ORD-001", "pending", 100.50, "Test order", now, now, 1, nil, 100.50, 0, 0, 0, nil))
 
-	// Setup transaction in context
-	ctx = setupTransaction(ctx, mock)
+	// Expect query for order items
+	mock.ExpectQuery("SELECT item_id, order_id, sku, description, quantity, unit_price FROM order_item").
+		WithArgs(orderID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "order_id", "sku", "description", "quantity", "unit_price"}).
+			AddRow(1, orderID, "SKU-1", "Widget", 2, 25.25).
+			AddRow(2, orderID, "SKU-2", "Gadget", 1, 50.00))
 
 	// Execute test
 	order, err := service.GetOrder(ctx, orderID)
@@ -87,6 +108,9 @@ func TestGetOrder(t *testing.T) {
 	assert.Equal(t, "pending", order.Status)
 	assert.Equal(t, 100.50, order.TotalAmount)
 	assert.Equal(t, "Test order", order.Notes)
+	require.Len(t, order.Items, 2)
+	assert.Equal(t, OrderItem{ID: 1, OrderID: orderID, SKU: "SKU-1", Description: "Widget", Quantity: 2, UnitPrice: 25.25}, order.Items[0])
+	assert.Equal(t, "SKU-2", order.Items[1].SKU)
 
 	// Verify all expectations were met
 	err = mock.ExpectationsWereMet()
@@ -104,29 +128,14 @@ func TestGetOrderNotFound(t *testing.T) {
 	// Create context with tenant
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
 	// Expect query for order (not found)
 	mock.ExpectQuery("SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at").
 		WithArgs(orderID, tenantID).
 		WillReturnError(sql.ErrNoRows)
 
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect rollback (since we're returning an error)
-	mock.ExpectRollback()
-
-	// Setup transaction in context
-	ctx = setupTransaction(ctx, mock)
-
 	// Execute test
 	order, err := service.GetOrder(ctx, orderID)
 
@@ -151,30 +160,15 @@ func TestListOrders(t *testing.T) {
 	// Create context with tenant
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
 	// Expect query for orders
 	mock.ExpectQuery("SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at").
-		WithArgs(tenantID).
-		WillReturnRows(sqlmock.NewRows([]string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at"}).
-			AddRow(1, tenantID, 100, "ORD-001", "pending", 100.50, "Test order 1", now, now).
-			AddRow(2, tenantID, 101, "ORD-002", "completed", 200.75, "Test order 2", now, now))
-
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect commit
-	mock.ExpectCommit()
-
-	// Setup transaction in context
-	ctx = setupTransaction(ctx, mock)
+		WithArgs(queryArgs(orderdb.ListOrdersParams{TenantID: tenantID, Sort: DefaultOrderSort})...).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}).
+			AddRow(1, tenantID, 100, "ORD-001", "pending", 100.50, "Test order 1", now, now, 1, nil, 100.50, 0, 0, 0, nil).
+			AddRow(2, tenantID, 101, "ORD-002", "completed", 200.75, "Test order 2", now, now, 1, nil, 200.75, 0, 0, 0, nil))
 
 	// Execute test
 	orders, err := service.ListOrders(ctx, OrderFilter{})
@@ -203,32 +197,25 @@ func TestListOrdersWithFilters(t *testing.T) {
 	// Create context with tenant ID
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
 	// Setup expectations for query
 	rows := sqlmock.NewRows([]string{
-		"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at",
+		"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to",
 	}).AddRow(
-		1, tenantID, userID, "ORD-001", status, 100.50, "Test order", now, now,
+		1, tenantID, userID, "ORD-001", status, 100.50, "Test order", now, now, 1, nil, 100.50, 0, 0, 0, nil,
 	)
 
-	mock.ExpectQuery(`SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at FROM "order" WHERE tenant_id = \$1 AND status = \$2 AND user_id = \$3 ORDER BY created_at DESC`).
-		WithArgs(tenantID, status, userID).
+	mock.ExpectQuery(`SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at, subtotal, discount_amount, tax_rate, tax_amount, assigned_to FROM "order" WHERE "order".tenant_id = \$1`).
+		WithArgs(queryArgs(orderdb.ListOrdersParams{
+			TenantID: tenantID,
+			Status:   sql.NullString{String: status, Valid: true},
+			UserID:   sql.NullInt64{Int64: userID, Valid: true},
+			Sort:     DefaultOrderSort,
+		})...).
 		WillReturnRows(rows)
 
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect commit
-	mock.ExpectCommit()
-
 	// Execute test
 	filter := OrderFilter{
 		Status: status,
@@ -247,6 +234,438 @@ func TestListOrdersWithFilters(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestOrderFilterConditions(t *testing.T) {
+	tenantID := int64(42)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	minAmount, maxAmount := 100.0, 500.0
+
+	t.Run("Count in ranges", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect the count with the date and amount conditions
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "order"`).
+			WithArgs(queryArgs(orderdb.CountOrdersParams{
+				TenantID:    tenantID,
+				CreatedFrom: sql.NullTime{Time: from, Valid: true},
+				CreatedTo:   sql.NullTime{Time: to, Valid: true},
+				MinAmount:   sql.NullFloat64{Float64: minAmount, Valid: true},
+				MaxAmount:   sql.NullFloat64{Float64: maxAmount, Valid: true},
+			})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+		// Execute test
+		count, err := service.CountOrders(ctx, OrderFilter{CreatedFrom: &from, CreatedTo: &to, MinAmount: &minAmount, MaxAmount: &maxAmount})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, 7, count)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect the search term with its wildcards escaped
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "order"`).
+			WithArgs(queryArgs(orderdb.CountOrdersParams{
+				TenantID: tenantID,
+				Search:   sql.NullString{String: `%50\%\_off%`, Valid: true},
+			})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		// Execute test
+		count, err := service.CountOrders(ctx, OrderFilter{Search: " 50%_off "})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect orders with every tag, normalized
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "order"`).
+			WithArgs(queryArgs(orderdb.CountOrdersParams{
+				TenantID: tenantID,
+				Tags:     json.RawMessage(`["vip","rush order"]`),
+			})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		// Execute test
+		count, err := service.CountOrders(ctx, OrderFilter{Tags: []string{"VIP", " Rush  Order"}})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Assignee", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		assignee := int64(7)
+
+		// Expect the orders assigned to the user, and the unassigned orders
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "order"`).
+			WithArgs(queryArgs(orderdb.CountOrdersParams{
+				TenantID:   tenantID,
+				AssignedTo: sql.NullInt64{Int64: assignee, Valid: true},
+			})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "order"`).
+			WithArgs(queryArgs(orderdb.CountOrdersParams{TenantID: tenantID, Unassigned: true})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(9))
+
+		// Execute test
+		assigned, err := service.CountOrders(ctx, OrderFilter{AssignedTo: &assignee})
+		require.NoError(t, err)
+		unassigned, err := service.CountOrders(ctx, OrderFilter{Unassigned: true})
+		require.NoError(t, err)
+		_, err = service.CountOrders(ctx, OrderFilter{AssignedTo: &assignee, Unassigned: true})
+
+		// Verify results
+		assert.Equal(t, 4, assigned)
+		assert.Equal(t, 9, unassigned)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Archived", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect the archive to be counted, with tags from the archived details
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM order_archive`).
+			WithArgs(queryArgs(orderdb.CountArchivedOrdersParams{
+				TenantID: tenantID,
+				Tags:     json.RawMessage(`["vip"]`),
+			})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		// Execute test
+		count, err := service.CountOrders(ctx, OrderFilter{Archived: true, Tags: []string{"VIP"}})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty created range", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		// Execute test
+		orders, err := service.ListOrders(createContextWithTenant(tenantID), OrderFilter{CreatedFrom: &to, CreatedTo: &from})
+
+		// Verify results
+		assert.Nil(t, orders)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("Empty amount range", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		// Execute test
+		_, err := service.CountOrders(createContextWithTenant(tenantID), OrderFilter{MinAmount: &maxAmount, MaxAmount: &minAmount})
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestListOrdersSorted(t *testing.T) {
+	orderColumns := []string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}
+	tenantID := int64(42)
+	now := time.Now()
+
+	tests := []struct {
+		sort  string
+		param string
+	}{
+		{sort: "", param: DefaultOrderSort},
+		{sort: "total_amount", param: "total_amount"},
+		{sort: "-updated_at", param: "-updated_at"},
+		{sort: "order_number", param: "order_number"},
+	}
+
+	for _, tt := range tests {
+		t.Run("Sort "+tt.sort, func(t *testing.T) {
+			db, mock, service := setupMock(t)
+			defer db.Close()
+
+			ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+			// Expect the query ordered by the sort column
+			mock.ExpectQuery("SELECT order_id, (.+) ORDER BY").
+				WithArgs(queryArgs(orderdb.ListOrdersParams{TenantID: tenantID, Sort: tt.param})...).
+				WillReturnRows(sqlmock.NewRows(orderColumns).
+					AddRow(1, tenantID, 100, "ORD-001", "pending", 100.50, "", now, now, 1, nil, 100.50, 0, 0, 0, nil))
+
+			// Execute test
+			orders, err := service.ListOrders(ctx, OrderFilter{Sort: tt.sort})
+
+			// Verify results
+			require.NoError(t, err)
+			assert.Len(t, orders, 1)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		for _, sort := range []string{"notes", "created_at; DROP TABLE usr", "--created_at"} {
+			// Execute test
+			orders, err := service.ListOrders(createContextWithTenant(tenantID), OrderFilter{Sort: sort})
+
+			// Verify results
+			assert.Nil(t, orders)
+			assert.ErrorIs(t, err, ErrInvalidInput, sort)
+		}
+	})
+
+	t.Run("Cursor with another sort", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		// Execute test
+		cursor := EncodeOrderCursor(OrderCursor{CreatedAt: now, ID: 3})
+		list, err := service.ListOrdersPage(createContextWithTenant(tenantID), OrderFilter{Cursor: cursor, Sort: "total_amount"})
+
+		// Verify results
+		assert.Nil(t, list)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestListOrdersPage(t *testing.T) {
+	orderColumns := []string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}
+	tenantID := int64(42)
+	now := time.Now()
+
+	t.Run("First page", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect count query, then the page with the given limit
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM \"order\" WHERE \"order\".tenant_id = \\$1").
+			WithArgs(queryArgs(orderdb.CountOrdersParams{TenantID: tenantID})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery("SELECT order_id, (.+) ORDER BY").
+			WithArgs(queryArgs(orderdb.ListOrdersParams{
+				TenantID: tenantID,
+				Sort:     DefaultOrderSort,
+				RowLimit: sql.NullInt64{Int64: 3, Valid: true},
+			})...).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(3, tenantID, 100, "ORD-003", "pending", 10.0, "", now, now, 1, nil, 10.0, 0, 0, 0, nil).
+				AddRow(2, tenantID, 100, "ORD-002", "pending", 20.0, "", now, now, 1, nil, 20.0, 0, 0, 0, nil).
+				AddRow(1, tenantID, 100, "ORD-001", "pending", 30.0, "", now, now, 1, nil, 30.0, 0, 0, 0, nil))
+
+		// Execute test
+		list, err := service.ListOrdersPage(ctx, OrderFilter{Limit: 2})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Len(t, list.Items, 2)
+		assert.Equal(t, 3, list.Total)
+		assert.Equal(t, 2, list.Limit)
+		assert.Equal(t, 0, list.Offset)
+		require.NotNil(t, list.NextOffset)
+		assert.Equal(t, 2, *list.NextOffset)
+		assert.Equal(t, EncodeOrderCursor(OrderCursor{CreatedAt: now, ID: 2}), list.NextCursor)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Last page with default limit", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect count query, then the page with the default limit
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\)").
+			WithArgs(queryArgs(orderdb.CountOrdersParams{TenantID: tenantID})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery("SELECT order_id, (.+) LIMIT \\$17::integer OFFSET \\$16::integer").
+			WithArgs(queryArgs(orderdb.ListOrdersParams{
+				TenantID:  tenantID,
+				Sort:      DefaultOrderSort,
+				RowLimit:  sql.NullInt64{Int64: DefaultOrderListLimit + 1, Valid: true},
+				RowOffset: 2,
+			})...).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(1, tenantID, 100, "ORD-001", "pending", 30.0, "", now, now, 1, nil, 30.0, 0, 0, 0, nil))
+
+		// Execute test
+		list, err := service.ListOrdersPage(ctx, OrderFilter{Offset: 2})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Len(t, list.Items, 1)
+		assert.Equal(t, DefaultOrderListLimit, list.Limit)
+		assert.Nil(t, list.NextOffset)
+		assert.Empty(t, list.NextCursor)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Offset past the last order", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect only the count query
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\)").
+			WithArgs(queryArgs(orderdb.CountOrdersParams{TenantID: tenantID})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		// Execute test
+		list, err := service.ListOrdersPage(ctx, OrderFilter{Limit: 1000, Offset: 10})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Empty(t, list.Items)
+		assert.Equal(t, MaxOrderListLimit, list.Limit)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Page after cursor", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		cursorTime := now.Add(-time.Hour).UTC()
+		cursor := EncodeOrderCursor(OrderCursor{CreatedAt: cursorTime, ID: 3})
+
+		// Expect count query, then the page after the cursor's order
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\)").
+			WithArgs(queryArgs(orderdb.CountOrdersParams{TenantID: tenantID})...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery("SELECT order_id, (.+) \\(created_at, order_id\\) < \\(\\$13, \\$14::integer\\)").
+			WithArgs(queryArgs(orderdb.ListOrdersParams{
+				TenantID:        tenantID,
+				CursorCreatedAt: sql.NullTime{Time: cursorTime, Valid: true},
+				CursorID:        sql.NullInt64{Int64: 3, Valid: true},
+				Sort:            DefaultOrderSort,
+				RowLimit:        sql.NullInt64{Int64: 2, Valid: true},
+			})...).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(2, tenantID, 100, "ORD-002", "pending", 20.0, "", now, now, 1, nil, 20.0, 0, 0, 0, nil).
+				AddRow(1, tenantID, 100, "ORD-001", "pending", 30.0, "", now, now, 1, nil, 30.0, 0, 0, 0, nil))
+
+		// Execute test
+		list, err := service.ListOrdersPage(ctx, OrderFilter{Limit: 1, Cursor: cursor})
+
+		// Verify results
+		require.NoError(t, err)
+		require.Len(t, list.Items, 1)
+		assert.Equal(t, int64(2), list.Items[0].ID)
+		assert.Nil(t, list.NextOffset)
+		assert.Equal(t, EncodeOrderCursor(OrderCursor{CreatedAt: now, ID: 2}), list.NextCursor)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cursor with offset", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		// Execute test
+		cursor := EncodeOrderCursor(OrderCursor{CreatedAt: now, ID: 3})
+		list, err := service.ListOrdersPage(createContextWithTenant(tenantID), OrderFilter{Offset: 2, Cursor: cursor})
+
+		// Verify results
+		assert.Nil(t, list)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("Negative offset", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		// Execute test
+		list, err := service.ListOrdersPage(createContextWithTenant(tenantID), OrderFilter{Offset: -1})
+
+		// Verify results
+		assert.Nil(t, list)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestOrderCursor(t *testing.T) {
+	cursor := OrderCursor{CreatedAt: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), ID: 42}
+
+	t.Run("Round trip", func(t *testing.T) {
+		decoded, err := DecodeOrderCursor(EncodeOrderCursor(cursor))
+		require.NoError(t, err)
+		assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+		assert.Equal(t, cursor.ID, decoded.ID)
+	})
+
+	t.Run("Invalid tokens", func(t *testing.T) {
+		for _, token := range []string{"not a cursor", "e30", EncodeOrderCursor(OrderCursor{ID: 42})} {
+			decoded, err := DecodeOrderCursor(token)
+			assert.Nil(t, decoded)
+			assert.ErrorIs(t, err, ErrInvalidInput, token)
+		}
+	})
+}
+
+func TestExportOrders(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	now := time.Now()
+	ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+	// Expect a single chunk in the default order, ignoring the sort and offset
+	mock.ExpectQuery("SELECT order_id, (.+) FROM \"order\"").
+		WithArgs(queryArgs(orderdb.ListOrdersParams{
+			TenantID: tenantID,
+			Status:   sql.NullString{String: "completed", Valid: true},
+			Sort:     DefaultOrderSort,
+			RowLimit: sql.NullInt64{Int64: OrderExportChunkSize, Valid: true},
+		})...).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}).
+			AddRow(2, tenantID, 100, "ORD-002", "completed", 20.0, "", now, now, 1, nil, 20.0, 0, 0, 0, nil).
+			AddRow(1, tenantID, 100, "ORD-001", "completed", 10.0, "", now, now, 1, nil, 10.0, 0, 0, 0, nil))
+
+	// Execute test
+	var exported []Order
+	err := service.ExportOrders(ctx, OrderFilter{Status: "completed", Sort: "total_amount", Offset: 10}, func(orders []Order) error {
+		exported = append(exported, orders...)
+		return nil
+	})
+
+	// Verify results
+	require.NoError(t, err)
+	require.Len(t, exported, 2)
+	assert.Equal(t, "ORD-002", exported[0].OrderNumber)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestListUserOrders(t *testing.T) {
 	db, mock, service := setupMock(t)
 	defer db.Close()
@@ -259,32 +678,24 @@ func TestListUserOrders(t *testing.T) {
 	// Create context with tenant ID
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
 	// Setup expectations for query
 	rows := sqlmock.NewRows([]string{
-		"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at",
+		"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to",
 	}).AddRow(
-		1, tenantID, userID, "ORD-001", "pending", 100.50, "Test order", now, now,
+		1, tenantID, userID, "ORD-001", "pending", 100.50, "Test order", now, now, 1, nil, 100.50, 0, 0, 0, nil,
 	)
 
-	mock.ExpectQuery(`SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at FROM "order" WHERE tenant_id = \$1 AND user_id = \$2 ORDER BY created_at DESC`).
-		WithArgs(tenantID, userID).
+	mock.ExpectQuery(`SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at, subtotal, discount_amount, tax_rate, tax_amount, assigned_to FROM "order" WHERE "order".tenant_id = \$1`).
+		WithArgs(queryArgs(orderdb.ListOrdersParams{
+			TenantID: tenantID,
+			UserID:   sql.NullInt64{Int64: userID, Valid: true},
+			Sort:     DefaultOrderSort,
+		})...).
 		WillReturnRows(rows)
 
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect commit
-	mock.ExpectCommit()
-
 	// Execute test
 	result, err := service.ListUserOrders(ctx, userID)
 
@@ -320,13 +731,9 @@ func TestCreateOrder(t *testing.T) {
 	// Create context with tenant
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
+	expectRelease := expectSavepoint(mock)
 
 	// Expect insert query
 	mock.ExpectQuery("INSERT INTO \"order\"").
@@ -337,20 +744,20 @@ func TestCreateOrder(t *testing.T) {
 			order.Status,
 			order.TotalAmount,
 			order.Notes,
-			order.CreatedAt,
-			order.UpdatedAt,
+			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
+			order.TotalAmount,
+			0.0,
+			0.0,
+			0.0,
 		).
-		WillReturnRows(sqlmock.NewRows([]string{"order_id"}).AddRow(1))
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "version"}).AddRow(1, 1))
 
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
+	// Expect the first status history entry
+	mock.ExpectExec("INSERT INTO order_status_history").
+		WithArgs(int64(1), tenantID, nil, "pending", nil, "", sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect commit
-	mock.ExpectCommit()
-
-	// Setup transaction in context
-	ctx = setupTransaction(ctx, mock)
+	expectRelease()
 
 	// Execute test
 	createdOrder, err := service.CreateOrder(ctx, order)
@@ -368,6 +775,168 @@ func TestCreateOrder(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCreateOrderWithItems(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	// Test data
+	tenantID := int64(42)
+	order := &Order{
+		TenantID:    tenantID,
+		UserID:      100,
+		OrderNumber: "ORD-004",
+		// Tax is charged on the discounted subtotal
+		DiscountAmount: 0.55,
+		TaxRate:        10,
+		Items: []OrderItem{
+			{SKU: "SKU-1", Description: "Widget", Quantity: 3, UnitPrice: 10.10},
+			{SKU: "SKU-2", Quantity: 1, UnitPrice: 0.25},
+		},
+	}
+
+	// Create context with tenant
+	ctx := createContextWithTenant(tenantID)
+
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
+	expectRelease := expectSavepoint(mock)
+
+	// Expect insert query with the derived totals
+	mock.ExpectQuery("INSERT INTO \"order\"").
+		WithArgs(tenantID, int64(100), "ORD-004", "pending", 33.0, "", sqlmock.AnyArg(), sqlmock.AnyArg(), 30.55, 0.55, 10.0, 3.0).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "version"}).AddRow(9, 1))
+
+	// Expect an insert per item
+	mock.ExpectQuery("INSERT INTO order_item").
+		WithArgs(int64(9), tenantID, "SKU-1", "Widget", 3, 10.10).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id"}).AddRow(21))
+	mock.ExpectQuery("INSERT INTO order_item").
+		WithArgs(int64(9), tenantID, "SKU-2", "", 1, 0.25).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id"}).AddRow(22))
+	mock.ExpectExec("INSERT INTO order_status_history").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRelease()
+
+	// Execute test
+	createdOrder, err := service.CreateOrder(ctx, order)
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, 30.55, createdOrder.Subtotal)
+	assert.Equal(t, 3.0, createdOrder.TaxAmount)
+	assert.Equal(t, 33.0, createdOrder.TotalAmount)
+	require.Len(t, createdOrder.Items, 2)
+	assert.Equal(t, int64(21), createdOrder.Items[0].ID)
+	assert.Equal(t, int64(9), createdOrder.Items[0].OrderID)
+	assert.Equal(t, int64(22), createdOrder.Items[1].ID)
+
+	// Verify all expectations were met
+	err = mock.ExpectationsWereMet()
+	require.NoError(t, err)
+}
+
+func TestCreateOrderNumbered(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	year := time.Now().UTC().Year()
+	ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+	expectRelease := expectSavepoint(mock)
+
+	// Expect the tenant's counter to be incremented with its prefix
+	mock.ExpectQuery("INSERT INTO order_number_sequence (.+) ON CONFLICT \\(tenant_id, year\\) DO UPDATE SET last_value = order_number_sequence.last_value \\+ 1").
+		WithArgs(tenantID, year, DefaultOrderNumberPrefix).
+		WillReturnRows(sqlmock.NewRows([]string{"last_value", "prefix"}).AddRow(123, "ACME"))
+
+	// Expect the order to be inserted with the generated number
+	orderNumber := fmt.Sprintf("ACME-%d-000123", year)
+	mock.ExpectQuery("INSERT INTO \"order\"").
+		WithArgs(tenantID, int64(100), orderNumber, "pending", 10.0, "", sqlmock.AnyArg(), sqlmock.AnyArg(), 10.0, 0.0, 0.0, 0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "version"}).AddRow(1, 1))
+	mock.ExpectExec("INSERT INTO order_status_history").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRelease()
+
+	// Execute test
+	createdOrder, err := service.CreateOrder(ctx, &Order{TenantID: tenantID, UserID: 100, TotalAmount: 10.0})
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, orderNumber, createdOrder.OrderNumber)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderRetriesDeadlock(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	year := time.Now().UTC().Year()
+	ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+	// Expect the numbering to deadlock and be rolled back to the savepoint
+	mock.ExpectExec("SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO order_number_sequence").
+		WithArgs(tenantID, year, DefaultOrderNumberPrefix).
+		WillReturnError(&pgconn.PgError{Code: database.CodeDeadlockDetected})
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Expect the retry to number and insert the order
+	expectRelease := expectSavepoint(mock)
+	mock.ExpectQuery("INSERT INTO order_number_sequence").
+		WithArgs(tenantID, year, DefaultOrderNumberPrefix).
+		WillReturnRows(sqlmock.NewRows([]string{"last_value", "prefix"}).AddRow(7, "ORD"))
+	mock.ExpectQuery("INSERT INTO \"order\"").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "version"}).AddRow(1, 1))
+	mock.ExpectExec("INSERT INTO order_status_history").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRelease()
+
+	// Execute test
+	createdOrder, err := service.CreateOrder(ctx, &Order{TenantID: tenantID, UserID: 100, TotalAmount: 10.0})
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("ORD-%d-000007", year), createdOrder.OrderNumber)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateOrderSettings(t *testing.T) {
+	tenantID := int64(42)
+
+	t.Run("Normalizes prefix", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect the prefix to be stored in upper case
+		mock.ExpectExec("INSERT INTO tenant_order_setting").
+			WithArgs(tenantID, "ACME-EU").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute test
+		settings := &OrderSettings{OrderNumberPrefix: " acme-eu "}
+		err := service.UpdateOrderSettings(ctx, settings)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, "ACME-EU", settings.OrderNumberPrefix)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid prefix", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		for _, prefix := range []string{"ACME-", "AC ME", "ACME_EU", "ABCDEFGHIJKLMNOPQ"} {
+			err := service.UpdateOrderSettings(createContextWithTenant(tenantID), &OrderSettings{OrderNumberPrefix: prefix})
+			assert.ErrorIs(t, err, ErrInvalidInput, prefix)
+		}
+	})
+}
+
 func TestCreateOrderValidationErrors(t *testing.T) {
 	db, _, service := setupMock(t)
 	defer db.Close()
@@ -401,22 +970,60 @@ func TestCreateOrderValidationErrors(t *testing.T) {
 			},
 		},
 		{
-			name: "Missing order number",
+			name: "Negative total amount",
 			order: &Order{
 				TenantID:    tenantID,
 				UserID:      3,
+				OrderNumber: "ORD-001",
 				Status:      "pending",
-				TotalAmount: 100.50,
+				TotalAmount: -10.0,
 			},
 		},
 		{
-			name: "Negative total amount",
+			name: "Item without SKU",
 			order: &Order{
 				TenantID:    tenantID,
 				UserID:      3,
 				OrderNumber: "ORD-001",
-				Status:      "pending",
-				TotalAmount: -10.0,
+				Items:       []OrderItem{{Quantity: 1, UnitPrice: 5}},
+			},
+		},
+		{
+			name: "Item without quantity",
+			order: &Order{
+				TenantID:    tenantID,
+				UserID:      3,
+				OrderNumber: "ORD-001",
+				Items:       []OrderItem{{SKU: "SKU-1", UnitPrice: 5}},
+			},
+		},
+		{
+			name: "Item with negative unit price",
+			order: &Order{
+				TenantID:    tenantID,
+				UserID:      3,
+				OrderNumber: "ORD-001",
+				Items:       []OrderItem{{SKU: "SKU-1", Quantity: 1, UnitPrice: -5}},
+			},
+		},
+		{
+			name: "Total not matching items",
+			order: &Order{
+				TenantID:    tenantID,
+				UserID:      3,
+				OrderNumber: "ORD-001",
+				TotalAmount: 1.00,
+				Items:       []OrderItem{{SKU: "SKU-1", Quantity: 2, UnitPrice: 5}},
+			},
+		},
+		{
+			name: "Discount greater than subtotal",
+			order: &Order{
+				TenantID:       tenantID,
+				UserID:         3,
+				OrderNumber:    "ORD-001",
+				Subtotal:       10,
+				DiscountAmount: 15,
 			},
 		},
 		{
@@ -449,26 +1056,29 @@ func TestUpdateOrder(t *testing.T) {
 	userID := int64(100)
 	now := time.Now()
 	order := &Order{
-		ID:          1,
-		TenantID:    tenantID,
-		UserID:      userID,
-		OrderNumber: "ORD-001",
-		Status:      "completed",
-		TotalAmount: 120.75,
-		Notes:       "Updated test order",
-		UpdatedAt:   now,
+		ID:           1,
+		TenantID:     tenantID,
+		UserID:       userID,
+		OrderNumber:  "ORD-001",
+		Status:       "completed",
+		TotalAmount:  120.75,
+		Notes:        "Updated test order",
+		UpdatedAt:    now,
+		Version:      3,
+		StatusReason: "Delivered to customer",
 	}
 
-	// Create context with tenant
-	ctx := createContextWithTenant(tenantID)
+	// Create context with tenant and acting user
+	ctx := authctx.WithUserID(createContextWithTenant(tenantID), userID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Expect the order to be locked in a retry savepoint
+	expectRelease := expectSavepoint(mock)
+	mock.ExpectQuery("SELECT status, version, (.+) FROM \"order\" WHERE order_id = \\$1 AND tenant_id = \\$2 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs(order.ID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "version", "subtotal"}).AddRow("processing", 3, nil))
 
 	// Expect update query
 	mock.ExpectExec("UPDATE \"order\"").
@@ -478,33 +1088,140 @@ func TestUpdateOrder(t *testing.T) {
 			order.Status,
 			order.TotalAmount,
 			order.Notes,
-			order.UpdatedAt,
+			sqlmock.AnyArg(),
 			order.ID,
 			order.TenantID,
+			order.TotalAmount,
+			0.0,
+			0.0,
+			0.0,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
+	// Expect the status change to be recorded by the acting user
+	mock.ExpectExec("INSERT INTO order_status_history").
+		WithArgs(order.ID, tenantID, "processing", "completed", userID, "Delivered to customer", sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect commit
-	mock.ExpectCommit()
-
-	// Setup transaction in context
-	ctx = setupTransaction(ctx, mock)
+	expectRelease()
 
 	// Execute test
 	err := service.UpdateOrder(ctx, order)
 
 	// Verify results
 	require.NoError(t, err)
+	assert.Equal(t, 4, order.Version)
 
 	// Verify all expectations were met
 	err = mock.ExpectationsWereMet()
 	require.NoError(t, err)
 }
 
+func TestUpdateOrderRetriesDeadlock(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	order := &Order{ID: 1, TenantID: tenantID, UserID: 100, OrderNumber: "ORD-001", Status: "pending", TotalAmount: 10.0, Version: 3}
+	ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+	// Expect the update to deadlock and be rolled back to the savepoint
+	mock.ExpectExec("SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT status, version, (.+) FROM \"order\"").
+		WithArgs(order.ID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "version", "subtotal"}).AddRow("pending", 3, nil))
+	mock.ExpectExec("UPDATE \"order\"").
+		WillReturnError(&pgconn.PgError{Code: database.CodeDeadlockDetected})
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Expect the retry to update the order at the same version
+	expectRelease := expectSavepoint(mock)
+	mock.ExpectQuery("SELECT status, version, (.+) FROM \"order\"").
+		WithArgs(order.ID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "version", "subtotal"}).AddRow("pending", 3, nil))
+	mock.ExpectExec("UPDATE \"order\"").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRelease()
+
+	// Execute test
+	err := service.UpdateOrder(ctx, order)
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, 4, order.Version)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchOrder(t *testing.T) {
+	orderColumns := []string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}
+	tenantID := int64(42)
+	now := time.Now()
+
+	t.Run("Patch notes only", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		notes := "Leave at the front desk"
+		version := 1
+
+		// Expect the order to be locked and read
+		mock.ExpectQuery("SELECT order_id, (.+) FROM \"order\" WHERE order_id = \\$1 AND tenant_id = \\$2 AND deleted_at IS NULL FOR UPDATE").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(1, tenantID, 100, "ORD-001", "processing", 99.5, "", now, now, 1, nil, 99.5, 0, 0, 0, nil))
+
+		// Expect the update to keep the fields missing from the patch
+		expectRelease := expectSavepoint(mock)
+		mock.ExpectQuery("SELECT status, version, (.+) FROM \"order\"").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "version", "subtotal"}).AddRow("processing", 1, nil))
+		mock.ExpectExec("UPDATE \"order\"").
+			WithArgs(int64(100), "ORD-001", "processing", 99.5, notes, sqlmock.AnyArg(), int64(1), tenantID, 99.5, 0.0, 0.0, 0.0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRelease()
+
+		// Expect the updated order to be read back
+		mock.ExpectQuery("SELECT order_id, (.+) FROM \"order\" WHERE order_id = \\$1 AND tenant_id = \\$2").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(1, tenantID, 100, "ORD-001", "processing", 99.5, notes, now, now, 1, nil, 99.5, 0, 0, 0, nil))
+		mock.ExpectQuery("SELECT item_id, order_id, sku, description, quantity, unit_price FROM order_item").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"item_id", "order_id", "sku", "description", "quantity", "unit_price"}))
+
+		// Execute test
+		order, err := service.PatchOrder(ctx, 1, OrderPatch{Notes: &notes, Version: &version})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, notes, order.Notes)
+		assert.Equal(t, "processing", order.Status)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		status := "completed"
+		version := 1
+
+		// Expect the lock to find no order
+		mock.ExpectQuery("SELECT order_id, (.+) FOR UPDATE").
+			WithArgs(int64(999), tenantID).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute test
+		order, err := service.PatchOrder(ctx, 999, OrderPatch{Status: &status, Version: &version})
+
+		// Verify results
+		assert.Nil(t, order)
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestDeleteOrder(t *testing.T) {
 	db, mock, service := setupMock(t)
 	defer db.Close()
@@ -516,38 +1233,279 @@ func TestDeleteOrder(t *testing.T) {
 	// Create context with tenant
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
-	// Expect delete query
-	mock.ExpectExec("DELETE FROM \"order\"").
+	// Expect the order to be locked at the expected version, then moved to the trash
+	mock.ExpectQuery("SELECT version FROM \"order\" WHERE order_id = \\$1 AND tenant_id = \\$2 AND deleted_at IS NULL FOR UPDATE").
 		WithArgs(orderID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectExec("UPDATE \"order\" SET deleted_at = \\$1").
+		WithArgs(sqlmock.AnyArg(), orderID, tenantID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Execute test
+	err := service.DeleteOrder(ctx, orderID, 2)
 
-	// Expect commit
-	mock.ExpectCommit()
+	// Verify results
+	require.NoError(t, err)
 
-	// Setup transaction in context
-	ctx = setupTransaction(ctx, mock)
+	// Verify all expectations were met
+	err = mock.ExpectationsWereMet()
+	require.NoError(t, err)
+}
+
+// recordingPublisher collects published events
+type recordingPublisher struct {
+	published chan events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.published <- event
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+func TestOrderEventsPublishedAfterCommit(t *testing.T) {
+	tenantID := int64(42)
+
+	// expectDelete expects an order to be moved to the trash
+	expectDelete := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT version FROM \"order\"").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mock.ExpectExec("UPDATE \"order\" SET deleted_at").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	t.Run("Committed", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		publisher := &recordingPublisher{published: make(chan events.Event, 1)}
+		service.WithEventPublisher(publisher)
+		manager := transaction.NewManager(db)
+
+		mock.ExpectBegin()
+		ctx, _, err := manager.Begin(createContextWithTenant(tenantID))
+		require.NoError(t, err)
+		expectDelete(mock)
+		mock.ExpectCommit()
+
+		// Execute test
+		require.NoError(t, service.DeleteOrder(ctx, 1, 2))
+		assert.Empty(t, publisher.published, "events must not be published before commit")
+		require.NoError(t, manager.Commit(ctx))
+
+		// Verify results
+		select {
+		case event := <-publisher.published:
+			assert.Equal(t, EventOrderDeleted, event.Type)
+			assert.Equal(t, tenantID, event.TenantID)
+			assert.Equal(t, "1", event.Key)
+			assert.Equal(t, OrderDeletedData{OrderID: 1, Version: 3}, event.Data)
+		case <-time.After(time.Second):
+			t.Fatal("event was not published")
+		}
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolled back", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		publisher := &recordingPublisher{published: make(chan events.Event, 1)}
+		service.WithEventPublisher(publisher)
+		manager := transaction.NewManager(db)
+
+		mock.ExpectBegin()
+		ctx, _, err := manager.Begin(createContextWithTenant(tenantID))
+		require.NoError(t, err)
+		expectDelete(mock)
+		mock.ExpectRollback()
+
+		// Execute test
+		require.NoError(t, service.DeleteOrder(ctx, 1, 2))
+		require.NoError(t, manager.Rollback(ctx))
+
+		// Verify results
+		select {
+		case event := <-publisher.published:
+			t.Fatalf("event %s of a rolled back change was published", event.Type)
+		case <-time.After(50 * time.Millisecond):
+		}
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOrderEventsWrittenToOutbox(t *testing.T) {
+	tenantID := int64(42)
+
+	t.Run("Written in the transaction", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		publisher := &recordingPublisher{published: make(chan events.Event, 1)}
+		service.WithEventPublisher(publisher).WithOutbox(events.NewOutbox(db))
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		mock.ExpectQuery("SELECT version FROM \"order\"").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mock.ExpectExec("UPDATE \"order\" SET deleted_at").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO event_outbox").
+			WithArgs(sqlmock.AnyArg(), tenantID, EventOrderDeleted, "1", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute test
+		require.NoError(t, service.DeleteOrder(ctx, 1, 2))
+
+		// Verify results: the outbox is used instead of the publisher
+		assert.Empty(t, publisher.published)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Outbox write fails", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		service.WithOutbox(events.NewOutbox(db))
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		mock.ExpectQuery("SELECT version FROM \"order\"").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mock.ExpectExec("UPDATE \"order\" SET deleted_at").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO event_outbox").
+			WillReturnError(errors.New("connection reset"))
+
+		// Execute test
+		err := service.DeleteOrder(ctx, 1, 2)
+
+		// Verify results: the change fails with its event
+		assert.ErrorIs(t, err, ErrDBOperation)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRestoreOrder(t *testing.T) {
+	orderColumns := []string{"order_id", "tenant_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at", "version", "deleted_at", "subtotal", "discount_amount", "tax_rate", "tax_amount", "assigned_to"}
+	tenantID := int64(42)
+	now := time.Now()
+
+	t.Run("Restore deleted order", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect the order to be taken out of the trash, then read back
+		mock.ExpectExec("UPDATE \"order\" SET deleted_at = NULL, (.+) AND deleted_at IS NOT NULL").
+			WithArgs(sqlmock.AnyArg(), int64(1), tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT order_id, (.+) AND deleted_at IS NULL").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(1, tenantID, 100, "ORD-001", "pending", 10.0, "", now, now, 3, nil, 10.0, 0, 0, 0, nil))
+		mock.ExpectQuery("SELECT item_id, order_id, sku, description, quantity, unit_price FROM order_item").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"item_id", "order_id", "sku", "description", "quantity", "unit_price"}))
+
+		// Execute test
+		order, err := service.RestoreOrder(ctx, 1)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Nil(t, order.DeletedAt)
+		assert.Equal(t, 3, order.Version)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Order not in trash", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect no deleted order to be found
+		mock.ExpectExec("UPDATE \"order\" SET deleted_at = NULL").
+			WithArgs(sqlmock.AnyArg(), int64(1), tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute test
+		order, err := service.RestoreOrder(ctx, 1)
+
+		// Verify results
+		assert.Nil(t, order)
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListDeletedOrders(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+	// Expect the count without the trash condition
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "order"`).
+		WithArgs(queryArgs(orderdb.CountOrdersParams{TenantID: tenantID, IncludeDeleted: true})...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
 
 	// Execute test
-	err := service.DeleteOrder(ctx, orderID)
+	count, err := service.CountOrders(ctx, OrderFilter{IncludeDeleted: true})
 
 	// Verify results
 	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
 
-	// Verify all expectations were met
-	err = mock.ExpectationsWereMet()
+func TestPurgeDeletedOrders(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	before := time.Now().Add(-DefaultOrderRetention)
+
+	// Expect orders deleted before the cutoff to be removed outside any transaction
+	mock.ExpectExec(`DELETE FROM "order" WHERE deleted_at < \$1`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	// Execute test
+	purged, err := service.PurgeDeletedOrders(context.Background(), before)
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), purged)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveOrders(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
+
+	before := time.Now().Add(-DefaultOrderArchiveAfter)
+
+	// Expect full batches to be archived until one comes up short
+	mock.ExpectExec(`WITH archived AS \( DELETE FROM "order" (.+) INSERT INTO order_archive`).
+		WithArgs(before, OrderArchiveBatchSize).
+		WillReturnResult(sqlmock.NewResult(0, OrderArchiveBatchSize))
+	mock.ExpectExec(`WITH archived AS`).
+		WithArgs(before, OrderArchiveBatchSize).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	// Execute test
+	archived, err := service.ArchiveOrders(context.Background(), before)
+
+	// Verify results
 	require.NoError(t, err)
+	assert.Equal(t, int64(OrderArchiveBatchSize+3), archived)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestDeleteOrderNotFound(t *testing.T) {
@@ -561,28 +1519,16 @@ func TestDeleteOrderNotFound(t *testing.T) {
 	// Create context with tenant ID
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
-	// Setup expectations for DeleteOrder - no rows affected
-	mock.ExpectExec(`DELETE FROM "order" WHERE order_id = \$1 AND tenant_id = \$2`).
+	// Setup expectations for DeleteOrder - the lock finds nothing
+	mock.ExpectQuery(`SELECT version FROM "order" WHERE order_id = \$1 AND tenant_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
 		WithArgs(orderID, tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	// Expect rollback due to error
-	mock.ExpectRollback()
+		WillReturnError(sql.ErrNoRows)
 
 	// Execute test
-	err := service.DeleteOrder(ctx, orderID)
+	err := service.DeleteOrder(ctx, orderID, 1)
 
 	// Verify results
 	assert.ErrorIs(t, err, ErrOrderNotFound)
@@ -602,41 +1548,200 @@ func TestCountOrders(t *testing.T) {
 	// Create context with tenant
 	ctx := createContextWithTenant(tenantID)
 
-	// Setup expectations for transaction
-	mock.ExpectBegin()
-
-	// Expect set_tenant_context call
-	mock.ExpectExec("SELECT set_tenant_context\\(\\$1\\)").
-		WithArgs(tenantID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Setup transaction in context
+	ctx = setupTransaction(t, ctx, db, mock)
 
 	// Expect count query
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\)").
-		WithArgs(tenantID).
+		WithArgs(queryArgs(orderdb.CountOrdersParams{TenantID: tenantID})...).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
 
-	// Expect clear_tenant_context call
-	mock.ExpectExec("SELECT clear_tenant_context\\(\\)").
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Execute test
+	count, err := service.CountOrders(ctx, OrderFilter{})
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	// Verify all expectations were met
+	err = mock.ExpectationsWereMet()
+	require.NoError(t, err)
+}
+
+func TestUpdateOrderNotFound(t *testing.T) {
+	db, mock, service := setupMock(t)
+	defer db.Close()
 
-	// Expect commit
-	mock.ExpectCommit()
+	// Test data
+	tenantID := int64(42)
+	order := &Order{ID: 999, TenantID: tenantID, UserID: 100, OrderNumber: "ORD-999", Status: "pending", Version: 1}
+
+	// Create context with tenant
+	ctx := createContextWithTenant(tenantID)
 
 	// Setup transaction in context
-	ctx = setupTransaction(ctx, mock)
+	ctx = setupTransaction(t, ctx, db, mock)
+
+	// Expect the order lock to find nothing and the savepoint to be rolled back
+	mock.ExpectExec("SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT status, version, (.+) FROM \"order\"").
+		WithArgs(order.ID, tenantID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
 
 	// Execute test
-	count, err := service.CountOrders(ctx, OrderFilter{})
+	err := service.UpdateOrder(ctx, order)
 
 	// Verify results
-	require.NoError(t, err)
-	assert.Equal(t, 5, count)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
 
 	// Verify all expectations were met
 	err = mock.ExpectationsWereMet()
 	require.NoError(t, err)
 }
 
+func TestOrderVersionConflict(t *testing.T) {
+	tenantID := int64(42)
+
+	t.Run("UpdateOrder", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		order := &Order{ID: 1, TenantID: tenantID, UserID: 100, OrderNumber: "ORD-001", Status: "pending", Version: 2}
+
+		// Expect the lock to find the order updated since version 2
+		mock.ExpectExec("SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT status, version, (.+) FROM \"order\"").
+			WithArgs(order.ID, tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "version", "subtotal"}).AddRow("pending", 3, nil))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT retry").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute test
+		err := service.UpdateOrder(ctx, order)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, 2, order.Version)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DeleteOrder", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect the lock to find the order updated since version 1
+		mock.ExpectQuery("SELECT version FROM \"order\"").
+			WithArgs(int64(1), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+		// Execute test
+		err := service.DeleteOrder(ctx, 1, 1)
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrConflict)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing version", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		ctx := createContextWithTenant(tenantID)
+
+		// Execute test
+		err := service.UpdateOrder(ctx, &Order{ID: 1, TenantID: tenantID, UserID: 100, OrderNumber: "ORD-001", Status: "pending"})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+
+		order, err := service.PatchOrder(ctx, 1, OrderPatch{})
+		assert.Nil(t, order)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestGetOrderHistory(t *testing.T) {
+	historyColumns := []string{"history_id", "order_id", "from_status", "to_status", "changed_by", "reason", "changed_at"}
+	tenantID := int64(42)
+	orderID := int64(1)
+	now := time.Now()
+
+	t.Run("Status changes", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect history query
+		mock.ExpectQuery("SELECT history_id, order_id, from_status, to_status, changed_by, reason, changed_at FROM order_status_history").
+			WithArgs(orderID, tenantID).
+			WillReturnRows(sqlmock.NewRows(historyColumns).
+				AddRow(1, orderID, nil, "pending", 100, "", now.Add(-time.Hour)).
+				AddRow(2, orderID, "pending", "cancelled", nil, "Out of stock", now))
+
+		// Execute test
+		history, err := service.GetOrderHistory(ctx, orderID)
+
+		// Verify results
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Nil(t, history[0].FromStatus)
+		assert.Equal(t, int64(100), *history[0].ChangedBy)
+		assert.Equal(t, "pending", *history[1].FromStatus)
+		assert.Equal(t, "cancelled", history[1].ToStatus)
+		assert.Nil(t, history[1].ChangedBy)
+		assert.Equal(t, "Out of stock", history[1].Reason)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Order without history", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect an empty history for an existing order
+		mock.ExpectQuery("FROM order_status_history").
+			WithArgs(orderID, tenantID).
+			WillReturnRows(sqlmock.NewRows(historyColumns))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(orderID, tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		// Execute test
+		history, err := service.GetOrderHistory(ctx, orderID)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Empty(t, history)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+
+		// Expect no history and no order
+		mock.ExpectQuery("FROM order_status_history").
+			WithArgs(int64(999), tenantID).
+			WillReturnRows(sqlmock.NewRows(historyColumns))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(int64(999), tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		// Execute test
+		history, err := service.GetOrderHistory(ctx, 999)
+
+		// Verify results
+		assert.Nil(t, history)
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestNoTenantContext(t *testing.T) {
 	db, _, service := setupMock(t)
 	defer db.Close()
@@ -664,12 +1769,12 @@ func TestNoTenantContext(t *testing.T) {
 	})
 
 	t.Run("UpdateOrder", func(t *testing.T) {
-		err := service.UpdateOrder(ctx, &Order{ID: 1})
+		err := service.UpdateOrder(ctx, &Order{ID: 1, TenantID: 1, UserID: 1, OrderNumber: "ORD-001", Status: "pending", Version: 1})
 		assert.ErrorIs(t, err, ErrNoTenantContext)
 	})
 
 	t.Run("DeleteOrder", func(t *testing.T) {
-		err := service.DeleteOrder(ctx, 1)
+		err := service.DeleteOrder(ctx, 1, 1)
 		assert.ErrorIs(t, err, ErrNoTenantContext)
 	})
 
@@ -678,4 +1783,10 @@ func TestNoTenantContext(t *testing.T) {
 		assert.Equal(t, 0, count)
 		assert.ErrorIs(t, err, ErrNoTenantContext)
 	})
+
+	t.Run("GetOrderHistory", func(t *testing.T) {
+		history, err := service.GetOrderHistory(ctx, 1)
+		assert.Nil(t, history)
+		assert.ErrorIs(t, err, ErrNoTenantContext)
+	})
 }
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// Order summary intervals
+const (
+	SummaryIntervalDay   = "day"
+	SummaryIntervalWeek  = "week"
+	SummaryIntervalMonth = "month"
+)
+
+// MaxOrderSummaryDays is the longest date range an order summary can cover
+const MaxOrderSummaryDays = 366
+
+// summaryDateLayout is the layout of the dates in an order summary
+const summaryDateLayout = "2006-01-02"
+
+// OrderSummaryFilter selects the orders to summarize. From and To are inclusive
+// UTC dates.
+type OrderSummaryFilter struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+}
+
+// OrderSummary is the number of orders and their revenue over a date range,
+// grouped by status and by period
+type OrderSummary struct {
+	From     string               `json:"from"`
+	To       string               `json:"to"`
+	Interval string               `json:"interval"`
+	Orders   int64                `json:"orders"`
+	Revenue  float64              `json:"revenue"`
+	ByStatus []OrderStatusSummary `json:"by_status"`
+	ByPeriod []OrderPeriodSummary `json:"by_period"`
+}
+
+// OrderStatusSummary is the number of orders with a status and their revenue
+type OrderStatusSummary struct {
+	Status  string  `json:"status"`
+	Orders  int64   `json:"orders"`
+	Revenue float64 `json:"revenue"`
+}
+
+// OrderPeriodSummary is the number of orders created in the period starting on
+// a date and their revenue
+type OrderPeriodSummary struct {
+	Period  string  `json:"period"`
+	Orders  int64   `json:"orders"`
+	Revenue float64 `json:"revenue"`
+}
+
+// GetOrderSummary summarizes the orders of the current tenant created within the
+// filter's date range. Every period of the range is included, with or without
+// orders.
+func (s *DBOrderService) GetOrderSummary(ctx context.Context, filter OrderSummaryFilter) (*OrderSummary, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	if filter.Interval == "" {
+		filter.Interval = SummaryIntervalDay
+	}
+	if err := validateOrderSummaryFilter(filter); err != nil {
+		return nil, err
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// One pass over the orders yields the totals by status, by period and
+	// overall, told apart by which columns were grouped
+	query := `
+		SELECT status, period, GROUPING(status, period), COUNT(*), COALESCE(SUM(total_amount), 0)
+		FROM (
+			SELECT status, total_amount, date_trunc($4, created_at AT TIME ZONE 'UTC')::date AS period
+			FROM "order"
+			WHERE tenant_id = $1 AND deleted_at IS NULL
+				AND created_at >= $2::date AND created_at < $3::date + 1
+		) o
+		GROUP BY GROUPING SETS ((status), (period), ())
+		ORDER BY 3, 1, 2`
+
+	from := filter.From.Format(summaryDateLayout)
+	to := filter.To.Format(summaryDateLayout)
+	rows, err := tx.QueryContext(ctx, query, *tenantID, from, to, filter.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	summary := &OrderSummary{
+		From:     from,
+		To:       to,
+		Interval: filter.Interval,
+		ByStatus: []OrderStatusSummary{},
+	}
+	periods := make(map[string]OrderPeriodSummary)
+	for rows.Next() {
+		var status sql.NullString
+		var period sql.NullTime
+		var grouping int
+		var orders int64
+		var revenue float64
+		if err := rows.Scan(&status, &period, &grouping, &orders, &revenue); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		// GROUPING sets a bit for each column that was rolled up
+		switch grouping {
+		case 1:
+			summary.ByStatus = append(summary.ByStatus, OrderStatusSummary{Status: status.String, Orders: orders, Revenue: revenue})
+		case 2:
+			date := period.Time.Format(summaryDateLayout)
+			periods[date] = OrderPeriodSummary{Period: date, Orders: orders, Revenue: revenue}
+		case 3:
+			summary.Orders = orders
+			summary.Revenue = revenue
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	for start := truncateToInterval(filter.From, filter.Interval); !start.After(filter.To); start = nextInterval(start, filter.Interval) {
+		date := start.Format(summaryDateLayout)
+		period, ok := periods[date]
+		if !ok {
+			period = OrderPeriodSummary{Period: date}
+		}
+		summary.ByPeriod = append(summary.ByPeriod, period)
+	}
+
+	return summary, nil
+}
+
+// validateOrderSummaryFilter validates the interval and date range of an order
+// summary
+func validateOrderSummaryFilter(filter OrderSummaryFilter) error {
+	switch filter.Interval {
+	case SummaryIntervalDay, SummaryIntervalWeek, SummaryIntervalMonth:
+	default:
+		return fmt.Errorf("%w: interval must be day, week or month", ErrInvalidInput)
+	}
+
+	if filter.To.Before(filter.From) {
+		return fmt.Errorf("%w: from date must not be after to date", ErrInvalidInput)
+	}
+	if days := int(filter.To.Sub(filter.From).Hours()/24) + 1; days > MaxOrderSummaryDays {
+		return fmt.Errorf("%w: date range cannot exceed %d days", ErrInvalidInput, MaxOrderSummaryDays)
+	}
+	return nil
+}
+
+// truncateToInterval returns the start of the interval containing the date, like
+// date_trunc. Weeks start on Monday.
+func truncateToInterval(date time.Time, interval string) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case SummaryIntervalWeek:
+		return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	case SummaryIntervalMonth:
+		return date.AddDate(0, 0, 1-date.Day())
+	default:
+		return date
+	}
+}
+
+// nextInterval returns the start of the interval after the one starting at start
+func nextInterval(start time.Time, interval string) time.Time {
+	switch interval {
+	case SummaryIntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case SummaryIntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
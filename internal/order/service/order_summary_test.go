@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrderSummary(t *testing.T) {
+	tenantID := int64(42)
+
+	t.Run("Summarized", func(t *testing.T) {
+		db, mock, service := setupMock(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		mock.ExpectQuery("GROUP BY GROUPING SETS").
+			WithArgs(tenantID, "2025-03-01", "2025-03-03", "day").
+			WillReturnRows(sqlmock.NewRows([]string{"status", "period", "grouping", "count", "sum"}).
+				AddRow("cancelled", nil, 1, 1, 10.0).
+				AddRow("pending", nil, 1, 2, 45.5).
+				AddRow(nil, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 2, 2, 30.0).
+				AddRow(nil, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), 2, 1, 25.5).
+				AddRow(nil, nil, 3, 3, 55.5))
+
+		// Execute test
+		summary, err := service.GetOrderSummary(ctx, OrderSummaryFilter{
+			From: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, &OrderSummary{
+			From:     "2025-03-01",
+			To:       "2025-03-03",
+			Interval: "day",
+			Orders:   3,
+			Revenue:  55.5,
+			ByStatus: []OrderStatusSummary{
+				{Status: "cancelled", Orders: 1, Revenue: 10},
+				{Status: "pending", Orders: 2, Revenue: 45.5},
+			},
+			ByPeriod: []OrderPeriodSummary{
+				{Period: "2025-03-01", Orders: 2, Revenue: 30},
+				{Period: "2025-03-02"},
+				{Period: "2025-03-03", Orders: 1, Revenue: 25.5},
+			},
+		}, summary)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		db, _, service := setupMock(t)
+		defer db.Close()
+
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		for name, filter := range map[string]OrderSummaryFilter{
+			"interval": {From: from, To: from, Interval: "year"},
+			"reversed": {From: from, To: from.AddDate(0, 0, -1)},
+			"too long": {From: from, To: from.AddDate(0, 0, MaxOrderSummaryDays)},
+		} {
+			// Execute test
+			_, err := service.GetOrderSummary(createContextWithTenant(tenantID), filter)
+
+			// Verify results
+			assert.ErrorIs(t, err, ErrInvalidInput, name)
+		}
+	})
+}
+
+func TestTruncateToInterval(t *testing.T) {
+	// Wednesday
+	date := time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC), truncateToInterval(date, SummaryIntervalDay))
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), truncateToInterval(date, SummaryIntervalWeek))
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), truncateToInterval(date, SummaryIntervalMonth))
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// OrderQuotaChecker checks whether a tenant can create another order
+type OrderQuotaChecker interface {
+	CheckOrderQuota(ctx context.Context, tenantID int64) error
+}
+
+// QuotaEnforcingOrderService decorates an OrderService, rejecting new orders
+// once a tenant reaches its order limit
+type QuotaEnforcingOrderService struct {
+	OrderService
+	quotaChecker OrderQuotaChecker
+	txManager    transaction.TxManager
+}
+
+// Ensure QuotaEnforcingOrderService implements OrderService
+var _ OrderService = (*QuotaEnforcingOrderService)(nil)
+
+// NewQuotaEnforcingOrderService creates a new QuotaEnforcingOrderService
+func NewQuotaEnforcingOrderService(orderService OrderService, quotaChecker OrderQuotaChecker) *QuotaEnforcingOrderService {
+	return &QuotaEnforcingOrderService{
+		OrderService: orderService,
+		quotaChecker: quotaChecker,
+	}
+}
+
+// WithTxManager checks the quota and creates the order in one transaction of
+// txManager, so concurrent orders can't both pass the check at the limit
+func (s *QuotaEnforcingOrderService) WithTxManager(txManager transaction.TxManager) *QuotaEnforcingOrderService {
+	s.txManager = txManager
+	return s
+}
+
+// CreateOrder creates a new order if the tenant's order limit allows it
+func (s *QuotaEnforcingOrderService) CreateOrder(ctx context.Context, order *Order) (*Order, error) {
+	var created *Order
+	err := s.withTransaction(ctx, func(ctx context.Context) (err error) {
+		if err := s.quotaChecker.CheckOrderQuota(ctx, order.TenantID); err != nil {
+			return err
+		}
+		created, err = s.OrderService.CreateOrder(ctx, order)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// withTransaction runs fn in the transaction in the context or a new one, or
+// without one if there is no transaction manager
+func (s *QuotaEnforcingOrderService) withTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.txManager == nil {
+		return fn(ctx)
+	}
+	return s.txManager.WithTransaction(ctx, fn)
+}
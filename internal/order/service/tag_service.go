@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// ErrTagNotFound is returned when no order of the tenant has a tag
+var ErrTagNotFound = errors.New("tag not found")
+
+// Tag limits
+const (
+	// MaxTagLength is the longest tag, in characters
+	MaxTagLength = 50
+	// MaxOrderTags is the most tags an order can have
+	MaxOrderTags = 20
+)
+
+// TagCount is a tag in use by a tenant with the number of its orders that have it
+type TagCount struct {
+	Tag    string `json:"tag"`
+	Orders int    `json:"orders"`
+}
+
+// TagService defines the interface for order tag operations. Tags are free-form
+// labels; they exist while at least one order has them.
+type TagService interface {
+	// ListTags lists the tags on orders of the current tenant, with the number of
+	// orders that have each
+	ListTags(ctx context.Context) ([]TagCount, error)
+
+	// RenameTag renames a tag on every order of the current tenant, merging it
+	// into the new name if that is in use, and returns the number of orders changed
+	RenameTag(ctx context.Context, tag string, name string) (int64, error)
+
+	// DeleteTag removes a tag from every order of the current tenant and returns
+	// the number of orders changed
+	DeleteTag(ctx context.Context, tag string) (int64, error)
+
+	// GetOrderTags retrieves the tags of an order of the current tenant
+	GetOrderTags(ctx context.Context, orderID int64) ([]string, error)
+
+	// SetOrderTags replaces the tags of an order of the current tenant and
+	// returns the tags it now has
+	SetOrderTags(ctx context.Context, orderID int64, tags []string) ([]string, error)
+
+	// ListTagsForOrders retrieves the tags of orders of the current tenant by
+	// order ID. Orders without tags are left out.
+	ListTagsForOrders(ctx context.Context, orderIDs []int64) (map[int64][]string, error)
+}
+
+// DBTagService implements TagService using a database
+type DBTagService struct {
+	txManager transaction.TxManager
+}
+
+// Ensure DBTagService implements TagService
+var _ TagService = (*DBTagService)(nil)
+
+// NewDBTagService creates a new DBTagService
+func NewDBTagService(db *sql.DB) *DBTagService {
+	return &DBTagService{
+		txManager: transaction.NewManager(db),
+	}
+}
+
+// WithTxManager runs the DBTagService's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (s *DBTagService) WithTxManager(txManager transaction.TxManager) *DBTagService {
+	s.txManager = txManager
+	return s
+}
+
+// normalizeTag lower-cases a tag and collapses its whitespace, so tags differing
+// only in case or spacing are the same
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// validateTag checks a normalized tag. Tags are used in URL paths, so they can't
+// contain slashes.
+func validateTag(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("%w: tag is empty", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(tag) > MaxTagLength {
+		return fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidInput, tag, MaxTagLength)
+	}
+	if strings.Contains(tag, "/") {
+		return fmt.Errorf("%w: tag %q contains a slash", ErrInvalidInput, tag)
+	}
+	return nil
+}
+
+// ListTags lists the tags on orders of the current tenant, with the number of
+// orders that have each. Orders in the trash aren't counted.
+func (s *DBTagService) ListTags(ctx context.Context) ([]TagCount, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	query := `
+		SELECT t.tag, COUNT(*)
+		FROM order_tag t
+		JOIN "order" o ON o.order_id = t.order_id
+		WHERE t.tenant_id = $1 AND o.deleted_at IS NULL
+		GROUP BY t.tag
+		ORDER BY t.tag
+	`
+
+	rows, err := tx.QueryContext(ctx, query, *tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	tags := []TagCount{}
+	for rows.Next() {
+		var tag TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Orders); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return tags, nil
+}
+
+// RenameTag renames a tag on every order of the current tenant, merging it into
+// the new name on orders that already have that, and returns the number of
+// orders changed
+func (s *DBTagService) RenameTag(ctx context.Context, tag string, name string) (int64, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return 0, ErrNoTenantContext
+	}
+
+	tag = normalizeTag(tag)
+	name = normalizeTag(name)
+	if err := validateTag(name); err != nil {
+		return 0, err
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if name != tag {
+		query := `
+			INSERT INTO order_tag (order_id, tenant_id, tag)
+			SELECT order_id, tenant_id, $3
+			FROM order_tag
+			WHERE tenant_id = $1 AND tag = $2
+			ON CONFLICT (order_id, tag) DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, query, *tenantID, tag, name); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+	}
+
+	// Remove the old tag, or count the orders with it if the name is unchanged
+	var changed int64
+	if name != tag {
+		changed, err = s.removeTag(ctx, tx, *tenantID, tag)
+	} else {
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM order_tag WHERE tenant_id = $1 AND tag = $2", *tenantID, tag).Scan(&changed)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if changed == 0 {
+		return 0, ErrTagNotFound
+	}
+
+	log.Printf("[INFO] Renamed tag %q to %q on %d orders of tenant %d", tag, name, changed, *tenantID)
+	return changed, nil
+}
+
+// DeleteTag removes a tag from every order of the current tenant and returns the
+// number of orders changed
+func (s *DBTagService) DeleteTag(ctx context.Context, tag string) (int64, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return 0, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	tag = normalizeTag(tag)
+	changed, err := s.removeTag(ctx, tx, *tenantID, tag)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if changed == 0 {
+		return 0, ErrTagNotFound
+	}
+
+	log.Printf("[INFO] Deleted tag %q from %d orders of tenant %d", tag, changed, *tenantID)
+	return changed, nil
+}
+
+// removeTag removes a tag from the tenant's orders, returning how many had it
+func (s *DBTagService) removeTag(ctx context.Context, tx *sql.Tx, tenantID int64, tag string) (int64, error) {
+	result, err := tx.ExecContext(ctx, "DELETE FROM order_tag WHERE tenant_id = $1 AND tag = $2", tenantID, tag)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetOrderTags retrieves the tags of an order of the current tenant, sorted
+func (s *DBTagService) GetOrderTags(ctx context.Context, orderID int64) ([]string, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := checkOrderExists(ctx, tx, *tenantID, orderID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT tag FROM order_tag WHERE order_id = $1 AND tenant_id = $2 ORDER BY tag", orderID, *tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return tags, nil
+}
+
+// SetOrderTags replaces the tags of an order of the current tenant and returns
+// the tags it now has, normalized and sorted. Orders in the trash can't be tagged.
+func (s *DBTagService) SetOrderTags(ctx context.Context, orderID int64, tags []string) ([]string, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	// Normalize and deduplicate the tags
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if err := validateTag(tag); err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > MaxOrderTags {
+		return nil, fmt.Errorf("%w: an order can have at most %d tags", ErrInvalidInput, MaxOrderTags)
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := checkOrderExists(ctx, tx, *tenantID, orderID); err != nil {
+		return nil, err
+	}
+
+	// Remove the tags the order no longer has and add the new ones
+	_, err = tx.ExecContext(ctx, "DELETE FROM order_tag WHERE order_id = $1 AND tenant_id = $2 AND tag <> ALL($3)",
+		orderID, *tenantID, database.Array(normalized))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if len(normalized) > 0 {
+		query := `
+			INSERT INTO order_tag (order_id, tenant_id, tag)
+			SELECT $1, $2, unnest($3::text[])
+			ON CONFLICT (order_id, tag) DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, query, orderID, *tenantID, database.Array(normalized)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+	}
+
+	return normalized, nil
+}
+
+// ListTagsForOrders retrieves the tags of orders of the current tenant by order
+// ID, each sorted. Orders without tags are left out.
+func (s *DBTagService) ListTagsForOrders(ctx context.Context, orderIDs []int64) (map[int64][]string, error) {
+	// Verify tenant context
+	tenantID, err := authctx.GetTenantID(ctx)
+	if err != nil || tenantID == nil {
+		return nil, ErrNoTenantContext
+	}
+
+	tags := make(map[int64][]string)
+	if len(orderIDs) == 0 {
+		return tags, nil
+	}
+
+	// Get transaction from context
+	tx, err := s.txManager.GetTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT order_id, tag FROM order_tag WHERE tenant_id = $1 AND order_id = ANY($2) ORDER BY order_id, tag",
+		*tenantID, database.Array(orderIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID int64
+		var tag string
+		if err := rows.Scan(&orderID, &tag); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		tags[orderID] = append(tags[orderID], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return tags, nil
+}
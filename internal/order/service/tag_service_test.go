@@ -0,0 +1,143 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+func setupTagService(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *DBTagService) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return db, mock, NewDBTagService(db)
+}
+
+func TestSetOrderTags(t *testing.T) {
+	tenantID := int64(42)
+
+	t.Run("Replaced", func(t *testing.T) {
+		db, mock, service := setupTagService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		expectOrderExists(mock, 1, tenantID)
+		mock.ExpectExec("DELETE FROM order_tag WHERE order_id = \\$1 AND tenant_id = \\$2 AND tag <> ALL\\(\\$3\\)").
+			WithArgs(int64(1), tenantID, database.Array([]string{"rush order", "vip"})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO order_tag").
+			WithArgs(int64(1), tenantID, database.Array([]string{"rush order", "vip"})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute test
+		tags, err := service.SetOrderTags(ctx, 1, []string{"VIP", "Rush   Order", "vip"})
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, []string{"rush order", "vip"}, tags)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cleared", func(t *testing.T) {
+		db, mock, service := setupTagService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		expectOrderExists(mock, 1, tenantID)
+		mock.ExpectExec("DELETE FROM order_tag").
+			WithArgs(int64(1), tenantID, database.Array([]string{})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		// Execute test
+		tags, err := service.SetOrderTags(ctx, 1, nil)
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid tags", func(t *testing.T) {
+		db, _, service := setupTagService(t)
+		defer db.Close()
+
+		for _, tags := range [][]string{{" "}, {"a/b"}, {strings.Repeat("x", MaxTagLength+1)}} {
+			// Execute test
+			_, err := service.SetOrderTags(createContextWithTenant(tenantID), 1, tags)
+
+			// Verify results
+			assert.ErrorIs(t, err, ErrInvalidInput, "tags %q", tags)
+		}
+	})
+}
+
+func TestRenameTag(t *testing.T) {
+	tenantID := int64(42)
+
+	t.Run("Renamed", func(t *testing.T) {
+		db, mock, service := setupTagService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		mock.ExpectExec("INSERT INTO order_tag").
+			WithArgs(tenantID, "vip", "priority").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("DELETE FROM order_tag WHERE tenant_id = \\$1 AND tag = \\$2").
+			WithArgs(tenantID, "vip").
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		// Execute test
+		changed, err := service.RenameTag(ctx, "VIP", "Priority")
+
+		// Verify results
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), changed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		db, mock, service := setupTagService(t)
+		defer db.Close()
+
+		ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+		mock.ExpectExec("INSERT INTO order_tag").
+			WithArgs(tenantID, "vip", "priority").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM order_tag").
+			WithArgs(tenantID, "vip").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute test
+		_, err := service.RenameTag(ctx, "vip", "priority")
+
+		// Verify results
+		assert.ErrorIs(t, err, ErrTagNotFound)
+	})
+}
+
+func TestListTagsForOrders(t *testing.T) {
+	db, mock, service := setupTagService(t)
+	defer db.Close()
+
+	tenantID := int64(42)
+	ctx := setupTransaction(t, createContextWithTenant(tenantID), db, mock)
+	mock.ExpectQuery("SELECT order_id, tag FROM order_tag WHERE tenant_id = \\$1 AND order_id = ANY\\(\\$2\\)").
+		WithArgs(tenantID, database.Array([]int64{1, 2, 3})).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "tag"}).
+			AddRow(1, "rush order").
+			AddRow(1, "vip").
+			AddRow(3, "vip"))
+
+	// Execute test
+	tags, err := service.ListTagsForOrders(ctx, []int64{1, 2, 3})
+
+	// Verify results
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]string{1: {"rush order", "vip"}, 3: {"vip"}}, tags)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
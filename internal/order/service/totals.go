@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// totalTolerance is how far a client's total can be from the computed total, to
+// allow for rounding
+const totalTolerance = 0.005
+
+// OrderTotals are the amounts making up an order's total
+type OrderTotals struct {
+	Subtotal float64 `json:"subtotal"`
+	Discount float64 `json:"discount_amount"`
+	Tax      float64 `json:"tax_amount"`
+	Total    float64 `json:"total_amount"`
+}
+
+// CalculateTotals computes an order's totals from its subtotal, discount and tax
+// rate in percent. Tax is charged on the discounted subtotal. Amounts are rounded
+// to cents.
+func CalculateTotals(subtotal float64, discount float64, taxRate float64) (OrderTotals, error) {
+	if subtotal < 0 {
+		return OrderTotals{}, fmt.Errorf("%w: subtotal cannot be negative", ErrInvalidInput)
+	}
+	if discount < 0 {
+		return OrderTotals{}, fmt.Errorf("%w: discount cannot be negative", ErrInvalidInput)
+	}
+	if taxRate < 0 || taxRate > 100 {
+		return OrderTotals{}, fmt.Errorf("%w: tax rate must be between 0 and 100 percent", ErrInvalidInput)
+	}
+
+	subtotal = roundAmount(subtotal)
+	discount = roundAmount(discount)
+	if discount > subtotal {
+		return OrderTotals{}, fmt.Errorf("%w: discount of %.2f is greater than the subtotal of %.2f", ErrInvalidInput, discount, subtotal)
+	}
+
+	tax := roundAmount((subtotal - discount) * taxRate / 100)
+	return OrderTotals{
+		Subtotal: subtotal,
+		Discount: discount,
+		Tax:      tax,
+		Total:    roundAmount(subtotal - discount + tax),
+	}, nil
+}
+
+// applyTotals recomputes an order's totals. The subtotal of an order with items is
+// the sum of their amounts, given by itemsSubtotal. Orders without items keep their
+// subtotal, or are priced by their total alone if they have no subtotal, discount
+// or tax. A non-zero total that doesn't match the computed one is rejected.
+func applyTotals(order *Order, itemsSubtotal *float64) error {
+	subtotal := order.Subtotal
+	if itemsSubtotal != nil {
+		subtotal = *itemsSubtotal
+	} else if subtotal == 0 && order.DiscountAmount == 0 && order.TaxRate == 0 {
+		subtotal = order.TotalAmount
+	}
+
+	totals, err := CalculateTotals(subtotal, order.DiscountAmount, order.TaxRate)
+	if err != nil {
+		return err
+	}
+
+	if order.TotalAmount != 0 && math.Abs(order.TotalAmount-totals.Total) >= totalTolerance {
+		return fmt.Errorf("%w: total amount %.2f does not match the computed total of %.2f", ErrInvalidInput, order.TotalAmount, totals.Total)
+	}
+
+	order.Subtotal = totals.Subtotal
+	order.DiscountAmount = totals.Discount
+	order.TaxAmount = totals.Tax
+	order.TotalAmount = totals.Total
+	return nil
+}
+
+// roundAmount rounds an amount to cents like the stored amounts
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateTotals(t *testing.T) {
+	tests := []struct {
+		name     string
+		subtotal float64
+		discount float64
+		taxRate  float64
+		want     OrderTotals
+	}{
+		{name: "Subtotal only", subtotal: 100, want: OrderTotals{Subtotal: 100, Total: 100}},
+		{name: "Discount", subtotal: 100, discount: 15.5, want: OrderTotals{Subtotal: 100, Discount: 15.5, Total: 84.5}},
+		{name: "Tax on discounted subtotal", subtotal: 100, discount: 20, taxRate: 8.25, want: OrderTotals{Subtotal: 100, Discount: 20, Tax: 6.6, Total: 86.6}},
+		{name: "Tax rounded to cents", subtotal: 9.99, taxRate: 7.5, want: OrderTotals{Subtotal: 9.99, Tax: 0.75, Total: 10.74}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totals, err := CalculateTotals(tt.subtotal, tt.discount, tt.taxRate)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, totals)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, args := range [][3]float64{{-1, 0, 0}, {10, -1, 0}, {10, 11, 0}, {10, 0, 101}} {
+			_, err := CalculateTotals(args[0], args[1], args[2])
+			assert.ErrorIs(t, err, ErrInvalidInput, "%v", args)
+		}
+	})
+}
+
+func TestApplyTotals(t *testing.T) {
+	t.Run("Priced by total", func(t *testing.T) {
+		order := &Order{TotalAmount: 42.5}
+
+		require.NoError(t, applyTotals(order, nil))
+		assert.Equal(t, 42.5, order.Subtotal)
+		assert.Equal(t, 42.5, order.TotalAmount)
+	})
+
+	t.Run("Subtotal from items", func(t *testing.T) {
+		subtotal := 50.0
+		order := &Order{Subtotal: 10, TaxRate: 10}
+
+		require.NoError(t, applyTotals(order, &subtotal))
+		assert.Equal(t, 50.0, order.Subtotal)
+		assert.Equal(t, 5.0, order.TaxAmount)
+		assert.Equal(t, 55.0, order.TotalAmount)
+	})
+
+	t.Run("Matching total", func(t *testing.T) {
+		order := &Order{Subtotal: 100, DiscountAmount: 10, TaxRate: 20, TotalAmount: 108}
+
+		assert.NoError(t, applyTotals(order, nil))
+	})
+
+	t.Run("Mismatched total", func(t *testing.T) {
+		order := &Order{Subtotal: 100, DiscountAmount: 10, TaxRate: 20, TotalAmount: 100}
+
+		assert.ErrorIs(t, applyTotals(order, nil), ErrInvalidInput)
+	})
+}
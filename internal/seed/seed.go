@@ -0,0 +1,338 @@
+// Package seed populates a database with roles, users, tenants and orders for
+// local development and CI from a YAML seed file. Seeding is idempotent: rows
+// that already exist are left as they are, so a seed file can be applied to the
+// same database any number of times.
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+
+	authservice "github.com/unsavory/silocore-go/internal/auth/service"
+	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidSeed is returned for seed files that can't be applied
+var ErrInvalidSeed = errors.New("invalid seed file")
+
+// File is the content of a seed file
+type File struct {
+	Roles   []Role   `yaml:"roles"`
+	Users   []User   `yaml:"users"`
+	Tenants []Tenant `yaml:"tenants"`
+}
+
+// Role is a role to create in addition to the built-in roles
+type Role struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// User is a platform user with platform roles, such as ADMIN
+type User struct {
+	Email     string   `yaml:"email"`
+	Password  string   `yaml:"password"`
+	FirstName string   `yaml:"first_name"`
+	LastName  string   `yaml:"last_name"`
+	Roles     []string `yaml:"roles"`
+}
+
+// Tenant is a tenant with its members and orders
+type Tenant struct {
+	Name    string   `yaml:"name"`
+	Slug    string   `yaml:"slug"`
+	Members []Member `yaml:"members"`
+	Orders  []Order  `yaml:"orders"`
+}
+
+// Member is a user's membership of a tenant, with tenant roles
+type Member struct {
+	Email   string   `yaml:"email"`
+	Roles   []string `yaml:"roles"`
+	Default bool     `yaml:"default"`
+}
+
+// Order is an order placed by a member of its tenant. Its total is the sum of
+// its items.
+type Order struct {
+	Number string      `yaml:"number"`
+	User   string      `yaml:"user"`
+	Status string      `yaml:"status"`
+	Notes  string      `yaml:"notes"`
+	Items  []OrderItem `yaml:"items"`
+}
+
+// OrderItem is a line of an order
+type OrderItem struct {
+	SKU         string  `yaml:"sku"`
+	Description string  `yaml:"description"`
+	Quantity    int     `yaml:"quantity"`
+	UnitPrice   float64 `yaml:"unit_price"`
+}
+
+// Load reads and validates a seed file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSeed, err)
+	}
+	if err := file.validate(); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// validate checks that the file's rows are complete and that members and
+// orders refer to users in the file
+func (f *File) validate() error {
+	users := make(map[string]bool, len(f.Users))
+	for _, user := range f.Users {
+		if user.Email == "" || user.FirstName == "" || user.LastName == "" {
+			return fmt.Errorf("%w: users need an email, first name and last name", ErrInvalidSeed)
+		}
+		if err := authservice.ValidatePassword(user.Password); err != nil {
+			return fmt.Errorf("%w: password of %s: %v", ErrInvalidSeed, user.Email, err)
+		}
+		users[strings.ToLower(user.Email)] = true
+	}
+
+	for _, role := range f.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("%w: roles need a name", ErrInvalidSeed)
+		}
+	}
+
+	for _, tenant := range f.Tenants {
+		if tenant.Name == "" {
+			return fmt.Errorf("%w: tenants need a name", ErrInvalidSeed)
+		}
+		if err := tenantservice.ValidateSlug(tenant.Slug); err != nil {
+			return fmt.Errorf("%w: tenant %s: %v", ErrInvalidSeed, tenant.Name, err)
+		}
+		for _, member := range tenant.Members {
+			if !users[strings.ToLower(member.Email)] {
+				return fmt.Errorf("%w: member %s of tenant %s is not a user", ErrInvalidSeed, member.Email, tenant.Slug)
+			}
+		}
+		for _, order := range tenant.Orders {
+			if order.Number == "" {
+				return fmt.Errorf("%w: orders of tenant %s need a number", ErrInvalidSeed, tenant.Slug)
+			}
+			if !users[strings.ToLower(order.User)] {
+				return fmt.Errorf("%w: user %s of order %s is not a user", ErrInvalidSeed, order.User, order.Number)
+			}
+			for _, item := range order.Items {
+				if item.SKU == "" || item.Quantity <= 0 || item.UnitPrice < 0 {
+					return fmt.Errorf("%w: order %s has an item without a SKU, quantity or price", ErrInvalidSeed, order.Number)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Seeder applies seed files to a database
+type Seeder struct {
+	db *sql.DB
+
+	// hashPassword is replaced in tests, since hashing is deliberately slow
+	hashPassword func(password string) (string, error)
+}
+
+// NewSeeder creates a new Seeder
+func NewSeeder(db *sql.DB) *Seeder {
+	return &Seeder{
+		db:           db,
+		hashPassword: authservice.HashPassword,
+	}
+}
+
+// Apply creates the file's rows that don't exist yet, in a single transaction
+func (s *Seeder) Apply(ctx context.Context, file *File) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, role := range file.Roles {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO role (name, description) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`,
+			role.Name, role.Description); err != nil {
+			return fmt.Errorf("failed to seed role %s: %w", role.Name, err)
+		}
+	}
+
+	userIDs := make(map[string]int64, len(file.Users))
+	for _, user := range file.Users {
+		userID, err := s.seedUser(ctx, tx, user)
+		if err != nil {
+			return fmt.Errorf("failed to seed user %s: %w", user.Email, err)
+		}
+		userIDs[strings.ToLower(user.Email)] = userID
+	}
+
+	for _, tenant := range file.Tenants {
+		if err := seedTenant(ctx, tx, tenant, userIDs); err != nil {
+			return fmt.Errorf("failed to seed tenant %s: %w", tenant.Slug, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("[INFO] Seeded %d roles, %d users and %d tenants", len(file.Roles), len(file.Users), len(file.Tenants))
+	return nil
+}
+
+// seedUser creates a user with its platform roles unless a user with its email
+// exists, whose password is then left unchanged, and returns the user's ID
+func (s *Seeder) seedUser(ctx context.Context, tx *sql.Tx, user User) (int64, error) {
+	var userID int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM usr WHERE LOWER(email) = LOWER($1)`, user.Email).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		passwordHash, err := s.hashPassword(user.Password)
+		if err != nil {
+			return 0, err
+		}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO usr (email, password_hash, first_name, last_name)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, user.Email, passwordHash, user.FirstName, user.LastName).Scan(&userID)
+		if err != nil {
+			return 0, err
+		}
+	} else if err != nil {
+		return 0, err
+	}
+
+	for _, role := range user.Roles {
+		roleID, err := roleID(ctx, tx, role)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO user_role (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			userID, roleID); err != nil {
+			return 0, err
+		}
+	}
+
+	return userID, nil
+}
+
+// roleID returns the ID of the role with the given name
+func roleID(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM role WHERE name = $1`, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: role %s does not exist", ErrInvalidSeed, name)
+	}
+	return id, err
+}
+
+// seedTenant creates a tenant unless one with its slug exists, then adds its
+// members and the orders it doesn't have yet
+func seedTenant(ctx context.Context, tx *sql.Tx, tenant Tenant, userIDs map[string]int64) error {
+	var tenantID int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM tenant WHERE slug = $1`, tenant.Slug).Scan(&tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO tenant (name, slug, status)
+			VALUES ($1, $2, 'active')
+			RETURNING id
+		`, tenant.Name, tenant.Slug).Scan(&tenantID)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, member := range tenant.Members {
+		userID := userIDs[strings.ToLower(member.Email)]
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO tenant_member (tenant_id, user_id, is_default)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`, tenantID, userID, member.Default); err != nil {
+			return err
+		}
+
+		for _, role := range member.Roles {
+			roleID, err := roleID(ctx, tx, role)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO tenant_role (tenant_id, user_id, role_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+				tenantID, userID, roleID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, order := range tenant.Orders {
+		if err := seedOrder(ctx, tx, tenantID, order, userIDs[strings.ToLower(order.User)]); err != nil {
+			return fmt.Errorf("order %s: %w", order.Number, err)
+		}
+	}
+
+	return nil
+}
+
+// seedOrder creates an order with its items and first status history entry,
+// unless the tenant has an order with its number
+func seedOrder(ctx context.Context, tx *sql.Tx, tenantID int64, order Order, userID int64) error {
+	status := order.Status
+	if status == "" {
+		status = "pending"
+	}
+
+	var subtotal float64
+	for _, item := range order.Items {
+		subtotal += float64(item.Quantity) * item.UnitPrice
+	}
+	subtotal = math.Round(subtotal*100) / 100
+
+	var orderID int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO "order" (tenant_id, user_id, order_number, status, total_amount, notes, subtotal)
+		VALUES ($1, $2, $3, $4, $5, $6, $5)
+		ON CONFLICT (tenant_id, order_number) DO NOTHING
+		RETURNING order_id
+	`, tenantID, userID, order.Number, status, subtotal, order.Notes).Scan(&orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The order was seeded before
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, item := range order.Items {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO order_item (order_id, tenant_id, sku, description, quantity, unit_price)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, orderID, tenantID, item.SKU, item.Description, item.Quantity, item.UnitPrice); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO order_status_history (order_id, tenant_id, to_status, changed_by)
+		VALUES ($1, $2, $3, $4)
+	`, orderID, tenantID, status, userID)
+	return err
+}
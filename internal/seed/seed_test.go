@@ -0,0 +1,163 @@
+package seed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSeedFile writes a seed file to a temporary directory
+func writeSeedFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Development seed file", func(t *testing.T) {
+		file, err := Load("../../sql/seed/dev.yaml")
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, file.Users)
+		assert.NotEmpty(t, file.Tenants)
+	})
+
+	t.Run("Member is not a user", func(t *testing.T) {
+		path := writeSeedFile(t, `
+tenants:
+  - name: Acme
+    slug: acme
+    members:
+      - email: nobody@acme.test
+`)
+
+		_, err := Load(path)
+
+		assert.ErrorIs(t, err, ErrInvalidSeed)
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		path := writeSeedFile(t, `
+users:
+  - email: admin@acme.test
+    password: short
+    first_name: Ada
+    last_name: Admin
+`)
+
+		_, err := Load(path)
+
+		assert.ErrorIs(t, err, ErrInvalidSeed)
+	})
+
+	t.Run("Invalid slug", func(t *testing.T) {
+		path := writeSeedFile(t, `
+tenants:
+  - name: Acme
+    slug: Not A Slug
+`)
+
+		_, err := Load(path)
+
+		assert.ErrorIs(t, err, ErrInvalidSeed)
+	})
+}
+
+func TestApply(t *testing.T) {
+	file := &File{
+		Users: []User{{Email: "alice@acme.test", Password: "Alice!Passw0rd", FirstName: "Alice", LastName: "Anders", Roles: []string{"ADMIN"}}},
+		Tenants: []Tenant{{
+			Name:    "Acme",
+			Slug:    "acme",
+			Members: []Member{{Email: "alice@acme.test", Roles: []string{"TENANT_SUPER"}, Default: true}},
+			Orders: []Order{
+				{Number: "ACME-1", User: "alice@acme.test", Items: []OrderItem{{SKU: "W-1", Quantity: 3, UnitPrice: 10.10}}},
+				{Number: "ACME-2", User: "alice@acme.test"},
+			},
+		}},
+	}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	seeder := NewSeeder(db)
+	seeder.hashPassword = func(password string) (string, error) { return "hash", nil }
+
+	mock.ExpectBegin()
+
+	// Expect the user to be created with its platform role
+	mock.ExpectQuery("SELECT id FROM usr").
+		WithArgs("alice@acme.test").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("INSERT INTO usr").
+		WithArgs("alice@acme.test", "hash", "Alice", "Anders").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery("SELECT id FROM role").
+		WithArgs("ADMIN").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO user_role").
+		WithArgs(int64(7), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Expect the existing tenant to get the member
+	mock.ExpectQuery("SELECT id FROM tenant").
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO tenant_member").
+		WithArgs(int64(3), int64(7), true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id FROM role").
+		WithArgs("TENANT_SUPER").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO tenant_role").
+		WithArgs(int64(3), int64(7), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Expect the new order to be created with its items and the seeded one skipped
+	mock.ExpectQuery("INSERT INTO \"order\" (.+) ON CONFLICT \\(tenant_id, order_number\\) DO NOTHING").
+		WithArgs(int64(3), int64(7), "ACME-1", "pending", 30.3, "").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id"}).AddRow(11))
+	mock.ExpectExec("INSERT INTO order_item").
+		WithArgs(int64(11), int64(3), "W-1", "", 3, 10.10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_status_history").
+		WithArgs(int64(11), int64(3), "pending", int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO \"order\"").
+		WithArgs(int64(3), int64(7), "ACME-2", "pending", 0.0, "").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id"}))
+
+	mock.ExpectCommit()
+
+	require.NoError(t, seeder.Apply(context.Background(), file))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyUnknownRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	seeder := NewSeeder(db)
+	file := &File{Users: []User{{Email: "alice@acme.test", Roles: []string{"MISSING"}}}}
+
+	// Expect the transaction to be rolled back
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM usr").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery("SELECT id FROM role").
+		WithArgs("MISSING").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err = seeder.Apply(context.Background(), file)
+
+	assert.ErrorIs(t, err, ErrInvalidSeed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,12 +1,24 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"log"
+	"time"
 
+	"github.com/unsavory/silocore-go/internal/antivirus"
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	"github.com/unsavory/silocore-go/internal/auth/authz"
 	"github.com/unsavory/silocore-go/internal/auth/jwt"
 	authservice "github.com/unsavory/silocore-go/internal/auth/service"
+	"github.com/unsavory/silocore-go/internal/cache"
+	"github.com/unsavory/silocore-go/internal/database"
 	"github.com/unsavory/silocore-go/internal/database/transaction"
+	"github.com/unsavory/silocore-go/internal/events"
+	"github.com/unsavory/silocore-go/internal/mail"
+	"github.com/unsavory/silocore-go/internal/order/invoice"
 	orderservice "github.com/unsavory/silocore-go/internal/order/service"
+	"github.com/unsavory/silocore-go/internal/storage"
 	tenantservice "github.com/unsavory/silocore-go/internal/tenant/service"
 )
 
@@ -17,62 +29,409 @@ type Factory struct {
 	// Transaction manager
 	txManager *transaction.Manager
 
+	// Outbox dispatcher, nil if events aren't published
+	outboxDispatcher *events.OutboxDispatcher
+
 	// Auth services
 	userService         authservice.UserService
 	authService         authservice.AuthService
 	roleService         authservice.RoleService
 	registrationService authservice.RegistrationService
 	jwtService          *jwt.Service
+	authorizer          authz.Authorizer
+
+	// Role service underlying roleService, which caches the role hierarchy
+	dbRoleService *authservice.DBRoleService
+
+	// Caching services, nil if roles and memberships are not cached
+	cachingUserService         *authservice.CachingUserService
+	cachingTenantMemberService *tenantservice.CachingTenantMemberService
+	cachingIPRuleService       *tenantservice.CachingIPRuleService
+
+	// Cached responses of expensive read endpoints, nil if they are not cached
+	responseCache *cache.ResponseCache
 
 	// Tenant services
 	tenantService       tenantservice.TenantService
 	tenantMemberService tenantservice.TenantMemberService
+	invitationService   tenantservice.InvitationService
+	quotaService        tenantservice.QuotaService
+	usageService        tenantservice.UsageService
+	brandingService     tenantservice.BrandingService
+	exportService       tenantservice.ExportService
+	ownershipService    tenantservice.OwnershipService
+	apiKeyService       tenantservice.APIKeyService
+	webhookService      tenantservice.WebhookService
+	domainService       tenantservice.DomainService
+	ipRuleService       tenantservice.IPRuleService
+	cloneService        tenantservice.CloneService
+	archiveService      tenantservice.ArchiveService
+	onboardingService   tenantservice.OnboardingService
+	statsService        tenantservice.StatsService
+	reportService       tenantservice.ReportService
+
+	// Audit services
+	auditService auditservice.AuditService
 
 	// Order services
-	orderService orderservice.OrderService
+	orderService      orderservice.OrderService
+	bulkOrderService  orderservice.BulkOrderService
+	attachmentService orderservice.AttachmentService
+	commentService    orderservice.CommentService
+	tagService        orderservice.TagService
+	invoiceRenderer   invoice.Renderer
 }
 
-// NewFactory creates a new service factory
-func NewFactory(db *sql.DB, jwtConfig jwt.Config) *Factory {
-	// Create transaction manager
+// NewFactory creates a new service factory.
+// If authorizer is nil, the default role-based authorizer is used.
+// If roleCache is nil, role and membership lookups are not cached.
+// Soft deleted tenants can be restored for tenantRetention, or the default if zero.
+// Confirmation emails are sent with mailer, and tenant archives and order attachments
+// are kept in store.
+// If planLimits is nil, API requests are only limited by tenant quotas.
+// Order changes are written to the event outbox and published from it with
+// publisher, unless it is nil.
+// Order attachments are scanned with scanner, unless it is nil.
+// Requests that only read, tenant lookups and role lookups are sent to read replicas
+// by queryRouter, unless it is nil.
+// If schemaProvisioner is nil, tenants share tables isolated by row level security.
+// Otherwise each new tenant is given its own schema, which its transactions resolve
+// tables in first.
+// Responses cached in responseCache, unless it is nil, are invalidated when
+// orders or tenant memberships change.
+func NewFactory(db *sql.DB, jwtConfig jwt.Config, authorizer authz.Authorizer, roleCache cache.Cache, tenantRetention time.Duration, mailer mail.Sender, store storage.Store, planLimits tenantservice.PlanLimitSource, publisher events.Publisher, scanner antivirus.Scanner, queryRouter *database.QueryRouter, schemaProvisioner *database.SchemaProvisioner, responseCache *cache.ResponseCache) *Factory {
+	// Create transaction manager, running read-only requests on replicas. Services
+	// share it, so they see its tenant schema settings.
 	txManager := transaction.NewManager(db)
+	dbUserService := authservice.NewDBUserService(db)
+	if queryRouter != nil {
+		txManager = txManager.WithQueryRouter(queryRouter)
+		dbUserService = dbUserService.WithQueryRouter(queryRouter)
+	}
+	if schemaProvisioner != nil {
+		txManager = txManager.WithTenantSchemas()
+	}
 
 	// Create JWT service
 	jwtService := jwt.NewService(jwtConfig)
 
-	// Create user service
-	userService := authservice.NewDBUserService(db)
+	// Create audit service
+	auditService := auditservice.NewDBAuditService(db)
+
+	// Create webhook service, delivering audited changes to tenant webhooks
+	webhookService := tenantservice.NewDBWebhookService(db)
+	auditRecorder := tenantservice.NewWebhookDispatchingRecorder(auditService, webhookService)
+
+	// Create role service, recording tenant role changes in the audit log
+	dbRoleService := authservice.NewDBRoleService(db)
+	var roleService authservice.RoleService = authservice.NewAuditingRoleService(dbRoleService, auditRecorder)
+
+	// Create user service, resolving inherited roles through the role hierarchy
+	var userService authservice.UserService = authservice.NewRoleResolvingUserService(dbUserService, roleService)
+
+	// Cache role lookups, invalidating them when role assignments change
+	var cachingUserService *authservice.CachingUserService
+	if roleCache != nil {
+		cachingUserService = authservice.NewCachingUserService(userService, roleCache)
+		userService = cachingUserService
+		roleService = authservice.NewInvalidatingRoleService(roleService, cachingUserService)
+	}
+
+	// Create quota service, falling back to the API request budget of the tenant's plan
+	quotaService := tenantservice.NewDBQuotaService(db)
+	if planLimits != nil {
+		quotaService = quotaService.WithPlanLimits(planLimits)
+	}
+
+	// Create registration service, assigning the configured default roles to new
+	// users and enforcing member limits when they join by invitation
+	registrationService := authservice.NewDBRegistrationService(db).
+		WithTxManager(txManager).
+		WithDefaultRoles(authservice.DefaultRolesFromEnv()...).
+		WithMemberQuota(quotaService)
 
-	// Create role service
-	roleService := authservice.NewDBRoleService(db)
+	// Create invitation service, rejecting invitations to tenants at their member limit
+	invitationService := tenantservice.NewDBInvitationService(db).WithMemberQuota(quotaService)
 
-	// Create registration service
-	registrationService := authservice.NewDBRegistrationService(db)
+	// Create tenant service, enforcing member limits and auditing membership changes
+	dbTenantService := tenantservice.NewDBTenantService(db)
+	if tenantRetention > 0 {
+		dbTenantService = dbTenantService.WithRetention(tenantRetention)
+	}
+	if queryRouter != nil {
+		dbTenantService = dbTenantService.WithQueryRouter(queryRouter)
+	}
+	if schemaProvisioner != nil {
+		dbTenantService = dbTenantService.WithProvisioner(schemaProvisioner)
+	}
+	tenantService := tenantservice.NewAuditingTenantService(tenantservice.NewQuotaEnforcingTenantService(dbTenantService, quotaService).WithTxManager(txManager), auditRecorder)
 
-	// Create tenant service
-	tenantService := tenantservice.NewDBTenantService(db)
+	// Create tenant member service, enforcing member limits, auditing membership changes
+	// and caching membership checks
+	var tenantMemberService tenantservice.TenantMemberService = tenantservice.NewAuditingTenantMemberService(
+		tenantservice.NewQuotaEnforcingTenantMemberService(tenantservice.NewDBTenantMemberService(db), quotaService).WithTxManager(txManager),
+		auditRecorder,
+	)
+	var cachingTenantMemberService *tenantservice.CachingTenantMemberService
+	if roleCache != nil {
+		// Removing a member also removes their tenant roles, so drop cached roles too,
+		// and the tenant's cached statistics, which count its members
+		cachingTenantMemberService = tenantservice.NewCachingTenantMemberService(tenantMemberService, roleCache).
+			OnMembershipChange(func(ctx context.Context, userID int64, tenantID int64) {
+				if err := cachingUserService.InvalidateUserRoles(ctx, userID); err != nil {
+					log.Printf("[ERROR] Failed to invalidate cached roles for user ID %d: %v", userID, err)
+				}
+				if responseCache != nil {
+					if err := responseCache.InvalidateTenant(ctx, tenantID); err != nil {
+						log.Printf("[ERROR] Failed to invalidate cached responses for tenant ID %d: %v", tenantID, err)
+					}
+				}
+			})
+		tenantMemberService = cachingTenantMemberService
+	}
 
-	// Create tenant member service
-	tenantMemberService := tenantservice.NewDBTenantMemberService(db)
+	// Create authorizer
+	if authorizer == nil {
+		authorizer = authz.NewRoleAuthorizer()
+	}
 
 	// Create auth service
-	authService := authservice.NewDefaultAuthService(userService, tenantMemberService, jwtService)
+	authService := authservice.NewDefaultAuthService(userService, tenantMemberService, jwtService).WithAuthorizer(authorizer)
+
+	// Create usage service
+	usageService := tenantservice.NewDBUsageService(db)
+
+	// Create branding service
+	brandingService := tenantservice.NewDBBrandingService(db)
+
+	// Create export service, signing download links with the JWT secret
+	exportService := tenantservice.NewDBExportService(db, []byte(jwtConfig.Secret)).WithTxManager(txManager)
+
+	// Create API key service
+	apiKeyService := tenantservice.NewDBAPIKeyService(db)
+
+	// Create tenant clone service
+	cloneService := tenantservice.NewDBCloneService(db)
+	if schemaProvisioner != nil {
+		cloneService = cloneService.WithProvisioner(schemaProvisioner)
+	}
+
+	// Create tenant archive service
+	archiveService := tenantservice.NewDBArchiveService(db, store)
+
+	// Create onboarding checklist service
+	onboardingService := tenantservice.NewDBOnboardingService(db)
 
-	// Create order service
-	orderService := orderservice.NewDBOrderService(db)
+	// Create tenant statistics service
+	statsService := tenantservice.NewDBStatsService(db)
+
+	// Create platform reporting service
+	reportService := tenantservice.NewDBReportService(db).WithTxManager(txManager)
+
+	// Create custom domain service
+	domainService := tenantservice.NewDBDomainService(db)
+
+	// Create IP rule service, caching the rules checked on every request
+	var ipRuleService tenantservice.IPRuleService = tenantservice.NewDBIPRuleService(db)
+	var cachingIPRuleService *tenantservice.CachingIPRuleService
+	if roleCache != nil {
+		cachingIPRuleService = tenantservice.NewCachingIPRuleService(ipRuleService, roleCache)
+		ipRuleService = cachingIPRuleService
+	}
+
+	// Create ownership transfer service, dropping cached roles of the old and new owners
+	ownershipService := tenantservice.NewDBOwnershipService(db, mailer, auditRecorder)
+	if roleCache != nil {
+		ownershipService = ownershipService.OnRoleChange(func(ctx context.Context, userID int64, tenantID int64) {
+			if err := cachingUserService.InvalidateUserRoles(ctx, userID); err != nil {
+				log.Printf("[ERROR] Failed to invalidate cached roles for user ID %d: %v", userID, err)
+			}
+		})
+	}
+
+	// Create the event outbox, written in the transaction of each change and
+	// published by the outbox dispatcher
+	dbOrderService := orderservice.NewDBOrderService(db).WithTxManager(txManager)
+	var outboxDispatcher *events.OutboxDispatcher
+	if publisher != nil {
+		dbOrderService = dbOrderService.WithOutbox(events.NewOutbox(db).WithTxManager(txManager))
+		outboxDispatcher = events.NewOutboxDispatcher(db, publisher)
+	}
+
+	// Create order service, writing order events to the outbox, enforcing order
+	// limits, metering created orders and auditing order mutations
+	var orderService orderservice.OrderService = orderservice.NewAuditingOrderService(
+		orderservice.NewQuotaEnforcingOrderService(
+			orderservice.NewMeteringOrderService(dbOrderService, usageService),
+			quotaService,
+		).WithTxManager(txManager),
+		auditRecorder,
+	)
+
+	// Drop the tenant's cached order summaries and statistics when its orders change
+	if responseCache != nil {
+		orderService = orderservice.NewInvalidatingOrderService(orderService, responseCache)
+	}
+
+	// Create bulk order service, creating each order through the order service
+	bulkOrderService := orderservice.NewDBBulkOrderService(db, orderService).WithTxManager(txManager)
+
+	// Create order attachment service, enforcing storage limits and signing
+	// download links with the JWT secret
+	attachmentService := orderservice.NewDBAttachmentService(db, store, []byte(jwtConfig.Secret)).WithTxManager(txManager).WithStorageQuota(quotaService)
+	if scanner != nil {
+		attachmentService = attachmentService.WithScanner(scanner)
+	}
+
+	// Create order comment service
+	commentService := orderservice.NewDBCommentService(db).WithTxManager(txManager)
+
+	// Create order tag service
+	tagService := orderservice.NewDBTagService(db).WithTxManager(txManager)
+
+	// Create order invoice renderer, keeping rendered invoices in memory
+	invoiceRenderer := invoice.NewPDFRenderer(orderService, brandingService).
+		WithCache(cache.NewLRUCache(invoice.DefaultCacheSize, invoice.DefaultCacheTTL))
 
 	return &Factory{
 		db:                  db,
 		txManager:           txManager,
+		outboxDispatcher:    outboxDispatcher,
 		userService:         userService,
 		authService:         authService,
 		roleService:         roleService,
 		registrationService: registrationService,
 		jwtService:          jwtService,
+		authorizer:          authorizer,
+		dbRoleService:       dbRoleService,
+
+		cachingUserService:         cachingUserService,
+		cachingTenantMemberService: cachingTenantMemberService,
+		cachingIPRuleService:       cachingIPRuleService,
+		responseCache:              responseCache,
+
 		tenantService:       tenantService,
 		tenantMemberService: tenantMemberService,
+		invitationService:   invitationService,
+		quotaService:        quotaService,
+		usageService:        usageService,
+		brandingService:     brandingService,
+		exportService:       exportService,
+		ownershipService:    ownershipService,
+		apiKeyService:       apiKeyService,
+		webhookService:      webhookService,
+		domainService:       domainService,
+		ipRuleService:       ipRuleService,
+		cloneService:        cloneService,
+		archiveService:      archiveService,
+		onboardingService:   onboardingService,
+		statsService:        statsService,
+		reportService:       reportService,
+		auditService:        auditService,
 		orderService:        orderService,
+		bulkOrderService:    bulkOrderService,
+		attachmentService:   attachmentService,
+		commentService:      commentService,
+		tagService:          tagService,
+		invoiceRenderer:     invoiceRenderer,
+	}
+}
+
+// RegisterHierarchyInvalidation reloads the role hierarchy cached by this
+// instance when listener receives changes to roles or their inheritance, made by
+// any server instance. It is cached in process whatever the cache backend.
+func (f *Factory) RegisterHierarchyInvalidation(listener *cache.InvalidationListener) {
+	invalidateHierarchy := func(context.Context, cache.Change) {
+		f.dbRoleService.InvalidateHierarchy()
 	}
+
+	listener.
+		OnChange("role", invalidateHierarchy).
+		OnChange("role_inheritance", invalidateHierarchy).
+		OnReconnect(func(ctx context.Context) {
+			invalidateHierarchy(ctx, cache.Change{})
+		})
+}
+
+// RegisterCacheInvalidation invalidates cached roles, memberships and IP rules,
+// and the cached responses of tenants whose memberships change, when listener
+// receives changes to them, made by any server instance. It does nothing if roles and
+// memberships are not cached.
+func (f *Factory) RegisterCacheInvalidation(listener *cache.InvalidationListener) {
+	if f.cachingUserService == nil {
+		return
+	}
+
+	invalidateUserRoles := func(ctx context.Context, change cache.Change) {
+		if change.UserID == nil {
+			return
+		}
+		if err := f.cachingUserService.InvalidateUserRoles(ctx, *change.UserID); err != nil {
+			log.Printf("[ERROR] Failed to invalidate cached roles for user ID %d: %v", *change.UserID, err)
+		}
+	}
+	invalidateAllRoles := func(ctx context.Context, _ cache.Change) {
+		if err := f.cachingUserService.InvalidateAllRoles(ctx); err != nil {
+			log.Printf("[ERROR] Failed to invalidate cached roles: %v", err)
+		}
+	}
+	invalidateMembership := func(ctx context.Context, change cache.Change) {
+		if change.UserID == nil || change.TenantID == nil {
+			return
+		}
+		if err := f.cachingTenantMemberService.InvalidateMembership(ctx, *change.UserID, *change.TenantID); err != nil {
+			log.Printf("[ERROR] Failed to invalidate cached membership for user ID %d, tenant ID %d: %v", *change.UserID, *change.TenantID, err)
+		}
+	}
+
+	if f.responseCache != nil {
+		listener.OnChange("tenant_member", func(ctx context.Context, change cache.Change) {
+			if change.TenantID == nil {
+				return
+			}
+			if err := f.responseCache.InvalidateTenant(ctx, *change.TenantID); err != nil {
+				log.Printf("[ERROR] Failed to invalidate cached responses for tenant ID %d: %v", *change.TenantID, err)
+			}
+		})
+	}
+
+	invalidateIPRules := func(ctx context.Context, change cache.Change) {
+		if change.TenantID == nil {
+			return
+		}
+		if err := f.cachingIPRuleService.InvalidateIPRules(ctx, *change.TenantID); err != nil {
+			log.Printf("[ERROR] Failed to invalidate cached IP rules for tenant ID %d: %v", *change.TenantID, err)
+		}
+	}
+
+	listener.
+		OnChange("user_role", invalidateUserRoles).
+		OnChange("tenant_role", invalidateUserRoles).
+		OnChange("tenant_member", invalidateMembership).
+		OnChange("tenant_member", invalidateUserRoles).
+		OnChange("role", invalidateAllRoles).
+		OnChange("role_inheritance", invalidateAllRoles).
+		OnChange("tenant_ip_rule", invalidateIPRules).
+		OnReconnect(func(ctx context.Context) {
+			invalidateAllRoles(ctx, cache.Change{})
+			if err := f.cachingTenantMemberService.InvalidateAllMemberships(ctx); err != nil {
+				log.Printf("[ERROR] Failed to invalidate cached memberships: %v", err)
+			}
+			if err := f.cachingIPRuleService.InvalidateAllIPRules(ctx); err != nil {
+				log.Printf("[ERROR] Failed to invalidate cached IP rules: %v", err)
+			}
+			if f.responseCache != nil {
+				if err := f.responseCache.InvalidateAll(ctx); err != nil {
+					log.Printf("[ERROR] Failed to invalidate cached responses: %v", err)
+				}
+			}
+		})
+}
+
+// ResponseCache returns the cache of responses of expensive read endpoints, or
+// nil if they are not cached
+func (f *Factory) ResponseCache() *cache.ResponseCache {
+	return f.responseCache
 }
 
 // UserService returns the user service
@@ -90,6 +449,11 @@ func (f *Factory) RoleService() authservice.RoleService {
 	return f.roleService
 }
 
+// Authorizer returns the authorizer used for access decisions
+func (f *Factory) Authorizer() authz.Authorizer {
+	return f.authorizer
+}
+
 // RegistrationService returns the registration service
 func (f *Factory) RegistrationService() authservice.RegistrationService {
 	return f.registrationService
@@ -110,16 +474,127 @@ func (f *Factory) TenantMemberService() tenantservice.TenantMemberService {
 	return f.tenantMemberService
 }
 
+// QuotaService returns the tenant quota service
+func (f *Factory) QuotaService() tenantservice.QuotaService {
+	return f.quotaService
+}
+
+// UsageService returns the tenant usage metering service
+func (f *Factory) UsageService() tenantservice.UsageService {
+	return f.usageService
+}
+
+// BrandingService returns the tenant branding service
+func (f *Factory) BrandingService() tenantservice.BrandingService {
+	return f.brandingService
+}
+
+// ExportService returns the tenant data export service
+func (f *Factory) ExportService() tenantservice.ExportService {
+	return f.exportService
+}
+
+// OwnershipService returns the tenant ownership transfer service
+func (f *Factory) OwnershipService() tenantservice.OwnershipService {
+	return f.ownershipService
+}
+
+// APIKeyService returns the tenant API key service
+func (f *Factory) APIKeyService() tenantservice.APIKeyService {
+	return f.apiKeyService
+}
+
+// WebhookService returns the tenant webhook service
+func (f *Factory) WebhookService() tenantservice.WebhookService {
+	return f.webhookService
+}
+
+// DomainService returns the tenant custom domain service
+func (f *Factory) DomainService() tenantservice.DomainService {
+	return f.domainService
+}
+
+// IPRuleService returns the tenant IP rule service
+func (f *Factory) IPRuleService() tenantservice.IPRuleService {
+	return f.ipRuleService
+}
+
+// CloneService returns the tenant clone service
+func (f *Factory) CloneService() tenantservice.CloneService {
+	return f.cloneService
+}
+
+// ArchiveService returns the tenant archive service
+func (f *Factory) ArchiveService() tenantservice.ArchiveService {
+	return f.archiveService
+}
+
+// OnboardingService returns the tenant onboarding service
+func (f *Factory) OnboardingService() tenantservice.OnboardingService {
+	return f.onboardingService
+}
+
+// StatsService returns the tenant statistics service
+func (f *Factory) StatsService() tenantservice.StatsService {
+	return f.statsService
+}
+
+// ReportService returns the platform reporting service
+func (f *Factory) ReportService() tenantservice.ReportService {
+	return f.reportService
+}
+
+// AuditService returns the tenant audit log service
+func (f *Factory) AuditService() auditservice.AuditService {
+	return f.auditService
+}
+
+// InvitationService returns the tenant invitation service
+func (f *Factory) InvitationService() tenantservice.InvitationService {
+	return f.invitationService
+}
+
 // OrderService returns the order service
 func (f *Factory) OrderService() orderservice.OrderService {
 	return f.orderService
 }
 
+// BulkOrderService returns the bulk order creation service
+func (f *Factory) BulkOrderService() orderservice.BulkOrderService {
+	return f.bulkOrderService
+}
+
+// AttachmentService returns the order attachment service
+func (f *Factory) AttachmentService() orderservice.AttachmentService {
+	return f.attachmentService
+}
+
+// CommentService returns the order comment service
+func (f *Factory) CommentService() orderservice.CommentService {
+	return f.commentService
+}
+
+// TagService returns the order tag service
+func (f *Factory) TagService() orderservice.TagService {
+	return f.tagService
+}
+
+// InvoiceRenderer returns the order invoice renderer
+func (f *Factory) InvoiceRenderer() invoice.Renderer {
+	return f.invoiceRenderer
+}
+
 // TransactionManager returns the transaction manager
 func (f *Factory) TransactionManager() *transaction.Manager {
 	return f.txManager
 }
 
+// OutboxDispatcher returns the dispatcher publishing the event outbox, or nil if
+// events aren't published
+func (f *Factory) OutboxDispatcher() *events.OutboxDispatcher {
+	return f.outboxDispatcher
+}
+
 // DB returns the database connection
 func (f *Factory) DB() *sql.DB {
 	return f.db
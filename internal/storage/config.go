@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+const (
+	// Default values
+	defaultDir      = "data/storage"
+	defaultS3Region = "us-east-1"
+
+	// Environment variable names
+	envStorageDir        = "STORAGE_DIR"
+	envS3Endpoint        = "S3_ENDPOINT"
+	envS3Bucket          = "S3_BUCKET"
+	envS3Region          = "S3_REGION"
+	envS3AccessKeyID     = "S3_ACCESS_KEY_ID"
+	envS3SecretAccessKey = "S3_SECRET_ACCESS_KEY"
+)
+
+// Config holds configuration for object storage
+type Config struct {
+	Dir               string
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+}
+
+// LoadConfig loads object storage configuration from environment variables
+func LoadConfig() (Config, error) {
+	config := Config{
+		Dir:               os.Getenv(envStorageDir),
+		S3Endpoint:        os.Getenv(envS3Endpoint),
+		S3Bucket:          os.Getenv(envS3Bucket),
+		S3Region:          os.Getenv(envS3Region),
+		S3AccessKeyID:     os.Getenv(envS3AccessKeyID),
+		S3SecretAccessKey: os.Getenv(envS3SecretAccessKey),
+	}
+
+	if config.Dir == "" {
+		config.Dir = defaultDir
+	}
+	if config.S3Region == "" {
+		config.S3Region = defaultS3Region
+	}
+	if config.S3Endpoint == "" {
+		config.S3Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.S3Region)
+	}
+
+	if config.S3Bucket != "" && (config.S3AccessKeyID == "" || config.S3SecretAccessKey == "") {
+		return Config{}, fmt.Errorf("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required when S3_BUCKET is set")
+	}
+
+	return config, nil
+}
+
+// New creates the Store selected by the configuration.
+// Objects are kept on the local filesystem when S3_BUCKET is not set.
+func New(config Config) Store {
+	if config.S3Bucket == "" {
+		log.Printf("[INFO] S3_BUCKET is not set, objects will be stored in %s", config.Dir)
+		return NewFileStore(config.Dir)
+	}
+
+	log.Printf("[INFO] Storing objects in bucket %s at %s", config.S3Bucket, config.S3Endpoint)
+	return NewS3Store(config.S3Endpoint, config.S3Bucket, config.S3Region, config.S3AccessKeyID, config.S3SecretAccessKey)
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Store keeps objects in a bucket of an S3 compatible object storage service.
+// Requests use path-style URLs and are signed with AWS Signature Version 4.
+type S3Store struct {
+	endpoint        string
+	bucket          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	client          *http.Client
+	now             func() time.Time
+}
+
+// Ensure S3Store implements Store
+var _ Store = (*S3Store)(nil)
+
+// NewS3Store creates a new S3Store for a bucket at endpoint, e.g. https://s3.eu-west-1.amazonaws.com
+func NewS3Store(endpoint string, bucket string, region string, accessKeyID string, secretAccessKey string) *S3Store {
+	return &S3Store{
+		endpoint:        strings.TrimSuffix(endpoint, "/"),
+		bucket:          bucket,
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		client:          &http.Client{Timeout: 60 * time.Second},
+		now:             time.Now,
+	}
+}
+
+// Put uploads an object
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(resp, http.MethodPut, key)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, s.responseError(resp, http.MethodGet, key)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Delete removes an object. S3 reports success for missing objects too.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s.responseError(resp, http.MethodDelete, key)
+	}
+	return nil
+}
+
+// do sends a signed request for an object
+func (s *S3Store) do(ctx context.Context, method string, key string, body []byte) (*http.Response, error) {
+	path := "/" + uriEncode(s.bucket) + "/" + uriEncode(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", key, err)
+	}
+	// Keep the escaped path as signed
+	req.URL.RawPath = path
+	req.ContentLength = int64(len(body))
+
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %s: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to a request
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, signature))
+}
+
+// responseError describes an unexpected response, including the start of its body
+func (s *S3Store) responseError(resp *http.Response, method string, key string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("failed to %s %s: unexpected status %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// uriEncode percent-encodes everything but unreserved characters and slashes, as
+// required for canonical request paths
+func uriEncode(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// sha256Hex returns the hex SHA-256 hash of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Store keeps objects by key, e.g. tenant archives and order attachments
+type Store interface {
+	// Put stores an object, replacing any object with the same key
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get retrieves an object
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// FileStore keeps objects as files in a directory. It is used in development when
+// no object storage bucket is configured.
+type FileStore struct {
+	dir string
+}
+
+// Ensure FileStore implements Store
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates a new FileStore keeping objects under dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Put writes an object to its file, creating directories as needed
+func (s *FileStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Get reads an object from its file
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	return data, nil
+}
+
+// Delete removes an object's file
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// path returns the file of an object, rejecting keys that escape the directory
+func (s *FileStore) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if key == "" || strings.HasSuffix(key, "/") || cleaned != "/"+key {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(cleaned)), nil
+}
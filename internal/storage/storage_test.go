@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	ctx := context.Background()
+
+	t.Run("Round trip", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "tenants/1/archive.json.gz", []byte("data")))
+
+		data, err := store.Get(ctx, "tenants/1/archive.json.gz")
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), data)
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		_, err := store.Get(ctx, "tenants/2/archive.json.gz")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "tenants/3/archive.json.gz", []byte("data")))
+
+		require.NoError(t, store.Delete(ctx, "tenants/3/archive.json.gz"))
+		_, err := store.Get(ctx, "tenants/3/archive.json.gz")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, store.Delete(ctx, "tenants/3/archive.json.gz"))
+	})
+
+	t.Run("Key outside the directory", func(t *testing.T) {
+		err := store.Put(ctx, "../escape", []byte("data"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestS3Store(t *testing.T) {
+	objects := map[string][]byte{}
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	store := NewS3Store(server.URL, "archives", "eu-west-1", "AKID", "secret")
+	store.now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	t.Run("Signed round trip", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "tenants/1/archive.json.gz", []byte("data")))
+		assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/20250301/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+		assert.Contains(t, objects, "/archives/tenants/1/archive.json.gz")
+
+		data, err := store.Get(ctx, "tenants/1/archive.json.gz")
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), data)
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		_, err := store.Get(ctx, "tenants/2/archive.json.gz")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "tenants/3/archive.json.gz", []byte("data")))
+
+		require.NoError(t, store.Delete(ctx, "tenants/3/archive.json.gz"))
+
+		assert.NotContains(t, objects, "/archives/tenants/3/archive.json.gz")
+	})
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/auth/authz"
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// API key errors
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrInvalidAPIKey is returned when a presented key is unknown, revoked or expired
+	ErrInvalidAPIKey = errors.New("invalid API key")
+)
+
+const (
+	// APIKeyPrefix starts every API key, so keys can be told apart from access tokens
+	APIKeyPrefix = "sck_"
+
+	// apiKeySize is the number of random bytes in an API key
+	apiKeySize = 32
+
+	// apiKeyDisplayLength is how much of a key is kept to identify it in listings
+	apiKeyDisplayLength = len(APIKeyPrefix) + 8
+)
+
+// APIKey is a key that authenticates requests to a tenant as the user who created
+// it, limited to its scopes
+type APIKey struct {
+	ID       int64  `json:"id"`
+	TenantID int64  `json:"tenant_id"`
+	Name     string `json:"name"`
+	// Prefix is the start of the key, for telling keys apart
+	Prefix string `json:"prefix"`
+	// Scopes are the "resource:action" permissions the key is limited to
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  int64      `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyService defines the interface for tenant API key operations
+type APIKeyService interface {
+	// CreateAPIKey creates an API key and returns it with the key itself.
+	// The key is only available at creation time.
+	CreateAPIKey(ctx context.Context, tenantID int64, createdBy int64, name string, scopes []string, expiresAt *time.Time) (*APIKey, string, error)
+
+	// ListAPIKeys retrieves a tenant's API keys, including revoked and expired keys
+	ListAPIKeys(ctx context.Context, tenantID int64) ([]APIKey, error)
+
+	// RevokeAPIKey revokes an API key so it can no longer be used
+	RevokeAPIKey(ctx context.Context, tenantID int64, keyID int64) error
+
+	// AuthenticateAPIKey looks up an active API key and records its use
+	AuthenticateAPIKey(ctx context.Context, key string) (*APIKey, error)
+}
+
+// DBAPIKeyService implements APIKeyService using a database
+type DBAPIKeyService struct {
+	db *sql.DB
+}
+
+// Ensure DBAPIKeyService implements APIKeyService
+var _ APIKeyService = (*DBAPIKeyService)(nil)
+
+// NewDBAPIKeyService creates a new DBAPIKeyService
+func NewDBAPIKeyService(db *sql.DB) *DBAPIKeyService {
+	return &DBAPIKeyService{db: db}
+}
+
+// CreateAPIKey creates an API key and returns it with the key itself
+func (s *DBAPIKeyService) CreateAPIKey(ctx context.Context, tenantID int64, createdBy int64, name string, scopes []string, expiresAt *time.Time) (*APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidInput)
+	}
+	for _, scope := range scopes {
+		if !authz.Permission(scope).Valid() {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidInput, scope)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expiry must be in the future", ErrInvalidInput)
+	}
+
+	keyBytes := make([]byte, apiKeySize)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(keyBytes)
+
+	apiKey := &APIKey{
+		TenantID:  tenantID,
+		Name:      name,
+		Prefix:    key[:apiKeyDisplayLength],
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	}
+
+	query := `
+		INSERT INTO tenant_api_key (tenant_id, name, key_prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID, name, apiKey.Prefix, HashToken(key), database.Array(scopes), createdBy, expiresAt).
+		Scan(&apiKey.ID, &apiKey.CreatedAt)
+	if err != nil {
+		log.Printf("[ERROR] Database error when creating API key for tenant %d: %v", tenantID, err)
+		return nil, "", fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] API key %d created for tenant %d by user %d", apiKey.ID, tenantID, createdBy)
+	return apiKey, key, nil
+}
+
+// ListAPIKeys retrieves a tenant's API keys, newest first
+func (s *DBAPIKeyService) ListAPIKeys(ctx context.Context, tenantID int64) ([]APIKey, error) {
+	query := `
+		SELECT id, tenant_id, name, key_prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at
+		FROM tenant_api_key
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := transaction.QuerierFor(ctx, s.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	apiKeys := []APIKey{}
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		apiKeys = append(apiKeys, *apiKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return apiKeys, nil
+}
+
+// RevokeAPIKey revokes an API key so it can no longer be used
+func (s *DBAPIKeyService) RevokeAPIKey(ctx context.Context, tenantID int64, keyID int64) error {
+	result, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx,
+		"UPDATE tenant_api_key SET revoked_at = NOW() WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL",
+		keyID, tenantID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	log.Printf("[INFO] API key %d revoked for tenant %d", keyID, tenantID)
+	return nil
+}
+
+// AuthenticateAPIKey looks up an active API key and records its use
+func (s *DBAPIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*APIKey, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	query := `
+		UPDATE tenant_api_key SET last_used_at = NOW()
+		WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING id, tenant_id, name, key_prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at
+	`
+
+	apiKey, err := scanAPIKey(s.db.QueryRowContext(ctx, query, HashToken(key)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return apiKey, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAPIKey scans an API key row
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var apiKey APIKey
+	var scopes database.StringArray
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(
+		&apiKey.ID,
+		&apiKey.TenantID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&scopes,
+		&apiKey.CreatedBy,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&apiKey.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	apiKey.Scopes = []string(scopes)
+	if expiresAt.Valid {
+		apiKey.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		apiKey.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		apiKey.RevokedAt = &revokedAt.Time
+	}
+	return &apiKey, nil
+}
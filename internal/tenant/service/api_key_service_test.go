@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores only the key hash", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBAPIKeyService(db)
+
+		// Setup mock expectations
+		var storedHash string
+		mock.ExpectQuery("INSERT INTO tenant_api_key").
+			WithArgs(int64(1), "CI", sqlmock.AnyArg(), hashArg{hash: &storedHash}, database.Array([]string{"orders:read"}), int64(7), nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+		// Execute
+		apiKey, key, err := service.CreateAPIKey(ctx, 1, 7, " CI ", []string{"orders:read"}, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), apiKey.ID)
+		assert.Equal(t, "CI", apiKey.Name)
+		assert.True(t, strings.HasPrefix(key, APIKeyPrefix))
+		assert.True(t, strings.HasPrefix(key, apiKey.Prefix))
+		assert.Equal(t, HashToken(key), storedHash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		service := NewDBAPIKeyService(nil)
+
+		// Execute
+		_, _, err := service.CreateAPIKey(ctx, 1, 7, "CI", []string{"admin:access"}, nil)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("No scopes", func(t *testing.T) {
+		service := NewDBAPIKeyService(nil)
+
+		// Execute
+		_, _, err := service.CreateAPIKey(ctx, 1, 7, "CI", nil, nil)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBAPIKeyService(db)
+	ctx := context.Background()
+	key := APIKeyPrefix + "secret"
+	columns := []string{"id", "tenant_id", "name", "key_prefix", "scopes", "created_by", "expires_at", "last_used_at", "revoked_at", "created_at"}
+
+	t.Run("Active key", func(t *testing.T) {
+		// Setup mock expectations
+		now := time.Now()
+		mock.ExpectQuery("UPDATE tenant_api_key SET last_used_at = NOW\\(\\)").
+			WithArgs(HashToken(key)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 1, "CI", "sck_secret", "{orders:read,members:read}", 7, nil, now, nil, now))
+
+		// Execute
+		apiKey, err := service.AuthenticateAPIKey(ctx, key)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), apiKey.TenantID)
+		assert.Equal(t, int64(7), apiKey.CreatedBy)
+		assert.Equal(t, []string{"orders:read", "members:read"}, apiKey.Scopes)
+		assert.NotNil(t, apiKey.LastUsedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revoked, expired or unknown key", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("UPDATE tenant_api_key SET last_used_at").
+			WithArgs(HashToken(key)).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		_, err := service.AuthenticateAPIKey(ctx, key)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidAPIKey))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not an API key", func(t *testing.T) {
+		// Execute
+		_, err := service.AuthenticateAPIKey(ctx, "eyJhbGciOiJIUzI1NiJ9")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidAPIKey))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBAPIKeyService(db)
+	ctx := context.Background()
+
+	t.Run("Key is revoked", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("UPDATE tenant_api_key SET revoked_at = NOW\\(\\) WHERE id = \\$1 AND tenant_id = \\$2 AND revoked_at IS NULL").
+			WithArgs(int64(3), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := service.RevokeAPIKey(ctx, 1, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Key from another tenant", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("UPDATE tenant_api_key SET revoked_at").
+			WithArgs(int64(3), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute
+		err := service.RevokeAPIKey(ctx, 2, 3)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrAPIKeyNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,429 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/storage"
+)
+
+// ErrArchiveNotFound is returned when an archived tenant has no archive to restore
+var ErrArchiveNotFound = errors.New("archive not found")
+
+// archiveFormatVersion is written to each archive so the format can change later
+const archiveFormatVersion = 1
+
+// archivedTables hold the tenant data moved to object storage when a tenant is
+// archived, in the order they are restored. They are removed in reverse order, so
+// order items, status history, attachments, comments and tags are read before
+// deleting their orders cascades to them. Archived orders are included. Attachment files stay in object storage.
+// Settings, members and roles stay in the database so the tenant can still be
+// browsed.
+var archivedTables = []struct {
+	name  string
+	table string
+}{
+	{"order", `"order"`},
+	{"order_item", "order_item"},
+	{"order_status_history", "order_status_history"},
+	{"order_attachment", "order_attachment"},
+	{"order_comment", "order_comment"},
+	{"order_tag", "order_tag"},
+	{"order_archive", "order_archive"},
+	{"audit_log", "audit_log"},
+	{"tenant_usage", "tenant_usage"},
+	{"tenant_usage_user", "tenant_usage_user"},
+}
+
+// TenantArchive is a copy of a tenant's data in object storage
+type TenantArchive struct {
+	ID         int64  `json:"id"`
+	TenantID   int64  `json:"tenant_id"`
+	StorageKey string `json:"storage_key"`
+	SizeBytes  int64  `json:"size_bytes"`
+	// Rows is the number of rows archived from each table
+	Rows       map[string]int64 `json:"rows"`
+	CreatedAt  time.Time        `json:"created_at"`
+	RestoredAt *time.Time       `json:"restored_at,omitempty"`
+}
+
+// archiveDocument is the gzipped JSON document stored for an archive
+type archiveDocument struct {
+	Version    int                                 `json:"version"`
+	TenantID   int64                               `json:"tenant_id"`
+	ArchivedAt time.Time                           `json:"archived_at"`
+	Tables     map[string][]map[string]interface{} `json:"tables"`
+}
+
+// ArchiveService defines the interface for archiving tenants to object storage
+type ArchiveService interface {
+	// ArchiveTenant moves an active or suspended tenant's data to object storage
+	// and marks the tenant archived, which makes it read-only
+	ArchiveTenant(ctx context.Context, tenantID int64) (*TenantArchive, error)
+
+	// RestoreArchivedTenant re-imports an archived tenant's latest archive and
+	// makes the tenant active again
+	RestoreArchivedTenant(ctx context.Context, tenantID int64) (*TenantArchive, error)
+
+	// ListArchives retrieves a tenant's archives, newest first
+	ListArchives(ctx context.Context, tenantID int64) ([]TenantArchive, error)
+}
+
+// DBArchiveService implements ArchiveService using a database and an object store
+type DBArchiveService struct {
+	db    *sql.DB
+	store storage.Store
+	now   func() time.Time
+}
+
+// Ensure DBArchiveService implements ArchiveService
+var _ ArchiveService = (*DBArchiveService)(nil)
+
+// NewDBArchiveService creates a new DBArchiveService keeping archives in store
+func NewDBArchiveService(db *sql.DB, store storage.Store) *DBArchiveService {
+	return &DBArchiveService{
+		db:    db,
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// ArchiveTenant moves the tenant's data to object storage in a single transaction.
+// The rows are deleted as they are read, so nothing written meanwhile is lost, and
+// the deletion is only committed once the archive is stored.
+func (s *DBArchiveService) ArchiveTenant(ctx context.Context, tenantID int64) (*TenantArchive, error) {
+	// Start a transaction to ensure atomicity
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	status, err := lockTenantStatus(ctx, tx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if status != TenantStatusActive && status != TenantStatusSuspended {
+		return nil, fmt.Errorf("%w: tenant is %s", ErrInvalidTenantStatus, status)
+	}
+
+	archivedAt := s.now().UTC()
+	document := archiveDocument{
+		Version:    archiveFormatVersion,
+		TenantID:   tenantID,
+		ArchivedAt: archivedAt,
+		Tables:     make(map[string][]map[string]interface{}, len(archivedTables)),
+	}
+	archive := &TenantArchive{
+		TenantID:   tenantID,
+		StorageKey: fmt.Sprintf("tenants/%d/archive-%s.json.gz", tenantID, archivedAt.Format("20060102T150405Z")),
+		Rows:       make(map[string]int64, len(archivedTables)),
+	}
+
+	for i := len(archivedTables) - 1; i >= 0; i-- {
+		archived := archivedTables[i]
+		rows, err := deleteArchivedRows(ctx, tx, archived.table, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to archive %s: %v", ErrDBOperation, archived.name, err)
+		}
+		document.Tables[archived.name] = rows
+		archive.Rows[archived.name] = int64(len(rows))
+	}
+
+	data, err := encodeArchive(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode archive of tenant %d: %w", tenantID, err)
+	}
+	archive.SizeBytes = int64(len(data))
+
+	if err := s.store.Put(ctx, archive.StorageKey, data); err != nil {
+		return nil, fmt.Errorf("failed to store archive of tenant %d: %w", tenantID, err)
+	}
+
+	rowCounts, err := json.Marshal(archive.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode archive row counts: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tenant_archive (tenant_id, storage_key, size_bytes, row_counts)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, tenantID, archive.StorageKey, archive.SizeBytes, rowCounts).Scan(&archive.ID, &archive.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE tenant SET status = $1, updated_at = NOW() WHERE id = $2", TenantStatusArchived, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Commit the transaction. If this fails the stored archive is left unused.
+	if err := tx.Commit(); err != nil {
+		log.Printf("[WARN] Archive %s of tenant %d was stored but not recorded", archive.StorageKey, tenantID)
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Tenant %d archived to %s (%d bytes): %v", tenantID, archive.StorageKey, archive.SizeBytes, archive.Rows)
+	return archive, nil
+}
+
+// RestoreArchivedTenant re-imports the tenant's latest archive in a single
+// transaction. The archive object is kept.
+func (s *DBArchiveService) RestoreArchivedTenant(ctx context.Context, tenantID int64) (*TenantArchive, error) {
+	// Start a transaction to ensure atomicity
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	status, err := lockTenantStatus(ctx, tx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if status != TenantStatusArchived {
+		return nil, fmt.Errorf("%w: tenant is %s", ErrInvalidTenantStatus, status)
+	}
+
+	archive, err := scanTenantArchive(tx.QueryRowContext(ctx, `
+		SELECT id, tenant_id, storage_key, size_bytes, row_counts, created_at, restored_at
+		FROM tenant_archive
+		WHERE tenant_id = $1 AND restored_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, tenantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrArchiveNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	data, err := s.store.Get(ctx, archive.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s of tenant %d: %w", archive.StorageKey, tenantID, err)
+	}
+
+	document, err := decodeArchive(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode archive %s of tenant %d: %w", archive.StorageKey, tenantID, err)
+	}
+	if document.TenantID != tenantID {
+		return nil, fmt.Errorf("archive %s belongs to tenant %d, not %d", archive.StorageKey, document.TenantID, tenantID)
+	}
+
+	for _, archived := range archivedTables {
+		for _, row := range document.Tables[archived.name] {
+			// Rows are always restored into the archived tenant
+			row["tenant_id"] = tenantID
+			if err := insertArchivedRow(ctx, tx, archived.table, row); err != nil {
+				return nil, fmt.Errorf("%w: failed to restore %s: %v", ErrDBOperation, archived.name, err)
+			}
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, "UPDATE tenant_archive SET restored_at = NOW() WHERE id = $1 RETURNING restored_at", archive.ID).
+		Scan(&archive.RestoredAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE tenant SET status = $1, updated_at = NOW() WHERE id = $2", TenantStatusActive, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Tenant %d restored from archive %s", tenantID, archive.StorageKey)
+	return archive, nil
+}
+
+// ListArchives retrieves a tenant's archives, newest first
+func (s *DBArchiveService) ListArchives(ctx context.Context, tenantID int64) ([]TenantArchive, error) {
+	query := `
+		SELECT id, tenant_id, storage_key, size_bytes, row_counts, created_at, restored_at
+		FROM tenant_archive
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	archives := []TenantArchive{}
+	for rows.Next() {
+		archive, err := scanTenantArchive(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		archives = append(archives, *archive)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return archives, nil
+}
+
+// lockTenantStatus locks a tenant that hasn't been deleted for the rest of the
+// transaction and returns its status
+func lockTenantStatus(ctx context.Context, tx *sql.Tx, tenantID int64) (TenantStatus, error) {
+	var status TenantStatus
+	err := tx.QueryRowContext(ctx, "SELECT status FROM tenant WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", tenantID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrTenantNotFound
+		}
+		return "", fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	return status, nil
+}
+
+// deleteArchivedRows deletes a tenant's rows from a table and returns them
+func deleteArchivedRows(ctx context.Context, tx *sql.Tx, table string, tenantID int64) ([]map[string]interface{}, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE tenant_id = $1 RETURNING *", table), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			record[column] = archiveValue(values[i])
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// insertArchivedRow inserts an archived row into a table
+func insertArchivedRow(ctx context.Context, tx *sql.Tx, table string, row map[string]interface{}) error {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		quoted[i] = `"` + strings.ReplaceAll(column, `"`, `""`) + `"`
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = row[column]
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// archiveValue converts a database value to a JSON value that can be inserted again
+func archiveValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// encodeArchive writes an archive document as gzipped JSON
+func encodeArchive(document archiveDocument) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(document); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeArchive reads a gzipped JSON archive document. Numbers are kept as
+// json.Number so IDs and amounts are restored exactly.
+func decodeArchive(data []byte) (*archiveDocument, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(zr, 1<<30))
+	decoder.UseNumber()
+
+	var document archiveDocument
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if document.Version != archiveFormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", document.Version)
+	}
+	return &document, nil
+}
+
+// scanTenantArchive scans a tenant_archive row
+func scanTenantArchive(row rowScanner) (*TenantArchive, error) {
+	var archive TenantArchive
+	var rowCounts []byte
+	var restoredAt sql.NullTime
+	if err := row.Scan(
+		&archive.ID,
+		&archive.TenantID,
+		&archive.StorageKey,
+		&archive.SizeBytes,
+		&rowCounts,
+		&archive.CreatedAt,
+		&restoredAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rowCounts, &archive.Rows); err != nil {
+		return nil, err
+	}
+	if restoredAt.Valid {
+		archive.RestoredAt = &restoredAt.Time
+	}
+	return &archive, nil
+}
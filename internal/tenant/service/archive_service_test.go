@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unsavory/silocore-go/internal/storage"
+)
+
+var tenantArchiveColumns = []string{"id", "tenant_id", "storage_key", "size_bytes", "row_counts", "created_at", "restored_at"}
+
+func TestArchiveTenant(t *testing.T) {
+	ctx := context.Background()
+	archivedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Active tenant", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		store := storage.NewFileStore(t.TempDir())
+		service := NewDBArchiveService(db, store)
+		service.now = func() time.Time { return archivedAt }
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM tenant WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+		mock.ExpectQuery("DELETE FROM tenant_usage_user").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}))
+		mock.ExpectQuery("DELETE FROM tenant_usage ").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}))
+		mock.ExpectQuery("DELETE FROM audit_log").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id"}))
+		mock.ExpectQuery("DELETE FROM order_archive").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"order_id", "tenant_id"}))
+		mock.ExpectQuery("DELETE FROM order_tag").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"order_id", "tag"}))
+		mock.ExpectQuery("DELETE FROM order_comment").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"comment_id", "order_id"}))
+		mock.ExpectQuery("DELETE FROM order_attachment").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"attachment_id", "order_id"}))
+		mock.ExpectQuery("DELETE FROM order_status_history").WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"history_id", "order_id"}))
+		mock.ExpectQuery("DELETE FROM order_item").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"item_id", "order_id", "tenant_id", "sku"}).AddRow(11, 8, 1, []byte("SKU-1")))
+		mock.ExpectQuery("DELETE FROM \"order\" WHERE tenant_id = \\$1 RETURNING \\*").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "tenant_id", "status", "created_at"}).
+				AddRow(7, 1, []byte("pending"), archivedAt).
+				AddRow(8, 1, []byte("shipped"), archivedAt))
+		mock.ExpectQuery("INSERT INTO tenant_archive").
+			WithArgs(int64(1), "tenants/1/archive-20250301T120000Z.json.gz", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, archivedAt))
+		mock.ExpectExec("UPDATE tenant SET status = \\$1").
+			WithArgs(TenantStatusArchived, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		archive, err := service.ArchiveTenant(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), archive.ID)
+		assert.Equal(t, map[string]int64{"order": 2, "order_item": 1, "order_status_history": 0, "order_attachment": 0, "order_comment": 0, "order_tag": 0, "order_archive": 0, "audit_log": 0, "tenant_usage": 0, "tenant_usage_user": 0}, archive.Rows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		data, err := store.Get(ctx, archive.StorageKey)
+		require.NoError(t, err)
+		assert.Equal(t, archive.SizeBytes, int64(len(data)))
+
+		document, err := decodeArchive(data)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), document.TenantID)
+		require.Len(t, document.Tables["order"], 2)
+		assert.Equal(t, "shipped", document.Tables["order"][1]["status"])
+		assert.Equal(t, "2025-03-01T12:00:00Z", document.Tables["order"][1]["created_at"])
+		require.Len(t, document.Tables["order_item"], 1)
+		assert.Equal(t, "SKU-1", document.Tables["order_item"][0]["sku"])
+	})
+
+	t.Run("Tenant pending deletion", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBArchiveService(db, storage.NewFileStore(t.TempDir()))
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM tenant").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("pending_deletion"))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.ArchiveTenant(ctx, 1)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidTenantStatus))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tenant not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBArchiveService(db, storage.NewFileStore(t.TempDir()))
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM tenant").
+			WithArgs(int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.ArchiveTenant(ctx, 9)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrTenantNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRestoreArchivedTenant(t *testing.T) {
+	ctx := context.Background()
+	archivedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	key := "tenants/1/archive-20250301T120000Z.json.gz"
+
+	t.Run("Archived tenant", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		store := storage.NewFileStore(t.TempDir())
+		data, err := encodeArchive(archiveDocument{
+			Version:    archiveFormatVersion,
+			TenantID:   1,
+			ArchivedAt: archivedAt,
+			Tables: map[string][]map[string]interface{}{
+				"order": {{"order_id": 7, "tenant_id": 1, "status": "pending"}},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, store.Put(ctx, key, data))
+
+		service := NewDBArchiveService(db, store)
+
+		// Setup mock expectations
+		restoredAt := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM tenant").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("archived"))
+		mock.ExpectQuery("SELECT (.+) FROM tenant_archive WHERE tenant_id = \\$1 AND restored_at IS NULL").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(tenantArchiveColumns).AddRow(3, 1, key, len(data), []byte(`{"order":1}`), archivedAt, nil))
+		mock.ExpectExec("INSERT INTO \"order\" \\(\"order_id\", \"status\", \"tenant_id\"\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+			WithArgs(sqlmock.AnyArg(), "pending", int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("UPDATE tenant_archive SET restored_at = NOW\\(\\) WHERE id = \\$1").
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"restored_at"}).AddRow(restoredAt))
+		mock.ExpectExec("UPDATE tenant SET status = \\$1").
+			WithArgs(TenantStatusActive, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		archive, err := service.RestoreArchivedTenant(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"order": 1}, archive.Rows)
+		require.NotNil(t, archive.RestoredAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Active tenant", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBArchiveService(db, storage.NewFileStore(t.TempDir()))
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM tenant").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.RestoreArchivedTenant(ctx, 1)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidTenantStatus))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No archive", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBArchiveService(db, storage.NewFileStore(t.TempDir()))
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM tenant").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("archived"))
+		mock.ExpectQuery("FROM tenant_archive").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(tenantArchiveColumns))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.RestoreArchivedTenant(ctx, 1)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrArchiveNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListArchives(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBArchiveService(db, storage.NewFileStore(t.TempDir()))
+
+	// Setup mock expectations
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM tenant_archive WHERE tenant_id = \\$1 ORDER BY created_at DESC").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(tenantArchiveColumns).
+			AddRow(4, 1, "tenants/1/b.json.gz", 512, []byte(`{"order":3}`), now, nil).
+			AddRow(3, 1, "tenants/1/a.json.gz", 256, []byte(`{"order":1}`), now.Add(-time.Hour), now))
+
+	// Execute
+	archives, err := service.ListArchives(context.Background(), 1)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, archives, 2)
+	assert.Nil(t, archives[0].RestoredAt)
+	assert.NotNil(t, archives[1].RestoredAt)
+	assert.Equal(t, int64(3), archives[0].Rows["order"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+)
+
+// AuditingTenantMemberService decorates a TenantMemberService, recording membership
+// changes in the tenant's audit log. Failing to record an entry does not fail the change.
+type AuditingTenantMemberService struct {
+	TenantMemberService
+	recorder auditservice.AuditRecorder
+}
+
+// Ensure AuditingTenantMemberService implements TenantMemberService
+var _ TenantMemberService = (*AuditingTenantMemberService)(nil)
+
+// NewAuditingTenantMemberService creates a new AuditingTenantMemberService
+func NewAuditingTenantMemberService(tenantMemberService TenantMemberService, recorder auditservice.AuditRecorder) *AuditingTenantMemberService {
+	return &AuditingTenantMemberService{
+		TenantMemberService: tenantMemberService,
+		recorder:            recorder,
+	}
+}
+
+// AddTenantMember adds a user to a tenant and records it in the audit log
+func (s *AuditingTenantMemberService) AddTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	if err := s.TenantMemberService.AddTenantMember(ctx, userID, tenantID); err != nil {
+		return err
+	}
+	recordMemberAudit(ctx, s.recorder, auditservice.ActionMemberAdded, userID, tenantID)
+	return nil
+}
+
+// RemoveTenantMember removes a user from a tenant and records it in the audit log
+func (s *AuditingTenantMemberService) RemoveTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	if err := s.TenantMemberService.RemoveTenantMember(ctx, userID, tenantID); err != nil {
+		return err
+	}
+	recordMemberAudit(ctx, s.recorder, auditservice.ActionMemberRemoved, userID, tenantID)
+	return nil
+}
+
+// AuditingTenantService decorates a TenantService, recording membership changes in
+// the tenant's audit log. Failing to record an entry does not fail the change.
+type AuditingTenantService struct {
+	TenantService
+	recorder auditservice.AuditRecorder
+}
+
+// Ensure AuditingTenantService implements TenantService
+var _ TenantService = (*AuditingTenantService)(nil)
+
+// NewAuditingTenantService creates a new AuditingTenantService
+func NewAuditingTenantService(tenantService TenantService, recorder auditservice.AuditRecorder) *AuditingTenantService {
+	return &AuditingTenantService{
+		TenantService: tenantService,
+		recorder:      recorder,
+	}
+}
+
+// AddTenantMember adds a user to a tenant and records it in the audit log
+func (s *AuditingTenantService) AddTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	if err := s.TenantService.AddTenantMember(ctx, userID, tenantID); err != nil {
+		return err
+	}
+	recordMemberAudit(ctx, s.recorder, auditservice.ActionMemberAdded, userID, tenantID)
+	return nil
+}
+
+// RemoveTenantMember removes a user from a tenant and records it in the audit log
+func (s *AuditingTenantService) RemoveTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	if err := s.TenantService.RemoveTenantMember(ctx, userID, tenantID); err != nil {
+		return err
+	}
+	recordMemberAudit(ctx, s.recorder, auditservice.ActionMemberRemoved, userID, tenantID)
+	return nil
+}
+
+// recordMemberAudit writes an audit entry for a membership change, logging rather
+// than returning failures
+func recordMemberAudit(ctx context.Context, recorder auditservice.AuditRecorder, action string, userID int64, tenantID int64) {
+	entry := auditservice.AuditEntry{
+		TenantID:     tenantID,
+		Action:       action,
+		ResourceType: auditservice.ResourceMember,
+		ResourceID:   strconv.FormatInt(userID, 10),
+	}
+	if err := recorder.Record(ctx, entry); err != nil {
+		log.Printf("[WARN] Failed to record %s audit entry for user %d in tenant %d: %v", action, userID, tenantID, err)
+	}
+}
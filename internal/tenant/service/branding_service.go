@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// MaxLogoBytes is the maximum size of an uploaded tenant logo
+const MaxLogoBytes = 512 * 1024
+
+// ErrLogoNotFound is returned when a tenant has no logo
+var ErrLogoNotFound = errors.New("logo not found")
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// logoContentTypes are the image types accepted as logos. SVG is excluded because
+// it can carry scripts.
+var logoContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Branding holds the identity a tenant's pages are rendered with
+type Branding struct {
+	TenantID int64 `json:"tenant_id"`
+	// DisplayName is shown in the header instead of the tenant name if set
+	DisplayName  string    `json:"display_name"`
+	PrimaryColor string    `json:"primary_color,omitempty"`
+	AccentColor  string    `json:"accent_color,omitempty"`
+	HasLogo      bool      `json:"has_logo"`
+	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+}
+
+// Logo is a tenant's logo image
+type Logo struct {
+	ContentType string
+	Data        []byte
+	UpdatedAt   time.Time
+}
+
+// BrandingService defines the interface for tenant branding operations
+type BrandingService interface {
+	// GetBranding retrieves a tenant's branding. Tenants without branding get
+	// their name and the default colors.
+	GetBranding(ctx context.Context, tenantID int64) (*Branding, error)
+
+	// UpdateBranding sets a tenant's display name and colors. Empty values reset
+	// them to the defaults.
+	UpdateBranding(ctx context.Context, branding *Branding) error
+
+	// GetLogo retrieves a tenant's logo
+	GetLogo(ctx context.Context, tenantID int64) (*Logo, error)
+
+	// SetLogo replaces a tenant's logo
+	SetLogo(ctx context.Context, tenantID int64, contentType string, data []byte) error
+
+	// DeleteLogo removes a tenant's logo
+	DeleteLogo(ctx context.Context, tenantID int64) error
+}
+
+// DBBrandingService implements BrandingService using a database
+type DBBrandingService struct {
+	db *sql.DB
+}
+
+// NewDBBrandingService creates a new DBBrandingService
+func NewDBBrandingService(db *sql.DB) *DBBrandingService {
+	return &DBBrandingService{
+		db: db,
+	}
+}
+
+// GetBranding retrieves a tenant's branding. Tenants without branding get
+// their name and the default colors.
+func (s *DBBrandingService) GetBranding(ctx context.Context, tenantID int64) (*Branding, error) {
+	query := `
+		SELECT t.name, b.display_name, b.primary_color, b.accent_color, b.logo IS NOT NULL, b.updated_at
+		FROM tenant t
+		LEFT JOIN tenant_branding b ON b.tenant_id = t.id
+		WHERE t.id = $1 AND t.deleted_at IS NULL
+	`
+
+	var name string
+	var displayName, primaryColor, accentColor sql.NullString
+	var hasLogo sql.NullBool
+	var updatedAt sql.NullTime
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID).Scan(&name, &displayName, &primaryColor, &accentColor, &hasLogo, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	branding := &Branding{
+		TenantID:     tenantID,
+		DisplayName:  displayName.String,
+		PrimaryColor: primaryColor.String,
+		AccentColor:  accentColor.String,
+		HasLogo:      hasLogo.Bool,
+		UpdatedAt:    updatedAt.Time,
+	}
+	if branding.DisplayName == "" {
+		branding.DisplayName = name
+	}
+
+	return branding, nil
+}
+
+// UpdateBranding sets a tenant's display name and colors. Empty values reset
+// them to the defaults.
+func (s *DBBrandingService) UpdateBranding(ctx context.Context, branding *Branding) error {
+	if branding.TenantID == 0 {
+		return fmt.Errorf("%w: tenant ID is required", ErrInvalidInput)
+	}
+
+	branding.DisplayName = strings.TrimSpace(branding.DisplayName)
+	if len(branding.DisplayName) > 255 {
+		return fmt.Errorf("%w: display name must be at most 255 characters", ErrInvalidInput)
+	}
+
+	branding.PrimaryColor = strings.ToLower(strings.TrimSpace(branding.PrimaryColor))
+	branding.AccentColor = strings.ToLower(strings.TrimSpace(branding.AccentColor))
+	for _, color := range []string{branding.PrimaryColor, branding.AccentColor} {
+		if color != "" && !colorPattern.MatchString(color) {
+			return fmt.Errorf("%w: colors must be hex values such as #1d4ed8", ErrInvalidInput)
+		}
+	}
+
+	query := `
+		INSERT INTO tenant_branding (tenant_id, display_name, primary_color, accent_color, updated_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET display_name = EXCLUDED.display_name,
+			primary_color = EXCLUDED.primary_color,
+			accent_color = EXCLUDED.accent_color,
+			updated_at = NOW()
+	`
+
+	_, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, query, branding.TenantID, branding.DisplayName, branding.PrimaryColor, branding.AccentColor)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Updated branding for tenant %d", branding.TenantID)
+	return nil
+}
+
+// GetLogo retrieves a tenant's logo
+func (s *DBBrandingService) GetLogo(ctx context.Context, tenantID int64) (*Logo, error) {
+	query := `
+		SELECT logo_content_type, logo, updated_at
+		FROM tenant_branding
+		WHERE tenant_id = $1 AND logo IS NOT NULL
+	`
+
+	var logo Logo
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID).Scan(&logo.ContentType, &logo.Data, &logo.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLogoNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return &logo, nil
+}
+
+// SetLogo replaces a tenant's logo
+func (s *DBBrandingService) SetLogo(ctx context.Context, tenantID int64, contentType string, data []byte) error {
+	if !logoContentTypes[contentType] {
+		return fmt.Errorf("%w: logo must be a PNG, JPEG, GIF or WebP image", ErrInvalidInput)
+	}
+
+	if len(data) == 0 || len(data) > MaxLogoBytes {
+		return fmt.Errorf("%w: logo must be between 1 byte and %d KB", ErrInvalidInput, MaxLogoBytes/1024)
+	}
+
+	query := `
+		INSERT INTO tenant_branding (tenant_id, logo, logo_content_type, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET logo = EXCLUDED.logo,
+			logo_content_type = EXCLUDED.logo_content_type,
+			updated_at = NOW()
+	`
+
+	_, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, query, tenantID, data, contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Updated logo for tenant %d (%s, %d bytes)", tenantID, contentType, len(data))
+	return nil
+}
+
+// DeleteLogo removes a tenant's logo
+func (s *DBBrandingService) DeleteLogo(ctx context.Context, tenantID int64) error {
+	query := `
+		UPDATE tenant_branding
+		SET logo = NULL, logo_content_type = NULL, updated_at = NOW()
+		WHERE tenant_id = $1 AND logo IS NOT NULL
+	`
+
+	result, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrLogoNotFound
+	}
+
+	log.Printf("[INFO] Removed logo for tenant %d", tenantID)
+	return nil
+}
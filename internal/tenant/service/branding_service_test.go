@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBranding(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBBrandingService(db)
+	ctx := context.Background()
+
+	columns := []string{"name", "display_name", "primary_color", "accent_color", "has_logo", "updated_at"}
+
+	t.Run("Tenant with branding", func(t *testing.T) {
+		// Setup mock expectations
+		updatedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT t.name, b.display_name, b.primary_color, b.accent_color, b.logo IS NOT NULL, b.updated_at FROM tenant t LEFT JOIN tenant_branding b").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("Acme Inc", "Acme", "#1d4ed8", nil, true, updatedAt))
+
+		// Execute
+		branding, err := service.GetBranding(ctx, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "Acme", branding.DisplayName)
+		assert.Equal(t, "#1d4ed8", branding.PrimaryColor)
+		assert.Empty(t, branding.AccentColor)
+		assert.True(t, branding.HasLogo)
+		assert.Equal(t, updatedAt, branding.UpdatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tenant without branding uses its name", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT t.name").
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("Globex", nil, nil, nil, nil, nil))
+
+		// Execute
+		branding, err := service.GetBranding(ctx, 2)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "Globex", branding.DisplayName)
+		assert.False(t, branding.HasLogo)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tenant not found", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT t.name").
+			WithArgs(int64(3)).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		_, err := service.GetBranding(ctx, 3)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrTenantNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateBranding(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBBrandingService(db)
+	ctx := context.Background()
+
+	t.Run("Colors are normalized", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("INSERT INTO tenant_branding \\(tenant_id, display_name, primary_color, accent_color, updated_at\\)").
+			WithArgs(int64(1), "Acme", "#1d4ed8", "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := service.UpdateBranding(ctx, &Branding{TenantID: 1, DisplayName: " Acme ", PrimaryColor: "#1D4ED8"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid color", func(t *testing.T) {
+		// Execute
+		err := service.UpdateBranding(ctx, &Branding{TenantID: 1, PrimaryColor: "red;background:url(x)"})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetLogo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBBrandingService(db)
+	ctx := context.Background()
+
+	t.Run("Valid logo", func(t *testing.T) {
+		// Setup
+		data := []byte("\x89PNG\r\n\x1a\n")
+
+		// Setup mock expectations
+		mock.ExpectExec("INSERT INTO tenant_branding \\(tenant_id, logo, logo_content_type, updated_at\\)").
+			WithArgs(int64(1), data, "image/png").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := service.SetLogo(ctx, 1, "image/png", data)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unsupported image type", func(t *testing.T) {
+		// Execute
+		err := service.SetLogo(ctx, 1, "image/svg+xml", []byte("<svg/>"))
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Logo too large", func(t *testing.T) {
+		// Execute
+		err := service.SetLogo(ctx, 1, "image/png", make([]byte, MaxLogoBytes+1))
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
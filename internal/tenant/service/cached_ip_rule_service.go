@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+// Cache key prefix for tenant IP rules
+const ipRulesKeyPrefix = "ip_rules:tenant:"
+
+// CachingIPRuleService decorates an IPRuleService, caching the IP rules that
+// are checked on every request and invalidating them when they change
+type CachingIPRuleService struct {
+	IPRuleService
+	cache cache.Cache
+}
+
+// Ensure CachingIPRuleService implements IPRuleService
+var _ IPRuleService = (*CachingIPRuleService)(nil)
+
+// NewCachingIPRuleService creates a new CachingIPRuleService
+func NewCachingIPRuleService(ipRuleService IPRuleService, c cache.Cache) *CachingIPRuleService {
+	return &CachingIPRuleService{
+		IPRuleService: ipRuleService,
+		cache:         c,
+	}
+}
+
+// ListIPRules retrieves a tenant's IP rules, using the cache when possible.
+// Cache failures are logged and fall through to the underlying service.
+func (s *CachingIPRuleService) ListIPRules(ctx context.Context, tenantID int64) ([]IPRule, error) {
+	key := ipRulesKey(tenantID)
+
+	data, found, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] Failed to read %s from cache: %v", key, err)
+	} else if found {
+		var rules []IPRule
+		if err := json.Unmarshal(data, &rules); err == nil {
+			return rules, nil
+		}
+		log.Printf("[WARN] Discarding malformed cache entry %s", key)
+	}
+
+	rules, err := s.IPRuleService.ListIPRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(rules); err == nil {
+		if err := s.cache.Set(ctx, key, data, 0); err != nil {
+			log.Printf("[WARN] Failed to write %s to cache: %v", key, err)
+		}
+	}
+
+	return rules, nil
+}
+
+// AddIPRule adds an IP rule to a tenant and invalidates its cached rules
+func (s *CachingIPRuleService) AddIPRule(ctx context.Context, tenantID int64, rule IPRule) (*IPRule, error) {
+	added, err := s.IPRuleService.AddIPRule(ctx, tenantID, rule)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, tenantID)
+	return added, nil
+}
+
+// RemoveIPRule removes an IP rule from a tenant and invalidates its cached rules
+func (s *CachingIPRuleService) RemoveIPRule(ctx context.Context, tenantID int64, ruleID int64) error {
+	if err := s.IPRuleService.RemoveIPRule(ctx, tenantID, ruleID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, tenantID)
+	return nil
+}
+
+// InvalidateIPRules removes a tenant's cached IP rules
+func (s *CachingIPRuleService) InvalidateIPRules(ctx context.Context, tenantID int64) error {
+	return s.cache.Delete(ctx, ipRulesKey(tenantID))
+}
+
+// InvalidateAllIPRules removes the cached IP rules of all tenants
+func (s *CachingIPRuleService) InvalidateAllIPRules(ctx context.Context) error {
+	return s.cache.DeletePrefix(ctx, ipRulesKeyPrefix)
+}
+
+// invalidate removes a tenant's cached IP rules, logging failures
+func (s *CachingIPRuleService) invalidate(ctx context.Context, tenantID int64) {
+	if err := s.InvalidateIPRules(ctx, tenantID); err != nil {
+		log.Printf("[ERROR] Failed to invalidate cached IP rules for tenant ID %d: %v", tenantID, err)
+	}
+}
+
+// ipRulesKey returns the cache key for a tenant's IP rules
+func ipRulesKey(tenantID int64) string {
+	return fmt.Sprintf("%s%d", ipRulesKeyPrefix, tenantID)
+}
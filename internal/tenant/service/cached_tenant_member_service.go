@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+// Cache key prefix for tenant memberships
+const membershipKeyPrefix = "membership:user:"
+
+// Cached membership values
+var (
+	cachedMember    = []byte("1")
+	cachedNonMember = []byte("0")
+)
+
+// CachingTenantMemberService decorates a TenantMemberService, caching membership
+// checks that run on every request and invalidating them when membership changes
+type CachingTenantMemberService struct {
+	TenantMemberService
+	cache cache.Cache
+
+	// onChange is called after a membership changes so dependent caches can be invalidated
+	onChange func(ctx context.Context, userID int64, tenantID int64)
+}
+
+// Ensure CachingTenantMemberService implements TenantMemberService
+var _ TenantMemberService = (*CachingTenantMemberService)(nil)
+
+// NewCachingTenantMemberService creates a new CachingTenantMemberService
+func NewCachingTenantMemberService(tenantMemberService TenantMemberService, c cache.Cache) *CachingTenantMemberService {
+	return &CachingTenantMemberService{
+		TenantMemberService: tenantMemberService,
+		cache:               c,
+	}
+}
+
+// OnMembershipChange registers a hook that is called after a user is added to or
+// removed from a tenant, e.g. to invalidate cached tenant roles
+func (s *CachingTenantMemberService) OnMembershipChange(hook func(ctx context.Context, userID int64, tenantID int64)) *CachingTenantMemberService {
+	s.onChange = hook
+	return s
+}
+
+// IsTenantMember checks if a user is a member of a tenant, using the cache when possible
+func (s *CachingTenantMemberService) IsTenantMember(ctx context.Context, userID int64, tenantID int64) (bool, error) {
+	key := membershipKey(userID, tenantID)
+
+	data, found, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] Failed to read %s from cache: %v", key, err)
+	} else if found {
+		return string(data) == string(cachedMember), nil
+	}
+
+	isMember, err := s.TenantMemberService.IsTenantMember(ctx, userID, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	value := cachedNonMember
+	if isMember {
+		value = cachedMember
+	}
+	if err := s.cache.Set(ctx, key, value, 0); err != nil {
+		log.Printf("[WARN] Failed to write %s to cache: %v", key, err)
+	}
+
+	return isMember, nil
+}
+
+// AddTenantMember adds a user to a tenant and invalidates the cached membership
+func (s *CachingTenantMemberService) AddTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	if err := s.TenantMemberService.AddTenantMember(ctx, userID, tenantID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, userID, tenantID)
+	return nil
+}
+
+// RemoveTenantMember removes a user from a tenant and invalidates the cached membership
+func (s *CachingTenantMemberService) RemoveTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	if err := s.TenantMemberService.RemoveTenantMember(ctx, userID, tenantID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, userID, tenantID)
+	return nil
+}
+
+// InvalidateMembership removes the cached membership of a user in a tenant
+func (s *CachingTenantMemberService) InvalidateMembership(ctx context.Context, userID int64, tenantID int64) error {
+	return s.cache.Delete(ctx, membershipKey(userID, tenantID))
+}
+
+// InvalidateAllMemberships removes all cached memberships
+func (s *CachingTenantMemberService) InvalidateAllMemberships(ctx context.Context) error {
+	return s.cache.DeletePrefix(ctx, membershipKeyPrefix)
+}
+
+// invalidate removes a cached membership, logging failures
+func (s *CachingTenantMemberService) invalidate(ctx context.Context, userID int64, tenantID int64) {
+	if err := s.InvalidateMembership(ctx, userID, tenantID); err != nil {
+		log.Printf("[ERROR] Failed to invalidate cached membership for user ID %d, tenant ID %d: %v", userID, tenantID, err)
+	}
+	if s.onChange != nil {
+		s.onChange(ctx, userID, tenantID)
+	}
+}
+
+// membershipKey returns the cache key for a user's membership in a tenant
+func membershipKey(userID int64, tenantID int64) string {
+	return fmt.Sprintf("%s%d:tenant:%d", membershipKeyPrefix, userID, tenantID)
+}
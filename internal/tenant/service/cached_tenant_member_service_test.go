@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+func TestCachingTenantMemberService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	userID := int64(1)
+	tenantID := int64(2)
+
+	var changed []int64
+	service := NewCachingTenantMemberService(NewDBTenantMemberService(db), cache.NewLRUCache(100, time.Minute)).
+		OnMembershipChange(func(ctx context.Context, userID int64, tenantID int64) {
+			changed = append(changed, userID)
+		})
+
+	// The first check hits the database, the second is served from the cache
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(userID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	for i := 0; i < 2; i++ {
+		isMember, err := service.IsTenantMember(ctx, userID, tenantID)
+		require.NoError(t, err)
+		assert.False(t, isMember)
+	}
+
+	// Adding the member invalidates the cached result
+	mock.ExpectExec("INSERT INTO tenant_member").
+		WithArgs(userID, tenantID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, service.AddTenantMember(ctx, userID, tenantID))
+	assert.Equal(t, []int64{userID}, changed)
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(userID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	isMember, err := service.IsTenantMember(ctx, userID, tenantID)
+	require.NoError(t, err)
+	assert.True(t, isMember)
+
+	// Invalidating all memberships, e.g. after another instance changed them,
+	// sends the next check to the database
+	require.NoError(t, service.InvalidateAllMemberships(ctx))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(userID, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	isMember, err = service.IsTenantMember(ctx, userID, tenantID)
+	require.NoError(t, err)
+	assert.False(t, isMember)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// CloneOptions selects what is copied into a cloned tenant besides its settings
+type CloneOptions struct {
+	// Members copies the source tenant's members and their tenant roles
+	Members bool `json:"members"`
+	// SampleData copies the source tenant's orders
+	SampleData bool `json:"sample_data"`
+}
+
+// CloneReport summarizes a tenant clone
+type CloneReport struct {
+	Tenant *Tenant `json:"tenant"`
+	// Rows is the number of rows copied into each tenant-scoped table
+	Rows map[string]int64 `json:"rows"`
+}
+
+// CloneService defines the interface for cloning tenants
+type CloneService interface {
+	// CloneTenant creates a tenant with the name and slug of target and copies the
+	// source tenant's settings into it: its description (unless target has one),
+	// quota and branding. Members, roles and sample data are copied as selected by
+	// options. Billing subscriptions, custom domains, webhooks and API keys are not copied.
+	CloneTenant(ctx context.Context, sourceTenantID int64, target *Tenant, options CloneOptions) (*CloneReport, error)
+}
+
+// DBCloneService implements CloneService using a database
+type DBCloneService struct {
+	db          *sql.DB
+	provisioner TenantProvisioner
+}
+
+// Ensure DBCloneService implements CloneService
+var _ CloneService = (*DBCloneService)(nil)
+
+// NewDBCloneService creates a new DBCloneService
+func NewDBCloneService(db *sql.DB) *DBCloneService {
+	return &DBCloneService{db: db}
+}
+
+// WithProvisioner provisions the database resources of each clone
+func (s *DBCloneService) WithProvisioner(provisioner TenantProvisioner) *DBCloneService {
+	s.provisioner = provisioner
+	return s
+}
+
+// tenantCloneSteps copy a tenant's data in order, from the source tenant ($1) to the
+// clone ($2). Members are copied before their roles and orders.
+var tenantCloneSteps = []struct {
+	name    string
+	query   string
+	include func(options CloneOptions) bool
+}{
+	{
+		name: "tenant_quota",
+		query: `INSERT INTO tenant_quota (tenant_id, max_members, max_orders, max_api_requests, max_storage_bytes)
+			SELECT $2, max_members, max_orders, max_api_requests, max_storage_bytes FROM tenant_quota WHERE tenant_id = $1`,
+	},
+	{
+		// The clone is shown under its own name, so the display name isn't copied
+		name: "tenant_branding",
+		query: `INSERT INTO tenant_branding (tenant_id, primary_color, accent_color, logo, logo_content_type)
+			SELECT $2, primary_color, accent_color, logo, logo_content_type FROM tenant_branding WHERE tenant_id = $1`,
+	},
+	{
+		name: "tenant_order_setting",
+		query: `INSERT INTO tenant_order_setting (tenant_id, order_number_prefix)
+			SELECT $2, order_number_prefix FROM tenant_order_setting WHERE tenant_id = $1`,
+	},
+	{
+		name: "tenant_member",
+		query: `INSERT INTO tenant_member (tenant_id, user_id)
+			SELECT $2, user_id FROM tenant_member WHERE tenant_id = $1`,
+		include: func(options CloneOptions) bool { return options.Members },
+	},
+	{
+		name: "tenant_role",
+		query: `INSERT INTO tenant_role (tenant_id, user_id, role_id)
+			SELECT $2, user_id, role_id FROM tenant_role WHERE tenant_id = $1`,
+		include: func(options CloneOptions) bool { return options.Members },
+	},
+	{
+		name: "order",
+		query: `INSERT INTO "order" (tenant_id, user_id, order_number, status, total_amount, notes)
+			SELECT $2, user_id, order_number, status, total_amount, notes FROM "order" WHERE tenant_id = $1 AND deleted_at IS NULL`,
+		include: func(options CloneOptions) bool { return options.SampleData },
+	},
+	{
+		// Items are matched to the cloned orders by order number, which is unique per tenant
+		name: "order_item",
+		query: `INSERT INTO order_item (order_id, tenant_id, sku, description, quantity, unit_price)
+			SELECT c.order_id, $2, i.sku, i.description, i.quantity, i.unit_price
+			FROM order_item i
+			JOIN "order" o ON o.order_id = i.order_id
+			JOIN "order" c ON c.tenant_id = $2 AND c.order_number = o.order_number
+			WHERE i.tenant_id = $1`,
+		include: func(options CloneOptions) bool { return options.SampleData },
+	},
+	{
+		// The copied orders keep their numbers, so numbering continues after them
+		name: "order_number_sequence",
+		query: `INSERT INTO order_number_sequence (tenant_id, year, last_value)
+			SELECT $2, year, last_value FROM order_number_sequence WHERE tenant_id = $1`,
+		include: func(options CloneOptions) bool { return options.SampleData },
+	},
+}
+
+// CloneTenant creates a tenant and copies the source tenant's configuration into it
+// in a single transaction
+func (s *DBCloneService) CloneTenant(ctx context.Context, sourceTenantID int64, target *Tenant, options CloneOptions) (*CloneReport, error) {
+	if target.Name == "" {
+		return nil, fmt.Errorf("%w: tenant name is required", ErrInvalidInput)
+	}
+
+	if err := prepareTenantSlug(target); err != nil {
+		return nil, err
+	}
+
+	// Start a transaction to ensure atomicity
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	// Create the clone, falling back to the source tenant's description. Deleted
+	// tenants can't be cloned.
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tenant (name, slug, description)
+		SELECT $2, $3, COALESCE(NULLIF($4, ''), description)
+		FROM tenant
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, name, slug, status, description, created_at, updated_at
+	`, sourceTenantID, target.Name, target.Slug, target.Description).Scan(
+		&target.ID,
+		&target.Name,
+		&target.Slug,
+		&target.Status,
+		&target.Description,
+		&target.CreatedAt,
+		&target.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, tenantWriteError(err)
+	}
+
+	report := &CloneReport{Tenant: target, Rows: make(map[string]int64, len(tenantCloneSteps))}
+
+	for _, step := range tenantCloneSteps {
+		if step.include != nil && !step.include(options) {
+			continue
+		}
+
+		result, err := tx.ExecContext(ctx, step.query, sourceTenantID, target.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to clone %s: %v", ErrDBOperation, step.name, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		report.Rows[step.name] = rowsAffected
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Tenant %d cloned into tenant %d (%s)", sourceTenantID, target.ID, target.Name)
+	provisionTenant(ctx, s.provisioner, target.ID)
+	return report, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cloneTenantColumns = []string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}
+
+func TestCloneTenant(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Settings only", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBCloneService(db)
+
+		// Setup mock expectations
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO tenant \\(name, slug, description\\) SELECT \\$2, \\$3, COALESCE\\(NULLIF\\(\\$4, ''\\), description\\) FROM tenant WHERE id = \\$1 AND deleted_at IS NULL").
+			WithArgs(int64(1), "Acme Staging", "acme-staging", "").
+			WillReturnRows(sqlmock.NewRows(cloneTenantColumns).AddRow(5, "Acme Staging", "acme-staging", "active", "Template", now, now))
+		mock.ExpectExec("INSERT INTO tenant_quota (.+) SELECT \\$2, (.+) FROM tenant_quota WHERE tenant_id = \\$1").
+			WithArgs(int64(1), int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO tenant_branding (.+) FROM tenant_branding WHERE tenant_id = \\$1").
+			WithArgs(int64(1), int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO tenant_order_setting (.+) FROM tenant_order_setting WHERE tenant_id = \\$1").
+			WithArgs(int64(1), int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		// Execute
+		report, err := service.CloneTenant(ctx, 1, &Tenant{Name: "Acme Staging"}, CloneOptions{})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), report.Tenant.ID)
+		assert.Equal(t, "Template", report.Tenant.Description)
+		assert.Equal(t, map[string]int64{"tenant_quota": 1, "tenant_branding": 1, "tenant_order_setting": 0}, report.Rows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Members and sample data", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBCloneService(db)
+
+		// Setup mock expectations
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO tenant").
+			WithArgs(int64(1), "Globex", "globex", "Demo").
+			WillReturnRows(sqlmock.NewRows(cloneTenantColumns).AddRow(6, "Globex", "globex", "active", "Demo", now, now))
+		mock.ExpectExec("INSERT INTO tenant_quota").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO tenant_branding").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO tenant_order_setting").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO tenant_member").WithArgs(int64(1), int64(6)).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("INSERT INTO tenant_role").WithArgs(int64(1), int64(6)).WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectExec("INSERT INTO \"order\"").WithArgs(int64(1), int64(6)).WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectExec("INSERT INTO order_item (.+) JOIN \"order\" c ON c.tenant_id = \\$2 AND c.order_number = o.order_number").
+			WithArgs(int64(1), int64(6)).
+			WillReturnResult(sqlmock.NewResult(0, 25))
+		mock.ExpectExec("INSERT INTO order_number_sequence").WithArgs(int64(1), int64(6)).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		// Execute
+		report, err := service.CloneTenant(ctx, 1, &Tenant{Name: "Globex", Description: "Demo"}, CloneOptions{Members: true, SampleData: true})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), report.Rows["tenant_member"])
+		assert.Equal(t, int64(10), report.Rows["order"])
+		assert.Equal(t, int64(25), report.Rows["order_item"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Copy fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBCloneService(db)
+
+		// Setup mock expectations
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO tenant").
+			WillReturnRows(sqlmock.NewRows(cloneTenantColumns).AddRow(6, "Globex", "globex", "active", "", now, now))
+		mock.ExpectExec("INSERT INTO tenant_quota").WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.CloneTenant(ctx, 1, &Tenant{Name: "Globex"}, CloneOptions{})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Source tenant not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBCloneService(db)
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO tenant").
+			WillReturnRows(sqlmock.NewRows(cloneTenantColumns))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.CloneTenant(ctx, 9, &Tenant{Name: "Globex"}, CloneOptions{})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrTenantNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
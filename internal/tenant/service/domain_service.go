@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// Custom domain errors
+var (
+	ErrDomainNotFound = errors.New("domain not found")
+
+	// ErrDomainTaken is returned when another tenant has already verified the domain
+	ErrDomainTaken = errors.New("domain is already in use")
+
+	// ErrDomainNotVerified is returned when the domain's verification TXT record can't be found
+	ErrDomainNotVerified = errors.New("domain verification record not found")
+)
+
+const (
+	// DomainVerificationPrefix is the subdomain holding a custom domain's verification TXT record
+	DomainVerificationPrefix = "_silocore-verification."
+
+	// domainVerificationValuePrefix starts the value of the verification TXT record
+	domainVerificationValuePrefix = "silocore-verification="
+
+	// domainTokenSize is the number of random bytes in a verification token
+	domainTokenSize = 16
+)
+
+// domainLabelPattern matches a single DNS label
+var domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// TenantDomain is a custom domain attached to a tenant. Requests to a verified
+// domain are served in the tenant's context.
+type TenantDomain struct {
+	ID                int64      `json:"id"`
+	TenantID          int64      `json:"tenant_id"`
+	Domain            string     `json:"domain"`
+	VerificationToken string     `json:"-"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Verified reports whether the tenant has proven it controls the domain
+func (d TenantDomain) Verified() bool {
+	return d.VerifiedAt != nil
+}
+
+// VerificationRecordName is the name of the TXT record that verifies the domain
+func (d TenantDomain) VerificationRecordName() string {
+	return DomainVerificationPrefix + d.Domain
+}
+
+// VerificationRecordValue is the value of the TXT record that verifies the domain
+func (d TenantDomain) VerificationRecordValue() string {
+	return domainVerificationValuePrefix + d.VerificationToken
+}
+
+// DomainHook is called after a custom domain is verified or removed, e.g. to
+// provision or release its TLS certificate
+type DomainHook func(ctx context.Context, domain TenantDomain)
+
+// DomainService defines the interface for tenant custom domain operations
+type DomainService interface {
+	// AddDomain attaches a domain to a tenant. It isn't used until it is verified.
+	AddDomain(ctx context.Context, tenantID int64, domain string) (*TenantDomain, error)
+
+	// ListDomains retrieves a tenant's domains
+	ListDomains(ctx context.Context, tenantID int64) ([]TenantDomain, error)
+
+	// VerifyDomain checks the domain's verification TXT record and marks it verified
+	VerifyDomain(ctx context.Context, tenantID int64, domainID int64) (*TenantDomain, error)
+
+	// RemoveDomain detaches a domain from a tenant
+	RemoveDomain(ctx context.Context, tenantID int64, domainID int64) error
+
+	// GetTenantIDByDomain retrieves the ID of the tenant a verified domain belongs to
+	GetTenantIDByDomain(ctx context.Context, domain string) (int64, error)
+}
+
+// DBDomainService implements DomainService using a database
+type DBDomainService struct {
+	db        *sql.DB
+	lookupTXT func(ctx context.Context, name string) ([]string, error)
+
+	// onVerified and onRemoved are called after a domain is verified or removed
+	onVerified DomainHook
+	onRemoved  DomainHook
+}
+
+// Ensure DBDomainService implements DomainService
+var _ DomainService = (*DBDomainService)(nil)
+
+// NewDBDomainService creates a new DBDomainService
+func NewDBDomainService(db *sql.DB) *DBDomainService {
+	return &DBDomainService{
+		db:        db,
+		lookupTXT: net.DefaultResolver.LookupTXT,
+	}
+}
+
+// OnDomainVerified registers a hook that is called after a domain is verified,
+// e.g. to provision a TLS certificate for it
+func (s *DBDomainService) OnDomainVerified(hook DomainHook) *DBDomainService {
+	s.onVerified = hook
+	return s
+}
+
+// OnDomainRemoved registers a hook that is called after a verified domain is
+// removed, e.g. to release its TLS certificate
+func (s *DBDomainService) OnDomainRemoved(hook DomainHook) *DBDomainService {
+	s.onRemoved = hook
+	return s
+}
+
+// AddDomain attaches a domain to a tenant with a new verification token
+func (s *DBDomainService) AddDomain(ctx context.Context, tenantID int64, domain string) (*TenantDomain, error) {
+	domain = NormalizeDomain(domain)
+	if err := ValidateDomain(domain); err != nil {
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, domainTokenSize)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate domain verification token: %w", err)
+	}
+
+	tenantDomain := &TenantDomain{
+		TenantID:          tenantID,
+		Domain:            domain,
+		VerificationToken: hex.EncodeToString(tokenBytes),
+	}
+
+	query := `
+		INSERT INTO tenant_domain (tenant_id, domain, verification_token)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID, domain, tenantDomain.VerificationToken).
+		Scan(&tenantDomain.ID, &tenantDomain.CreatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: the tenant has already added %s", ErrInvalidInput, domain)
+		}
+		log.Printf("[ERROR] Database error when adding domain %s to tenant %d: %v", domain, tenantID, err)
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Domain %s added to tenant %d", domain, tenantID)
+	return tenantDomain, nil
+}
+
+// ListDomains retrieves a tenant's domains, ordered by domain
+func (s *DBDomainService) ListDomains(ctx context.Context, tenantID int64) ([]TenantDomain, error) {
+	query := `
+		SELECT id, tenant_id, domain, verification_token, verified_at, created_at
+		FROM tenant_domain
+		WHERE tenant_id = $1
+		ORDER BY domain
+	`
+
+	rows, err := transaction.QuerierFor(ctx, s.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	domains := []TenantDomain{}
+	for rows.Next() {
+		domain, err := scanTenantDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		domains = append(domains, *domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return domains, nil
+}
+
+// VerifyDomain checks the domain's verification TXT record and marks it verified.
+// Verifying an already verified domain checks nothing and returns it as is.
+func (s *DBDomainService) VerifyDomain(ctx context.Context, tenantID int64, domainID int64) (*TenantDomain, error) {
+	domain, err := s.getDomain(ctx, tenantID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.Verified() {
+		return domain, nil
+	}
+
+	records, err := s.lookupTXT(ctx, domain.VerificationRecordName())
+	if err != nil {
+		log.Printf("[INFO] Failed to look up verification record for domain %s: %v", domain.Domain, err)
+		return nil, ErrDomainNotVerified
+	}
+	if !slices.Contains(records, domain.VerificationRecordValue()) {
+		return nil, ErrDomainNotVerified
+	}
+
+	err = transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx,
+		"UPDATE tenant_domain SET verified_at = NOW() WHERE id = $1 AND tenant_id = $2 RETURNING verified_at",
+		domainID, tenantID).Scan(&domain.VerifiedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDomainTaken
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDomainNotFound
+		}
+		log.Printf("[ERROR] Database error when verifying domain %s for tenant %d: %v", domain.Domain, tenantID, err)
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Domain %s verified for tenant %d", domain.Domain, tenantID)
+
+	if s.onVerified != nil {
+		s.onVerified(ctx, *domain)
+	}
+
+	return domain, nil
+}
+
+// RemoveDomain detaches a domain from a tenant
+func (s *DBDomainService) RemoveDomain(ctx context.Context, tenantID int64, domainID int64) error {
+	query := `
+		DELETE FROM tenant_domain
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING id, tenant_id, domain, verification_token, verified_at, created_at
+	`
+
+	domain, err := scanTenantDomain(transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, domainID, tenantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDomainNotFound
+		}
+		log.Printf("[ERROR] Database error when removing domain %d from tenant %d: %v", domainID, tenantID, err)
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Domain %s removed from tenant %d", domain.Domain, tenantID)
+
+	if domain.Verified() && s.onRemoved != nil {
+		s.onRemoved(ctx, *domain)
+	}
+
+	return nil
+}
+
+// GetTenantIDByDomain retrieves the ID of the tenant a verified domain belongs to.
+// Unverified domains and domains of deleted tenants are not found.
+func (s *DBDomainService) GetTenantIDByDomain(ctx context.Context, domain string) (int64, error) {
+	query := `
+		SELECT d.tenant_id
+		FROM tenant_domain d
+		JOIN tenant t ON t.id = d.tenant_id
+		WHERE d.domain = $1 AND d.verified_at IS NOT NULL AND t.deleted_at IS NULL
+	`
+
+	var tenantID int64
+	err := s.db.QueryRowContext(ctx, query, NormalizeDomain(domain)).Scan(&tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrDomainNotFound
+		}
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return tenantID, nil
+}
+
+// getDomain retrieves one of a tenant's domains
+func (s *DBDomainService) getDomain(ctx context.Context, tenantID int64, domainID int64) (*TenantDomain, error) {
+	query := `
+		SELECT id, tenant_id, domain, verification_token, verified_at, created_at
+		FROM tenant_domain
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	domain, err := scanTenantDomain(transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, domainID, tenantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return domain, nil
+}
+
+// scanTenantDomain scans a tenant_domain row
+func scanTenantDomain(row rowScanner) (*TenantDomain, error) {
+	var domain TenantDomain
+	var verifiedAt sql.NullTime
+	if err := row.Scan(
+		&domain.ID,
+		&domain.TenantID,
+		&domain.Domain,
+		&domain.VerificationToken,
+		&verifiedAt,
+		&domain.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if verifiedAt.Valid {
+		domain.VerifiedAt = &verifiedAt.Time
+	}
+	return &domain, nil
+}
+
+// NormalizeDomain lowercases a domain and removes surrounding whitespace and the
+// trailing dot of a fully qualified name
+func NormalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// ValidateDomain checks that a normalized domain is a host name with at least two
+// labels. IP addresses, ports and wildcards are rejected.
+func ValidateDomain(domain string) error {
+	if len(domain) == 0 || len(domain) > 253 || net.ParseIP(domain) != nil {
+		return fmt.Errorf("%w: %q is not a valid domain", ErrInvalidInput, domain)
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("%w: %q is not a valid domain", ErrInvalidInput, domain)
+	}
+	for _, label := range labels {
+		if !domainLabelPattern.MatchString(label) {
+			return fmt.Errorf("%w: %q is not a valid domain", ErrInvalidInput, domain)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var domainColumns = []string{"id", "tenant_id", "domain", "verification_token", "verified_at", "created_at"}
+
+func TestAddDomain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Domain is normalized", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBDomainService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("INSERT INTO tenant_domain \\(tenant_id, domain, verification_token\\)").
+			WithArgs(int64(1), "shop.example.com", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, time.Now()))
+
+		// Execute
+		domain, err := service.AddDomain(ctx, 1, " Shop.Example.com. ")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), domain.ID)
+		assert.Equal(t, "_silocore-verification.shop.example.com", domain.VerificationRecordName())
+		assert.Equal(t, "silocore-verification="+domain.VerificationToken, domain.VerificationRecordValue())
+		assert.False(t, domain.Verified())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid domain", func(t *testing.T) {
+		service := NewDBDomainService(nil)
+
+		// Execute
+		_, err := service.AddDomain(ctx, 1, "*.example.com")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
+
+func TestVerifyDomain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Verification record found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		var verified []string
+		service := NewDBDomainService(db).OnDomainVerified(func(ctx context.Context, domain TenantDomain) {
+			verified = append(verified, domain.Domain)
+		})
+		service.lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+			assert.Equal(t, "_silocore-verification.shop.example.com", name)
+			return []string{"v=spf1 -all", "silocore-verification=token"}, nil
+		}
+
+		// Setup mock expectations
+		now := time.Now()
+		mock.ExpectQuery("SELECT (.+) FROM tenant_domain WHERE id = \\$1 AND tenant_id = \\$2").
+			WithArgs(int64(4), int64(1)).
+			WillReturnRows(sqlmock.NewRows(domainColumns).AddRow(4, 1, "shop.example.com", "token", nil, now))
+		mock.ExpectQuery("UPDATE tenant_domain SET verified_at = NOW\\(\\)").
+			WithArgs(int64(4), int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"verified_at"}).AddRow(now))
+
+		// Execute
+		domain, err := service.VerifyDomain(ctx, 1, 4)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, domain.Verified())
+		assert.Equal(t, []string{"shop.example.com"}, verified)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Verification record missing", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBDomainService(db)
+		service.lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+			return []string{"silocore-verification=other"}, nil
+		}
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM tenant_domain").
+			WithArgs(int64(4), int64(1)).
+			WillReturnRows(sqlmock.NewRows(domainColumns).AddRow(4, 1, "shop.example.com", "token", nil, time.Now()))
+
+		// Execute
+		_, err = service.VerifyDomain(ctx, 1, 4)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrDomainNotVerified))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTenantIDByDomain(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBDomainService(db)
+	ctx := context.Background()
+
+	t.Run("Verified domain", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT d.tenant_id FROM tenant_domain d JOIN tenant t ON t.id = d.tenant_id WHERE d.domain = \\$1 AND d.verified_at IS NOT NULL AND t.deleted_at IS NULL").
+			WithArgs("shop.example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow(1))
+
+		// Execute
+		tenantID, err := service.GetTenantIDByDomain(ctx, "Shop.Example.com")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), tenantID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown or unverified domain", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT d.tenant_id FROM tenant_domain").
+			WithArgs("other.example.com").
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		_, err := service.GetTenantIDByDomain(ctx, "other.example.com")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrDomainNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestValidateDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		valid  bool
+	}{
+		{"example.com", true},
+		{"shop.example.co.uk", true},
+		{"xn--bcher-kva.example", true},
+		{"localhost", false},
+		{"10.0.0.1", false},
+		{"example.com:8080", false},
+		{"-shop.example.com", false},
+		{"shop..example.com", false},
+		{"*.example.com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			err := ValidateDomain(tt.domain)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+			}
+		})
+	}
+}
@@ -0,0 +1,503 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// DefaultExportLinkTTL is how long a completed export can be downloaded
+const DefaultExportLinkTTL = 24 * time.Hour
+
+// Export errors
+var (
+	ErrExportNotFound = errors.New("export not found")
+
+	// ErrExportInProgress is returned when a tenant requests an export while another is being generated
+	ErrExportInProgress = errors.New("an export is already in progress")
+
+	// ErrInvalidDownloadLink is returned when an export download link is forged or expired
+	ErrInvalidDownloadLink = errors.New("invalid or expired download link")
+)
+
+// ExportStatus is the state of a tenant data export
+type ExportStatus string
+
+// Export statuses
+const (
+	ExportPending   ExportStatus = "pending"
+	ExportRunning   ExportStatus = "running"
+	ExportCompleted ExportStatus = "completed"
+	ExportFailed    ExportStatus = "failed"
+)
+
+// Export is a tenant data export
+type Export struct {
+	ID          int64        `json:"id"`
+	TenantID    int64        `json:"tenant_id"`
+	RequestedBy int64        `json:"requested_by"`
+	Status      ExportStatus `json:"status"`
+	// Progress is the percentage of the export generated so far
+	Progress    int        `json:"progress"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	// DownloadURL is a signed link to the archive, set once the export is completed
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// ExportArchive is a downloadable export zip
+type ExportArchive struct {
+	Filename string
+	Data     []byte
+}
+
+// ExportService defines the interface for tenant data exports
+type ExportService interface {
+	// RequestExport starts generating an export of the tenant's data in the background
+	RequestExport(ctx context.Context, tenantID int64, userID int64) (*Export, error)
+
+	// GetExport retrieves a tenant's export and its progress
+	GetExport(ctx context.Context, tenantID int64, exportID int64) (*Export, error)
+
+	// ListExports retrieves a tenant's exports, newest first
+	ListExports(ctx context.Context, tenantID int64) ([]Export, error)
+
+	// OpenDownload retrieves the archive of a completed export from a signed download link
+	OpenDownload(ctx context.Context, exportID int64, expires int64, signature string) (*ExportArchive, error)
+}
+
+// exportDataset is one file set in an export archive
+type exportDataset struct {
+	name  string
+	query string
+}
+
+// exportDatasets are written to each export as <name>.json and <name>.csv
+var exportDatasets = []exportDataset{
+	{
+		name: "users",
+		query: `
+			SELECT u.user_id, u.email, u.first_name, u.last_name, u.created_at
+			FROM usr u
+			WHERE u.user_id IN (SELECT user_id FROM tenant_member WHERE tenant_id = $1)
+			ORDER BY u.user_id
+		`,
+	},
+	{
+		name: "memberships",
+		query: `
+			SELECT user_id, created_at
+			FROM tenant_member
+			WHERE tenant_id = $1
+			ORDER BY user_id
+		`,
+	},
+	{
+		name: "roles",
+		query: `
+			SELECT tr.user_id, r.name AS role, tr.created_at
+			FROM tenant_role tr
+			JOIN role r ON tr.role_id = r.id
+			WHERE tr.tenant_id = $1
+			ORDER BY tr.user_id, r.name
+		`,
+	},
+	{
+		name: "orders",
+		query: `
+			SELECT order_id, user_id, order_number, status, total_amount, notes, created_at, updated_at
+			FROM "order"
+			WHERE tenant_id = $1
+			ORDER BY order_id
+		`,
+	},
+}
+
+// DBExportService implements ExportService using a database. Exports run in a
+// goroutine and the archive is stored with the export.
+type DBExportService struct {
+	db         *sql.DB
+	txManager  transaction.TxManager
+	signingKey []byte
+	linkTTL    time.Duration
+	now        func() time.Time
+
+	// start runs an export in the background
+	start func(exportID int64, tenantID int64)
+}
+
+// NewDBExportService creates a new DBExportService. Download links are signed with a
+// key derived from secret.
+func NewDBExportService(db *sql.DB, secret []byte) *DBExportService {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("tenant-export-download"))
+
+	s := &DBExportService{
+		db:         db,
+		txManager:  transaction.NewManager(db),
+		signingKey: mac.Sum(nil),
+		linkTTL:    DefaultExportLinkTTL,
+		now:        time.Now,
+	}
+	s.start = func(exportID int64, tenantID int64) {
+		go s.runExport(context.Background(), exportID, tenantID)
+	}
+	return s
+}
+
+// WithTxManager runs the DBExportService's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (s *DBExportService) WithTxManager(txManager transaction.TxManager) *DBExportService {
+	s.txManager = txManager
+	return s
+}
+
+// RequestExport starts generating an export of the tenant's data in the background
+func (s *DBExportService) RequestExport(ctx context.Context, tenantID int64, userID int64) (*Export, error) {
+	// Only insert if no export is pending or running for the tenant
+	query := `
+		INSERT INTO tenant_export (tenant_id, requested_by, status)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (
+			SELECT 1 FROM tenant_export WHERE tenant_id = $1 AND status IN ($3, $4)
+		)
+		RETURNING id, created_at
+	`
+
+	export := &Export{
+		TenantID:    tenantID,
+		RequestedBy: userID,
+		Status:      ExportPending,
+	}
+	err := s.db.QueryRowContext(ctx, query, tenantID, userID, ExportPending, ExportRunning).Scan(&export.ID, &export.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExportInProgress
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] User ID %d requested export %d of tenant %d", userID, export.ID, tenantID)
+	s.start(export.ID, tenantID)
+
+	return export, nil
+}
+
+// GetExport retrieves a tenant's export and its progress
+func (s *DBExportService) GetExport(ctx context.Context, tenantID int64, exportID int64) (*Export, error) {
+	query := `
+		SELECT id, tenant_id, requested_by, status, progress, error, created_at, completed_at, expires_at
+		FROM tenant_export
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	export, err := s.scanExport(s.db.QueryRowContext(ctx, query, exportID, tenantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// ListExports retrieves a tenant's exports, newest first
+func (s *DBExportService) ListExports(ctx context.Context, tenantID int64) ([]Export, error) {
+	query := `
+		SELECT id, tenant_id, requested_by, status, progress, error, created_at, completed_at, expires_at
+		FROM tenant_export
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	exports := []Export{}
+	for rows.Next() {
+		export, err := s.scanExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, *export)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return exports, nil
+}
+
+// exportScanner is implemented by *sql.Row and *sql.Rows
+type exportScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanExport scans an export row, adding a signed download URL to completed,
+// unexpired exports. sql.ErrNoRows is returned unwrapped.
+func (s *DBExportService) scanExport(row exportScanner) (*Export, error) {
+	var export Export
+	var requestedBy sql.NullInt64
+	var exportErr sql.NullString
+	var completedAt, expiresAt sql.NullTime
+	err := row.Scan(&export.ID, &export.TenantID, &requestedBy, &export.Status, &export.Progress, &exportErr,
+		&export.CreatedAt, &completedAt, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	export.RequestedBy = requestedBy.Int64
+	export.Error = exportErr.String
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+		if export.Status == ExportCompleted && s.now().Before(expiresAt.Time) {
+			export.DownloadURL = s.downloadURL(export.ID, expiresAt.Time.Unix())
+		}
+	}
+
+	return &export, nil
+}
+
+// downloadURL returns the signed download link of an export
+func (s *DBExportService) downloadURL(exportID int64, expires int64) string {
+	return fmt.Sprintf("/exports/%d/download?expires=%d&signature=%s", exportID, expires, s.sign(exportID, expires))
+}
+
+// sign returns the hex HMAC of an export ID and link expiry
+func (s *DBExportService) sign(exportID int64, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(strconv.FormatInt(exportID, 10) + "." + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// OpenDownload retrieves the archive of a completed export from a signed download link
+func (s *DBExportService) OpenDownload(ctx context.Context, exportID int64, expires int64, signature string) (*ExportArchive, error) {
+	expected, err := hex.DecodeString(s.sign(exportID, expires))
+	if err != nil {
+		return nil, ErrInvalidDownloadLink
+	}
+	provided, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(provided, expected) {
+		log.Printf("[WARN] Rejected download of export %d with an invalid signature", exportID)
+		return nil, ErrInvalidDownloadLink
+	}
+
+	if !s.now().Before(time.Unix(expires, 0)) {
+		return nil, ErrInvalidDownloadLink
+	}
+
+	query := `
+		SELECT tenant_id, archive
+		FROM tenant_export
+		WHERE id = $1 AND status = $2 AND archive IS NOT NULL
+	`
+
+	var tenantID int64
+	var data []byte
+	err = s.db.QueryRowContext(ctx, query, exportID, ExportCompleted).Scan(&tenantID, &data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExportNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return &ExportArchive{
+		Filename: fmt.Sprintf("tenant-%d-export-%d.zip", tenantID, exportID),
+		Data:     data,
+	}, nil
+}
+
+// runExport generates an export archive, recording progress after each dataset
+func (s *DBExportService) runExport(ctx context.Context, exportID int64, tenantID int64) {
+	log.Printf("[INFO] Generating export %d of tenant %d", exportID, tenantID)
+
+	if err := s.setProgress(ctx, exportID, ExportRunning, 0); err != nil {
+		log.Printf("[ERROR] Failed to start export %d: %v", exportID, err)
+		return
+	}
+
+	archive, err := s.buildArchive(ctx, exportID, tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Export %d of tenant %d failed: %v", exportID, tenantID, err)
+		_, updateErr := s.db.ExecContext(ctx, "UPDATE tenant_export SET status = $1, error = $2, completed_at = NOW() WHERE id = $3",
+			ExportFailed, err.Error(), exportID)
+		if updateErr != nil {
+			log.Printf("[ERROR] Failed to mark export %d as failed: %v", exportID, updateErr)
+		}
+		return
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE tenant_export
+		SET status = $1, progress = 100, archive = $2, completed_at = NOW(), expires_at = $3
+		WHERE id = $4
+	`, ExportCompleted, archive, s.now().Add(s.linkTTL), exportID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to store export %d: %v", exportID, err)
+		return
+	}
+
+	log.Printf("[INFO] Completed export %d of tenant %d (%d bytes)", exportID, tenantID, len(archive))
+}
+
+// setProgress updates an export's status and progress
+func (s *DBExportService) setProgress(ctx context.Context, exportID int64, status ExportStatus, progress int) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE tenant_export SET status = $1, progress = $2 WHERE id = $3", status, progress, exportID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	return nil
+}
+
+// buildArchive writes each dataset to a zip archive as JSON and CSV. The datasets
+// are read in one read-only transaction, so they are consistent with each other.
+func (s *DBExportService) buildArchive(ctx context.Context, exportID int64, tenantID int64) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	err := s.txManager.WithReadOnly(ctx, func(ctx context.Context) error {
+		for i, dataset := range exportDatasets {
+			columns, records, err := s.queryDataset(ctx, dataset, tenantID)
+			if err != nil {
+				return fmt.Errorf("failed to export %s: %w", dataset.name, err)
+			}
+
+			if err := writeDatasetFiles(zw, dataset.name, columns, records); err != nil {
+				return fmt.Errorf("failed to write %s: %w", dataset.name, err)
+			}
+
+			// Leave the last step for storing the archive. Progress is written
+			// outside the read-only transaction.
+			progress := (i + 1) * 100 / (len(exportDatasets) + 1)
+			if err := s.setProgress(ctx, exportID, ExportRunning, progress); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// queryDataset runs a dataset query and returns its column names and rows as strings
+func (s *DBExportService) queryDataset(ctx context.Context, dataset exportDataset, tenantID int64) ([]string, [][]string, error) {
+	rows, err := transaction.QuerierFor(ctx, s.db).QueryContext(ctx, dataset.query, tenantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	records := [][]string{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		record := make([]string, len(columns))
+		for i, value := range values {
+			record[i] = exportValue(value)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return columns, records, nil
+}
+
+// exportValue formats a database value for export
+func exportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeDatasetFiles writes a dataset to the archive as <name>.json and <name>.csv
+func writeDatasetFiles(zw *zip.Writer, name string, columns []string, records [][]string) error {
+	objects := make([]map[string]string, 0, len(records))
+	for _, record := range records {
+		object := make(map[string]string, len(columns))
+		for i, column := range columns {
+			object[column] = record[i]
+		}
+		objects = append(objects, object)
+	}
+
+	jsonFile, err := zw.Create(name + ".json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(jsonFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(objects); err != nil {
+		return err
+	}
+
+	csvFile, err := zw.Create(name + ".csv")
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(csvFile)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+
+	return nil
+}
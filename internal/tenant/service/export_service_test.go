@@ -0,0 +1,196 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestExport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBExportService(db, []byte("secret"))
+	var started []int64
+	service.start = func(exportID int64, tenantID int64) { started = append(started, exportID) }
+	ctx := context.Background()
+
+	t.Run("Export is started", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("INSERT INTO tenant_export \\(tenant_id, requested_by, status\\)").
+			WithArgs(int64(1), int64(7), ExportPending, ExportRunning).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, time.Now()))
+
+		// Execute
+		export, err := service.RequestExport(ctx, 1, 7)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), export.ID)
+		assert.Equal(t, ExportPending, export.Status)
+		assert.Equal(t, []int64{5}, started)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Export already in progress", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("INSERT INTO tenant_export").
+			WithArgs(int64(1), int64(7), ExportPending, ExportRunning).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		_, err := service.RequestExport(ctx, 1, 7)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrExportInProgress))
+		assert.Equal(t, []int64{5}, started)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRunExport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBExportService(db, []byte("secret"))
+	ctx := context.Background()
+
+	// Setup mock expectations
+	mock.ExpectExec("UPDATE tenant_export SET status = \\$1, progress = \\$2 WHERE id = \\$3").
+		WithArgs(ExportRunning, 0, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	datasetRows := map[string]*sqlmock.Rows{
+		"users":       sqlmock.NewRows([]string{"user_id", "email", "first_name", "last_name", "created_at"}).AddRow(7, "jo@example.com", "Jo", "Smith, Jr.", createdAt),
+		"memberships": sqlmock.NewRows([]string{"user_id", "created_at"}).AddRow(7, createdAt),
+		"roles":       sqlmock.NewRows([]string{"user_id", "role", "created_at"}).AddRow(7, "TENANT_SUPER", createdAt),
+		"orders":      sqlmock.NewRows([]string{"order_id", "user_id", "order_number", "status", "total_amount", "notes", "created_at", "updated_at"}),
+	}
+	// The datasets are read in one read-only transaction
+	mock.ExpectBegin()
+	for i, dataset := range exportDatasets {
+		mock.ExpectQuery("SELECT").WithArgs(int64(1)).WillReturnRows(datasetRows[dataset.name])
+		mock.ExpectExec("UPDATE tenant_export SET status = \\$1, progress = \\$2").
+			WithArgs(ExportRunning, (i+1)*20, int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	var archive []byte
+	mock.ExpectExec("UPDATE tenant_export SET status = \\$1, progress = 100, archive = \\$2").
+		WithArgs(ExportCompleted, &archiveArg{&archive}, sqlmock.AnyArg(), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	service.runExport(ctx, 5, 1)
+
+	// Assert
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+
+	assert.Len(t, files, 8)
+	assert.Equal(t, "user_id,email,first_name,last_name,created_at\n7,jo@example.com,Jo,\"Smith, Jr.\",2025-03-01T12:00:00Z\n", files["users.csv"])
+	assert.Contains(t, files["roles.json"], `"role": "TENANT_SUPER"`)
+	assert.Equal(t, "[]\n", files["orders.json"])
+}
+
+// archiveArg captures the archive written by an export
+type archiveArg struct {
+	archive *[]byte
+}
+
+// Match implements sqlmock.Argument
+func (a *archiveArg) Match(value driver.Value) bool {
+	data, ok := value.([]byte)
+	*a.archive = data
+	return ok
+}
+
+func TestOpenDownload(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := NewDBExportService(db, []byte("secret"))
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	expires := now.Add(time.Hour).Unix()
+	link, err := url.Parse(service.downloadURL(5, expires))
+	require.NoError(t, err)
+	signature := link.Query().Get("signature")
+	assert.Equal(t, "/exports/5/download", link.Path)
+	assert.Equal(t, strconv.FormatInt(expires, 10), link.Query().Get("expires"))
+
+	t.Run("Valid link", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT tenant_id, archive FROM tenant_export WHERE id = \\$1 AND status = \\$2 AND archive IS NOT NULL").
+			WithArgs(int64(5), ExportCompleted).
+			WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "archive"}).AddRow(1, []byte("zip")))
+
+		// Execute
+		archive, err := service.OpenDownload(ctx, 5, expires, signature)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "tenant-1-export-5.zip", archive.Filename)
+		assert.Equal(t, []byte("zip"), archive.Data)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Link for another export", func(t *testing.T) {
+		// Execute
+		_, err := service.OpenDownload(ctx, 6, expires, signature)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidDownloadLink))
+	})
+
+	t.Run("Extended expiry", func(t *testing.T) {
+		// Execute
+		_, err := service.OpenDownload(ctx, 5, expires+3600, signature)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidDownloadLink))
+	})
+
+	t.Run("Expired link", func(t *testing.T) {
+		// Setup
+		expired := now.Add(-time.Minute).Unix()
+		expiredSignature := service.sign(5, expired)
+
+		// Execute
+		_, err := service.OpenDownload(ctx, 5, expired, expiredSignature)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidDownloadLink))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// Invitation errors
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+)
+
+const (
+	// DefaultInvitationTTL is how long an invitation can be accepted
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+
+	// invitationTokenSize is the number of random bytes in an invitation token
+	invitationTokenSize = 32
+)
+
+// Invitation represents an invitation for a user to join a tenant
+type Invitation struct {
+	ID         int64      `json:"id"`
+	TenantID   int64      `json:"tenant_id"`
+	Email      string     `json:"email"`
+	RoleID     *int64     `json:"role_id,omitempty"`
+	InvitedBy  *int64     `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// InvitationService defines the interface for tenant invitation operations
+type InvitationService interface {
+	// CreateInvitation creates an invitation and returns it with its token.
+	// The token is only available at creation time.
+	CreateInvitation(ctx context.Context, tenantID int64, email string, roleID *int64, invitedBy int64) (*Invitation, string, error)
+
+	// ListInvitations retrieves pending invitations for a tenant
+	ListInvitations(ctx context.Context, tenantID int64) ([]Invitation, error)
+
+	// RevokeInvitation deletes a pending invitation
+	RevokeInvitation(ctx context.Context, tenantID int64, invitationID int64) error
+}
+
+// DBInvitationService implements InvitationService using a database
+type DBInvitationService struct {
+	db    *sql.DB
+	ttl   time.Duration
+	quota QuotaService
+}
+
+// NewDBInvitationService creates a new DBInvitationService
+func NewDBInvitationService(db *sql.DB) *DBInvitationService {
+	return &DBInvitationService{db: db, ttl: DefaultInvitationTTL}
+}
+
+// WithMemberQuota rejects invitations to tenants that have reached their member
+// limit with ErrQuotaExceeded. The limit is checked again when an invitation is
+// accepted.
+func (s *DBInvitationService) WithMemberQuota(quota QuotaService) *DBInvitationService {
+	s.quota = quota
+	return s
+}
+
+// CreateInvitation creates an invitation and returns it with its token
+func (s *DBInvitationService) CreateInvitation(ctx context.Context, tenantID int64, email string, roleID *int64, invitedBy int64) (*Invitation, string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, "", fmt.Errorf("%w: a valid email is required", ErrInvalidInput)
+	}
+
+	if roleID != nil {
+		if err := s.checkInvitationRole(ctx, *roleID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Invitees aren't members yet, so no user is excluded from the count
+	if s.quota != nil {
+		if err := s.quota.CheckMemberQuota(ctx, tenantID, 0); err != nil {
+			return nil, "", err
+		}
+	}
+
+	tokenBytes := make([]byte, invitationTokenSize)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	query := `
+		INSERT INTO tenant_invitation (tenant_id, email, role_id, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	invitation := &Invitation{
+		TenantID:  tenantID,
+		Email:     email,
+		RoleID:    roleID,
+		InvitedBy: &invitedBy,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID, email, roleID, HashToken(token), invitedBy, invitation.ExpiresAt).
+		Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		log.Printf("[ERROR] Database error when creating invitation for %s to tenant %d: %v", email, tenantID, err)
+		return nil, "", fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Invitation %d created for %s to tenant %d", invitation.ID, email, tenantID)
+	return invitation, token, nil
+}
+
+// checkInvitationRole rejects invitations with a role that doesn't exist or may
+// not be assigned within a tenant, such as ADMIN
+func (s *DBInvitationService) checkInvitationRole(ctx context.Context, roleID int64) error {
+	var name string
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, "SELECT name FROM role WHERE id = $1", roleID).Scan(&name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: role %d does not exist", ErrInvalidInput, roleID)
+		}
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if authctx.IsPlatformRole(authctx.Role(name)) {
+		return fmt.Errorf("%w: role %s cannot be assigned within a tenant", ErrInvalidInput, name)
+	}
+	return nil
+}
+
+// ListInvitations retrieves pending invitations for a tenant
+func (s *DBInvitationService) ListInvitations(ctx context.Context, tenantID int64) ([]Invitation, error) {
+	query := `
+		SELECT id, tenant_id, email, role_id, invited_by, expires_at, accepted_at, created_at
+		FROM tenant_invitation
+		WHERE tenant_id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, err := transaction.QuerierFor(ctx, s.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		var invitation Invitation
+		var roleID, invitedBy sql.NullInt64
+		var acceptedAt sql.NullTime
+		if err := rows.Scan(
+			&invitation.ID,
+			&invitation.TenantID,
+			&invitation.Email,
+			&roleID,
+			&invitedBy,
+			&invitation.ExpiresAt,
+			&acceptedAt,
+			&invitation.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if roleID.Valid {
+			invitation.RoleID = &roleID.Int64
+		}
+		if invitedBy.Valid {
+			invitation.InvitedBy = &invitedBy.Int64
+		}
+		if acceptedAt.Valid {
+			invitation.AcceptedAt = &acceptedAt.Time
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation deletes a pending invitation
+func (s *DBInvitationService) RevokeInvitation(ctx context.Context, tenantID int64, invitationID int64) error {
+	result, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx,
+		"DELETE FROM tenant_invitation WHERE id = $1 AND tenant_id = $2 AND accepted_at IS NULL",
+		invitationID, tenantID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrInvitationNotFound
+	}
+
+	log.Printf("[INFO] Invitation %d revoked for tenant %d", invitationID, tenantID)
+	return nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hashArg matches an argument against the hash of the captured invitation token
+type hashArg struct {
+	hash *string
+}
+
+func (a hashArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if ok {
+		*a.hash = s
+	}
+	return ok && len(s) == 64
+}
+
+func TestCreateInvitation(t *testing.T) {
+	ctx := context.Background()
+	tenantID := int64(7)
+	invitedBy := int64(3)
+
+	t.Run("Stores only the token hash", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		var storedHash string
+		mock.ExpectQuery("INSERT INTO tenant_invitation").
+			WithArgs(tenantID, "new@example.com", nil, hashArg{hash: &storedHash}, invitedBy, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+		service := NewDBInvitationService(db)
+		invitation, token, err := service.CreateInvitation(ctx, tenantID, " new@example.com ", nil, invitedBy)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), invitation.ID)
+		assert.Equal(t, "new@example.com", invitation.Email)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, HashToken(token), storedHash)
+		assert.NotEqual(t, token, storedHash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Stores the tenant role", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		roleID := int64(3)
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(roleID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("TENANT_SUPER"))
+		mock.ExpectQuery("INSERT INTO tenant_invitation").
+			WithArgs(tenantID, "new@example.com", &roleID, sqlmock.AnyArg(), invitedBy, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+		invitation, _, err := NewDBInvitationService(db).CreateInvitation(ctx, tenantID, "new@example.com", &roleID, invitedBy)
+
+		require.NoError(t, err)
+		assert.Equal(t, &roleID, invitation.RoleID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Platform role rejected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		roleID := int64(1)
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(roleID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("ADMIN"))
+
+		_, _, err = NewDBInvitationService(db).CreateInvitation(ctx, tenantID, "new@example.com", &roleID, invitedBy)
+
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown role rejected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		roleID := int64(99)
+		mock.ExpectQuery("SELECT name FROM role WHERE id = \\$1").
+			WithArgs(roleID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+		_, _, err = NewDBInvitationService(db).CreateInvitation(ctx, tenantID, "new@example.com", &roleID, invitedBy)
+
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Tenant at its member limit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT max_members, max_orders, max_api_requests, max_storage_bytes, updated_at FROM tenant_quota").
+			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"max_members", "max_orders", "max_api_requests", "max_storage_bytes", "updated_at"}).
+				AddRow(2, nil, nil, nil, time.Now()))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant_member").
+			WithArgs(tenantID, int64(0)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		service := NewDBInvitationService(db).WithMemberQuota(NewDBQuotaService(db))
+		_, _, err = service.CreateInvitation(ctx, tenantID, "new@example.com", nil, invitedBy)
+
+		assert.True(t, errors.Is(err, ErrQuotaExceeded))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		_, _, err = NewDBInvitationService(db).CreateInvitation(ctx, tenantID, "not-an-email", nil, invitedBy)
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM tenant_invitation").
+		WithArgs(int64(9), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = NewDBInvitationService(db).RevokeInvitation(context.Background(), 7, 9)
+	assert.True(t, errors.Is(err, ErrInvitationNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// ErrIPRuleNotFound is returned when a tenant has no IP rule with the given ID
+var ErrIPRuleNotFound = errors.New("IP rule not found")
+
+// IP rule actions
+const (
+	IPRuleAllow = "allow"
+	IPRuleDeny  = "deny"
+)
+
+// maxIPRuleDescriptionLength is the longest description of an IP rule
+const maxIPRuleDescriptionLength = 255
+
+// IPRule allows or denies access to a tenant from a range of IP addresses
+type IPRule struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
+	// CIDR is the range of addresses, such as 203.0.113.0/24 or 2001:db8::/32
+	CIDR        string    `json:"cidr"`
+	Action      string    `json:"action"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IPAccessList decides which IP addresses may access a tenant, or the platform
+type IPAccessList struct {
+	Allow []netip.Prefix
+	Deny  []netip.Prefix
+}
+
+// NewIPAccessList creates the access list of a tenant's rules. Rules whose range
+// can't be parsed are skipped.
+func NewIPAccessList(rules []IPRule) IPAccessList {
+	var list IPAccessList
+	for _, rule := range rules {
+		prefix, err := ParseIPPrefix(rule.CIDR)
+		if err != nil {
+			log.Printf("[WARN] Skipping IP rule %d of tenant %d: %v", rule.ID, rule.TenantID, err)
+			continue
+		}
+		switch rule.Action {
+		case IPRuleAllow:
+			list.Allow = append(list.Allow, prefix)
+		case IPRuleDeny:
+			list.Deny = append(list.Deny, prefix)
+		}
+	}
+	return list
+}
+
+// Empty reports whether the list has no rules, letting every address in
+func (l IPAccessList) Empty() bool {
+	return len(l.Allow) == 0 && len(l.Deny) == 0
+}
+
+// Permits reports whether an address may access. Addresses in a denied range
+// are blocked. If there are allowed ranges, addresses outside them are blocked
+// too.
+func (l IPAccessList) Permits(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range l.Deny {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if len(l.Allow) == 0 {
+		return true
+	}
+	for _, prefix := range l.Allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIPPrefix parses a CIDR range, or a single address as a range of one.
+// Host bits are cleared, so 10.1.2.3/8 is 10.0.0.0/8.
+func ParseIPPrefix(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidInput, value)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidInput, value)
+	}
+	return prefix.Masked(), nil
+}
+
+// IPRuleService defines the interface for tenant IP rule operations
+type IPRuleService interface {
+	// AddIPRule adds an allow or deny rule to a tenant
+	AddIPRule(ctx context.Context, tenantID int64, rule IPRule) (*IPRule, error)
+
+	// ListIPRules retrieves a tenant's IP rules
+	ListIPRules(ctx context.Context, tenantID int64) ([]IPRule, error)
+
+	// RemoveIPRule removes one of a tenant's IP rules
+	RemoveIPRule(ctx context.Context, tenantID int64, ruleID int64) error
+}
+
+// DBIPRuleService implements IPRuleService using a database
+type DBIPRuleService struct {
+	db *sql.DB
+}
+
+// Ensure DBIPRuleService implements IPRuleService
+var _ IPRuleService = (*DBIPRuleService)(nil)
+
+// NewDBIPRuleService creates a new DBIPRuleService
+func NewDBIPRuleService(db *sql.DB) *DBIPRuleService {
+	return &DBIPRuleService{db: db}
+}
+
+// AddIPRule validates a rule, normalizes its range and adds it to a tenant
+func (s *DBIPRuleService) AddIPRule(ctx context.Context, tenantID int64, rule IPRule) (*IPRule, error) {
+	if rule.Action != IPRuleAllow && rule.Action != IPRuleDeny {
+		return nil, fmt.Errorf("%w: action must be %q or %q", ErrInvalidInput, IPRuleAllow, IPRuleDeny)
+	}
+	prefix, err := ParseIPPrefix(rule.CIDR)
+	if err != nil {
+		return nil, err
+	}
+	rule.Description = strings.TrimSpace(rule.Description)
+	if len(rule.Description) > maxIPRuleDescriptionLength {
+		return nil, fmt.Errorf("%w: description must not be longer than %d characters", ErrInvalidInput, maxIPRuleDescriptionLength)
+	}
+
+	rule.TenantID = tenantID
+	rule.CIDR = prefix.String()
+
+	query := `
+		INSERT INTO tenant_ip_rule (tenant_id, cidr, action, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err = transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID, rule.CIDR, rule.Action, rule.Description).
+		Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: the tenant already has a %s rule for %s", ErrInvalidInput, rule.Action, rule.CIDR)
+		}
+		log.Printf("[ERROR] Database error when adding IP rule %s %s to tenant %d: %v", rule.Action, rule.CIDR, tenantID, err)
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] IP rule %s %s added to tenant %d", rule.Action, rule.CIDR, tenantID)
+	return &rule, nil
+}
+
+// ListIPRules retrieves a tenant's IP rules, deny rules first
+func (s *DBIPRuleService) ListIPRules(ctx context.Context, tenantID int64) ([]IPRule, error) {
+	query := `
+		SELECT id, tenant_id, cidr::TEXT, action, description, created_at
+		FROM tenant_ip_rule
+		WHERE tenant_id = $1
+		ORDER BY action DESC, cidr
+	`
+
+	rows, err := transaction.QuerierFor(ctx, s.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	rules := []IPRule{}
+	for rows.Next() {
+		var rule IPRule
+		if err := rows.Scan(&rule.ID, &rule.TenantID, &rule.CIDR, &rule.Action, &rule.Description, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return rules, nil
+}
+
+// RemoveIPRule removes one of a tenant's IP rules
+func (s *DBIPRuleService) RemoveIPRule(ctx context.Context, tenantID int64, ruleID int64) error {
+	result, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx,
+		"DELETE FROM tenant_ip_rule WHERE id = $1 AND tenant_id = $2", ruleID, tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Database error when removing IP rule %d from tenant %d: %v", ruleID, tenantID, err)
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if removed == 0 {
+		return ErrIPRuleNotFound
+	}
+
+	log.Printf("[INFO] IP rule %d removed from tenant %d", ruleID, tenantID)
+	return nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/cache"
+)
+
+func TestParseIPPrefix(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"203.0.113.0/24", "203.0.113.0/24"},
+		{" 10.1.2.3/8 ", "10.0.0.0/8"},
+		{"198.51.100.7", "198.51.100.7/32"},
+		{"::ffff:198.51.100.7", "198.51.100.7/32"},
+		{"2001:db8::1/32", "2001:db8::/32"},
+	}
+	for _, tt := range tests {
+		prefix, err := ParseIPPrefix(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.expected, prefix.String())
+	}
+
+	for _, value := range []string{"", "example.com", "10.0.0.0/33", "10.0.0"} {
+		_, err := ParseIPPrefix(value)
+		assert.ErrorIs(t, err, ErrInvalidInput, value)
+	}
+}
+
+func TestIPAccessList(t *testing.T) {
+	addr := netip.MustParseAddr
+
+	t.Run("Empty list permits every address", func(t *testing.T) {
+		list := NewIPAccessList(nil)
+		assert.True(t, list.Empty())
+		assert.True(t, list.Permits(addr("203.0.113.9")))
+	})
+
+	t.Run("Deny rules block their ranges", func(t *testing.T) {
+		list := NewIPAccessList([]IPRule{{CIDR: "203.0.113.0/24", Action: IPRuleDeny}})
+		assert.False(t, list.Permits(addr("203.0.113.9")))
+		assert.False(t, list.Permits(addr("::ffff:203.0.113.9")))
+		assert.True(t, list.Permits(addr("198.51.100.1")))
+	})
+
+	t.Run("Allow rules block addresses outside them", func(t *testing.T) {
+		list := NewIPAccessList([]IPRule{{CIDR: "10.0.0.0/8", Action: IPRuleAllow}})
+		assert.True(t, list.Permits(addr("10.20.30.40")))
+		assert.False(t, list.Permits(addr("198.51.100.1")))
+	})
+
+	t.Run("Deny rules take precedence", func(t *testing.T) {
+		list := NewIPAccessList([]IPRule{
+			{CIDR: "10.0.0.0/8", Action: IPRuleAllow},
+			{CIDR: "10.9.0.0/16", Action: IPRuleDeny},
+		})
+		assert.True(t, list.Permits(addr("10.1.0.1")))
+		assert.False(t, list.Permits(addr("10.9.0.1")))
+	})
+}
+
+func TestAddIPRule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Range is normalized", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBIPRuleService(db)
+
+		mock.ExpectQuery("INSERT INTO tenant_ip_rule \\(tenant_id, cidr, action, description\\)").
+			WithArgs(int64(1), "10.0.0.0/8", IPRuleAllow, "Office").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+		rule, err := service.AddIPRule(ctx, 1, IPRule{CIDR: "10.1.2.3/8", Action: IPRuleAllow, Description: " Office "})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), rule.ID)
+		assert.Equal(t, int64(1), rule.TenantID)
+		assert.Equal(t, "10.0.0.0/8", rule.CIDR)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid rules are rejected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBIPRuleService(db)
+
+		_, err = service.AddIPRule(ctx, 1, IPRule{CIDR: "10.0.0.0/8", Action: "block"})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+
+		_, err = service.AddIPRule(ctx, 1, IPRule{CIDR: "intranet", Action: IPRuleDeny})
+		assert.ErrorIs(t, err, ErrInvalidInput)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRemoveIPRule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBIPRuleService(db)
+
+	mock.ExpectExec("DELETE FROM tenant_ip_rule WHERE id = \\$1 AND tenant_id = \\$2").
+		WithArgs(int64(3), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = service.RemoveIPRule(context.Background(), 1, 3)
+
+	assert.ErrorIs(t, err, ErrIPRuleNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachingIPRuleService(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewCachingIPRuleService(NewDBIPRuleService(db), cache.NewLRUCache(10, time.Minute))
+	columns := []string{"id", "tenant_id", "cidr", "action", "description", "created_at"}
+
+	// Rules are loaded once, then served from the cache
+	mock.ExpectQuery("SELECT (.+) FROM tenant_ip_rule").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 1, "10.0.0.0/8", IPRuleAllow, "", time.Now()))
+
+	for i := 0; i < 2; i++ {
+		rules, err := service.ListIPRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, "10.0.0.0/8", rules[0].CIDR)
+	}
+
+	// Removing a rule invalidates the cached rules
+	mock.ExpectExec("DELETE FROM tenant_ip_rule").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM tenant_ip_rule").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	require.NoError(t, service.RemoveIPRule(ctx, 1, 3))
+	rules, err := service.ListIPRules(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultTenantRetention is how long soft deleted tenants can be restored
+	DefaultTenantRetention = 30 * 24 * time.Hour
+
+	// DefaultPurgeInterval is how often expired tenants are purged
+	DefaultPurgeInterval = time.Hour
+
+	// Environment variable names
+	envTenantRetentionDays  = "TENANT_RETENTION_DAYS"
+	envTenantPurgeIntervalM = "TENANT_PURGE_INTERVAL_MINUTES"
+)
+
+// LifecycleConfig holds configuration for soft deleted tenants
+type LifecycleConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// LoadLifecycleConfig loads tenant lifecycle configuration from environment variables
+func LoadLifecycleConfig() (LifecycleConfig, error) {
+	config := LifecycleConfig{
+		Retention:     DefaultTenantRetention,
+		PurgeInterval: DefaultPurgeInterval,
+	}
+
+	if daysStr := os.Getenv(envTenantRetentionDays); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			return LifecycleConfig{}, fmt.Errorf("invalid TENANT_RETENTION_DAYS value: %q", daysStr)
+		}
+		config.Retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	if minutesStr := os.Getenv(envTenantPurgeIntervalM); minutesStr != "" {
+		minutes, err := strconv.Atoi(minutesStr)
+		if err != nil || minutes <= 0 {
+			return LifecycleConfig{}, fmt.Errorf("invalid TENANT_PURGE_INTERVAL_MINUTES value: %q", minutesStr)
+		}
+		config.PurgeInterval = time.Duration(minutes) * time.Minute
+	}
+
+	return config, nil
+}
+
+// StartPurgeJob purges expired soft deleted tenants every interval until ctx is cancelled
+func StartPurgeJob(ctx context.Context, tenantService TenantService, interval time.Duration) {
+	log.Printf("[INFO] Starting tenant purge job every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("[INFO] Stopping tenant purge job")
+				return
+			case <-ticker.C:
+				report, err := tenantService.PurgeDeletedTenants(ctx)
+				if err != nil {
+					log.Printf("[ERROR] Failed to purge deleted tenants: %v", err)
+					continue
+				}
+				if report.Tenants > 0 {
+					log.Printf("[INFO] Purged %d deleted tenants, removing rows: %v", report.Tenants, report.Rows)
+				}
+			}
+		}
+	}()
+}
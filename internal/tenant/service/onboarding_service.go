@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// OnboardingStep identifies a step of a tenant's onboarding checklist
+type OnboardingStep string
+
+// Onboarding steps
+const (
+	OnboardingStepInviteMembers     OnboardingStep = "invite_members"
+	OnboardingStepConfigureBranding OnboardingStep = "configure_branding"
+	OnboardingStepCreateFirstOrder  OnboardingStep = "create_first_order"
+)
+
+// OnboardingStatus is the state of a tenant's onboarding checklist. Checklists
+// move from not started to in progress when the first step is done, and to
+// completed when every step is done. Completed checklists stay completed. An
+// unfinished checklist can be dismissed.
+type OnboardingStatus string
+
+// Onboarding statuses
+const (
+	OnboardingStatusNotStarted OnboardingStatus = "not_started"
+	OnboardingStatusInProgress OnboardingStatus = "in_progress"
+	OnboardingStatusCompleted  OnboardingStatus = "completed"
+	OnboardingStatusDismissed  OnboardingStatus = "dismissed"
+)
+
+// OnboardingTask is a step of the checklist and whether the tenant has done it
+type OnboardingTask struct {
+	Step  OnboardingStep `json:"step"`
+	Title string         `json:"title"`
+	Done  bool           `json:"done"`
+}
+
+// Onboarding is a tenant's onboarding checklist
+type Onboarding struct {
+	TenantID    int64            `json:"tenant_id"`
+	Status      OnboardingStatus `json:"status"`
+	Tasks       []OnboardingTask `json:"tasks"`
+	Done        int              `json:"done"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	DismissedAt *time.Time       `json:"dismissed_at,omitempty"`
+}
+
+// onboardingTitles are the titles of the steps, in checklist order
+var onboardingTitles = []struct {
+	step  OnboardingStep
+	title string
+}{
+	{OnboardingStepInviteMembers, "Invite your team"},
+	{OnboardingStepConfigureBranding, "Add your logo and colors"},
+	{OnboardingStepCreateFirstOrder, "Create your first order"},
+}
+
+// OnboardingService defines the interface for tenant onboarding operations
+type OnboardingService interface {
+	// GetOnboarding computes a tenant's onboarding checklist from its data
+	GetOnboarding(ctx context.Context, tenantID int64) (*Onboarding, error)
+
+	// DismissOnboarding hides an unfinished onboarding checklist
+	DismissOnboarding(ctx context.Context, tenantID int64) (*Onboarding, error)
+}
+
+// DBOnboardingService implements OnboardingService using a database
+type DBOnboardingService struct {
+	db *sql.DB
+}
+
+// Ensure DBOnboardingService implements OnboardingService
+var _ OnboardingService = (*DBOnboardingService)(nil)
+
+// NewDBOnboardingService creates a new DBOnboardingService
+func NewDBOnboardingService(db *sql.DB) *DBOnboardingService {
+	return &DBOnboardingService{
+		db: db,
+	}
+}
+
+// GetOnboarding computes a tenant's onboarding checklist from its data. The
+// checklist is recorded as completed the first time every step is done.
+func (s *DBOnboardingService) GetOnboarding(ctx context.Context, tenantID int64) (*Onboarding, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM tenant_member WHERE tenant_id = $1) > 1
+				OR EXISTS (SELECT 1 FROM tenant_invitation WHERE tenant_id = $1),
+			EXISTS (SELECT 1 FROM tenant_branding WHERE tenant_id = $1),
+			EXISTS (SELECT 1 FROM "order" WHERE tenant_id = $1),
+			o.completed_at,
+			o.dismissed_at
+		FROM (SELECT 1) AS t
+		LEFT JOIN tenant_onboarding o ON o.tenant_id = $1
+	`
+
+	var invited, branded, ordered bool
+	var completedAt, dismissedAt sql.NullTime
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID).Scan(&invited, &branded, &ordered, &completedAt, &dismissedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	done := map[OnboardingStep]bool{
+		OnboardingStepInviteMembers:     invited,
+		OnboardingStepConfigureBranding: branded,
+		OnboardingStepCreateFirstOrder:  ordered,
+	}
+
+	onboarding := &Onboarding{
+		TenantID: tenantID,
+		Tasks:    make([]OnboardingTask, 0, len(onboardingTitles)),
+	}
+	for _, step := range onboardingTitles {
+		onboarding.Tasks = append(onboarding.Tasks, OnboardingTask{Step: step.step, Title: step.title, Done: done[step.step]})
+		if done[step.step] {
+			onboarding.Done++
+		}
+	}
+	if dismissedAt.Valid {
+		onboarding.DismissedAt = &dismissedAt.Time
+	}
+
+	if !completedAt.Valid && onboarding.Done == len(onboarding.Tasks) {
+		if err := s.recordCompleted(ctx, tenantID, &completedAt); err != nil {
+			return nil, err
+		}
+		log.Printf("[INFO] Tenant %d completed onboarding", tenantID)
+	}
+
+	switch {
+	case completedAt.Valid:
+		onboarding.Status = OnboardingStatusCompleted
+		onboarding.CompletedAt = &completedAt.Time
+	case dismissedAt.Valid:
+		onboarding.Status = OnboardingStatusDismissed
+	case onboarding.Done > 0:
+		onboarding.Status = OnboardingStatusInProgress
+	default:
+		onboarding.Status = OnboardingStatusNotStarted
+	}
+
+	return onboarding, nil
+}
+
+// DismissOnboarding hides an unfinished onboarding checklist. Dismissing a
+// dismissed or completed checklist has no effect.
+func (s *DBOnboardingService) DismissOnboarding(ctx context.Context, tenantID int64) (*Onboarding, error) {
+	query := `
+		INSERT INTO tenant_onboarding (tenant_id, dismissed_at, updated_at)
+		VALUES ($1, NOW(), NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET dismissed_at = COALESCE(tenant_onboarding.dismissed_at, EXCLUDED.dismissed_at),
+			updated_at = NOW()
+		WHERE tenant_onboarding.completed_at IS NULL
+	`
+
+	if _, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, query, tenantID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Tenant %d dismissed onboarding", tenantID)
+	return s.GetOnboarding(ctx, tenantID)
+}
+
+// recordCompleted records that a tenant completed its onboarding checklist,
+// keeping the first completion time
+func (s *DBOnboardingService) recordCompleted(ctx context.Context, tenantID int64, completedAt *sql.NullTime) error {
+	query := `
+		INSERT INTO tenant_onboarding (tenant_id, completed_at, updated_at)
+		VALUES ($1, NOW(), NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET completed_at = COALESCE(tenant_onboarding.completed_at, EXCLUDED.completed_at),
+			updated_at = NOW()
+		RETURNING completed_at
+	`
+
+	if err := s.db.QueryRowContext(ctx, query, tenantID).Scan(completedAt); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var onboardingColumns = []string{"invited", "branded", "ordered", "completed_at", "dismissed_at"}
+
+func TestGetOnboarding(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Not started", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBOnboardingService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM \\(SELECT 1\\) AS t LEFT JOIN tenant_onboarding o ON o.tenant_id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(onboardingColumns).AddRow(false, false, false, nil, nil))
+
+		// Execute
+		onboarding, err := service.GetOnboarding(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, OnboardingStatusNotStarted, onboarding.Status)
+		assert.Len(t, onboarding.Tasks, 3)
+		assert.Equal(t, 0, onboarding.Done)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBOnboardingService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM \\(SELECT 1\\) AS t").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(onboardingColumns).AddRow(true, false, true, nil, nil))
+
+		// Execute
+		onboarding, err := service.GetOnboarding(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, OnboardingStatusInProgress, onboarding.Status)
+		assert.Equal(t, 2, onboarding.Done)
+		assert.Equal(t, OnboardingTask{Step: OnboardingStepConfigureBranding, Title: "Add your logo and colors", Done: false}, onboarding.Tasks[1])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Completing the last step is recorded", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBOnboardingService(db)
+
+		// Setup mock expectations
+		completedAt := time.Now()
+		mock.ExpectQuery("SELECT (.+) FROM \\(SELECT 1\\) AS t").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(onboardingColumns).AddRow(true, true, true, nil, nil))
+		mock.ExpectQuery("INSERT INTO tenant_onboarding \\(tenant_id, completed_at, updated_at\\)").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"completed_at"}).AddRow(completedAt))
+
+		// Execute
+		onboarding, err := service.GetOnboarding(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, OnboardingStatusCompleted, onboarding.Status)
+		assert.Equal(t, completedAt, *onboarding.CompletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Completed checklist stays completed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBOnboardingService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM \\(SELECT 1\\) AS t").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(onboardingColumns).AddRow(true, false, true, time.Now(), nil))
+
+		// Execute
+		onboarding, err := service.GetOnboarding(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, OnboardingStatusCompleted, onboarding.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBOnboardingService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM \\(SELECT 1\\) AS t").
+			WithArgs(int64(1)).
+			WillReturnError(errors.New("connection reset"))
+
+		// Execute
+		_, err = service.GetOnboarding(ctx, 1)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDismissOnboarding(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBOnboardingService(db)
+
+	// Setup mock expectations
+	dismissedAt := time.Now()
+	mock.ExpectExec("INSERT INTO tenant_onboarding \\(tenant_id, dismissed_at, updated_at\\) (.+) WHERE tenant_onboarding.completed_at IS NULL").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM \\(SELECT 1\\) AS t").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(onboardingColumns).AddRow(true, false, false, nil, dismissedAt))
+
+	// Execute
+	onboarding, err := service.DismissOnboarding(context.Background(), 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, OnboardingStatusDismissed, onboarding.Status)
+	assert.Equal(t, dismissedAt, *onboarding.DismissedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	"github.com/unsavory/silocore-go/internal/mail"
+)
+
+// Ownership transfer errors
+var (
+	// ErrTransferNotFound is returned when a confirmation token doesn't match a pending
+	// transfer to the confirming user, or the transfer has expired
+	ErrTransferNotFound = errors.New("ownership transfer not found or expired")
+
+	// ErrTransferEmailFailed is returned when the confirmation email can't be sent
+	ErrTransferEmailFailed = errors.New("failed to send ownership transfer confirmation")
+)
+
+const (
+	// DefaultOwnershipTransferTTL is how long the new owner has to confirm a transfer
+	DefaultOwnershipTransferTTL = 48 * time.Hour
+
+	// transferTokenSize is the number of random bytes in a transfer confirmation token
+	transferTokenSize = 32
+)
+
+// OwnershipTransfer is a pending or confirmed handover of a tenant to a new owner
+type OwnershipTransfer struct {
+	ID         int64  `json:"id"`
+	TenantID   int64  `json:"tenant_id"`
+	FromUserID *int64 `json:"from_user_id"`
+	ToUserID   int64  `json:"to_user_id"`
+	// DemotePrevious revokes TENANT_SUPER from the previous owner on confirmation
+	DemotePrevious bool       `json:"demote_previous"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	ConfirmedAt    *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// OwnershipService defines the interface for tenant ownership transfers
+type OwnershipService interface {
+	// RequestTransfer starts a transfer of a tenant from one member to another and
+	// emails the new owner a link to confirmURL with the confirmation token. Any
+	// pending transfer for the tenant is replaced.
+	RequestTransfer(ctx context.Context, tenantID int64, fromUserID int64, toUserID int64, demotePrevious bool, confirmURL string) (*OwnershipTransfer, error)
+
+	// ConfirmTransfer completes a transfer as the new owner, granting them TENANT_SUPER
+	// and, if requested, revoking it from the previous owner in one transaction
+	ConfirmTransfer(ctx context.Context, userID int64, token string) (*OwnershipTransfer, error)
+}
+
+// DBOwnershipService implements OwnershipService using a database
+type DBOwnershipService struct {
+	db       *sql.DB
+	sender   mail.Sender
+	recorder auditservice.AuditRecorder
+	ttl      time.Duration
+	now      func() time.Time
+
+	// onChange is called for each user whose tenant roles changed
+	onChange func(ctx context.Context, userID int64, tenantID int64)
+}
+
+// Ensure DBOwnershipService implements OwnershipService
+var _ OwnershipService = (*DBOwnershipService)(nil)
+
+// NewDBOwnershipService creates a new DBOwnershipService
+func NewDBOwnershipService(db *sql.DB, sender mail.Sender, recorder auditservice.AuditRecorder) *DBOwnershipService {
+	return &DBOwnershipService{
+		db:       db,
+		sender:   sender,
+		recorder: recorder,
+		ttl:      DefaultOwnershipTransferTTL,
+		now:      time.Now,
+	}
+}
+
+// OnRoleChange registers a hook that is called after a confirmed transfer changes a
+// user's tenant roles, e.g. to invalidate cached tenant roles
+func (s *DBOwnershipService) OnRoleChange(hook func(ctx context.Context, userID int64, tenantID int64)) *DBOwnershipService {
+	s.onChange = hook
+	return s
+}
+
+// RequestTransfer starts a transfer of a tenant and emails the new owner a confirmation link
+func (s *DBOwnershipService) RequestTransfer(ctx context.Context, tenantID int64, fromUserID int64, toUserID int64, demotePrevious bool, confirmURL string) (*OwnershipTransfer, error) {
+	if toUserID == 0 {
+		return nil, fmt.Errorf("%w: new owner user ID is required", ErrInvalidInput)
+	}
+	if toUserID == fromUserID {
+		return nil, fmt.Errorf("%w: cannot transfer ownership to yourself", ErrInvalidInput)
+	}
+
+	tokenBytes := make([]byte, transferTokenSize)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate transfer token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	// The new owner must already be a member of the tenant
+	var email, tenantName string
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.email, t.name
+		FROM tenant_member tm
+		JOIN usr u ON u.user_id = tm.user_id
+		JOIN tenant t ON t.id = tm.tenant_id
+		WHERE tm.tenant_id = $1 AND tm.user_id = $2
+	`, tenantID, toUserID).Scan(&email, &tenantName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: the new owner must be a member of the tenant", ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Replace any transfer that is still waiting for confirmation
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tenant_ownership_transfer WHERE tenant_id = $1 AND confirmed_at IS NULL", tenantID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	transfer := &OwnershipTransfer{
+		TenantID:       tenantID,
+		FromUserID:     &fromUserID,
+		ToUserID:       toUserID,
+		DemotePrevious: demotePrevious,
+		ExpiresAt:      s.now().Add(s.ttl),
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tenant_ownership_transfer (tenant_id, from_user_id, to_user_id, demote_previous, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, tenantID, fromUserID, toUserID, demotePrevious, HashToken(token), transfer.ExpiresAt).
+		Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	message := mail.Message{
+		To:      email,
+		Subject: fmt.Sprintf("Confirm ownership of %s", tenantName),
+		Body: fmt.Sprintf(
+			"You have been asked to become the owner of %s.\n\nConfirm the transfer within %s at:\n%s?token=%s\n\nIf you weren't expecting this, you can ignore this email.\n",
+			tenantName, s.ttl, confirmURL, url.QueryEscape(token),
+		),
+	}
+	if err := s.sender.Send(ctx, message); err != nil {
+		log.Printf("[ERROR] Failed to email ownership transfer %d for tenant %d: %v", transfer.ID, tenantID, err)
+		return nil, ErrTransferEmailFailed
+	}
+
+	s.record(ctx, auditservice.ActionOwnershipTransferRequested, transfer)
+	log.Printf("[INFO] Ownership transfer %d of tenant %d to user %d requested", transfer.ID, tenantID, toUserID)
+	return transfer, nil
+}
+
+// ConfirmTransfer completes a transfer as the new owner
+func (s *DBOwnershipService) ConfirmTransfer(ctx context.Context, userID int64, token string) (*OwnershipTransfer, error) {
+	if token == "" {
+		return nil, ErrTransferNotFound
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	var transfer OwnershipTransfer
+	var fromUserID sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, tenant_id, from_user_id, to_user_id, demote_previous, expires_at, created_at
+		FROM tenant_ownership_transfer
+		WHERE token_hash = $1 AND confirmed_at IS NULL AND expires_at > $2
+		FOR UPDATE
+	`, HashToken(token), s.now()).Scan(
+		&transfer.ID,
+		&transfer.TenantID,
+		&fromUserID,
+		&transfer.ToUserID,
+		&transfer.DemotePrevious,
+		&transfer.ExpiresAt,
+		&transfer.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if fromUserID.Valid {
+		transfer.FromUserID = &fromUserID.Int64
+	}
+
+	// Only the new owner can confirm the transfer
+	if transfer.ToUserID != userID {
+		return nil, ErrTransferNotFound
+	}
+
+	var isMember bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM tenant_member WHERE user_id = $1 AND tenant_id = $2)", userID, transfer.TenantID).Scan(&isMember)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if !isMember {
+		return nil, fmt.Errorf("%w: the new owner is no longer a member of the tenant", ErrInvalidInput)
+	}
+
+	var roleID int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM role WHERE name = $1", tenantSuperRole).Scan(&roleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: role %s does not exist", ErrDBOperation, tenantSuperRole)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Grant the new owner TENANT_SUPER
+	result, err := tx.ExecContext(ctx, "INSERT INTO tenant_role (user_id, tenant_id, role_id) VALUES ($1, $2, $3) ON CONFLICT (user_id, tenant_id, role_id) DO NOTHING", userID, transfer.TenantID, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	} else if rowsAffected > 0 {
+		if err := s.recordRoleAudit(ctx, tx, "assign", userID, userID, transfer.TenantID, roleID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Demote the previous owner
+	demoted := false
+	if transfer.DemotePrevious && transfer.FromUserID != nil {
+		result, err := tx.ExecContext(ctx, "DELETE FROM tenant_role WHERE user_id = $1 AND tenant_id = $2 AND role_id = $3", *transfer.FromUserID, transfer.TenantID, roleID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		} else if rowsAffected > 0 {
+			demoted = true
+			if err := s.recordRoleAudit(ctx, tx, "revoke", userID, *transfer.FromUserID, transfer.TenantID, roleID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	confirmedAt := s.now()
+	if _, err := tx.ExecContext(ctx, "UPDATE tenant_ownership_transfer SET confirmed_at = $1 WHERE id = $2", confirmedAt, transfer.ID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	transfer.ConfirmedAt = &confirmedAt
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if s.onChange != nil {
+		s.onChange(ctx, userID, transfer.TenantID)
+		if demoted {
+			s.onChange(ctx, *transfer.FromUserID, transfer.TenantID)
+		}
+	}
+
+	s.record(ctx, auditservice.ActionOwnershipTransferred, &transfer)
+	log.Printf("[INFO] Ownership of tenant %d transferred to user %d (transfer %d)", transfer.TenantID, userID, transfer.ID)
+	return &transfer, nil
+}
+
+// recordRoleAudit writes a role audit record for a role change. The new owner
+// confirming the transfer is the actor.
+func (s *DBOwnershipService) recordRoleAudit(ctx context.Context, tx *sql.Tx, action string, actorUserID int64, targetUserID int64, tenantID int64, roleID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO role_audit (actor_user_id, target_user_id, role_id, role_name, tenant_id, action)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, actorUserID, targetUserID, roleID, tenantSuperRole, tenantID, action)
+	if err != nil {
+		return fmt.Errorf("%w: failed to record role audit: %v", ErrDBOperation, err)
+	}
+	return nil
+}
+
+// record writes an audit entry for a transfer, logging rather than returning failures
+func (s *DBOwnershipService) record(ctx context.Context, action string, transfer *OwnershipTransfer) {
+	details := map[string]interface{}{
+		"transfer_id":     transfer.ID,
+		"to_user_id":      transfer.ToUserID,
+		"demote_previous": transfer.DemotePrevious,
+	}
+	if transfer.FromUserID != nil {
+		details["from_user_id"] = *transfer.FromUserID
+	}
+
+	entry := auditservice.AuditEntry{
+		TenantID:     transfer.TenantID,
+		Action:       action,
+		ResourceType: auditservice.ResourceTenant,
+		ResourceID:   strconv.FormatInt(transfer.TenantID, 10),
+		Details:      details,
+	}
+	if err := s.recorder.Record(ctx, entry); err != nil {
+		log.Printf("[WARN] Failed to record %s audit entry for tenant %d: %v", action, transfer.TenantID, err)
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	"github.com/unsavory/silocore-go/internal/mail"
+)
+
+// recordingSender captures sent email
+type recordingSender struct {
+	messages []mail.Message
+	err      error
+}
+
+func (s *recordingSender) Send(ctx context.Context, message mail.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+// recordingAuditRecorder captures audit entries
+type recordingAuditRecorder struct {
+	entries []auditservice.AuditEntry
+}
+
+func (r *recordingAuditRecorder) Record(ctx context.Context, entry auditservice.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// confirmationToken extracts the token from a transfer confirmation email
+func confirmationToken(t *testing.T, body string) string {
+	start := strings.Index(body, "http")
+	require.NotEqual(t, -1, start)
+	link, err := url.Parse(strings.Fields(body[start:])[0])
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestRequestTransfer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Emails the new owner", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		sender := &recordingSender{}
+		recorder := &recordingAuditRecorder{}
+		service := NewDBOwnershipService(db, sender, recorder)
+		service.now = func() time.Time { return now }
+
+		// Setup mock expectations
+		var storedHash string
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT u.email, t.name").
+			WithArgs(int64(1), int64(8)).
+			WillReturnRows(sqlmock.NewRows([]string{"email", "name"}).AddRow("new@example.com", "Acme"))
+		mock.ExpectExec("DELETE FROM tenant_ownership_transfer WHERE tenant_id = \\$1 AND confirmed_at IS NULL").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("INSERT INTO tenant_ownership_transfer").
+			WithArgs(int64(1), int64(7), int64(8), true, hashArg{hash: &storedHash}, now.Add(DefaultOwnershipTransferTTL)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, now))
+		mock.ExpectCommit()
+
+		// Execute
+		transfer, err := service.RequestTransfer(ctx, 1, 7, 8, true, "https://app.example.com/tenants/ownership/confirm")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), transfer.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		require.Len(t, sender.messages, 1)
+		assert.Equal(t, "new@example.com", sender.messages[0].To)
+		assert.Contains(t, sender.messages[0].Subject, "Acme")
+		token := confirmationToken(t, sender.messages[0].Body)
+		assert.Equal(t, HashToken(token), storedHash)
+
+		require.Len(t, recorder.entries, 1)
+		assert.Equal(t, auditservice.ActionOwnershipTransferRequested, recorder.entries[0].Action)
+	})
+
+	t.Run("New owner isn't a member", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		sender := &recordingSender{}
+		service := NewDBOwnershipService(db, sender, &recordingAuditRecorder{})
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT u.email, t.name").
+			WithArgs(int64(1), int64(8)).
+			WillReturnRows(sqlmock.NewRows([]string{"email", "name"}))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.RequestTransfer(ctx, 1, 7, 8, false, "https://app.example.com/confirm")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.Empty(t, sender.messages)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Transfer to yourself", func(t *testing.T) {
+		service := NewDBOwnershipService(nil, &recordingSender{}, &recordingAuditRecorder{})
+
+		// Execute
+		_, err := service.RequestTransfer(ctx, 1, 7, 7, false, "https://app.example.com/confirm")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
+
+func TestConfirmTransfer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	token := "transfer-token"
+	transferColumns := []string{"id", "tenant_id", "from_user_id", "to_user_id", "demote_previous", "expires_at", "created_at"}
+
+	t.Run("Grants the new owner and demotes the previous owner", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		recorder := &recordingAuditRecorder{}
+		service := NewDBOwnershipService(db, &recordingSender{}, recorder)
+		service.now = func() time.Time { return now }
+		var changed []int64
+		service.OnRoleChange(func(ctx context.Context, userID int64, tenantID int64) {
+			changed = append(changed, userID)
+		})
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, tenant_id, from_user_id, to_user_id, demote_previous, expires_at, created_at FROM tenant_ownership_transfer").
+			WithArgs(HashToken(token), now).
+			WillReturnRows(sqlmock.NewRows(transferColumns).AddRow(4, 1, 7, 8, true, now.Add(time.Hour), now))
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM tenant_member").
+			WithArgs(int64(8), int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery("SELECT id FROM role WHERE name = \\$1").
+			WithArgs(tenantSuperRole).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec("INSERT INTO tenant_role").
+			WithArgs(int64(8), int64(1), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(int64(8), int64(8), int64(3), tenantSuperRole, int64(1), "assign").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM tenant_role WHERE user_id = \\$1 AND tenant_id = \\$2 AND role_id = \\$3").
+			WithArgs(int64(7), int64(1), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(int64(8), int64(7), int64(3), tenantSuperRole, int64(1), "revoke").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE tenant_ownership_transfer SET confirmed_at = \\$1 WHERE id = \\$2").
+			WithArgs(now, int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		transfer, err := service.ConfirmTransfer(ctx, 8, token)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, &now, transfer.ConfirmedAt)
+		assert.Equal(t, []int64{8, 7}, changed)
+		require.Len(t, recorder.entries, 1)
+		assert.Equal(t, auditservice.ActionOwnershipTransferred, recorder.entries[0].Action)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Confirmed by another user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		recorder := &recordingAuditRecorder{}
+		service := NewDBOwnershipService(db, &recordingSender{}, recorder)
+		service.now = func() time.Time { return now }
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, tenant_id, from_user_id, to_user_id").
+			WithArgs(HashToken(token), now).
+			WillReturnRows(sqlmock.NewRows(transferColumns).AddRow(4, 1, 7, 8, true, now.Add(time.Hour), now))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.ConfirmTransfer(ctx, 9, token)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrTransferNotFound))
+		assert.Empty(t, recorder.entries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Expired or unknown token", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBOwnershipService(db, &recordingSender{}, &recordingAuditRecorder{})
+		service.now = func() time.Time { return now }
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, tenant_id, from_user_id, to_user_id").
+			WithArgs(HashToken(token), now).
+			WillReturnRows(sqlmock.NewRows(transferColumns))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.ConfirmTransfer(ctx, 8, token)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrTransferNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"log"
+)
+
+// TenantProvisioner sets up per-tenant database resources for new tenants, such
+// as their own schema in schema-per-tenant mode
+type TenantProvisioner interface {
+	ProvisionTenant(ctx context.Context, tenantID int64) error
+}
+
+// provisionTenant provisions a tenant that has just been created. The tenant
+// already exists, so a failure is logged rather than returned; the server
+// provisions missing schemas again at startup.
+func provisionTenant(ctx context.Context, provisioner TenantProvisioner, tenantID int64) {
+	if provisioner == nil {
+		return
+	}
+	if err := provisioner.ProvisionTenant(ctx, tenantID); err != nil {
+		log.Printf("[WARN] Failed to provision tenant %d: %v", tenantID, err)
+	}
+}
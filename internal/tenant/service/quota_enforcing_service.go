@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// QuotaEnforcingTenantService decorates a TenantService, rejecting new members
+// once a tenant reaches its member limit
+type QuotaEnforcingTenantService struct {
+	TenantService
+	quotaService QuotaService
+	txManager    transaction.TxManager
+}
+
+// Ensure QuotaEnforcingTenantService implements TenantService
+var _ TenantService = (*QuotaEnforcingTenantService)(nil)
+
+// NewQuotaEnforcingTenantService creates a new QuotaEnforcingTenantService
+func NewQuotaEnforcingTenantService(tenantService TenantService, quotaService QuotaService) *QuotaEnforcingTenantService {
+	return &QuotaEnforcingTenantService{
+		TenantService: tenantService,
+		quotaService:  quotaService,
+	}
+}
+
+// WithTxManager checks the quota and adds the member in one transaction of
+// txManager, so concurrent additions can't both pass the check at the limit
+func (s *QuotaEnforcingTenantService) WithTxManager(txManager transaction.TxManager) *QuotaEnforcingTenantService {
+	s.txManager = txManager
+	return s
+}
+
+// AddTenantMember adds a user to a tenant if the tenant's member limit allows it
+func (s *QuotaEnforcingTenantService) AddTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	return withQuotaTransaction(ctx, s.txManager, func(ctx context.Context) error {
+		if err := s.quotaService.CheckMemberQuota(ctx, tenantID, userID); err != nil {
+			return err
+		}
+		return s.TenantService.AddTenantMember(ctx, userID, tenantID)
+	})
+}
+
+// QuotaEnforcingTenantMemberService decorates a TenantMemberService, rejecting new
+// members once a tenant reaches its member limit
+type QuotaEnforcingTenantMemberService struct {
+	TenantMemberService
+	quotaService QuotaService
+	txManager    transaction.TxManager
+}
+
+// Ensure QuotaEnforcingTenantMemberService implements TenantMemberService
+var _ TenantMemberService = (*QuotaEnforcingTenantMemberService)(nil)
+
+// NewQuotaEnforcingTenantMemberService creates a new QuotaEnforcingTenantMemberService
+func NewQuotaEnforcingTenantMemberService(tenantMemberService TenantMemberService, quotaService QuotaService) *QuotaEnforcingTenantMemberService {
+	return &QuotaEnforcingTenantMemberService{
+		TenantMemberService: tenantMemberService,
+		quotaService:        quotaService,
+	}
+}
+
+// WithTxManager checks the quota and adds the member in one transaction of
+// txManager, so concurrent additions can't both pass the check at the limit
+func (s *QuotaEnforcingTenantMemberService) WithTxManager(txManager transaction.TxManager) *QuotaEnforcingTenantMemberService {
+	s.txManager = txManager
+	return s
+}
+
+// AddTenantMember adds a user to a tenant if the tenant's member limit allows it
+func (s *QuotaEnforcingTenantMemberService) AddTenantMember(ctx context.Context, userID int64, tenantID int64) error {
+	return withQuotaTransaction(ctx, s.txManager, func(ctx context.Context) error {
+		if err := s.quotaService.CheckMemberQuota(ctx, tenantID, userID); err != nil {
+			return err
+		}
+		return s.TenantMemberService.AddTenantMember(ctx, userID, tenantID)
+	})
+}
+
+// withQuotaTransaction runs a quota check and the change it guards in the
+// transaction in the context or a new one, or without one if txManager is nil
+func withQuotaTransaction(ctx context.Context, txManager transaction.TxManager, fn func(ctx context.Context) error) error {
+	if txManager == nil {
+		return fn(ctx)
+	}
+	return txManager.WithTransaction(ctx, fn)
+}
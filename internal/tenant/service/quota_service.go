@@ -0,0 +1,507 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// Quota errors
+var (
+	// ErrQuotaExceeded is returned when a tenant has reached a member, order or storage limit
+	ErrQuotaExceeded = errors.New("tenant quota exceeded")
+
+	// ErrRateLimitExceeded is returned when a tenant has used up its API requests for the current window
+	ErrRateLimitExceeded = errors.New("tenant API request limit exceeded")
+)
+
+// APIRequestWindow is the window over which API requests are counted against MaxAPIRequests
+const APIRequestWindow = time.Hour
+
+// Quota holds the limits configured for a tenant. A nil limit means unlimited.
+// MaxStorageBytes limits the total size of the tenant's order attachments.
+type Quota struct {
+	TenantID        int64     `json:"tenant_id"`
+	MaxMembers      *int      `json:"max_members"`
+	MaxOrders       *int      `json:"max_orders"`
+	MaxAPIRequests  *int      `json:"max_api_requests_per_hour"`
+	MaxStorageBytes *int64    `json:"max_storage_bytes"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+}
+
+// QuotaUsage reports a tenant's usage against its quota
+type QuotaUsage struct {
+	Quota       Quota `json:"quota"`
+	Members     int   `json:"members"`
+	Orders      int   `json:"orders"`
+	APIRequests int   `json:"api_requests"`
+	// StorageBytes is the total size of the tenant's order attachments
+	StorageBytes int64 `json:"storage_bytes"`
+	// APIRequestLimit is the hourly API request budget that applies, from the
+	// quota or the tenant's plan. Nil is unlimited.
+	APIRequestLimit *int      `json:"api_request_limit"`
+	WindowEnds      time.Time `json:"api_window_ends"`
+}
+
+// RateLimit is the state of a tenant's API request budget after a request
+type RateLimit struct {
+	// Limit is the number of requests allowed in the window. Nil is unlimited.
+	Limit     *int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimitMetrics counts a tenant's API requests since the server started
+type RateLimitMetrics struct {
+	TenantID int64 `json:"tenant_id"`
+	Allowed  int64 `json:"allowed"`
+	Limited  int64 `json:"limited"`
+	// WindowRequests is the number of requests counted in the current window
+	WindowRequests int       `json:"window_requests"`
+	WindowEnds     time.Time `json:"window_ends"`
+}
+
+// PlanLimitSource looks up the API request budget of a tenant's plan
+type PlanLimitSource interface {
+	GetTenantAPIRequestLimit(ctx context.Context, tenantID int64) (*int, error)
+}
+
+// QuotaService defines the interface for tenant quota operations
+type QuotaService interface {
+	// GetQuota retrieves a tenant's quota, which is unlimited if none is configured
+	GetQuota(ctx context.Context, tenantID int64) (*Quota, error)
+
+	// SetQuota creates or replaces a tenant's quota
+	SetQuota(ctx context.Context, quota *Quota) error
+
+	// GetUsage retrieves a tenant's usage against its quota
+	GetUsage(ctx context.Context, tenantID int64) (*QuotaUsage, error)
+
+	// CheckMemberQuota checks whether the user can be added to the tenant without
+	// exceeding its member limit. Existing members are always allowed.
+	CheckMemberQuota(ctx context.Context, tenantID int64, userID int64) error
+
+	// CheckOrderQuota checks whether the tenant can create another order
+	CheckOrderQuota(ctx context.Context, tenantID int64) error
+
+	// CheckStorageQuota checks whether the tenant can store another size bytes
+	CheckStorageQuota(ctx context.Context, tenantID int64, size int64) error
+
+	// AllowAPIRequest counts an API request against the tenant's limit, returning
+	// ErrRateLimitExceeded if the limit for the current window has been reached.
+	// The tenant's rate limit is returned either way.
+	AllowAPIRequest(ctx context.Context, tenantID int64) (*RateLimit, error)
+
+	// GetRateLimitMetrics retrieves the API request counts of each tenant that
+	// has made requests, ordered by tenant ID
+	GetRateLimitMetrics(ctx context.Context) ([]RateLimitMetrics, error)
+}
+
+// DBQuotaService implements QuotaService using a database. API requests are
+// counted in memory, so limits apply per server instance.
+//
+// The member, order and storage checks lock the tenant's quota row until the
+// transaction in the context ends, so checks of concurrent transactions run in
+// turn and each counts the rows the others added. The rows a check guards must
+// be inserted in the same transaction.
+type DBQuotaService struct {
+	db       *sql.DB
+	requests *requestCounter
+	plans    PlanLimitSource
+}
+
+// NewDBQuotaService creates a new DBQuotaService
+func NewDBQuotaService(db *sql.DB) *DBQuotaService {
+	return &DBQuotaService{
+		db:       db,
+		requests: newRequestCounter(APIRequestWindow, time.Now),
+	}
+}
+
+// WithPlanLimits applies the API request budget of a tenant's plan when its
+// quota has no API request limit
+func (s *DBQuotaService) WithPlanLimits(plans PlanLimitSource) *DBQuotaService {
+	s.plans = plans
+	return s
+}
+
+// GetQuota retrieves a tenant's quota, which is unlimited if none is configured
+func (s *DBQuotaService) GetQuota(ctx context.Context, tenantID int64) (*Quota, error) {
+	return s.getQuota(ctx, s.db, tenantID, "")
+}
+
+// lockQuota retrieves a tenant's quota like GetQuota, locking it in the
+// transaction in the context. Unlimited tenants have no quota row to lock.
+func (s *DBQuotaService) lockQuota(ctx context.Context, tenantID int64) (*Quota, error) {
+	return s.getQuota(ctx, transaction.QuerierFor(ctx, s.db), tenantID, "FOR UPDATE")
+}
+
+// getQuota retrieves a tenant's quota with q, with the given locking clause
+func (s *DBQuotaService) getQuota(ctx context.Context, q transaction.Querier, tenantID int64, lock string) (*Quota, error) {
+	query := `
+		SELECT max_members, max_orders, max_api_requests, max_storage_bytes, updated_at
+		FROM tenant_quota
+		WHERE tenant_id = $1
+	` + lock
+
+	var maxMembers, maxOrders, maxAPIRequests, maxStorageBytes sql.NullInt64
+	quota := Quota{TenantID: tenantID}
+	err := q.QueryRowContext(ctx, query, tenantID).Scan(&maxMembers, &maxOrders, &maxAPIRequests, &maxStorageBytes, &quota.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &quota, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	quota.MaxMembers = nullIntPtr(maxMembers)
+	quota.MaxOrders = nullIntPtr(maxOrders)
+	quota.MaxAPIRequests = nullIntPtr(maxAPIRequests)
+	if maxStorageBytes.Valid {
+		quota.MaxStorageBytes = &maxStorageBytes.Int64
+	}
+
+	return &quota, nil
+}
+
+// SetQuota creates or replaces a tenant's quota
+func (s *DBQuotaService) SetQuota(ctx context.Context, quota *Quota) error {
+	if quota.TenantID == 0 {
+		return fmt.Errorf("%w: tenant ID is required", ErrInvalidInput)
+	}
+
+	for _, limit := range []*int{quota.MaxMembers, quota.MaxOrders, quota.MaxAPIRequests} {
+		if limit != nil && *limit < 0 {
+			return fmt.Errorf("%w: quota limits cannot be negative", ErrInvalidInput)
+		}
+	}
+	if quota.MaxStorageBytes != nil && *quota.MaxStorageBytes < 0 {
+		return fmt.Errorf("%w: quota limits cannot be negative", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO tenant_quota (tenant_id, max_members, max_orders, max_api_requests, max_storage_bytes, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET max_members = EXCLUDED.max_members,
+			max_orders = EXCLUDED.max_orders,
+			max_api_requests = EXCLUDED.max_api_requests,
+			max_storage_bytes = EXCLUDED.max_storage_bytes,
+			updated_at = NOW()
+	`
+
+	_, err := s.db.ExecContext(ctx, query, quota.TenantID, quota.MaxMembers, quota.MaxOrders, quota.MaxAPIRequests, quota.MaxStorageBytes)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Updated quota for tenant %d", quota.TenantID)
+	return nil
+}
+
+// GetUsage retrieves a tenant's usage against its quota
+func (s *DBQuotaService) GetUsage(ctx context.Context, tenantID int64) (*QuotaUsage, error) {
+	quota, err := s.GetQuota(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.countMembers(ctx, tenantID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.countOrders(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	storageBytes, err := s.storageBytes(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := s.apiRequestLimit(ctx, quota)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, windowEnds := s.requests.current(tenantID)
+
+	return &QuotaUsage{
+		Quota:           *quota,
+		Members:         members,
+		Orders:          orders,
+		APIRequests:     requests,
+		StorageBytes:    storageBytes,
+		APIRequestLimit: limit,
+		WindowEnds:      windowEnds,
+	}, nil
+}
+
+// CheckMemberQuota checks whether the user can be added to the tenant without
+// exceeding its member limit. Existing members are always allowed.
+func (s *DBQuotaService) CheckMemberQuota(ctx context.Context, tenantID int64, userID int64) error {
+	quota, err := s.lockQuota(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxMembers == nil {
+		return nil
+	}
+
+	// Don't count the user, so re-adding an existing member is allowed
+	members, err := s.countMembers(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+
+	if members >= *quota.MaxMembers {
+		log.Printf("[WARN] Tenant %d reached its limit of %d members", tenantID, *quota.MaxMembers)
+		return fmt.Errorf("%w: tenant is limited to %d members", ErrQuotaExceeded, *quota.MaxMembers)
+	}
+
+	return nil
+}
+
+// CheckOrderQuota checks whether the tenant can create another order
+func (s *DBQuotaService) CheckOrderQuota(ctx context.Context, tenantID int64) error {
+	quota, err := s.lockQuota(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxOrders == nil {
+		return nil
+	}
+
+	orders, err := s.countOrders(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if orders >= *quota.MaxOrders {
+		log.Printf("[WARN] Tenant %d reached its limit of %d orders", tenantID, *quota.MaxOrders)
+		return fmt.Errorf("%w: tenant is limited to %d orders", ErrQuotaExceeded, *quota.MaxOrders)
+	}
+
+	return nil
+}
+
+// CheckStorageQuota checks whether the tenant can store another size bytes
+func (s *DBQuotaService) CheckStorageQuota(ctx context.Context, tenantID int64, size int64) error {
+	quota, err := s.lockQuota(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxStorageBytes == nil {
+		return nil
+	}
+
+	used, err := s.storageBytes(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if used+size > *quota.MaxStorageBytes {
+		log.Printf("[WARN] Tenant %d reached its limit of %d storage bytes", tenantID, *quota.MaxStorageBytes)
+		return fmt.Errorf("%w: tenant is limited to %d bytes of storage, %d in use", ErrQuotaExceeded, *quota.MaxStorageBytes, used)
+	}
+
+	return nil
+}
+
+// AllowAPIRequest counts an API request against the tenant's limit, returning
+// ErrRateLimitExceeded if the limit for the current window has been reached.
+// Requests of unlimited tenants are counted too, for metrics.
+func (s *DBQuotaService) AllowAPIRequest(ctx context.Context, tenantID int64) (*RateLimit, error) {
+	quota, err := s.GetQuota(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := s.apiRequestLimit(ctx, quota)
+	if err != nil {
+		return nil, err
+	}
+
+	count, windowEnds, allowed := s.requests.allow(tenantID, limit)
+
+	rateLimit := &RateLimit{Limit: limit, Reset: windowEnds}
+	if limit != nil && *limit > count {
+		rateLimit.Remaining = *limit - count
+	}
+
+	if !allowed {
+		return rateLimit, fmt.Errorf("%w: tenant is limited to %d API requests per hour", ErrRateLimitExceeded, *limit)
+	}
+
+	return rateLimit, nil
+}
+
+// GetRateLimitMetrics retrieves the API request counts of each tenant that has
+// made requests, ordered by tenant ID
+func (s *DBQuotaService) GetRateLimitMetrics(ctx context.Context) ([]RateLimitMetrics, error) {
+	return s.requests.metrics(), nil
+}
+
+// apiRequestLimit returns the hourly API request budget of a tenant. A limit in
+// the quota overrides the budget of the tenant's plan.
+func (s *DBQuotaService) apiRequestLimit(ctx context.Context, quota *Quota) (*int, error) {
+	if quota.MaxAPIRequests != nil || s.plans == nil {
+		return quota.MaxAPIRequests, nil
+	}
+
+	limit, err := s.plans.GetTenantAPIRequestLimit(ctx, quota.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan API request limit of tenant %d: %w", quota.TenantID, err)
+	}
+	return limit, nil
+}
+
+// countMembers counts a tenant's members, excluding the given user if non-zero
+func (s *DBQuotaService) countMembers(ctx context.Context, tenantID int64, excludeUserID int64) (int, error) {
+	var count int
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM tenant_member WHERE tenant_id = $1 AND user_id <> $2", tenantID, excludeUserID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	return count, nil
+}
+
+// countOrders counts a tenant's orders, including archived orders
+func (s *DBQuotaService) countOrders(ctx context.Context, tenantID int64) (int, error) {
+	var count int
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, `SELECT (SELECT COUNT(*) FROM "order" WHERE tenant_id = $1) + (SELECT COUNT(*) FROM order_archive WHERE tenant_id = $1)`, tenantID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	return count, nil
+}
+
+// storageBytes sums the sizes of a tenant's order attachments
+func (s *DBQuotaService) storageBytes(ctx context.Context, tenantID int64) (int64, error) {
+	var total int64
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, "SELECT COALESCE(SUM(size_bytes), 0) FROM order_attachment WHERE tenant_id = $1", tenantID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	return total, nil
+}
+
+// nullIntPtr converts a nullable integer column to a pointer
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
+
+// requestCounter counts requests per tenant in fixed windows
+type requestCounter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	now     func() time.Time
+	windows map[int64]*requestWindow
+	totals  map[int64]*requestTotals
+}
+
+// requestWindow is the request count for one tenant in the current window
+type requestWindow struct {
+	start time.Time
+	count int
+}
+
+// requestTotals are the requests allowed and limited for one tenant since the
+// counter was created
+type requestTotals struct {
+	allowed int64
+	limited int64
+}
+
+// newRequestCounter creates a requestCounter with the given window length
+func newRequestCounter(window time.Duration, now func() time.Time) *requestCounter {
+	return &requestCounter{
+		window:  window,
+		now:     now,
+		windows: make(map[int64]*requestWindow),
+		totals:  make(map[int64]*requestTotals),
+	}
+}
+
+// allow counts a request for the tenant if it is under the limit for the current
+// window, or always if limit is nil. It returns the tenant's count and the end of
+// the window.
+func (c *requestCounter) allow(tenantID int64, limit *int) (int, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := c.currentWindow(tenantID)
+	totals, ok := c.totals[tenantID]
+	if !ok {
+		totals = &requestTotals{}
+		c.totals[tenantID] = totals
+	}
+
+	if limit != nil && w.count >= *limit {
+		totals.limited++
+		return w.count, w.start.Add(c.window), false
+	}
+	w.count++
+	totals.allowed++
+	return w.count, w.start.Add(c.window), true
+}
+
+// metrics returns the request counts of each tenant, ordered by tenant ID
+func (c *requestCounter) metrics() []RateLimitMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := make([]RateLimitMetrics, 0, len(c.totals))
+	for tenantID, totals := range c.totals {
+		w := c.currentWindow(tenantID)
+		metrics = append(metrics, RateLimitMetrics{
+			TenantID:       tenantID,
+			Allowed:        totals.allowed,
+			Limited:        totals.limited,
+			WindowRequests: w.count,
+			WindowEnds:     w.start.Add(c.window),
+		})
+	}
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].TenantID < metrics[j].TenantID })
+	return metrics
+}
+
+// current returns the tenant's request count and the end of the current window
+func (c *requestCounter) current(tenantID int64) (int, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := c.currentWindow(tenantID)
+	return w.count, w.start.Add(c.window)
+}
+
+// currentWindow returns the tenant's window, starting a new one if it has expired.
+// The caller must hold c.mu.
+func (c *requestCounter) currentWindow(tenantID int64) *requestWindow {
+	now := c.now()
+	start := now.Truncate(c.window)
+
+	w, ok := c.windows[tenantID]
+	if !ok || !w.start.Equal(start) {
+		w = &requestWindow{start: start}
+		c.windows[tenantID] = w
+	}
+	return w
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+func setupQuotaService(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *DBQuotaService) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return db, mock, NewDBQuotaService(db)
+}
+
+func expectQuota(mock sqlmock.Sqlmock, tenantID int64, maxMembers, maxOrders, maxAPIRequests interface{}) {
+	expectStorageQuota(mock, tenantID, maxMembers, maxOrders, maxAPIRequests, nil)
+}
+
+func expectStorageQuota(mock sqlmock.Sqlmock, tenantID int64, maxMembers, maxOrders, maxAPIRequests, maxStorageBytes interface{}) {
+	mock.ExpectQuery("SELECT max_members, max_orders, max_api_requests, max_storage_bytes, updated_at FROM tenant_quota WHERE tenant_id = \\$1").
+		WithArgs(tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"max_members", "max_orders", "max_api_requests", "max_storage_bytes", "updated_at"}).
+			AddRow(maxMembers, maxOrders, maxAPIRequests, maxStorageBytes, time.Now()))
+}
+
+func TestGetQuota(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	t.Run("No quota configured", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT max_members, max_orders, max_api_requests, max_storage_bytes, updated_at FROM tenant_quota").
+			WithArgs(int64(1)).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		quota, err := service.GetQuota(ctx, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, quota.MaxMembers)
+		assert.Nil(t, quota.MaxOrders)
+		assert.Nil(t, quota.MaxAPIRequests)
+	})
+
+	t.Run("Partial quota", func(t *testing.T) {
+		// Setup mock expectations
+		expectQuota(mock, 1, 5, nil, 100)
+
+		// Execute
+		quota, err := service.GetQuota(ctx, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 5, *quota.MaxMembers)
+		assert.Nil(t, quota.MaxOrders)
+		assert.Equal(t, 100, *quota.MaxAPIRequests)
+	})
+}
+
+func TestSetQuota(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	maxMembers := 10
+
+	t.Run("Successful update", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("INSERT INTO tenant_quota").
+			WithArgs(int64(1), &maxMembers, nil, nil, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := service.SetQuota(ctx, &Quota{TenantID: 1, MaxMembers: &maxMembers})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Negative limit", func(t *testing.T) {
+		negative := -1
+
+		// Execute
+		err := service.SetQuota(ctx, &Quota{TenantID: 1, MaxOrders: &negative})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
+
+func TestCheckMemberQuota(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	t.Run("Under limit", func(t *testing.T) {
+		// Setup mock expectations
+		expectQuota(mock, 1, 3, nil, nil)
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant_member WHERE tenant_id = \\$1 AND user_id <> \\$2").
+			WithArgs(int64(1), int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		// Execute
+		err := service.CheckMemberQuota(ctx, 1, 9)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Limit reached", func(t *testing.T) {
+		// Setup mock expectations
+		expectQuota(mock, 1, 3, nil, nil)
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant_member").
+			WithArgs(int64(1), int64(9)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		// Execute
+		err := service.CheckMemberQuota(ctx, 1, 9)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		// Setup mock expectations
+		expectQuota(mock, 1, nil, nil, nil)
+
+		// Execute
+		err := service.CheckMemberQuota(ctx, 1, 9)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCheckMemberQuotaLocksQuota(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	// Setup mock expectations: the quota is locked and the members counted in
+	// the transaction that adds the member
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT max_members, max_orders, max_api_requests, max_storage_bytes, updated_at FROM tenant_quota WHERE tenant_id = \\$1 FOR UPDATE").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"max_members", "max_orders", "max_api_requests", "max_storage_bytes", "updated_at"}).
+			AddRow(3, nil, nil, nil, time.Now()))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant_member").
+		WithArgs(int64(1), int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO tenant_member").
+		WithArgs(int64(9), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	members := NewQuotaEnforcingTenantMemberService(NewDBTenantMemberService(db), service).
+		WithTxManager(transaction.NewManager(db))
+	err := members.AddTenantMember(context.Background(), 9, 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckOrderQuota(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	// Setup mock expectations
+	expectQuota(mock, 1, nil, 50, nil)
+	mock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM \"order\" WHERE tenant_id = \\$1\\) \\+ \\(SELECT COUNT\\(\\*\\) FROM order_archive WHERE tenant_id = \\$1\\)").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(50))
+
+	// Execute
+	err := service.CheckOrderQuota(context.Background(), 1)
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckStorageQuota(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	t.Run("Fits", func(t *testing.T) {
+		// Setup mock expectations
+		expectStorageQuota(mock, 1, nil, nil, nil, 1000)
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(size_bytes\\), 0\\) FROM order_attachment WHERE tenant_id = \\$1").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(600))
+
+		// Execute
+		err := service.CheckStorageQuota(ctx, 1, 400)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Limit exceeded", func(t *testing.T) {
+		// Setup mock expectations
+		expectStorageQuota(mock, 1, nil, nil, nil, 1000)
+		mock.ExpectQuery("SELECT COALESCE\\(SUM\\(size_bytes\\), 0\\) FROM order_attachment").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(600))
+
+		// Execute
+		err := service.CheckStorageQuota(ctx, 1, 401)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrQuotaExceeded))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAllowAPIRequest(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	now := time.Date(2025, 3, 31, 12, 15, 0, 0, time.UTC)
+	service.requests.now = func() time.Time { return now }
+
+	// Two requests are allowed in the window
+	for i := 0; i < 2; i++ {
+		expectQuota(mock, 1, nil, nil, 2)
+		rateLimit, err := service.AllowAPIRequest(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1-i, rateLimit.Remaining)
+	}
+
+	// The third is rejected
+	expectQuota(mock, 1, nil, nil, 2)
+	rateLimit, err := service.AllowAPIRequest(ctx, 1)
+	assert.True(t, errors.Is(err, ErrRateLimitExceeded))
+	assert.Equal(t, 2, *rateLimit.Limit)
+	assert.Equal(t, 0, rateLimit.Remaining)
+	assert.Equal(t, time.Date(2025, 3, 31, 13, 0, 0, 0, time.UTC), rateLimit.Reset)
+
+	// Other tenants have their own count
+	expectQuota(mock, 2, nil, nil, 2)
+	_, err = service.AllowAPIRequest(ctx, 2)
+	assert.NoError(t, err)
+
+	// The count resets in the next window
+	now = now.Add(time.Hour)
+	expectQuota(mock, 1, nil, nil, 2)
+	_, err = service.AllowAPIRequest(ctx, 1)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Metrics count allowed and limited requests per tenant
+	metrics, err := service.GetRateLimitMetrics(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RateLimitMetrics{
+		{TenantID: 1, Allowed: 3, Limited: 1, WindowRequests: 1, WindowEnds: time.Date(2025, 3, 31, 14, 0, 0, 0, time.UTC)},
+		{TenantID: 2, Allowed: 1, Limited: 0, WindowRequests: 0, WindowEnds: time.Date(2025, 3, 31, 14, 0, 0, 0, time.UTC)},
+	}, metrics)
+}
+
+// fakePlanLimits returns a fixed plan API request budget
+type fakePlanLimits struct {
+	limit *int
+	calls int
+}
+
+func (f *fakePlanLimits) GetTenantAPIRequestLimit(ctx context.Context, tenantID int64) (*int, error) {
+	f.calls++
+	return f.limit, nil
+}
+
+func TestAllowAPIRequestPlanLimits(t *testing.T) {
+	db, mock, service := setupQuotaService(t)
+	defer db.Close()
+
+	planLimit := 1
+	plans := &fakePlanLimits{limit: &planLimit}
+	service.WithPlanLimits(plans)
+	ctx := context.Background()
+
+	t.Run("Plan budget applies without a quota limit", func(t *testing.T) {
+		// Setup mock expectations
+		expectQuota(mock, 1, nil, nil, nil)
+		expectQuota(mock, 1, nil, nil, nil)
+
+		// Execute
+		rateLimit, err := service.AllowAPIRequest(ctx, 1)
+		_, limitErr := service.AllowAPIRequest(ctx, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, *rateLimit.Limit)
+		assert.True(t, errors.Is(limitErr, ErrRateLimitExceeded))
+		assert.Equal(t, 2, plans.calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Quota limit overrides the plan", func(t *testing.T) {
+		// Setup mock expectations
+		expectQuota(mock, 2, nil, nil, 10)
+
+		// Execute
+		rateLimit, err := service.AllowAPIRequest(ctx, 2)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 10, *rateLimit.Limit)
+		assert.Equal(t, 9, rateLimit.Remaining)
+		assert.Equal(t, 2, plans.calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
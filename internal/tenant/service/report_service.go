@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// MaxReportDays is the longest date range a platform report can cover
+const MaxReportDays = 366
+
+// TenantOrderTotals are the orders a tenant created over a report's range
+type TenantOrderTotals struct {
+	TenantID int64   `json:"tenant_id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	Orders   int64   `json:"orders"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// OrdersReport lists the orders of every tenant over a date range, busiest first
+type OrdersReport struct {
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	Tenants []TenantOrderTotals `json:"tenants"`
+	Orders  int64               `json:"orders"`
+	Revenue float64             `json:"revenue"`
+}
+
+// DailyGrowth counts what was created on the platform on one day
+type DailyGrowth struct {
+	Date       string `json:"date"`
+	NewTenants int64  `json:"new_tenants"`
+	NewUsers   int64  `json:"new_users"`
+	NewOrders  int64  `json:"new_orders"`
+}
+
+// GrowthReport counts the tenants, users and orders created each day of a date range
+type GrowthReport struct {
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	Days       []DailyGrowth `json:"days"`
+	NewTenants int64         `json:"new_tenants"`
+	NewUsers   int64         `json:"new_users"`
+	NewOrders  int64         `json:"new_orders"`
+}
+
+// TenantActiveUsers is the number of distinct users active in a tenant
+type TenantActiveUsers struct {
+	TenantID    int64  `json:"tenant_id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	ActiveUsers int64  `json:"active_users"`
+}
+
+// ActiveUsersReport counts the users active over a date range, per tenant and on
+// the whole platform. Users active in several tenants are counted once in ActiveUsers.
+type ActiveUsersReport struct {
+	From        string              `json:"from"`
+	To          string              `json:"to"`
+	Tenants     []TenantActiveUsers `json:"tenants"`
+	ActiveUsers int64               `json:"active_users"`
+}
+
+// ReportService defines the interface for platform reports aggregating across all
+// tenants. Reports are only available to admins.
+type ReportService interface {
+	// GetOrdersReport retrieves the orders of every tenant between two dates, inclusive
+	GetOrdersReport(ctx context.Context, from, to time.Time) (*OrdersReport, error)
+
+	// GetGrowthReport retrieves the tenants, users and orders created each day
+	// between two dates, inclusive
+	GetGrowthReport(ctx context.Context, from, to time.Time) (*GrowthReport, error)
+
+	// GetActiveUsersReport retrieves the users active between two dates, inclusive
+	GetActiveUsersReport(ctx context.Context, from, to time.Time) (*ActiveUsersReport, error)
+}
+
+// DBReportService implements ReportService using a database. Every report runs
+// through the transaction manager's cross-tenant read path, which checks for the
+// ADMIN role and clears the tenant context.
+type DBReportService struct {
+	txManager transaction.TxManager
+}
+
+// Ensure DBReportService implements ReportService
+var _ ReportService = (*DBReportService)(nil)
+
+// NewDBReportService creates a new DBReportService
+func NewDBReportService(db *sql.DB) *DBReportService {
+	return &DBReportService{
+		txManager: transaction.NewManager(db),
+	}
+}
+
+// WithTxManager runs the DBReportService's queries in the transactions of txManager,
+// such as the factory's shared manager or a FakeManager in tests
+func (s *DBReportService) WithTxManager(txManager transaction.TxManager) *DBReportService {
+	s.txManager = txManager
+	return s
+}
+
+// GetOrdersReport retrieves the orders of every tenant between two dates, inclusive.
+// Tenants without orders are listed too.
+func (s *DBReportService) GetOrdersReport(ctx context.Context, from, to time.Time) (*OrdersReport, error) {
+	start, end, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OrdersReport{From: start, To: end, Tenants: []TenantOrderTotals{}}
+
+	query := `
+		SELECT t.id, t.name, t.slug, COUNT(o.order_id), COALESCE(SUM(o.total_amount), 0)
+		FROM tenant t
+		LEFT JOIN "order" o ON o.tenant_id = t.id
+			AND o.deleted_at IS NULL
+			AND o.created_at >= $1::date
+			AND o.created_at < $2::date + 1
+		WHERE t.deleted_at IS NULL
+		GROUP BY t.id, t.name, t.slug
+		ORDER BY COUNT(o.order_id) DESC, t.id
+	`
+
+	err = s.txManager.WithCrossTenantRead(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, start, end)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var totals TenantOrderTotals
+			if err := rows.Scan(&totals.TenantID, &totals.Name, &totals.Slug, &totals.Orders, &totals.Revenue); err != nil {
+				return fmt.Errorf("%w: %v", ErrDBOperation, err)
+			}
+			report.Tenants = append(report.Tenants, totals)
+			report.Orders += totals.Orders
+			report.Revenue += totals.Revenue
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetGrowthReport retrieves the tenants, users and orders created each day between
+// two dates, inclusive. Days without activity are included with zero counts.
+func (s *DBReportService) GetGrowthReport(ctx context.Context, from, to time.Time) (*GrowthReport, error) {
+	start, end, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GrowthReport{From: start, To: end, Days: []DailyGrowth{}}
+
+	query := `
+		SELECT d.day::date, COALESCE(t.count, 0), COALESCE(u.count, 0), COALESCE(o.count, 0)
+		FROM generate_series($1::date, $2::date, INTERVAL '1 day') AS d(day)
+		LEFT JOIN (
+			SELECT created_at::date AS day, COUNT(*) AS count
+			FROM tenant
+			WHERE created_at >= $1::date AND created_at < $2::date + 1
+			GROUP BY 1
+		) t ON t.day = d.day::date
+		LEFT JOIN (
+			SELECT created_at::date AS day, COUNT(*) AS count
+			FROM usr
+			WHERE created_at >= $1::date AND created_at < $2::date + 1
+			GROUP BY 1
+		) u ON u.day = d.day::date
+		LEFT JOIN (
+			SELECT created_at::date AS day, COUNT(*) AS count
+			FROM "order"
+			WHERE created_at >= $1::date AND created_at < $2::date + 1 AND deleted_at IS NULL
+			GROUP BY 1
+		) o ON o.day = d.day::date
+		ORDER BY d.day
+	`
+
+	err = s.txManager.WithCrossTenantRead(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, start, end)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var day DailyGrowth
+			var date time.Time
+			if err := rows.Scan(&date, &day.NewTenants, &day.NewUsers, &day.NewOrders); err != nil {
+				return fmt.Errorf("%w: %v", ErrDBOperation, err)
+			}
+			day.Date = date.Format(usageDateLayout)
+			report.Days = append(report.Days, day)
+			report.NewTenants += day.NewTenants
+			report.NewUsers += day.NewUsers
+			report.NewOrders += day.NewOrders
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetActiveUsersReport retrieves the users active between two dates, inclusive,
+// from the usage meter's daily active users
+func (s *DBReportService) GetActiveUsersReport(ctx context.Context, from, to time.Time) (*ActiveUsersReport, error) {
+	start, end, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ActiveUsersReport{From: start, To: end, Tenants: []TenantActiveUsers{}}
+
+	tenantsQuery := `
+		SELECT t.id, t.name, t.slug, COUNT(DISTINCT u.user_id)
+		FROM tenant_usage_user u
+		JOIN tenant t ON t.id = u.tenant_id
+		WHERE u.usage_date BETWEEN $1 AND $2 AND t.deleted_at IS NULL
+		GROUP BY t.id, t.name, t.slug
+		ORDER BY COUNT(DISTINCT u.user_id) DESC, t.id
+	`
+
+	totalQuery := `
+		SELECT COUNT(DISTINCT user_id)
+		FROM tenant_usage_user
+		WHERE usage_date BETWEEN $1 AND $2
+	`
+
+	err = s.txManager.WithCrossTenantRead(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, tenantsQuery, start, end)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var tenant TenantActiveUsers
+			if err := rows.Scan(&tenant.TenantID, &tenant.Name, &tenant.Slug, &tenant.ActiveUsers); err != nil {
+				return fmt.Errorf("%w: %v", ErrDBOperation, err)
+			}
+			report.Tenants = append(report.Tenants, tenant)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+
+		if err := tx.QueryRowContext(ctx, totalQuery, start, end).Scan(&report.ActiveUsers); err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// reportRange validates a report's date range and formats its dates
+func reportRange(from, to time.Time) (string, string, error) {
+	if to.Before(from) {
+		return "", "", fmt.Errorf("%w: end date is before start date", ErrInvalidInput)
+	}
+	if to.Sub(from) >= MaxReportDays*24*time.Hour {
+		return "", "", fmt.Errorf("%w: reports cover at most %d days", ErrInvalidInput, MaxReportDays)
+	}
+	return from.UTC().Format(usageDateLayout), to.UTC().Format(usageDateLayout), nil
+}
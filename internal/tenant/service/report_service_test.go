@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// expectCrossTenantRead sets up the expectations for opening a cross-tenant read
+func expectCrossTenantRead(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config\\('core.tenant_context', '', TRUE\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestGetOrdersReport(t *testing.T) {
+	adminCtx := authctx.WithRoles(authctx.WithUserID(context.Background(), 1), []authctx.Role{authctx.RoleAdmin})
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Admin", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBReportService(db)
+
+		// Setup mock expectations
+		expectCrossTenantRead(mock)
+		mock.ExpectQuery("SELECT t.id, t.name, t.slug, COUNT\\(o.order_id\\)(.+)FROM tenant t LEFT JOIN \"order\" o").
+			WithArgs("2025-03-01", "2025-03-31").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "orders", "revenue"}).
+				AddRow(2, "Globex", "globex", 12, 340.5).
+				AddRow(1, "Acme", "acme", 3, 59.5).
+				AddRow(3, "Initech", "initech", 0, 0))
+		mock.ExpectCommit()
+
+		// Execute
+		report, err := service.GetOrdersReport(adminCtx, from, to)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "2025-03-01", report.From)
+		assert.Equal(t, "2025-03-31", report.To)
+		require.Len(t, report.Tenants, 3)
+		assert.Equal(t, TenantOrderTotals{TenantID: 2, Name: "Globex", Slug: "globex", Orders: 12, Revenue: 340.5}, report.Tenants[0])
+		assert.Equal(t, int64(15), report.Orders)
+		assert.Equal(t, 400.0, report.Revenue)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not an admin", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBReportService(db)
+		ctx := authctx.WithRoles(context.Background(), []authctx.Role{authctx.RoleTenantSuper})
+
+		// Execute
+		report, err := service.GetOrdersReport(ctx, from, to)
+
+		// Assert
+		assert.Nil(t, report)
+		assert.True(t, errors.Is(err, transaction.ErrAdminRequired))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBReportService(db)
+
+		// Execute
+		_, err = service.GetOrdersReport(adminCtx, to, from)
+		_, tooLongErr := service.GetOrdersReport(adminCtx, from, from.AddDate(2, 0, 0))
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.True(t, errors.Is(tooLongErr, ErrInvalidInput))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBReportService(db)
+
+		// Setup mock expectations
+		expectCrossTenantRead(mock)
+		mock.ExpectQuery("SELECT t.id").WillReturnError(errors.New("connection refused"))
+		mock.ExpectRollback()
+
+		// Execute
+		_, err = service.GetOrdersReport(adminCtx, from, to)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetGrowthReport(t *testing.T) {
+	adminCtx := authctx.WithRoles(context.Background(), []authctx.Role{authctx.RoleAdmin})
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBReportService(db)
+
+	// Setup mock expectations
+	expectCrossTenantRead(mock)
+	mock.ExpectQuery("FROM generate_series\\(\\$1::date, \\$2::date, INTERVAL '1 day'\\)").
+		WithArgs("2025-03-01", "2025-03-03").
+		WillReturnRows(sqlmock.NewRows([]string{"day", "new_tenants", "new_users", "new_orders"}).
+			AddRow(from, 1, 4, 10).
+			AddRow(from.AddDate(0, 0, 1), 0, 0, 0).
+			AddRow(to, 2, 3, 7))
+	mock.ExpectCommit()
+
+	// Execute
+	report, err := service.GetGrowthReport(adminCtx, from, to)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Days, 3)
+	assert.Equal(t, DailyGrowth{Date: "2025-03-02"}, report.Days[1])
+	assert.Equal(t, "2025-03-03", report.Days[2].Date)
+	assert.Equal(t, int64(3), report.NewTenants)
+	assert.Equal(t, int64(7), report.NewUsers)
+	assert.Equal(t, int64(17), report.NewOrders)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActiveUsersReport(t *testing.T) {
+	adminCtx := authctx.WithRoles(context.Background(), []authctx.Role{authctx.RoleAdmin})
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBReportService(db)
+
+	// Setup mock expectations
+	expectCrossTenantRead(mock)
+	mock.ExpectQuery("SELECT t.id, t.name, t.slug, COUNT\\(DISTINCT u.user_id\\) FROM tenant_usage_user u").
+		WithArgs("2025-03-01", "2025-03-31").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "active_users"}).
+			AddRow(1, "Acme", "acme", 8).
+			AddRow(2, "Globex", "globex", 5))
+	mock.ExpectQuery("SELECT COUNT\\(DISTINCT user_id\\) FROM tenant_usage_user").
+		WithArgs("2025-03-01", "2025-03-31").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+	mock.ExpectCommit()
+
+	// Execute
+	report, err := service.GetActiveUsersReport(adminCtx, from, to)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Tenants, 2)
+	assert.Equal(t, TenantActiveUsers{TenantID: 1, Name: "Acme", Slug: "acme", ActiveUsers: 8}, report.Tenants[0])
+	assert.Equal(t, int64(11), report.ActiveUsers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// RecentActivityLimit is the number of audit log entries included in tenant statistics
+const RecentActivityLimit = 10
+
+// RecentRevenueWindow is the period covered by TenantStats.Revenue.Recent
+const RecentRevenueWindow = 30 * 24 * time.Hour
+
+// TenantStats summarizes a tenant's members, orders and recent activity
+type TenantStats struct {
+	TenantID       int64          `json:"tenant_id"`
+	Members        int            `json:"members"`
+	Orders         OrderStats     `json:"orders"`
+	Revenue        RevenueStats   `json:"revenue"`
+	RecentActivity []ActivityItem `json:"recent_activity"`
+}
+
+// OrderStats counts a tenant's orders
+type OrderStats struct {
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"by_status"`
+}
+
+// RevenueStats totals the amounts of a tenant's orders
+type RevenueStats struct {
+	Total    float64            `json:"total"`
+	ByStatus map[string]float64 `json:"by_status"`
+	// Recent is the total of orders created in the last RecentRevenueWindow
+	Recent float64 `json:"recent"`
+}
+
+// ActivityItem is an entry of a tenant's audit log
+type ActivityItem struct {
+	ActorUserID  *int64    `json:"actor_user_id"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// StatsService defines the interface for tenant statistics
+type StatsService interface {
+	// GetTenantStats computes a tenant's statistics
+	GetTenantStats(ctx context.Context, tenantID int64) (*TenantStats, error)
+}
+
+// DBStatsService implements StatsService using a database
+type DBStatsService struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// Ensure DBStatsService implements StatsService
+var _ StatsService = (*DBStatsService)(nil)
+
+// NewDBStatsService creates a new DBStatsService
+func NewDBStatsService(db *sql.DB) *DBStatsService {
+	return &DBStatsService{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+// GetTenantStats computes a tenant's statistics in a single query. Orders are
+// aggregated by status once, and the breakdowns and recent activity are returned
+// as JSON.
+func (s *DBStatsService) GetTenantStats(ctx context.Context, tenantID int64) (*TenantStats, error) {
+	query := `
+		WITH order_totals AS (
+			SELECT status,
+				COUNT(*) AS orders,
+				COALESCE(SUM(total_amount), 0) AS revenue,
+				COALESCE(SUM(total_amount) FILTER (WHERE created_at >= $2), 0) AS recent_revenue
+			FROM "order"
+			WHERE tenant_id = $1 AND deleted_at IS NULL
+			GROUP BY status
+		),
+		recent_activity AS (
+			SELECT actor_user_id, action, resource_type, resource_id, created_at
+			FROM audit_log
+			WHERE tenant_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $3
+		)
+		SELECT
+			(SELECT COUNT(*) FROM tenant_member WHERE tenant_id = $1),
+			COALESCE((SELECT json_object_agg(status, orders) FROM order_totals), '{}'),
+			COALESCE((SELECT json_object_agg(status, revenue) FROM order_totals), '{}'),
+			COALESCE((SELECT SUM(recent_revenue) FROM order_totals), 0),
+			COALESCE((SELECT json_agg(a ORDER BY a.created_at DESC) FROM recent_activity a), '[]')
+	`
+
+	stats := TenantStats{TenantID: tenantID}
+	var ordersByStatus, revenueByStatus, activity []byte
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, tenantID, s.now().Add(-RecentRevenueWindow), RecentActivityLimit).Scan(
+		&stats.Members,
+		&ordersByStatus,
+		&revenueByStatus,
+		&stats.Revenue.Recent,
+		&activity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if err := json.Unmarshal(ordersByStatus, &stats.Orders.ByStatus); err != nil {
+		return nil, fmt.Errorf("failed to decode order counts: %w", err)
+	}
+	if err := json.Unmarshal(revenueByStatus, &stats.Revenue.ByStatus); err != nil {
+		return nil, fmt.Errorf("failed to decode revenue totals: %w", err)
+	}
+	if err := json.Unmarshal(activity, &stats.RecentActivity); err != nil {
+		return nil, fmt.Errorf("failed to decode recent activity: %w", err)
+	}
+
+	for _, count := range stats.Orders.ByStatus {
+		stats.Orders.Total += count
+	}
+	for _, revenue := range stats.Revenue.ByStatus {
+		stats.Revenue.Total += revenue
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tenantStatsColumns = []string{"members", "orders_by_status", "revenue_by_status", "recent_revenue", "recent_activity"}
+
+func TestGetTenantStats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Tenant with orders", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBStatsService(db)
+		service.now = func() time.Time { return now }
+
+		// Setup mock expectations
+		mock.ExpectQuery("WITH order_totals AS (.+) FROM \"order\" WHERE tenant_id = \\$1 AND deleted_at IS NULL GROUP BY status").
+			WithArgs(int64(1), now.Add(-RecentRevenueWindow), RecentActivityLimit).
+			WillReturnRows(sqlmock.NewRows(tenantStatsColumns).AddRow(
+				4,
+				[]byte(`{"pending": 2, "shipped": 3}`),
+				[]byte(`{"pending": 20.5, "shipped": 99.5}`),
+				45.25,
+				[]byte(`[{"actor_user_id": 7, "action": "order.created", "resource_type": "order", "resource_id": "12", "created_at": "2025-03-30T09:15:00.123456+00:00"}]`),
+			))
+
+		// Execute
+		stats, err := service.GetTenantStats(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 4, stats.Members)
+		assert.Equal(t, OrderStats{Total: 5, ByStatus: map[string]int{"pending": 2, "shipped": 3}}, stats.Orders)
+		assert.Equal(t, 120.0, stats.Revenue.Total)
+		assert.Equal(t, 45.25, stats.Revenue.Recent)
+		require.Len(t, stats.RecentActivity, 1)
+		assert.Equal(t, "order.created", stats.RecentActivity[0].Action)
+		assert.Equal(t, int64(7), *stats.RecentActivity[0].ActorUserID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("New tenant", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBStatsService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("WITH order_totals AS").
+			WithArgs(int64(2), sqlmock.AnyArg(), RecentActivityLimit).
+			WillReturnRows(sqlmock.NewRows(tenantStatsColumns).AddRow(1, []byte(`{}`), []byte(`{}`), 0, []byte(`[]`)))
+
+		// Execute
+		stats, err := service.GetTenantStats(ctx, 2)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.Orders.Total)
+		assert.Empty(t, stats.Orders.ByStatus)
+		assert.Empty(t, stats.RecentActivity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBStatsService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("WITH order_totals AS").
+			WillReturnError(errors.New("connection reset"))
+
+		// Execute
+		_, err = service.GetTenantStats(ctx, 1)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"github.com/unsavory/silocore-go/internal/database/transaction"
 )
 
 // Common errors
@@ -19,6 +21,7 @@ var (
 type TenantMembership struct {
 	UserID    int64     `json:"user_id"`
 	TenantID  int64     `json:"tenant_id"`
+	IsDefault bool      `json:"is_default"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -27,9 +30,13 @@ type TenantMemberService interface {
 	// GetUserTenantMemberships retrieves all tenant memberships for a user
 	GetUserTenantMemberships(ctx context.Context, userID int64) ([]TenantMembership, error)
 
-	// GetUserDefaultTenant retrieves a user's default tenant ID (first tenant in membership list)
+	// GetUserDefaultTenant retrieves a user's default tenant ID, falling back to their
+	// oldest membership when no default has been chosen
 	GetUserDefaultTenant(ctx context.Context, userID int64) (*int64, error)
 
+	// SetUserDefaultTenant makes a tenant the one a user lands in after logging in
+	SetUserDefaultTenant(ctx context.Context, userID int64, tenantID int64) error
+
 	// IsTenantMember checks if a user is a member of a specific tenant
 	IsTenantMember(ctx context.Context, userID int64, tenantID int64) (bool, error)
 
@@ -53,7 +60,7 @@ func NewDBTenantMemberService(db *sql.DB) *DBTenantMemberService {
 // GetUserTenantMemberships retrieves all tenant memberships for a user
 func (s *DBTenantMemberService) GetUserTenantMemberships(ctx context.Context, userID int64) ([]TenantMembership, error) {
 	query := `
-		SELECT tenant_id, user_id, created_at
+		SELECT tenant_id, user_id, is_default, created_at
 		FROM tenant_member
 		WHERE user_id = $1
 		ORDER BY created_at ASC
@@ -72,6 +79,7 @@ func (s *DBTenantMemberService) GetUserTenantMemberships(ctx context.Context, us
 		if err := rows.Scan(
 			&membership.TenantID,
 			&membership.UserID,
+			&membership.IsDefault,
 			&membership.CreatedAt,
 		); err != nil {
 			log.Printf("[ERROR] Error scanning tenant membership row for user %d: %v", userID, err)
@@ -88,14 +96,15 @@ func (s *DBTenantMemberService) GetUserTenantMemberships(ctx context.Context, us
 	return memberships, nil
 }
 
-// GetUserDefaultTenant retrieves a user's default tenant ID (first tenant in membership list)
+// GetUserDefaultTenant retrieves a user's default tenant ID, falling back to their
+// oldest membership when no default has been chosen
 func (s *DBTenantMemberService) GetUserDefaultTenant(ctx context.Context, userID int64) (*int64, error) {
-	// Get the first tenant membership for the user (ordered by created_at)
+	// Prefer the chosen default, then the first tenant membership (ordered by created_at)
 	query := `
 		SELECT tenant_id
 		FROM tenant_member
 		WHERE user_id = $1
-		ORDER BY created_at ASC
+		ORDER BY is_default DESC, created_at ASC
 		LIMIT 1
 	`
 
@@ -114,6 +123,49 @@ func (s *DBTenantMemberService) GetUserDefaultTenant(ctx context.Context, userID
 	return &tenantID, nil
 }
 
+// SetUserDefaultTenant makes a tenant the one a user lands in after logging in,
+// clearing any previous default
+func (s *DBTenantMemberService) SetUserDefaultTenant(ctx context.Context, userID int64, tenantID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("[ERROR] Failed to begin transaction when setting default tenant %d for user %d: %v", tenantID, userID, err)
+		return fmt.Errorf("%w: %v", ErrDBOperationTM, err)
+	}
+	defer tx.Rollback()
+
+	// Clear the previous default first so the unique index on defaults isn't violated
+	_, err = tx.ExecContext(ctx, "UPDATE tenant_member SET is_default = FALSE WHERE user_id = $1 AND is_default", userID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to clear default tenant for user %d: %v", userID, err)
+		return fmt.Errorf("%w: %v", ErrDBOperationTM, err)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE tenant_member SET is_default = TRUE WHERE user_id = $1 AND tenant_id = $2", userID, tenantID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to set default tenant %d for user %d: %v", tenantID, userID, err)
+		return fmt.Errorf("%w: %v", ErrDBOperationTM, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("[ERROR] Failed to get rows affected when setting default tenant %d for user %d: %v", tenantID, userID, err)
+		return fmt.Errorf("%w: %v", ErrDBOperationTM, err)
+	}
+
+	if rowsAffected == 0 {
+		log.Printf("[WARN] User %d is not a member of tenant %d", userID, tenantID)
+		return ErrMemberNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("[ERROR] Failed to commit transaction when setting default tenant %d for user %d: %v", tenantID, userID, err)
+		return fmt.Errorf("%w: %v", ErrDBOperationTM, err)
+	}
+
+	log.Printf("[INFO] Tenant %d is now the default tenant for user %d", tenantID, userID)
+	return nil
+}
+
 // IsTenantMember checks if a user is a member of a specific tenant
 func (s *DBTenantMemberService) IsTenantMember(ctx context.Context, userID int64, tenantID int64) (bool, error) {
 	query := `
@@ -141,7 +193,7 @@ func (s *DBTenantMemberService) AddTenantMember(ctx context.Context, userID int6
 		ON CONFLICT (user_id, tenant_id) DO NOTHING
 	`
 
-	_, err := s.db.ExecContext(ctx, query, userID, tenantID)
+	_, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, query, userID, tenantID)
 	if err != nil {
 		log.Printf("[ERROR] Database error when adding user %d to tenant %d: %v", userID, tenantID, err)
 		return fmt.Errorf("%w: %v", ErrDBOperationTM, err)
@@ -29,7 +29,7 @@ func TestGetUserDefaultTenant(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"tenant_id"}).
 			AddRow(expectedTenantID)
 
-		mock.ExpectQuery("SELECT tenant_id FROM tenant_member").
+		mock.ExpectQuery("SELECT tenant_id FROM tenant_member WHERE user_id = \\$1 ORDER BY is_default DESC, created_at ASC").
 			WithArgs(userID).
 			WillReturnRows(rows)
 
@@ -161,11 +161,11 @@ func TestGetUserTenantMemberships(t *testing.T) {
 
 	t.Run("User has tenant memberships", func(t *testing.T) {
 		// Set up mock expectations
-		rows := sqlmock.NewRows([]string{"tenant_id", "user_id", "created_at"}).
-			AddRow(1, userID, now).
-			AddRow(2, userID, now)
+		rows := sqlmock.NewRows([]string{"tenant_id", "user_id", "is_default", "created_at"}).
+			AddRow(1, userID, false, now).
+			AddRow(2, userID, true, now)
 
-		mock.ExpectQuery("SELECT tenant_id, user_id, created_at FROM tenant_member").
+		mock.ExpectQuery("SELECT tenant_id, user_id, is_default, created_at FROM tenant_member").
 			WithArgs(userID).
 			WillReturnRows(rows)
 
@@ -175,6 +175,7 @@ func TestGetUserTenantMemberships(t *testing.T) {
 		assert.Len(t, memberships, 2)
 		assert.Equal(t, int64(1), memberships[0].TenantID)
 		assert.Equal(t, int64(2), memberships[1].TenantID)
+		assert.True(t, memberships[1].IsDefault)
 
 		// Ensure all expectations were met
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -182,9 +183,9 @@ func TestGetUserTenantMemberships(t *testing.T) {
 
 	t.Run("User has no tenant memberships", func(t *testing.T) {
 		// Set up mock expectations
-		rows := sqlmock.NewRows([]string{"tenant_id", "user_id", "created_at"})
+		rows := sqlmock.NewRows([]string{"tenant_id", "user_id", "is_default", "created_at"})
 
-		mock.ExpectQuery("SELECT tenant_id, user_id, created_at FROM tenant_member").
+		mock.ExpectQuery("SELECT tenant_id, user_id, is_default, created_at FROM tenant_member").
 			WithArgs(userID).
 			WillReturnRows(rows)
 
@@ -199,7 +200,7 @@ func TestGetUserTenantMemberships(t *testing.T) {
 
 	t.Run("Database error", func(t *testing.T) {
 		// Set up mock expectations
-		mock.ExpectQuery("SELECT tenant_id, user_id, created_at FROM tenant_member").
+		mock.ExpectQuery("SELECT tenant_id, user_id, is_default, created_at FROM tenant_member").
 			WithArgs(userID).
 			WillReturnError(sql.ErrConnDone)
 
@@ -212,3 +213,57 @@ func TestGetUserTenantMemberships(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestSetUserDefaultTenant(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create a new tenant member service with the mock database
+	tenantMemberService := NewDBTenantMemberService(db)
+
+	// Set up test data
+	userID := int64(1)
+	tenantID := int64(2)
+
+	t.Run("Default tenant is replaced", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE tenant_member SET is_default = FALSE WHERE user_id = \\$1 AND is_default").
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE tenant_member SET is_default = TRUE WHERE user_id = \\$1 AND tenant_id = \\$2").
+			WithArgs(userID, tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Call the method being tested
+		err := tenantMemberService.SetUserDefaultTenant(context.Background(), userID, tenantID)
+		assert.NoError(t, err)
+
+		// Ensure all expectations were met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("User is not a member", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE tenant_member SET is_default = FALSE").
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE tenant_member SET is_default = TRUE").
+			WithArgs(userID, tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		// Call the method being tested
+		err := tenantMemberService.SetUserDefaultTenant(context.Background(), userID, tenantID)
+		assert.ErrorIs(t, err, ErrMemberNotFound)
+
+		// Ensure all expectations were met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -5,51 +5,170 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// MaxSlugLength is the maximum length of a tenant slug, which must fit in a DNS label
+const MaxSlugLength = 63
+
+var (
+	slugPattern    = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
+	slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+	reservedSlugs  = map[string]bool{"www": true, "api": true, "admin": true, "app": true}
 )
 
+// tenantSuperRole is the role granted to the user who creates a tenant
+const tenantSuperRole = "TENANT_SUPER"
+
 // Common errors
 var (
 	ErrTenantNotFound = errors.New("tenant not found")
 	ErrDBOperation    = errors.New("database operation failed")
 	ErrInvalidInput   = errors.New("invalid input")
+
+	// ErrTenantExists is returned when another tenant already uses the name or slug
+	ErrTenantExists = errors.New("a tenant with this name or slug already exists")
+
+	// ErrInvalidTenantStatus is returned when a status change isn't allowed from the tenant's current status
+	ErrInvalidTenantStatus = errors.New("invalid tenant status transition")
+)
+
+// TenantStatus is the lifecycle state of a tenant
+type TenantStatus string
+
+// Tenant statuses
+const (
+	TenantStatusActive          TenantStatus = "active"
+	TenantStatusSuspended       TenantStatus = "suspended"
+	TenantStatusPendingDeletion TenantStatus = "pending_deletion"
+	TenantStatusArchived        TenantStatus = "archived"
 )
 
 // Tenant represents a tenant in the system
 type Tenant struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	Slug        string       `json:"slug"`
+	Status      TenantStatus `json:"status"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Tenant list page sizes
+const (
+	DefaultTenantListLimit = 50
+	MaxTenantListLimit     = 500
+)
+
+// TenantFilter defines filters for listing tenants
+type TenantFilter struct {
+	// Search matches tenants whose name or slug contains the term, ignoring case
+	Search string
+	// Status restricts the list to tenants in a status. Deleted tenants are only
+	// listed when filtering for TenantStatusPendingDeletion.
+	Status      TenantStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Limit       int
+	Offset      int
+}
+
+// TenantList is a page of tenants with the total number of tenants matching the filter
+type TenantList struct {
+	Tenants []Tenant `json:"tenants"`
+	Total   int      `json:"total"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
 }
 
-// TenantMember represents a user's membership in a tenant
+// TenantMember represents a user's membership in a tenant, with the user's details
+// and tenant roles
 type TenantMember struct {
-	UserID    int64     `json:"user_id"`
-	TenantID  int64     `json:"tenant_id"`
+	UserID    int64  `json:"user_id"`
+	TenantID  int64  `json:"tenant_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	// Roles are the names of the member's roles in the tenant
+	Roles []string `json:"roles"`
+	// CreatedAt is when the user joined the tenant
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Tenant member list page sizes
+const (
+	DefaultMemberListLimit = 50
+	MaxMemberListLimit     = 500
+)
+
+// TenantMemberFilter defines filters for listing a tenant's members
+type TenantMemberFilter struct {
+	TenantID int64
+	// Email matches members whose email contains the term, ignoring case
+	Email  string
+	Limit  int
+	Offset int
+}
+
+// TenantMemberList is a page of tenant members with the total number of members
+// matching the filter
+type TenantMemberList struct {
+	Members []TenantMember `json:"members"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
 // TenantService defines the interface for tenant-related operations
 type TenantService interface {
 	// GetTenant retrieves a tenant by ID
 	GetTenant(ctx context.Context, tenantID int64) (*Tenant, error)
 
-	// ListTenants retrieves all tenants
-	ListTenants(ctx context.Context) ([]Tenant, error)
+	// GetTenantBySlug retrieves a tenant by its URL slug
+	GetTenantBySlug(ctx context.Context, slug string) (*Tenant, error)
+
+	// ListTenants retrieves a page of tenants matching the filter, along with the total count
+	ListTenants(ctx context.Context, filter TenantFilter) (*TenantList, error)
 
 	// CreateTenant creates a new tenant
 	CreateTenant(ctx context.Context, tenant *Tenant) (*Tenant, error)
 
+	// CreateTenantWithOwner creates a new tenant and makes the given user its
+	// first member with the TENANT_SUPER role
+	CreateTenantWithOwner(ctx context.Context, tenant *Tenant, ownerUserID int64) (*Tenant, error)
+
+	// GetTenantStatus retrieves the lifecycle status of a tenant
+	GetTenantStatus(ctx context.Context, tenantID int64) (TenantStatus, error)
+
+	// SuspendTenant blocks access to an active tenant
+	SuspendTenant(ctx context.Context, tenantID int64) error
+
+	// ResumeTenant restores access to a suspended tenant
+	ResumeTenant(ctx context.Context, tenantID int64) error
+
 	// UpdateTenant updates an existing tenant
 	UpdateTenant(ctx context.Context, tenant *Tenant) error
 
-	// DeleteTenant deletes a tenant
+	// DeleteTenant soft deletes a tenant, which can be restored until its retention window expires
 	DeleteTenant(ctx context.Context, tenantID int64) error
 
-	// GetTenantMembers retrieves all members of a tenant
-	GetTenantMembers(ctx context.Context, tenantID int64) ([]TenantMember, error)
+	// RestoreTenant restores a soft deleted tenant
+	RestoreTenant(ctx context.Context, tenantID int64) error
+
+	// PurgeDeletedTenants permanently deletes tenants whose retention window has
+	// expired, along with all of their data
+	PurgeDeletedTenants(ctx context.Context) (*PurgeReport, error)
+
+	// GetTenantMembers retrieves a page of a tenant's members matching the filter,
+	// along with the total count
+	GetTenantMembers(ctx context.Context, filter TenantMemberFilter) (*TenantMemberList, error)
 
 	// AddTenantMember adds a user to a tenant
 	AddTenantMember(ctx context.Context, userID int64, tenantID int64) error
@@ -63,26 +182,63 @@ type TenantService interface {
 
 // DBTenantService implements TenantService using a database
 type DBTenantService struct {
-	db *sql.DB
+	db          *sql.DB
+	router      *database.QueryRouter
+	provisioner TenantProvisioner
+	retention   time.Duration
+	now         func() time.Time
 }
 
 // NewDBTenantService creates a new DBTenantService
 func NewDBTenantService(db *sql.DB) *DBTenantService {
-	return &DBTenantService{db: db}
+	return &DBTenantService{
+		db:        db,
+		retention: DefaultTenantRetention,
+		now:       time.Now,
+	}
+}
+
+// WithRetention sets how long soft deleted tenants can be restored before they are purged
+func (s *DBTenantService) WithRetention(retention time.Duration) *DBTenantService {
+	s.retention = retention
+	return s
+}
+
+// WithQueryRouter reads tenants by ID, slug and status from the router's read
+// replicas
+func (s *DBTenantService) WithQueryRouter(router *database.QueryRouter) *DBTenantService {
+	s.router = router
+	return s
+}
+
+// WithProvisioner provisions the database resources of each tenant created
+func (s *DBTenantService) WithProvisioner(provisioner TenantProvisioner) *DBTenantService {
+	s.provisioner = provisioner
+	return s
+}
+
+// reader returns the database for tenant lookups
+func (s *DBTenantService) reader(ctx context.Context) *sql.DB {
+	if s.router == nil {
+		return s.db
+	}
+	return s.router.Reader(ctx)
 }
 
 // GetTenant retrieves a tenant by ID
 func (s *DBTenantService) GetTenant(ctx context.Context, tenantID int64) (*Tenant, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, slug, status, description, created_at, updated_at
 		FROM tenant
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var tenant Tenant
-	err := s.db.QueryRowContext(ctx, query, tenantID).Scan(
+	err := s.reader(ctx).QueryRowContext(ctx, query, tenantID).Scan(
 		&tenant.ID,
 		&tenant.Name,
+		&tenant.Slug,
+		&tenant.Status,
 		&tenant.Description,
 		&tenant.CreatedAt,
 		&tenant.UpdatedAt,
@@ -98,40 +254,152 @@ func (s *DBTenantService) GetTenant(ctx context.Context, tenantID int64) (*Tenan
 	return &tenant, nil
 }
 
-// ListTenants retrieves all tenants
-func (s *DBTenantService) ListTenants(ctx context.Context) ([]Tenant, error) {
+// GetTenantBySlug retrieves a tenant by its URL slug
+func (s *DBTenantService) GetTenantBySlug(ctx context.Context, slug string) (*Tenant, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, slug, status, description, created_at, updated_at
 		FROM tenant
-		ORDER BY name
+		WHERE slug = $1 AND deleted_at IS NULL
 	`
 
-	rows, err := s.db.QueryContext(ctx, query)
+	var tenant Tenant
+	err := s.reader(ctx).QueryRowContext(ctx, query, strings.ToLower(slug)).Scan(
+		&tenant.ID,
+		&tenant.Name,
+		&tenant.Slug,
+		&tenant.Status,
+		&tenant.Description,
+		&tenant.CreatedAt,
+		&tenant.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return &tenant, nil
+}
+
+// ListTenants retrieves a page of tenants matching the filter, ordered by name,
+// along with the total number of matching tenants
+func (s *DBTenantService) ListTenants(ctx context.Context, filter TenantFilter) (*TenantList, error) {
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset cannot be negative", ErrInvalidInput)
+	}
+	switch filter.Status {
+	case "", TenantStatusActive, TenantStatusSuspended, TenantStatusPendingDeletion, TenantStatusArchived:
+	default:
+		return nil, fmt.Errorf("%w: unknown tenant status %q", ErrInvalidInput, filter.Status)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: created range is empty", ErrInvalidInput)
+	}
+
+	where, args := tenantFilterConditions(filter)
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM tenant WHERE " + where
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultTenantListLimit
+	}
+	if limit > MaxTenantListLimit {
+		limit = MaxTenantListLimit
+	}
+
+	list := &TenantList{
+		Tenants: []Tenant{},
+		Total:   total,
+		Limit:   limit,
+		Offset:  filter.Offset,
+	}
+
+	// Skip the page query when the offset is past the last match
+	if filter.Offset >= total {
+		return list, nil
+	}
+
+	args = append(args, limit, filter.Offset)
+	query := `
+		SELECT id, name, slug, status, description, created_at, updated_at
+		FROM tenant
+		WHERE ` + where + fmt.Sprintf(`
+		ORDER BY name, id
+		LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 	defer rows.Close()
 
-	var tenants []Tenant
 	for rows.Next() {
 		var tenant Tenant
 		if err := rows.Scan(
 			&tenant.ID,
 			&tenant.Name,
+			&tenant.Slug,
+			&tenant.Status,
 			&tenant.Description,
 			&tenant.CreatedAt,
 			&tenant.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 		}
-		tenants = append(tenants, tenant)
+		list.Tenants = append(list.Tenants, tenant)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	return tenants, nil
+	return list, nil
+}
+
+// tenantFilterConditions builds the WHERE clause and arguments for a tenant filter.
+// Deleted tenants are only included when filtering for pending_deletion.
+func tenantFilterConditions(filter TenantFilter) (string, []interface{}) {
+	var args []interface{}
+	var conditions []string
+
+	if filter.Status == TenantStatusPendingDeletion {
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "deleted_at IS NULL")
+		if filter.Status != "" {
+			args = append(args, filter.Status)
+			conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+		}
+	}
+
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		args = append(args, "%"+escapeLikePattern(search)+"%")
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR slug ILIKE $%d)", len(args), len(args)))
+	}
+
+	if filter.CreatedFrom != nil {
+		args = append(args, *filter.CreatedFrom)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if filter.CreatedTo != nil {
+		args = append(args, *filter.CreatedTo)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// escapeLikePattern escapes the LIKE wildcards in a search term so it matches literally
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
 }
 
 // CreateTenant creates a new tenant
@@ -140,27 +408,171 @@ func (s *DBTenantService) CreateTenant(ctx context.Context, tenant *Tenant) (*Te
 		return nil, fmt.Errorf("%w: tenant name is required", ErrInvalidInput)
 	}
 
+	if err := prepareTenantSlug(tenant); err != nil {
+		return nil, err
+	}
+
 	query := `
-		INSERT INTO tenant (name, description)
-		VALUES ($1, $2)
-		RETURNING id, name, description, created_at, updated_at
+		INSERT INTO tenant (name, slug, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, name, slug, status, description, created_at, updated_at
 	`
 
-	err := s.db.QueryRowContext(ctx, query, tenant.Name, tenant.Description).Scan(
+	err := s.db.QueryRowContext(ctx, query, tenant.Name, tenant.Slug, tenant.Description).Scan(
+		&tenant.ID,
+		&tenant.Name,
+		&tenant.Slug,
+		&tenant.Status,
+		&tenant.Description,
+		&tenant.CreatedAt,
+		&tenant.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, tenantWriteError(err)
+	}
+
+	provisionTenant(ctx, s.provisioner, tenant.ID)
+	return tenant, nil
+}
+
+// CreateTenantWithOwner creates a new tenant and bootstraps its owner in a single
+// transaction: the owner is added as a member, granted TENANT_SUPER, and the
+// role grant is recorded in the role audit trail
+func (s *DBTenantService) CreateTenantWithOwner(ctx context.Context, tenant *Tenant, ownerUserID int64) (*Tenant, error) {
+	if tenant.Name == "" {
+		return nil, fmt.Errorf("%w: tenant name is required", ErrInvalidInput)
+	}
+
+	if ownerUserID == 0 {
+		return nil, fmt.Errorf("%w: owner user ID is required", ErrInvalidInput)
+	}
+
+	if err := prepareTenantSlug(tenant); err != nil {
+		return nil, err
+	}
+
+	// Start a transaction to ensure atomicity
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	// Create tenant
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tenant (name, slug, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, name, slug, status, description, created_at, updated_at
+	`, tenant.Name, tenant.Slug, tenant.Description).Scan(
 		&tenant.ID,
 		&tenant.Name,
+		&tenant.Slug,
+		&tenant.Status,
 		&tenant.Description,
 		&tenant.CreatedAt,
 		&tenant.UpdatedAt,
 	)
+	if err != nil {
+		return nil, tenantWriteError(err)
+	}
+
+	// Add the owner as a member
+	_, err = tx.ExecContext(ctx, "INSERT INTO tenant_member (user_id, tenant_id) VALUES ($1, $2)", ownerUserID, tenant.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Grant the owner TENANT_SUPER
+	var roleID int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM role WHERE name = $1", tenantSuperRole).Scan(&roleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: role %s does not exist", ErrDBOperation, tenantSuperRole)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
 
+	_, err = tx.ExecContext(ctx, "INSERT INTO tenant_role (user_id, tenant_id, role_id) VALUES ($1, $2, $3)", ownerUserID, tenant.ID, roleID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
+	// The owner grants the role to themselves, so they are both actor and target
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO role_audit (actor_user_id, target_user_id, role_id, role_name, tenant_id, action)
+		VALUES ($1, $1, $2, $3, $4, 'assign')
+	`, ownerUserID, roleID, tenantSuperRole, tenant.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] User %d created tenant %d (%s)", ownerUserID, tenant.ID, tenant.Name)
+	provisionTenant(ctx, s.provisioner, tenant.ID)
 	return tenant, nil
 }
 
+// GetTenantStatus retrieves the lifecycle status of a tenant
+func (s *DBTenantService) GetTenantStatus(ctx context.Context, tenantID int64) (TenantStatus, error) {
+	var status TenantStatus
+	err := s.reader(ctx).QueryRowContext(ctx, "SELECT status FROM tenant WHERE id = $1", tenantID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrTenantNotFound
+		}
+		return "", fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return status, nil
+}
+
+// SuspendTenant blocks access to an active tenant
+func (s *DBTenantService) SuspendTenant(ctx context.Context, tenantID int64) error {
+	return s.transitionTenantStatus(ctx, tenantID, TenantStatusActive, TenantStatusSuspended)
+}
+
+// ResumeTenant restores access to a suspended tenant
+func (s *DBTenantService) ResumeTenant(ctx context.Context, tenantID int64) error {
+	return s.transitionTenantStatus(ctx, tenantID, TenantStatusSuspended, TenantStatusActive)
+}
+
+// transitionTenantStatus moves a tenant from one status to another, failing with
+// ErrInvalidTenantStatus if the tenant isn't currently in the expected status
+func (s *DBTenantService) transitionTenantStatus(ctx context.Context, tenantID int64, from, to TenantStatus) error {
+	query := `
+		UPDATE tenant
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`
+
+	result, err := s.db.ExecContext(ctx, query, to, tenantID, from)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		// Distinguish a missing tenant from one in the wrong status
+		current, err := s.GetTenantStatus(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: tenant is %s", ErrInvalidTenantStatus, current)
+	}
+
+	log.Printf("[INFO] Tenant %d status changed from %s to %s", tenantID, from, to)
+	return nil
+}
+
 // UpdateTenant updates an existing tenant
 func (s *DBTenantService) UpdateTenant(ctx context.Context, tenant *Tenant) error {
 	if tenant.ID == 0 {
@@ -174,12 +586,12 @@ func (s *DBTenantService) UpdateTenant(ctx context.Context, tenant *Tenant) erro
 	query := `
 		UPDATE tenant
 		SET name = $1, description = $2, updated_at = NOW()
-		WHERE id = $3
+		WHERE id = $3 AND deleted_at IS NULL
 	`
 
 	result, err := s.db.ExecContext(ctx, query, tenant.Name, tenant.Description, tenant.ID)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return tenantWriteError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -194,29 +606,43 @@ func (s *DBTenantService) UpdateTenant(ctx context.Context, tenant *Tenant) erro
 	return nil
 }
 
-// DeleteTenant deletes a tenant
+// DeleteTenant soft deletes a tenant. The tenant is hidden from queries and marked
+// pending_deletion, and can be restored until its retention window expires and
+// PurgeDeletedTenants removes it.
 func (s *DBTenantService) DeleteTenant(ctx context.Context, tenantID int64) error {
-	// Start a transaction to ensure atomicity
-	tx, err := s.db.BeginTx(ctx, nil)
+	query := `
+		UPDATE tenant
+		SET status = $1, deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := s.db.ExecContext(ctx, query, TenantStatusPendingDeletion, tenantID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
-	defer tx.Rollback()
 
-	// Delete tenant members
-	_, err = tx.ExecContext(ctx, "DELETE FROM tenant_member WHERE tenant_id = $1", tenantID)
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	// Delete tenant roles
-	_, err = tx.ExecContext(ctx, "DELETE FROM tenant_role WHERE tenant_id = $1", tenantID)
-	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	if rowsAffected == 0 {
+		return ErrTenantNotFound
 	}
 
-	// Delete tenant
-	result, err := tx.ExecContext(ctx, "DELETE FROM tenant WHERE id = $1", tenantID)
+	log.Printf("[INFO] Tenant %d marked for deletion, restorable for %s", tenantID, s.retention)
+	return nil
+}
+
+// RestoreTenant restores a soft deleted tenant whose retention window hasn't expired
+func (s *DBTenantService) RestoreTenant(ctx context.Context, tenantID int64) error {
+	query := `
+		UPDATE tenant
+		SET status = $1, deleted_at = NULL, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NOT NULL AND deleted_at > $3
+	`
+
+	result, err := s.db.ExecContext(ctx, query, TenantStatusActive, tenantID, s.purgeCutoff())
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
@@ -227,49 +653,207 @@ func (s *DBTenantService) DeleteTenant(ctx context.Context, tenantID int64) erro
 	}
 
 	if rowsAffected == 0 {
-		return ErrTenantNotFound
+		current, err := s.GetTenantStatus(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		if current == TenantStatusPendingDeletion {
+			return fmt.Errorf("%w: restore window has expired", ErrInvalidTenantStatus)
+		}
+		return fmt.Errorf("%w: tenant is %s", ErrInvalidTenantStatus, current)
+	}
+
+	log.Printf("[INFO] Tenant %d restored", tenantID)
+	return nil
+}
+
+// PurgeReport summarizes the rows removed by PurgeDeletedTenants
+type PurgeReport struct {
+	Tenants int64 `json:"tenants"`
+	// Rows is the number of rows removed from each tenant-scoped table
+	Rows map[string]int64 `json:"rows"`
+}
+
+// tenantScopedTables are the tables holding tenant data, in the order they are purged.
+// The audit trail in role_audit is kept; its tenant reference is cleared by the schema.
+var tenantScopedTables = []struct {
+	name  string
+	table string
+}{
+	{"order_item", "order_item"},
+	{"order_status_history", "order_status_history"},
+	{"order_attachment", "order_attachment"},
+	{"order_comment", "order_comment"},
+	{"order_tag", "order_tag"},
+	{"order", `"order"`},
+	{"order_archive", "order_archive"},
+	{"tenant_invitation", "tenant_invitation"},
+	{"tenant_export", "tenant_export"},
+	{"tenant_ownership_transfer", "tenant_ownership_transfer"},
+	{"tenant_api_key", "tenant_api_key"},
+	{"tenant_webhook_delivery", "tenant_webhook_delivery"},
+	{"tenant_webhook", "tenant_webhook"},
+	{"tenant_domain", "tenant_domain"},
+	{"tenant_ip_rule", "tenant_ip_rule"},
+	{"tenant_archive", "tenant_archive"},
+	{"tenant_onboarding", "tenant_onboarding"},
+	{"order_number_sequence", "order_number_sequence"},
+	{"tenant_order_setting", "tenant_order_setting"},
+	{"tenant_usage_user", "tenant_usage_user"},
+	{"tenant_usage", "tenant_usage"},
+	{"tenant_quota", "tenant_quota"},
+	{"tenant_subscription", "tenant_subscription"},
+	{"tenant_branding", "tenant_branding"},
+	{"audit_log", "audit_log"},
+	{"tenant_role", "tenant_role"},
+	{"tenant_member", "tenant_member"},
+}
+
+// PurgeDeletedTenants permanently deletes tenants whose retention window has
+// expired, along with all of their data. Everything is removed in a single
+// transaction so a failure leaves the tenants intact for the next run.
+func (s *DBTenantService) PurgeDeletedTenants(ctx context.Context) (*PurgeReport, error) {
+	cutoff := s.purgeCutoff()
+
+	// Start a transaction to ensure atomicity
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	report := &PurgeReport{Rows: make(map[string]int64, len(tenantScopedTables))}
+
+	// Delete tenant data before the tenants themselves
+	for _, scoped := range tenantScopedTables {
+		query := fmt.Sprintf("DELETE FROM %s WHERE tenant_id IN (SELECT id FROM tenant WHERE deleted_at < $1)", scoped.table)
+		result, err := tx.ExecContext(ctx, query, cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to purge %s: %v", ErrDBOperation, scoped.name, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		report.Rows[scoped.name] = rowsAffected
+	}
+
+	// Delete tenants
+	result, err := tx.ExecContext(ctx, "DELETE FROM tenant WHERE deleted_at < $1", cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	report.Tenants, err = result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
 	// Commit the transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	return nil
+	return report, nil
 }
 
-// GetTenantMembers retrieves all members of a tenant
-func (s *DBTenantService) GetTenantMembers(ctx context.Context, tenantID int64) ([]TenantMember, error) {
-	query := `
-		SELECT user_id, tenant_id, created_at
-		FROM tenant_member
-		WHERE tenant_id = $1
-	`
+// purgeCutoff returns the deletion time before which soft deleted tenants can be purged
+func (s *DBTenantService) purgeCutoff() time.Time {
+	return s.now().Add(-s.retention)
+}
 
-	rows, err := s.db.QueryContext(ctx, query, tenantID)
+// GetTenantMembers retrieves a page of a tenant's members matching the filter, ordered
+// by name, along with the total number of matching members
+func (s *DBTenantService) GetTenantMembers(ctx context.Context, filter TenantMemberFilter) (*TenantMemberList, error) {
+	if filter.TenantID == 0 {
+		return nil, fmt.Errorf("%w: tenant ID is required", ErrInvalidInput)
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset cannot be negative", ErrInvalidInput)
+	}
+
+	args := []interface{}{filter.TenantID}
+	where := "tm.tenant_id = $1"
+	if email := strings.TrimSpace(filter.Email); email != "" {
+		args = append(args, "%"+escapeLikePattern(email)+"%")
+		where += fmt.Sprintf(" AND u.email ILIKE $%d", len(args))
+	}
+
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM tenant_member tm
+		JOIN usr u ON u.user_id = tm.user_id
+		WHERE ` + where
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultMemberListLimit
+	}
+	if limit > MaxMemberListLimit {
+		limit = MaxMemberListLimit
+	}
+
+	list := &TenantMemberList{
+		Members: []TenantMember{},
+		Total:   total,
+		Limit:   limit,
+		Offset:  filter.Offset,
+	}
+
+	// Skip the page query when the offset is past the last match
+	if filter.Offset >= total {
+		return list, nil
+	}
+
+	// Aggregate each member's roles so the page takes a single query
+	args = append(args, limit, filter.Offset)
+	query := `
+		SELECT tm.user_id, tm.tenant_id, u.email, u.first_name, u.last_name,
+			COALESCE(array_agg(r.name ORDER BY r.name) FILTER (WHERE r.name IS NOT NULL), '{}') AS roles,
+			tm.created_at
+		FROM tenant_member tm
+		JOIN usr u ON u.user_id = tm.user_id
+		LEFT JOIN tenant_role tr ON tr.tenant_id = tm.tenant_id AND tr.user_id = tm.user_id
+		LEFT JOIN role r ON tr.role_id = r.id
+		WHERE ` + where + fmt.Sprintf(`
+		GROUP BY tm.user_id, tm.tenant_id, u.email, u.first_name, u.last_name, tm.created_at
+		ORDER BY u.last_name, u.first_name, tm.user_id
+		LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 	defer rows.Close()
 
-	var members []TenantMember
 	for rows.Next() {
 		var member TenantMember
+		var roles database.StringArray
 		if err := rows.Scan(
 			&member.UserID,
 			&member.TenantID,
+			&member.Email,
+			&member.FirstName,
+			&member.LastName,
+			&roles,
 			&member.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 		}
-		members = append(members, member)
+		member.Roles = []string(roles)
+		list.Members = append(list.Members, member)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
 
-	return members, nil
+	return list, nil
 }
 
 // AddTenantMember adds a user to a tenant
@@ -280,7 +864,7 @@ func (s *DBTenantService) AddTenantMember(ctx context.Context, userID int64, ten
 		ON CONFLICT (user_id, tenant_id) DO NOTHING
 	`
 
-	_, err := s.db.ExecContext(ctx, query, userID, tenantID)
+	_, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, query, userID, tenantID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDBOperation, err)
 	}
@@ -329,10 +913,10 @@ func (s *DBTenantService) RemoveTenantMember(ctx context.Context, userID int64,
 // GetUserTenants retrieves all tenants a user is a member of
 func (s *DBTenantService) GetUserTenants(ctx context.Context, userID int64) ([]Tenant, error) {
 	query := `
-		SELECT t.id, t.name, t.description, t.created_at, t.updated_at
+		SELECT t.id, t.name, t.slug, t.status, t.description, t.created_at, t.updated_at
 		FROM tenant t
 		JOIN tenant_member tm ON t.id = tm.tenant_id
-		WHERE tm.user_id = $1
+		WHERE tm.user_id = $1 AND t.deleted_at IS NULL
 		ORDER BY t.name
 	`
 
@@ -348,6 +932,8 @@ func (s *DBTenantService) GetUserTenants(ctx context.Context, userID int64) ([]T
 		if err := rows.Scan(
 			&tenant.ID,
 			&tenant.Name,
+			&tenant.Slug,
+			&tenant.Status,
 			&tenant.Description,
 			&tenant.CreatedAt,
 			&tenant.UpdatedAt,
@@ -363,3 +949,42 @@ func (s *DBTenantService) GetUserTenants(ctx context.Context, userID int64) ([]T
 
 	return tenants, nil
 }
+
+// Slugify derives a tenant slug from a name by lowercasing it and replacing runs of
+// characters that are not letters or digits with hyphens
+func Slugify(name string) string {
+	slug := slugSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	if len(slug) > MaxSlugLength {
+		slug = slug[:MaxSlugLength]
+	}
+	return strings.Trim(slug, "-")
+}
+
+// ValidateSlug checks that a slug can be used as a subdomain and URL path segment
+func ValidateSlug(slug string) error {
+	if len(slug) == 0 || len(slug) > MaxSlugLength || !slugPattern.MatchString(slug) {
+		return fmt.Errorf("%w: slug must be 1-%d lowercase letters, digits or hyphens", ErrInvalidInput, MaxSlugLength)
+	}
+	if reservedSlugs[slug] {
+		return fmt.Errorf("%w: slug %q is reserved", ErrInvalidInput, slug)
+	}
+	return nil
+}
+
+// prepareTenantSlug derives the slug from the tenant name when none is given and validates it
+func prepareTenantSlug(tenant *Tenant) error {
+	if tenant.Slug == "" {
+		tenant.Slug = Slugify(tenant.Name)
+	}
+	tenant.Slug = strings.ToLower(tenant.Slug)
+	return ValidateSlug(tenant.Slug)
+}
+
+// tenantWriteError maps an error writing a tenant, reporting name and slug conflicts
+// as ErrTenantExists
+func tenantWriteError(err error) error {
+	if database.IsUniqueViolation(err) {
+		return ErrTenantExists
+	}
+	return fmt.Errorf("%w: %v", ErrDBOperation, err)
+}
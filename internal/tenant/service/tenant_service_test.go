@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -29,10 +32,10 @@ func TestGetTenant(t *testing.T) {
 
 	t.Run("Successful retrieval", func(t *testing.T) {
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow(tenantID, "Test Tenant", "Test Description", time.Now(), time.Now())
+		rows := sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}).
+			AddRow(tenantID, "Test Tenant", "test-tenant", "active", "Test Description", time.Now(), time.Now())
 
-		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM tenant WHERE id = \\$1").
+		mock.ExpectQuery("SELECT id, name, slug, status, description, created_at, updated_at FROM tenant WHERE id = \\$1 AND deleted_at IS NULL").
 			WithArgs(tenantID).
 			WillReturnRows(rows)
 
@@ -49,7 +52,7 @@ func TestGetTenant(t *testing.T) {
 
 	t.Run("Tenant not found", func(t *testing.T) {
 		// Setup mock expectations
-		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM tenant WHERE id = \\$1").
+		mock.ExpectQuery("SELECT id, name, slug, status, description, created_at, updated_at FROM tenant WHERE id = \\$1 AND deleted_at IS NULL").
 			WithArgs(tenantID).
 			WillReturnError(sql.ErrNoRows)
 
@@ -65,7 +68,7 @@ func TestGetTenant(t *testing.T) {
 	t.Run("Database error", func(t *testing.T) {
 		// Setup mock expectations
 		dbErr := errors.New("database error")
-		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM tenant WHERE id = \\$1").
+		mock.ExpectQuery("SELECT id, name, slug, status, description, created_at, updated_at FROM tenant WHERE id = \\$1 AND deleted_at IS NULL").
 			WithArgs(tenantID).
 			WillReturnError(dbErr)
 
@@ -79,7 +82,7 @@ func TestGetTenant(t *testing.T) {
 	})
 }
 
-func TestListTenants(t *testing.T) {
+func TestGetTenantBySlug(t *testing.T) {
 	db, mock, service := setupMockDB(t)
 	defer db.Close()
 
@@ -87,52 +90,155 @@ func TestListTenants(t *testing.T) {
 
 	t.Run("Successful retrieval", func(t *testing.T) {
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow(1, "Tenant 1", "Description 1", time.Now(), time.Now()).
-			AddRow(2, "Tenant 2", "Description 2", time.Now(), time.Now())
+		rows := sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}).
+			AddRow(4, "Acme", "acme", "active", "", time.Now(), time.Now())
 
-		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM tenant ORDER BY name").
+		mock.ExpectQuery("SELECT id, name, slug, status, description, created_at, updated_at FROM tenant WHERE slug = \\$1 AND deleted_at IS NULL").
+			WithArgs("acme").
 			WillReturnRows(rows)
 
 		// Execute
-		tenants, err := service.ListTenants(ctx)
+		tenant, err := service.GetTenantBySlug(ctx, "ACME")
 
 		// Assert
 		assert.NoError(t, err)
-		assert.Len(t, tenants, 2)
-		assert.Equal(t, int64(1), tenants[0].ID)
-		assert.Equal(t, "Tenant 1", tenants[0].Name)
-		assert.Equal(t, int64(2), tenants[1].ID)
-		assert.Equal(t, "Tenant 2", tenants[1].Name)
+		assert.Equal(t, int64(4), tenant.ID)
+		assert.Equal(t, "acme", tenant.Slug)
 	})
 
-	t.Run("Empty result", func(t *testing.T) {
+	t.Run("Tenant not found", func(t *testing.T) {
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"})
+		mock.ExpectQuery("SELECT id, name, slug, status, description, created_at, updated_at FROM tenant WHERE slug = \\$1 AND deleted_at IS NULL").
+			WithArgs("missing").
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		tenant, err := service.GetTenantBySlug(ctx, "missing")
+
+		// Assert
+		assert.Nil(t, tenant)
+		assert.Equal(t, ErrTenantNotFound, err)
+	})
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "acme-corp", Slugify("Acme Corp."))
+	assert.Equal(t, "o-brien-sons", Slugify("  O'Brien & Sons "))
+	assert.Len(t, Slugify(strings.Repeat("a", 100)), MaxSlugLength)
+
+	assert.NoError(t, ValidateSlug("acme-corp"))
+	assert.True(t, errors.Is(ValidateSlug(""), ErrInvalidInput))
+	assert.True(t, errors.Is(ValidateSlug("-acme"), ErrInvalidInput))
+	assert.True(t, errors.Is(ValidateSlug("Acme"), ErrInvalidInput))
+	assert.True(t, errors.Is(ValidateSlug("www"), ErrInvalidInput))
+}
+
+func TestListTenants(t *testing.T) {
+	db, mock, service := setupMockDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	columns := []string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant WHERE deleted_at IS NULL").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "Tenant 1", "tenant-1", "active", "Description 1", time.Now(), time.Now()).
+			AddRow(2, "Tenant 2", "tenant-2", "active", "Description 2", time.Now(), time.Now())
 
-		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM tenant ORDER BY name").
+		mock.ExpectQuery("SELECT id, name, slug, status, description, created_at, updated_at FROM tenant WHERE deleted_at IS NULL ORDER BY name, id LIMIT \\$1 OFFSET \\$2").
+			WithArgs(DefaultTenantListLimit, 0).
 			WillReturnRows(rows)
 
 		// Execute
-		tenants, err := service.ListTenants(ctx)
+		list, err := service.ListTenants(ctx, TenantFilter{})
 
 		// Assert
 		assert.NoError(t, err)
-		assert.Empty(t, tenants)
+		assert.Equal(t, 2, list.Total)
+		assert.Equal(t, DefaultTenantListLimit, list.Limit)
+		assert.Len(t, list.Tenants, 2)
+		assert.Equal(t, int64(1), list.Tenants[0].ID)
+		assert.Equal(t, "Tenant 1", list.Tenants[0].Name)
+		assert.Equal(t, int64(2), list.Tenants[1].ID)
+		assert.Equal(t, "Tenant 2", list.Tenants[1].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Filtered page", func(t *testing.T) {
+		// Setup
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 1, 0)
+		where := "WHERE deleted_at IS NULL AND status = \\$1 AND \\(name ILIKE \\$2 OR slug ILIKE \\$2\\) AND created_at >= \\$3 AND created_at < \\$4"
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant "+where).
+			WithArgs(TenantStatusSuspended, "%50\\%\\_off%", from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(30))
+
+		mock.ExpectQuery("FROM tenant "+where+" ORDER BY name, id LIMIT \\$5 OFFSET \\$6").
+			WithArgs(TenantStatusSuspended, "%50\\%\\_off%", from, to, MaxTenantListLimit, 20).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, "50%_off", "off", "suspended", "", time.Now(), time.Now()))
+
+		// Execute
+		list, err := service.ListTenants(ctx, TenantFilter{
+			Search:      " 50%_off ",
+			Status:      TenantStatusSuspended,
+			CreatedFrom: &from,
+			CreatedTo:   &to,
+			Limit:       1000,
+			Offset:      20,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 30, list.Total)
+		assert.Equal(t, MaxTenantListLimit, list.Limit)
+		assert.Len(t, list.Tenants, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Deleted tenants", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant WHERE deleted_at IS NOT NULL").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		// Execute
+		list, err := service.ListTenants(ctx, TenantFilter{Status: TenantStatusPendingDeletion})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 0, list.Total)
+		assert.Empty(t, list.Tenants)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		// Execute
+		_, statusErr := service.ListTenants(ctx, TenantFilter{Status: "closed"})
+		_, offsetErr := service.ListTenants(ctx, TenantFilter{Offset: -1})
+
+		// Assert
+		assert.True(t, errors.Is(statusErr, ErrInvalidInput))
+		assert.True(t, errors.Is(offsetErr, ErrInvalidInput))
 	})
 
 	t.Run("Database error", func(t *testing.T) {
 		// Setup mock expectations
 		dbErr := errors.New("database error")
-		mock.ExpectQuery("SELECT id, name, description, created_at, updated_at FROM tenant ORDER BY name").
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant WHERE deleted_at IS NULL").
 			WillReturnError(dbErr)
 
 		// Execute
-		tenants, err := service.ListTenants(ctx)
+		list, err := service.ListTenants(ctx, TenantFilter{})
 
 		// Assert
 		assert.Error(t, err)
-		assert.Nil(t, tenants)
+		assert.Nil(t, list)
 		assert.True(t, errors.Is(err, ErrDBOperation))
 	})
 }
@@ -152,11 +258,11 @@ func TestCreateTenant(t *testing.T) {
 		}
 
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow(1, tenant.Name, tenant.Description, now, now)
+		rows := sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}).
+			AddRow(1, tenant.Name, "new-tenant", "active", tenant.Description, now, now)
 
-		mock.ExpectQuery("INSERT INTO tenant \\(name, description\\) VALUES \\(\\$1, \\$2\\) RETURNING id, name, description, created_at, updated_at").
-			WithArgs(tenant.Name, tenant.Description).
+		mock.ExpectQuery("INSERT INTO tenant \\(name, slug, description\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id, name, slug, status, description, created_at, updated_at").
+			WithArgs(tenant.Name, "new-tenant", tenant.Description).
 			WillReturnRows(rows)
 
 		// Execute
@@ -170,6 +276,24 @@ func TestCreateTenant(t *testing.T) {
 		assert.Equal(t, tenant.Description, createdTenant.Description)
 	})
 
+	t.Run("Provisions the tenant", func(t *testing.T) {
+		provisioner := &recordingProvisioner{}
+		service.WithProvisioner(provisioner)
+		defer service.WithProvisioner(nil)
+
+		mock.ExpectQuery("INSERT INTO tenant \\(name, slug, description\\)").
+			WithArgs("Provisioned", "provisioned", "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}).
+				AddRow(7, "Provisioned", "provisioned", "active", "", now, now))
+
+		// Execute
+		_, err := service.CreateTenant(ctx, &Tenant{Name: "Provisioned"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []int64{7}, provisioner.tenantIDs)
+	})
+
 	t.Run("Invalid input - empty name", func(t *testing.T) {
 		// Setup
 		tenant := &Tenant{
@@ -194,8 +318,8 @@ func TestCreateTenant(t *testing.T) {
 
 		// Setup mock expectations
 		dbErr := errors.New("database error")
-		mock.ExpectQuery("INSERT INTO tenant \\(name, description\\) VALUES \\(\\$1, \\$2\\) RETURNING id, name, description, created_at, updated_at").
-			WithArgs(tenant.Name, tenant.Description).
+		mock.ExpectQuery("INSERT INTO tenant \\(name, slug, description\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id, name, slug, status, description, created_at, updated_at").
+			WithArgs(tenant.Name, "new-tenant", tenant.Description).
 			WillReturnError(dbErr)
 
 		// Execute
@@ -206,6 +330,109 @@ func TestCreateTenant(t *testing.T) {
 		assert.Nil(t, createdTenant)
 		assert.True(t, errors.Is(err, ErrDBOperation))
 	})
+
+	t.Run("Name or slug taken", func(t *testing.T) {
+		// Setup
+		tenant := &Tenant{Name: "Acme", Slug: "acme"}
+
+		// Setup mock expectations
+		mock.ExpectQuery("INSERT INTO tenant \\(name, slug, description\\)").
+			WithArgs("Acme", "acme", "").
+			WillReturnError(&pgconn.PgError{Code: "23505"})
+
+		// Execute
+		createdTenant, err := service.CreateTenant(ctx, tenant)
+
+		// Assert
+		assert.Nil(t, createdTenant)
+		assert.True(t, errors.Is(err, ErrTenantExists))
+	})
+}
+
+func TestCreateTenantWithOwner(t *testing.T) {
+	db, mock, service := setupMockDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	ownerID := int64(7)
+
+	t.Run("Successful creation", func(t *testing.T) {
+		// Setup
+		tenant := &Tenant{
+			Name:        "New Tenant",
+			Description: "New Description",
+		}
+
+		// Setup mock expectations
+		rows := sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}).
+			AddRow(1, tenant.Name, "new-tenant", "active", tenant.Description, now, now)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO tenant \\(name, slug, description\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id, name, slug, status, description, created_at, updated_at").
+			WithArgs(tenant.Name, "new-tenant", tenant.Description).
+			WillReturnRows(rows)
+		mock.ExpectExec("INSERT INTO tenant_member \\(user_id, tenant_id\\) VALUES \\(\\$1, \\$2\\)").
+			WithArgs(ownerID, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id FROM role WHERE name = \\$1").
+			WithArgs("TENANT_SUPER").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec("INSERT INTO tenant_role \\(user_id, tenant_id, role_id\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+			WithArgs(ownerID, int64(1), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO role_audit").
+			WithArgs(ownerID, int64(3), "TENANT_SUPER", int64(1)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		createdTenant, err := service.CreateTenantWithOwner(ctx, tenant, ownerID)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotNil(t, createdTenant)
+		assert.Equal(t, int64(1), createdTenant.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid input - empty name", func(t *testing.T) {
+		// Execute
+		createdTenant, err := service.CreateTenantWithOwner(ctx, &Tenant{}, ownerID)
+
+		// Assert
+		assert.Nil(t, createdTenant)
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("Missing TENANT_SUPER role rolls back", func(t *testing.T) {
+		// Setup
+		tenant := &Tenant{Name: "New Tenant"}
+
+		// Setup mock expectations
+		rows := sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}).
+			AddRow(2, tenant.Name, "new-tenant", "active", "", now, now)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO tenant").
+			WithArgs(tenant.Name, "new-tenant", "").
+			WillReturnRows(rows)
+		mock.ExpectExec("INSERT INTO tenant_member").
+			WithArgs(ownerID, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id FROM role WHERE name = \\$1").
+			WithArgs("TENANT_SUPER").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		// Execute
+		createdTenant, err := service.CreateTenantWithOwner(ctx, tenant, ownerID)
+
+		// Assert
+		assert.Nil(t, createdTenant)
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestUpdateTenant(t *testing.T) {
@@ -223,7 +450,7 @@ func TestUpdateTenant(t *testing.T) {
 		}
 
 		// Setup mock expectations
-		mock.ExpectExec("UPDATE tenant SET name = \\$1, description = \\$2, updated_at = NOW\\(\\) WHERE id = \\$3").
+		mock.ExpectExec("UPDATE tenant SET name = \\$1, description = \\$2, updated_at = NOW\\(\\) WHERE id = \\$3 AND deleted_at IS NULL").
 			WithArgs(tenant.Name, tenant.Description, tenant.ID).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -273,7 +500,7 @@ func TestUpdateTenant(t *testing.T) {
 		}
 
 		// Setup mock expectations
-		mock.ExpectExec("UPDATE tenant SET name = \\$1, description = \\$2, updated_at = NOW\\(\\) WHERE id = \\$3").
+		mock.ExpectExec("UPDATE tenant SET name = \\$1, description = \\$2, updated_at = NOW\\(\\) WHERE id = \\$3 AND deleted_at IS NULL").
 			WithArgs(tenant.Name, tenant.Description, tenant.ID).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -286,26 +513,72 @@ func TestUpdateTenant(t *testing.T) {
 	})
 }
 
-func TestDeleteTenant(t *testing.T) {
+func TestSuspendAndResumeTenant(t *testing.T) {
 	db, mock, service := setupMockDB(t)
 	defer db.Close()
 
 	ctx := context.Background()
 	tenantID := int64(1)
 
-	t.Run("Successful deletion", func(t *testing.T) {
+	t.Run("Suspend active tenant", func(t *testing.T) {
 		// Setup mock expectations
-		mock.ExpectBegin()
-		mock.ExpectExec("DELETE FROM tenant_member WHERE tenant_id = \\$1").
-			WithArgs(tenantID).
-			WillReturnResult(sqlmock.NewResult(0, 2))
-		mock.ExpectExec("DELETE FROM tenant_role WHERE tenant_id = \\$1").
-			WithArgs(tenantID).
+		mock.ExpectExec("UPDATE tenant SET status = \\$1, updated_at = NOW\\(\\) WHERE id = \\$2 AND status = \\$3").
+			WithArgs(TenantStatusSuspended, tenantID, TenantStatusActive).
 			WillReturnResult(sqlmock.NewResult(0, 1))
-		mock.ExpectExec("DELETE FROM tenant WHERE id = \\$1").
+
+		// Execute
+		err := service.SuspendTenant(ctx, tenantID)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Resume tenant that is not suspended", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("UPDATE tenant SET status = \\$1, updated_at = NOW\\(\\) WHERE id = \\$2 AND status = \\$3").
+			WithArgs(TenantStatusActive, tenantID, TenantStatusSuspended).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT status FROM tenant WHERE id = \\$1").
 			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+
+		// Execute
+		err := service.ResumeTenant(ctx, tenantID)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidTenantStatus))
+	})
+
+	t.Run("Tenant not found", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("UPDATE tenant SET status").
+			WithArgs(TenantStatusSuspended, int64(999), TenantStatusActive).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT status FROM tenant WHERE id = \\$1").
+			WithArgs(int64(999)).
+			WillReturnError(sql.ErrNoRows)
+
+		// Execute
+		err := service.SuspendTenant(ctx, 999)
+
+		// Assert
+		assert.Equal(t, ErrTenantNotFound, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteTenant(t *testing.T) {
+	db, mock, service := setupMockDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	tenantID := int64(1)
+
+	t.Run("Successful soft deletion", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("UPDATE tenant SET status = \\$1, deleted_at = NOW\\(\\), updated_at = NOW\\(\\) WHERE id = \\$2 AND deleted_at IS NULL").
+			WithArgs(TenantStatusPendingDeletion, tenantID).
 			WillReturnResult(sqlmock.NewResult(0, 1))
-		mock.ExpectCommit()
 
 		// Execute
 		err := service.DeleteTenant(ctx, tenantID)
@@ -316,17 +589,9 @@ func TestDeleteTenant(t *testing.T) {
 
 	t.Run("Tenant not found", func(t *testing.T) {
 		// Setup mock expectations
-		mock.ExpectBegin()
-		mock.ExpectExec("DELETE FROM tenant_member WHERE tenant_id = \\$1").
-			WithArgs(tenantID).
-			WillReturnResult(sqlmock.NewResult(0, 0))
-		mock.ExpectExec("DELETE FROM tenant_role WHERE tenant_id = \\$1").
-			WithArgs(tenantID).
+		mock.ExpectExec("UPDATE tenant SET status = \\$1, deleted_at = NOW\\(\\), updated_at = NOW\\(\\) WHERE id = \\$2 AND deleted_at IS NULL").
+			WithArgs(TenantStatusPendingDeletion, tenantID).
 			WillReturnResult(sqlmock.NewResult(0, 0))
-		mock.ExpectExec("DELETE FROM tenant WHERE id = \\$1").
-			WithArgs(tenantID).
-			WillReturnResult(sqlmock.NewResult(0, 0))
-		mock.ExpectRollback()
 
 		// Execute
 		err := service.DeleteTenant(ctx, tenantID)
@@ -337,6 +602,115 @@ func TestDeleteTenant(t *testing.T) {
 	})
 }
 
+func TestRestoreTenant(t *testing.T) {
+	db, mock, service := setupMockDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	tenantID := int64(1)
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	service.WithRetention(30 * 24 * time.Hour).now = func() time.Time { return now }
+	cutoff := now.Add(-30 * 24 * time.Hour)
+
+	t.Run("Successful restore", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("UPDATE tenant SET status = \\$1, deleted_at = NULL, updated_at = NOW\\(\\) WHERE id = \\$2 AND deleted_at IS NOT NULL AND deleted_at > \\$3").
+			WithArgs(TenantStatusActive, tenantID, cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := service.RestoreTenant(ctx, tenantID)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Restore window expired", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectExec("UPDATE tenant SET status = \\$1, deleted_at = NULL").
+			WithArgs(TenantStatusActive, tenantID, cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT status FROM tenant WHERE id = \\$1").
+			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("pending_deletion"))
+
+		// Execute
+		err := service.RestoreTenant(ctx, tenantID)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidTenantStatus))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPurgeDeletedTenants(t *testing.T) {
+	db, mock, service := setupMockDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	service.WithRetention(7 * 24 * time.Hour).now = func() time.Time { return now }
+	cutoff := now.Add(-7 * 24 * time.Hour)
+
+	// Setup mock expectations
+	mock.ExpectBegin()
+	for _, scoped := range tenantScopedTables {
+		mock.ExpectExec("DELETE FROM " + regexp.QuoteMeta(scoped.table) + " WHERE tenant_id IN \\(SELECT id FROM tenant WHERE deleted_at < \\$1\\)").
+			WithArgs(cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+	}
+	mock.ExpectExec("DELETE FROM tenant WHERE deleted_at < \\$1").
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// Execute
+	report, err := service.PurgeDeletedTenants(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), report.Tenants)
+	assert.Equal(t, int64(3), report.Rows["order"])
+	assert.Equal(t, int64(3), report.Rows["tenant_member"])
+	assert.Len(t, report.Rows, len(tenantScopedTables))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeDeletedTenantsRollsBackOnFailure(t *testing.T) {
+	db, mock, service := setupMockDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// Setup mock expectations
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM order_item").
+		WillReturnResult(sqlmock.NewResult(0, 9))
+	mock.ExpectExec("DELETE FROM order_status_history").
+		WillReturnResult(sqlmock.NewResult(0, 6))
+	mock.ExpectExec("DELETE FROM order_attachment").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM order_comment").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM order_tag").
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec("DELETE FROM \"order\"").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("DELETE FROM order_archive").
+		WillReturnResult(sqlmock.NewResult(0, 7))
+	mock.ExpectExec("DELETE FROM tenant_invitation").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	// Execute
+	report, err := service.PurgeDeletedTenants(ctx)
+
+	// Assert
+	assert.Nil(t, report)
+	assert.True(t, errors.Is(err, ErrDBOperation))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetTenantMembers(t *testing.T) {
 	db, mock, service := setupMockDB(t)
 	defer db.Close()
@@ -344,42 +718,75 @@ func TestGetTenantMembers(t *testing.T) {
 	ctx := context.Background()
 	tenantID := int64(1)
 	now := time.Now()
+	columns := []string{"user_id", "tenant_id", "email", "first_name", "last_name", "roles", "created_at"}
 
 	t.Run("Successful retrieval", func(t *testing.T) {
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"user_id", "tenant_id", "created_at"}).
-			AddRow(1, tenantID, now).
-			AddRow(2, tenantID, now)
-
-		mock.ExpectQuery("SELECT user_id, tenant_id, created_at FROM tenant_member WHERE tenant_id = \\$1").
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tenant_member tm JOIN usr u ON u.user_id = tm.user_id WHERE tm.tenant_id = \\$1").
 			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, tenantID, "ada@example.com", "Ada", "Lovelace", "{TENANT_MEMBER,TENANT_SUPER}", now).
+			AddRow(2, tenantID, "alan@example.com", "Alan", "Turing", "{}", now)
+
+		mock.ExpectQuery("SELECT tm.user_id, tm.tenant_id, u.email, u.first_name, u.last_name, .*array_agg\\(r.name ORDER BY r.name\\).* FROM tenant_member tm JOIN usr u ON u.user_id = tm.user_id LEFT JOIN tenant_role tr .* WHERE tm.tenant_id = \\$1 GROUP BY .* LIMIT \\$2 OFFSET \\$3").
+			WithArgs(tenantID, DefaultMemberListLimit, 0).
 			WillReturnRows(rows)
 
 		// Execute
-		members, err := service.GetTenantMembers(ctx, tenantID)
+		list, err := service.GetTenantMembers(ctx, TenantMemberFilter{TenantID: tenantID})
 
 		// Assert
 		assert.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+		members := list.Members
 		assert.Len(t, members, 2)
 		assert.Equal(t, int64(1), members[0].UserID)
 		assert.Equal(t, tenantID, members[0].TenantID)
+		assert.Equal(t, "ada@example.com", members[0].Email)
+		assert.Equal(t, "Lovelace", members[0].LastName)
+		assert.Equal(t, []string{"TENANT_MEMBER", "TENANT_SUPER"}, members[0].Roles)
 		assert.Equal(t, int64(2), members[1].UserID)
+		assert.Empty(t, members[1].Roles)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("Empty result", func(t *testing.T) {
+	t.Run("Search by email", func(t *testing.T) {
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"user_id", "tenant_id", "created_at"})
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) .* WHERE tm.tenant_id = \\$1 AND u.email ILIKE \\$2").
+			WithArgs(tenantID, "%ada%").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		mock.ExpectQuery("WHERE tm.tenant_id = \\$1 AND u.email ILIKE \\$2 GROUP BY .* LIMIT \\$3 OFFSET \\$4").
+			WithArgs(tenantID, "%ada%", 2, 2).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, tenantID, "adam@example.com", "Adam", "Smith", "{TENANT_MEMBER}", now))
+
+		// Execute
+		list, err := service.GetTenantMembers(ctx, TenantMemberFilter{TenantID: tenantID, Email: "ada", Limit: 2, Offset: 2})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 3, list.Total)
+		assert.Len(t, list.Members, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 
-		mock.ExpectQuery("SELECT user_id, tenant_id, created_at FROM tenant_member WHERE tenant_id = \\$1").
+	t.Run("Offset past the end", func(t *testing.T) {
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\)").
 			WithArgs(tenantID).
-			WillReturnRows(rows)
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 
 		// Execute
-		members, err := service.GetTenantMembers(ctx, tenantID)
+		list, err := service.GetTenantMembers(ctx, TenantMemberFilter{TenantID: tenantID, Offset: 50})
 
 		// Assert
 		assert.NoError(t, err)
-		assert.Empty(t, members)
+		assert.Equal(t, 2, list.Total)
+		assert.Empty(t, list.Members)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -474,11 +881,11 @@ func TestGetUserTenants(t *testing.T) {
 
 	t.Run("Successful retrieval", func(t *testing.T) {
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow(1, "Tenant 1", "Description 1", now, now).
-			AddRow(2, "Tenant 2", "Description 2", now, now)
+		rows := sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"}).
+			AddRow(1, "Tenant 1", "tenant-1", "active", "Description 1", now, now).
+			AddRow(2, "Tenant 2", "tenant-2", "active", "Description 2", now, now)
 
-		mock.ExpectQuery("SELECT t.id, t.name, t.description, t.created_at, t.updated_at FROM tenant t JOIN tenant_member tm ON t.id = tm.tenant_id WHERE tm.user_id = \\$1 ORDER BY t.name").
+		mock.ExpectQuery("SELECT t.id, t.name, t.slug, t.status, t.description, t.created_at, t.updated_at FROM tenant t JOIN tenant_member tm ON t.id = tm.tenant_id WHERE tm.user_id = \\$1 AND t.deleted_at IS NULL ORDER BY t.name").
 			WithArgs(userID).
 			WillReturnRows(rows)
 
@@ -496,9 +903,9 @@ func TestGetUserTenants(t *testing.T) {
 
 	t.Run("No tenants", func(t *testing.T) {
 		// Setup mock expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"})
+		rows := sqlmock.NewRows([]string{"id", "name", "slug", "status", "description", "created_at", "updated_at"})
 
-		mock.ExpectQuery("SELECT t.id, t.name, t.description, t.created_at, t.updated_at FROM tenant t JOIN tenant_member tm ON t.id = tm.tenant_id WHERE tm.user_id = \\$1 ORDER BY t.name").
+		mock.ExpectQuery("SELECT t.id, t.name, t.slug, t.status, t.description, t.created_at, t.updated_at FROM tenant t JOIN tenant_member tm ON t.id = tm.tenant_id WHERE tm.user_id = \\$1 AND t.deleted_at IS NULL ORDER BY t.name").
 			WithArgs(userID).
 			WillReturnRows(rows)
 
@@ -510,3 +917,13 @@ func TestGetUserTenants(t *testing.T) {
 		assert.Empty(t, tenants)
 	})
 }
+
+// recordingProvisioner records the tenants it provisions
+type recordingProvisioner struct {
+	tenantIDs []int64
+}
+
+func (p *recordingProvisioner) ProvisionTenant(ctx context.Context, tenantID int64) error {
+	p.tenantIDs = append(p.tenantIDs, tenantID)
+	return nil
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken returns the stored hash of a secret handed out once, such as an
+// invitation token, an ownership transfer token or an API key. Only the hash is
+// stored, and secrets are looked up by it.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// usageDateLayout is the format of usage dates
+	usageDateLayout = "2006-01-02"
+
+	// DefaultUsageFlushInterval is how often recorded usage is written to the database
+	DefaultUsageFlushInterval = time.Minute
+)
+
+// DailyUsage holds a tenant's usage counters for one day (UTC)
+type DailyUsage struct {
+	Date          string `json:"date"`
+	APIRequests   int64  `json:"api_requests"`
+	OrdersCreated int64  `json:"orders_created"`
+	ActiveUsers   int64  `json:"active_users"`
+}
+
+// UsageReport summarizes a tenant's usage over a date range
+type UsageReport struct {
+	TenantID int64        `json:"tenant_id"`
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Days     []DailyUsage `json:"days"`
+
+	// Totals over the range. ActiveUsers counts distinct users, not the sum of daily counts.
+	APIRequests   int64 `json:"api_requests"`
+	OrdersCreated int64 `json:"orders_created"`
+	ActiveUsers   int64 `json:"active_users"`
+}
+
+// UsageService defines the interface for tenant usage metering
+type UsageService interface {
+	// RecordAPIRequest counts an API request made by a user in a tenant
+	RecordAPIRequest(tenantID int64, userID int64)
+
+	// RecordOrderCreated counts an order created in a tenant
+	RecordOrderCreated(tenantID int64)
+
+	// Flush writes recorded counters to the daily usage table
+	Flush(ctx context.Context) error
+
+	// GetUsageReport retrieves a tenant's daily usage between two dates, inclusive
+	GetUsageReport(ctx context.Context, tenantID int64, from, to time.Time) (*UsageReport, error)
+}
+
+// usageKey identifies a tenant's counters for one day
+type usageKey struct {
+	tenantID int64
+	date     string
+}
+
+// usageCounts holds counters recorded since the last flush
+type usageCounts struct {
+	apiRequests   int64
+	ordersCreated int64
+	users         map[int64]struct{}
+}
+
+// DBUsageService implements UsageService using a database. Counters are buffered
+// in memory and written to the database by Flush, so recording is cheap enough to
+// run on every request.
+type DBUsageService struct {
+	db  *sql.DB
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[usageKey]*usageCounts
+}
+
+// NewDBUsageService creates a new DBUsageService
+func NewDBUsageService(db *sql.DB) *DBUsageService {
+	return &DBUsageService{
+		db:      db,
+		now:     time.Now,
+		pending: make(map[usageKey]*usageCounts),
+	}
+}
+
+// RecordAPIRequest counts an API request made by a user in a tenant
+func (s *DBUsageService) RecordAPIRequest(tenantID int64, userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := s.countsFor(tenantID)
+	counts.apiRequests++
+	counts.users[userID] = struct{}{}
+}
+
+// RecordOrderCreated counts an order created in a tenant
+func (s *DBUsageService) RecordOrderCreated(tenantID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.countsFor(tenantID).ordersCreated++
+}
+
+// countsFor returns today's pending counters for a tenant. The caller must hold s.mu.
+func (s *DBUsageService) countsFor(tenantID int64) *usageCounts {
+	key := usageKey{tenantID: tenantID, date: s.now().UTC().Format(usageDateLayout)}
+
+	counts, ok := s.pending[key]
+	if !ok {
+		counts = &usageCounts{users: make(map[int64]struct{})}
+		s.pending[key] = counts
+	}
+	return counts
+}
+
+// Flush writes recorded counters to the daily usage table. Counters that fail to
+// be written are kept for the next flush.
+func (s *DBUsageService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]*usageCounts)
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := s.write(ctx, pending); err != nil {
+		s.restore(pending)
+		return err
+	}
+
+	log.Printf("[DEBUG] Flushed usage counters for %d tenant days", len(pending))
+	return nil
+}
+
+// write stores counters in a single transaction
+func (s *DBUsageService) write(ctx context.Context, pending map[usageKey]*usageCounts) error {
+	// Start a transaction to ensure atomicity
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer tx.Rollback()
+
+	for key, counts := range pending {
+		// Record the distinct users active on the day
+		for userID := range counts.users {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO tenant_usage_user (tenant_id, usage_date, user_id)
+				VALUES ($1, $2, $3)
+				ON CONFLICT DO NOTHING
+			`, key.tenantID, key.date, userID)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrDBOperation, err)
+			}
+		}
+
+		// Add the counters to the day's totals
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO tenant_usage (tenant_id, usage_date, api_requests, orders_created, active_users)
+			VALUES ($1, $2, $3, $4, (SELECT COUNT(*) FROM tenant_usage_user WHERE tenant_id = $1 AND usage_date = $2))
+			ON CONFLICT (tenant_id, usage_date) DO UPDATE
+			SET api_requests = tenant_usage.api_requests + EXCLUDED.api_requests,
+				orders_created = tenant_usage.orders_created + EXCLUDED.orders_created,
+				active_users = EXCLUDED.active_users
+		`, key.tenantID, key.date, counts.apiRequests, counts.ordersCreated)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return nil
+}
+
+// restore merges counters that failed to flush back into the pending counters
+func (s *DBUsageService) restore(failed map[usageKey]*usageCounts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, counts := range failed {
+		current, ok := s.pending[key]
+		if !ok {
+			s.pending[key] = counts
+			continue
+		}
+		current.apiRequests += counts.apiRequests
+		current.ordersCreated += counts.ordersCreated
+		for userID := range counts.users {
+			current.users[userID] = struct{}{}
+		}
+	}
+}
+
+// GetUsageReport retrieves a tenant's daily usage between two dates, inclusive
+func (s *DBUsageService) GetUsageReport(ctx context.Context, tenantID int64, from, to time.Time) (*UsageReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: end date is before start date", ErrInvalidInput)
+	}
+
+	report := &UsageReport{
+		TenantID: tenantID,
+		From:     from.UTC().Format(usageDateLayout),
+		To:       to.UTC().Format(usageDateLayout),
+		Days:     []DailyUsage{},
+	}
+
+	query := `
+		SELECT usage_date, api_requests, orders_created, active_users
+		FROM tenant_usage
+		WHERE tenant_id = $1 AND usage_date BETWEEN $2 AND $3
+		ORDER BY usage_date
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, tenantID, report.From, report.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day DailyUsage
+		var date time.Time
+		if err := rows.Scan(&date, &day.APIRequests, &day.OrdersCreated, &day.ActiveUsers); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		day.Date = date.Format(usageDateLayout)
+
+		report.Days = append(report.Days, day)
+		report.APIRequests += day.APIRequests
+		report.OrdersCreated += day.OrdersCreated
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT user_id)
+		FROM tenant_usage_user
+		WHERE tenant_id = $1 AND usage_date BETWEEN $2 AND $3
+	`, tenantID, report.From, report.To).Scan(&report.ActiveUsers)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return report, nil
+}
+
+// StartUsageFlushJob flushes recorded usage every interval until ctx is cancelled.
+// Callers should Flush once more on shutdown so the last counters aren't lost.
+func StartUsageFlushJob(ctx context.Context, usageService UsageService, interval time.Duration) {
+	log.Printf("[INFO] Starting usage flush job every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("[INFO] Stopping usage flush job")
+				return
+			case <-ticker.C:
+				if err := usageService.Flush(ctx); err != nil {
+					log.Printf("[ERROR] Failed to flush usage: %v", err)
+				}
+			}
+		}
+	}()
+}
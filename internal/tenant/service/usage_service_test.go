@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageFlush(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBUsageService(db)
+	service.now = func() time.Time { return time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	t.Run("Nothing to flush", func(t *testing.T) {
+		// Execute
+		err := service.Flush(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed flush is retried", func(t *testing.T) {
+		// Setup
+		service.RecordAPIRequest(1, 7)
+		service.RecordAPIRequest(1, 7)
+		service.RecordOrderCreated(1)
+
+		// Setup mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tenant_usage_user").
+			WithArgs(int64(1), "2025-03-31", int64(7)).
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		// Execute
+		err := service.Flush(ctx)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrDBOperation))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Successful flush", func(t *testing.T) {
+		// Setup
+		service.RecordAPIRequest(1, 7)
+
+		// Setup mock expectations, including the counters kept from the failed flush
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tenant_usage_user \\(tenant_id, usage_date, user_id\\) VALUES \\(\\$1, \\$2, \\$3\\) ON CONFLICT DO NOTHING").
+			WithArgs(int64(1), "2025-03-31", int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO tenant_usage \\(tenant_id, usage_date, api_requests, orders_created, active_users\\)").
+			WithArgs(int64(1), "2025-03-31", int64(3), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		err := service.Flush(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Empty(t, service.pending)
+	})
+}
+
+func TestGetUsageReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBUsageService(db)
+	ctx := context.Background()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Successful report", func(t *testing.T) {
+		// Setup mock expectations
+		rows := sqlmock.NewRows([]string{"usage_date", "api_requests", "orders_created", "active_users"}).
+			AddRow(time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), 100, 4, 3).
+			AddRow(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), 50, 1, 2)
+		mock.ExpectQuery("SELECT usage_date, api_requests, orders_created, active_users FROM tenant_usage WHERE tenant_id = \\$1 AND usage_date BETWEEN \\$2 AND \\$3").
+			WithArgs(int64(1), "2025-03-01", "2025-03-31").
+			WillReturnRows(rows)
+		mock.ExpectQuery("SELECT COUNT\\(DISTINCT user_id\\) FROM tenant_usage_user").
+			WithArgs(int64(1), "2025-03-01", "2025-03-31").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+		// Execute
+		report, err := service.GetUsageReport(ctx, 1, from, to)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, report.Days, 2)
+		assert.Equal(t, "2025-03-02", report.Days[0].Date)
+		assert.Equal(t, int64(150), report.APIRequests)
+		assert.Equal(t, int64(5), report.OrdersCreated)
+		assert.Equal(t, int64(4), report.ActiveUsers)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		// Execute
+		report, err := service.GetUsageReport(ctx, 1, to, from)
+
+		// Assert
+		assert.Nil(t, report)
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
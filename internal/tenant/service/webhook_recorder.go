@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	authctx "github.com/unsavory/silocore-go/internal/auth/context"
+)
+
+// WebhookDispatchingRecorder decorates an AuditRecorder, dispatching each recorded
+// entry to the tenant's webhooks as an event of the same type. Failing to dispatch
+// an event does not fail the recording.
+type WebhookDispatchingRecorder struct {
+	auditservice.AuditRecorder
+	dispatcher WebhookDispatcher
+	now        func() time.Time
+}
+
+// Ensure WebhookDispatchingRecorder implements AuditRecorder
+var _ auditservice.AuditRecorder = (*WebhookDispatchingRecorder)(nil)
+
+// NewWebhookDispatchingRecorder creates a new WebhookDispatchingRecorder
+func NewWebhookDispatchingRecorder(recorder auditservice.AuditRecorder, dispatcher WebhookDispatcher) *WebhookDispatchingRecorder {
+	return &WebhookDispatchingRecorder{
+		AuditRecorder: recorder,
+		dispatcher:    dispatcher,
+		now:           time.Now,
+	}
+}
+
+// Record writes an audit entry and dispatches it to the tenant's webhooks
+func (r *WebhookDispatchingRecorder) Record(ctx context.Context, entry auditservice.AuditEntry) error {
+	if err := r.AuditRecorder.Record(ctx, entry); err != nil {
+		return err
+	}
+
+	if entry.ActorUserID == nil {
+		if id, err := authctx.GetUserID(ctx); err == nil {
+			entry.ActorUserID = &id
+		}
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		log.Printf("[WARN] Failed to generate webhook event ID for %s in tenant %d: %v", entry.Action, entry.TenantID, err)
+		return nil
+	}
+
+	event := WebhookEvent{
+		ID:        "evt_" + hex.EncodeToString(idBytes),
+		Type:      entry.Action,
+		TenantID:  entry.TenantID,
+		CreatedAt: r.now().UTC(),
+		Data: map[string]interface{}{
+			"resource_type": entry.ResourceType,
+			"resource_id":   entry.ResourceID,
+			"actor_user_id": entry.ActorUserID,
+			"details":       entry.Details,
+		},
+	}
+
+	if err := r.dispatcher.Dispatch(ctx, event); err != nil {
+		log.Printf("[WARN] Failed to dispatch %s webhook event for tenant %d: %v", entry.Action, entry.TenantID, err)
+	}
+	return nil
+}
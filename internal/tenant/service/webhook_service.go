@@ -0,0 +1,541 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	"github.com/unsavory/silocore-go/internal/database"
+	"github.com/unsavory/silocore-go/internal/database/transaction"
+)
+
+// Webhook errors
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// Webhook delivery headers
+const (
+	WebhookSignatureHeader = "X-SiloCore-Signature"
+	WebhookEventHeader     = "X-SiloCore-Event"
+	WebhookDeliveryHeader  = "X-SiloCore-Delivery"
+)
+
+const (
+	// webhookSecretPrefix starts every generated webhook secret
+	webhookSecretPrefix = "whsec_"
+
+	// webhookSecretSize is the number of random bytes in a generated webhook secret
+	webhookSecretSize = 24
+
+	// webhookTimeout is how long a single delivery attempt can take
+	webhookTimeout = 10 * time.Second
+
+	// webhookMaxAttempts is how many times a delivery is attempted before giving up
+	webhookMaxAttempts = 3
+
+	// webhookRetryDelay is the wait before the first retry, doubled for each later retry
+	webhookRetryDelay = 2 * time.Second
+
+	// webhookDeliveryLogSize is how many delivery attempts are kept per webhook
+	webhookDeliveryLogSize = 100
+)
+
+// WebhookEventTypes are the events webhooks can subscribe to. They match the audit
+// log actions that trigger them. "*" subscribes to every event.
+var WebhookEventTypes = []string{
+	auditservice.ActionMemberAdded,
+	auditservice.ActionMemberRemoved,
+	auditservice.ActionRoleAssigned,
+	auditservice.ActionRoleRevoked,
+	auditservice.ActionOrderCreated,
+	auditservice.ActionOrderUpdated,
+	auditservice.ActionOrderStatusChanged,
+	auditservice.ActionOrderDeleted,
+	auditservice.ActionOrderRestored,
+	auditservice.ActionOrderAssigned,
+	auditservice.ActionOrderUnassigned,
+	auditservice.ActionOwnershipTransferRequested,
+	auditservice.ActionOwnershipTransferred,
+}
+
+// Webhook is an endpoint that receives a tenant's events
+type Webhook struct {
+	ID       int64  `json:"id"`
+	TenantID int64  `json:"tenant_id"`
+	URL      string `json:"url"`
+	// Secret signs deliveries. It is only returned when the webhook is created.
+	Secret     string   `json:"-"`
+	EventTypes []string `json:"event_types"`
+	Enabled    bool     `json:"enabled"`
+	// LastDeliveryStatus is the HTTP status of the latest delivery, or 0 if the
+	// endpoint couldn't be reached
+	LastDeliveryAt     *time.Time `json:"last_delivery_at,omitempty"`
+	LastDeliveryStatus *int       `json:"last_delivery_status,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// WebhookEvent is the payload posted to webhooks
+type WebhookEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	TenantID  int64                  `json:"tenant_id"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// WebhookDelivery is an attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID        int64  `json:"id"`
+	WebhookID int64  `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	Attempt   int    `json:"attempt"`
+	// ResponseStatus is the HTTP status of the response, or 0 if the endpoint
+	// couldn't be reached
+	ResponseStatus int             `json:"response_status"`
+	Error          string          `json:"error,omitempty"`
+	DurationMs     int64           `json:"duration_ms"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookDispatcher delivers events to the webhooks subscribed to them
+type WebhookDispatcher interface {
+	// Dispatch queues an event for delivery to the tenant's enabled webhooks
+	// subscribed to its type. Deliveries happen in the background.
+	Dispatch(ctx context.Context, event WebhookEvent) error
+}
+
+// WebhookService defines the interface for tenant webhook operations
+type WebhookService interface {
+	WebhookDispatcher
+
+	// ListWebhooks retrieves a tenant's webhooks
+	ListWebhooks(ctx context.Context, tenantID int64) ([]Webhook, error)
+
+	// GetWebhook retrieves a tenant's webhook
+	GetWebhook(ctx context.Context, tenantID int64, webhookID int64) (*Webhook, error)
+
+	// CreateWebhook creates a webhook. A secret is generated if none is given.
+	CreateWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error)
+
+	// UpdateWebhook updates a webhook's URL, event types and enabled flag
+	UpdateWebhook(ctx context.Context, webhook *Webhook) error
+
+	// DeleteWebhook deletes a tenant's webhook
+	DeleteWebhook(ctx context.Context, tenantID int64, webhookID int64) error
+
+	// ListWebhookDeliveries retrieves the latest delivery attempts of a tenant's
+	// webhook, newest first
+	ListWebhookDeliveries(ctx context.Context, tenantID int64, webhookID int64) ([]WebhookDelivery, error)
+}
+
+// DBWebhookService implements WebhookService using a database
+type DBWebhookService struct {
+	db     *sql.DB
+	client *http.Client
+	now    func() time.Time
+
+	// deliver sends an event to a webhook in the background
+	deliver func(webhook Webhook, event WebhookEvent, payload []byte)
+}
+
+// Ensure DBWebhookService implements WebhookService
+var _ WebhookService = (*DBWebhookService)(nil)
+
+// NewDBWebhookService creates a new DBWebhookService
+func NewDBWebhookService(db *sql.DB) *DBWebhookService {
+	s := &DBWebhookService{
+		db:     db,
+		client: &http.Client{Timeout: webhookTimeout},
+		now:    time.Now,
+	}
+	s.deliver = func(webhook Webhook, event WebhookEvent, payload []byte) {
+		go s.deliverWithRetries(context.Background(), webhook, event, payload, webhookRetryDelay)
+	}
+	return s
+}
+
+// SignWebhookPayload returns the signature header for a payload sent at timestamp.
+// The header has the form "t=<timestamp>,v1=<signature>", where the signature is the
+// hex HMAC-SHA256 of "<timestamp>.<payload>" keyed with the webhook secret.
+func SignWebhookPayload(payload []byte, secret string, timestamp time.Time) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ListWebhooks retrieves a tenant's webhooks
+func (s *DBWebhookService) ListWebhooks(ctx context.Context, tenantID int64) ([]Webhook, error) {
+	query := `
+		SELECT id, tenant_id, url, secret, event_types, enabled, last_delivery_at, last_delivery_status, created_at, updated_at
+		FROM tenant_webhook
+		WHERE tenant_id = $1
+		ORDER BY id
+	`
+
+	return s.queryWebhooks(ctx, transaction.QuerierFor(ctx, s.db), query, tenantID)
+}
+
+// GetWebhook retrieves a tenant's webhook
+func (s *DBWebhookService) GetWebhook(ctx context.Context, tenantID int64, webhookID int64) (*Webhook, error) {
+	query := `
+		SELECT id, tenant_id, url, secret, event_types, enabled, last_delivery_at, last_delivery_status, created_at, updated_at
+		FROM tenant_webhook
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	webhook, err := scanWebhook(transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, webhookID, tenantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return webhook, nil
+}
+
+// CreateWebhook creates a webhook. A secret is generated if none is given.
+func (s *DBWebhookService) CreateWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	if webhook.Secret == "" {
+		secretBytes := make([]byte, webhookSecretSize)
+		if _, err := rand.Read(secretBytes); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		webhook.Secret = webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(secretBytes)
+	}
+
+	query := `
+		INSERT INTO tenant_webhook (tenant_id, url, secret, event_types, enabled)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := transaction.QuerierFor(ctx, s.db).QueryRowContext(ctx, query, webhook.TenantID, webhook.URL, webhook.Secret, database.Array(webhook.EventTypes), webhook.Enabled).
+		Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		log.Printf("[ERROR] Database error when creating webhook for tenant %d: %v", webhook.TenantID, err)
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	log.Printf("[INFO] Webhook %d created for tenant %d", webhook.ID, webhook.TenantID)
+	return webhook, nil
+}
+
+// UpdateWebhook updates a webhook's URL, event types and enabled flag
+func (s *DBWebhookService) UpdateWebhook(ctx context.Context, webhook *Webhook) error {
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tenant_webhook
+		SET url = $1, event_types = $2, enabled = $3, updated_at = NOW()
+		WHERE id = $4 AND tenant_id = $5
+	`
+
+	result, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, query, webhook.URL, database.Array(webhook.EventTypes), webhook.Enabled, webhook.ID, webhook.TenantID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// DeleteWebhook deletes a tenant's webhook
+func (s *DBWebhookService) DeleteWebhook(ctx context.Context, tenantID int64, webhookID int64) error {
+	result, err := transaction.QuerierFor(ctx, s.db).ExecContext(ctx, "DELETE FROM tenant_webhook WHERE id = $1 AND tenant_id = $2", webhookID, tenantID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	log.Printf("[INFO] Webhook %d deleted for tenant %d", webhookID, tenantID)
+	return nil
+}
+
+// ListWebhookDeliveries retrieves the latest delivery attempts of a tenant's
+// webhook, newest first
+func (s *DBWebhookService) ListWebhookDeliveries(ctx context.Context, tenantID int64, webhookID int64) ([]WebhookDelivery, error) {
+	// Make sure the webhook belongs to the tenant
+	if _, err := s.GetWebhook(ctx, tenantID, webhookID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, webhook_id, event_id, event_type, attempt, response_status, error, duration_ms, payload, created_at
+		FROM tenant_webhook_delivery
+		WHERE webhook_id = $1 AND tenant_id = $2
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := transaction.QuerierFor(ctx, s.db).QueryContext(ctx, query, webhookID, tenantID, webhookDeliveryLogSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var delivery WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Attempt,
+			&delivery.ResponseStatus,
+			&delivery.Error,
+			&delivery.DurationMs,
+			&payload,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		delivery.Payload = json.RawMessage(payload)
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return deliveries, nil
+}
+
+// Dispatch queues an event for delivery to the tenant's subscribed webhooks
+func (s *DBWebhookService) Dispatch(ctx context.Context, event WebhookEvent) error {
+	query := `
+		SELECT id, tenant_id, url, secret, event_types, enabled, last_delivery_at, last_delivery_status, created_at, updated_at
+		FROM tenant_webhook
+		WHERE tenant_id = $1 AND enabled AND ($2 = ANY(event_types) OR '*' = ANY(event_types))
+		ORDER BY id
+	`
+
+	webhooks, err := s.queryWebhooks(ctx, s.db, query, event.TenantID, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		s.deliver(webhook, event, payload)
+	}
+
+	return nil
+}
+
+// deliverWithRetries posts an event to a webhook, retrying failed attempts with
+// exponential backoff. Every attempt is logged, and the outcome of the last one
+// is recorded on the webhook.
+func (s *DBWebhookService) deliverWithRetries(ctx context.Context, webhook Webhook, event WebhookEvent, payload []byte, retryDelay time.Duration) {
+	var status int
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		var err error
+		start := time.Now()
+		status, err = s.send(ctx, webhook, event, payload)
+		s.logDelivery(ctx, webhook, event, payload, attempt, status, err, time.Since(start))
+		if err == nil {
+			break
+		}
+
+		log.Printf("[WARN] Webhook %d delivery of event %s failed (attempt %d of %d): %v", webhook.ID, event.ID, attempt, webhookMaxAttempts, err)
+		if attempt < webhookMaxAttempts {
+			time.Sleep(retryDelay)
+			retryDelay *= 2
+		}
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE tenant_webhook SET last_delivery_at = $1, last_delivery_status = $2 WHERE id = $3",
+		s.now(), status, webhook.ID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to record delivery status of webhook %d: %v", webhook.ID, err)
+	}
+
+	// Keep only the latest attempts in the log
+	_, err = s.db.ExecContext(ctx, `
+		DELETE FROM tenant_webhook_delivery
+		WHERE webhook_id = $1 AND id < (
+			SELECT MIN(id) FROM (
+				SELECT id FROM tenant_webhook_delivery WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2
+			) latest
+		)
+	`, webhook.ID, webhookDeliveryLogSize)
+	if err != nil {
+		log.Printf("[ERROR] Failed to prune delivery log of webhook %d: %v", webhook.ID, err)
+	}
+}
+
+// logDelivery adds a delivery attempt to the webhook's delivery log
+func (s *DBWebhookService) logDelivery(ctx context.Context, webhook Webhook, event WebhookEvent, payload []byte, attempt int, status int, deliveryErr error, duration time.Duration) {
+	var message string
+	if deliveryErr != nil {
+		message = deliveryErr.Error()
+	}
+
+	query := `
+		INSERT INTO tenant_webhook_delivery (webhook_id, tenant_id, event_id, event_type, attempt, response_status, error, duration_ms, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := s.db.ExecContext(ctx, query, webhook.ID, webhook.TenantID, event.ID, event.Type, attempt, status, message, duration.Milliseconds(), payload, s.now())
+	if err != nil {
+		log.Printf("[ERROR] Failed to log delivery of event %s to webhook %d: %v", event.ID, webhook.ID, err)
+	}
+}
+
+// send makes a single signed delivery attempt. It returns the response status, or
+// 0 if the endpoint couldn't be reached, and an error unless the status is 2xx.
+func (s *DBWebhookService) send(ctx context.Context, webhook Webhook, event WebhookEvent, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookDeliveryHeader, event.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(payload, webhook.Secret, s.now()))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// queryWebhooks runs a query returning webhook rows
+func (s *DBWebhookService) queryWebhooks(ctx context.Context, q transaction.Querier, query string, args ...interface{}) ([]Webhook, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDBOperation, err)
+	}
+
+	return webhooks, nil
+}
+
+// scanWebhook scans a webhook row
+func scanWebhook(row rowScanner) (*Webhook, error) {
+	var webhook Webhook
+	var eventTypes database.StringArray
+	var lastDeliveryAt sql.NullTime
+	var lastDeliveryStatus sql.NullInt64
+	if err := row.Scan(
+		&webhook.ID,
+		&webhook.TenantID,
+		&webhook.URL,
+		&webhook.Secret,
+		&eventTypes,
+		&webhook.Enabled,
+		&lastDeliveryAt,
+		&lastDeliveryStatus,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	webhook.EventTypes = []string(eventTypes)
+	if lastDeliveryAt.Valid {
+		webhook.LastDeliveryAt = &lastDeliveryAt.Time
+	}
+	if lastDeliveryStatus.Valid {
+		status := int(lastDeliveryStatus.Int64)
+		webhook.LastDeliveryStatus = &status
+	}
+	return &webhook, nil
+}
+
+// validateWebhook checks a webhook's URL and event types
+func validateWebhook(webhook *Webhook) error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	endpoint, err := url.Parse(webhook.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("%w: a valid http or https URL is required", ErrInvalidInput)
+	}
+
+	if len(webhook.EventTypes) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrInvalidInput)
+	}
+	for _, eventType := range webhook.EventTypes {
+		if eventType != "*" && !slices.Contains(WebhookEventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidInput, eventType)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auditservice "github.com/unsavory/silocore-go/internal/audit/service"
+	"github.com/unsavory/silocore-go/internal/database"
+)
+
+var webhookColumns = []string{"id", "tenant_id", "url", "secret", "event_types", "enabled", "last_delivery_at", "last_delivery_status", "created_at", "updated_at"}
+
+func TestCreateWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Secret is generated", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBWebhookService(db)
+
+		// Setup mock expectations
+		now := time.Now()
+		mock.ExpectQuery("INSERT INTO tenant_webhook \\(tenant_id, url, secret, event_types, enabled\\)").
+			WithArgs(int64(1), "https://example.com/hooks", sqlmock.AnyArg(), database.Array([]string{"order.created"}), true).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(2, now, now))
+
+		// Execute
+		webhook, err := service.CreateWebhook(ctx, &Webhook{
+			TenantID:   1,
+			URL:        " https://example.com/hooks ",
+			EventTypes: []string{"order.created"},
+			Enabled:    true,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), webhook.ID)
+		assert.True(t, strings.HasPrefix(webhook.Secret, webhookSecretPrefix))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		service := NewDBWebhookService(nil)
+
+		// Execute
+		_, err := service.CreateWebhook(ctx, &Webhook{TenantID: 1, URL: "ftp://example.com", EventTypes: []string{"*"}})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("Unknown event type", func(t *testing.T) {
+		service := NewDBWebhookService(nil)
+
+		// Execute
+		_, err := service.CreateWebhook(ctx, &Webhook{TenantID: 1, URL: "https://example.com", EventTypes: []string{"tenant.exploded"}})
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
+
+func TestDispatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := NewDBWebhookService(db)
+	var delivered []int64
+	service.deliver = func(webhook Webhook, event WebhookEvent, payload []byte) {
+		delivered = append(delivered, webhook.ID)
+	}
+	ctx := context.Background()
+
+	// Setup mock expectations
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM tenant_webhook WHERE tenant_id = \\$1 AND enabled AND \\(\\$2 = ANY\\(event_types\\) OR '\\*' = ANY\\(event_types\\)\\)").
+		WithArgs(int64(1), "member.added").
+		WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(2, 1, "https://a.example.com", "secret", "{member.added}", true, nil, nil, now, now).
+			AddRow(3, 1, "https://b.example.com", "secret", "{*}", true, nil, nil, now, now))
+
+	// Execute
+	err = service.Dispatch(ctx, WebhookEvent{ID: "evt_1", Type: "member.added", TenantID: 1})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, delivered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliverWithRetries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := WebhookEvent{ID: "evt_1", Type: "order.created", TenantID: 1}
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	t.Run("Signed delivery", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		service := NewDBWebhookService(db)
+		service.now = func() time.Time { return now }
+
+		// Setup mock expectations
+		mock.ExpectExec("INSERT INTO tenant_webhook_delivery").
+			WithArgs(int64(2), int64(1), "evt_1", "order.created", 1, http.StatusNoContent, "", sqlmock.AnyArg(), payload, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE tenant_webhook SET last_delivery_at = \\$1, last_delivery_status = \\$2 WHERE id = \\$3").
+			WithArgs(now, http.StatusNoContent, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM tenant_webhook_delivery").
+			WithArgs(int64(2), webhookDeliveryLogSize).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute
+		service.deliverWithRetries(context.Background(), Webhook{ID: 2, TenantID: 1, URL: server.URL, Secret: "secret"}, event, payload, time.Millisecond)
+
+		// Assert
+		require.NotNil(t, received)
+		assert.Equal(t, payload, body)
+		assert.Equal(t, "order.created", received.Header.Get(WebhookEventHeader))
+		assert.Equal(t, "evt_1", received.Header.Get(WebhookDeliveryHeader))
+		assert.Equal(t, SignWebhookPayload(payload, "secret", now), received.Header.Get(WebhookSignatureHeader))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed deliveries are retried", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		service := NewDBWebhookService(db)
+		service.now = func() time.Time { return now }
+
+		// Setup mock expectations
+		for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+			mock.ExpectExec("INSERT INTO tenant_webhook_delivery").
+				WithArgs(int64(2), int64(1), "evt_1", "order.created", attempt, http.StatusServiceUnavailable, "unexpected status 503", sqlmock.AnyArg(), payload, now).
+				WillReturnResult(sqlmock.NewResult(int64(attempt), 1))
+		}
+		mock.ExpectExec("UPDATE tenant_webhook SET last_delivery_at").
+			WithArgs(now, http.StatusServiceUnavailable, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM tenant_webhook_delivery").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Execute
+		service.deliverWithRetries(context.Background(), Webhook{ID: 2, TenantID: 1, URL: server.URL, Secret: "secret"}, event, payload, time.Millisecond)
+
+		// Assert
+		assert.Equal(t, webhookMaxAttempts, attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("Latest attempts", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBWebhookService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM tenant_webhook WHERE id = \\$1 AND tenant_id = \\$2").
+			WithArgs(int64(2), int64(1)).
+			WillReturnRows(sqlmock.NewRows(webhookColumns).
+				AddRow(2, 1, "https://example.com/hooks", "whsec_1", "{*}", true, now, 503, now, now))
+		mock.ExpectQuery("SELECT (.+) FROM tenant_webhook_delivery WHERE webhook_id = \\$1 AND tenant_id = \\$2 ORDER BY id DESC LIMIT \\$3").
+			WithArgs(int64(2), int64(1), webhookDeliveryLogSize).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "event_id", "event_type", "attempt", "response_status", "error", "duration_ms", "payload", "created_at"}).
+				AddRow(8, 2, "evt_1", "order.created", 2, 503, "unexpected status 503", 120, []byte(`{"id":"evt_1"}`), now).
+				AddRow(7, 2, "evt_1", "order.created", 1, 0, "connection refused", 3, []byte(`{"id":"evt_1"}`), now))
+
+		// Execute
+		deliveries, err := service.ListWebhookDeliveries(ctx, 1, 2)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, 503, deliveries[0].ResponseStatus)
+		assert.Equal(t, "connection refused", deliveries[1].Error)
+		assert.JSONEq(t, `{"id":"evt_1"}`, string(deliveries[0].Payload))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Other tenant's webhook", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		service := NewDBWebhookService(db)
+
+		// Setup mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM tenant_webhook WHERE id = \\$1 AND tenant_id = \\$2").
+			WithArgs(int64(2), int64(1)).
+			WillReturnRows(sqlmock.NewRows(webhookColumns))
+
+		// Execute
+		deliveries, err := service.ListWebhookDeliveries(ctx, 1, 2)
+
+		// Assert
+		assert.Nil(t, deliveries)
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+
+	signature := SignWebhookPayload([]byte(`{"id":"evt_1"}`), "secret", timestamp)
+
+	assert.True(t, strings.HasPrefix(signature, "t=1700000000,v1="))
+	assert.NotEqual(t, signature, SignWebhookPayload([]byte(`{"id":"evt_1"}`), "other", timestamp))
+}
+
+// recordingDispatcher captures dispatched webhook events
+type recordingDispatcher struct {
+	events []WebhookEvent
+}
+
+func (d *recordingDispatcher) Dispatch(ctx context.Context, event WebhookEvent) error {
+	d.events = append(d.events, event)
+	return nil
+}
+
+func TestWebhookDispatchingRecorder(t *testing.T) {
+	dispatcher := &recordingDispatcher{}
+	recorder := NewWebhookDispatchingRecorder(&recordingAuditRecorder{}, dispatcher)
+
+	// Execute
+	err := recorder.Record(context.Background(), auditservice.AuditEntry{
+		TenantID:     1,
+		Action:       auditservice.ActionMemberAdded,
+		ResourceType: auditservice.ResourceMember,
+		ResourceID:   "8",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, dispatcher.events, 1)
+	assert.Equal(t, auditservice.ActionMemberAdded, dispatcher.events[0].Type)
+	assert.Equal(t, int64(1), dispatcher.events[0].TenantID)
+	assert.Equal(t, "8", dispatcher.events[0].Data["resource_id"])
+	assert.True(t, strings.HasPrefix(dispatcher.events[0].ID, "evt_"))
+}
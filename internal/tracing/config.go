@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	// Default values
+	defaultServiceName = "silocore-go"
+	defaultSampleRatio = 1.0
+
+	// Environment variable names. The OTLP exporter also reads the standard
+	// OTEL_EXPORTER_OTLP_* variables, e.g. OTEL_EXPORTER_OTLP_HEADERS.
+	envEndpoint    = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	envServiceName = "OTEL_SERVICE_NAME"
+	envSampleRatio = "TRACING_SAMPLE_RATIO"
+)
+
+// Config holds configuration for tracing
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP collector spans are exported to,
+	// e.g. http://localhost:4318. Tracing is disabled if it is empty.
+	Endpoint string
+
+	// ServiceName identifies the server in traces
+	ServiceName string
+
+	// SampleRatio is the fraction of traces started by the server that are
+	// recorded, from 0 to 1. Traces started by a caller follow its decision.
+	SampleRatio float64
+}
+
+// Enabled reports whether spans are exported
+func (c Config) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// LoadConfig loads tracing configuration from environment variables
+func LoadConfig() (Config, error) {
+	config := Config{
+		Endpoint:    os.Getenv(envEndpoint),
+		ServiceName: os.Getenv(envServiceName),
+		SampleRatio: defaultSampleRatio,
+	}
+
+	if config.ServiceName == "" {
+		config.ServiceName = defaultServiceName
+	}
+
+	if value := os.Getenv(envSampleRatio); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return Config{}, fmt.Errorf("invalid TRACING_SAMPLE_RATIO value: %q", value)
+		}
+		config.SampleRatio = ratio
+	}
+
+	return config, nil
+}
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		t.Setenv(envEndpoint, "")
+
+		config, err := LoadConfig()
+
+		require.NoError(t, err)
+		assert.False(t, config.Enabled())
+		assert.Equal(t, defaultServiceName, config.ServiceName)
+		assert.Equal(t, defaultSampleRatio, config.SampleRatio)
+	})
+
+	t.Run("From environment", func(t *testing.T) {
+		t.Setenv(envEndpoint, "http://collector:4318")
+		t.Setenv(envServiceName, "silocore-api")
+		t.Setenv(envSampleRatio, "0.25")
+
+		config, err := LoadConfig()
+
+		require.NoError(t, err)
+		assert.True(t, config.Enabled())
+		assert.Equal(t, "silocore-api", config.ServiceName)
+		assert.Equal(t, 0.25, config.SampleRatio)
+	})
+
+	t.Run("Invalid sample ratio", func(t *testing.T) {
+		t.Setenv(envSampleRatio, "2")
+
+		_, err := LoadConfig()
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider recording the spans ended during the
+// test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+// spanAttributes returns the attributes of a span by key
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{})
+
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+	_, err := Setup(context.Background(), Config{})
+	require.NoError(t, err)
+
+	var handlerSpan trace.SpanContext
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	})
+
+	t.Run("Continues the caller's trace", func(t *testing.T) {
+		recorder.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "GET /orders/{id}", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		assert.Equal(t, span.SpanContext(), handlerSpan)
+
+		attributes := spanAttributes(span)
+		assert.Equal(t, "/orders/{id}", attributes["http.route"].AsString())
+		assert.Equal(t, int64(http.StatusNoContent), attributes["http.response.status_code"].AsInt64())
+	})
+
+	t.Run("Marks server errors", func(t *testing.T) {
+		recorder.Reset()
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.False(t, spans[0].Parent().IsValid())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	})
+
+	t.Run("Unmatched route", func(t *testing.T) {
+		recorder.Reset()
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET", spans[0].Name())
+	})
+}
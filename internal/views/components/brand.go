@@ -0,0 +1,44 @@
+package components
+
+import "context"
+
+// brandKey is the context key for the brand pages are rendered with
+type brandKey struct{}
+
+// Brand is the identity shown in the page layout and header
+type Brand struct {
+	Name string
+	// LogoURL is the logo image shown in the header, or empty to show the name only
+	LogoURL string
+	// PrimaryColor and AccentColor are hex colors, or empty for the default theme
+	PrimaryColor string
+	AccentColor  string
+}
+
+// DefaultBrand is used for pages rendered outside a tenant or for tenants without branding
+var DefaultBrand = Brand{Name: "SiloCore"}
+
+// WithBrand returns a context that renders pages with the brand
+func WithBrand(ctx context.Context, brand Brand) context.Context {
+	return context.WithValue(ctx, brandKey{}, brand)
+}
+
+// BrandFromContext returns the brand to render pages with, or DefaultBrand if none is set
+func BrandFromContext(ctx context.Context) Brand {
+	if brand, ok := ctx.Value(brandKey{}).(Brand); ok {
+		return brand
+	}
+	return DefaultBrand
+}
+
+// ThemeStyle returns CSS custom properties for the brand colors, for use in a style attribute
+func (b Brand) ThemeStyle() string {
+	style := ""
+	if b.PrimaryColor != "" {
+		style += "--brand-primary: " + b.PrimaryColor + ";"
+	}
+	if b.AccentColor != "" {
+		style += "--brand-accent: " + b.AccentColor + ";"
+	}
+	return style
+}
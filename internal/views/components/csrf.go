@@ -0,0 +1,41 @@
+package components
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CSRF token names shared with the CSRF middleware
+const (
+	// CSRFFieldName is the form field forms submit the CSRF token in
+	CSRFFieldName = "csrf_token"
+
+	// CSRFHeaderName is the header HTMX requests send the CSRF token in
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// csrfTokenKey is the context key for the CSRF token of the request
+type csrfTokenKey struct{}
+
+// WithCSRFToken returns a context that renders pages with the CSRF token
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey{}, token)
+}
+
+// CSRFTokenFromContext returns the CSRF token to render pages with, or an empty
+// string if none is set
+func CSRFTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// CSRFHeaders returns the hx-headers attribute value sending the CSRF token with
+// every HTMX request of the page, or an empty string if there is no token
+func CSRFHeaders(ctx context.Context) string {
+	token := CSRFTokenFromContext(ctx)
+	if token == "" {
+		return ""
+	}
+	headers, _ := json.Marshal(map[string]string{CSRFHeaderName: token})
+	return string(headers)
+}
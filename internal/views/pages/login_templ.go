@@ -8,7 +8,10 @@ package pages
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-import "github.com/unsavory/silocore-go/internal/views/layouts"
+import (
+	"github.com/unsavory/silocore-go/internal/views/components"
+	"github.com/unsavory/silocore-go/internal/views/layouts"
+)
 
 type LoginData struct {
 	Error   string
@@ -60,7 +63,7 @@ func Login(data LoginData) templ.Component {
 				var templ_7745c5c3_Var3 string
 				templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(data.Error)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/login.templ`, Line: 20, Col: 47}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `pages/login.templ`, Line: 23, Col: 47}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 				if templ_7745c5c3_Err != nil {
@@ -79,7 +82,7 @@ func Login(data LoginData) templ.Component {
 				var templ_7745c5c3_Var4 string
 				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(data.Success)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/login.templ`, Line: 26, Col: 49}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `pages/login.templ`, Line: 29, Col: 49}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 				if templ_7745c5c3_Err != nil {
@@ -90,7 +93,15 @@ func Login(data LoginData) templ.Component {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<form hx-post=\"/login\" hx-swap=\"outerHTML\" class=\"space-y-4\"><div><label for=\"email\" class=\"form-label\">Email</label> <input type=\"email\" id=\"email\" name=\"email\" class=\"form-input\" required autocomplete=\"email\"></div><div><label for=\"password\" class=\"form-label\">Password</label> <input type=\"password\" id=\"password\" name=\"password\" class=\"form-input\" required autocomplete=\"current-password\"></div><div class=\"flex items-center justify-between\"><div class=\"flex items-center\"><input type=\"checkbox\" id=\"remember\" name=\"remember\" class=\"h-4 w-4 text-primary-600 focus:ring-primary-500 border-gray-300 rounded\"> <label for=\"remember\" class=\"ml-2 block text-sm text-gray-700\">Remember me</label></div><a href=\"/forgot-password\" class=\"text-sm text-primary-600 hover:text-primary-500\">Forgot password?</a></div><div><button type=\"submit\" class=\"btn-primary w-full\">Sign in</button></div></form><div class=\"mt-6 text-center\"><p class=\"text-sm text-gray-600\">Don't have an account?  <a href=\"/register\" class=\"text-primary-600 hover:text-primary-500 font-medium\">Sign up</a></p></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<form hx-post=\"/login\" hx-swap=\"outerHTML\" class=\"space-y-4\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = components.CSRFField().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div><label for=\"email\" class=\"form-label\">Email</label> <input type=\"email\" id=\"email\" name=\"email\" class=\"form-input\" required autocomplete=\"email\"></div><div><label for=\"password\" class=\"form-label\">Password</label> <input type=\"password\" id=\"password\" name=\"password\" class=\"form-input\" required autocomplete=\"current-password\"></div><div class=\"flex items-center justify-between\"><div class=\"flex items-center\"><input type=\"checkbox\" id=\"remember\" name=\"remember\" class=\"h-4 w-4 text-primary-600 focus:ring-primary-500 border-gray-300 rounded\"> <label for=\"remember\" class=\"ml-2 block text-sm text-gray-700\">Remember me</label></div><a href=\"/forgot-password\" class=\"text-sm text-primary-600 hover:text-primary-500\">Forgot password?</a></div><div><button type=\"submit\" class=\"btn-primary w-full\">Sign in</button></div></form><div class=\"mt-6 text-center\"><p class=\"text-sm text-gray-600\">Don't have an account?  <a href=\"/register\" class=\"text-primary-600 hover:text-primary-500 font-medium\">Sign up</a></p></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -0,0 +1,10 @@
+SET ROLE silocore_admin;
+
+-- Tenants move between active, suspended and pending_deletion.
+-- The disabled status is replaced by suspended.
+ALTER TABLE tenant DROP CONSTRAINT tenant_status_check;
+
+UPDATE tenant SET status = 'suspended' WHERE status = 'disabled';
+
+ALTER TABLE tenant ADD CONSTRAINT tenant_status_check CHECK (status IN ('active', 'suspended', 'pending_deletion'));
+ALTER TABLE tenant ALTER COLUMN status SET DEFAULT 'active';
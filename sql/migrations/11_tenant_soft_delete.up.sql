@@ -0,0 +1,6 @@
+SET ROLE silocore_admin;
+
+-- Deleted tenants are kept for a retention window so they can be restored,
+-- then purged by a background job
+ALTER TABLE tenant ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX tenant_deleted_at_idx ON tenant(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,31 @@
+SET ROLE silocore_admin;
+
+-- Create a table of per-tenant limits. NULL limits are unlimited and tenants
+-- without a row have no limits.
+CREATE TABLE tenant_quota (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    max_members INTEGER CHECK (max_members >= 0),
+    max_orders INTEGER CHECK (max_orders >= 0),
+    max_api_requests INTEGER CHECK (max_api_requests >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Enable Row Level Security on tenant_quota table
+ALTER TABLE tenant_quota ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_quota table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies 
+        WHERE tablename = 'tenant_quota' AND policyname = 'tenant_quota_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_quota_isolation_policy ON tenant_quota
+        USING (
+            tenant_id = tenant_context() 
+            OR 
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
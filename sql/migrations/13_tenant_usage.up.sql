@@ -0,0 +1,52 @@
+SET ROLE silocore_admin;
+
+-- Create a table of daily usage counters per tenant, used for billing and capacity planning
+CREATE TABLE tenant_usage (
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    usage_date DATE NOT NULL,
+    api_requests BIGINT NOT NULL DEFAULT 0,
+    orders_created BIGINT NOT NULL DEFAULT 0,
+    active_users BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, usage_date)
+);
+
+-- Create a table of the distinct users active in a tenant each day
+CREATE TABLE tenant_usage_user (
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    usage_date DATE NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES usr(id) ON DELETE CASCADE,
+    PRIMARY KEY (tenant_id, usage_date, user_id)
+);
+
+-- Enable Row Level Security on usage tables
+ALTER TABLE tenant_usage ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_usage_user ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policies for usage tables if they don't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies 
+        WHERE tablename = 'tenant_usage' AND policyname = 'tenant_usage_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_usage_isolation_policy ON tenant_usage
+        USING (
+            tenant_id = tenant_context() 
+            OR 
+            tenant_context() IS NULL
+        );
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies 
+        WHERE tablename = 'tenant_usage_user' AND policyname = 'tenant_usage_user_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_usage_user_isolation_policy ON tenant_usage_user
+        USING (
+            tenant_id = tenant_context() 
+            OR 
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,58 @@
+SET ROLE silocore_admin;
+
+-- Create a table of billing plans. Each paid plan maps to a Stripe price and
+-- grants a set of feature flags; tenants without a paying subscription get the
+-- features of the free plan.
+CREATE TABLE plan (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    stripe_price_id VARCHAR(255) UNIQUE,
+    features TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Seed the default plans. The Stripe price of paid plans is set per deployment.
+INSERT INTO plan (code, name, features) VALUES
+    ('free', 'Free', '{}'),
+    ('pro', 'Pro', '{invitations}');
+
+-- Link tenants to their Stripe customer and subscription. Status mirrors the
+-- Stripe subscription status.
+CREATE TABLE tenant_subscription (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    plan_id INTEGER REFERENCES plan(id),
+    stripe_customer_id VARCHAR(255) NOT NULL UNIQUE,
+    stripe_subscription_id VARCHAR(255) UNIQUE,
+    status VARCHAR(32) NOT NULL DEFAULT 'incomplete',
+    current_period_end TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Enable Row Level Security on tenant_subscription table
+ALTER TABLE tenant_subscription ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_subscription table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_subscription' AND policyname = 'tenant_subscription_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_subscription_isolation_policy ON tenant_subscription
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
+
+-- Record processed Stripe webhook events so redelivered events are ignored
+CREATE TABLE billing_event (
+    id VARCHAR(255) PRIMARY KEY,
+    type VARCHAR(255) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,33 @@
+SET ROLE silocore_admin;
+
+-- Create a table of per-tenant branding shown in the tenant's pages. Tenants
+-- without a row use the default SiloCore branding and their own name.
+CREATE TABLE tenant_branding (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    display_name VARCHAR(255),
+    primary_color VARCHAR(7) CHECK (primary_color ~ '^#[0-9a-f]{6}$'),
+    accent_color VARCHAR(7) CHECK (accent_color ~ '^#[0-9a-f]{6}$'),
+    logo BYTEA,
+    logo_content_type VARCHAR(64),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Enable Row Level Security on tenant_branding table
+ALTER TABLE tenant_branding ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_branding table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_branding' AND policyname = 'tenant_branding_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_branding_isolation_policy ON tenant_branding
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
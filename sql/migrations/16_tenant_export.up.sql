@@ -0,0 +1,37 @@
+SET ROLE silocore_admin;
+
+-- Create a table of tenant data exports. Exports are generated in the background
+-- and the zip archive is stored until the download link expires.
+CREATE TABLE tenant_export (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    requested_by INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    progress INTEGER NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    error TEXT,
+    archive BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+CREATE INDEX tenant_export_tenant_id_idx ON tenant_export(tenant_id);
+
+-- Enable Row Level Security on tenant_export table
+ALTER TABLE tenant_export ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_export table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_export' AND policyname = 'tenant_export_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_export_isolation_policy ON tenant_export
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,37 @@
+SET ROLE silocore_admin;
+
+-- Create a tenant-scoped audit log of changes made within a tenant, such as
+-- member, role and order changes. Actor references are nullable so records
+-- survive user deletion.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    actor_user_id INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    action VARCHAR(64) NOT NULL,
+    resource_type VARCHAR(64) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX audit_log_tenant_id_created_at_idx ON audit_log(tenant_id, created_at);
+CREATE INDEX audit_log_actor_user_id_idx ON audit_log(actor_user_id);
+
+-- Enable Row Level Security on audit_log table
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for audit_log table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'audit_log' AND policyname = 'audit_log_isolation_policy'
+    ) THEN
+        CREATE POLICY audit_log_isolation_policy ON audit_log
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
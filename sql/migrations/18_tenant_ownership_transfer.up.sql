@@ -0,0 +1,36 @@
+SET ROLE silocore_admin;
+
+-- Create a table of tenant ownership transfers. A transfer grants TENANT_SUPER to
+-- the new owner once they confirm it with the emailed token, which is stored hashed.
+CREATE TABLE tenant_ownership_transfer (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    from_user_id INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    to_user_id INTEGER NOT NULL REFERENCES usr(id) ON DELETE CASCADE,
+    demote_previous BOOLEAN NOT NULL DEFAULT FALSE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX tenant_ownership_transfer_tenant_id_idx ON tenant_ownership_transfer(tenant_id);
+
+-- Enable Row Level Security on tenant_ownership_transfer table
+ALTER TABLE tenant_ownership_transfer ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_ownership_transfer table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_ownership_transfer' AND policyname = 'tenant_ownership_transfer_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_ownership_transfer_isolation_policy ON tenant_ownership_transfer
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,39 @@
+SET ROLE silocore_admin;
+
+-- Create a table of tenant API keys. Keys act for the user who created them,
+-- limited to their scopes, and are stored hashed. The prefix identifies a key
+-- in listings without revealing it.
+CREATE TABLE tenant_api_key (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_by INTEGER NOT NULL REFERENCES usr(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX tenant_api_key_tenant_id_idx ON tenant_api_key(tenant_id);
+
+-- Enable Row Level Security on tenant_api_key table
+ALTER TABLE tenant_api_key ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_api_key table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_api_key' AND policyname = 'tenant_api_key_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_api_key_isolation_policy ON tenant_api_key
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,38 @@
+SET ROLE silocore_admin;
+
+-- Create a table of tenant webhook endpoints. Events of the subscribed types are
+-- posted to the URL, signed with the endpoint's secret. The outcome of the latest
+-- delivery is kept for troubleshooting.
+CREATE TABLE tenant_webhook (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_delivery_at TIMESTAMPTZ,
+    last_delivery_status INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX tenant_webhook_tenant_id_idx ON tenant_webhook(tenant_id);
+
+-- Enable Row Level Security on tenant_webhook table
+ALTER TABLE tenant_webhook ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_webhook table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_webhook' AND policyname = 'tenant_webhook_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_webhook_isolation_policy ON tenant_webhook
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,8 @@
+SET ROLE silocore_admin;
+
+-- Let users choose the tenant they land in after logging in. Users without a
+-- default fall back to their oldest membership.
+ALTER TABLE tenant_member ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- A user has at most one default tenant
+CREATE UNIQUE INDEX tenant_member_user_default_idx ON tenant_member(user_id) WHERE is_default;
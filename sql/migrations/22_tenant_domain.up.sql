@@ -0,0 +1,35 @@
+SET ROLE silocore_admin;
+
+-- Create a table of custom domains attached to tenants. A domain is only used to
+-- resolve the tenant once a DNS TXT record proves the tenant controls it, so the
+-- same domain can be pending for several tenants but verified for only one.
+CREATE TABLE tenant_domain (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    domain VARCHAR(253) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, domain)
+);
+CREATE UNIQUE INDEX tenant_domain_verified_domain_idx ON tenant_domain(domain) WHERE verified_at IS NOT NULL;
+
+-- Enable Row Level Security on tenant_domain table
+ALTER TABLE tenant_domain ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_domain table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_domain' AND policyname = 'tenant_domain_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_domain_isolation_policy ON tenant_domain
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
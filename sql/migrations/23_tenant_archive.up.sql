@@ -0,0 +1,39 @@
+SET ROLE silocore_admin;
+
+-- Archived tenants keep their settings and members, but their data is moved to
+-- object storage and they are read-only until restored
+ALTER TABLE tenant DROP CONSTRAINT tenant_status_check;
+ALTER TABLE tenant ADD CONSTRAINT tenant_status_check CHECK (status IN ('active', 'suspended', 'pending_deletion', 'archived'));
+
+-- Create a table recording each archive of a tenant's data. The archive objects
+-- are kept after a restore to satisfy retention requirements.
+CREATE TABLE tenant_archive (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    storage_key VARCHAR(1024) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    row_counts JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    restored_at TIMESTAMPTZ
+);
+CREATE INDEX tenant_archive_tenant_id_idx ON tenant_archive(tenant_id);
+
+-- Enable Row Level Security on tenant_archive table
+ALTER TABLE tenant_archive ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_archive table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_archive' AND policyname = 'tenant_archive_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_archive_isolation_policy ON tenant_archive
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,5 @@
+SET ROLE silocore_admin;
+
+-- Add the hourly API request budget of each plan. NULL is unlimited. A limit
+-- in a tenant's quota overrides the budget of its plan.
+ALTER TABLE plan ADD COLUMN max_api_requests INTEGER CHECK (max_api_requests >= 0);
@@ -0,0 +1,31 @@
+SET ROLE silocore_admin;
+
+-- Record the progress of each tenant's onboarding checklist. The steps are
+-- computed from the tenant's data; only completing the checklist and dismissing
+-- it are stored, so a completed checklist stays complete.
+CREATE TABLE tenant_onboarding (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    completed_at TIMESTAMPTZ,
+    dismissed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Enable Row Level Security on tenant_onboarding table
+ALTER TABLE tenant_onboarding ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_onboarding table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_onboarding' AND policyname = 'tenant_onboarding_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_onboarding_isolation_policy ON tenant_onboarding
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
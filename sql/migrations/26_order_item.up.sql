@@ -0,0 +1,41 @@
+SET ROLE silocore_admin;
+
+-- The order service has always queried "order" by order_id; align the table
+-- created as ordr with it
+ALTER TABLE ordr RENAME TO "order";
+ALTER TABLE "order" RENAME COLUMN id TO order_id;
+
+-- Create a table for the line items of orders. An order with items has its
+-- total derived from them.
+CREATE TABLE order_item (
+    item_id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES "order"(order_id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL CHECK (sku <> ''),
+    description TEXT NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_order_item_order_id ON order_item (order_id);
+CREATE INDEX idx_order_item_tenant_id ON order_item (tenant_id);
+
+-- Enable Row Level Security on order_item table
+ALTER TABLE order_item ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for order_item table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'order_item' AND policyname = 'order_item_isolation_policy'
+    ) THEN
+        CREATE POLICY order_item_isolation_policy ON order_item
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
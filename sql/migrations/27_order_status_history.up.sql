@@ -0,0 +1,40 @@
+SET ROLE silocore_admin;
+
+-- Create a table recording every status change of an order. The first entry of
+-- an order has no previous status.
+CREATE TABLE order_status_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES "order"(order_id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    from_status VARCHAR(64),
+    to_status VARCHAR(64) NOT NULL,
+    changed_by INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_order_status_history_order_id ON order_status_history (order_id, changed_at);
+CREATE INDEX idx_order_status_history_tenant_id ON order_status_history (tenant_id);
+
+-- Start the history of existing orders with their current status
+INSERT INTO order_status_history (order_id, tenant_id, to_status, changed_by, changed_at)
+SELECT order_id, tenant_id, status, user_id, created_at FROM "order";
+
+-- Enable Row Level Security on order_status_history table
+ALTER TABLE order_status_history ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for order_status_history table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'order_status_history' AND policyname = 'order_status_history_isolation_policy'
+    ) THEN
+        CREATE POLICY order_status_history_isolation_policy ON order_status_history
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
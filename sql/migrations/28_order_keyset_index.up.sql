@@ -0,0 +1,4 @@
+SET ROLE silocore_admin;
+
+-- Order lists page by (created_at, order_id) within a tenant, newest first
+CREATE INDEX idx_order_tenant_created_at ON "order" (tenant_id, created_at DESC, order_id DESC);
@@ -0,0 +1,8 @@
+SET ROLE silocore_admin;
+
+-- Order search matches substrings of the order number and notes, which trigram
+-- indexes serve for ILIKE patterns
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_order_order_number_trgm ON "order" USING GIN (order_number gin_trgm_ops);
+CREATE INDEX idx_order_notes_trgm ON "order" USING GIN (notes gin_trgm_ops);
@@ -0,0 +1,5 @@
+SET ROLE silocore_admin;
+
+-- Version orders for optimistic concurrency control. Every update increments the
+-- version, and updates and deletes must give the version they expect.
+ALTER TABLE "order" ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
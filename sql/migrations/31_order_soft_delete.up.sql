@@ -0,0 +1,6 @@
+SET ROLE silocore_admin;
+
+-- Deleted orders move to the trash, where they can be restored until the purge
+-- job removes them after the retention period
+ALTER TABLE "order" ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX idx_order_deleted_at ON "order" (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,53 @@
+SET ROLE silocore_admin;
+
+-- Count the orders numbered for each tenant per year. Orders without a number
+-- given by the client are numbered from this table rather than a database
+-- sequence: the row stays locked until the creating transaction ends and rolls
+-- back with it, so the numbers have no gaps.
+CREATE TABLE order_number_sequence (
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    year INTEGER NOT NULL,
+    last_value BIGINT NOT NULL CHECK (last_value > 0),
+    PRIMARY KEY (tenant_id, year)
+);
+
+-- Create a table of per-tenant order settings. Tenants without a row number
+-- their orders with the default prefix.
+CREATE TABLE tenant_order_setting (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    order_number_prefix VARCHAR(16) NOT NULL CHECK (order_number_prefix ~ '^[A-Z0-9]([A-Z0-9-]*[A-Z0-9])?$'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Enable Row Level Security on the new tables
+ALTER TABLE order_number_sequence ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_order_setting ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policies for the new tables if they don't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'order_number_sequence' AND policyname = 'order_number_sequence_isolation_policy'
+    ) THEN
+        CREATE POLICY order_number_sequence_isolation_policy ON order_number_sequence
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_order_setting' AND policyname = 'tenant_order_setting_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_order_setting_isolation_policy ON tenant_order_setting
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
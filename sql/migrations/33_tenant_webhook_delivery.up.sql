@@ -0,0 +1,39 @@
+SET ROLE silocore_admin;
+
+-- Log every attempt to deliver an event to a webhook, so tenants can debug their
+-- endpoints. Only the latest attempts of each webhook are kept.
+CREATE TABLE tenant_webhook_delivery (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES tenant_webhook(id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    attempt INTEGER NOT NULL,
+    response_status INTEGER NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX tenant_webhook_delivery_webhook_id_idx ON tenant_webhook_delivery(webhook_id, id DESC);
+CREATE INDEX tenant_webhook_delivery_tenant_id_idx ON tenant_webhook_delivery(tenant_id);
+
+-- Enable Row Level Security on tenant_webhook_delivery table
+ALTER TABLE tenant_webhook_delivery ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_webhook_delivery table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_webhook_delivery' AND policyname = 'tenant_webhook_delivery_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_webhook_delivery_isolation_policy ON tenant_webhook_delivery
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,40 @@
+SET ROLE silocore_admin;
+
+-- Files attached to orders, e.g. invoices and photos. The content is kept in
+-- object storage under storage_key.
+CREATE TABLE order_attachment (
+    attachment_id BIGSERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES "order"(order_id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    storage_key VARCHAR(512) NOT NULL UNIQUE,
+    uploaded_by INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_order_attachment_order_id ON order_attachment (order_id, created_at);
+CREATE INDEX idx_order_attachment_tenant_id ON order_attachment (tenant_id);
+
+-- Limit the total size of a tenant's attachments. NULL is unlimited.
+ALTER TABLE tenant_quota ADD COLUMN max_storage_bytes BIGINT CHECK (max_storage_bytes >= 0);
+
+-- Enable Row Level Security on order_attachment table
+ALTER TABLE order_attachment ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for order_attachment table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'order_attachment' AND policyname = 'order_attachment_isolation_policy'
+    ) THEN
+        CREATE POLICY order_attachment_isolation_policy ON order_attachment
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,34 @@
+SET ROLE silocore_admin;
+
+-- Create a table of comments on orders, so tenant members can discuss an order
+-- where it is kept
+CREATE TABLE order_comment (
+    comment_id BIGSERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES "order"(order_id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    author_id INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    body TEXT NOT NULL CHECK (body <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_order_comment_order_id ON order_comment (order_id, created_at);
+CREATE INDEX idx_order_comment_tenant_id ON order_comment (tenant_id);
+
+-- Enable Row Level Security on order_comment table
+ALTER TABLE order_comment ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for order_comment table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'order_comment' AND policyname = 'order_comment_isolation_policy'
+    ) THEN
+        CREATE POLICY order_comment_isolation_policy ON order_comment
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
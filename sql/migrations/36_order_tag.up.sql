@@ -0,0 +1,32 @@
+SET ROLE silocore_admin;
+
+-- Create a table of free-form tags on orders, so tenants can categorize orders
+-- without schema changes
+CREATE TABLE order_tag (
+    order_id INTEGER NOT NULL REFERENCES "order"(order_id) ON DELETE CASCADE,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL CHECK (tag <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (order_id, tag)
+);
+CREATE INDEX idx_order_tag_tenant_id_tag ON order_tag (tenant_id, tag);
+
+-- Enable Row Level Security on order_tag table
+ALTER TABLE order_tag ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for order_tag table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'order_tag' AND policyname = 'order_tag_isolation_policy'
+    ) THEN
+        CREATE POLICY order_tag_isolation_policy ON order_tag
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
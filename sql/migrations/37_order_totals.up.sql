@@ -0,0 +1,12 @@
+SET ROLE silocore_admin;
+
+-- Break order totals down into a subtotal, a discount and tax. The total is the
+-- subtotal less the discount plus tax at the order's rate, in percent.
+ALTER TABLE "order"
+    ADD COLUMN subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (subtotal >= 0),
+    ADD COLUMN discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0),
+    ADD COLUMN tax_rate DECIMAL(6, 3) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0 AND tax_rate <= 100),
+    ADD COLUMN tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0);
+
+-- Existing orders had neither discount nor tax
+UPDATE "order" SET subtotal = total_amount;
@@ -0,0 +1,49 @@
+SET ROLE silocore_admin;
+
+-- Orders that haven't changed for a long time are moved here by the archive job
+-- to keep "order" small. The columns match "order" so archived orders are listed
+-- the same way; the items, status history, comments and tags of an archived
+-- order are kept in details.
+CREATE TABLE order_archive (
+    order_id INTEGER PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES usr(id) ON DELETE CASCADE,
+    order_number VARCHAR(64) NOT NULL,
+    status VARCHAR(64) NOT NULL,
+    total_amount DECIMAL(10, 2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    version INTEGER NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    subtotal DECIMAL(10, 2) NOT NULL,
+    discount_amount DECIMAL(10, 2) NOT NULL,
+    tax_rate DECIMAL(6, 3) NOT NULL,
+    tax_amount DECIMAL(10, 2) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_order_archive_tenant_created_at ON order_archive (tenant_id, created_at DESC, order_id DESC);
+
+-- The archive job finds orders by their last change
+CREATE INDEX idx_order_updated_at ON "order" (updated_at) WHERE deleted_at IS NULL;
+
+-- Enable Row Level Security on order_archive table
+ALTER TABLE order_archive ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for order_archive table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'order_archive' AND policyname = 'order_archive_isolation_policy'
+    ) THEN
+        CREATE POLICY order_archive_isolation_policy ON order_archive
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,11 @@
+SET ROLE silocore_admin;
+
+-- Orders can be assigned to a tenant member to divide work on them. Orders of a
+-- user who is deleted become unassigned.
+ALTER TABLE "order"
+    ADD COLUMN assigned_to INTEGER REFERENCES usr(id) ON DELETE SET NULL;
+CREATE INDEX idx_order_assigned_to ON "order" (tenant_id, assigned_to) WHERE assigned_to IS NOT NULL;
+
+-- Archived orders keep their assignee
+ALTER TABLE order_archive
+    ADD COLUMN assigned_to INTEGER;
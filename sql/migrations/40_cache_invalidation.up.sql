@@ -0,0 +1,62 @@
+SET ROLE silocore_admin;
+
+-- Changes to cached data are broadcast on the cache_invalidation channel when
+-- they commit, so every server instance can drop its stale in-memory entries,
+-- whichever instance or tool made the change. The payload names the table and
+-- the user and tenant of the changed row, where it has them.
+CREATE OR REPLACE FUNCTION notify_cache_invalidation()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed JSONB;
+BEGIN
+    IF TG_LEVEL = 'ROW' THEN
+        IF TG_OP = 'DELETE' THEN
+            changed := to_jsonb(OLD);
+        ELSE
+            changed := to_jsonb(NEW);
+        END IF;
+    END IF;
+
+    PERFORM pg_notify('cache_invalidation', jsonb_build_object(
+        'table', TG_TABLE_NAME,
+        'user_id', changed -> 'user_id',
+        'tenant_id', CASE WHEN TG_TABLE_NAME = 'tenant' THEN changed -> 'id' ELSE changed -> 'tenant_id' END
+    )::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Roles and memberships
+CREATE TRIGGER user_role_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON user_role
+FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+CREATE TRIGGER tenant_role_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON tenant_role
+FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+CREATE TRIGGER tenant_member_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON tenant_member
+FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+-- Role definitions and the role hierarchy affect every user's roles
+CREATE TRIGGER role_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON role
+FOR EACH STATEMENT EXECUTE FUNCTION notify_cache_invalidation();
+
+CREATE TRIGGER role_inheritance_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON role_inheritance
+FOR EACH STATEMENT EXECUTE FUNCTION notify_cache_invalidation();
+
+-- Tenant settings
+CREATE TRIGGER tenant_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON tenant
+FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+CREATE TRIGGER tenant_branding_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON tenant_branding
+FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+CREATE TRIGGER tenant_quota_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON tenant_quota
+FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
@@ -0,0 +1,45 @@
+SET ROLE silocore_admin;
+
+-- Domain events are written to the outbox in the same transaction as the change
+-- they describe, so an event is never lost or published for a rolled back
+-- change. A background dispatcher publishes pending events and marks them
+-- published; an event may be published more than once, and consumers detect
+-- duplicates by its event_id. Events with the same event_key are published in
+-- order. Published events are kept for a while, then pruned.
+CREATE TABLE event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL UNIQUE,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    event_type VARCHAR(64) NOT NULL,
+    event_key VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX event_outbox_pending_idx ON event_outbox(available_at, id) WHERE published_at IS NULL;
+CREATE INDEX event_outbox_pending_key_idx ON event_outbox(tenant_id, event_key, id) WHERE published_at IS NULL;
+CREATE INDEX event_outbox_published_at_idx ON event_outbox(published_at) WHERE published_at IS NOT NULL;
+CREATE INDEX event_outbox_tenant_id_idx ON event_outbox(tenant_id);
+
+-- Enable Row Level Security on event_outbox table
+ALTER TABLE event_outbox ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for event_outbox table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'event_outbox' AND policyname = 'event_outbox_isolation_policy'
+    ) THEN
+        CREATE POLICY event_outbox_isolation_policy ON event_outbox
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,39 @@
+SET ROLE silocore_admin;
+
+-- Tenants restrict the IP addresses their members may connect from with allow
+-- and deny rules. Deny rules take precedence; once a tenant has an allow rule,
+-- only addresses within its allow rules are let in.
+CREATE TABLE tenant_ip_rule (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    cidr CIDR NOT NULL,
+    action VARCHAR(8) NOT NULL CHECK (action IN ('allow', 'deny')),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, action, cidr)
+);
+
+-- Enable Row Level Security on tenant_ip_rule table
+ALTER TABLE tenant_ip_rule ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_ip_rule table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies
+        WHERE tablename = 'tenant_ip_rule' AND policyname = 'tenant_ip_rule_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_ip_rule_isolation_policy ON tenant_ip_rule
+        USING (
+            tenant_id = tenant_context()
+            OR
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
+
+-- Rules are cached, so instances drop a tenant's rules when they change
+CREATE TRIGGER tenant_ip_rule_cache_invalidation
+AFTER INSERT OR UPDATE OR DELETE ON tenant_ip_rule
+FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
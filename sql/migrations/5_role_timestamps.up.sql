@@ -0,0 +1,12 @@
+SET ROLE silocore_admin;
+
+-- Record when roles are created and last changed. The role management API reads
+-- and returns both; roles created by earlier migrations get the current time.
+ALTER TABLE role
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE TRIGGER update_role_updated_at
+BEFORE UPDATE ON role
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,23 @@
+SET ROLE silocore_admin;
+
+-- Add a role representing plain tenant membership so it can be inherited
+INSERT INTO role (name, description) VALUES
+    ('TENANT_MEMBER', 'A member of a tenant with access to tenant-scoped features')
+ON CONFLICT (name) DO NOTHING;
+
+-- Create a table describing which roles inherit the permissions of other roles
+CREATE TABLE role_inheritance (
+    role_id INTEGER NOT NULL REFERENCES role(id) ON DELETE CASCADE,
+    inherited_role_id INTEGER NOT NULL REFERENCES role(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (role_id, inherited_role_id),
+    CHECK (role_id <> inherited_role_id)
+);
+CREATE INDEX role_inheritance_inherited_role_id_idx ON role_inheritance(inherited_role_id);
+
+-- TENANT_SUPER inherits TENANT_MEMBER
+INSERT INTO role_inheritance (role_id, inherited_role_id)
+SELECT parent.id, child.id
+FROM role parent, role child
+WHERE parent.name = 'TENANT_SUPER' AND child.name = 'TENANT_MEMBER'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+SET ROLE silocore_admin;
+
+-- Create an audit trail of role assignments and revocations.
+-- Role and user references are kept nullable so records survive deletions.
+CREATE TABLE role_audit (
+    id SERIAL PRIMARY KEY,
+    actor_user_id INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    target_user_id INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    role_id INTEGER REFERENCES role(id) ON DELETE SET NULL,
+    role_name VARCHAR(64) NOT NULL,
+    tenant_id INTEGER REFERENCES tenant(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('assign', 'revoke')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX role_audit_target_user_id_idx ON role_audit(target_user_id);
+CREATE INDEX role_audit_tenant_id_idx ON role_audit(tenant_id);
+CREATE INDEX role_audit_created_at_idx ON role_audit(created_at);
@@ -0,0 +1,37 @@
+SET ROLE silocore_admin;
+
+-- Create a table of invitations for users to join a tenant.
+-- Only a hash of the invitation token is stored.
+CREATE TABLE tenant_invitation (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role_id INTEGER REFERENCES role(id) ON DELETE SET NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    accepted_user_id INTEGER REFERENCES usr(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX tenant_invitation_tenant_id_idx ON tenant_invitation(tenant_id);
+
+-- Enable Row Level Security on tenant_invitation table
+ALTER TABLE tenant_invitation ENABLE ROW LEVEL SECURITY;
+
+-- Create RLS policy for tenant_invitation table if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_policies 
+        WHERE tablename = 'tenant_invitation' AND policyname = 'tenant_invitation_isolation_policy'
+    ) THEN
+        CREATE POLICY tenant_invitation_isolation_policy ON tenant_invitation
+        USING (
+            tenant_id = tenant_context() 
+            OR 
+            tenant_context() IS NULL
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,22 @@
+SET ROLE silocore_admin;
+
+-- Add a URL slug to tenants, used to resolve the tenant from a subdomain
+-- ({slug}.example.com) or a path prefix (/t/{slug})
+ALTER TABLE tenant ADD COLUMN slug VARCHAR(63);
+
+-- Backfill slugs for existing tenants from their names, appending the tenant ID
+-- when two names produce the same slug
+UPDATE tenant t
+SET slug = s.base || CASE WHEN s.rn > 1 THEN '-' || t.id ELSE '' END
+FROM (
+    SELECT id, base, ROW_NUMBER() OVER (PARTITION BY base ORDER BY id) AS rn
+    FROM (
+        SELECT id, COALESCE(NULLIF(TRIM(BOTH '-' FROM LEFT(REGEXP_REPLACE(LOWER(name), '[^a-z0-9]+', '-', 'g'), 50)), ''), 'tenant') AS base
+        FROM tenant
+    ) b
+) s
+WHERE t.id = s.id;
+
+ALTER TABLE tenant ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE tenant ADD CONSTRAINT tenant_slug_key UNIQUE (slug);
+ALTER TABLE tenant ADD CONSTRAINT tenant_slug_format CHECK (slug ~ '^[a-z0-9]([a-z0-9-]*[a-z0-9])?$');
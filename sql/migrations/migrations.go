@@ -0,0 +1,10 @@
+// Package migrations embeds the database migrations, so binaries can apply
+// them without the sql/migrations directory on disk
+package migrations
+
+import "embed"
+
+// FS holds the migration files
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,122 @@
+-- GetOrder retrieves an order of the tenant that isn't in the trash
+-- name: GetOrder :one
+SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at,
+    subtotal, discount_amount, tax_rate, tax_amount, assigned_to
+FROM "order"
+WHERE order_id = @order_id AND tenant_id = @tenant_id AND deleted_at IS NULL;
+
+-- ListOrderItems retrieves an order's items in the order they were added
+-- name: ListOrderItems :many
+SELECT item_id, order_id, sku, description, quantity, unit_price
+FROM order_item
+WHERE order_id = @order_id AND tenant_id = @tenant_id
+ORDER BY item_id;
+
+-- ListOrders retrieves the tenant's orders matching the filters that are set;
+-- unset filters are NULL. Orders are sorted by the column in sort, prefixed
+-- with "-" for descending order, and then newest first. Lists newest first
+-- continue after the cursor's order if one is given. The planner folds away the
+-- unset filters and sorts when it plans the query with its parameters.
+-- name: ListOrders :many
+SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at,
+    subtotal, discount_amount, tax_rate, tax_amount, assigned_to
+FROM "order"
+WHERE "order".tenant_id = @tenant_id
+    AND (@include_deleted::boolean OR deleted_at IS NULL)
+    AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+    AND (sqlc.narg(user_id)::integer IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(assigned_to)::integer IS NULL OR assigned_to = sqlc.narg(assigned_to))
+    AND (NOT @unassigned::boolean OR assigned_to IS NULL)
+    AND (sqlc.narg(search)::text IS NULL OR order_number ILIKE sqlc.narg(search) OR notes ILIKE sqlc.narg(search))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(min_amount)::numeric IS NULL OR total_amount >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::numeric IS NULL OR total_amount <= sqlc.narg(max_amount))
+    AND (sqlc.narg(tags)::jsonb IS NULL OR sqlc.narg(tags) <@ (
+        SELECT jsonb_agg(tag) FROM order_tag WHERE order_tag.order_id = "order".order_id
+    ))
+    AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+        OR (created_at, order_id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::integer))
+ORDER BY
+    CASE WHEN @sort::text = 'created_at' THEN created_at END,
+    CASE WHEN @sort = 'updated_at' THEN updated_at END,
+    CASE WHEN @sort = '-updated_at' THEN updated_at END DESC,
+    CASE WHEN @sort = 'total_amount' THEN total_amount END,
+    CASE WHEN @sort = '-total_amount' THEN total_amount END DESC,
+    CASE WHEN @sort = 'status' THEN status END,
+    CASE WHEN @sort = '-status' THEN status END DESC,
+    CASE WHEN @sort = 'order_number' THEN order_number END,
+    CASE WHEN @sort = '-order_number' THEN order_number END DESC,
+    CASE WHEN @sort NOT LIKE '-%' THEN order_id END,
+    created_at DESC, order_id DESC
+LIMIT sqlc.narg(row_limit)::integer OFFSET @row_offset::integer;
+
+-- CountOrders counts the tenant's orders matching the filters that are set
+-- name: CountOrders :one
+SELECT COUNT(*)
+FROM "order"
+WHERE "order".tenant_id = @tenant_id
+    AND (@include_deleted::boolean OR deleted_at IS NULL)
+    AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+    AND (sqlc.narg(user_id)::integer IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(assigned_to)::integer IS NULL OR assigned_to = sqlc.narg(assigned_to))
+    AND (NOT @unassigned::boolean OR assigned_to IS NULL)
+    AND (sqlc.narg(search)::text IS NULL OR order_number ILIKE sqlc.narg(search) OR notes ILIKE sqlc.narg(search))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(min_amount)::numeric IS NULL OR total_amount >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::numeric IS NULL OR total_amount <= sqlc.narg(max_amount))
+    AND (sqlc.narg(tags)::jsonb IS NULL OR sqlc.narg(tags) <@ (
+        SELECT jsonb_agg(tag) FROM order_tag WHERE order_tag.order_id = "order".order_id
+    ));
+
+-- ListArchivedOrders is ListOrders for the archive. Archived orders keep their
+-- tags in their details.
+-- name: ListArchivedOrders :many
+SELECT order_id, tenant_id, user_id, order_number, status, total_amount, notes, created_at, updated_at, version, deleted_at,
+    subtotal, discount_amount, tax_rate, tax_amount, assigned_to
+FROM order_archive
+WHERE tenant_id = @tenant_id
+    AND (@include_deleted::boolean OR deleted_at IS NULL)
+    AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+    AND (sqlc.narg(user_id)::integer IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(assigned_to)::integer IS NULL OR assigned_to = sqlc.narg(assigned_to))
+    AND (NOT @unassigned::boolean OR assigned_to IS NULL)
+    AND (sqlc.narg(search)::text IS NULL OR order_number ILIKE sqlc.narg(search) OR notes ILIKE sqlc.narg(search))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(min_amount)::numeric IS NULL OR total_amount >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::numeric IS NULL OR total_amount <= sqlc.narg(max_amount))
+    AND (sqlc.narg(tags)::jsonb IS NULL OR details -> 'tags' @> sqlc.narg(tags))
+    AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+        OR (created_at, order_id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::integer))
+ORDER BY
+    CASE WHEN @sort::text = 'created_at' THEN created_at END,
+    CASE WHEN @sort = 'updated_at' THEN updated_at END,
+    CASE WHEN @sort = '-updated_at' THEN updated_at END DESC,
+    CASE WHEN @sort = 'total_amount' THEN total_amount END,
+    CASE WHEN @sort = '-total_amount' THEN total_amount END DESC,
+    CASE WHEN @sort = 'status' THEN status END,
+    CASE WHEN @sort = '-status' THEN status END DESC,
+    CASE WHEN @sort = 'order_number' THEN order_number END,
+    CASE WHEN @sort = '-order_number' THEN order_number END DESC,
+    CASE WHEN @sort NOT LIKE '-%' THEN order_id END,
+    created_at DESC, order_id DESC
+LIMIT sqlc.narg(row_limit)::integer OFFSET @row_offset::integer;
+
+-- CountArchivedOrders is CountOrders for the archive
+-- name: CountArchivedOrders :one
+SELECT COUNT(*)
+FROM order_archive
+WHERE tenant_id = @tenant_id
+    AND (@include_deleted::boolean OR deleted_at IS NULL)
+    AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+    AND (sqlc.narg(user_id)::integer IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(assigned_to)::integer IS NULL OR assigned_to = sqlc.narg(assigned_to))
+    AND (NOT @unassigned::boolean OR assigned_to IS NULL)
+    AND (sqlc.narg(search)::text IS NULL OR order_number ILIKE sqlc.narg(search) OR notes ILIKE sqlc.narg(search))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+    AND (sqlc.narg(min_amount)::numeric IS NULL OR total_amount >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::numeric IS NULL OR total_amount <= sqlc.narg(max_amount))
+    AND (sqlc.narg(tags)::jsonb IS NULL OR details -> 'tags' @> sqlc.narg(tags));
@@ -0,0 +1,49 @@
+SET ROLE silocore_admin;
+
+-- In schema-per-tenant mode each tenant's orders live in its own schema, which
+-- comes before public in the search path of the tenant's transactions. The
+-- tables copy the shared tables' columns, defaults, checks and indexes, and so
+-- share their ID sequences. Foreign keys are not copied and are added here.
+CREATE TABLE "order" (LIKE public."order" INCLUDING ALL);
+ALTER TABLE "order"
+    ADD FOREIGN KEY (tenant_id) REFERENCES public.tenant(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (user_id) REFERENCES public.usr(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (assigned_to) REFERENCES public.usr(id) ON DELETE SET NULL;
+
+CREATE TRIGGER update_order_updated_at
+BEFORE UPDATE ON "order"
+FOR EACH ROW
+EXECUTE FUNCTION public.set_updated_at();
+
+CREATE TABLE order_item (LIKE public.order_item INCLUDING ALL);
+ALTER TABLE order_item
+    ADD FOREIGN KEY (order_id) REFERENCES "order"(order_id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (tenant_id) REFERENCES public.tenant(id) ON DELETE CASCADE;
+
+CREATE TABLE order_status_history (LIKE public.order_status_history INCLUDING ALL);
+ALTER TABLE order_status_history
+    ADD FOREIGN KEY (order_id) REFERENCES "order"(order_id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (tenant_id) REFERENCES public.tenant(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (changed_by) REFERENCES public.usr(id) ON DELETE SET NULL;
+
+CREATE TABLE order_attachment (LIKE public.order_attachment INCLUDING ALL);
+ALTER TABLE order_attachment
+    ADD FOREIGN KEY (order_id) REFERENCES "order"(order_id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (tenant_id) REFERENCES public.tenant(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (uploaded_by) REFERENCES public.usr(id) ON DELETE SET NULL;
+
+CREATE TABLE order_comment (LIKE public.order_comment INCLUDING ALL);
+ALTER TABLE order_comment
+    ADD FOREIGN KEY (order_id) REFERENCES "order"(order_id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (tenant_id) REFERENCES public.tenant(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (author_id) REFERENCES public.usr(id) ON DELETE SET NULL;
+
+CREATE TABLE order_tag (LIKE public.order_tag INCLUDING ALL);
+ALTER TABLE order_tag
+    ADD FOREIGN KEY (order_id) REFERENCES "order"(order_id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (tenant_id) REFERENCES public.tenant(id) ON DELETE CASCADE;
+
+CREATE TABLE order_archive (LIKE public.order_archive INCLUDING ALL);
+ALTER TABLE order_archive
+    ADD FOREIGN KEY (tenant_id) REFERENCES public.tenant(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (user_id) REFERENCES public.usr(id) ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+// Package tenantmigrations embeds the migrations run in each tenant's schema
+// when tenants are given their own schemas
+package tenantmigrations
+
+import "embed"
+
+// FS holds the migration files
+//
+//go:embed *.sql
+var FS embed.FS